@@ -0,0 +1,201 @@
+// Command benchgate converts `go test -bench` output to JSON and compares it
+// against a committed baseline.
+//
+// Record a baseline:
+//
+//	go test -run '^$' -bench . -benchmem ./internal/repository/ | benchgate -record bench/baseline.json
+//
+// Check for regressions (exit status 1 if any benchmark is slower than the
+// baseline by more than -threshold):
+//
+//	go test -run '^$' -bench . -benchmem ./internal/repository/ | benchgate -baseline bench/baseline.json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result holds the metrics of a single benchmark.
+type Result struct {
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op,omitempty"`
+	AllocsPerOp float64 `json:"allocs_per_op,omitempty"`
+}
+
+// Report is the JSON document written by -record and read by -baseline.
+type Report struct {
+	RecordedAt    time.Time         `json:"recorded_at"`
+	GoVersion     string            `json:"go_version"`
+	GOOS          string            `json:"goos"`
+	GOARCH        string            `json:"goarch"`
+	CPU           string            `json:"cpu,omitempty"`
+	Tasks         string            `json:"bench_tasks,omitempty"`
+	EventsPerTask string            `json:"bench_events_per_task,omitempty"`
+	Results       map[string]Result `json:"results"`
+}
+
+func main() {
+	record := flag.String("record", "", "write parsed results to this file")
+	baseline := flag.String("baseline", "", "compare parsed results against this file")
+	threshold := flag.Float64("threshold", 1.25, "maximum allowed ns/op ratio against the baseline")
+	flag.Parse()
+
+	if (*record == "") == (*baseline == "") {
+		fmt.Fprintln(os.Stderr, "benchgate: exactly one of -record or -baseline is required")
+		os.Exit(2)
+	}
+
+	report, err := parse(io.TeeReader(os.Stdin, os.Stdout))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchgate:", err)
+		os.Exit(2)
+	}
+
+	if *record != "" {
+		if err := write(*record, report); err != nil {
+			fmt.Fprintln(os.Stderr, "benchgate:", err)
+			os.Exit(2)
+		}
+		fmt.Printf("benchgate: recorded %d results to %s\n", len(report.Results), *record)
+		return
+	}
+
+	base, err := read(*baseline)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchgate:", err)
+		os.Exit(2)
+	}
+
+	if !compare(os.Stdout, base, report, *threshold) {
+		os.Exit(1)
+	}
+}
+
+// parse reads benchmark lines such as
+//
+//	BenchmarkList_FirstPage-8   120   9876543 ns/op   2048 B/op   31 allocs/op
+func parse(r io.Reader) (*Report, error) {
+	report := &Report{
+		RecordedAt:    time.Now().UTC(),
+		GoVersion:     runtime.Version(),
+		GOOS:          runtime.GOOS,
+		GOARCH:        runtime.GOARCH,
+		Tasks:         os.Getenv("BENCH_TASKS"),
+		EventsPerTask: os.Getenv("BENCH_EVENTS_PER_TASK"),
+		Results:       map[string]Result{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if cpu, ok := strings.CutPrefix(line, "cpu: "); ok {
+			report.CPU = cpu
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := fields[0]
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			name = name[:i] // strip GOMAXPROCS suffix
+		}
+
+		var result Result
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q: %w", line, err)
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				result.NsPerOp = value
+			case "B/op":
+				result.BytesPerOp = value
+			case "allocs/op":
+				result.AllocsPerOp = value
+			}
+		}
+		report.Results[name] = result
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read benchmark output: %w", err)
+	}
+
+	if len(report.Results) == 0 {
+		return nil, errors.New("no benchmark results in input (is BENCH_DATABASE_URL set?)")
+	}
+
+	return report, nil
+}
+
+// compare prints a table of current vs baseline ns/op and reports whether all
+// benchmarks are within the threshold. Benchmarks missing from the baseline pass.
+func compare(w io.Writer, base, current *Report, threshold float64) bool {
+	names := make([]string, 0, len(current.Results))
+	for name := range current.Results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ok := true
+	fmt.Fprintf(w, "\n%-40s %15s %15s %8s\n", "benchmark", "baseline ns/op", "current ns/op", "ratio")
+	for _, name := range names {
+		result := current.Results[name]
+		previous, found := base.Results[name]
+		if !found || previous.NsPerOp == 0 {
+			fmt.Fprintf(w, "%-40s %15s %15.0f %8s\n", name, "-", result.NsPerOp, "new")
+			continue
+		}
+
+		ratio := result.NsPerOp / previous.NsPerOp
+		verdict := ""
+		if ratio > threshold {
+			verdict = "  REGRESSION"
+			ok = false
+		}
+		fmt.Fprintf(w, "%-40s %15.0f %15.0f %8.2f%s\n", name, previous.NsPerOp, result.NsPerOp, ratio, verdict)
+	}
+
+	if !ok {
+		fmt.Fprintf(w, "\nbenchgate: regressions above %.2fx the baseline\n", threshold)
+	}
+	return ok
+}
+
+func read(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+func write(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write results: %w", err)
+	}
+	return nil
+}
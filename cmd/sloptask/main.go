@@ -10,21 +10,33 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mtlprog/sloptask/internal/config"
+	"github.com/mtlprog/sloptask/internal/coordination"
 	"github.com/mtlprog/sloptask/internal/database"
+	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/handler"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
 	"github.com/mtlprog/sloptask/internal/logger"
+	"github.com/mtlprog/sloptask/internal/middleware"
 	"github.com/mtlprog/sloptask/internal/repository"
 	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/tui"
 	"github.com/urfave/cli/v2"
 )
 
@@ -41,15 +53,45 @@ func main() {
 				EnvVars: []string{"LOG_LEVEL"},
 			},
 			&cli.StringFlag{
-				Name:     "database-url",
-				Aliases:  []string{"d"},
-				Value:    config.DefaultDatabaseURL,
-				Usage:    "PostgreSQL database URL",
-				EnvVars:  []string{"DATABASE_URL"},
-				Required: true,
+				Name:    "database-url",
+				Aliases: []string{"d"},
+				Value:   config.DefaultDatabaseURL,
+				Usage:   "PostgreSQL database URL (required by every command but tui and verify-event-log)",
+				EnvVars: []string{"DATABASE_URL"},
+			},
+			&cli.IntFlag{
+				Name:    "db-max-conns",
+				Value:   int(database.DefaultPoolConfig.MaxConns),
+				Usage:   "Most connections the database pool opens",
+				EnvVars: []string{"DATABASE_MAX_CONNS"},
+			},
+			&cli.IntFlag{
+				Name:    "db-min-conns",
+				Value:   int(database.DefaultPoolConfig.MinConns),
+				Usage:   "Connections the database pool keeps open when idle",
+				EnvVars: []string{"DATABASE_MIN_CONNS"},
+			},
+			&cli.DurationFlag{
+				Name:    "db-max-conn-lifetime",
+				Value:   database.DefaultPoolConfig.MaxConnLifetime,
+				Usage:   "How long a database connection is used before it is replaced",
+				EnvVars: []string{"DATABASE_MAX_CONN_LIFETIME"},
+			},
+			&cli.DurationFlag{
+				Name:    "db-statement-timeout",
+				Usage:   "Cancel SQL statements running longer than this (0 keeps the server's statement_timeout)",
+				EnvVars: []string{"DATABASE_STATEMENT_TIMEOUT"},
+			},
+			&cli.StringFlag{
+				Name:    "config",
+				Usage:   "YAML or TOML file with settings for flags set neither on the command line nor in the environment",
+				EnvVars: []string{"SLOPTASK_CONFIG"},
 			},
 		},
 		Before: func(c *cli.Context) error {
+			if err := applyConfigFile(c, ""); err != nil {
+				return err
+			}
 			logger.Setup(logger.ParseLevel(c.String("log-level")))
 			return nil
 		},
@@ -65,18 +107,396 @@ func main() {
 						Usage:   "HTTP server port",
 						EnvVars: []string{"PORT"},
 					},
+					&cli.StringFlag{
+						Name:    "admin-token",
+						Usage:   "Token for /api/v1/admin endpoints (admin API disabled when empty)",
+						EnvVars: []string{"ADMIN_TOKEN"},
+					},
+					&cli.StringFlag{
+						Name:    "runtime-config",
+						Usage:   "JSON file with settings reloaded on SIGHUP (log_level, intake_requests_per_hour, read_only)",
+						EnvVars: []string{"RUNTIME_CONFIG"},
+					},
+					&cli.IntFlag{
+						Name:    "intake-requests-per-hour",
+						Value:   config.DefaultIntakeRequestsPerHour,
+						Usage:   "Intake submissions accepted per client IP and hour (the runtime settings file overrides it)",
+						EnvVars: []string{"INTAKE_REQUESTS_PER_HOUR"},
+					},
+					&cli.BoolFlag{
+						Name:    "read-only",
+						Usage:   "Start in read-only mode: serve reads, reject writes with 503 (the runtime settings file and PUT /api/v1/admin/read-only override it)",
+						EnvVars: []string{"READ_ONLY"},
+					},
+					&cli.DurationFlag{
+						Name:    "agent-cache-ttl",
+						Value:   30 * time.Second,
+						Usage:   "How long an agent token lookup is reused; changes made by other instances or the CLI take up to this long to apply unless --redis-url is set (0 disables the cache)",
+						EnvVars: []string{"AGENT_CACHE_TTL"},
+					},
+					&cli.DurationFlag{
+						Name:    "drain-timeout",
+						Value:   20 * time.Second,
+						Usage:   "How long shutdown waits for in-flight writes before closing the database pool (new writes get 503 meanwhile)",
+						EnvVars: []string{"DRAIN_TIMEOUT"},
+					},
+					&cli.StringFlag{
+						Name:    "tls-cert",
+						Usage:   "PEM certificate file (chain included) to serve HTTPS with, together with --tls-key",
+						EnvVars: []string{"TLS_CERT"},
+					},
+					&cli.StringFlag{
+						Name:    "tls-key",
+						Usage:   "PEM private key file of --tls-cert",
+						EnvVars: []string{"TLS_KEY"},
+					},
+					&cli.StringFlag{
+						Name:    "autocert-domains",
+						Usage:   "Comma-separated domains to get Let's Encrypt certificates for and serve HTTPS with; Let's Encrypt must reach the server on port 443",
+						EnvVars: []string{"AUTOCERT_DOMAINS"},
+					},
+					&cli.StringFlag{
+						Name:    "autocert-cache",
+						Value:   config.DefaultAutocertCacheDir,
+						Usage:   "Directory keeping Let's Encrypt certificates and the account key between restarts",
+						EnvVars: []string{"AUTOCERT_CACHE"},
+					},
+					&cli.StringFlag{
+						Name:    "autocert-email",
+						Usage:   "Contact email given to Let's Encrypt for expiry and account notices",
+						EnvVars: []string{"AUTOCERT_EMAIL"},
+					},
+					&cli.BoolFlag{
+						Name:    "gzip",
+						Usage:   "Compress responses of 1 KiB and more for clients sending Accept-Encoding: gzip, such as task lists and statistics",
+						EnvVars: []string{"GZIP"},
+					},
+					&cli.BoolFlag{
+						Name:    "access-log",
+						Usage:   "Log every request with its method, path, status, size, duration and client IP",
+						EnvVars: []string{"ACCESS_LOG"},
+					},
+					&cli.Int64Flag{
+						Name:    "max-body-bytes",
+						Value:   handler.DefaultMaxBodyBytes,
+						Usage:   "Largest JSON request body accepted; bigger ones get 413 (imports, workspace configurations and intake have their own limits)",
+						EnvVars: []string{"MAX_BODY_BYTES"},
+					},
+					&cli.StringFlag{
+						Name:    "trusted-proxies",
+						Usage:   "Comma-separated addresses or networks of load balancers and proxies whose X-Forwarded-For and X-Real-IP headers name the client, e.g. 10.0.0.0/8",
+						EnvVars: []string{"TRUSTED_PROXIES"},
+					},
+					&cli.StringFlag{
+						Name:    "cors-origins",
+						Usage:   "Comma-separated origins browsers may call the API from, e.g. https://dashboard.example.com, or * for any (cross-origin calls blocked when empty)",
+						EnvVars: []string{"CORS_ORIGINS"},
+					},
+					&cli.StringFlag{
+						Name:    "cors-methods",
+						Usage:   "Comma-separated methods allowed from --cors-origins (default: " + strings.Join(middleware.DefaultCORSMethods, ",") + ")",
+						EnvVars: []string{"CORS_METHODS"},
+					},
+					&cli.StringFlag{
+						Name:    "cors-headers",
+						Usage:   "Comma-separated request headers allowed from --cors-origins (default: " + strings.Join(middleware.DefaultCORSHeaders, ",") + ")",
+						EnvVars: []string{"CORS_HEADERS"},
+					},
+					&cli.StringFlag{
+						Name:    "redis-url",
+						Usage:   "Redis shared by server replicas for rate limits, idempotency keys, read-only mode and agent cache invalidations, e.g. redis://localhost:6379/0 (kept in memory per server when empty)",
+						EnvVars: []string{"REDIS_URL"},
+					},
 				},
 				Action: runServe,
 			},
 			{
-				Name:   "check-deadlines",
-				Usage:  "Check and update expired task deadlines",
+				Name:  "check-deadlines",
+				Usage: "Check and update expired task deadlines, warn about approaching ones, release tasks of deactivated or stale agents and age unclaimed NEW tasks",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:    "interval",
+						Usage:   "Check again after this long until SIGINT/SIGTERM (a single pass when 0, e.g. from cron)",
+						EnvVars: []string{"DEADLINE_CHECK_INTERVAL"},
+					},
+					workerRuntimeConfigFlag(),
+					workerRedisURLFlag(),
+				},
 				Action: runCheckDeadlines,
 			},
+			{
+				Name:   "auto-assign",
+				Usage:  "Assign NEW tasks to idle agents using each workspace's strategy",
+				Flags:  []cli.Flag{workerRuntimeConfigFlag(), workerRedisURLFlag()},
+				Action: runAutoAssign,
+			},
+			{
+				Name:  "scheduler",
+				Usage: "Create tasks from recurring schedules, deliver scheduled reports as they come due, send escalation notifications and publish task events to event webhooks",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:    "interval",
+						Value:   time.Minute,
+						Usage:   "How often to check for due schedules",
+						EnvVars: []string{"SCHEDULER_INTERVAL"},
+					},
+					&cli.BoolFlag{
+						Name:  "once",
+						Usage: "Run due schedules, reports, escalation and event deliveries once and exit (for use from cron)",
+					},
+					&cli.StringFlag{
+						Name:    "webhook-secret",
+						Usage:   "Secret signing report and escalation webhook deliveries of workspaces without their own secret (unsigned when empty)",
+						EnvVars: []string{"WEBHOOK_SECRET"},
+					},
+					&cli.StringFlag{
+						Name:    "smtp-addr",
+						Usage:   "SMTP relay host:port for email reports and escalations (email deliveries fail when empty)",
+						EnvVars: []string{"SMTP_ADDR"},
+					},
+					&cli.StringFlag{
+						Name:    "smtp-from",
+						Usage:   "Sender address of email reports and escalations",
+						EnvVars: []string{"SMTP_FROM"},
+					},
+					&cli.StringFlag{
+						Name:    "smtp-username",
+						Usage:   "SMTP username (PLAIN auth when set)",
+						EnvVars: []string{"SMTP_USERNAME"},
+					},
+					&cli.StringFlag{
+						Name:    "smtp-password",
+						Usage:   "SMTP password",
+						EnvVars: []string{"SMTP_PASSWORD"},
+					},
+					&cli.StringFlag{
+						Name:    "broker-url",
+						Usage:   "Broker publishing task events of workspaces with a broker topic: nats://host:4222 or kafka://host:9092,host:9092 (broker events stay queued when empty)",
+						EnvVars: []string{"EVENT_BROKER_URL"},
+					},
+				},
+				Action: runScheduler,
+			},
+			{
+				Name:  "purge",
+				Usage: "Hard-delete soft-deleted tasks and their events, and expired sandboxes",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:     "older-than",
+						Usage:    "Only purge tasks deleted at least this long ago (e.g. 720h)",
+						Required: true,
+					},
+				},
+				Action: runPurge,
+			},
+			{
+				Name:  "prune-events",
+				Usage: "Delete task events past their retention to bound the history's size",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:    "older-than",
+						Usage:   "Retention: delete events recorded at least this long ago (e.g. 2160h); required",
+						EnvVars: []string{"EVENT_RETENTION"},
+					},
+					&cli.StringFlag{
+						Name:    "workspace",
+						Aliases: []string{"w"},
+						Usage:   "Only prune events of this workspace (slug); all workspaces when empty",
+					},
+				},
+				Action: runPruneEvents,
+			},
+			{
+				Name:  "export",
+				Usage: "Dump a workspace's agents, tasks and events for backup or migration",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workspace",
+						Aliases:  []string{"w"},
+						Usage:    "Slug of the workspace to export",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: dto.ExportFormatJSON,
+						Usage: "Output format: json or ndjson (one record per line)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "-",
+						Usage:   "File to write, - for stdout",
+					},
+				},
+				Action: runExport,
+			},
+			{
+				Name:  "event-log",
+				Usage: "Write a workspace's task events as a hash-chained log for audits",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workspace",
+						Aliases:  []string{"w"},
+						Usage:    "Slug of the workspace",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "-",
+						Usage:   "File to write, - for stdout",
+					},
+					&cli.BoolFlag{
+						Name:  "append",
+						Usage: "Verify the existing --output file against the database and append only the new records",
+					},
+				},
+				Action: runEventLog,
+			},
+			{
+				Name:  "verify-event-log",
+				Usage: "Check the hash chain of an event log (no database needed)",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "input",
+						Aliases: []string{"i"},
+						Value:   "-",
+						Usage:   "File to read, - for stdin",
+					},
+				},
+				Action: runVerifyEventLog,
+			},
+			{
+				Name:  "import",
+				Usage: "Create tasks in a workspace from an export or a CSV file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workspace",
+						Aliases:  []string{"w"},
+						Usage:    "Slug of the workspace to import into",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "creator",
+						Usage:    "ID of the agent recorded as creator of the imported tasks",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Input format: csv, json or ndjson (default: from the file extension, json for stdin)",
+					},
+					&cli.StringFlag{
+						Name:    "input",
+						Aliases: []string{"i"},
+						Value:   "-",
+						Usage:   "File to read, - for stdin",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Validate every row without creating tasks",
+					},
+				},
+				Action: runImport,
+			},
+			{
+				Name:  "delete-workspace",
+				Usage: "Archive a workspace, export it and delete it with all its data",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "workspace",
+						Aliases:  []string{"w"},
+						Usage:    "Slug of the workspace to delete",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "NDJSON file receiving the final export, - for stdout",
+					},
+					&cli.StringFlag{
+						Name:  "reason",
+						Usage: "Reason recorded in the audit log and on disabled schedules and reports",
+					},
+					&cli.BoolFlag{
+						Name:  "archive-only",
+						Usage: "Only archive the workspace (revoke tokens, disable schedules and reports)",
+					},
+					&cli.BoolFlag{
+						Name:  "skip-export",
+						Usage: "Delete without exporting first",
+					},
+					&cli.IntFlag{
+						Name:  "batch-size",
+						Value: service.DefaultDeleteBatchSize,
+						Usage: "Tasks deleted per transaction",
+					},
+					&cli.StringFlag{
+						Name:    "redis-url",
+						Usage:   "Redis of the server replicas, to lock the workspace's agents out of their caches at once",
+						EnvVars: []string{"REDIS_URL"},
+					},
+				},
+				Action: runDeleteWorkspace,
+			},
+			{
+				Name:  "seed",
+				Usage: "Create a demo workspace with agents and a graph of tasks to try sloptask out",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "slug",
+						Value: service.DefaultSeedSlug,
+						Usage: "Slug of the demo workspace",
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "Demo",
+						Usage: "Name of the demo workspace",
+					},
+				},
+				Action: runSeed,
+			},
+			{
+				Name:  "tui",
+				Usage: "Terminal console for a running server: live board, task details and operator actions",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "server",
+						Value:   "http://localhost:" + config.DefaultPort,
+						Usage:   "Base URL of the sloptask server",
+						EnvVars: []string{"SLOPTASK_URL"},
+					},
+					&cli.StringFlag{
+						Name:     "token",
+						Usage:    "Admin token (operator actions), read token or agent token",
+						EnvVars:  []string{"SLOPTASK_TOKEN"},
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:    "workspace",
+						Aliases: []string{"w"},
+						Usage:   "Workspace slug or ID; required with the admin token",
+						EnvVars: []string{"SLOPTASK_WORKSPACE"},
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Value: 5 * time.Second,
+						Usage: "How often the board refreshes",
+					},
+				},
+				Action: runTUI,
+			},
 		},
 		Action: runServe,
 	}
 
+	// Each command reads its own settings from the config file
+	for _, command := range app.Commands {
+		name := command.Name
+		command.Before = func(c *cli.Context) error {
+			return applyConfigFile(c, name)
+		}
+	}
+
 	if err := app.Run(os.Args); err != nil {
 		slog.Error("application error", "error", err)
 		os.Exit(1)
@@ -90,9 +510,35 @@ func runServe(c *cli.Context) error {
 	if port == "" {
 		port = config.DefaultPort
 	}
-	databaseURL := c.String("database-url")
+	databaseURL, err := requireDatabaseURL(c)
+	if err != nil {
+		return err
+	}
+	tlsServerConfig, err := tlsConfig(c).ServerConfig()
+	if err != nil {
+		return err
+	}
+	trustedProxies, err := middleware.NewTrustedProxies(splitList(c.String("trusted-proxies")))
+	if err != nil {
+		return err
+	}
+
+	// The settings file overrides the flags; a reload falls back to them for
+	// settings removed from the file
+	runtimeBase := config.DefaultRuntime()
+	runtimeBase.LogLevel = c.String("log-level")
+	runtimeBase.IntakeRequestsPerHour = c.Int("intake-requests-per-hour")
+	runtimeBase.ReadOnly = c.Bool("read-only")
+	runtime, err := config.NewRuntimeStore(c.String("runtime-config"), runtimeBase)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(logger.ParseLevel(runtime.Current().LogLevel))
+	runtime.OnChange(func(settings config.Runtime) {
+		logger.SetLevel(logger.ParseLevel(settings.LogLevel))
+	})
 
-	db, err := database.New(ctx, databaseURL)
+	db, err := database.New(ctx, databaseURL, poolConfig(c))
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -102,27 +548,107 @@ func runServe(c *cli.Context) error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	h := handler.New(db.Pool())
+	var shared coordination.Backend
+	if redisURL := c.String("redis-url"); redisURL != "" {
+		redis, err := coordination.NewRedis(ctx, redisURL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		defer func() {
+			if err := redis.Close(); err != nil {
+				slog.Error("failed to close redis", "error", err)
+			}
+		}()
+		shared = redis
+	}
+
+	// Long-polling requests wake on task changes from any process
+	feedCtx, stopFeed := context.WithCancel(ctx)
+	defer stopFeed()
+	changeFeed := service.NewChangeFeed(repository.NewTaskEventRepository(db.Pool()))
+	go changeFeed.Run(feedCtx)
+
+	drain := middleware.NewDrain()
+	readOnly := middleware.NewReadOnly(runtime.Current().ReadOnly, shared)
+	if err := readOnly.Sync(ctx); err != nil {
+		slog.Warn("failed to share read-only mode with the other replicas", "error", err)
+	}
+	if readOnly.Enabled() {
+		slog.Warn("starting in read-only mode, writes are rejected")
+	}
+	cors := middleware.NewCORS(
+		splitList(c.String("cors-origins")),
+		splitList(c.String("cors-methods")),
+		splitList(c.String("cors-headers")),
+	)
+	maxBodyBytes := c.Int64("max-body-bytes")
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = handler.DefaultMaxBodyBytes
+	}
+	h := handler.New(db.Pool(), handler.Config{
+		AdminToken:    c.String("admin-token"),
+		ChangeFeed:    changeFeed,
+		Runtime:       runtime,
+		AgentCacheTTL: c.Duration("agent-cache-ttl"),
+		Shared:        shared,
+		Drain:         drain,
+		ReadOnly:      readOnly,
+		MaxBodyBytes:  maxBodyBytes,
+	})
 
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
+	// Replayed responses are kept uncompressed, as the handlers wrote them
+	routes := middleware.NewIdempotency(shared, maxBodyBytes).Handle(mux)
+	if c.Bool("gzip") {
+		routes = middleware.NewCompress(0).Handle(routes)
+	}
+
+	// Requests rejected by CORS, read-only mode or draining are logged too
+	served := cors.Handle(middleware.PropagateTrace(readOnly.Guard(drain.Track(routes))))
+	if c.Bool("access-log") {
+		served = middleware.NewAccessLog(nil).Handle(served)
+	}
 
 	server := &http.Server{
 		Addr:              ":" + port,
-		Handler:           mux,
+		Handler:           trustedProxies.Resolve(served),
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      30 * time.Second,
 		IdleTimeout:       60 * time.Second,
 		ReadHeaderTimeout: 5 * time.Second,
+		TLSConfig:         tlsServerConfig,
 	}
+	// Pending long-polls answer 204 instead of holding up the shutdown
+	server.RegisterOnShutdown(changeFeed.Close)
 
 	serverErr := make(chan error, 1)
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
 	go func() {
-		slog.Info("starting server", "server_addr", "http://localhost:"+port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		for range reload {
+			// A failed reload keeps the running settings
+			if _, _, err := h.ReloadRuntimeConfig(ctx, handler.ReloadSourceSignal); err != nil {
+				slog.Error("failed to reload runtime settings", "error", err)
+			}
+		}
+	}()
+
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			slog.Info("starting server", "server_addr", "https://localhost:"+port)
+			// The certificates come from server.TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("starting server", "server_addr", "http://localhost:"+port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 	}()
@@ -134,6 +660,17 @@ func runServe(c *cli.Context) error {
 		slog.Info("shutting down server")
 	}
 
+	// Writes in flight commit or roll back before the pool closes; new ones get
+	// 503 and reads are still served meanwhile
+	drainCtx, cancelDrain := context.WithTimeout(ctx, c.Duration("drain-timeout"))
+	pending, err := drain.Wait(drainCtx)
+	cancelDrain()
+	if err != nil {
+		slog.Warn("drain timed out, shutting down with writes in flight", "in_flight", pending)
+	} else {
+		slog.Info("in-flight writes drained")
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -145,34 +682,187 @@ func runServe(c *cli.Context) error {
 	return nil
 }
 
-func runCheckDeadlines(c *cli.Context) error {
-	ctx := c.Context
+// requireDatabaseURL returns the database URL, which every command but tui needs.
+func requireDatabaseURL(c *cli.Context) (string, error) {
 	databaseURL := c.String("database-url")
+	if databaseURL == "" {
+		return "", errors.New("database URL is required: set --database-url or DATABASE_URL")
+	}
+	return databaseURL, nil
+}
+
+// poolConfig returns the database pool configuration set by the global flags.
+func poolConfig(c *cli.Context) database.PoolConfig {
+	return database.PoolConfig{
+		MaxConns:         int32(c.Int("db-max-conns")),
+		MinConns:         int32(c.Int("db-min-conns")),
+		MaxConnLifetime:  c.Duration("db-max-conn-lifetime"),
+		StatementTimeout: c.Duration("db-statement-timeout"),
+	}
+}
+
+// tlsConfig returns the TLS settings of the serve flags.
+func tlsConfig(c *cli.Context) config.TLS {
+	return config.TLS{
+		CertFile:         c.String("tls-cert"),
+		KeyFile:          c.String("tls-key"),
+		AutocertDomains:  splitList(c.String("autocert-domains")),
+		AutocertCacheDir: c.String("autocert-cache"),
+		AutocertEmail:    c.String("autocert-email"),
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyConfigFile fills the flags of command (the global flags when empty) that
+// are set neither on the command line nor in the environment from the --config
+// file, if one is given.
+func applyConfigFile(c *cli.Context, command string) error {
+	path := c.String("config")
+	if path == "" {
+		return nil
+	}
 
-	db, err := database.New(ctx, databaseURL)
+	file, err := config.LoadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
+	}
+
+	for name, value := range file.FlagValues(command) {
+		if c.IsSet(name) {
+			continue
+		}
+		if err := c.Set(name, value); err != nil {
+			return fmt.Errorf("config file %s: invalid value %q for --%s: %w", path, value, name, err)
+		}
+	}
+
+	return nil
+}
+
+// openDatabase connects to the database and applies migrations for worker commands.
+// The caller closes the returned DB.
+func openDatabase(c *cli.Context) (*database.DB, error) {
+	ctx := c.Context
+	databaseURL, err := requireDatabaseURL(c)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.New(ctx, databaseURL, poolConfig(c))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer db.Close()
 
 	if err := database.RunMigrations(ctx, db.Pool()); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	return db, nil
+}
+
+// newWorkspaceService builds a WorkspaceService on the given pool.
+func newWorkspaceService(pool *pgxpool.Pool) *service.WorkspaceService {
+	return service.NewWorkspaceService(
+		pool,
+		repository.NewWorkspaceRepository(pool),
+		repository.NewAgentRepository(pool),
+		repository.NewReadTokenRepository(pool),
+		repository.NewScheduleRepository(pool),
+		repository.NewReportRepository(pool),
+		repository.NewAuditRepository(pool),
+	)
+}
+
+// newTaskService builds a TaskService on the given pool.
+func newTaskService(pool *pgxpool.Pool) *service.TaskService {
 	// Create repositories
-	taskRepo := repository.NewTaskRepository(db.Pool())
-	eventRepo := repository.NewTaskEventRepository(db.Pool())
-	agentRepo := repository.NewAgentRepository(db.Pool())
-	workspaceRepo := repository.NewWorkspaceRepository(db.Pool())
+	taskRepo := repository.NewTaskRepository(pool)
+	eventRepo := repository.NewTaskEventRepository(pool)
+	agentRepo := repository.NewAgentRepository(pool)
+	workspaceRepo := repository.NewWorkspaceRepository(pool)
+	checklistRepo := repository.NewChecklistRepository(pool)
+	queueRepo := repository.NewQueueRepository(pool)
+	labelRepo := repository.NewLabelRepository(pool)
+	escalationRepo := repository.NewEscalationRepository(pool)
 
 	// Create service
-	taskService := service.NewTaskService(
-		db.Pool(),
+	return service.NewTaskService(
+		pool,
 		taskRepo,
 		eventRepo,
 		agentRepo,
 		workspaceRepo,
+		checklistRepo,
+		queueRepo,
+		labelRepo,
+		escalationRepo,
+		repository.NewWebhookSecretRepository(pool),
 	)
+}
+
+func runCheckDeadlines(c *cli.Context) error {
+	interval := c.Duration("interval")
+	if interval < 0 {
+		return fmt.Errorf("interval must not be negative, got %s", interval)
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pause, closePause, err := openWorkerPause(c)
+	if err != nil {
+		return err
+	}
+	defer closePause()
+
+	taskService := newTaskService(db.Pool())
+
+	if interval == 0 {
+		return checkDeadlines(c.Context, taskService, pause)
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("starting deadline checker", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// A failed pass is retried on the next tick rather than stopping the worker
+		if err := checkDeadlines(ctx, taskService, pause); err != nil && ctx.Err() == nil {
+			slog.Error("deadline check pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("deadline checker stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDeadlines runs one pass of check-deadlines, unless the server is in
+// read-only mode.
+func checkDeadlines(ctx context.Context, taskService *service.TaskService, pause *workerPause) error {
+	if skip, err := pause.skip(ctx, "check-deadlines"); skip || err != nil {
+		return err
+	}
 
 	// Process expired deadlines
 	slog.Info("checking for expired task deadlines")
@@ -182,5 +872,872 @@ func runCheckDeadlines(c *cli.Context) error {
 	}
 
 	slog.Info("deadline checker completed", "tasks_updated", count)
+
+	warned, err := taskService.WarnApproachingDeadlines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to warn about approaching deadlines: %w", err)
+	}
+
+	slog.Info("deadline warnings completed", "tasks_warned", warned)
+
+	// Released tasks are back in NEW in time for auto-assignment to hand them out again
+	released, err := taskService.ReleaseAbandonedTasks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to release abandoned tasks: %w", err)
+	}
+
+	slog.Info("abandoned task release completed", "tasks_released", released)
+
+	// Aged tasks rank higher before auto-assignment picks the next ones
+	aged, err := taskService.AgeWaitingTasks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to age waiting tasks: %w", err)
+	}
+
+	slog.Info("priority aging completed", "tasks_aged", aged)
+
+	// Auto-assignment runs on the same schedule; workspaces without a strategy are skipped
+	return autoAssign(ctx, taskService)
+}
+
+func runAutoAssign(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pause, closePause, err := openWorkerPause(c)
+	if err != nil {
+		return err
+	}
+	defer closePause()
+
+	if skip, err := pause.skip(c.Context, "auto-assign"); skip || err != nil {
+		return err
+	}
+
+	taskService := newTaskService(db.Pool())
+
+	return autoAssign(c.Context, taskService)
+}
+
+// workerRuntimeConfigFlag points a worker command at the server's runtime
+// settings file, to pause while it sets read_only.
+func workerRuntimeConfigFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "runtime-config",
+		Usage:   "The server's runtime settings file; passes are skipped while it sets read_only",
+		EnvVars: []string{"RUNTIME_CONFIG"},
+	}
+}
+
+// workerRedisURLFlag points a worker command at the Redis of the server
+// replicas, to pause while they are switched to read-only mode.
+func workerRedisURLFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "redis-url",
+		Usage:   "Redis of the server replicas; passes are skipped while PUT /admin/read-only has switched them to read-only",
+		EnvVars: []string{"REDIS_URL"},
+	}
+}
+
+// workerPause tells a worker to skip its passes while the server is in
+// read-only mode: agents can neither heartbeat nor change status then, so
+// releasing or expiring their tasks would punish them for the maintenance.
+// The mode is read on every pass, so a switch applies without a signal.
+type workerPause struct {
+	runtimeConfig string               // the server's runtime settings file, if any
+	shared        coordination.Backend // the replicas' Redis, if any
+}
+
+// openWorkerPause connects to what the worker flags point at. The returned
+// function closes the connection.
+func openWorkerPause(c *cli.Context) (*workerPause, func(), error) {
+	pause := &workerPause{runtimeConfig: c.String("runtime-config")}
+	redisURL := c.String("redis-url")
+	if redisURL == "" {
+		return pause, func() {}, nil
+	}
+
+	redis, err := coordination.NewRedis(c.Context, redisURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	pause.shared = redis
+	return pause, func() {
+		if err := redis.Close(); err != nil {
+			slog.Error("failed to close redis", "error", err)
+		}
+	}, nil
+}
+
+// skip reports whether a pass of command must be skipped, because the runtime
+// settings file sets read_only or the replicas were switched to read-only.
+func (p *workerPause) skip(ctx context.Context, command string) (bool, error) {
+	readOnly := false
+	if p.runtimeConfig != "" {
+		settings, err := config.LoadRuntime(p.runtimeConfig, config.DefaultRuntime())
+		if err != nil {
+			return false, err
+		}
+		readOnly = settings.ReadOnly
+	}
+	if !readOnly && p.shared != nil {
+		shared, err := middleware.SharedReadOnly(ctx, p.shared)
+		if err != nil {
+			return false, fmt.Errorf("failed to read the read-only mode: %w", err)
+		}
+		readOnly = shared
+	}
+
+	if readOnly {
+		slog.Warn("server is read-only, skipping pass", "command", command)
+	}
+	return readOnly, nil
+}
+
+// autoAssign hands NEW tasks to idle agents in workspaces with a strategy enabled.
+func autoAssign(ctx context.Context, taskService *service.TaskService) error {
+	slog.Info("auto-assigning new tasks")
+	count, err := taskService.AutoAssign(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to auto-assign tasks: %w", err)
+	}
+
+	slog.Info("auto-assignment completed", "tasks_assigned", count)
+	return nil
+}
+
+func runScheduler(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pool := db.Pool()
+	scheduleService := service.NewScheduleService(
+		pool,
+		repository.NewScheduleRepository(pool),
+		newTaskService(pool),
+	)
+	delivery := service.ReportDeliveryConfig{
+		WebhookSecret: c.String("webhook-secret"),
+		SMTPAddr:      c.String("smtp-addr"),
+		SMTPFrom:      c.String("smtp-from"),
+		SMTPUsername:  c.String("smtp-username"),
+		SMTPPassword:  c.String("smtp-password"),
+	}
+	reportService := service.NewReportService(
+		pool,
+		repository.NewReportRepository(pool),
+		repository.NewTaskRepository(pool),
+		repository.NewWorkspaceRepository(pool),
+		repository.NewWebhookSecretRepository(pool),
+		delivery,
+	)
+	escalationService := service.NewEscalationService(
+		pool,
+		repository.NewEscalationRepository(pool),
+		repository.NewAgentRepository(pool),
+		repository.NewWorkspaceRepository(pool),
+		repository.NewWebhookSecretRepository(pool),
+		delivery,
+	)
+	var broker service.EventBroker
+	if brokerURL := c.String("broker-url"); brokerURL != "" {
+		broker, err = service.NewEventBroker(brokerURL)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := broker.Close(); err != nil {
+				slog.Error("failed to close event broker", "error", err)
+			}
+		}()
+	}
+	outboxService := service.NewOutboxService(
+		pool,
+		repository.NewOutboxRepository(pool),
+		repository.NewWebhookSecretRepository(pool),
+		delivery,
+		broker,
+	)
+
+	if c.Bool("once") {
+		if err := runDueSchedules(c.Context, scheduleService); err != nil {
+			return err
+		}
+		if err := runDueReports(c.Context, reportService); err != nil {
+			return err
+		}
+		if err := runEscalationDeliveries(c.Context, escalationService); err != nil {
+			return err
+		}
+		return runOutboxDeliveries(c.Context, outboxService)
+	}
+
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval)
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Escalations and task events are delivered as soon as they commit instead of
+	// on the next tick
+	changeFeed := service.NewChangeFeed(repository.NewTaskEventRepository(pool))
+	go changeFeed.Run(ctx)
+	changes, unsubscribe := changeFeed.Subscribe("")
+	defer unsubscribe()
+
+	slog.Info("starting scheduler", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// A failed pass is retried on the next tick rather than stopping the worker
+		if err := runDueSchedules(ctx, scheduleService); err != nil && ctx.Err() == nil {
+			slog.Error("scheduler pass failed", "error", err)
+		}
+		if err := runDueReports(ctx, reportService); err != nil && ctx.Err() == nil {
+			slog.Error("report pass failed", "error", err)
+		}
+		if err := runEscalationDeliveries(ctx, escalationService); err != nil && ctx.Err() == nil {
+			slog.Error("escalation delivery pass failed", "error", err)
+		}
+		if err := runOutboxDeliveries(ctx, outboxService); err != nil && ctx.Err() == nil {
+			slog.Error("event delivery pass failed", "error", err)
+		}
+
+		if !waitForSchedulerPass(ctx, ticker, changes, escalationService, outboxService) {
+			slog.Info("scheduler stopped")
+			return nil
+		}
+	}
+}
+
+// waitForSchedulerPass waits for the next tick, publishing task events whenever a
+// task changes in the meantime and delivering escalations whenever one is
+// escalated. Returns false once ctx is done.
+func waitForSchedulerPass(
+	ctx context.Context,
+	ticker *time.Ticker,
+	changes <-chan *domain.TaskChange,
+	escalationService *service.EscalationService,
+	outboxService *service.OutboxService,
+) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			return true
+		case change := <-changes:
+			if err := runOutboxDeliveries(ctx, outboxService); err != nil && ctx.Err() == nil {
+				slog.Error("event delivery pass failed", "error", err)
+			}
+			if change.Type != domain.EventTypeEscalated {
+				continue
+			}
+			if err := runEscalationDeliveries(ctx, escalationService); err != nil && ctx.Err() == nil {
+				slog.Error("escalation delivery pass failed", "error", err)
+			}
+		}
+	}
+}
+
+// runDueSchedules creates the tasks of all schedules that are due.
+func runDueSchedules(ctx context.Context, scheduleService *service.ScheduleService) error {
+	count, err := scheduleService.RunDueSchedules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run schedules: %w", err)
+	}
+
+	if count > 0 {
+		slog.Info("scheduled tasks created", "tasks_created", count)
+	}
+	return nil
+}
+
+// runDueReports delivers all scheduled reports that are due.
+func runDueReports(ctx context.Context, reportService *service.ReportService) error {
+	count, err := reportService.RunDueReports(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run reports: %w", err)
+	}
+
+	if count > 0 {
+		slog.Info("scheduled reports delivered", "reports_delivered", count)
+	}
+	return nil
+}
+
+// runEscalationDeliveries delivers the webhook and email escalation notifications that are due.
+func runEscalationDeliveries(ctx context.Context, escalationService *service.EscalationService) error {
+	count, err := escalationService.DeliverPendingEscalations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to deliver escalations: %w", err)
+	}
+
+	if count > 0 {
+		slog.Info("escalation notifications delivered", "notifications_delivered", count)
+	}
+	return nil
+}
+
+// runOutboxDeliveries publishes the task events queued for event webhooks and
+// broker topics that are due.
+func runOutboxDeliveries(ctx context.Context, outboxService *service.OutboxService) error {
+	count, err := outboxService.PublishPending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to publish events: %w", err)
+	}
+
+	if count > 0 {
+		slog.Info("task events published", "events_published", count)
+	}
+	return nil
+}
+
+func runPurge(c *cli.Context) error {
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	taskService := newTaskService(db.Pool())
+
+	olderThan := c.Duration("older-than")
+	slog.Info("purging deleted tasks", "older_than", olderThan)
+	count, err := taskService.PurgeDeletedTasks(c.Context, olderThan)
+	if err != nil {
+		return fmt.Errorf("failed to purge deleted tasks: %w", err)
+	}
+
+	sandboxes, err := newWorkspaceService(db.Pool()).PurgeExpiredSandboxes(c.Context, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to purge expired sandboxes: %w", err)
+	}
+
+	slog.Info("purge completed", "tasks_purged", count, "sandboxes_purged", sandboxes)
+	return nil
+}
+
+func runPruneEvents(c *cli.Context) error {
+	ctx := c.Context
+
+	// The checkpoint must only cover settled events, which no late commit or
+	// comment edit can change anymore
+	olderThan := c.Duration("older-than")
+	if olderThan < dto.EventLogSettleTime {
+		return fmt.Errorf("--older-than must be at least %s, got %s", dto.EventLogSettleTime, olderThan)
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspaceRepo := repository.NewWorkspaceRepository(db.Pool())
+	var workspaces []*domain.Workspace
+	if slug := c.String("workspace"); slug != "" {
+		workspace, err := workspaceRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return fmt.Errorf("failed to find workspace %q: %w", slug, err)
+		}
+		workspaces = append(workspaces, workspace)
+	} else {
+		if workspaces, err = workspaceRepo.List(ctx); err != nil {
+			return fmt.Errorf("failed to list workspaces: %w", err)
+		}
+	}
+
+	exportRepo := repository.NewExportRepository(db.Pool())
+	taskService := newTaskService(db.Pool())
+	before := time.Now().Add(-olderThan)
+	slog.Info("pruning task events", "older_than", olderThan, "workspaces", len(workspaces))
+
+	var total int64
+	for _, workspace := range workspaces {
+		checkpoint, err := checkpointEventLog(ctx, exportRepo, workspace.ID, before)
+		if err != nil {
+			return fmt.Errorf("failed to checkpoint event log of workspace %q: %w", workspace.Slug, err)
+		}
+
+		count, err := taskService.PruneEvents(ctx, workspace.ID, before)
+		total += count
+		if err != nil {
+			return fmt.Errorf("failed to prune task events of workspace %q: %w", workspace.Slug, err)
+		}
+
+		slog.Info("pruned workspace events",
+			"workspace_id", workspace.ID,
+			"slug", workspace.Slug,
+			"events_pruned", count,
+			"checkpoint_seq", checkpoint.Seq,
+			"checkpoint_hash", checkpoint.Hash,
+		)
+	}
+
+	slog.Info("prune completed", "events_pruned", total)
+	return nil
+}
+
+// checkpointEventLog records the event log chain over the workspace's events
+// recorded before before, so the log can be extended once they are pruned.
+func checkpointEventLog(ctx context.Context, exportRepo *repository.ExportRepository, workspaceID string, before time.Time) (*domain.EventLogCheckpoint, error) {
+	checkpoint, err := exportRepo.EventLogCheckpoint(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil && !before.After(checkpoint.Before) {
+		return checkpoint, nil
+	}
+
+	events, err := exportRepo.EventLog(ctx, workspaceID, checkpoint, before)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := dto.NextEventLogCheckpoint(workspaceID, events, checkpoint, before)
+	if err != nil {
+		return nil, err
+	}
+	if err := exportRepo.SaveEventLogCheckpoint(ctx, next); err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+func runExport(c *cli.Context) error {
+	ctx := c.Context
+
+	format := c.String("format")
+	if format != dto.ExportFormatJSON && format != dto.ExportFormatNDJSON {
+		return fmt.Errorf("format must be json or ndjson, got %q", format)
+	}
+
+	// Keep stdout for the export itself
+	output := c.String("output")
+	if output == "-" {
+		logger.SetupWriter(os.Stderr, logger.ParseLevel(c.String("log-level")))
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspace, err := repository.NewWorkspaceRepository(db.Pool()).GetBySlug(ctx, c.String("workspace"))
+	if err != nil {
+		return fmt.Errorf("failed to find workspace %q: %w", c.String("workspace"), err)
+	}
+
+	return exportWorkspace(ctx, db.Pool(), workspace, output, format)
+}
+
+// exportWorkspace writes a workspace snapshot to output (- for stdout) and
+// records the export in the admin audit log.
+func exportWorkspace(ctx context.Context, pool *pgxpool.Pool, workspace *domain.Workspace, output, format string) error {
+	snapshot, err := repository.NewExportRepository(pool).Snapshot(ctx, workspace.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read workspace snapshot: %w", err)
+	}
+
+	if output == "-" {
+		if err := writeExport(os.Stdout, snapshot, format); err != nil {
+			return err
+		}
+	} else {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		if err := writeExport(file, snapshot, format); err != nil {
+			file.Close()
+			os.Remove(output)
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to close export file: %w", err)
+		}
+	}
+
+	err = repository.NewAuditRepository(pool).Record(ctx, &domain.AuditEntry{
+		Action:      domain.AuditWorkspaceExported,
+		WorkspaceID: &workspace.ID,
+		Details: map[string]any{
+			"source":       "cli",
+			"output":       output,
+			"format":       format,
+			"snapshot_lsn": snapshot.Manifest.SnapshotLSN,
+			"tasks":        snapshot.Manifest.TaskCount,
+			"events":       snapshot.Manifest.EventCount,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record export: %w", err)
+	}
+
+	slog.Info("workspace exported",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"output", output,
+		"format", format,
+		"snapshot_lsn", snapshot.Manifest.SnapshotLSN,
+		"agents", snapshot.Manifest.AgentCount,
+		"tasks", snapshot.Manifest.TaskCount,
+		"events", snapshot.Manifest.EventCount,
+	)
+	return nil
+}
+
+func runEventLog(c *cli.Context) error {
+	ctx := c.Context
+
+	// Keep stdout for the log itself
+	output := c.String("output")
+	appendLog := c.Bool("append")
+	if output == "-" {
+		if appendLog {
+			return errors.New("--append needs an --output file")
+		}
+		logger.SetupWriter(os.Stderr, logger.ParseLevel(c.String("log-level")))
+	}
+
+	// Check what is already stored before reading the database, so a damaged
+	// file is reported as such
+	stored := &dto.EventLogSummary{Head: dto.EventLogGenesisHash}
+	if appendLog {
+		file, err := os.Open(output)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to open event log: %w", err)
+		}
+		if err == nil {
+			stored, err = dto.VerifyEventLog(file)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("stored event log is not intact: %w", err)
+			}
+		}
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspace, err := repository.NewWorkspaceRepository(db.Pool()).GetBySlug(ctx, c.String("workspace"))
+	if err != nil {
+		return fmt.Errorf("failed to find workspace %q: %w", c.String("workspace"), err)
+	}
+
+	exportRepo := repository.NewExportRepository(db.Pool())
+	checkpoint, err := exportRepo.EventLogCheckpoint(ctx, workspace.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read event log checkpoint: %w", err)
+	}
+
+	events, err := exportRepo.EventLog(ctx, workspace.ID, checkpoint, time.Now().Add(-dto.EventLogSettleTime))
+	if err != nil {
+		return fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	// A new log after pruning starts at the checkpoint
+	if stored.Records > 0 {
+		head, err := dto.EventLogHead(events, checkpoint, stored.LastSeq)
+		if err != nil {
+			return fmt.Errorf("stored event log cannot be extended: %w", err)
+		}
+		if head != stored.Head {
+			return fmt.Errorf("stored event log differs from the database at seq %d: events were changed or removed", stored.LastSeq)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "-" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if appendLog {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		file, err := os.OpenFile(output, flags, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open event log: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	buf := bufio.NewWriter(out)
+	written, head, err := dto.WriteEventLog(buf, events, checkpoint, stored.LastSeq)
+	if err != nil {
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+
+	err = repository.NewAuditRepository(db.Pool()).Record(ctx, &domain.AuditEntry{
+		Action:      domain.AuditEventLogExported,
+		WorkspaceID: &workspace.ID,
+		Details: map[string]any{
+			"source":  "cli",
+			"output":  output,
+			"events":  len(events),
+			"after":   stored.LastSeq,
+			"written": written,
+			"head":    head,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record event log export: %w", err)
+	}
+
+	slog.Info("event log exported",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"output", output,
+		"events", len(events),
+		"written", written,
+		"head", head,
+	)
+	return nil
+}
+
+func runVerifyEventLog(c *cli.Context) error {
+	var in io.Reader = os.Stdin
+	if input := c.String("input"); input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open event log: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	summary, err := dto.VerifyEventLog(in)
+	if err != nil {
+		return fmt.Errorf("event log is not intact: %w", err)
+	}
+
+	fmt.Printf("ok: %d records, seq %d-%d, head %s\n", summary.Records, summary.FirstSeq, summary.LastSeq, summary.Head)
+	return nil
+}
+
+func runDeleteWorkspace(c *cli.Context) error {
+	ctx := c.Context
+
+	output := c.String("output")
+	skipExport := c.Bool("skip-export")
+	archiveOnly := c.Bool("archive-only")
+	if output == "" && !skipExport && !archiveOnly {
+		return fmt.Errorf("--output is required unless --skip-export or --archive-only is set")
+	}
+	if output == "-" {
+		logger.SetupWriter(os.Stderr, logger.ParseLevel(c.String("log-level")))
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspaceRepo := repository.NewWorkspaceRepository(db.Pool())
+	workspaceService := newWorkspaceService(db.Pool())
+
+	slug := c.String("workspace")
+	workspace, err := workspaceRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("failed to find workspace %q: %w", slug, err)
+	}
+
+	// Archive first so the export is final and tokens stop working
+	if !workspace.IsArchived() {
+		result, err := workspaceService.ArchiveWorkspace(ctx, workspace.ID, c.String("reason"))
+		if err != nil {
+			return fmt.Errorf("failed to archive workspace: %w", err)
+		}
+		workspace = result.Workspace
+	}
+	if redisURL := c.String("redis-url"); redisURL != "" {
+		if err := broadcastWorkspaceRevoked(ctx, redisURL, workspace.ID); err != nil {
+			// The server caches expire on their own
+			slog.Warn("failed to notify servers of the revoked agents", "error", err)
+		}
+	}
+	if archiveOnly {
+		return nil
+	}
+
+	if !skipExport {
+		if err := exportWorkspace(ctx, db.Pool(), workspace, output, dto.ExportFormatNDJSON); err != nil {
+			return err
+		}
+	}
+
+	if _, err := workspaceService.DeleteWorkspace(ctx, service.DeleteWorkspaceParams{
+		WorkspaceID: workspace.ID,
+		Confirm:     slug,
+		SkipExport:  skipExport,
+		BatchSize:   c.Int("batch-size"),
+	}); err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+
+	return nil
+}
+
+// broadcastWorkspaceRevoked tells the servers sharing the Redis at redisURL to
+// drop the workspace's agents from their token caches.
+func broadcastWorkspaceRevoked(ctx context.Context, redisURL, workspaceID string) error {
+	redis, err := coordination.NewRedis(ctx, redisURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := redis.Close(); err != nil {
+			slog.Error("failed to close redis", "error", err)
+		}
+	}()
+
+	return middleware.PublishWorkspaceInvalidation(ctx, redis, workspaceID)
+}
+
+func runImport(c *cli.Context) error {
+	ctx := c.Context
+
+	input := c.String("input")
+	format := c.String("format")
+	if format == "" {
+		format = importFormatFromPath(input)
+	}
+
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	rows, err := service.ParseImport(bufio.NewReader(r), format)
+	if err != nil {
+		return fmt.Errorf("failed to read import: %w", err)
+	}
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspace, err := repository.NewWorkspaceRepository(db.Pool()).GetBySlug(ctx, c.String("workspace"))
+	if err != nil {
+		return fmt.Errorf("failed to find workspace %q: %w", c.String("workspace"), err)
+	}
+
+	result, err := newTaskService(db.Pool()).ImportTasks(ctx, service.ImportTasksParams{
+		WorkspaceID: workspace.ID,
+		CreatorID:   c.String("creator"),
+		Rows:        rows,
+		DryRun:      c.Bool("dry-run"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import tasks: %w", err)
+	}
+
+	for _, rowErr := range result.Errors {
+		slog.Error("invalid import row", "row", rowErr.Row, "error", rowErr.Message)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d of %d rows are invalid, nothing was imported", len(result.Errors), len(rows))
+	}
+
+	slog.Info("tasks imported",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"format", format,
+		"rows", len(rows),
+		"imported", len(result.Tasks),
+		"dry_run", c.Bool("dry-run"),
+	)
+	return nil
+}
+
+func runSeed(c *cli.Context) error {
+	ctx := c.Context
+
+	db, err := openDatabase(c)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pool := db.Pool()
+	result, err := service.NewSeedService(
+		pool,
+		repository.NewWorkspaceRepository(pool),
+		repository.NewAgentRepository(pool),
+		repository.NewTaskRepository(pool),
+		newTaskService(pool),
+	).Seed(ctx, service.SeedParams{Slug: c.String("slug"), Name: c.String("name")})
+	if err != nil {
+		return fmt.Errorf("failed to seed demo data: %w", err)
+	}
+
+	// Tokens go to stdout, apart from the logs, so they can be copied or piped
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Workspace %s (%s)\n\n", result.Workspace.Slug, result.Workspace.ID)
+	fmt.Fprintln(w, "AGENT\tCAPABILITIES\tTOKEN")
+	for _, agent := range result.Agents {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", agent.Name, strings.Join(agent.Capabilities, ","), agent.Token)
+	}
+	fmt.Fprintln(w, "\nTASK\tKEY\tSTATUS\tTITLE")
+	for _, task := range result.Tasks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", task.ID, task.Key, task.Status, task.Title)
+	}
+	return w.Flush()
+}
+
+func runTUI(c *cli.Context) error {
+	// Log lines would tear up the console's screen
+	logger.SetupWriter(io.Discard, logger.ParseLevel(c.String("log-level")))
+
+	client := tui.NewClient(c.String("server"), c.String("token"), c.String("workspace"))
+	return tui.Run(client, c.Duration("interval"))
+}
+
+// importFormatFromPath picks the import format from a file extension.
+func importFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return service.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return service.ImportFormatNDJSON
+	default:
+		return service.ImportFormatJSON
+	}
+}
+
+// writeExport writes a snapshot in the given format through a buffer.
+func writeExport(w io.Writer, snapshot *domain.WorkspaceSnapshot, format string) error {
+	buf := bufio.NewWriter(w)
+	if err := dto.WriteExport(buf, dto.ToWorkspaceExportResponse(snapshot), format); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
 	return nil
 }
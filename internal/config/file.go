@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a configuration file. Its settings fill the command-line flags that
+// are set neither on the command line nor in the environment. Durations are
+// written like flags take them ("30s", "5m").
+type File struct {
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+
+	Database struct {
+		URL      string `yaml:"url" toml:"url"`
+		MaxConns *int   `yaml:"max_conns" toml:"max_conns"`
+		MinConns *int   `yaml:"min_conns" toml:"min_conns"`
+
+		MaxConnLifetime  string `yaml:"max_conn_lifetime" toml:"max_conn_lifetime"`
+		StatementTimeout string `yaml:"statement_timeout" toml:"statement_timeout"`
+	} `yaml:"database" toml:"database"`
+
+	Server struct {
+		Port                  string   `yaml:"port" toml:"port"`
+		AdminToken            string   `yaml:"admin_token" toml:"admin_token"`
+		RuntimeConfig         string   `yaml:"runtime_config" toml:"runtime_config"`
+		AgentCacheTTL         string   `yaml:"agent_cache_ttl" toml:"agent_cache_ttl"`
+		DrainTimeout          string   `yaml:"drain_timeout" toml:"drain_timeout"`
+		TLSCert               string   `yaml:"tls_cert" toml:"tls_cert"`
+		TLSKey                string   `yaml:"tls_key" toml:"tls_key"`
+		AutocertDomains       []string `yaml:"autocert_domains" toml:"autocert_domains"`
+		AutocertCache         string   `yaml:"autocert_cache" toml:"autocert_cache"`
+		AutocertEmail         string   `yaml:"autocert_email" toml:"autocert_email"`
+		TrustedProxies        []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+		CORSOrigins           []string `yaml:"cors_origins" toml:"cors_origins"`
+		CORSMethods           []string `yaml:"cors_methods" toml:"cors_methods"`
+		CORSHeaders           []string `yaml:"cors_headers" toml:"cors_headers"`
+		IntakeRequestsPerHour *int     `yaml:"intake_requests_per_hour" toml:"intake_requests_per_hour"`
+		MaxBodyBytes          *int     `yaml:"max_body_bytes" toml:"max_body_bytes"`
+		Gzip                  *bool    `yaml:"gzip" toml:"gzip"`
+		AccessLog             *bool    `yaml:"access_log" toml:"access_log"`
+		ReadOnly              *bool    `yaml:"read_only" toml:"read_only"`
+	} `yaml:"server" toml:"server"`
+
+	DeadlineCheck struct {
+		Interval string `yaml:"interval" toml:"interval"`
+	} `yaml:"deadline_check" toml:"deadline_check"`
+
+	Scheduler struct {
+		Interval string `yaml:"interval" toml:"interval"`
+	} `yaml:"scheduler" toml:"scheduler"`
+
+	EventRetention struct {
+		OlderThan string `yaml:"older_than" toml:"older_than"`
+	} `yaml:"event_retention" toml:"event_retention"`
+
+	Integrations struct {
+		RedisURL      string `yaml:"redis_url" toml:"redis_url"`
+		BrokerURL     string `yaml:"broker_url" toml:"broker_url"`
+		WebhookSecret string `yaml:"webhook_secret" toml:"webhook_secret"`
+		SMTP          struct {
+			Addr     string `yaml:"addr" toml:"addr"`
+			From     string `yaml:"from" toml:"from"`
+			Username string `yaml:"username" toml:"username"`
+			Password string `yaml:"password" toml:"password"`
+		} `yaml:"smtp" toml:"smtp"`
+	} `yaml:"integrations" toml:"integrations"`
+}
+
+// LoadFile reads a YAML (.yaml, .yml) or TOML (.toml) configuration file.
+// Unknown settings are rejected so typos surface.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var file File
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// An empty document is a valid file without settings
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("parse config file %s: unknown setting %q", path, undecoded[0].String())
+		}
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+
+	return &file, nil
+}
+
+// FlagValues returns the settings of the file as flag values by flag name, for
+// the global flags when command is empty and for the command's own flags
+// otherwise. Settings left out of the file are left out.
+func (f *File) FlagValues(command string) map[string]string {
+	values := map[string]string{}
+	set := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	setInt := func(name string, value *int) {
+		if value != nil {
+			values[name] = strconv.Itoa(*value)
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			values[name] = strconv.FormatBool(*value)
+		}
+	}
+
+	switch command {
+	case "":
+		set("log-level", f.LogLevel)
+		set("database-url", f.Database.URL)
+		setInt("db-max-conns", f.Database.MaxConns)
+		setInt("db-min-conns", f.Database.MinConns)
+		set("db-max-conn-lifetime", f.Database.MaxConnLifetime)
+		set("db-statement-timeout", f.Database.StatementTimeout)
+	case "serve":
+		set("port", f.Server.Port)
+		set("admin-token", f.Server.AdminToken)
+		set("runtime-config", f.Server.RuntimeConfig)
+		set("agent-cache-ttl", f.Server.AgentCacheTTL)
+		set("drain-timeout", f.Server.DrainTimeout)
+		set("tls-cert", f.Server.TLSCert)
+		set("tls-key", f.Server.TLSKey)
+		set("autocert-domains", strings.Join(f.Server.AutocertDomains, ","))
+		set("autocert-cache", f.Server.AutocertCache)
+		set("autocert-email", f.Server.AutocertEmail)
+		set("trusted-proxies", strings.Join(f.Server.TrustedProxies, ","))
+		set("cors-origins", strings.Join(f.Server.CORSOrigins, ","))
+		set("cors-methods", strings.Join(f.Server.CORSMethods, ","))
+		set("cors-headers", strings.Join(f.Server.CORSHeaders, ","))
+		setInt("intake-requests-per-hour", f.Server.IntakeRequestsPerHour)
+		setInt("max-body-bytes", f.Server.MaxBodyBytes)
+		setBool("gzip", f.Server.Gzip)
+		setBool("access-log", f.Server.AccessLog)
+		setBool("read-only", f.Server.ReadOnly)
+		set("redis-url", f.Integrations.RedisURL)
+	case "check-deadlines":
+		set("interval", f.DeadlineCheck.Interval)
+		set("runtime-config", f.Server.RuntimeConfig)
+		set("redis-url", f.Integrations.RedisURL)
+	case "auto-assign":
+		set("runtime-config", f.Server.RuntimeConfig)
+		set("redis-url", f.Integrations.RedisURL)
+	case "scheduler":
+		set("interval", f.Scheduler.Interval)
+		set("webhook-secret", f.Integrations.WebhookSecret)
+		set("smtp-addr", f.Integrations.SMTP.Addr)
+		set("smtp-from", f.Integrations.SMTP.From)
+		set("smtp-username", f.Integrations.SMTP.Username)
+		set("smtp-password", f.Integrations.SMTP.Password)
+		set("broker-url", f.Integrations.BrokerURL)
+	case "prune-events":
+		set("older-than", f.EventRetention.OlderThan)
+	case "delete-workspace":
+		set("redis-url", f.Integrations.RedisURL)
+	}
+
+	return values
+}
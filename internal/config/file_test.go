@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile_YAMLAndTOML(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	yamlPath := write("sloptask.yaml", `
+log_level: debug
+database:
+  url: postgres://localhost/sloptask
+  max_conns: 20
+  min_conns: 0
+  statement_timeout: 30s
+server:
+  port: "9000"
+  agent_cache_ttl: 1m
+scheduler:
+  interval: 30s
+event_retention:
+  older_than: 2160h
+integrations:
+  redis_url: redis://localhost:6379/0
+  smtp:
+    addr: smtp.example.com:587
+`)
+	tomlPath := write("sloptask.toml", `
+log_level = "debug"
+
+[database]
+url = "postgres://localhost/sloptask"
+max_conns = 20
+min_conns = 0
+statement_timeout = "30s"
+
+[server]
+port = "9000"
+agent_cache_ttl = "1m"
+
+[scheduler]
+interval = "30s"
+
+[event_retention]
+older_than = "2160h"
+
+[integrations]
+redis_url = "redis://localhost:6379/0"
+
+[integrations.smtp]
+addr = "smtp.example.com:587"
+`)
+
+	for _, path := range []string{yamlPath, tomlPath} {
+		file, err := LoadFile(path)
+		require.NoError(t, err, path)
+
+		assert.Equal(t, map[string]string{
+			"log-level":            "debug",
+			"database-url":         "postgres://localhost/sloptask",
+			"db-max-conns":         "20",
+			"db-min-conns":         "0",
+			"db-statement-timeout": "30s",
+		}, file.FlagValues(""), path)
+		assert.Equal(t, map[string]string{
+			"port":            "9000",
+			"agent-cache-ttl": "1m",
+			"redis-url":       "redis://localhost:6379/0",
+		}, file.FlagValues("serve"), path)
+		assert.Equal(t, map[string]string{
+			"interval":  "30s",
+			"smtp-addr": "smtp.example.com:587",
+		}, file.FlagValues("scheduler"), path)
+		assert.Equal(t, map[string]string{"older-than": "2160h"}, file.FlagValues("prune-events"), path)
+		assert.Equal(t, map[string]string{"redis-url": "redis://localhost:6379/0"}, file.FlagValues("check-deadlines"), path)
+	}
+
+	_, err := LoadFile(write("typo.yaml", "server:\n  prot: \"9000\"\n"))
+	assert.ErrorContains(t, err, "prot")
+	_, err = LoadFile(write("typo.toml", "[server]\nprot = \"9000\"\n"))
+	assert.ErrorContains(t, err, "server.prot")
+	_, err = LoadFile(write("sloptask.json", "{}"))
+	assert.ErrorContains(t, err, "must end in .yaml, .yml or .toml")
+
+	file, err := LoadFile(write("empty.yaml", ""))
+	require.NoError(t, err)
+	assert.Empty(t, file.FlagValues(""))
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultIntakeRequestsPerHour is the default number of intake submissions
+// accepted per client IP and hour, on top of each workspace's own limit.
+const DefaultIntakeRequestsPerHour = 5
+
+// ErrNoRuntimeFile is returned by a reload when no runtime settings file is configured.
+var ErrNoRuntimeFile = errors.New("no runtime settings file configured")
+
+// logLevels are the accepted values of Runtime.LogLevel.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Runtime holds the settings a running server applies without a restart.
+type Runtime struct {
+	LogLevel              string `json:"log_level"`
+	IntakeRequestsPerHour int    `json:"intake_requests_per_hour"`
+	ReadOnly              bool   `json:"read_only"` // reject API writes with 503, e.g. during maintenance
+}
+
+// DefaultRuntime returns the runtime settings used when nothing overrides them.
+func DefaultRuntime() Runtime {
+	return Runtime{LogLevel: "info", IntakeRequestsPerHour: DefaultIntakeRequestsPerHour}
+}
+
+// Validate checks that every setting has a usable value.
+func (r Runtime) Validate() error {
+	if !slices.Contains(logLevels, r.LogLevel) {
+		return fmt.Errorf("log_level must be one of debug, info, warn, error, got %q", r.LogLevel)
+	}
+	if r.IntakeRequestsPerHour < 1 || r.IntakeRequestsPerHour > 10000 {
+		return fmt.Errorf("intake_requests_per_hour must be between 1 and 10000, got %d", r.IntakeRequestsPerHour)
+	}
+	return nil
+}
+
+// Changes lists the settings that differ in next as {"old": ..., "new": ...}
+// pairs keyed by setting name.
+func (r Runtime) Changes(next Runtime) map[string]any {
+	changes := map[string]any{}
+	if r.LogLevel != next.LogLevel {
+		changes["log_level"] = map[string]any{"old": r.LogLevel, "new": next.LogLevel}
+	}
+	if r.IntakeRequestsPerHour != next.IntakeRequestsPerHour {
+		changes["intake_requests_per_hour"] = map[string]any{"old": r.IntakeRequestsPerHour, "new": next.IntakeRequestsPerHour}
+	}
+	if r.ReadOnly != next.ReadOnly {
+		changes["read_only"] = map[string]any{"old": r.ReadOnly, "new": next.ReadOnly}
+	}
+	return changes
+}
+
+// LoadRuntime reads a JSON settings file over base. Settings missing from the
+// file keep their base value; unknown settings are rejected so typos surface.
+func LoadRuntime(path string, base Runtime) (Runtime, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Runtime{}, fmt.Errorf("read runtime settings: %w", err)
+	}
+
+	settings := base
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return Runtime{}, fmt.Errorf("parse runtime settings %s: %w", path, err)
+	}
+	if err := settings.Validate(); err != nil {
+		return Runtime{}, fmt.Errorf("invalid runtime settings %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// RuntimeStore holds the active runtime settings. A reload reads the settings
+// file again, validates it and swaps the active settings in one step, so a bad
+// file leaves the running configuration untouched.
+type RuntimeStore struct {
+	path string
+	base Runtime
+
+	mu        sync.Mutex // serializes reloads
+	active    atomic.Pointer[Runtime]
+	listeners []func(Runtime)
+}
+
+// NewRuntimeStore creates a store for the settings file at path, with base
+// supplying the values the file leaves out. An empty path disables reloads.
+// The file is read once here; an invalid file is an error.
+func NewRuntimeStore(path string, base Runtime) (*RuntimeStore, error) {
+	settings := base
+	if path != "" {
+		var err error
+		if settings, err = LoadRuntime(path, base); err != nil {
+			return nil, err
+		}
+	} else if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	store := &RuntimeStore{path: path, base: base}
+	store.active.Store(&settings)
+	return store, nil
+}
+
+// Path returns the settings file, empty when none is configured.
+func (s *RuntimeStore) Path() string {
+	return s.path
+}
+
+// Current returns the active settings.
+func (s *RuntimeStore) Current() Runtime {
+	return *s.active.Load()
+}
+
+// OnChange registers fn to be called with the new settings after each reload
+// that changed something.
+func (s *RuntimeStore) OnChange(fn func(Runtime)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload reads the settings file again and activates it. It returns the new
+// settings and what changed; on error the active settings stay as they were.
+func (s *RuntimeStore) Reload() (Runtime, map[string]any, error) {
+	if s.path == "" {
+		return Runtime{}, nil, ErrNoRuntimeFile
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, err := LoadRuntime(s.path, s.base)
+	if err != nil {
+		return Runtime{}, nil, err
+	}
+
+	changes := s.active.Swap(&next).Changes(next)
+	if len(changes) > 0 {
+		for _, fn := range s.listeners {
+			fn(next)
+		}
+	}
+	return next, changes, nil
+}
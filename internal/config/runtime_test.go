@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write(`{"intake_requests_per_hour": 20}`)
+	store, err := NewRuntimeStore(path, DefaultRuntime())
+	require.NoError(t, err)
+	assert.Equal(t, Runtime{LogLevel: "info", IntakeRequestsPerHour: 20}, store.Current())
+
+	var applied []Runtime
+	store.OnChange(func(settings Runtime) { applied = append(applied, settings) })
+
+	write(`{"log_level": "debug", "intake_requests_per_hour": 20}`)
+	settings, changes, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", settings.LogLevel)
+	assert.Equal(t, map[string]any{"log_level": map[string]any{"old": "info", "new": "debug"}}, changes)
+	assert.Equal(t, []Runtime{settings}, applied)
+
+	// Invalid files leave the active settings alone
+	for _, content := range []string{`{"log_level": "verbose"}`, `{"intake_requests_per_hour": 0}`, `{"log_levle": "warn"}`, `{`} {
+		write(content)
+		_, _, err := store.Reload()
+		assert.Error(t, err, content)
+		assert.Equal(t, settings, store.Current())
+	}
+
+	// Unchanged settings notify nobody
+	write(`{"log_level": "debug", "intake_requests_per_hour": 20}`)
+	_, changes, err = store.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Len(t, applied, 1)
+
+	write(`{"log_level": "debug", "intake_requests_per_hour": 20, "read_only": true}`)
+	settings, changes, err = store.Reload()
+	require.NoError(t, err)
+	assert.True(t, settings.ReadOnly)
+	assert.Equal(t, map[string]any{"read_only": map[string]any{"old": false, "new": true}}, changes)
+	assert.Len(t, applied, 2)
+}
+
+func TestRuntimeStore_WithoutFile(t *testing.T) {
+	store, err := NewRuntimeStore("", DefaultRuntime())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRuntime(), store.Current())
+
+	_, _, err = store.Reload()
+	assert.ErrorIs(t, err, ErrNoRuntimeFile)
+
+	_, err = NewRuntimeStore("", Runtime{LogLevel: "loud", IntakeRequestsPerHour: 5})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultAutocertCacheDir is where Let's Encrypt certificates are kept between
+// restarts unless configured otherwise.
+const DefaultAutocertCacheDir = "autocert-cache"
+
+// TLS selects how the server terminates TLS: with a certificate and key from
+// files, with certificates obtained from Let's Encrypt for the given domains,
+// or not at all when nothing is set.
+type TLS struct {
+	CertFile string
+	KeyFile  string
+
+	AutocertDomains  []string
+	AutocertCacheDir string // DefaultAutocertCacheDir when empty
+	AutocertEmail    string // optional contact for expiry notices
+}
+
+// ServerConfig returns the TLS configuration for the server, or nil when TLS
+// is off. Certificate files are read once, so a renewed certificate applies
+// after a restart; Let's Encrypt certificates are renewed in the background.
+func (t TLS) ServerConfig() (*tls.Config, error) {
+	if len(t.AutocertDomains) > 0 {
+		if t.CertFile != "" || t.KeyFile != "" {
+			return nil, errors.New("TLS certificate files and autocert domains are mutually exclusive")
+		}
+		cacheDir := t.AutocertCacheDir
+		if cacheDir == "" {
+			cacheDir = DefaultAutocertCacheDir
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(t.AutocertDomains...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      t.AutocertEmail,
+		}
+		// Answers the TLS-ALPN-01 challenge itself, so Let's Encrypt must
+		// reach the server on port 443
+		return manager.TLSConfig(), nil
+	}
+
+	if t.CertFile == "" && t.KeyFile == "" {
+		return nil, nil
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, errors.New("TLS needs both a certificate and a key file")
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLS_ServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sloptask.example.com"},
+		DNSNames:     []string{"sloptask.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	tlsConfig, err := TLS{}.ServerConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "plain HTTP when nothing is set")
+
+	tlsConfig, err = TLS{CertFile: certFile, KeyFile: keyFile}.ServerConfig()
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	tlsConfig, err = TLS{AutocertDomains: []string{"sloptask.example.com"}, AutocertCacheDir: dir}.ServerConfig()
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+
+	_, err = TLS{CertFile: certFile}.ServerConfig()
+	assert.Error(t, err, "a certificate needs its key")
+
+	_, err = TLS{CertFile: certFile, KeyFile: keyFile, AutocertDomains: []string{"sloptask.example.com"}}.ServerConfig()
+	assert.Error(t, err, "certificate files and autocert don't mix")
+
+	_, err = TLS{CertFile: keyFile, KeyFile: keyFile}.ServerConfig()
+	assert.Error(t, err)
+}
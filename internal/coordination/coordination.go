@@ -0,0 +1,34 @@
+// Package coordination shares state between server replicas: request counters
+// for rate limits, responses to idempotent requests and broadcasts invalidating
+// per-replica caches. Without a
+// backend each server keeps this state in memory, which is only correct for a
+// single replica.
+package coordination
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is the state shared by server replicas.
+type Backend interface {
+	// Increment counts a hit on the counter key, which resets window after its
+	// first hit. Returns the count including this hit and the time until reset.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Duration, error)
+	// Reserve sets key to value, expiring after ttl, unless key is set. Returns
+	// the value key holds and whether this call set it.
+	Reserve(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error)
+	// Set sets key to value, expiring after ttl, or never when ttl is zero.
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Get returns the value of key and whether it is set.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+	// Publish broadcasts message to the subscribers of channel on every replica,
+	// this one included.
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe delivers the messages published to channel. The channel is
+	// closed when the backend is closed.
+	Subscribe(channel string) <-chan string
+	Close() error
+}
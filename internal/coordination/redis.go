@@ -0,0 +1,167 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces every key and channel, so sloptask can share a Redis.
+const keyPrefix = "sloptask:"
+
+// subscribeTimeout bounds how long Subscribe waits for Redis to confirm.
+const subscribeTimeout = 5 * time.Second
+
+// incrementScript counts a hit and starts the window on the first one, in one
+// round trip so a crash can't leave a counter without expiry.
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// reserveScript sets a key unless it exists and returns the value it holds, in
+// one round trip so the value read is the one that won.
+var reserveScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return {1, ARGV[1]}
+end
+return {0, redis.call("GET", KEYS[1])}
+`)
+
+// Redis is a Backend on a Redis server shared by the replicas.
+type Redis struct {
+	client *redis.Client
+
+	mu      sync.Mutex
+	pubsubs []*redis.PubSub
+}
+
+// NewRedis connects to the Redis server at rawURL (redis://[:password@]host:6379/db,
+// or rediss:// for TLS).
+func NewRedis(ctx context.Context, rawURL string) (*Redis, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("ping Redis: %w", err)
+	}
+
+	slog.Info("redis connected", "addr", opts.Addr)
+
+	return &Redis{client: client}, nil
+}
+
+// Increment counts a hit on the counter key, which resets window after its
+// first hit.
+func (r *Redis) Increment(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	result, err := incrementScript.Run(ctx, r.client, []string{keyPrefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("increment %s: %w", key, err)
+	}
+
+	return int(result[0]), time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Reserve sets key to value, expiring after ttl, unless key is set.
+func (r *Redis) Reserve(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	result, err := reserveScript.Run(ctx, r.client, []string{keyPrefix + key}, value, ttl.Milliseconds()).Slice()
+	if err != nil {
+		return "", false, fmt.Errorf("reserve %s: %w", key, err)
+	}
+	// GET finds nothing when the key expired in between
+	if len(result) < 2 {
+		return "", false, fmt.Errorf("reserve %s: key expired while reserving", key)
+	}
+
+	reserved, _ := result[0].(int64)
+	held, _ := result[1].(string)
+	return held, reserved == 1, nil
+}
+
+// Set sets key to value, expiring after ttl, or never when ttl is zero.
+func (r *Redis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, keyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("set %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get returns the value of key and whether it is set.
+func (r *Redis) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.client.Get(ctx, keyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Delete removes key.
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Publish broadcasts message to the subscribers of channel on every replica.
+func (r *Redis) Publish(ctx context.Context, channel, message string) error {
+	if err := r.client.Publish(ctx, keyPrefix+channel, message).Err(); err != nil {
+		return fmt.Errorf("publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Subscribe delivers the messages published to channel, from the moment Redis
+// confirms the subscription. The subscription reconnects on its own; messages
+// published while it is disconnected are lost.
+func (r *Redis) Subscribe(channel string) <-chan string {
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+
+	pubsub := r.client.Subscribe(ctx, keyPrefix+channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		slog.Warn("redis subscription not confirmed, retrying in the background", "channel", channel, "error", err)
+	}
+	r.mu.Lock()
+	r.pubsubs = append(r.pubsubs, pubsub)
+	r.mu.Unlock()
+
+	messages := make(chan string, 64)
+	go func() {
+		defer close(messages)
+		for message := range pubsub.Channel() {
+			messages <- message.Payload
+		}
+	}()
+
+	return messages
+}
+
+// Close disconnects from Redis and closes every subscription.
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pubsub := range r.pubsubs {
+		if err := pubsub.Close(); err != nil {
+			slog.Warn("failed to close redis subscription", "error", err)
+		}
+	}
+	r.pubsubs = nil
+
+	return r.client.Close()
+}
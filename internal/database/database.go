@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -18,29 +20,56 @@ func (db *DB) Pool() *pgxpool.Pool {
 	return db.pool
 }
 
+// PoolConfig tunes the connection pool.
+type PoolConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration // connections are closed and replaced after this long
+	// StatementTimeout cancels statements running longer, sent as the
+	// statement_timeout startup parameter; 0 keeps the server's setting
+	StatementTimeout time.Duration
+}
+
+// DefaultPoolConfig is the pool configuration when none is given.
+var DefaultPoolConfig = PoolConfig{MaxConns: 10, MinConns: 2, MaxConnLifetime: time.Hour}
+
 // New creates a new database connection pool.
-func New(ctx context.Context, databaseURL string) (*DB, error) {
+func New(ctx context.Context, databaseURL string, pool PoolConfig) (*DB, error) {
+	if pool.MaxConns < 1 || pool.MinConns < 0 || pool.MinConns > pool.MaxConns {
+		return nil, fmt.Errorf("pool size must have max_conns >= 1 and 0 <= min_conns <= max_conns, got %d and %d", pool.MaxConns, pool.MinConns)
+	}
+	if pool.MaxConnLifetime <= 0 {
+		return nil, fmt.Errorf("max_conn_lifetime must be positive, got %s", pool.MaxConnLifetime)
+	}
+	if pool.StatementTimeout < 0 {
+		return nil, fmt.Errorf("statement_timeout must not be negative, got %s", pool.StatementTimeout)
+	}
+
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse database URL: %w", err)
 	}
 
-	config.MaxConns = 10
-	config.MinConns = 2
+	config.MaxConns = pool.MaxConns
+	config.MinConns = pool.MinConns
+	config.MaxConnLifetime = pool.MaxConnLifetime
+	if pool.StatementTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(pool.StatementTimeout.Milliseconds(), 10)
+	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	connPool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("create connection pool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
+	if err := connPool.Ping(ctx); err != nil {
+		connPool.Close()
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
 	slog.Info("database connected")
 
-	return &DB{pool: pool}, nil
+	return &DB{pool: connPool}, nil
 }
 
 // Close closes the database connection pool.
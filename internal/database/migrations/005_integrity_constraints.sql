@@ -0,0 +1,75 @@
+-- +goose Up
+-- Database-level guarantees for invariants otherwise enforced only in Go.
+-- Enum CHECK constraints and agent FKs already exist in 002_create_schema.sql.
+
+-- A task cannot block itself
+ALTER TABLE tasks ADD CONSTRAINT tasks_blocked_by_not_self
+    CHECK (NOT (id = ANY(blocked_by)));
+
+-- Deadlines only apply to statuses that can expire
+ALTER TABLE tasks ADD CONSTRAINT tasks_deadline_only_for_active_status
+    CHECK (status_deadline_at IS NULL OR status IN ('NEW', 'IN_PROGRESS', 'BLOCKED'));
+
+-- Status-bearing events must record the resulting status
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type = 'commented' OR new_status IS NOT NULL);
+
+-- Deadline configuration must be a JSON object (status -> minutes)
+ALTER TABLE workspaces ADD CONSTRAINT workspaces_status_deadlines_object
+    CHECK (jsonb_typeof(status_deadlines) = 'object');
+
+-- Creator, assignee and blockers must all belong to the task's workspace.
+-- +goose StatementBegin
+CREATE FUNCTION tasks_validate_workspace_refs() RETURNS trigger AS $$
+DECLARE
+    missing_count INTEGER;
+BEGIN
+    -- Only re-validate references that changed, so unrelated updates don't trip over
+    -- blockers deleted after the fact.
+    IF TG_OP = 'UPDATE' AND NEW.workspace_id = OLD.workspace_id THEN
+        IF NEW.creator_id = OLD.creator_id AND
+           NEW.assignee_id IS NOT DISTINCT FROM OLD.assignee_id AND
+           NEW.blocked_by = OLD.blocked_by THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.creator_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'creator % is not in workspace %', NEW.creator_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    IF NEW.assignee_id IS NOT NULL AND
+       NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.assignee_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'assignee % is not in workspace %', NEW.assignee_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    IF cardinality(NEW.blocked_by) > 0 AND
+       (TG_OP = 'INSERT' OR NEW.workspace_id <> OLD.workspace_id OR NEW.blocked_by <> OLD.blocked_by) THEN
+        SELECT COUNT(*) INTO missing_count
+        FROM unnest(NEW.blocked_by) AS b(id)
+        WHERE NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = b.id AND t.workspace_id = NEW.workspace_id);
+
+        IF missing_count > 0 THEN
+            RAISE EXCEPTION 'blocked_by references % task(s) missing from workspace %', missing_count, NEW.workspace_id
+                USING ERRCODE = 'foreign_key_violation';
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_validate_workspace_refs
+    BEFORE INSERT OR UPDATE OF workspace_id, creator_id, assignee_id, blocked_by ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_validate_workspace_refs();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS tasks_validate_workspace_refs ON tasks;
+DROP FUNCTION IF EXISTS tasks_validate_workspace_refs();
+ALTER TABLE workspaces DROP CONSTRAINT IF EXISTS workspaces_status_deadlines_object;
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS task_events_new_status_required;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_deadline_only_for_active_status;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_blocked_by_not_self;
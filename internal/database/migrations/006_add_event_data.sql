@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE task_events ADD COLUMN data JSONB
+    CHECK (data IS NULL OR jsonb_typeof(data) = 'object');
+
+COMMENT ON COLUMN task_events.data IS 'Optional structured payload attached by the actor (tool output, links, metrics)';
+
+-- +goose Down
+ALTER TABLE task_events DROP COLUMN data;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN result JSONB
+    CHECK (result IS NULL OR jsonb_typeof(result) = 'object');
+
+COMMENT ON COLUMN tasks.result IS 'Structured result recorded when the task is completed (optional, set on DONE)';
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN result;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Read tokens: workspace-scoped, read-only credentials for dashboards and scripts.
+-- Not tied to an agent; only a SHA-256 hash of the token is stored.
+CREATE TABLE read_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE read_tokens IS 'Workspace-scoped read-only API tokens for dashboards';
+
+CREATE INDEX idx_read_tokens_workspace_id ON read_tokens(workspace_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS read_tokens;
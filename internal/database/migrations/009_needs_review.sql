@@ -0,0 +1,41 @@
+-- +goose Up
+-- NEEDS_REVIEW status and review outcome events.
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected'));
+
+-- Review verdicts need a justification just like status changes
+ALTER TABLE task_events ADD CONSTRAINT comment_required_for_review
+    CHECK (type NOT IN ('review_approved', 'review_rejected') OR comment IS NOT NULL);
+
+-- +goose Down
+ALTER TABLE task_events DROP CONSTRAINT comment_required_for_review;
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Checklist items split a task into parts that helper agents can claim individually.
+CREATE TABLE task_checklist_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL CHECK (char_length(title) > 0),
+    position INTEGER NOT NULL,
+    claimed_by UUID REFERENCES agents(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMPTZ,
+    completed_by UUID REFERENCES agents(id) ON DELETE SET NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT task_checklist_items_position_unique UNIQUE (task_id, position)
+);
+
+COMMENT ON TABLE task_checklist_items IS 'Checklist items of a task, claimable by helper agents without reassigning the task';
+
+-- Checklist events are scoped to an item (data.checklist_item_id) and do not change task status
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type IN ('commented', 'checklist_claimed', 'checklist_completed') OR new_status IS NOT NULL);
+
+-- +goose Down
+DELETE FROM task_events WHERE type IN ('checklist_claimed', 'checklist_completed');
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type = 'commented' OR new_status IS NOT NULL);
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected'));
+
+DROP TABLE IF EXISTS task_checklist_items;
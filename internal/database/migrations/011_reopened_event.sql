@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'reopened';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed'));
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Capability-based routing: agents advertise capabilities, tasks require them.
+ALTER TABLE agents ADD COLUMN capabilities TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks ADD COLUMN required_capabilities TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN agents.capabilities IS 'Skills the agent offers (e.g. coder, researcher, reviewer)';
+COMMENT ON COLUMN tasks.required_capabilities IS 'Capabilities an agent must have all of to claim the task';
+
+-- +goose Down
+ALTER TABLE tasks DROP COLUMN required_capabilities;
+ALTER TABLE agents DROP COLUMN capabilities;
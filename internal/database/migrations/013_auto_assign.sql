@@ -0,0 +1,12 @@
+-- +goose Up
+-- Auto-assignment: per-workspace strategy for handing NEW tasks to idle agents.
+ALTER TABLE workspaces ADD COLUMN auto_assign_strategy VARCHAR(20) NOT NULL DEFAULT 'none'
+    CHECK (auto_assign_strategy IN ('none', 'round_robin', 'least_loaded', 'capability_match'));
+ALTER TABLE agents ADD COLUMN last_auto_assigned_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN workspaces.auto_assign_strategy IS 'How the auto-assign job picks an agent for NEW tasks (none disables it)';
+COMMENT ON COLUMN agents.last_auto_assigned_at IS 'When the auto-assign job last gave this agent a task (round_robin ordering)';
+
+-- +goose Down
+ALTER TABLE agents DROP COLUMN last_auto_assigned_at;
+ALTER TABLE workspaces DROP COLUMN auto_assign_strategy;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Named queues split a workspace into independent pipelines.
+CREATE TABLE task_queues (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL CHECK (name ~ '^[a-z0-9][a-z0-9_-]*$'),
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT task_queues_workspace_name_unique UNIQUE (workspace_id, name)
+);
+
+COMMENT ON TABLE task_queues IS 'Named task queues within a workspace';
+
+-- Tasks reference queues by name; renames cascade, deleting a queue with tasks fails
+ALTER TABLE tasks ADD COLUMN queue VARCHAR(50);
+ALTER TABLE tasks ADD CONSTRAINT tasks_queue_fkey
+    FOREIGN KEY (workspace_id, queue) REFERENCES task_queues (workspace_id, name)
+    ON UPDATE CASCADE;
+
+CREATE INDEX idx_tasks_queue ON tasks (workspace_id, queue, status) WHERE queue IS NOT NULL;
+
+COMMENT ON COLUMN tasks.queue IS 'Queue name within the workspace, NULL for the default pool';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_queue;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_queue_fkey;
+ALTER TABLE tasks DROP COLUMN queue;
+DROP TABLE IF EXISTS task_queues;
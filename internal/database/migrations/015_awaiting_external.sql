@@ -0,0 +1,67 @@
+-- +goose Up
+-- AWAITING_EXTERNAL status: the assignee waits on an external system (CI run, vendor ticket, PR review),
+-- identified by a structured reference, rather than on another task.
+ALTER TABLE tasks ADD COLUMN external_system VARCHAR(100);
+ALTER TABLE tasks ADD COLUMN external_id VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN external_url TEXT;
+
+COMMENT ON COLUMN tasks.external_system IS 'External system an AWAITING_EXTERNAL task waits on, e.g. github, jira';
+COMMENT ON COLUMN tasks.external_id IS 'Identifier of the awaited item within external_system';
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+-- A waiting task always has its reference (the application clears it on leaving the status)
+ALTER TABLE tasks ADD CONSTRAINT tasks_external_ref_check
+    CHECK (status <> 'AWAITING_EXTERNAL' OR (external_system IS NOT NULL AND external_id IS NOT NULL));
+
+-- Integrations resolve waits by reference
+CREATE INDEX idx_tasks_awaiting_external ON tasks(workspace_id, external_system, external_id)
+    WHERE status = 'AWAITING_EXTERNAL';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved'));
+
+-- +goose Down
+DELETE FROM task_events
+WHERE type IN ('awaiting_external', 'external_resolved')
+   OR old_status = 'AWAITING_EXTERNAL'
+   OR new_status = 'AWAITING_EXTERNAL';
+UPDATE tasks SET status = 'IN_PROGRESS', external_system = NULL, external_id = NULL, external_url = NULL
+WHERE status = 'AWAITING_EXTERNAL';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+DROP INDEX idx_tasks_awaiting_external;
+ALTER TABLE tasks DROP CONSTRAINT tasks_external_ref_check;
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE tasks DROP COLUMN external_url;
+ALTER TABLE tasks DROP COLUMN external_id;
+ALTER TABLE tasks DROP COLUMN external_system;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Recurring task schedules: a cron expression plus the template of the task to create.
+CREATE TABLE schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL CHECK (char_length(name) > 0),
+    cron_expr VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    task_template JSONB NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT schedules_workspace_name_unique UNIQUE (workspace_id, name)
+);
+
+COMMENT ON TABLE schedules IS 'Recurring task schedules instantiated by the scheduler worker';
+COMMENT ON COLUMN schedules.task_template IS 'Fields of the created task: title, description, priority, visibility, assignee_id, required_capabilities, queue';
+
+-- The scheduler polls for due schedules
+CREATE INDEX idx_schedules_due ON schedules (next_run_at) WHERE enabled;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_schedules_due;
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Managed label registry: tasks may only carry labels registered in their workspace,
+-- so agents converge on one vocabulary instead of near-duplicate ad-hoc labels.
+CREATE TABLE labels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL CHECK (name ~ '^[a-z0-9][a-z0-9_.:-]*$'),
+    color CHAR(7) NOT NULL DEFAULT '#6b7280' CHECK (color ~ '^#[0-9a-f]{6}$'),
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT labels_workspace_name_unique UNIQUE (workspace_id, name)
+);
+
+COMMENT ON TABLE labels IS 'Registered task labels of a workspace';
+
+-- Sorted, deduplicated label names; renames and merges rewrite them
+ALTER TABLE tasks ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_tasks_labels ON tasks USING GIN (labels);
+
+COMMENT ON COLUMN tasks.labels IS 'Names of registered labels of the workspace';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'labels_changed';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved'));
+
+DROP INDEX IF EXISTS idx_tasks_labels;
+ALTER TABLE tasks DROP COLUMN labels;
+DROP TABLE IF EXISTS labels;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Archived tasks are finished (DONE or CANCELLED) tasks hidden from default task lists.
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN tasks.archived_at IS 'When the task was archived; NULL for live tasks';
+
+-- Default listings only scan live tasks
+CREATE INDEX idx_tasks_live ON tasks (workspace_id, status) WHERE archived_at IS NULL;
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'archived';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed'));
+
+DROP INDEX IF EXISTS idx_tasks_live;
+ALTER TABLE tasks DROP COLUMN archived_at;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Full versions of a task's title and description. Revision 1 is the original
+-- text, recorded together with the first edit.
+CREATE TABLE task_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL CHECK (revision > 0),
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL,
+    author_id UUID NOT NULL REFERENCES agents(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT task_revisions_task_revision_unique UNIQUE (task_id, revision)
+);
+
+COMMENT ON TABLE task_revisions IS 'Version history of task titles and descriptions';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'edited';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived'));
+
+DROP TABLE IF EXISTS task_revisions;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- W3C trace context of the request that caused the event, handed on to event consumers
+ALTER TABLE task_events ADD COLUMN traceparent VARCHAR(55);
+ALTER TABLE task_events ADD COLUMN tracestate VARCHAR(512);
+
+COMMENT ON COLUMN task_events.traceparent IS 'W3C traceparent of the triggering request; NULL for system events and untraced requests';
+
+-- +goose Down
+ALTER TABLE task_events DROP COLUMN tracestate;
+ALTER TABLE task_events DROP COLUMN traceparent;
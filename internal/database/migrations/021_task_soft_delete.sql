@@ -0,0 +1,36 @@
+-- +goose Up
+-- Soft-deleted tasks are hidden from every API until `sloptask purge` removes them
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN deleted_by UUID REFERENCES agents(id);
+
+COMMENT ON COLUMN tasks.deleted_at IS 'When the task was soft-deleted; NULL for visible tasks';
+COMMENT ON COLUMN tasks.deleted_by IS 'Agent that deleted the task; NULL when deleted by an operator';
+
+-- Purge scans only deleted rows
+CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Default listings only scan live, visible tasks
+DROP INDEX IF EXISTS idx_tasks_live;
+CREATE INDEX idx_tasks_live ON tasks (workspace_id, status) WHERE archived_at IS NULL AND deleted_at IS NULL;
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'deleted';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited'));
+
+DROP INDEX IF EXISTS idx_tasks_live;
+CREATE INDEX idx_tasks_live ON tasks (workspace_id, status) WHERE archived_at IS NULL;
+
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+ALTER TABLE tasks DROP COLUMN deleted_by;
+ALTER TABLE tasks DROP COLUMN deleted_at;
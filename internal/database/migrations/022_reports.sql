@@ -0,0 +1,32 @@
+-- +goose Up
+-- Scheduled reports: a cron expression, what to render and where to deliver it.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    creator_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL CHECK (char_length(name) > 0),
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('workspace_summary', 'agent_performance')),
+    format VARCHAR(10) NOT NULL CHECK (format IN ('json', 'markdown')),
+    period VARCHAR(10) NOT NULL CHECK (period IN ('day', 'week', 'month')),
+    cron_expr VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('webhook', 'email')),
+    target TEXT NOT NULL CHECK (char_length(target) > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT reports_workspace_name_unique UNIQUE (workspace_id, name)
+);
+
+COMMENT ON TABLE reports IS 'Scheduled reports rendered and delivered by the scheduler worker';
+COMMENT ON COLUMN reports.target IS 'Webhook URL or comma-separated email addresses, depending on target_type';
+
+-- The scheduler polls for due reports
+CREATE INDEX idx_reports_due ON reports (next_run_at) WHERE enabled;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reports_due;
+DROP TABLE IF EXISTS reports;
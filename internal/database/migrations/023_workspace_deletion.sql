@@ -0,0 +1,25 @@
+-- +goose Up
+-- Archived workspaces are frozen: agents and read tokens are revoked, schedules and
+-- reports disabled, and background jobs skip them. Archiving precedes deletion.
+ALTER TABLE workspaces ADD COLUMN archived_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN workspaces.archived_at IS 'When the workspace was archived; NULL for live workspaces';
+
+-- Admin audit log: operator actions made through the admin API or CLI.
+-- workspace_id has no foreign key so entries outlive deleted workspaces.
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    action VARCHAR(50) NOT NULL,
+    workspace_id UUID,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE admin_audit_log IS 'Operator actions, kept after the workspace they concern is deleted';
+
+CREATE INDEX idx_admin_audit_log_workspace ON admin_audit_log (workspace_id, created_at DESC);
+CREATE INDEX idx_admin_audit_log_created_at ON admin_audit_log (created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_audit_log;
+ALTER TABLE workspaces DROP COLUMN archived_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Per-workspace webhook signing secrets. Secrets are stored in plaintext because
+-- the server signs with them. During a rotation the previous secret keeps signing
+-- alongside the new one until previous_expires_at or until the rotation is completed.
+CREATE TABLE webhook_secrets (
+    workspace_id UUID PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    previous_secret TEXT,
+    previous_expires_at TIMESTAMPTZ,
+    rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT webhook_secrets_previous_check CHECK ((previous_secret IS NULL) = (previous_expires_at IS NULL))
+);
+
+COMMENT ON TABLE webhook_secrets IS 'Webhook signing secrets per workspace, with the previous secret during rotation';
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_secrets;
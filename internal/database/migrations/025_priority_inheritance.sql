@@ -0,0 +1,30 @@
+-- +goose Up
+-- Priority inheritance: while enabled, an open task blocking an open high or
+-- critical task is ranked as if it had that task's priority.
+ALTER TABLE workspaces ADD COLUMN priority_inheritance BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspaces.priority_inheritance IS 'Whether blockers inherit the priority of the high and critical tasks they block';
+
+ALTER TABLE tasks ADD COLUMN inherited_priority VARCHAR(20)
+    CHECK (inherited_priority IN ('high', 'critical'));
+
+COMMENT ON COLUMN tasks.inherited_priority IS 'Priority inherited from an open task this one blocks; NULL unless higher than its own';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type IN ('priority_inherited', 'priority_restored');
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted'));
+
+ALTER TABLE tasks DROP COLUMN inherited_priority;
+ALTER TABLE workspaces DROP COLUMN priority_inheritance;
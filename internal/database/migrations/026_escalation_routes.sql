@@ -0,0 +1,50 @@
+-- +goose Up
+-- Escalation routing: ordered rules deciding who is told about an escalation.
+-- The first rule whose criteria all match the task wins.
+CREATE TABLE escalation_routes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    name VARCHAR(100) NOT NULL CHECK (char_length(name) > 0),
+    label VARCHAR(50),
+    priority VARCHAR(20) CHECK (priority IN ('low', 'normal', 'high', 'critical')),
+    creator_id UUID REFERENCES agents(id) ON DELETE CASCADE,
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('agent', 'webhook', 'email')),
+    target TEXT NOT NULL CHECK (char_length(target) > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT escalation_routes_workspace_position_unique UNIQUE (workspace_id, position),
+    CONSTRAINT escalation_routes_workspace_name_unique UNIQUE (workspace_id, name)
+);
+
+COMMENT ON TABLE escalation_routes IS 'Rules routing escalation notifications, evaluated in position order';
+COMMENT ON COLUMN escalation_routes.target IS 'Agent ID, webhook URL or comma-separated email addresses, depending on target_type';
+
+-- One notification per escalation. Agent notifications are delivered on creation;
+-- webhook and email notifications are delivered by the scheduler worker.
+CREATE TABLE escalation_notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES task_events(id) ON DELETE CASCADE,
+    route VARCHAR(100) NOT NULL,
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('agent', 'webhook', 'email')),
+    target TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN escalation_notifications.route IS 'Name of the matched route at the time of the escalation, or default';
+COMMENT ON COLUMN escalation_notifications.payload IS 'The task and escalation as they were when escalated; the delivered document';
+
+-- The scheduler polls for pending deliveries; agents read their inbox
+CREATE INDEX idx_escalation_notifications_pending ON escalation_notifications (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_escalation_notifications_target ON escalation_notifications (target_type, target, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS escalation_notifications;
+DROP TABLE IF EXISTS escalation_routes;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Sandboxes: short-lived clones of a workspace's open work for testing agents
+-- against realistic data. The purge job deletes them once they expire.
+ALTER TABLE workspaces ADD COLUMN sandbox_of UUID REFERENCES workspaces(id) ON DELETE SET NULL;
+ALTER TABLE workspaces ADD COLUMN expires_at TIMESTAMPTZ;
+ALTER TABLE workspaces ADD CONSTRAINT workspaces_sandbox_expires
+    CHECK (sandbox_of IS NULL OR expires_at IS NOT NULL);
+
+COMMENT ON COLUMN workspaces.sandbox_of IS 'Workspace this sandbox was cloned from; NULL for regular workspaces and once the source is deleted';
+COMMENT ON COLUMN workspaces.expires_at IS 'When the purge job deletes this sandbox; NULL for regular workspaces';
+
+CREATE INDEX idx_workspaces_expires_at ON workspaces (expires_at) WHERE expires_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_workspaces_expires_at;
+ALTER TABLE workspaces DROP CONSTRAINT IF EXISTS workspaces_sandbox_expires;
+ALTER TABLE workspaces DROP COLUMN expires_at;
+ALTER TABLE workspaces DROP COLUMN sandbox_of;
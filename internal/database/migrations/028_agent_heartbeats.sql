@@ -0,0 +1,17 @@
+-- +goose Up
+-- Agent heartbeats: agents report liveness, and an agent whose last heartbeat is
+-- older than the workspace's window is stale. Agents that never sent one are not.
+ALTER TABLE agents ADD COLUMN last_seen_at TIMESTAMPTZ;
+ALTER TABLE workspaces ADD COLUMN agent_stale_after_seconds INTEGER NOT NULL DEFAULT 300
+    CHECK (agent_stale_after_seconds > 0);
+
+COMMENT ON COLUMN agents.last_seen_at IS 'Time of the last heartbeat; NULL if the agent never sent one';
+COMMENT ON COLUMN workspaces.agent_stale_after_seconds IS 'Agents without a heartbeat for this long are stale';
+
+CREATE INDEX idx_agents_last_seen_at ON agents (workspace_id, last_seen_at)
+    WHERE is_active = true AND last_seen_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_agents_last_seen_at;
+ALTER TABLE workspaces DROP COLUMN agent_stale_after_seconds;
+ALTER TABLE agents DROP COLUMN last_seen_at;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- DONE validation: a workspace webhook asked synchronously to approve every move
+-- to DONE, so CI or test harnesses can gate completions claimed by agents.
+ALTER TABLE workspaces ADD COLUMN done_validation_url TEXT;
+ALTER TABLE workspaces ADD COLUMN done_validation_timeout_seconds INTEGER NOT NULL DEFAULT 5
+    CHECK (done_validation_timeout_seconds BETWEEN 1 AND 30);
+ALTER TABLE workspaces ADD COLUMN done_validation_fail_open BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspaces.done_validation_url IS 'Webhook approving moves to DONE; NULL when completions are not validated';
+COMMENT ON COLUMN workspaces.done_validation_fail_open IS 'Allow moves to DONE when the webhook cannot be reached';
+
+-- +goose Down
+ALTER TABLE workspaces DROP COLUMN done_validation_fail_open;
+ALTER TABLE workspaces DROP COLUMN done_validation_timeout_seconds;
+ALTER TABLE workspaces DROP COLUMN done_validation_url;
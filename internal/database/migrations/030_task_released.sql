@@ -0,0 +1,19 @@
+-- +goose Up
+-- Released events: the system returned an IN_PROGRESS task to the pool because
+-- its assignee was deactivated or stopped sending heartbeats.
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'released';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored'));
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Direct messages between two agents about a task, kept out of the task's
+-- public event stream.
+CREATE TABLE task_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    body TEXT NOT NULL CHECK (char_length(body) BETWEEN 1 AND 10000),
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT task_messages_not_self CHECK (sender_id <> recipient_id)
+);
+
+COMMENT ON TABLE task_messages IS 'Agent-to-agent messages scoped to a task, visible only to sender and recipient';
+COMMENT ON COLUMN task_messages.read_at IS 'When the recipient acknowledged the message; NULL while unread';
+
+CREATE INDEX idx_task_messages_recipient ON task_messages (recipient_id, created_at DESC);
+CREATE INDEX idx_task_messages_sender ON task_messages (sender_id, created_at DESC);
+CREATE INDEX idx_task_messages_unread ON task_messages (recipient_id) WHERE read_at IS NULL;
+CREATE INDEX idx_task_messages_task ON task_messages (task_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_messages;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Public intake form: lets humans outside the system submit work to a workspace
+-- without an agent token. Submissions become NEW tasks created by a designated agent.
+CREATE TABLE intake_forms (
+    workspace_id UUID PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    key_hash TEXT NOT NULL UNIQUE,
+    rate_limit_per_hour INTEGER NOT NULL DEFAULT 10 CHECK (rate_limit_per_hour BETWEEN 1 AND 1000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE intake_forms IS 'Public intake form of a workspace; absent when intake is disabled';
+COMMENT ON COLUMN intake_forms.agent_id IS 'Agent recorded as the creator of submitted tasks';
+COMMENT ON COLUMN intake_forms.key_hash IS 'SHA-256 of the intake key; the plaintext is shown only when issued';
+
+-- +goose Down
+DROP TABLE IF EXISTS intake_forms;
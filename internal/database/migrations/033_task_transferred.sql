@@ -0,0 +1,18 @@
+-- +goose Up
+-- Transferred events: an operator moved a task, with its events, to another workspace.
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'transferred';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released'));
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Priority aging: NEW tasks nobody claims for too long get their priority raised
+-- one step (bump) or a reminder event (warn), so they don't starve behind newer work.
+ALTER TABLE workspaces ADD COLUMN priority_aging_after_seconds INTEGER
+    CHECK (priority_aging_after_seconds BETWEEN 60 AND 2592000);
+ALTER TABLE workspaces ADD COLUMN priority_aging_action VARCHAR(10) NOT NULL DEFAULT 'bump'
+    CHECK (priority_aging_action IN ('bump', 'warn'));
+
+COMMENT ON COLUMN workspaces.priority_aging_after_seconds IS 'How long a NEW task may wait unclaimed before it ages; NULL when aging is off';
+COMMENT ON COLUMN workspaces.priority_aging_action IS 'bump raises the priority one step, warn only records an event';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'priority_aged';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred'));
+
+ALTER TABLE workspaces DROP COLUMN priority_aging_action;
+ALTER TABLE workspaces DROP COLUMN priority_aging_after_seconds;
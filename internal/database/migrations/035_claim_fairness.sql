@@ -0,0 +1,21 @@
+-- +goose Up
+-- Claim fairness: in mixed fleets the fastest poller would otherwise claim all
+-- the work. A quota caps claims per agent and window; taking turns stops an agent
+-- from claiming twice in a row while another live agent sits idle.
+ALTER TABLE workspaces ADD COLUMN claim_quota INTEGER
+    CHECK (claim_quota BETWEEN 1 AND 10000);
+ALTER TABLE workspaces ADD COLUMN claim_quota_window_seconds INTEGER NOT NULL DEFAULT 3600
+    CHECK (claim_quota_window_seconds BETWEEN 60 AND 604800);
+ALTER TABLE workspaces ADD COLUMN claim_take_turns BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspaces.claim_quota IS 'Claims an agent may make per claim_quota_window_seconds; NULL for no quota';
+COMMENT ON COLUMN workspaces.claim_take_turns IS 'Refuse a second claim in a row while another live agent is idle';
+
+-- Counting an agent's recent claims
+CREATE INDEX idx_task_events_claims ON task_events (actor_id, created_at) WHERE type = 'claimed';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_task_events_claims;
+ALTER TABLE workspaces DROP COLUMN claim_take_turns;
+ALTER TABLE workspaces DROP COLUMN claim_quota_window_seconds;
+ALTER TABLE workspaces DROP COLUMN claim_quota;
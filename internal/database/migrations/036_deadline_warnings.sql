@@ -0,0 +1,31 @@
+-- +goose Up
+-- Deadline warnings: a system event once a task has used up most of its status
+-- deadline, so agents hear about it before the task goes STUCK.
+ALTER TABLE workspaces ADD COLUMN deadline_warning_percent INTEGER
+    CHECK (deadline_warning_percent BETWEEN 1 AND 99);
+
+COMMENT ON COLUMN workspaces.deadline_warning_percent IS 'Warn when this share of a status deadline is left; NULL when warnings are off';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning'));
+
+-- Finding whether a task was already warned about its current deadline
+CREATE INDEX idx_task_events_deadline_warnings ON task_events (task_id, created_at) WHERE type = 'deadline_warning';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_task_events_deadline_warnings;
+DELETE FROM task_events WHERE type = 'deadline_warning';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged'));
+
+ALTER TABLE workspaces DROP COLUMN deadline_warning_percent;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Per-status expiry behavior: the status a task enters when its deadline in a
+-- status expires, instead of always STUCK.
+ALTER TABLE workspaces ADD COLUMN deadline_expiry JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN workspaces.deadline_expiry IS 'Status -> status entered when its deadline expires; statuses left out go STUCK';
+
+-- +goose Down
+ALTER TABLE workspaces DROP COLUMN deadline_expiry;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Attempt counter: how often a task changed hands (taken over or returned to
+-- NEW). Once a workspace's limit is reached the task is held for human review
+-- instead of bouncing between agents forever.
+ALTER TABLE tasks ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN human_review_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN tasks.attempts IS 'Times the task was taken over or returned to NEW';
+COMMENT ON COLUMN tasks.human_review_at IS 'Set once attempts reached the workspace limit; agents may not claim or take over the task until cleared';
+
+ALTER TABLE workspaces ADD COLUMN max_attempts INTEGER CHECK (max_attempts BETWEEN 1 AND 100);
+
+COMMENT ON COLUMN workspaces.max_attempts IS 'Attempts after which tasks are held for human review; NULL for no limit';
+
+-- The human review queue of a workspace
+CREATE INDEX idx_tasks_human_review ON tasks (workspace_id, human_review_at) WHERE human_review_at IS NOT NULL;
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared'));
+
+-- +goose Down
+DELETE FROM task_events WHERE type IN ('attempts_exceeded', 'human_review_cleared');
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning'));
+
+DROP INDEX IF EXISTS idx_tasks_human_review;
+ALTER TABLE workspaces DROP COLUMN max_attempts;
+ALTER TABLE tasks DROP COLUMN human_review_at;
+ALTER TABLE tasks DROP COLUMN attempts;
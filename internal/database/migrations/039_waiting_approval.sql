@@ -0,0 +1,61 @@
+-- +goose Up
+-- WAITING_APPROVAL status: finished work on tasks such as deployments or payments
+-- waits for an operator instead of completing on an agent's say-so.
+ALTER TABLE tasks ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN tasks.requires_approval IS 'Agents cannot move the task to DONE; it goes through WAITING_APPROVAL and an operator decides';
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'WAITING_APPROVAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+-- The approval queue of a workspace
+CREATE INDEX idx_tasks_waiting_approval ON tasks (workspace_id, updated_at) WHERE status = 'WAITING_APPROVAL';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'WAITING_APPROVAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'WAITING_APPROVAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared', 'approval_granted',
+                    'approval_rejected'));
+
+-- +goose Down
+DELETE FROM task_events
+WHERE type IN ('approval_granted', 'approval_rejected')
+   OR old_status = 'WAITING_APPROVAL'
+   OR new_status = 'WAITING_APPROVAL';
+UPDATE tasks SET status = 'IN_PROGRESS' WHERE status = 'WAITING_APPROVAL';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_check
+    CHECK (new_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_old_status_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_old_status_check
+    CHECK (old_status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+DROP INDEX IF EXISTS idx_tasks_waiting_approval;
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check
+    CHECK (status IN ('NEW', 'IN_PROGRESS', 'NEEDS_REVIEW', 'BLOCKED', 'AWAITING_EXTERNAL', 'STUCK', 'DONE', 'CANCELLED'));
+
+ALTER TABLE tasks DROP COLUMN requires_approval;
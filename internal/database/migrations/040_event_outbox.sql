@@ -0,0 +1,38 @@
+-- +goose Up
+-- Event webhooks: task events of a workspace are published to a webhook through
+-- a transactional outbox. The outbox row is written in the transaction writing
+-- the event, so a crash can neither lose an event nor publish one rolled back.
+ALTER TABLE workspaces ADD COLUMN event_webhook_url TEXT;
+ALTER TABLE workspaces ADD COLUMN event_webhook_types TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN workspaces.event_webhook_url IS 'Webhook receiving task events; NULL when events are not published';
+COMMENT ON COLUMN workspaces.event_webhook_types IS 'Event types published to the webhook; empty for all';
+
+-- One row per event to publish, delivered by the scheduler worker with retries.
+-- seq orders deliveries: a task's events are delivered one after another.
+CREATE TABLE event_outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGSERIAL NOT NULL UNIQUE,
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES task_events(id) ON DELETE CASCADE,
+    target TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN event_outbox.target IS 'Webhook URL of the workspace when the event was written';
+
+-- The scheduler polls for pending deliveries; operators list a workspace's outbox
+CREATE INDEX idx_event_outbox_pending ON event_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_event_outbox_task_pending ON event_outbox (task_id, seq) WHERE status = 'pending';
+CREATE INDEX idx_event_outbox_workspace ON event_outbox (workspace_id, seq DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_outbox;
+ALTER TABLE workspaces DROP COLUMN event_webhook_types;
+ALTER TABLE workspaces DROP COLUMN event_webhook_url;
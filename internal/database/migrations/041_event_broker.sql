@@ -0,0 +1,26 @@
+-- +goose Up
+-- Broker publishing: task events of a workspace can also go to a NATS subject or
+-- Kafka topic through the outbox. The scheduler holds the broker connection.
+ALTER TABLE workspaces ADD COLUMN event_broker_topic TEXT;
+ALTER TABLE workspaces ADD COLUMN event_broker_types TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN workspaces.event_broker_topic IS 'NATS subject or Kafka topic receiving task events; NULL when events are not published to the broker';
+COMMENT ON COLUMN workspaces.event_broker_types IS 'Event types published to the broker; empty for all';
+
+ALTER TABLE event_outbox ADD COLUMN target_type VARCHAR(10) NOT NULL DEFAULT 'webhook' CHECK (target_type IN ('webhook', 'broker'));
+
+COMMENT ON COLUMN event_outbox.target IS 'Webhook URL or broker topic of the workspace when the event was written';
+
+-- A task's events are delivered in order per target type, so a failing webhook
+-- does not hold back the broker
+DROP INDEX idx_event_outbox_task_pending;
+CREATE INDEX idx_event_outbox_task_pending ON event_outbox (task_id, target_type, seq) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX idx_event_outbox_task_pending;
+DELETE FROM event_outbox WHERE target_type = 'broker';
+ALTER TABLE event_outbox DROP COLUMN target_type;
+CREATE INDEX idx_event_outbox_task_pending ON event_outbox (task_id, seq) WHERE status = 'pending';
+COMMENT ON COLUMN event_outbox.target IS 'Webhook URL of the workspace when the event was written';
+ALTER TABLE workspaces DROP COLUMN event_broker_types;
+ALTER TABLE workspaces DROP COLUMN event_broker_topic;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Optimistic concurrency for task edits: every update of a task bumps its
+-- version, so a client can send the version it read and have a lost update
+-- rejected. A trigger bumps it, so no UPDATE can forget to.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN tasks.version IS 'Incremented by every update of the row; clients send it back as expected_version or If-Match';
+
+-- +goose StatementBegin
+CREATE FUNCTION tasks_bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_bump_version
+    BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_bump_version();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS tasks_bump_version ON tasks;
+DROP FUNCTION IF EXISTS tasks_bump_version();
+ALTER TABLE tasks DROP COLUMN version;
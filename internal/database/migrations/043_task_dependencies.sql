@@ -0,0 +1,133 @@
+-- +goose Up
+-- Blockers move from the tasks.blocked_by array to a join table, so foreign keys
+-- keep them real: a blocker must exist in the same workspace, hard-deleting a
+-- task drops its edges, and "what does this task block" is an indexed lookup.
+-- The API still shows blocked_by as a list, in the order it was given.
+ALTER TABLE tasks ADD CONSTRAINT tasks_id_workspace_unique UNIQUE (id, workspace_id);
+
+CREATE TABLE task_dependencies (
+    workspace_id UUID NOT NULL,
+    task_id UUID NOT NULL,
+    blocker_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (task_id, blocker_id),
+    -- Both ends in the dependency's workspace: a task can't change workspace
+    -- while it has edges, see PgTaskRepository.Transfer
+    FOREIGN KEY (task_id, workspace_id) REFERENCES tasks (id, workspace_id) ON DELETE CASCADE,
+    FOREIGN KEY (blocker_id, workspace_id) REFERENCES tasks (id, workspace_id) ON DELETE CASCADE,
+    CONSTRAINT task_dependencies_not_self CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocker ON task_dependencies (blocker_id);
+
+COMMENT ON TABLE task_dependencies IS 'Blockers of tasks: task_id cannot start until blocker_id is DONE';
+COMMENT ON COLUMN task_dependencies.position IS 'Order of the blocker in the task''s blocked_by list';
+
+-- Existing edges whose blocker is gone or in another workspace are dropped
+INSERT INTO task_dependencies (workspace_id, task_id, blocker_id, position)
+SELECT DISTINCT ON (t.id, b.id) t.workspace_id, t.id, b.id, b.position
+FROM tasks t
+CROSS JOIN LATERAL unnest(t.blocked_by) WITH ORDINALITY AS b(id, position)
+WHERE b.id <> t.id
+    AND EXISTS (SELECT 1 FROM tasks bt WHERE bt.id = b.id AND bt.workspace_id = t.workspace_id)
+ORDER BY t.id, b.id, b.position;
+
+-- Blockers are now checked by the foreign keys
+DROP TRIGGER tasks_validate_workspace_refs ON tasks;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION tasks_validate_workspace_refs() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.workspace_id = OLD.workspace_id AND
+       NEW.creator_id = OLD.creator_id AND
+       NEW.assignee_id IS NOT DISTINCT FROM OLD.assignee_id THEN
+        RETURN NEW;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.creator_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'creator % is not in workspace %', NEW.creator_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    IF NEW.assignee_id IS NOT NULL AND
+       NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.assignee_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'assignee % is not in workspace %', NEW.assignee_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_validate_workspace_refs
+    BEFORE INSERT OR UPDATE OF workspace_id, creator_id, assignee_id ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_validate_workspace_refs();
+
+ALTER TABLE tasks DROP CONSTRAINT tasks_blocked_by_not_self;
+ALTER TABLE tasks DROP COLUMN blocked_by;
+
+-- +goose Down
+ALTER TABLE tasks ADD COLUMN blocked_by UUID[] NOT NULL DEFAULT '{}';
+COMMENT ON COLUMN tasks.blocked_by IS 'Array of task IDs that block this task (validated in application: same workspace, no cycles)';
+
+UPDATE tasks t SET blocked_by = d.blocked_by
+FROM (
+    SELECT task_id, array_agg(blocker_id ORDER BY position) AS blocked_by
+    FROM task_dependencies
+    GROUP BY task_id
+) d
+WHERE d.task_id = t.id;
+
+ALTER TABLE tasks ADD CONSTRAINT tasks_blocked_by_not_self
+    CHECK (NOT (id = ANY(blocked_by)));
+
+DROP TRIGGER tasks_validate_workspace_refs ON tasks;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION tasks_validate_workspace_refs() RETURNS trigger AS $$
+DECLARE
+    missing_count INTEGER;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.workspace_id = OLD.workspace_id THEN
+        IF NEW.creator_id = OLD.creator_id AND
+           NEW.assignee_id IS NOT DISTINCT FROM OLD.assignee_id AND
+           NEW.blocked_by = OLD.blocked_by THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.creator_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'creator % is not in workspace %', NEW.creator_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    IF NEW.assignee_id IS NOT NULL AND
+       NOT EXISTS (SELECT 1 FROM agents WHERE id = NEW.assignee_id AND workspace_id = NEW.workspace_id) THEN
+        RAISE EXCEPTION 'assignee % is not in workspace %', NEW.assignee_id, NEW.workspace_id
+            USING ERRCODE = 'foreign_key_violation';
+    END IF;
+
+    IF cardinality(NEW.blocked_by) > 0 AND
+       (TG_OP = 'INSERT' OR NEW.workspace_id <> OLD.workspace_id OR NEW.blocked_by <> OLD.blocked_by) THEN
+        SELECT COUNT(*) INTO missing_count
+        FROM unnest(NEW.blocked_by) AS b(id)
+        WHERE NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = b.id AND t.workspace_id = NEW.workspace_id);
+
+        IF missing_count > 0 THEN
+            RAISE EXCEPTION 'blocked_by references % task(s) missing from workspace %', missing_count, NEW.workspace_id
+                USING ERRCODE = 'foreign_key_violation';
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_validate_workspace_refs
+    BEFORE INSERT OR UPDATE OF workspace_id, creator_id, assignee_id, blocked_by ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_validate_workspace_refs();
+
+DROP TABLE IF EXISTS task_dependencies;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_id_workspace_unique;
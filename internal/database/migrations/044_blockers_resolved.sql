@@ -0,0 +1,39 @@
+-- +goose Up
+-- When a task is done, its dependents whose blockers are all done get a
+-- blockers_resolved event. With auto_unblock, BLOCKED dependents also return
+-- to the status they were blocked from, recorded on the same event.
+ALTER TABLE workspaces ADD COLUMN auto_unblock BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspaces.auto_unblock IS 'Whether BLOCKED tasks return to their previous status once all their blockers are done';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared', 'approval_granted',
+                    'approval_rejected', 'blockers_resolved'));
+
+-- A blockers_resolved event without auto-unblock leaves the status unchanged
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type IN ('commented', 'checklist_claimed', 'checklist_completed', 'blockers_resolved') OR new_status IS NOT NULL);
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'blockers_resolved';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type IN ('commented', 'checklist_claimed', 'checklist_completed') OR new_status IS NOT NULL);
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared', 'approval_granted',
+                    'approval_rejected'));
+
+ALTER TABLE workspaces DROP COLUMN auto_unblock;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Agents watching tasks they neither created nor own. GET /api/v1/notifications
+-- lists the events on watched tasks since the watch started.
+CREATE TABLE task_watchers (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, agent_id)
+);
+
+COMMENT ON TABLE task_watchers IS 'Agents subscribed to the events of a task';
+COMMENT ON COLUMN task_watchers.created_at IS 'When the watch started; earlier events are not notified';
+
+CREATE INDEX idx_task_watchers_agent ON task_watchers (agent_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_watchers;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Agents mentioned as @name in comments. The comment shows up in their
+-- notifications whether or not they watch the task.
+CREATE TABLE task_mentions (
+    event_id UUID NOT NULL REFERENCES task_events(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    PRIMARY KEY (event_id, agent_id)
+);
+
+COMMENT ON TABLE task_mentions IS 'Agents mentioned in comment events';
+
+CREATE INDEX idx_task_mentions_agent ON task_mentions (agent_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_mentions;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- Authors may edit or redact their comments for a short while. The comment
+-- event keeps its place in the history with the new body; earlier bodies are
+-- kept for operators in previous_comments and a comment_edited event records
+-- each change.
+ALTER TABLE task_events ADD COLUMN edited_at TIMESTAMPTZ;
+ALTER TABLE task_events ADD COLUMN redacted_at TIMESTAMPTZ;
+ALTER TABLE task_events ADD COLUMN previous_comments TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN task_events.edited_at IS 'When the author last edited or redacted the comment';
+COMMENT ON COLUMN task_events.redacted_at IS 'When the author redacted the comment, leaving it empty';
+COMMENT ON COLUMN task_events.previous_comments IS 'Earlier bodies of an edited comment, oldest first; not returned by the API';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared', 'approval_granted',
+                    'approval_rejected', 'blockers_resolved', 'comment_edited'));
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type IN ('commented', 'checklist_claimed', 'checklist_completed', 'blockers_resolved', 'comment_edited')
+           OR new_status IS NOT NULL);
+
+-- +goose Down
+DELETE FROM task_events WHERE type = 'comment_edited';
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_new_status_required;
+ALTER TABLE task_events ADD CONSTRAINT task_events_new_status_required
+    CHECK (type IN ('commented', 'checklist_claimed', 'checklist_completed', 'blockers_resolved') OR new_status IS NOT NULL);
+
+ALTER TABLE task_events DROP CONSTRAINT task_events_type_check;
+ALTER TABLE task_events ADD CONSTRAINT task_events_type_check
+    CHECK (type IN ('created', 'status_changed', 'claimed', 'escalated', 'taken_over', 'commented', 'deadline_expired',
+                    'review_approved', 'review_rejected', 'checklist_claimed', 'checklist_completed', 'reopened',
+                    'awaiting_external', 'external_resolved', 'labels_changed', 'archived', 'edited', 'deleted',
+                    'priority_inherited', 'priority_restored', 'released', 'transferred', 'priority_aged',
+                    'deadline_warning', 'attempts_exceeded', 'human_review_cleared', 'approval_granted',
+                    'approval_rejected', 'blockers_resolved'));
+
+ALTER TABLE task_events DROP COLUMN previous_comments;
+ALTER TABLE task_events DROP COLUMN redacted_at;
+ALTER TABLE task_events DROP COLUMN edited_at;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Comments can reply to another comment of the same task. thread_id points at
+-- the comment that started the thread, so a whole thread is read with one
+-- lookup however deep the replies go.
+ALTER TABLE task_events ADD COLUMN reply_to_event_id UUID REFERENCES task_events(id) ON DELETE CASCADE;
+ALTER TABLE task_events ADD COLUMN thread_id UUID REFERENCES task_events(id) ON DELETE CASCADE;
+
+ALTER TABLE task_events ADD CONSTRAINT task_events_reply_is_comment
+    CHECK ((reply_to_event_id IS NULL AND thread_id IS NULL)
+           OR (type = 'commented' AND reply_to_event_id IS NOT NULL AND thread_id IS NOT NULL));
+
+CREATE INDEX idx_task_events_reply_to_event_id ON task_events(reply_to_event_id) WHERE reply_to_event_id IS NOT NULL;
+CREATE INDEX idx_task_events_thread_id ON task_events(thread_id) WHERE thread_id IS NOT NULL;
+
+COMMENT ON COLUMN task_events.reply_to_event_id IS 'Comment this comment replies to';
+COMMENT ON COLUMN task_events.thread_id IS 'Comment that started the thread of this reply';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_task_events_thread_id;
+DROP INDEX IF EXISTS idx_task_events_reply_to_event_id;
+ALTER TABLE task_events DROP CONSTRAINT task_events_reply_is_comment;
+ALTER TABLE task_events DROP COLUMN thread_id;
+ALTER TABLE task_events DROP COLUMN reply_to_event_id;
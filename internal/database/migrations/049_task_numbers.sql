@@ -0,0 +1,48 @@
+-- +goose Up
+-- Tasks are numbered per workspace in creation order, so people and agents can
+-- refer to them by a short key such as TST-142 (workspace slug in upper case,
+-- then the number) instead of a UUID. A trigger issues the numbers from a
+-- counter on the workspace, so every insert gets one; numbers are never
+-- reused, even after a task is purged. A task transferred to another
+-- workspace gets the next number there.
+ALTER TABLE workspaces ADD COLUMN last_task_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN number INTEGER;
+
+COMMENT ON COLUMN workspaces.last_task_number IS 'Number of the last task created in the workspace';
+COMMENT ON COLUMN tasks.number IS 'Sequential number of the task in its workspace; the key is UPPER(slug) || ''-'' || number';
+
+-- Number existing tasks without bumping their versions
+ALTER TABLE tasks DISABLE TRIGGER tasks_bump_version;
+UPDATE tasks t SET number = n.number
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY workspace_id ORDER BY created_at, id) AS number FROM tasks) n
+WHERE n.id = t.id;
+ALTER TABLE tasks ENABLE TRIGGER tasks_bump_version;
+
+UPDATE workspaces w SET last_task_number = COALESCE((SELECT MAX(t.number) FROM tasks t WHERE t.workspace_id = w.id), 0);
+
+ALTER TABLE tasks ALTER COLUMN number SET NOT NULL;
+ALTER TABLE tasks ADD CONSTRAINT tasks_workspace_number_unique UNIQUE (workspace_id, number);
+
+-- +goose StatementBegin
+CREATE FUNCTION tasks_assign_number() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.workspace_id IS DISTINCT FROM OLD.workspace_id THEN
+        UPDATE workspaces SET last_task_number = last_task_number + 1
+        WHERE id = NEW.workspace_id
+        RETURNING last_task_number INTO NEW.number;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_assign_number
+    BEFORE INSERT OR UPDATE OF workspace_id ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_assign_number();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS tasks_assign_number ON tasks;
+DROP FUNCTION IF EXISTS tasks_assign_number();
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_workspace_number_unique;
+ALTER TABLE tasks DROP COLUMN number;
+ALTER TABLE workspaces DROP COLUMN last_task_number;
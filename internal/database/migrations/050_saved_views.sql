@@ -0,0 +1,17 @@
+-- +goose Up
+-- Named GET /tasks queries an agent saved, listed with GET /tasks?view=<name>.
+CREATE TABLE saved_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL CHECK (name ~ '^[a-z0-9][a-z0-9_-]*$'),
+    params JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT saved_views_agent_name_unique UNIQUE (agent_id, name)
+);
+
+COMMENT ON TABLE saved_views IS 'Named task list filters and sort saved by an agent';
+COMMENT ON COLUMN saved_views.params IS 'GET /tasks query parameters, by name';
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_views;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- The deadline checker scans live tasks of the statuses that expire by
+-- deadline, oldest deadline first, in bounded batches.
+DROP INDEX IF EXISTS idx_tasks_overdue;
+CREATE INDEX idx_tasks_active_deadline ON tasks (status, status_deadline_at, id)
+    WHERE status_deadline_at IS NOT NULL AND deleted_at IS NULL
+      AND status IN ('NEW', 'IN_PROGRESS', 'BLOCKED');
+
+-- Default task lists: live tasks of a workspace, filtered by status and ranked
+-- by priority, then age.
+CREATE INDEX idx_tasks_list ON tasks (workspace_id, status, priority, created_at)
+    WHERE deleted_at IS NULL AND archived_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tasks_list;
+DROP INDEX IF EXISTS idx_tasks_active_deadline;
+CREATE INDEX idx_tasks_overdue ON tasks(status_deadline_at)
+    WHERE status_deadline_at IS NOT NULL AND status IN ('NEW', 'IN_PROGRESS', 'BLOCKED');
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Pruning task events would break the hash chain of a workspace's event log.
+-- Before events are pruned, the chain over every event recorded before the
+-- cutoff is recorded here, and later logs continue from it.
+CREATE TABLE event_log_checkpoints (
+    workspace_id UUID PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL CHECK (seq >= 0),
+    hash CHAR(64) NOT NULL,
+    covers_before TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE event_log_checkpoints IS 'Event log chain over the events recorded before covers_before, kept when older events are pruned';
+COMMENT ON COLUMN event_log_checkpoints.seq IS 'Number of log records covered; the log continues at seq + 1';
+COMMENT ON COLUMN event_log_checkpoints.hash IS 'Hash of record seq, the prev_hash of the next record';
+
+-- Pruning keeps events still referenced by deliveries and notifications
+CREATE INDEX idx_event_outbox_event_id ON event_outbox (event_id);
+CREATE INDEX idx_escalation_notifications_event_id ON escalation_notifications (event_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_escalation_notifications_event_id;
+DROP INDEX IF EXISTS idx_event_outbox_event_id;
+DROP TABLE IF EXISTS event_log_checkpoints;
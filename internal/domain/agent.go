@@ -1,13 +1,59 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Agent represents an AI agent registered in the system.
 type Agent struct {
-	ID          string
-	WorkspaceID string
-	Name        string
-	Token       string
-	IsActive    bool
-	CreatedAt   time.Time
+	ID           string
+	WorkspaceID  string
+	Name         string
+	Token        string
+	IsActive     bool
+	Capabilities []string
+	LastSeenAt   *time.Time // last heartbeat; nil if the agent never sent one
+	CreatedAt    time.Time
+}
+
+// IsStale reports whether the agent's last heartbeat is older than staleAfter.
+// Agents that never sent a heartbeat are not stale: nothing is known about them.
+func (a *Agent) IsStale(now time.Time, staleAfter time.Duration) bool {
+	return a.LastSeenAt != nil && now.Sub(*a.LastSeenAt) > staleAfter
+}
+
+// Reasons recorded on released events.
+const (
+	ReleaseReasonAgentInactive = "agent_inactive"
+	ReleaseReasonAgentStale    = "agent_stale"
+)
+
+// ReleaseReason reports why the agent's IN_PROGRESS tasks should return to the
+// pool: it was deactivated or went stale. Empty when they should stay with it.
+func (a *Agent) ReleaseReason(now time.Time, staleAfter time.Duration) string {
+	switch {
+	case !a.IsActive:
+		return ReleaseReasonAgentInactive
+	case a.IsStale(now, staleAfter):
+		return ReleaseReasonAgentStale
+	default:
+		return ""
+	}
+}
+
+// HasCapabilities checks if the agent has every one of the required capabilities.
+func (a *Agent) HasCapabilities(required []string) bool {
+	return len(a.MissingCapabilities(required)) == 0
+}
+
+// MissingCapabilities returns the required capabilities the agent lacks.
+func (a *Agent) MissingCapabilities(required []string) []string {
+	missing := []string{}
+	for _, capability := range required {
+		if !slices.Contains(a.Capabilities, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
 }
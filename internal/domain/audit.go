@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// AuditAction names an operator action recorded in the admin audit log.
+type AuditAction string
+
+const (
+	AuditReadTokenCreated    AuditAction = "read_token.created"
+	AuditReadTokenRevoked    AuditAction = "read_token.revoked"
+	AuditAgentCapabilities   AuditAction = "agent.capabilities_set"
+	AuditTaskDeleted         AuditAction = "task.deleted"
+	AuditTaskTransferred     AuditAction = "task.transferred"
+	AuditHumanReviewCleared  AuditAction = "task.human_review_cleared"
+	AuditTaskApproved        AuditAction = "task.approved"
+	AuditTaskRejected        AuditAction = "task.rejected"
+	AuditAutoAssignStrategy  AuditAction = "workspace.auto_assign_set"
+	AuditPriorityInheritance AuditAction = "workspace.priority_inheritance_set"
+	AuditAutoUnblock         AuditAction = "workspace.auto_unblock_set"
+	AuditEscalationRoutes    AuditAction = "workspace.escalation_routes_set"
+	AuditAgentStaleAfter     AuditAction = "workspace.agent_stale_after_set"
+	AuditDoneValidation      AuditAction = "workspace.done_validation_set"
+	AuditPriorityAging       AuditAction = "workspace.priority_aging_set"
+	AuditClaimFairness       AuditAction = "workspace.claim_fairness_set"
+	AuditDeadlineWarning     AuditAction = "workspace.deadline_warning_set"
+	AuditDeadlineExpiry      AuditAction = "workspace.deadline_expiry_set"
+	AuditMaxAttempts         AuditAction = "workspace.max_attempts_set"
+	AuditEventWebhook        AuditAction = "workspace.event_webhook_set"
+	AuditEventBroker         AuditAction = "workspace.event_broker_set"
+	AuditIntakeForm          AuditAction = "workspace.intake_form_set"
+	AuditWorkspaceExported   AuditAction = "workspace.exported"
+	AuditEventLogExported    AuditAction = "workspace.event_log_exported"
+	AuditConfigImported      AuditAction = "workspace.config_imported"
+	AuditWorkspaceArchived   AuditAction = "workspace.archived"
+	AuditWorkspaceDeleted    AuditAction = "workspace.deleted"
+	AuditSandboxCreated      AuditAction = "workspace.sandbox_created"
+	AuditOutboxRetried       AuditAction = "outbox.retried"
+	AuditWebhookRotated      AuditAction = "webhook_secret.rotated"
+	AuditWebhookCompleted    AuditAction = "webhook_secret.rotation_completed"
+	AuditConfigReloaded      AuditAction = "config.reloaded"
+	AuditReadOnly            AuditAction = "config.read_only_set"
+)
+
+// AuditEntry is one operator action. WorkspaceID is nil for actions not tied to
+// a workspace and stays set after the workspace is deleted.
+type AuditEntry struct {
+	ID          string
+	Action      AuditAction
+	WorkspaceID *string
+	Details     map[string]any
+	CreatedAt   time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// AgentWorkload describes an agent's current load, used by the auto-assign job.
+type AgentWorkload struct {
+	Agent              *Agent
+	InProgressCount    int        // tasks in IN_PROGRESS assigned to the agent
+	ActiveCount        int        // assigned tasks in any non-terminal status
+	LastAutoAssignedAt *time.Time // nil if the agent was never auto-assigned
+}
+
+// IsIdle returns true if the agent has no task in progress.
+func (w *AgentWorkload) IsIdle() bool {
+	return w.InProgressCount == 0
+}
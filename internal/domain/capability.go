@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxCapabilities limits how many capabilities an agent or task may list.
+	MaxCapabilities = 20
+	// MaxCapabilityLength limits the length of a single capability name.
+	MaxCapabilityLength = 50
+)
+
+// NormalizeCapabilities trims, lowercases, deduplicates and sorts capability names.
+// Returns ErrValidation for empty, overlong or too many entries.
+func NormalizeCapabilities(capabilities []string) ([]string, error) {
+	normalized := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		capability = strings.ToLower(strings.TrimSpace(capability))
+		if capability == "" || len(capability) > MaxCapabilityLength {
+			return nil, fmt.Errorf("%w: capability names must be 1-%d characters", ErrValidation, MaxCapabilityLength)
+		}
+		normalized = append(normalized, capability)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) > MaxCapabilities {
+		return nil, fmt.Errorf("%w: at most %d capabilities allowed", ErrValidation, MaxCapabilities)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// ChecklistItem is a part of a task that a helper agent can claim and complete
+// without taking over the whole task.
+type ChecklistItem struct {
+	ID          string
+	TaskID      string
+	Title       string
+	Position    int
+	ClaimedBy   *string
+	ClaimedAt   *time.Time
+	CompletedBy *string
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+}
+
+// IsCompleted returns true if the item has been completed.
+func (i *ChecklistItem) IsCompleted() bool {
+	return i.CompletedAt != nil
+}
+
+// IsClaimedBy checks if the item is claimed by the given agent.
+func (i *ChecklistItem) IsClaimedBy(agentID string) bool {
+	return i.ClaimedBy != nil && *i.ClaimedBy == agentID
+}
+
+// ChecklistProgress summarises completion of a task's checklist.
+type ChecklistProgress struct {
+	Total     int
+	Completed int
+}
+
+// NewChecklistProgress counts completed items.
+func NewChecklistProgress(items []*ChecklistItem) ChecklistProgress {
+	progress := ChecklistProgress{Total: len(items)}
+	for _, item := range items {
+		if item.IsCompleted() {
+			progress.Completed++
+		}
+	}
+	return progress
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Thursday
+	from := time.Date(2026, time.January, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, time.January, 16, 10, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, time.January, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, time.January, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches
+		{"0 0 20 * 5", time.Date(2026, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cron.Next(from))
+		})
+	}
+}
+
+func TestCronSchedule_NextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	cron, err := ParseCron("0 9 * * *")
+	require.NoError(t, err)
+
+	// 09:00 in Berlin is 08:00 UTC in winter
+	next := cron.Next(time.Date(2026, time.January, 15, 8, 30, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2026, time.January, 16, 8, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@every 5m"} {
+		_, err := ParseCron(expr)
+		assert.ErrorIs(t, err, ErrInvalidCron, expr)
+	}
+
+	// Valid syntax that never fires
+	schedule := &Schedule{CronExpr: "0 0 30 2 *", Timezone: "UTC"}
+	_, err := schedule.NextRun(time.Now())
+	assert.ErrorIs(t, err, ErrInvalidCron)
+}
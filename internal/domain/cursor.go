@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventCursor marks a position in a task's events ordered by creation time,
+// with the event ID breaking ties between events created at the same instant.
+type EventCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c EventCursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseEventCursor decodes a token made by EventCursor.String.
+func ParseEventCursor(token string) (EventCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrValidation)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return EventCursor{}, invalid
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return EventCursor{}, invalid
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return EventCursor{}, invalid
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return EventCursor{}, invalid
+	}
+
+	return EventCursor{CreatedAt: t, ID: id}, nil
+}
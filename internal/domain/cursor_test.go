@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCursor_RoundTrip(t *testing.T) {
+	cursor := EventCursor{
+		CreatedAt: time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.FixedZone("CET", 3600)),
+		ID:        "0b6f2f1e-6a53-4c1e-9d59-3b1c7f0e2a11",
+	}
+
+	parsed, err := ParseEventCursor(cursor.String())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+
+	for _, token := range []string{"", "not base64!", "bm8tc2VwYXJhdG9y", EventCursor{CreatedAt: time.Now(), ID: "nope"}.String()} {
+		_, err := ParseEventCursor(token)
+		assert.ErrorIs(t, err, ErrValidation, token)
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table of UnifiedDiff. Larger inputs are diffed as a
+// whole-text replacement instead.
+const maxDiffCells = 4 << 20
+
+// diffOp is one line of a line diff: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the line diff from oldText to newText in unified format:
+// "@@ -l,s +l,s @@" hunks with up to contextLines unchanged lines around each
+// change, without file headers. Returns "" if the texts are equal.
+func UnifiedDiff(oldText, newText string, contextLines int) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(strings.Split(oldText, "\n"), strings.Split(newText, "\n"))
+
+	var b strings.Builder
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(ops); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				break
+			}
+			end = run
+		}
+
+		// Advance line counters to the first context line of the hunk
+		hunkStart := max(first-contextLines, start)
+		for _, op := range ops[start:hunkStart] {
+			oldLine, newLine = advanceDiffLines(op, oldLine, newLine)
+		}
+		hunkEnd := min(end+contextLines, len(ops))
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		oldStart, newStart := oldLine, newLine
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+			b.WriteByte('\n')
+			oldLine, newLine = advanceDiffLines(op, oldLine, newLine)
+		}
+		start = hunkEnd
+	}
+
+	return b.String()
+}
+
+// advanceDiffLines moves the old and new line numbers past op.
+func advanceDiffLines(op diffOp, oldLine, newLine int) (int, int) {
+	if op.kind != '+' {
+		oldLine++
+	}
+	if op.kind != '-' {
+		newLine++
+	}
+	return oldLine, newLine
+}
+
+// diffLines computes a minimal line diff from the longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: '-', text: line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: '+', text: line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', text: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{kind: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{kind: '+', text: b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb",
+			new:  "a\nb",
+			want: "",
+		},
+		{
+			name:    "changed line",
+			old:     "a\nb\nc",
+			new:     "a\nB\nc",
+			context: 3,
+			want:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "appended line",
+			old:     "a\nb",
+			new:     "a\nb\nc",
+			context: 1,
+			want:    "@@ -2,1 +2,2 @@\n b\n+c\n",
+		},
+		{
+			name:    "insertion into empty text",
+			old:     "",
+			new:     "a",
+			context: 0,
+			want:    "@@ -1,1 +1,1 @@\n-\n+a\n",
+		},
+		{
+			name:    "pure insertion without context",
+			old:     "a\nc",
+			new:     "a\nb\nc",
+			context: 0,
+			want:    "@@ -1,0 +2,1 @@\n+b\n",
+		},
+		{
+			name:    "distant changes make separate hunks",
+			old:     "1\n2\n3\n4\n5\n6\n7",
+			new:     "x\n2\n3\n4\n5\n6\ny",
+			context: 1,
+			want:    "@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -6,2 +6,2 @@\n 6\n-7\n+y\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			old:     "1\n2\n3\n4",
+			new:     "x\n2\n3\ny",
+			context: 1,
+			want:    "@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnifiedDiff(tt.old, tt.new, tt.context))
+		})
+	}
+}
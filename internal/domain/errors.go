@@ -10,11 +10,75 @@ var (
 	ErrInvalidTransition  = errors.New("invalid status transition")
 	ErrUnresolvedBlockers = errors.New("task has unresolved blockers")
 	ErrCyclicDependency   = errors.New("cyclic dependency detected")
+	ErrHumanReviewHold    = errors.New("task is held for human review")
+	ErrApprovalRequired   = errors.New("task requires operator approval")
+	ErrVersionConflict    = errors.New("task changed since it was read")
+
+	// Claim fairness errors
+	ErrClaimQuotaExceeded = errors.New("claim quota exceeded")
+	ErrClaimTurn          = errors.New("another idle agent is due to claim first")
+
+	// DONE validation errors
+	ErrDoneRejected              = errors.New("completion rejected by the workspace's validation webhook")
+	ErrDoneValidationUnavailable = errors.New("completion validation webhook unavailable")
+
+	// External reference errors
+	ErrExternalRefNotFound = errors.New("no task awaits this external reference")
+
+	// Queue errors
+	ErrQueueNotFound   = errors.New("queue not found")
+	ErrQueueExists     = errors.New("queue already exists")
+	ErrQueueNotEmpty   = errors.New("queue still has tasks")
+	ErrNoClaimableTask = errors.New("no claimable task available")
+
+	// Label errors
+	ErrLabelNotFound = errors.New("label not found")
+	ErrLabelExists   = errors.New("label already exists")
+	ErrUnknownLabel  = errors.New("label is not registered in the workspace")
+
+	// Schedule errors
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrScheduleExists   = errors.New("schedule already exists")
+	ErrInvalidCron      = errors.New("invalid cron expression")
+	ErrInvalidTimezone  = errors.New("invalid timezone")
+
+	// Saved view errors
+	ErrViewNotFound = errors.New("view not found")
+	ErrViewExists   = errors.New("view already exists")
+
+	// Report errors
+	ErrReportNotFound = errors.New("report not found")
+	ErrReportExists   = errors.New("report already exists")
+
+	// Escalation errors
+	ErrEscalationNotificationNotFound = errors.New("escalation notification not found")
+
+	// Outbox errors
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+
+	// Message errors
+	ErrMessageNotFound = errors.New("message not found")
+
+	// Intake errors
+	ErrIntakeNotFound    = errors.New("intake form not found")
+	ErrInvalidIntakeKey  = errors.New("invalid intake key")
+	ErrIntakeRateLimited = errors.New("intake rate limit exceeded")
+
+	// Checklist errors
+	ErrChecklistItemNotFound  = errors.New("checklist item not found")
+	ErrChecklistItemClaimed   = errors.New("checklist item already claimed")
+	ErrChecklistItemCompleted = errors.New("checklist item already completed")
+
+	// Comment errors
+	ErrCommentNotFound         = errors.New("comment not found")
+	ErrCommentEditWindowClosed = errors.New("comment can no longer be edited")
+	ErrCommentRedacted         = errors.New("comment was redacted")
 
 	// Permission errors
-	ErrPermissionDenied = errors.New("permission denied")
-	ErrNotTaskOwner     = errors.New("not task owner")
-	ErrNotTaskCreator   = errors.New("not task creator")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrNotTaskOwner        = errors.New("not task owner")
+	ErrNotTaskCreator      = errors.New("not task creator")
+	ErrMissingCapabilities = errors.New("agent lacks required capabilities")
 
 	// Agent errors
 	ErrAgentNotFound = errors.New("agent not found")
@@ -23,12 +87,51 @@ var (
 
 	// Workspace errors
 	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceArchived = errors.New("workspace is archived")
+	ErrExportRequired    = errors.New("workspace must be exported before deletion")
+	ErrWorkspaceExists   = errors.New("workspace slug already in use")
+
+	// Read token errors
+	ErrReadTokenNotFound = errors.New("read token not found")
+
+	// Webhook secret errors
+	ErrWebhookSecretNotFound = errors.New("webhook secret not found")
+	ErrRotationInProgress    = errors.New("webhook secret rotation already in progress")
+	ErrNoRotationInProgress  = errors.New("no webhook secret rotation in progress")
 
 	// Validation errors
+	ErrValidation         = errors.New("validation failed")
 	ErrInvalidStatus      = errors.New("invalid task status")
 	ErrInvalidVisibility  = errors.New("invalid task visibility")
 	ErrInvalidPriority    = errors.New("invalid task priority")
 	ErrEmptyComment       = errors.New("comment is required")
 	ErrArtefactRequired   = errors.New("artefact URL is required to close a task")
 	ErrInvalidArtefactURL = errors.New("artefact must be a valid http:// or https:// URL")
+	ErrEventDataTooLarge  = errors.New("event data exceeds maximum size")
+	ErrTaskResultTooLarge = errors.New("task result exceeds maximum size")
+	ErrInvalidExternalRef = errors.New("invalid external reference")
 )
+
+// DetailedError attaches structured details to an error, e.g. the blockers a
+// task still waits for, so clients can act on it without parsing the message.
+type DetailedError struct {
+	Err     error
+	Details map[string]any
+}
+
+func (e *DetailedError) Error() string { return e.Err.Error() }
+func (e *DetailedError) Unwrap() error { return e.Err }
+
+// WithDetails attaches details to err.
+func WithDetails(err error, details map[string]any) error {
+	return &DetailedError{Err: err, Details: details}
+}
+
+// ErrorDetails returns the details attached to err or an error it wraps, or nil.
+func ErrorDetails(err error) map[string]any {
+	var detailed *DetailedError
+	if errors.As(err, &detailed) {
+		return detailed.Details
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// MaxEscalationRoutes limits the routing rules of a workspace.
+	MaxEscalationRoutes = 50
+	// MaxEscalationRouteNameLength limits route names.
+	MaxEscalationRouteNameLength = 100
+	// MaxEscalationDeliveryAttempts is how often a webhook or email notification
+	// is tried before it is marked failed.
+	MaxEscalationDeliveryAttempts = 5
+)
+
+// DefaultEscalationRoute names the fallback used when no rule matches: the
+// escalation goes to the task's creator.
+const DefaultEscalationRoute = "default"
+
+// EscalationTargetType is who receives an escalation notification.
+type EscalationTargetType string
+
+const (
+	// EscalationTargetAgent puts the notification in an agent's escalation inbox
+	EscalationTargetAgent EscalationTargetType = "agent"
+	// EscalationTargetWebhook POSTs the notification to an http(s) URL, e.g. a team channel
+	EscalationTargetWebhook EscalationTargetType = "webhook"
+	// EscalationTargetEmail mails the notification to a comma-separated list of addresses
+	EscalationTargetEmail EscalationTargetType = "email"
+)
+
+// IsValid checks if the escalation target type is valid.
+func (t EscalationTargetType) IsValid() bool {
+	switch t {
+	case EscalationTargetAgent, EscalationTargetWebhook, EscalationTargetEmail:
+		return true
+	default:
+		return false
+	}
+}
+
+// EscalationRoute is a workspace rule deciding who is notified of an escalation.
+// Rules are evaluated in position order and the first match wins. A rule matches
+// tasks meeting all of its set criteria; a rule without criteria matches every task.
+type EscalationRoute struct {
+	ID          string
+	WorkspaceID string
+	Position    int // evaluation order, from 0
+	Name        string
+
+	// Criteria; nil matches any task
+	Label     *string       // the task carries this label
+	Priority  *TaskPriority // the task has this priority
+	CreatorID *string       // the task was created by this agent
+
+	TargetType EscalationTargetType
+	Target     string // agent ID, webhook URL or comma-separated email addresses
+	CreatedAt  time.Time
+}
+
+// Matches reports whether the route applies to the task.
+func (r *EscalationRoute) Matches(task *Task) bool {
+	if r.Label != nil && !slices.Contains(task.Labels, *r.Label) {
+		return false
+	}
+	if r.Priority != nil && task.Priority != *r.Priority {
+		return false
+	}
+	if r.CreatorID != nil && task.CreatorID != *r.CreatorID {
+		return false
+	}
+	return true
+}
+
+// MatchEscalationRoute returns the first route, in position order, that applies
+// to the task, or nil if none does.
+func MatchEscalationRoute(routes []*EscalationRoute, task *Task) *EscalationRoute {
+	for _, route := range routes {
+		if route.Matches(task) {
+			return route
+		}
+	}
+	return nil
+}
+
+// EscalationRetryDelay returns how long to wait before retrying a delivery that
+// failed attempts times: 1, 4, 9, 16 minutes.
+func EscalationRetryDelay(attempts int) time.Duration {
+	return time.Duration(attempts*attempts) * time.Minute
+}
+
+// EscalationNotificationStatus tracks the delivery of an escalation notification.
+type EscalationNotificationStatus string
+
+const (
+	// EscalationPending notifications wait for the scheduler to deliver them
+	EscalationPending EscalationNotificationStatus = "pending"
+	// EscalationDelivered notifications reached their target; agent notifications are delivered on creation
+	EscalationDelivered EscalationNotificationStatus = "delivered"
+	// EscalationFailed notifications gave up after MaxEscalationDeliveryAttempts
+	EscalationFailed EscalationNotificationStatus = "failed"
+)
+
+// EscalationNotification tells a route's target that a task was escalated.
+type EscalationNotification struct {
+	ID            string
+	WorkspaceID   string
+	TaskID        string
+	EventID       string // the escalated event
+	Route         string // name of the matched route, or DefaultEscalationRoute
+	TargetType    EscalationTargetType
+	Target        string
+	Payload       map[string]any // the task and escalation when escalated; the delivered document
+	Status        EscalationNotificationStatus
+	Attempts      int
+	NextAttemptAt time.Time // when a pending delivery is tried next
+	LastError     *string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// SnapshotManifest describes the point in time a workspace snapshot was taken.
+// All rows of the snapshot come from a single REPEATABLE READ transaction.
+type SnapshotManifest struct {
+	WorkspaceID string
+	SnapshotAt  time.Time // transaction start time
+	SnapshotLSN string    // WAL position at snapshot time
+	TxSnapshot  string    // pg_current_snapshot() of the exporting transaction
+	AgentCount  int
+	TaskCount   int
+	EventCount  int
+}
+
+// WorkspaceSnapshot is a mutually consistent copy of a workspace's data.
+type WorkspaceSnapshot struct {
+	Manifest  SnapshotManifest
+	Workspace *Workspace
+	Agents    []*Agent
+	Queues    []*Queue
+	Labels    []*Label
+	Tasks     []*Task
+	Events    []*TaskEvent
+}
+
+// EventLogCheckpoint is the event log chain over every event of a workspace
+// recorded before Before, kept when older events are pruned. Later logs start
+// at Seq+1 with Hash as the prev_hash of their first record.
+type EventLogCheckpoint struct {
+	WorkspaceID string
+	Seq         int
+	Hash        string
+	Before      time.Time
+	CreatedAt   time.Time
+}
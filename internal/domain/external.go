@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits for ExternalRef fields.
+const (
+	MaxExternalSystemLength = 100
+	MaxExternalIDLength     = 255
+)
+
+// ExternalRef identifies the item in an external system an AWAITING_EXTERNAL task waits on.
+type ExternalRef struct {
+	System string  // e.g. "github", "jira", "ci"
+	ID     string  // identifier within System, e.g. "acme/api#412"
+	URL    *string // optional link for humans
+}
+
+// NormalizeExternalRef trims the reference fields and checks their lengths.
+// The system name is lowercased so integrations can match it reliably.
+func NormalizeExternalRef(ref ExternalRef) (ExternalRef, error) {
+	ref.System = strings.ToLower(strings.TrimSpace(ref.System))
+	ref.ID = strings.TrimSpace(ref.ID)
+
+	if ref.System == "" || len(ref.System) > MaxExternalSystemLength {
+		return ref, fmt.Errorf("%w: system must be 1-%d characters", ErrInvalidExternalRef, MaxExternalSystemLength)
+	}
+	if ref.ID == "" || len(ref.ID) > MaxExternalIDLength {
+		return ref, fmt.Errorf("%w: id must be 1-%d characters", ErrInvalidExternalRef, MaxExternalIDLength)
+	}
+	if ref.URL != nil && *ref.URL == "" {
+		ref.URL = nil
+	}
+
+	return ref, nil
+}
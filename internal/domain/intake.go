@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// IntakeKeyPrefix marks intake form keys.
+const IntakeKeyPrefix = "sli_"
+
+// IntakeLabel is the label carried by every task submitted through an intake form.
+// It is registered in the workspace on first use.
+const IntakeLabel = "intake"
+
+// Limits of intake submissions. The per-hour limit applies to the whole workspace.
+const (
+	DefaultIntakeRateLimit     = 10
+	MaxIntakeRateLimit         = 1000
+	MaxIntakeDescriptionLength = 10000
+	MaxIntakeContactLength     = 200
+)
+
+// IntakeForm lets humans without an agent token submit work to a workspace.
+// Submissions become NEW tasks created by the form's agent.
+type IntakeForm struct {
+	WorkspaceID      string
+	AgentID          string // recorded as the creator of submitted tasks
+	KeyHash          string
+	RateLimitPerHour int // submissions accepted per workspace per hour
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// IntakeSubmission is work requested through an intake form.
+type IntakeSubmission struct {
+	Title       string
+	Description string
+	Contact     string // optional: how to reach the submitter
+}
+
+// HashIntakeKey returns the hex-encoded SHA-256 hash under which an intake key is stored.
+func HashIntakeKey(key string) string {
+	return HashReadToken(key)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxLabelNameLength limits label names.
+	MaxLabelNameLength = 50
+	// MaxTaskLabels limits how many labels a task may carry.
+	MaxTaskLabels = 20
+	// DefaultLabelColor is used when a label is registered without a color.
+	DefaultLabelColor = "#6b7280"
+)
+
+var (
+	labelNamePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]*$`)
+	labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+)
+
+// Label is a registered task label of a workspace.
+type Label struct {
+	ID          string
+	WorkspaceID string
+	Name        string
+	Color       string // "#rrggbb"
+	Description string
+	UsageCount  int // number of tasks carrying the label
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NormalizeLabelName lowercases and trims a label name and checks its format:
+// letters, digits, '-', '_', '.' and ':', starting with a letter or digit.
+func NormalizeLabelName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > MaxLabelNameLength || !labelNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: label name must be 1-%d characters of a-z, 0-9, '-', '_', '.' or ':'", ErrValidation, MaxLabelNameLength)
+	}
+	return name, nil
+}
+
+// NormalizeLabels normalizes, deduplicates and sorts the label names of a task.
+func NormalizeLabels(labels []string) ([]string, error) {
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		name, err := NormalizeLabelName(label)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, name)
+	}
+
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) > MaxTaskLabels {
+		return nil, fmt.Errorf("%w: at most %d labels allowed", ErrValidation, MaxTaskLabels)
+	}
+
+	return normalized, nil
+}
+
+// NormalizeLabelColor lowercases a "#rrggbb" color.
+func NormalizeLabelColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !labelColorPattern.MatchString(color) {
+		return "", fmt.Errorf("%w: color must be a hex color like #1f883d", ErrValidation)
+	}
+	return color, nil
+}
@@ -0,0 +1,25 @@
+package domain
+
+// LineageRelation describes how a task in a lineage tree relates to its parent node.
+type LineageRelation string
+
+const (
+	// LineageRelationBlocks marks a node that blocks its parent (parent lists it in blocked_by).
+	LineageRelationBlocks LineageRelation = "blocks"
+	// LineageRelationBlockedBy marks a node that is blocked by its parent.
+	LineageRelationBlockedBy LineageRelation = "blocked_by"
+)
+
+// LineageNode is a task within a lineage tree together with its related tasks.
+type LineageNode struct {
+	Task     *Task
+	Relation LineageRelation
+	Children []*LineageNode
+}
+
+// TaskLineage holds the ancestry (upstream) and descendant (downstream) trees of a task.
+type TaskLineage struct {
+	Task        *Task
+	Ancestors   []*LineageNode
+	Descendants []*LineageNode
+}
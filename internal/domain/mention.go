@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// mentionPattern matches @name at the start of the text or after a character
+// that can't be part of an e-mail address, so user@example.com is no mention.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@-])@([\w][\w.-]*)`)
+
+// ParseMentions returns the agent names mentioned as @name in text, in order of
+// first mention. Names may contain letters, digits, '_', '-' and '.', but don't
+// end with '.' so "ask @alice." mentions alice.
+func ParseMentions(text string) []string {
+	names := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
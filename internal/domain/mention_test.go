@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "none", text: "All tests pass", want: []string{}},
+		{name: "start of text", text: "@reviewer please look", want: []string{"reviewer"}},
+		{name: "several in order", text: "cc @agent-2, @agent-1 and @agent-2", want: []string{"agent-2", "agent-1"}},
+		{name: "trailing period", text: "Ask @planner.v2.", want: []string{"planner.v2"}},
+		{name: "in parentheses", text: "(see @ops_bot)", want: []string{"ops_bot"}},
+		{name: "e-mail address", text: "mail ops@example.com", want: []string{}},
+		{name: "bare at sign", text: "meet @ 10:00", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMentions(tt.text))
+		})
+	}
+}
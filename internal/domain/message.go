@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// MaxMessageBodyLength limits the body of a direct message, in characters.
+const MaxMessageBodyLength = 10000
+
+// TaskMessage is a direct message from one agent to another about a task. Unlike
+// comments it is not part of the task's events and only its two agents see it.
+type TaskMessage struct {
+	ID          string
+	TaskID      string
+	SenderID    string
+	RecipientID string
+	Body        string
+	ReadAt      *time.Time // set once the recipient acknowledges it
+	CreatedAt   time.Time
+}
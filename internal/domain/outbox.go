@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// MaxOutboxDeliveryAttempts is how often an event is offered to the webhook or
+// broker before its outbox message is marked failed.
+const MaxOutboxDeliveryAttempts = 10
+
+// OutboxRetryDelay returns how long to wait before retrying a delivery that
+// failed attempts times: 1, 4, 9, 16 minutes and so on, about 5 hours in all.
+func OutboxRetryDelay(attempts int) time.Duration {
+	return time.Duration(attempts*attempts) * time.Minute
+}
+
+// OutboxStatus tracks the delivery of an outbox message.
+type OutboxStatus string
+
+const (
+	// OutboxPending messages wait for the scheduler to deliver them
+	OutboxPending OutboxStatus = "pending"
+	// OutboxDelivered messages were accepted by the webhook or broker
+	OutboxDelivered OutboxStatus = "delivered"
+	// OutboxFailed messages gave up after MaxOutboxDeliveryAttempts; operators can retry them
+	OutboxFailed OutboxStatus = "failed"
+)
+
+// IsValid checks if the outbox status is valid.
+func (s OutboxStatus) IsValid() bool {
+	switch s {
+	case OutboxPending, OutboxDelivered, OutboxFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// OutboxTargetType is where an outbox message is published.
+type OutboxTargetType string
+
+const (
+	// OutboxTargetWebhook messages are POSTed to the workspace's event webhook
+	OutboxTargetWebhook OutboxTargetType = "webhook"
+	// OutboxTargetBroker messages are published to the workspace's NATS subject or
+	// Kafka topic; they wait while the scheduler runs without a broker
+	OutboxTargetBroker OutboxTargetType = "broker"
+)
+
+// OutboxMessage is a task event waiting to be, or already, published to a
+// workspace's event webhook or broker topic. It is written in the transaction
+// writing the event.
+type OutboxMessage struct {
+	ID            string
+	Seq           int64 // delivery order; a task's messages to a target type are delivered one after another
+	WorkspaceID   string
+	TaskID        string
+	EventID       string
+	TargetType    OutboxTargetType
+	Target        string // webhook URL or broker topic when the event was written
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time // when a pending delivery is tried next
+	LastError     *string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+
+	Event *TaskEvent // the published event; set when read for delivery
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxQueueNameLength limits queue names.
+const MaxQueueNameLength = 50
+
+var queueNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Queue is a named pipeline of tasks within a workspace.
+type Queue struct {
+	ID          string
+	WorkspaceID string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+// NormalizeQueueName lowercases and trims a queue name and checks its format:
+// letters, digits, '-' and '_', starting with a letter or digit.
+func NormalizeQueueName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > MaxQueueNameLength || !queueNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: queue name must be 1-%d characters of a-z, 0-9, '-' or '_'", ErrValidation, MaxQueueNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ReadTokenPrefix distinguishes read tokens from agent tokens.
+const ReadTokenPrefix = "slr_"
+
+// ReadToken is a workspace-scoped, read-only API credential not tied to an agent.
+type ReadToken struct {
+	ID          string
+	WorkspaceID string
+	Name        string
+	TokenHash   string
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// IsUsable returns true if the token is neither revoked nor expired at the given time.
+func (t *ReadToken) IsUsable(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// HashReadToken returns the hex-encoded SHA-256 hash under which a read token is stored.
+func HashReadToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,121 @@
+package domain
+
+import "time"
+
+// MaxReportNameLength limits report names.
+const MaxReportNameLength = 100
+
+// ReportKind selects what a scheduled report contains.
+type ReportKind string
+
+const (
+	// ReportKindWorkspaceSummary covers task flow and backlog health of the workspace
+	ReportKindWorkspaceSummary ReportKind = "workspace_summary"
+	// ReportKindAgentPerformance covers completed, cancelled and stuck tasks per agent
+	ReportKindAgentPerformance ReportKind = "agent_performance"
+)
+
+// IsValid checks if the report kind is valid.
+func (k ReportKind) IsValid() bool {
+	switch k {
+	case ReportKindWorkspaceSummary, ReportKindAgentPerformance:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultPeriod returns the period a report of this kind covers unless configured:
+// a day for workspace summaries, a week for agent performance.
+func (k ReportKind) DefaultPeriod() ReportPeriod {
+	if k == ReportKindAgentPerformance {
+		return ReportPeriodWeek
+	}
+	return ReportPeriodDay
+}
+
+// ReportFormat is the rendering of a report document.
+type ReportFormat string
+
+const (
+	ReportFormatJSON     ReportFormat = "json"
+	ReportFormatMarkdown ReportFormat = "markdown"
+)
+
+// IsValid checks if the report format is valid.
+func (f ReportFormat) IsValid() bool {
+	return f == ReportFormatJSON || f == ReportFormatMarkdown
+}
+
+// ReportPeriod is the time window a report covers, ending at the time it runs.
+type ReportPeriod string
+
+const (
+	ReportPeriodDay   ReportPeriod = "day"
+	ReportPeriodWeek  ReportPeriod = "week"
+	ReportPeriodMonth ReportPeriod = "month"
+)
+
+// IsValid checks if the report period is valid.
+func (p ReportPeriod) IsValid() bool {
+	switch p {
+	case ReportPeriodDay, ReportPeriodWeek, ReportPeriodMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start returns the beginning of the period ending at end.
+func (p ReportPeriod) Start(end time.Time) time.Time {
+	switch p {
+	case ReportPeriodWeek:
+		return end.AddDate(0, 0, -7)
+	case ReportPeriodMonth:
+		return end.AddDate(0, -1, 0)
+	default:
+		return end.AddDate(0, 0, -1)
+	}
+}
+
+// ReportTargetType is how a report is delivered.
+type ReportTargetType string
+
+const (
+	// ReportTargetWebhook POSTs the document to an http(s) URL
+	ReportTargetWebhook ReportTargetType = "webhook"
+	// ReportTargetEmail mails the document to a comma-separated list of addresses
+	ReportTargetEmail ReportTargetType = "email"
+)
+
+// IsValid checks if the report target type is valid.
+func (t ReportTargetType) IsValid() bool {
+	return t == ReportTargetWebhook || t == ReportTargetEmail
+}
+
+// Report is rendered and delivered to its target whenever its cron expression
+// fires. Like schedules, missed runs are not made up.
+type Report struct {
+	ID          string
+	WorkspaceID string
+	CreatorID   string
+	Name        string
+	Kind        ReportKind
+	Format      ReportFormat
+	Period      ReportPeriod
+	CronExpr    string
+	Timezone    string // IANA name the cron expression is evaluated in
+	TargetType  ReportTargetType
+	Target      string // webhook URL or comma-separated email addresses
+	Enabled     bool
+	NextRunAt   time.Time
+	LastRunAt   *time.Time
+	LastError   *string // why the last delivery failed
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NextRun returns the first time after t the report is due.
+func (r *Report) NextRun(t time.Time) (time.Time, error) {
+	return nextCronRun(r.CronExpr, r.Timezone, t)
+}
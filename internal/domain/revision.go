@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// TaskRevision is one version of a task's title and description.
+// Revisions are numbered from 1, the text the task was created with.
+type TaskRevision struct {
+	ID          string
+	TaskID      string
+	Revision    int
+	Title       string
+	Description string
+	AuthorID    string
+	CreatedAt   time.Time
+}
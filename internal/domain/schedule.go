@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// MaxScheduleNameLength limits schedule names.
+const MaxScheduleNameLength = 100
+
+// TaskTemplate holds the fields of the tasks a schedule creates.
+type TaskTemplate struct {
+	Title                string
+	Description          string
+	Priority             TaskPriority
+	Visibility           TaskVisibility
+	AssigneeID           *string // nil leaves the task NEW for any agent to claim
+	RequiredCapabilities []string
+	Queue                *string
+}
+
+// Schedule creates a task from its template whenever its cron expression fires.
+// Runs missed while no scheduler was running are not made up: the next run is
+// always computed from the time of the last one.
+type Schedule struct {
+	ID          string
+	WorkspaceID string
+	CreatorID   string // creator of the instantiated tasks
+	Name        string
+	CronExpr    string
+	Timezone    string // IANA name the cron expression is evaluated in
+	Enabled     bool
+	Template    TaskTemplate
+	NextRunAt   time.Time
+	LastRunAt   *time.Time
+	LastTaskID  *string
+	LastError   *string // why the last run created no task
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NextRun returns the first time after t the schedule fires.
+func (s *Schedule) NextRun(t time.Time) (time.Time, error) {
+	return nextCronRun(s.CronExpr, s.Timezone, t)
+}
+
+// nextCronRun returns the first time after t a cron expression fires in the
+// given IANA timezone.
+func nextCronRun(expr, timezone string, t time.Time) (time.Time, error) {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimezone
+	}
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, ErrInvalidCron
+	}
+	return next, nil
+}
@@ -1,17 +1,32 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// MaxTaskResultBytes limits the JSON-encoded size of Task.Result.
+const MaxTaskResultBytes = 256 * 1024
 
 // TaskStatus represents the status of a task in the state machine.
 type TaskStatus string
 
 const (
-	TaskStatusNew        TaskStatus = "NEW"
-	TaskStatusInProgress TaskStatus = "IN_PROGRESS"
-	TaskStatusBlocked    TaskStatus = "BLOCKED"
-	TaskStatusStuck      TaskStatus = "STUCK"
-	TaskStatusDone       TaskStatus = "DONE"
-	TaskStatusCancelled  TaskStatus = "CANCELLED"
+	TaskStatusNew         TaskStatus = "NEW"
+	TaskStatusInProgress  TaskStatus = "IN_PROGRESS"
+	TaskStatusNeedsReview TaskStatus = "NEEDS_REVIEW"
+	TaskStatusBlocked     TaskStatus = "BLOCKED"
+	TaskStatusStuck       TaskStatus = "STUCK"
+	TaskStatusDone        TaskStatus = "DONE"
+	TaskStatusCancelled   TaskStatus = "CANCELLED"
+
+	// TaskStatusAwaitingExternal marks work paused on an external system (see ExternalRef),
+	// as opposed to BLOCKED, which is a wait on other agents' tasks.
+	TaskStatusAwaitingExternal TaskStatus = "AWAITING_EXTERNAL"
+
+	// TaskStatusWaitingApproval marks finished work that only an operator may accept
+	// (see Task.RequiresApproval): no agent can move the task on.
+	TaskStatusWaitingApproval TaskStatus = "WAITING_APPROVAL"
 )
 
 // IsTerminal returns true if the status is terminal (no transitions allowed).
@@ -24,11 +39,29 @@ func (s TaskStatus) HasDeadline() bool {
 	return s == TaskStatusNew || s == TaskStatusInProgress || s == TaskStatusBlocked
 }
 
+// ExpiryTargets returns the statuses a task may be moved to when its deadline in
+// the status expires, the default first. Statuses without a deadline have none.
+func (s TaskStatus) ExpiryTargets() []TaskStatus {
+	switch s {
+	case TaskStatusNew:
+		return []TaskStatus{TaskStatusStuck, TaskStatusCancelled}
+	case TaskStatusInProgress, TaskStatusBlocked:
+		return []TaskStatus{TaskStatusStuck, TaskStatusNew, TaskStatusCancelled}
+	default:
+		return nil
+	}
+}
+
+// RequiresArtefact returns true if moving into the status requires an artefact URL.
+func (s TaskStatus) RequiresArtefact() bool {
+	return s == TaskStatusDone || s == TaskStatusNeedsReview || s == TaskStatusWaitingApproval
+}
+
 // IsValid checks if the status is one of the allowed values.
 func (s TaskStatus) IsValid() bool {
 	switch s {
-	case TaskStatusNew, TaskStatusInProgress, TaskStatusBlocked,
-		TaskStatusStuck, TaskStatusDone, TaskStatusCancelled:
+	case TaskStatusNew, TaskStatusInProgress, TaskStatusNeedsReview, TaskStatusBlocked,
+		TaskStatusAwaitingExternal, TaskStatusWaitingApproval, TaskStatusStuck, TaskStatusDone, TaskStatusCancelled:
 		return true
 	default:
 		return false
@@ -43,6 +76,11 @@ const (
 	TaskVisibilityPrivate TaskVisibility = "private"
 )
 
+// IsValid checks if the visibility is one of the allowed values.
+func (v TaskVisibility) IsValid() bool {
+	return v == TaskVisibilityPublic || v == TaskVisibilityPrivate
+}
+
 // TaskPriority represents the priority level of a task.
 type TaskPriority string
 
@@ -53,29 +91,124 @@ const (
 	TaskPriorityCritical TaskPriority = "critical"
 )
 
+// IsValid checks if the priority is one of the allowed values.
+func (p TaskPriority) IsValid() bool {
+	switch p {
+	case TaskPriorityLow, TaskPriorityNormal, TaskPriorityHigh, TaskPriorityCritical:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rank orders priorities from most urgent (1, critical) to least (4, low).
+func (p TaskPriority) Rank() int {
+	switch p {
+	case TaskPriorityCritical:
+		return 1
+	case TaskPriorityHigh:
+		return 2
+	case TaskPriorityNormal:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Raised returns the next more urgent priority, or false for critical.
+func (p TaskPriority) Raised() (TaskPriority, bool) {
+	switch p {
+	case TaskPriorityLow:
+		return TaskPriorityNormal, true
+	case TaskPriorityNormal:
+		return TaskPriorityHigh, true
+	case TaskPriorityHigh:
+		return TaskPriorityCritical, true
+	default:
+		return p, false
+	}
+}
+
 // Task represents a unit of work for agents.
 type Task struct {
-	ID               string
-	WorkspaceID      string
-	Title            string
-	Description      string
-	CreatorID        string
-	AssigneeID       *string
-	Status           TaskStatus
-	Visibility       TaskVisibility
-	Priority         TaskPriority
-	BlockedBy        []string
-	StatusDeadlineAt *time.Time
-	Artefact         *string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	ID                   string
+	WorkspaceID          string
+	Number               int    // sequential within the workspace
+	Key                  string // e.g. TST-142, see ParseTaskKey
+	Title                string
+	Description          string
+	CreatorID            string
+	AssigneeID           *string
+	Status               TaskStatus
+	Visibility           TaskVisibility
+	Priority             TaskPriority
+	InheritedPriority    *TaskPriority // set while priority inheritance bumps a blocker
+	BlockedBy            []string
+	RequiredCapabilities []string     // agent must have all of these to claim
+	Queue                *string      // nil for the workspace's default pool
+	Labels               []string     // registered label names, sorted
+	ExternalRef          *ExternalRef // set while AWAITING_EXTERNAL
+	StatusDeadlineAt     *time.Time
+	Artefact             *string
+	Result               map[string]any // structured completion result, set on DONE
+	ArchivedAt           *time.Time     // set once a finished task is archived
+	Attempts             int            // times the task was taken over or returned to NEW
+	HumanReviewAt        *time.Time     // set once Attempts reached the workspace limit
+	RequiresApproval     bool           // only an operator may complete the task, through WAITING_APPROVAL
+	Version              int            // bumped by every update, for optimistic concurrency
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// CheckVersion returns ErrVersionConflict if the client expected another
+// version of the task than the current one. A nil expected version skips the
+// check.
+func (t *Task) CheckVersion(expected *int) error {
+	if expected == nil || *expected == t.Version {
+		return nil
+	}
+	return WithDetails(
+		fmt.Errorf("%w: task %s is at version %d, not %d", ErrVersionConflict, t.ID, t.Version, *expected),
+		map[string]any{"current_version": t.Version},
+	)
+}
+
+// EffectivePriority returns the priority the task is ranked by: the inherited
+// one while set, otherwise its own.
+func (t *Task) EffectivePriority() TaskPriority {
+	if t.InheritedPriority != nil {
+		return *t.InheritedPriority
+	}
+	return t.Priority
 }
 
 // IsClaimable checks if the task can be claimed by an agent.
 func (t *Task) IsClaimable() bool {
 	return t.Status == TaskStatusNew &&
 		t.AssigneeID == nil &&
-		t.Visibility == TaskVisibilityPublic
+		t.Visibility == TaskVisibilityPublic &&
+		!t.IsHeldForReview()
+}
+
+// IsHeldForReview reports whether the task changed hands too often and waits for
+// an operator: agents may not claim or take it over meanwhile.
+func (t *Task) IsHeldForReview() bool {
+	return t.HumanReviewAt != nil
+}
+
+// DeadlineInSeconds returns the seconds remaining until the status deadline relative to now.
+// The value is negative once the deadline has passed; nil when the task has no deadline.
+func (t *Task) DeadlineInSeconds(now time.Time) *int64 {
+	if t.StatusDeadlineAt == nil {
+		return nil
+	}
+	seconds := int64(t.StatusDeadlineAt.Sub(now) / time.Second)
+	return &seconds
+}
+
+// IsArchived reports whether the task is hidden from default task lists.
+func (t *Task) IsArchived() bool {
+	return t.ArchivedAt != nil
 }
 
 // IsOwnedBy checks if the task is assigned to the given agent.
@@ -87,3 +220,15 @@ func (t *Task) IsOwnedBy(agentID string) bool {
 func (t *Task) IsCreatedBy(agentID string) bool {
 	return t.CreatorID == agentID
 }
+
+// IsVisibleTo checks if the agent may read the task.
+// Tasks are visible within their workspace; private tasks only to creator and assignee.
+func (t *Task) IsVisibleTo(agent *Agent) bool {
+	if t.WorkspaceID != agent.WorkspaceID {
+		return false
+	}
+	if t.Visibility == TaskVisibilityPrivate {
+		return t.IsCreatedBy(agent.ID) || t.IsOwnedBy(agent.ID)
+	}
+	return true
+}
@@ -2,6 +2,9 @@ package domain
 
 import "time"
 
+// MaxEventDataBytes limits the JSON-encoded size of TaskEvent.Data.
+const MaxEventDataBytes = 64 * 1024
+
 // EventType represents the type of task event.
 type EventType string
 
@@ -13,8 +16,100 @@ const (
 	EventTypeTakenOver       EventType = "taken_over"
 	EventTypeCommented       EventType = "commented"
 	EventTypeDeadlineExpired EventType = "deadline_expired"
+	EventTypeReviewApproved  EventType = "review_approved"
+	EventTypeReviewRejected  EventType = "review_rejected"
+	EventTypeReopened        EventType = "reopened"
+
+	// External wait events carry data.external_system and data.external_id
+	EventTypeAwaitingExternal EventType = "awaiting_external"
+	EventTypeExternalResolved EventType = "external_resolved"
+
+	// Label changes carry data.added and data.removed and leave the task status unchanged
+	EventTypeLabelsChanged EventType = "labels_changed"
+
+	// Archival hides a DONE or CANCELLED task from default task lists
+	EventTypeArchived EventType = "archived"
+
+	// Edits of title or description carry data.revision and data.fields
+	EventTypeEdited EventType = "edited"
+
+	// Deletion hides the task everywhere; the event is kept until the task is purged
+	EventTypeDeleted EventType = "deleted"
+
+	// Checklist events carry data.checklist_item_id and leave the task status unchanged
+	EventTypeChecklistClaimed   EventType = "checklist_claimed"
+	EventTypeChecklistCompleted EventType = "checklist_completed"
+
+	// Priority inheritance events carry data.priority, data.inherited_priority and,
+	// for a bump, data.from_task_id; they leave the task status unchanged
+	EventTypePriorityInherited EventType = "priority_inherited"
+	EventTypePriorityRestored  EventType = "priority_restored"
+
+	// Release returns an IN_PROGRESS task to NEW when its assignee was deactivated
+	// or went stale; carries data.agent_id and data.reason
+	EventTypeReleased EventType = "released"
+
+	// Transfer moves a task with its events to another workspace as NEW and
+	// unassigned; carries data.from_workspace_id and data.to_workspace_id
+	EventTypeTransferred EventType = "transferred"
+
+	// Priority aging marks a NEW task left unclaimed past the workspace threshold;
+	// carries data.action, data.waited_seconds and the priority before and after
+	EventTypePriorityAged EventType = "priority_aged"
+
+	// Deadline warning marks a task with little of its status deadline left, once
+	// per deadline; carries data.status, data.deadline_at, data.remaining_seconds
+	// and data.percent
+	EventTypeDeadlineWarning EventType = "deadline_warning"
+
+	// Attempts exceeded marks a task held for human review once it changed hands
+	// as often as the workspace allows; carries data.attempts and data.max_attempts.
+	// Human review cleared lifts the hold and resets the attempts.
+	EventTypeAttemptsExceeded   EventType = "attempts_exceeded"
+	EventTypeHumanReviewCleared EventType = "human_review_cleared"
+
+	// An operator resolves a WAITING_APPROVAL task: approval moves it to DONE or
+	// IN_PROGRESS, rejection returns it to NEW and unassigned
+	EventTypeApprovalGranted  EventType = "approval_granted"
+	EventTypeApprovalRejected EventType = "approval_rejected"
+
+	// Blockers resolved marks an open task whose last blocker is done; carries
+	// data.blocker_id. With the workspace's auto-unblock, a BLOCKED task returns
+	// to its previous status, recorded on the same event; otherwise the status is unchanged.
+	EventTypeBlockersResolved EventType = "blockers_resolved"
+
+	// Comment edited records that the author edited or redacted a comment within
+	// CommentEditWindow; carries data.event_id and data.action (edited or
+	// redacted) but neither body, so a redacted secret isn't repeated
+	EventTypeCommentEdited EventType = "comment_edited"
+)
+
+// CommentEditWindow is how long after posting authors may edit or redact a comment.
+const CommentEditWindow = 15 * time.Minute
+
+// Actions recorded on comment_edited events.
+const (
+	CommentActionEdited   = "edited"
+	CommentActionRedacted = "redacted"
 )
 
+// IsValid checks if the event type is one of the known values.
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventTypeCreated, EventTypeStatusChanged, EventTypeClaimed, EventTypeEscalated,
+		EventTypeTakenOver, EventTypeCommented, EventTypeDeadlineExpired,
+		EventTypeReviewApproved, EventTypeReviewRejected, EventTypeReopened,
+		EventTypeAwaitingExternal, EventTypeExternalResolved, EventTypeLabelsChanged, EventTypeArchived, EventTypeEdited, EventTypeDeleted,
+		EventTypeChecklistClaimed, EventTypeChecklistCompleted, EventTypePriorityInherited, EventTypePriorityRestored,
+		EventTypeReleased, EventTypeTransferred, EventTypePriorityAged, EventTypeDeadlineWarning,
+		EventTypeAttemptsExceeded, EventTypeHumanReviewCleared, EventTypeApprovalGranted, EventTypeApprovalRejected,
+		EventTypeBlockersResolved, EventTypeCommentEdited:
+		return true
+	default:
+		return false
+	}
+}
+
 // TaskEvent represents an audit log entry for a task action.
 type TaskEvent struct {
 	ID        string
@@ -24,10 +119,31 @@ type TaskEvent struct {
 	OldStatus *TaskStatus
 	NewStatus *TaskStatus
 	Comment   string
+	Data      map[string]any // optional structured payload
+	Trace     *TraceContext  // trace context of the triggering request, if any
 	CreatedAt time.Time
+
+	// Set on comments the author edited or redacted
+	EditedAt   *time.Time
+	RedactedAt *time.Time
+
+	// Set on comments replying to another comment. ThreadID is the comment
+	// that started the thread.
+	ReplyToEventID *string
+	ThreadID       *string
 }
 
 // IsSystemEvent returns true if the event was created by the system.
 func (e *TaskEvent) IsSystemEvent() bool {
 	return e.ActorID == nil
 }
+
+// TaskChange announces a committed task event to listeners in any process. It
+// carries enough to filter on; listeners load the task or event for details.
+type TaskChange struct {
+	EventID     string      `json:"event_id"`
+	TaskID      string      `json:"task_id"`
+	WorkspaceID string      `json:"workspace_id"`
+	Type        EventType   `json:"type"`
+	NewStatus   *TaskStatus `json:"new_status"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Tasks are numbered per workspace in the order they were created. A task's
+// key is its workspace slug in upper case followed by its number, e.g. TST-142
+// for task 142 of workspace tst. Slugs never change, so keys don't either, and
+// they are unique across workspaces.
+
+// ParseTaskKey splits a task key into the workspace slug and the task number.
+// Case is ignored. ok is false if key has no slug or no positive number, and
+// for task IDs, some of which would otherwise pass for keys.
+func ParseTaskKey(key string) (slug string, number int, ok bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	if _, err := uuid.Parse(key); err == nil {
+		return "", 0, false
+	}
+
+	digits := key[i+1:]
+	if strings.TrimLeft(digits, "0123456789") != "" {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(digits)
+	if err != nil || number <= 0 {
+		return "", 0, false
+	}
+
+	return strings.ToLower(key[:i]), number, true
+}
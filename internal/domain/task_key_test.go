@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTaskKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		slug   string
+		number int
+		ok     bool
+	}{
+		{"TST-142", "tst", 142, true},
+		{"tst-7", "tst", 7, true},
+		{"ACME-PLATFORM-12", "acme-platform", 12, true},
+		{"TST-0", "", 0, false},
+		{"TST-3a", "", 0, false},
+		{"TST-+3", "", 0, false},
+		{"TST-", "", 0, false},
+		{"-5", "", 0, false},
+		{"142", "", 0, false},
+		{"0b6f2f1e-6a53-4c1e-9d59-3b1c7f0e2a11", "", 0, false},
+		{"0b6f2f1e-6a53-4c1e-9d59-301157002811", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		slug, number, ok := ParseTaskKey(tt.key)
+		assert.Equal(t, tt.ok, ok, tt.key)
+		if tt.ok {
+			assert.Equal(t, tt.slug, slug, tt.key)
+			assert.Equal(t, tt.number, number, tt.key)
+		}
+	}
+}
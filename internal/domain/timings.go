@@ -0,0 +1,226 @@
+package domain
+
+import "time"
+
+// TimingPeriod is a stretch of a task's life with one status and one assignee.
+// EndedAt is nil for the current period.
+type TimingPeriod struct {
+	Status     TaskStatus
+	AssigneeID *string
+	StartedAt  time.Time
+	EndedAt    *time.Time
+	Duration   time.Duration
+}
+
+// StatusTiming is the total time a task spent in a status.
+type StatusTiming struct {
+	Status   TaskStatus
+	Duration time.Duration
+	Entries  int // times the task entered the status
+}
+
+// AssigneeTiming is the total time a task was assigned to an agent.
+type AssigneeTiming struct {
+	AgentID   string
+	AgentName string
+	Duration  time.Duration
+	ByStatus  map[TaskStatus]time.Duration
+	Periods   int
+}
+
+// TaskTimings breaks a task's life down by status and by assignee. Time in DONE
+// or CANCELLED only counts once the task was reopened, so a finished task's
+// totals stop growing when it finishes.
+type TaskTimings struct {
+	TaskID    string
+	Status    TaskStatus
+	Since     time.Time // task creation
+	Until     time.Time // now, or when the task finished
+	Total     time.Duration
+	Periods   []TimingPeriod
+	Statuses  []StatusTiming   // in order of first entry
+	Assignees []AssigneeTiming // in order of first assignment
+}
+
+// ComputeTaskTimings reconstructs the status and assignee history of a task from
+// its events (oldest first) and measures it up to now.
+//
+// Assignees come from claimed and taken_over events (the actor), auto-assignment
+// (data.agent_id) and returns to NEW (none). The assignee of a task created
+// already assigned is read from its created event's data.assignee_id; for older
+// events without it, from the first later event revealing the assignee, else
+// from the task itself if it was never reassigned. Periods whose assignee
+// cannot be told have none.
+func ComputeTaskTimings(task *Task, events []*TaskEvent, now time.Time) *TaskTimings {
+	timings := &TaskTimings{TaskID: task.ID, Status: task.Status, Since: task.CreatedAt, Until: now}
+
+	type change struct {
+		at       time.Time
+		status   TaskStatus
+		assignee *string
+	}
+
+	var changes []change
+	var assignee *string
+	unresolved := -1 // first change whose assignee is not known yet
+
+	resolve := func(agentID string) {
+		for i := unresolved; i < len(changes); i++ {
+			changes[i].assignee = &agentID
+		}
+		assignee = &agentID
+		unresolved = -1
+	}
+
+	for _, event := range events {
+		if unresolved >= 0 {
+			if id, ok := impliedAssignee(event); ok {
+				resolve(id)
+			}
+		}
+
+		if event.NewStatus == nil {
+			continue
+		}
+		status := *event.NewStatus
+
+		// Once the assignment changes, an unknown earlier assignee stays unknown
+		switch {
+		case event.Type == EventTypeCreated:
+			if id, ok := eventDataString(event, "assignee_id"); ok {
+				assignee = &id
+			} else if status != TaskStatusNew {
+				unresolved = len(changes)
+			}
+		case status == TaskStatusNew:
+			unresolved = -1
+			assignee = nil
+		case event.Type == EventTypeClaimed || event.Type == EventTypeTakenOver:
+			unresolved = -1
+			assignee = event.ActorID
+		case event.Data["auto_assigned"] == true:
+			if id, ok := eventDataString(event, "agent_id"); ok {
+				unresolved = -1
+				assignee = &id
+			}
+		}
+
+		if n := len(changes); n > 0 && changes[n-1].status == status && sameAgent(changes[n-1].assignee, assignee) {
+			continue
+		}
+		changes = append(changes, change{at: event.CreatedAt, status: status, assignee: assignee})
+	}
+	if unresolved >= 0 {
+		for i := unresolved; i < len(changes); i++ {
+			changes[i].assignee = task.AssigneeID
+		}
+	}
+
+	// A task without history has been in its current state since creation
+	if len(changes) == 0 {
+		changes = append(changes, change{at: task.CreatedAt, status: task.Status, assignee: task.AssigneeID})
+	}
+	timings.Since = changes[0].at
+
+	statusIndex := make(map[TaskStatus]int)
+	assigneeIndex := make(map[string]int)
+
+	for i, c := range changes {
+		period := TimingPeriod{Status: c.status, AssigneeID: c.assignee, StartedAt: c.at}
+		if i+1 < len(changes) {
+			endedAt := changes[i+1].at
+			period.EndedAt = &endedAt
+			period.Duration = endedAt.Sub(c.at)
+		} else if c.status.IsTerminal() {
+			timings.Until = c.at
+		} else {
+			period.Duration = now.Sub(c.at)
+		}
+		if period.Duration < 0 {
+			period.Duration = 0
+		}
+		timings.Periods = append(timings.Periods, period)
+		timings.Total += period.Duration
+
+		idx, ok := statusIndex[c.status]
+		if !ok {
+			idx = len(timings.Statuses)
+			statusIndex[c.status] = idx
+			timings.Statuses = append(timings.Statuses, StatusTiming{Status: c.status})
+		}
+		timings.Statuses[idx].Duration += period.Duration
+		timings.Statuses[idx].Entries++
+
+		if c.assignee == nil {
+			continue
+		}
+		idx, ok = assigneeIndex[*c.assignee]
+		if !ok {
+			idx = len(timings.Assignees)
+			assigneeIndex[*c.assignee] = idx
+			timings.Assignees = append(timings.Assignees, AssigneeTiming{AgentID: *c.assignee, ByStatus: make(map[TaskStatus]time.Duration)})
+		}
+		at := &timings.Assignees[idx]
+		at.Duration += period.Duration
+		at.ByStatus[c.status] += period.Duration
+		if i == 0 || !sameAgent(changes[i-1].assignee, c.assignee) {
+			at.Periods++
+		}
+	}
+
+	return timings
+}
+
+// impliedAssignee returns the assignee an event reveals: one named in its data
+// by escalations and takeovers, or the actor of a transition only the assignee
+// may make.
+func impliedAssignee(event *TaskEvent) (string, bool) {
+	if event.Type != EventTypeCreated {
+		if id, ok := eventDataString(event, "assignee_id"); ok {
+			return id, true
+		}
+	}
+	if id, ok := eventDataString(event, "previous_assignee_id"); ok {
+		return id, true
+	}
+	if event.ActorID == nil || event.OldStatus == nil || event.NewStatus == nil {
+		return "", false
+	}
+
+	switch event.Type {
+	case EventTypeAwaitingExternal:
+		return *event.ActorID, true
+	case EventTypeStatusChanged:
+		if isAssigneeOnlyTransition(*event.OldStatus, *event.NewStatus) {
+			return *event.ActorID, true
+		}
+	}
+	return "", false
+}
+
+// isAssigneeOnlyTransition reports whether only the task's assignee may change
+// its status from one to the other through PATCH /status.
+func isAssigneeOnlyTransition(from, to TaskStatus) bool {
+	switch from {
+	case TaskStatusInProgress:
+		return to == TaskStatusNew || to == TaskStatusNeedsReview || to == TaskStatusBlocked || to == TaskStatusDone ||
+			to == TaskStatusWaitingApproval
+	case TaskStatusBlocked, TaskStatusAwaitingExternal, TaskStatusStuck:
+		return to == TaskStatusInProgress
+	}
+	return false
+}
+
+// eventDataString returns a non-empty string field of the event's data.
+func eventDataString(event *TaskEvent, key string) (string, bool) {
+	value, ok := event.Data[key].(string)
+	return value, ok && value != ""
+}
+
+// sameAgent reports whether two optional agent IDs are equal.
+func sameAgent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeTaskTimings(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	status := func(s TaskStatus) *TaskStatus { return &s }
+	agent := func(id string) *string { return &id }
+
+	t.Run("claim, block, takeover and finish", func(t *testing.T) {
+		task := &Task{ID: "task", Status: TaskStatusDone, AssigneeID: agent("b"), CreatedAt: start}
+		events := []*TaskEvent{
+			{Type: EventTypeCreated, ActorID: agent("creator"), NewStatus: status(TaskStatusNew), CreatedAt: at(0)},
+			{Type: EventTypeClaimed, ActorID: agent("a"), OldStatus: status(TaskStatusNew), NewStatus: status(TaskStatusInProgress), CreatedAt: at(10)},
+			{Type: EventTypeCommented, ActorID: agent("a"), CreatedAt: at(15)},
+			{Type: EventTypeStatusChanged, ActorID: agent("a"), OldStatus: status(TaskStatusInProgress), NewStatus: status(TaskStatusBlocked), CreatedAt: at(40)},
+			{Type: EventTypeDeadlineExpired, OldStatus: status(TaskStatusBlocked), NewStatus: status(TaskStatusStuck), CreatedAt: at(100)},
+			{Type: EventTypeTakenOver, ActorID: agent("b"), OldStatus: status(TaskStatusStuck), NewStatus: status(TaskStatusInProgress), CreatedAt: at(130)},
+			{Type: EventTypeStatusChanged, ActorID: agent("b"), OldStatus: status(TaskStatusInProgress), NewStatus: status(TaskStatusDone), CreatedAt: at(150)},
+		}
+
+		timings := ComputeTaskTimings(task, events, at(1000))
+
+		assert.Equal(t, at(0), timings.Since)
+		assert.Equal(t, at(150), timings.Until, "a finished task stops at completion")
+		assert.Equal(t, 150*time.Minute, timings.Total)
+		require.Len(t, timings.Periods, 6)
+		assert.Nil(t, timings.Periods[5].EndedAt)
+
+		byStatus := make(map[TaskStatus]StatusTiming)
+		for _, s := range timings.Statuses {
+			byStatus[s.Status] = s
+		}
+		assert.Equal(t, 10*time.Minute, byStatus[TaskStatusNew].Duration)
+		assert.Equal(t, 50*time.Minute, byStatus[TaskStatusInProgress].Duration)
+		assert.Equal(t, 2, byStatus[TaskStatusInProgress].Entries)
+		assert.Equal(t, 60*time.Minute, byStatus[TaskStatusBlocked].Duration)
+		assert.Equal(t, 30*time.Minute, byStatus[TaskStatusStuck].Duration)
+		assert.Zero(t, byStatus[TaskStatusDone].Duration)
+
+		require.Len(t, timings.Assignees, 2)
+		assert.Equal(t, "a", timings.Assignees[0].AgentID)
+		assert.Equal(t, 120*time.Minute, timings.Assignees[0].Duration)
+		assert.Equal(t, 30*time.Minute, timings.Assignees[0].ByStatus[TaskStatusInProgress])
+		assert.Equal(t, 1, timings.Assignees[0].Periods)
+		assert.Equal(t, "b", timings.Assignees[1].AgentID)
+		assert.Equal(t, 20*time.Minute, timings.Assignees[1].Duration)
+	})
+
+	t.Run("open task counts until now", func(t *testing.T) {
+		task := &Task{ID: "task", Status: TaskStatusInProgress, AssigneeID: agent("a"), CreatedAt: start}
+		events := []*TaskEvent{
+			{Type: EventTypeCreated, ActorID: agent("creator"), NewStatus: status(TaskStatusInProgress), Data: map[string]any{"assignee_id": "a"}, CreatedAt: at(0)},
+		}
+
+		timings := ComputeTaskTimings(task, events, at(45))
+
+		assert.Equal(t, at(45), timings.Until)
+		assert.Equal(t, 45*time.Minute, timings.Total)
+		require.Len(t, timings.Assignees, 1)
+		assert.Equal(t, 45*time.Minute, timings.Assignees[0].Duration)
+	})
+
+	t.Run("assignee of older created events is inferred", func(t *testing.T) {
+		task := &Task{ID: "task", Status: TaskStatusNew, CreatedAt: start}
+		events := []*TaskEvent{
+			{Type: EventTypeCreated, ActorID: agent("creator"), NewStatus: status(TaskStatusInProgress), CreatedAt: at(0)},
+			{Type: EventTypeStatusChanged, ActorID: agent("a"), OldStatus: status(TaskStatusInProgress), NewStatus: status(TaskStatusNew), CreatedAt: at(20)},
+		}
+
+		timings := ComputeTaskTimings(task, events, at(30))
+
+		require.Len(t, timings.Assignees, 1)
+		assert.Equal(t, "a", timings.Assignees[0].AgentID)
+		assert.Equal(t, 20*time.Minute, timings.Assignees[0].Duration)
+		assert.Nil(t, timings.Periods[1].AssigneeID)
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		task := &Task{ID: "task", Status: TaskStatusNew, CreatedAt: start}
+
+		timings := ComputeTaskTimings(task, nil, at(5))
+
+		require.Len(t, timings.Periods, 1)
+		assert.Equal(t, 5*time.Minute, timings.Total)
+		assert.Empty(t, timings.Assignees)
+	})
+}
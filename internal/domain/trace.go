@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// MaxTraceStateLength limits the tracestate header kept with a trace context.
+const MaxTraceStateLength = 512
+
+// TraceContext is the W3C Trace Context of the request that caused an event.
+// It is stored with the event and handed on to webhook and broker consumers,
+// so their spans join the trace of the agent that triggered the change.
+type TraceContext struct {
+	TraceParent string
+	TraceState  string // optional vendor data, passed on unchanged
+}
+
+type traceContextKey struct{}
+
+// ParseTraceContext validates a traceparent header and pairs it with tracestate.
+// Returns nil if traceparent is missing or malformed; an oversized tracestate is dropped.
+// For future traceparent versions only the fields known to version 00 are kept.
+func ParseTraceContext(traceParent, traceState string) *TraceContext {
+	traceParent = strings.TrimSpace(traceParent)
+	if len(traceParent) < 55 || (len(traceParent) > 55 && traceParent[55] != '-') {
+		return nil
+	}
+	version := traceParent[0:2]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(traceParent) != 55) {
+		return nil
+	}
+	if traceParent[2] != '-' || traceParent[35] != '-' || traceParent[52] != '-' {
+		return nil
+	}
+	traceID, parentID, flags := traceParent[3:35], traceParent[36:52], traceParent[53:55]
+	if !isLowerHex(traceID) || !isLowerHex(parentID) || !isLowerHex(flags) {
+		return nil
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return nil
+	}
+
+	traceState = strings.TrimSpace(traceState)
+	if len(traceState) > MaxTraceStateLength {
+		traceState = ""
+	}
+
+	return &TraceContext{
+		TraceParent: "00" + traceParent[2:55],
+		TraceState:  traceState,
+	}
+}
+
+// ContextWithTrace returns a copy of ctx carrying tc.
+func ContextWithTrace(ctx context.Context, tc *TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceFromContext returns the trace context carried by ctx, or nil.
+func TraceFromContext(ctx context.Context) *TraceContext {
+	tc, _ := ctx.Value(traceContextKey{}).(*TraceContext)
+	return tc
+}
+
+// isLowerHex reports whether s consists of lowercase hex digits only.
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceContext(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tc := ParseTraceContext(valid, " vendor=abc ")
+	require.NotNil(t, tc)
+	assert.Equal(t, valid, tc.TraceParent)
+	assert.Equal(t, "vendor=abc", tc.TraceState)
+
+	// Future versions keep the version 00 fields
+	tc = ParseTraceContext("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "")
+	require.NotNil(t, tc)
+	assert.Equal(t, valid, tc.TraceParent)
+
+	// Oversized tracestate is dropped, the traceparent kept
+	tc = ParseTraceContext(valid, strings.Repeat("a", MaxTraceStateLength+1))
+	require.NotNil(t, tc)
+	assert.Empty(t, tc.TraceState)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		assert.Nil(t, ParseTraceContext(invalid, "vendor=abc"), invalid)
+	}
+}
+
+func TestTraceFromContext(t *testing.T) {
+	assert.Nil(t, TraceFromContext(context.Background()))
+
+	tc := &TraceContext{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	assert.Same(t, tc, TraceFromContext(ContextWithTrace(context.Background(), tc)))
+}
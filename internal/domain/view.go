@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxViewNameLength limits saved view names.
+const MaxViewNameLength = 50
+
+var viewNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// viewParams are the GET /tasks query parameters a saved view can hold: the
+// filters, sort and shape of the list, but not the page.
+var viewParams = map[string]bool{
+	"status": true, "assignee": true, "unassigned": true, "creator": true,
+	"blocked_by": true, "blocks": true,
+	"created_after": true, "created_before": true, "updated_after": true,
+	"visibility": true, "priority": true, "queue": true, "label": true,
+	"archived": true, "overdue": true, "human_review": true, "has_unresolved_blockers": true,
+	"sort": true, "fields": true, "limit": true, "time_format": true,
+}
+
+// SavedView is a named GET /tasks query an agent saved, so that it lists the
+// same tasks on every poll with ?view=<name>.
+type SavedView struct {
+	ID        string
+	AgentID   string
+	Name      string
+	Params    map[string]string // GET /tasks query parameters
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeViewName lowercases and trims a view name and checks its format:
+// letters, digits, '-' and '_', starting with a letter or digit.
+func NormalizeViewName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > MaxViewNameLength || !viewNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: view name must be 1-%d characters of a-z, 0-9, '-' or '_'", ErrValidation, MaxViewNameLength)
+	}
+	return name, nil
+}
+
+// ValidateViewParams checks that params only holds GET /tasks parameters a
+// view can save. Their values are checked when the view is used.
+func ValidateViewParams(params map[string]string) error {
+	for name := range params {
+		if !viewParams[name] {
+			valid := make([]string, 0, len(viewParams))
+			for param := range viewParams {
+				valid = append(valid, param)
+			}
+			sort.Strings(valid)
+			return fmt.Errorf("%w: views can't save %q, only %s", ErrValidation, name, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
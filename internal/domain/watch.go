@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// TaskWatch subscribes an agent to the events of a task it can see. The events
+// show up in the agent's notifications, from the time the watch started.
+type TaskWatch struct {
+	TaskID    string
+	AgentID   string
+	CreatedAt time.Time
+}
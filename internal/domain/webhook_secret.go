@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// WebhookSecretPrefix marks webhook signing secrets.
+const WebhookSecretPrefix = "whsec_"
+
+// Overlap of the old and new secret when rotating a webhook secret.
+const (
+	DefaultWebhookRotationOverlap = 24 * time.Hour
+	MaxWebhookRotationOverlap     = 30 * 24 * time.Hour
+)
+
+// WebhookSecret is the secret signing a workspace's webhook deliveries. While a
+// rotation is in progress, deliveries are signed with both Secret and
+// PreviousSecret so receivers can switch secrets without rejecting deliveries.
+type WebhookSecret struct {
+	WorkspaceID       string
+	Secret            string
+	PreviousSecret    *string
+	PreviousExpiresAt *time.Time
+	RotatedAt         time.Time // when Secret was issued
+	CreatedAt         time.Time
+}
+
+// IsRotating returns true if the previous secret still signs deliveries at the given time.
+func (s *WebhookSecret) IsRotating(now time.Time) bool {
+	return s.PreviousSecret != nil && s.PreviousExpiresAt != nil && now.Before(*s.PreviousExpiresAt)
+}
+
+// SigningSecrets returns the secrets deliveries are signed with at the given
+// time: the current one, then the previous one during a rotation.
+func (s *WebhookSecret) SigningSecrets(now time.Time) []string {
+	if s.IsRotating(now) {
+		return []string{s.Secret, *s.PreviousSecret}
+	}
+	return []string{s.Secret}
+}
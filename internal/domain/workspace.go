@@ -2,13 +2,175 @@ package domain
 
 import "time"
 
+const (
+	// DefaultSandboxTTL is how long a sandbox lives unless a TTL is given.
+	DefaultSandboxTTL = 72 * time.Hour
+	// MaxSandboxTTL limits how long a sandbox lives.
+	MaxSandboxTTL = 30 * 24 * time.Hour
+	// DefaultDoneValidationTimeout bounds a DONE validation call unless a timeout is given.
+	DefaultDoneValidationTimeout = 5 * time.Second
+	// MaxDoneValidationTimeout limits how long a move to DONE may wait for validation.
+	MaxDoneValidationTimeout = 30 * time.Second
+)
+
+// Bounds and default of a workspace's agent staleness window, in seconds.
+const (
+	DefaultAgentStaleAfterSeconds = 300
+	MinAgentStaleAfterSeconds     = 30
+	MaxAgentStaleAfterSeconds     = 24 * 60 * 60
+)
+
+// Bounds of a workspace's priority aging threshold, in seconds.
+const (
+	MinPriorityAgingAfterSeconds = 60
+	MaxPriorityAgingAfterSeconds = 30 * 24 * 60 * 60
+)
+
+// Bounds of a workspace's deadline warning threshold, in percent of a status deadline.
+const (
+	MinDeadlineWarningPercent = 1
+	MaxDeadlineWarningPercent = 99
+)
+
+// MaxAttemptsLimit bounds a workspace's attempt limit.
+const MaxAttemptsLimit = 100
+
+// Bounds of a workspace's claim quota. The window defaults to an hour.
+const (
+	MaxClaimQuota                  = 10000
+	DefaultClaimQuotaWindowSeconds = 60 * 60
+	MinClaimQuotaWindowSeconds     = 60
+	MaxClaimQuotaWindowSeconds     = 7 * 24 * 60 * 60
+)
+
+// AutoAssignStrategy selects how NEW tasks are assigned to idle agents automatically.
+type AutoAssignStrategy string
+
+const (
+	AutoAssignNone            AutoAssignStrategy = "none"
+	AutoAssignRoundRobin      AutoAssignStrategy = "round_robin"
+	AutoAssignLeastLoaded     AutoAssignStrategy = "least_loaded"
+	AutoAssignCapabilityMatch AutoAssignStrategy = "capability_match"
+)
+
+// IsValid checks if the strategy is one of the known values.
+func (s AutoAssignStrategy) IsValid() bool {
+	switch s {
+	case AutoAssignNone, AutoAssignRoundRobin, AutoAssignLeastLoaded, AutoAssignCapabilityMatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// PriorityAgingAction is what happens to a NEW task left unclaimed too long.
+type PriorityAgingAction string
+
+const (
+	// PriorityAgingBump raises the task's priority one step
+	PriorityAgingBump PriorityAgingAction = "bump"
+	// PriorityAgingWarn only records a priority_aged event
+	PriorityAgingWarn PriorityAgingAction = "warn"
+)
+
+// IsValid checks if the action is one of the known values.
+func (a PriorityAgingAction) IsValid() bool {
+	return a == PriorityAgingBump || a == PriorityAgingWarn
+}
+
+// PriorityAging is a workspace's policy for NEW tasks nobody claims. A task ages
+// each time it has waited After since it became NEW or last aged.
+type PriorityAging struct {
+	After  time.Duration
+	Action PriorityAgingAction
+}
+
+// ClaimFairness keeps the fastest polling agent from claiming all of a
+// workspace's work. The zero value imposes no limits.
+type ClaimFairness struct {
+	// Quota is how many tasks an agent may claim within Window; 0 means no quota
+	Quota  int
+	Window time.Duration
+	// TakeTurns refuses a claim by the agent that made the workspace's last claim
+	// while another live agent able to take the task is idle
+	TakeTurns bool
+}
+
+// IsEnabled reports whether any limit applies.
+func (f ClaimFairness) IsEnabled() bool {
+	return f.Quota > 0 || f.TakeTurns
+}
+
+// DoneValidationHook is a workspace's webhook asked to approve every move to DONE.
+type DoneValidationHook struct {
+	URL     string
+	Timeout time.Duration
+	// FailOpen lets moves to DONE through when the webhook cannot be reached
+	FailOpen bool
+}
+
+// EventWebhook is a workspace's webhook receiving its task events.
+type EventWebhook struct {
+	URL        string
+	EventTypes []EventType // types published; empty for all
+}
+
+// EventBrokerTopic is the NATS subject or Kafka topic receiving a workspace's
+// task events, on the broker the scheduler is connected to.
+type EventBrokerTopic struct {
+	Topic      string
+	EventTypes []EventType // types published; empty for all
+}
+
 // Workspace represents an isolated environment for a group of agents.
 type Workspace struct {
-	ID              string
-	Name            string
-	Slug            string
-	StatusDeadlines map[string]int // status -> minutes
-	CreatedAt       time.Time
+	ID                 string
+	Name               string
+	Slug               string
+	StatusDeadlines    map[string]int // status -> minutes
+	AutoAssignStrategy AutoAssignStrategy
+	// PriorityInheritance lets blockers of open high and critical tasks inherit their priority
+	PriorityInheritance bool
+	// AutoUnblock returns BLOCKED tasks to their previous status once all their blockers are done
+	AutoUnblock bool
+	// AgentStaleAfterSeconds is how long an agent may go without a heartbeat before it is stale
+	AgentStaleAfterSeconds int
+	DoneValidation         *DoneValidationHook // nil when completions are not validated
+	PriorityAging          *PriorityAging      // nil when unclaimed tasks don't age
+	ClaimFairness          ClaimFairness       // zero when claims are not limited
+	DeadlineWarningPercent int                 // share of a status deadline left when tasks are warned; 0 for no warnings
+	DeadlineExpiry         map[string]string   // status -> status entered when its deadline expires; STUCK if unset
+	MaxAttempts            int                 // attempts after which tasks are held for human review; 0 for no limit
+	EventWebhook           *EventWebhook       // nil when task events are not published
+	EventBroker            *EventBrokerTopic   // nil when task events are not published to the broker
+	ArchivedAt             *time.Time          // set once archived; archived workspaces are frozen
+	// Sandboxes are clones of another workspace's open work, deleted by the purge job once expired
+	SandboxOf *string    // the source workspace; nil once it is deleted
+	ExpiresAt *time.Time // set for sandboxes only
+	CreatedAt time.Time
+}
+
+// IsArchived reports whether the workspace has been archived.
+func (w *Workspace) IsArchived() bool {
+	return w.ArchivedAt != nil
+}
+
+// IsSandbox reports whether the workspace is a sandbox.
+func (w *Workspace) IsSandbox() bool {
+	return w.ExpiresAt != nil
+}
+
+// AgentStaleAfter returns the workspace's agent staleness window.
+func (w *Workspace) AgentStaleAfter() time.Duration {
+	return time.Duration(w.AgentStaleAfterSeconds) * time.Second
+}
+
+// ExpiryStatus returns the status a task enters when its deadline in status expires.
+func (w *Workspace) ExpiryStatus(status TaskStatus) TaskStatus {
+	if target, ok := w.DeadlineExpiry[string(status)]; ok {
+		return TaskStatus(target)
+	}
+	return TaskStatusStuck
 }
 
 // GetDeadlineMinutes returns the deadline in minutes for a given status.
@@ -0,0 +1,55 @@
+package domain
+
+// WorkspaceConfigVersion is the version of the workspace configuration document.
+const WorkspaceConfigVersion = 1
+
+// WorkspaceSettings are the per-workspace policies stored on the workspace itself.
+type WorkspaceSettings struct {
+	StatusDeadlines        map[string]int // status -> minutes
+	AutoAssignStrategy     AutoAssignStrategy
+	PriorityInheritance    bool
+	AutoUnblock            bool
+	AgentStaleAfterSeconds int
+	DoneValidation         *DoneValidationHook
+	PriorityAging          *PriorityAging
+	ClaimFairness          ClaimFairness
+	DeadlineWarningPercent int
+	DeadlineExpiry         map[string]string // status -> status entered when its deadline expires
+	MaxAttempts            int
+	EventWebhook           *EventWebhook
+	EventBroker            *EventBrokerTopic
+}
+
+// WorkspaceConfig is the configuration of a workspace without its work: its
+// settings, labels, queues, escalation routes, schedules and reports. It can be
+// exported from one deployment and imported into another, so agents are referred
+// to by name rather than ID: CreatorID of routes, schedules and reports, Target of
+// agent routes and AssigneeID of schedule templates hold agent names. IDs and
+// timestamps are left empty. A nil section is not part of the configuration.
+type WorkspaceConfig struct {
+	Settings         *WorkspaceSettings
+	Labels           []*Label
+	Queues           []*Queue
+	EscalationRoutes []*EscalationRoute
+	Schedules        []*Schedule
+	Reports          []*Report
+}
+
+// ConfigImportCounts tells how many items of one section an import created,
+// changed, left as they were and removed. Only escalation routes are removed.
+type ConfigImportCounts struct {
+	Created   int
+	Updated   int
+	Unchanged int
+	Deleted   int
+}
+
+// ConfigImportResult summarizes an import of a workspace configuration.
+type ConfigImportResult struct {
+	SettingsChanged  bool
+	Labels           ConfigImportCounts
+	Queues           ConfigImportCounts
+	EscalationRoutes ConfigImportCounts
+	Schedules        ConfigImportCounts
+	Reports          ConfigImportCounts
+}
@@ -0,0 +1,212 @@
+// Package graphapi serves a read-only GraphQL view of tasks, so planners can
+// walk the dependency graph in one request instead of one REST call per task.
+package graphapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// MaxDepth bounds how deeply a query may nest, e.g. blockedBy within
+// blockedBy, so one request can't walk an unbounded graph.
+const MaxDepth = 10
+
+// maxTasks is the page size limit of the tasks query, as in GET /api/v1/tasks.
+const maxTasks = 200
+
+// errInternal hides database errors from clients; they are logged instead.
+var errInternal = errors.New("internal error")
+
+// NewSchema parses the schema with resolvers reading tasks and events from
+// the repositories. Queries see what the caller of GET /api/v1/tasks/{id}
+// would: the authenticated agent, read token or coordinator in the context.
+func NewSchema(tasks repository.TaskRepository, events repository.TaskEventRepository) *graphql.Schema {
+	return graphql.MustParseSchema(schemaSource, &resolver{tasks: tasks, events: events},
+		graphql.MaxDepth(MaxDepth),
+	)
+}
+
+// resolver answers the Query type.
+type resolver struct {
+	tasks  repository.TaskRepository
+	events repository.TaskEventRepository
+}
+
+// Task resolves task(id), null if it doesn't exist or the caller can't see it.
+// id may also be a task key such as TST-142.
+func (r *resolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	taskID := string(args.ID)
+	if slug, number, ok := domain.ParseTaskKey(taskID); ok {
+		id, err := r.tasks.GetIDByKey(ctx, slug, number)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, r.internal("get task by key", err)
+		}
+		taskID = id
+	}
+
+	task, err := r.tasks.GetByID(ctx, taskID)
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.internal("get task", err)
+	}
+	if !visible(ctx, task) {
+		return nil, nil
+	}
+	return &taskResolver{root: r, task: task}, nil
+}
+
+// tasksArgs holds the arguments of the tasks query.
+type tasksArgs struct {
+	Status   *[]string
+	Assignee *graphql.ID
+	Queue    *string
+	Labels   *[]string
+	Limit    int32
+	Offset   int32
+}
+
+// Tasks resolves tasks(...) within the caller's workspace.
+func (r *resolver) Tasks(ctx context.Context, args tasksArgs) (*taskPageResolver, error) {
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if args.Limit < 1 || args.Limit > maxTasks {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxTasks)
+	}
+	if args.Offset < 0 {
+		return nil, errors.New("offset must not be negative")
+	}
+
+	filters := repository.TaskListFilters{
+		WorkspaceID: workspaceID,
+		Queue:       args.Queue,
+		Limit:       int(args.Limit),
+		Offset:      int(args.Offset),
+	}
+	// Read tokens and coordinators have no agent: they see public tasks only
+	if agent, err := middleware.GetAgentFromContext(ctx); err == nil {
+		filters.AgentID = agent.ID
+	}
+	if args.Status != nil {
+		filters.Statuses = *args.Status
+	}
+	if args.Assignee != nil {
+		assigneeID := string(*args.Assignee)
+		filters.AssigneeID = &assigneeID
+	}
+	if args.Labels != nil {
+		filters.Labels = *args.Labels
+	}
+
+	results, total, err := r.tasks.List(ctx, filters)
+	if err != nil {
+		return nil, r.internal("list tasks", err)
+	}
+	page := &taskPageResolver{total: int32(total)}
+	for _, result := range results {
+		page.tasks = append(page.tasks, &taskResolver{root: r, task: result.Task})
+	}
+	return page, nil
+}
+
+// internal logs err and returns the error clients see instead.
+func (r *resolver) internal(action string, err error) error {
+	slog.Error("graphql: "+action, "error", err)
+	return errInternal
+}
+
+// visible reports whether the caller may see task, as GET /api/v1/tasks/{id}
+// decides: agents by IsVisibleTo, everyone else public tasks of their workspace.
+func visible(ctx context.Context, task *domain.Task) bool {
+	if agent, err := middleware.GetAgentFromContext(ctx); err == nil {
+		return task.IsVisibleTo(agent)
+	}
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	return err == nil && task.WorkspaceID == workspaceID && task.Visibility == domain.TaskVisibilityPublic
+}
+
+// visibleTasks wraps the tasks the caller may see.
+func (r *resolver) visibleTasks(ctx context.Context, tasks []*domain.Task) []*taskResolver {
+	resolvers := make([]*taskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		if visible(ctx, task) {
+			resolvers = append(resolvers, &taskResolver{root: r, task: task})
+		}
+	}
+	return resolvers
+}
+
+// taskPageResolver answers the TaskPage type.
+type taskPageResolver struct {
+	tasks []*taskResolver
+	total int32
+}
+
+func (p *taskPageResolver) Tasks() []*taskResolver { return p.tasks }
+func (p *taskPageResolver) Total() int32           { return p.total }
+
+// JSON is the JSON scalar: task results and event data.
+type JSON map[string]any
+
+// ImplementsGraphQLType maps JSON to the scalar of the same name.
+func (JSON) ImplementsGraphQLType(name string) bool { return name == "JSON" }
+
+// UnmarshalGraphQL accepts objects; the schema takes no JSON input yet.
+func (j *JSON) UnmarshalGraphQL(input any) error {
+	object, ok := input.(map[string]any)
+	if !ok {
+		return fmt.Errorf("JSON must be an object, got %T", input)
+	}
+	*j = object
+	return nil
+}
+
+// MarshalJSON encodes the object as is.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(j))
+}
+
+// optionalJSON returns nil for a missing object, which the schema shows as null.
+func optionalJSON(object map[string]any) *JSON {
+	if object == nil {
+		return nil
+	}
+	j := JSON(object)
+	return &j
+}
+
+// optionalTime wraps a nullable timestamp.
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+// optionalID wraps a nullable ID.
+func optionalID(id *string) *graphql.ID {
+	if id == nil {
+		return nil
+	}
+	gid := graphql.ID(*id)
+	return &gid
+}
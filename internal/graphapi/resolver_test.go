@@ -0,0 +1,97 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/graphapi"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/testutil/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEventRepo serves the events of tasks from memory.
+type fakeEventRepo struct {
+	repository.TaskEventRepository
+	events map[string][]repository.TaskEventWithActor
+}
+
+func (r *fakeEventRepo) GetByTaskIDWithActors(_ context.Context, taskID string) ([]repository.TaskEventWithActor, error) {
+	return r.events[taskID], nil
+}
+
+func TestSchema_TaskWithBlockersAndEvents(t *testing.T) {
+	task := func(id, creatorID string, status domain.TaskStatus, visibility domain.TaskVisibility, blockedBy ...string) *domain.Task {
+		return &domain.Task{
+			ID: id, WorkspaceID: "ws", CreatorID: creatorID, Status: status, Visibility: visibility,
+			BlockedBy: blockedBy, Version: 1, CreatedAt: time.Now(),
+		}
+	}
+	tasks := &fake.TaskRepository{Tasks: map[string]*domain.Task{
+		"design": task("design", "agent", domain.TaskStatusDone, domain.TaskVisibilityPublic),
+		"secret": task("secret", "other", domain.TaskStatusInProgress, domain.TaskVisibilityPrivate),
+		"build":  task("build", "agent", domain.TaskStatusNew, domain.TaskVisibilityPublic, "design", "secret"),
+		"ship":   task("ship", "agent", domain.TaskStatusNew, domain.TaskVisibilityPublic, "build"),
+	}}
+	planner := "planner"
+	events := &fakeEventRepo{events: map[string][]repository.TaskEventWithActor{
+		"build": {{ID: "e1", TaskID: "build", Type: domain.EventTypeCreated, ActorName: &planner, Comment: "Created"}},
+	}}
+	schema := graphapi.NewSchema(tasks, events)
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyAgent, &domain.Agent{ID: "agent", WorkspaceID: "ws"})
+
+	response := schema.Exec(ctx, `query($id: ID!) {
+		task(id: $id) {
+			id status version hasUnresolvedBlockers
+			blockedBy { id status blockedBy { id } }
+			blocks { id }
+			events { type comment }
+		}
+		hidden: task(id: "secret") { id }
+	}`, "", map[string]any{"id": "build"})
+	require.Empty(t, response.Errors)
+
+	var data struct {
+		Task struct {
+			ID                    string
+			Status                string
+			Version               int
+			HasUnresolvedBlockers bool
+			BlockedBy             []struct {
+				ID, Status string
+				BlockedBy  []struct{ ID string }
+			}
+			Blocks []struct{ ID string }
+			Events []struct{ Type, Comment string }
+		}
+		Hidden *struct{ ID string }
+	}
+	require.NoError(t, json.Unmarshal(response.Data, &data))
+	assert.Equal(t, "build", data.Task.ID)
+	assert.Equal(t, 1, data.Task.Version)
+	assert.True(t, data.Task.HasUnresolvedBlockers, "the hidden blocker still counts")
+	require.Len(t, data.Task.BlockedBy, 1, "other agents' private tasks are left out")
+	assert.Equal(t, "design", data.Task.BlockedBy[0].ID)
+	assert.Equal(t, "DONE", data.Task.BlockedBy[0].Status)
+	require.Len(t, data.Task.Blocks, 1)
+	assert.Equal(t, "ship", data.Task.Blocks[0].ID)
+	require.Len(t, data.Task.Events, 1)
+	assert.Equal(t, "created", data.Task.Events[0].Type)
+	assert.Nil(t, data.Hidden)
+}
+
+func TestSchema_MaxDepth(t *testing.T) {
+	schema := graphapi.NewSchema(&fake.TaskRepository{}, &fakeEventRepo{})
+	selection := "id"
+	for range graphapi.MaxDepth {
+		selection = "blockedBy { " + selection + " }"
+	}
+	response := schema.Exec(context.Background(), `{ task(id: "a") { `+selection+` } }`, "", nil)
+	require.NotEmpty(t, response.Errors)
+	assert.Contains(t, response.Errors[0].Message, "depth")
+}
@@ -0,0 +1,131 @@
+package graphapi
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// taskResolver answers the Task type.
+type taskResolver struct {
+	root *resolver
+	task *domain.Task
+}
+
+func (t *taskResolver) ID() graphql.ID          { return graphql.ID(t.task.ID) }
+func (t *taskResolver) Number() int32           { return int32(t.task.Number) }
+func (t *taskResolver) Key() string             { return t.task.Key }
+func (t *taskResolver) Title() string           { return t.task.Title }
+func (t *taskResolver) Description() string     { return t.task.Description }
+func (t *taskResolver) Status() string          { return string(t.task.Status) }
+func (t *taskResolver) Priority() string        { return string(t.task.Priority) }
+func (t *taskResolver) Visibility() string      { return string(t.task.Visibility) }
+func (t *taskResolver) Queue() *string          { return t.task.Queue }
+func (t *taskResolver) Labels() []string        { return t.task.Labels }
+func (t *taskResolver) CreatorID() graphql.ID   { return graphql.ID(t.task.CreatorID) }
+func (t *taskResolver) Artefact() *string       { return t.task.Artefact }
+func (t *taskResolver) Result() *JSON           { return optionalJSON(t.task.Result) }
+func (t *taskResolver) Version() int32          { return int32(t.task.Version) }
+func (t *taskResolver) Attempts() int32         { return int32(t.task.Attempts) }
+func (t *taskResolver) RequiresApproval() bool  { return t.task.RequiresApproval }
+func (t *taskResolver) CreatedAt() graphql.Time { return graphql.Time{Time: t.task.CreatedAt} }
+func (t *taskResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: t.task.UpdatedAt} }
+
+func (t *taskResolver) AssigneeID() *graphql.ID {
+	if t.task.AssigneeID == nil {
+		return nil
+	}
+	id := graphql.ID(*t.task.AssigneeID)
+	return &id
+}
+
+func (t *taskResolver) StatusDeadlineAt() *graphql.Time { return optionalTime(t.task.StatusDeadlineAt) }
+func (t *taskResolver) ArchivedAt() *graphql.Time       { return optionalTime(t.task.ArchivedAt) }
+
+// HasUnresolvedBlockers counts every blocker, including those the caller
+// can't see, like the REST responses do.
+func (t *taskResolver) HasUnresolvedBlockers(ctx context.Context) (bool, error) {
+	if len(t.task.BlockedBy) == 0 {
+		return false, nil
+	}
+	blockers, err := t.root.tasks.GetBlockedByTasks(ctx, t.task.BlockedBy)
+	if err != nil {
+		return false, t.root.internal("get blockers", err)
+	}
+	for _, blocker := range blockers {
+		if blocker.Status != domain.TaskStatusDone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (t *taskResolver) BlockedBy(ctx context.Context) ([]*taskResolver, error) {
+	if len(t.task.BlockedBy) == 0 {
+		return []*taskResolver{}, nil
+	}
+	blockers, err := t.root.tasks.GetBlockedByTasks(ctx, t.task.BlockedBy)
+	if err != nil {
+		return nil, t.root.internal("get blockers", err)
+	}
+	return t.root.visibleTasks(ctx, blockers), nil
+}
+
+func (t *taskResolver) Blocks(ctx context.Context) ([]*taskResolver, error) {
+	dependents, err := t.root.tasks.GetDependentTasks(ctx, []string{t.task.ID})
+	if err != nil {
+		return nil, t.root.internal("get dependents", err)
+	}
+	return t.root.visibleTasks(ctx, dependents), nil
+}
+
+func (t *taskResolver) Events(ctx context.Context) ([]*eventResolver, error) {
+	events, err := t.root.events.GetByTaskIDWithActors(ctx, t.task.ID)
+	if err != nil {
+		return nil, t.root.internal("get events", err)
+	}
+	resolvers := make([]*eventResolver, len(events))
+	for i := range events {
+		resolvers[i] = &eventResolver{event: &events[i]}
+	}
+	return resolvers, nil
+}
+
+// eventResolver answers the TaskEvent type.
+type eventResolver struct {
+	event *repository.TaskEventWithActor
+}
+
+func (e *eventResolver) ID() graphql.ID            { return graphql.ID(e.event.ID) }
+func (e *eventResolver) Type() string              { return string(e.event.Type) }
+func (e *eventResolver) ActorName() *string        { return e.event.ActorName }
+func (e *eventResolver) Comment() string           { return e.event.Comment }
+func (e *eventResolver) Data() *JSON               { return optionalJSON(e.event.Data) }
+func (e *eventResolver) CreatedAt() graphql.Time   { return graphql.Time{Time: e.event.CreatedAt} }
+func (e *eventResolver) EditedAt() *graphql.Time   { return optionalTime(e.event.EditedAt) }
+func (e *eventResolver) RedactedAt() *graphql.Time { return optionalTime(e.event.RedactedAt) }
+
+func (e *eventResolver) ActorID() *graphql.ID {
+	if e.event.ActorID == nil {
+		return nil
+	}
+	id := graphql.ID(*e.event.ActorID)
+	return &id
+}
+
+func (e *eventResolver) ReplyToEventID() *graphql.ID { return optionalID(e.event.ReplyToEventID) }
+func (e *eventResolver) ThreadID() *graphql.ID       { return optionalID(e.event.ThreadID) }
+
+func (e *eventResolver) OldStatus() *string { return optionalStatus(e.event.OldStatus) }
+func (e *eventResolver) NewStatus() *string { return optionalStatus(e.event.NewStatus) }
+
+// optionalStatus converts a nullable status.
+func optionalStatus(status *domain.TaskStatus) *string {
+	if status == nil {
+		return nil
+	}
+	s := string(*status)
+	return &s
+}
@@ -0,0 +1,1339 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleCreateReadToken issues a workspace read token.
+// @Summary Create read token
+// @Description Issue a workspace-scoped read-only token for dashboards and scripts. The token is returned only once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.CreateReadTokenRequest true "Read token request"
+// @Success 201 {object} dto.CreateReadTokenResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/read-tokens [post]
+func (h *Handler) handleCreateReadToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateReadTokenRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	token, readToken, err := h.readTokenService.CreateReadToken(ctx, workspaceID, req.Name, req.ExpiresAt)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditReadTokenCreated, &workspaceID, map[string]any{
+		"read_token_id": readToken.ID,
+		"name":          readToken.Name,
+	})
+
+	respondJSON(w, http.StatusCreated, dto.CreateReadTokenResponse{
+		Token:     token,
+		ReadToken: dto.ToReadTokenInfo(readToken),
+	})
+}
+
+// handleListReadTokens lists the read tokens of a workspace.
+// @Summary List read tokens
+// @Description List workspace read tokens with expiry and last-used information (secrets are never returned)
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.ReadTokensListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/read-tokens [get]
+func (h *Handler) handleListReadTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	tokens, err := h.readTokenService.ListReadTokens(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ReadTokensListResponse{
+		ReadTokens: make([]dto.ReadTokenInfo, len(tokens)),
+	}
+	for i, token := range tokens {
+		response.ReadTokens[i] = dto.ToReadTokenInfo(token)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleRevokeReadToken revokes a read token.
+// @Summary Revoke read token
+// @Description Revoke a workspace read token immediately
+// @Tags admin
+// @Param id path string true "Read token ID"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/read-tokens/{id} [delete]
+func (h *Handler) handleRevokeReadToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tokenID, ok := extractPathUUID(w, r, "id", "read token id")
+	if !ok {
+		return
+	}
+
+	if err := h.readTokenService.RevokeReadToken(ctx, tokenID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditReadTokenRevoked, nil, map[string]any{"read_token_id": tokenID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExportWorkspace exports a consistent snapshot of a workspace.
+// @Summary Export workspace
+// @Description Export workspace settings, agents (without tokens), tasks and events from a single REPEATABLE READ snapshot. The manifest records the snapshot time and WAL position. format=ndjson returns one {"type", "data"} record per line instead (manifest, workspace, agents, queues, labels, tasks, events).
+// @Tags admin
+// @Produce json
+// @Produce application/x-ndjson
+// @Param workspace_id path string true "Workspace ID"
+// @Param format query string false "Output format: json (default), ndjson"
+// @Success 200 {object} dto.WorkspaceExportResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/export [get]
+func (h *Handler) handleExportWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = dto.ExportFormatJSON
+	}
+	if format != dto.ExportFormatJSON && format != dto.ExportFormatNDJSON {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "format must be json or ndjson")
+		return
+	}
+
+	snapshot, err := h.exportRepo.Snapshot(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	slog.Info("workspace exported",
+		"workspace_id", workspaceID,
+		"snapshot_lsn", snapshot.Manifest.SnapshotLSN,
+		"tasks", snapshot.Manifest.TaskCount,
+		"events", snapshot.Manifest.EventCount,
+		"format", format,
+	)
+
+	h.recordAudit(ctx, domain.AuditWorkspaceExported, &workspaceID, map[string]any{
+		"source":       "api",
+		"format":       format,
+		"snapshot_lsn": snapshot.Manifest.SnapshotLSN,
+		"tasks":        snapshot.Manifest.TaskCount,
+		"events":       snapshot.Manifest.EventCount,
+	})
+
+	if format == dto.ExportFormatJSON {
+		respondJSON(w, http.StatusOK, dto.ToWorkspaceExportResponse(snapshot))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if err := dto.WriteExport(w, dto.ToWorkspaceExportResponse(snapshot), format); err != nil {
+		slog.Error("failed to write NDJSON export", "workspace_id", workspaceID, "error", err)
+	}
+}
+
+// handleExportEventLog exports the events of a workspace as a hash-chained log.
+// @Summary Export event log
+// @Description Export every event recorded on the workspace's tasks, deleted tasks included, as NDJSON records in the order they were recorded. Each record has a seq (from 1), prev_hash, hash and the event; hash is the hex SHA-256 of prev_hash followed by the event object exactly as written, and the first prev_hash is 64 zeros. Rewriting, dropping or reordering any event changes every later hash, so an operator who keeps the head hash can prove the history was not altered. Events younger than 15 minutes are left out, so later exports only append records and comments are past the time their authors may edit them. after=N skips records up to seq N while keeping the chain, for extending a stored log. Once prune-events has deleted old events, the log starts after the checkpoint it recorded: the first record has the seq after it and its hash as prev_hash. Purging or transferring tasks removes their events and breaks the chain.
+// @Tags admin
+// @Produce application/x-ndjson
+// @Param workspace_id path string true "Workspace ID"
+// @Param after query int false "Only write records after this seq"
+// @Success 200 {object} dto.EventLogRecord
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-log [get]
+func (h *Handler) handleExportEventLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	after := 0
+	if afterParam := r.URL.Query().Get("after"); afterParam != "" {
+		n, err := strconv.Atoi(afterParam)
+		if err != nil || n < 0 {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "after must be a non-negative integer")
+			return
+		}
+		after = n
+	}
+
+	if _, err := h.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	checkpoint, err := h.exportRepo.EventLogCheckpoint(ctx, workspaceID)
+	if err != nil {
+		slog.Error("failed to read event log checkpoint", "workspace_id", workspaceID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to read event log")
+		return
+	}
+
+	events, err := h.exportRepo.EventLog(ctx, workspaceID, checkpoint, time.Now().Add(-dto.EventLogSettleTime))
+	if err != nil {
+		slog.Error("failed to read event log", "workspace_id", workspaceID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to read event log")
+		return
+	}
+
+	// The whole log is hashed before anything is sent, so the audit entry
+	// records the head the client receives
+	var buf bytes.Buffer
+	written, head, err := dto.WriteEventLog(&buf, events, checkpoint, after)
+	if err != nil {
+		slog.Error("failed to write event log", "workspace_id", workspaceID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to write event log")
+		return
+	}
+
+	slog.Info("event log exported", "workspace_id", workspaceID, "events", len(events), "written", written, "head", head)
+
+	h.recordAudit(ctx, domain.AuditEventLogExported, &workspaceID, map[string]any{
+		"source":  "api",
+		"events":  len(events),
+		"after":   after,
+		"written": written,
+		"head":    head,
+	})
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.Error("failed to send event log", "workspace_id", workspaceID, "error", err)
+	}
+}
+
+// handleSetAgentCapabilities replaces the capabilities of an agent.
+// @Summary Set agent capabilities
+// @Description Replace the capabilities an agent offers. Tasks with required_capabilities can only be claimed by agents having all of them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body dto.SetAgentCapabilitiesRequest true "Capabilities"
+// @Success 200 {object} dto.AgentCapabilitiesResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/agents/{id}/capabilities [put]
+func (h *Handler) handleSetAgentCapabilities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agentID, ok := extractPathUUID(w, r, "id", "agent id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetAgentCapabilitiesRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	capabilities, err := domain.NormalizeCapabilities(req.Capabilities)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if err := h.agentRepo.SetCapabilities(ctx, agentID, capabilities); err != nil {
+		if errors.Is(err, domain.ErrAgentNotFound) {
+			respondError(w, http.StatusNotFound, "AGENT_NOT_FOUND", "agent not found")
+			return
+		}
+		respondDomainError(w, err)
+		return
+	}
+
+	h.authMiddleware.InvalidateAgent(ctx, agentID)
+
+	slog.Info("agent capabilities updated",
+		"agent_id", agentID,
+		"capabilities", capabilities,
+	)
+
+	h.recordAudit(ctx, domain.AuditAgentCapabilities, nil, map[string]any{
+		"agent_id":     agentID,
+		"capabilities": capabilities,
+	})
+
+	respondJSON(w, http.StatusOK, dto.AgentCapabilitiesResponse{
+		AgentID:      agentID,
+		Capabilities: capabilities,
+	})
+}
+
+// handleAdminDeleteTask soft-deletes a task on behalf of an operator.
+// @Summary Delete a task (operator)
+// @Description Soft-delete any task of the workspace, e.g. one containing leaked secrets. The task disappears from every API and is removed for good by `sloptask purge`. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.DeleteTaskRequest false "Delete request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/tasks/{id} [delete]
+func (h *Handler) handleAdminDeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.DeleteTaskRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.DeleteTask(ctx, service.DeleteTaskParams{
+		TaskID:      taskID,
+		WorkspaceID: workspaceID,
+		Comment:     req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditTaskDeleted, &workspaceID, map[string]any{
+		"task_id": taskID,
+		"comment": event.Comment,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleTransferTask moves a task to another workspace on behalf of an operator.
+// @Summary Transfer a task (operator)
+// @Description Move an open task with its events, checklist and revisions to another workspace, e.g. one triaged into the wrong team. It arrives NEW and unassigned with the target's deadline, created by creator_id, an active agent of the target workspace. Its blockers, queue, external wait and checklist claims are dropped, as are labels the target has not registered; tasks of the old workspace stop waiting on it. The transferred event records what was dropped.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.TransferTaskRequest true "Transfer request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Task closed or workspace archived"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/tasks/{id}/transfer [post]
+func (h *Handler) handleTransferTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.TransferTaskRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if _, err := uuid.Parse(req.TargetWorkspaceID); err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "target_workspace_id must be a valid UUID")
+		return
+	}
+	if _, err := uuid.Parse(req.CreatorID); err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "creator_id must be a valid UUID")
+		return
+	}
+
+	var visibility *domain.TaskVisibility
+	if req.Visibility != nil {
+		v := domain.TaskVisibility(*req.Visibility)
+		visibility = &v
+	}
+
+	event, err := h.taskService.TransferTask(ctx, service.TransferTaskParams{
+		TaskID:            taskID,
+		WorkspaceID:       workspaceID,
+		TargetWorkspaceID: req.TargetWorkspaceID,
+		CreatorID:         req.CreatorID,
+		Visibility:        visibility,
+		Comment:           req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditTaskTransferred, &workspaceID, map[string]any{
+		"task_id":         taskID,
+		"to_workspace_id": req.TargetWorkspaceID,
+		"creator_id":      req.CreatorID,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleClearReview lifts the human review hold of a task on behalf of an operator.
+// @Summary Clear a human review hold (operator)
+// @Description A task that changed hands (taken over or returned to NEW) as often as the workspace's max_attempts allows is held for human review: agents can no longer claim or take it over. Once an operator has looked at it, e.g. split it or clarified it, clearing the hold resets the task's attempts to 0 and records a human_review_cleared event. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.ClearReviewRequest false "Clear request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Task not held for human review"
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/tasks/{id}/clear-review [post]
+func (h *Handler) handleClearReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ClearReviewRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.ClearHumanReview(ctx, workspaceID, taskID, req.Comment)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditHumanReviewCleared, &workspaceID, map[string]any{
+		"task_id":  taskID,
+		"attempts": event.Data["attempts"],
+		"comment":  event.Comment,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleApproveTask accepts a WAITING_APPROVAL task on behalf of an operator.
+// @Summary Approve a task (operator)
+// @Description Accept a task waiting for approval: it moves to DONE (default) keeping the artefact it was submitted with, or with status IN_PROGRESS back to its assignee for more work. Agents cannot resolve WAITING_APPROVAL themselves. The DONE validation webhook is not called. Records an approval_granted event. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.ApproveTaskRequest false "Approval"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Task not WAITING_APPROVAL"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/tasks/{id}/approve [post]
+func (h *Handler) handleApproveTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ApproveTaskRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	newStatus := domain.TaskStatusDone
+	if req.Status != "" {
+		newStatus = domain.TaskStatus(req.Status)
+	}
+
+	event, err := h.taskService.ApproveTask(ctx, workspaceID, taskID, newStatus, req.Comment)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditTaskApproved, &workspaceID, map[string]any{
+		"task_id":    taskID,
+		"new_status": newStatus,
+		"comment":    event.Comment,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleRejectTask turns down a WAITING_APPROVAL task on behalf of an operator.
+// @Summary Reject a task (operator)
+// @Description Turn down a task waiting for approval: it returns to NEW, unassigned and with a fresh deadline, so another agent can pick it up. The comment saying why is required. Records an approval_rejected event.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.RejectTaskRequest true "Rejection"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Task not WAITING_APPROVAL"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/tasks/{id}/reject [post]
+func (h *Handler) handleRejectTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RejectTaskRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.RejectTask(ctx, workspaceID, taskID, req.Comment)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditTaskRejected, &workspaceID, map[string]any{
+		"task_id": taskID,
+		"comment": event.Comment,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleSetAutoAssignStrategy changes how NEW tasks of a workspace are auto-assigned.
+// @Summary Set auto-assignment strategy
+// @Description Choose how the auto-assign job hands NEW tasks to idle agents: none, round_robin, least_loaded or capability_match.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetAutoAssignStrategyRequest true "Strategy"
+// @Success 200 {object} dto.AutoAssignStrategyResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/auto-assign [put]
+func (h *Handler) handleSetAutoAssignStrategy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetAutoAssignStrategyRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	strategy := domain.AutoAssignStrategy(req.Strategy)
+	if !strategy.IsValid() {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR",
+			"strategy must be one of none, round_robin, least_loaded, capability_match")
+		return
+	}
+
+	if err := h.workspaceRepo.SetAutoAssignStrategy(ctx, workspaceID, strategy); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	slog.Info("workspace auto-assign strategy updated",
+		"workspace_id", workspaceID,
+		"strategy", strategy,
+	)
+
+	h.recordAudit(ctx, domain.AuditAutoAssignStrategy, &workspaceID, map[string]any{"strategy": strategy})
+
+	respondJSON(w, http.StatusOK, dto.AutoAssignStrategyResponse{
+		WorkspaceID: workspaceID,
+		Strategy:    string(strategy),
+	})
+}
+
+// handleSetPriorityInheritance turns priority inheritance on or off for a workspace.
+// @Summary Set priority inheritance
+// @Description While enabled, an open task blocking an open high or critical task inherits that priority: it is reported with an effective_priority and ranked by it in lists, next-task selection and auto-assignment. Bumps and their reversal are recorded as priority_inherited and priority_restored events. Toggling re-evaluates every affected task.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetPriorityInheritanceRequest true "Setting"
+// @Success 200 {object} dto.PriorityInheritanceResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/priority-inheritance [put]
+func (h *Handler) handleSetPriorityInheritance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetPriorityInheritanceRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.taskService.SetPriorityInheritance(ctx, workspaceID, req.Enabled)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditPriorityInheritance, &workspaceID, map[string]any{
+		"enabled":       req.Enabled,
+		"tasks_updated": updated,
+	})
+
+	respondJSON(w, http.StatusOK, dto.PriorityInheritanceResponse{
+		WorkspaceID:  workspaceID,
+		Enabled:      req.Enabled,
+		TasksUpdated: updated,
+	})
+}
+
+// handleSetAutoUnblock turns auto-unblocking of BLOCKED tasks on or off for a workspace.
+// @Summary Set auto-unblock
+// @Description Whatever the setting, when a task moves to DONE every open task it was the last unresolved blocker of gets a blockers_resolved event. While enabled, BLOCKED dependents also return to the status they were blocked from (usually IN_PROGRESS, keeping their assignee), recorded on that event. Escalated tasks stay BLOCKED.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetAutoUnblockRequest true "Setting"
+// @Success 200 {object} dto.AutoUnblockResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/auto-unblock [put]
+func (h *Handler) handleSetAutoUnblock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetAutoUnblockRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.workspaceRepo.SetAutoUnblock(ctx, workspaceID, req.Enabled); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	slog.Info("workspace auto-unblock updated",
+		"workspace_id", workspaceID,
+		"enabled", req.Enabled,
+	)
+
+	h.recordAudit(ctx, domain.AuditAutoUnblock, &workspaceID, map[string]any{"enabled": req.Enabled})
+
+	respondJSON(w, http.StatusOK, dto.AutoUnblockResponse{
+		WorkspaceID: workspaceID,
+		Enabled:     req.Enabled,
+	})
+}
+
+// handleSetAgentStaleAfter changes how long agents of a workspace may go without a heartbeat.
+// @Summary Set agent staleness window
+// @Description Agents whose last heartbeat (POST /agents/me/heartbeat) is older than stale_after_seconds are reported as stale in GET /agents and GET /stats. Default 300.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetAgentStaleAfterRequest true "Window"
+// @Success 200 {object} dto.AgentStaleAfterResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/agent-staleness [put]
+func (h *Handler) handleSetAgentStaleAfter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetAgentStaleAfterRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.StaleAfterSeconds < domain.MinAgentStaleAfterSeconds || req.StaleAfterSeconds > domain.MaxAgentStaleAfterSeconds {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR",
+			fmt.Sprintf("stale_after_seconds must be between %d and %d", domain.MinAgentStaleAfterSeconds, domain.MaxAgentStaleAfterSeconds))
+		return
+	}
+
+	if err := h.workspaceRepo.SetAgentStaleAfter(ctx, workspaceID, req.StaleAfterSeconds); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	slog.Info("workspace agent staleness window updated",
+		"workspace_id", workspaceID,
+		"stale_after_seconds", req.StaleAfterSeconds,
+	)
+
+	h.recordAudit(ctx, domain.AuditAgentStaleAfter, &workspaceID, map[string]any{"stale_after_seconds": req.StaleAfterSeconds})
+
+	respondJSON(w, http.StatusOK, dto.AgentStaleAfterResponse{
+		WorkspaceID:       workspaceID,
+		StaleAfterSeconds: req.StaleAfterSeconds,
+	})
+}
+
+// handleGetDoneValidation returns a workspace's DONE validation webhook.
+// @Summary Get DONE validation
+// @Description The webhook asked to approve every move to DONE in the workspace, if any
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.DoneValidationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/done-validation [get]
+func (h *Handler) handleGetDoneValidation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToDoneValidationResponse(workspaceID, workspace.DoneValidation))
+}
+
+// handleSetDoneValidation sets a workspace's DONE validation webhook.
+// @Summary Set DONE validation
+// @Description Every move to DONE in the workspace first POSTs a signed JSON request (task, agent, artefact, result, comment; see pkg/client DoneValidationRequest) to url and waits up to timeout_seconds. A 2xx response accepts the completion. A 4xx response rejects it: the transition fails with 422 DONE_REJECTED and the reason from the response ({"reason": "..."} or plain text). Other responses and timeouts fail the transition with 502 DONE_VALIDATION_UNAVAILABLE, unless fail_open is set. The outcome is recorded in the event's data.done_validation.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetDoneValidationRequest true "Webhook"
+// @Success 200 {object} dto.DoneValidationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/done-validation [put]
+func (h *Handler) handleSetDoneValidation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetDoneValidationRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	hook, err := h.taskService.SetDoneValidation(ctx, workspaceID, &domain.DoneValidationHook{
+		URL:      req.URL,
+		Timeout:  time.Duration(req.TimeoutSeconds) * time.Second,
+		FailOpen: req.FailOpen,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDoneValidation, &workspaceID, map[string]any{
+		"url":             hook.URL,
+		"timeout_seconds": int(hook.Timeout / time.Second),
+		"fail_open":       hook.FailOpen,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToDoneValidationResponse(workspaceID, hook))
+}
+
+// handleDeleteDoneValidation removes a workspace's DONE validation webhook.
+// @Summary Remove DONE validation
+// @Description Stop validating moves to DONE in the workspace
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.DoneValidationResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/done-validation [delete]
+func (h *Handler) handleDeleteDoneValidation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskService.SetDoneValidation(ctx, workspaceID, nil); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDoneValidation, &workspaceID, map[string]any{"url": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToDoneValidationResponse(workspaceID, nil))
+}
+
+// handleGetPriorityAging returns a workspace's priority aging policy.
+// @Summary Get priority aging
+// @Description How long NEW tasks may wait unclaimed in the workspace before they age, if at all
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.PriorityAgingResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/priority-aging [get]
+func (h *Handler) handleGetPriorityAging(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToPriorityAgingResponse(workspaceID, workspace.PriorityAging))
+}
+
+// handleSetPriorityAging sets a workspace's priority aging policy.
+// @Summary Set priority aging
+// @Description A NEW, unassigned task that has waited after_seconds since it became NEW (or last aged) ages: with action bump its priority is raised one step (low → normal → high → critical), with action warn it only gets an event. Either way a system priority_aged event is recorded and the wait starts over. The policy is applied by check-deadlines.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetPriorityAgingRequest true "Policy"
+// @Success 200 {object} dto.PriorityAgingResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/priority-aging [put]
+func (h *Handler) handleSetPriorityAging(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetPriorityAgingRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	aging, err := h.taskService.SetPriorityAging(ctx, workspaceID, &domain.PriorityAging{
+		After:  time.Duration(req.AfterSeconds) * time.Second,
+		Action: domain.PriorityAgingAction(req.Action),
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditPriorityAging, &workspaceID, map[string]any{
+		"after_seconds": int(aging.After / time.Second),
+		"action":        aging.Action,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToPriorityAgingResponse(workspaceID, aging))
+}
+
+// handleDeletePriorityAging turns priority aging off in a workspace.
+// @Summary Remove priority aging
+// @Description Stop aging unclaimed NEW tasks in the workspace. Priorities already raised stay.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.PriorityAgingResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/priority-aging [delete]
+func (h *Handler) handleDeletePriorityAging(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskService.SetPriorityAging(ctx, workspaceID, nil); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditPriorityAging, &workspaceID, map[string]any{"after_seconds": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToPriorityAgingResponse(workspaceID, nil))
+}
+
+// handleGetClaimFairness returns a workspace's claim limits.
+// @Summary Get claim fairness
+// @Description The claim quota per agent and whether agents take turns while others are idle
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.ClaimFairnessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/claim-fairness [get]
+func (h *Handler) handleGetClaimFairness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToClaimFairnessResponse(workspaceID, workspace.ClaimFairness))
+}
+
+// handleSetClaimFairness sets a workspace's claim limits.
+// @Summary Set claim fairness
+// @Description Keeps the fastest polling agent from claiming all the work. With a quota, an agent that claimed quota tasks within the last window_seconds (default 3600) gets 429 CLAIM_QUOTA_EXCEEDED. With take_turns, the agent that made the workspace's last claim gets 409 CLAIM_TURN while another live agent (heartbeat within the staleness window) with the task's required capabilities holds no IN_PROGRESS task. Both apply to claim and claim-next, not to auto-assignment.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetClaimFairnessRequest true "Limits"
+// @Success 200 {object} dto.ClaimFairnessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/claim-fairness [put]
+func (h *Handler) handleSetClaimFairness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetClaimFairnessRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	fairness, err := h.taskService.SetClaimFairness(ctx, workspaceID, domain.ClaimFairness{
+		Quota:     req.Quota,
+		Window:    time.Duration(req.WindowSeconds) * time.Second,
+		TakeTurns: req.TakeTurns,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditClaimFairness, &workspaceID, map[string]any{
+		"quota":          fairness.Quota,
+		"window_seconds": int(fairness.Window / time.Second),
+		"take_turns":     fairness.TakeTurns,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToClaimFairnessResponse(workspaceID, fairness))
+}
+
+// handleDeleteClaimFairness lifts a workspace's claim limits.
+// @Summary Remove claim fairness
+// @Description Let agents claim without a quota or turns
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.ClaimFairnessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/claim-fairness [delete]
+func (h *Handler) handleDeleteClaimFairness(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	fairness, err := h.taskService.SetClaimFairness(ctx, workspaceID, domain.ClaimFairness{})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditClaimFairness, &workspaceID, map[string]any{"quota": 0, "take_turns": false})
+
+	respondJSON(w, http.StatusOK, dto.ToClaimFairnessResponse(workspaceID, fairness))
+}
+
+// handleGetDeadlineWarning returns a workspace's deadline warning setting.
+// @Summary Get deadline warning
+// @Description How much of a status deadline is left when tasks in the workspace get a deadline_warning event, if at all
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.DeadlineWarningResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/deadline-warning [get]
+func (h *Handler) handleGetDeadlineWarning(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToDeadlineWarningResponse(workspaceID, workspace.DeadlineWarningPercent))
+}
+
+// handleSetDeadlineWarning turns on deadline warnings for a workspace.
+// @Summary Set deadline warning
+// @Description Once a NEW, IN_PROGRESS or BLOCKED task has no more than percent of its status deadline left, it gets a system deadline_warning event (data.status, data.deadline_at, data.remaining_seconds, data.percent), once per deadline. Like every task event it is announced with Postgres NOTIFY on the sloptask_task_changes channel. The share is measured against the workspace's current deadline for the status. Warnings are recorded by check-deadlines, so they are only as timely as its schedule.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetDeadlineWarningRequest true "Setting"
+// @Success 200 {object} dto.DeadlineWarningResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/deadline-warning [put]
+func (h *Handler) handleSetDeadlineWarning(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetDeadlineWarningRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Percent == 0 {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR",
+			fmt.Sprintf("percent must be between %d and %d", domain.MinDeadlineWarningPercent, domain.MaxDeadlineWarningPercent))
+		return
+	}
+
+	if err := h.taskService.SetDeadlineWarning(ctx, workspaceID, req.Percent); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDeadlineWarning, &workspaceID, map[string]any{"percent": req.Percent})
+
+	respondJSON(w, http.StatusOK, dto.ToDeadlineWarningResponse(workspaceID, req.Percent))
+}
+
+// handleDeleteDeadlineWarning turns deadline warnings off in a workspace.
+// @Summary Remove deadline warning
+// @Description Stop warning about approaching status deadlines in the workspace
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.DeadlineWarningResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/deadline-warning [delete]
+func (h *Handler) handleDeleteDeadlineWarning(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.SetDeadlineWarning(ctx, workspaceID, 0); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDeadlineWarning, &workspaceID, map[string]any{"percent": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToDeadlineWarningResponse(workspaceID, 0))
+}
+
+// handleGetDeadlineExpiry returns what happens to a workspace's tasks when a status deadline expires.
+// @Summary Get deadline expiry
+// @Description The status a task enters when its deadline expires, for each status with a deadline (NEW, IN_PROGRESS, BLOCKED)
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.DeadlineExpiryResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/deadline-expiry [get]
+func (h *Handler) handleGetDeadlineExpiry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToDeadlineExpiryResponse(workspaceID, workspace.DeadlineExpiry))
+}
+
+// handleSetDeadlineExpiry sets what happens to a workspace's tasks when a status deadline expires.
+// @Summary Set deadline expiry
+// @Description Replaces the status a task enters when its deadline in a status expires; statuses left out go to STUCK, so {"expiry": {}} restores the default. NEW may go to STUCK or CANCELLED; IN_PROGRESS and BLOCKED to STUCK, NEW or CANCELLED. A task moved to NEW loses its assignee and gets a fresh NEW deadline. Expiry is applied by check-deadlines with a system deadline_expired event.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetDeadlineExpiryRequest true "Expiry statuses"
+// @Success 200 {object} dto.DeadlineExpiryResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/deadline-expiry [put]
+func (h *Handler) handleSetDeadlineExpiry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetDeadlineExpiryRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.taskService.SetDeadlineExpiry(ctx, workspaceID, req.Expiry); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditDeadlineExpiry, &workspaceID, map[string]any{"expiry": req.Expiry})
+
+	respondJSON(w, http.StatusOK, dto.ToDeadlineExpiryResponse(workspaceID, req.Expiry))
+}
+
+// handleGetMaxAttempts returns a workspace's attempt limit.
+// @Summary Get max attempts
+// @Description How many times a task may change hands before it is held for human review, if there is a limit
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.MaxAttemptsResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/max-attempts [get]
+func (h *Handler) handleGetMaxAttempts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToMaxAttemptsResponse(workspaceID, workspace.MaxAttempts))
+}
+
+// handleSetMaxAttempts limits how often a workspace's tasks may change hands.
+// @Summary Set max attempts
+// @Description Every takeover and every return to NEW (given up by its assignee, released from a stale agent, or sent back by an expired deadline) counts as an attempt on the task. When the attempts reach max_attempts, the task is held for human review with a system attempts_exceeded event: agents can no longer claim or take it over, auto-assignment skips it, and GET /tasks?human_review=true lists it. The hand-off that reaches the limit still goes through. An operator lifts the hold with POST /admin/workspaces/{workspace_id}/tasks/{id}/clear-review.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetMaxAttemptsRequest true "Limit"
+// @Success 200 {object} dto.MaxAttemptsResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/max-attempts [put]
+func (h *Handler) handleSetMaxAttempts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetMaxAttemptsRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.MaxAttempts == 0 {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR",
+			fmt.Sprintf("max_attempts must be between 1 and %d", domain.MaxAttemptsLimit))
+		return
+	}
+
+	if err := h.taskService.SetMaxAttempts(ctx, workspaceID, req.MaxAttempts); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditMaxAttempts, &workspaceID, map[string]any{"max_attempts": req.MaxAttempts})
+
+	respondJSON(w, http.StatusOK, dto.ToMaxAttemptsResponse(workspaceID, req.MaxAttempts))
+}
+
+// handleDeleteMaxAttempts removes a workspace's attempt limit.
+// @Summary Remove max attempts
+// @Description Let tasks of the workspace change hands without limit. Tasks already held for human review stay held until cleared.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.MaxAttemptsResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/max-attempts [delete]
+func (h *Handler) handleDeleteMaxAttempts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.SetMaxAttempts(ctx, workspaceID, 0); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditMaxAttempts, &workspaceID, map[string]any{"max_attempts": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToMaxAttemptsResponse(workspaceID, 0))
+}
+
+// recordAudit appends an operator action to the admin audit log. The action has
+// already taken effect, so a failure is logged instead of failing the request.
+func (h *Handler) recordAudit(ctx context.Context, action domain.AuditAction, workspaceID *string, details map[string]any) {
+	entry := &domain.AuditEntry{Action: action, WorkspaceID: workspaceID, Details: details}
+	if err := h.auditRepo.Record(ctx, entry); err != nil {
+		slog.Error("failed to record audit entry", "action", action, "error", err)
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+// handleListAgents lists the agents of the caller's workspace with their liveness.
+// @Summary List agents
+// @Description Agents of the workspace by name, with their last heartbeat. An agent is stale once its last heartbeat is older than the workspace's stale_after_seconds; agents that never sent a heartbeat are not stale. Accepts a read token.
+// @Tags agents
+// @Produce json
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Param stale query bool false "Only stale agents (true) or only agents that are not stale (false)"
+// @Success 200 {object} dto.AgentsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /agents [get]
+func (h *Handler) handleListAgents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Agents and workspace read tokens may both list agents
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var staleFilter *bool
+	if staleParam := r.URL.Query().Get("stale"); staleParam != "" {
+		stale, err := strconv.ParseBool(staleParam)
+		if err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "stale must be true or false")
+			return
+		}
+		staleFilter = &stale
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	agents, err := h.agentRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch agents")
+		return
+	}
+
+	now := time.Now()
+	response := dto.AgentsResponse{
+		Agents:            []dto.AgentInfo{},
+		StaleAfterSeconds: workspace.AgentStaleAfterSeconds,
+	}
+	for _, agent := range agents {
+		stale := agent.IsStale(now, workspace.AgentStaleAfter())
+		if staleFilter != nil && stale != *staleFilter {
+			continue
+		}
+		capabilities := agent.Capabilities
+		if capabilities == nil {
+			capabilities = []string{}
+		}
+		response.Agents = append(response.Agents, dto.AgentInfo{
+			ID:           agent.ID,
+			Name:         agent.Name,
+			IsActive:     agent.IsActive,
+			Capabilities: capabilities,
+			LastSeenAt:   agent.LastSeenAt,
+			Stale:        stale,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleGetMe describes the calling agent.
+// @Summary Get current agent
+// @Description The calling agent's ID, name, capabilities and liveness, its workspace, a summary of its open tasks: how many are IN_PROGRESS (wip_count), assigned by status, overdue, and created by it, and its unread direct messages.
+// @Tags agents
+// @Produce json
+// @Success 200 {object} dto.AgentMeResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /agents/me [get]
+func (h *Handler) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	me, err := h.describeAgent(ctx, agent, workspace)
+	if err != nil {
+		slog.Error("failed to describe agent", "agent_id", agent.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch agent tasks")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, me)
+}
+
+// openTaskStatuses are the statuses of tasks that are not DONE or CANCELLED.
+var openTaskStatuses = []domain.TaskStatus{
+	domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusNeedsReview, domain.TaskStatusBlocked,
+	domain.TaskStatusAwaitingExternal, domain.TaskStatusWaitingApproval, domain.TaskStatusStuck,
+}
+
+// describeAgent builds the GET /agents/me description of an agent of the workspace.
+func (h *Handler) describeAgent(ctx context.Context, agent *domain.Agent, workspace *domain.Workspace) (dto.AgentMeResponse, error) {
+	summary, err := h.taskRepo.GetAgentTaskSummary(ctx, agent.ID)
+	if err != nil {
+		return dto.AgentMeResponse{}, err
+	}
+
+	unread, err := h.messageService.CountUnread(ctx, agent.ID)
+	if err != nil {
+		return dto.AgentMeResponse{}, fmt.Errorf("count unread messages: %w", err)
+	}
+
+	capabilities := agent.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+
+	openTasks := dto.AgentOpenTasks{
+		AssignedByStatus: make(map[string]int),
+		Overdue:          summary.Overdue,
+		Created:          summary.CreatedOpen,
+	}
+	for _, status := range openTaskStatuses {
+		count := summary.AssignedByStatus[string(status)]
+		openTasks.AssignedByStatus[string(status)] = count
+		openTasks.Assigned += count
+	}
+
+	return dto.AgentMeResponse{
+		ID:           agent.ID,
+		Name:         agent.Name,
+		Capabilities: capabilities,
+		LastSeenAt:   agent.LastSeenAt,
+		Stale:        agent.IsStale(time.Now(), workspace.AgentStaleAfter()),
+		Workspace: dto.WorkspaceSummary{
+			ID:                workspace.ID,
+			Name:              workspace.Name,
+			Slug:              workspace.Slug,
+			StaleAfterSeconds: workspace.AgentStaleAfterSeconds,
+		},
+		WIPCount:       openTasks.AssignedByStatus[string(domain.TaskStatusInProgress)],
+		OpenTasks:      openTasks,
+		UnreadMessages: unread,
+	}, nil
+}
+
+// handleHeartbeat records that the calling agent is alive.
+// @Summary Send heartbeat
+// @Description Record that the calling agent is alive. Agents that send heartbeats become stale when they stop for longer than the workspace's stale_after_seconds (default 300); staleness shows in GET /agents and GET /stats. The response counts the agent's unread direct messages.
+// @Tags agents
+// @Produce json
+// @Success 200 {object} dto.HeartbeatResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /agents/me/heartbeat [post]
+func (h *Handler) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	lastSeenAt, err := h.agentRepo.Heartbeat(ctx, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	// GET /agents/me reports the new last_seen_at
+	h.authMiddleware.RefreshAgent(agent.ID)
+
+	// The heartbeat doubles as the agent's notification poll
+	unread, err := h.messageService.CountUnread(ctx, agent.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to count unread messages")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.HeartbeatResponse{
+		AgentID:           agent.ID,
+		LastSeenAt:        lastSeenAt,
+		StaleAfterSeconds: workspace.AgentStaleAfterSeconds,
+		UnreadMessages:    unread,
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleAddChecklistItem appends an item to a task's checklist.
+// @Summary Add checklist item
+// @Description Creator or assignee splits a task into checklist items that helper agents can claim
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.AddChecklistItemRequest true "Checklist item"
+// @Success 201 {object} dto.ChecklistItemInfo
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/checklist [post]
+func (h *Handler) handleAddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.AddChecklistItemRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	item, err := h.taskService.AddChecklistItem(ctx, taskID, agent.ID, req.Title)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToChecklistItemInfo(item))
+}
+
+// handleClaimChecklistItem claims a single checklist item without reassigning the task.
+// @Summary Claim checklist item
+// @Description Helper agent claims one checklist item of an IN_PROGRESS task; the task assignee is unchanged
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param item_id path string true "Checklist item ID"
+// @Param request body dto.ChecklistItemActionRequest true "Claim request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/checklist/{item_id}/claim [post]
+func (h *Handler) handleClaimChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.handleChecklistItemAction(w, r, h.taskService.ClaimChecklistItem)
+}
+
+// handleCompleteChecklistItem marks a checklist item as completed.
+// @Summary Complete checklist item
+// @Description Agent that claimed the item, or the task assignee, marks it completed
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param item_id path string true "Checklist item ID"
+// @Param request body dto.ChecklistItemActionRequest true "Complete request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/checklist/{item_id}/complete [post]
+func (h *Handler) handleCompleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	h.handleChecklistItemAction(w, r, h.taskService.CompleteChecklistItem)
+}
+
+// handleChecklistItemAction parses a checklist item request and applies the given service operation.
+func (h *Handler) handleChecklistItemAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action func(ctx context.Context, params service.ChecklistItemParams) (*domain.TaskEvent, error),
+) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, ok := extractPathUUID(w, r, "item_id", "checklist item id")
+	if !ok {
+		return
+	}
+
+	var req dto.ChecklistItemActionRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Comment == "" {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "comment is required")
+		return
+	}
+
+	event, err := action(ctx, service.ChecklistItemParams{
+		TaskID:  taskID,
+		ItemID:  itemID,
+		AgentID: agent.ID,
+		Comment: req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
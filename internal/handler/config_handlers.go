@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/config"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// Reload sources recorded in the audit log.
+const (
+	ReloadSourceAPI    = "api"
+	ReloadSourceSignal = "signal"
+)
+
+// ReloadRuntimeConfig reads the runtime settings file again and activates it,
+// recording the reload and what changed in the audit log. An invalid file leaves
+// the active settings untouched. source says what triggered the reload.
+func (h *Handler) ReloadRuntimeConfig(ctx context.Context, source string) (config.Runtime, map[string]any, error) {
+	settings, changes, err := h.runtime.Reload()
+	if err != nil {
+		return config.Runtime{}, nil, err
+	}
+
+	h.recordAudit(ctx, domain.AuditConfigReloaded, nil, map[string]any{
+		"source":  source,
+		"file":    h.runtime.Path(),
+		"changes": changes,
+	})
+	slog.Info("runtime settings reloaded", "source", source, "changes", changes)
+
+	return settings, changes, nil
+}
+
+// runtimeConfigResponse converts runtime settings to their API form.
+func (h *Handler) runtimeConfigResponse(settings config.Runtime) dto.RuntimeConfigResponse {
+	return dto.RuntimeConfigResponse{
+		File:                  h.runtime.Path(),
+		LogLevel:              settings.LogLevel,
+		IntakeRequestsPerHour: settings.IntakeRequestsPerHour,
+		ReadOnly:              h.readOnly.Enabled(),
+	}
+}
+
+// handleGetRuntimeConfig returns the active runtime settings.
+// @Summary Get runtime settings
+// @Description The settings the server applies without a restart (log level, per-IP intake rate limit, read-only mode) and the file they are reloaded from.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.RuntimeConfigResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/config [get]
+func (h *Handler) handleGetRuntimeConfig(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.runtimeConfigResponse(h.runtime.Current()))
+}
+
+// handleReloadRuntimeConfig reloads the runtime settings file, like SIGHUP.
+// @Summary Reload runtime settings
+// @Description Reads the runtime settings file again, validates it and swaps the active settings in one step. An invalid file leaves the running settings untouched. The reload and what changed are recorded in the audit log.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.ReloadConfigResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "No settings file configured"
+// @Failure 422 {object} dto.ErrorResponse "Settings file missing or invalid"
+// @Security BearerAuth
+// @Router /admin/config/reload [post]
+func (h *Handler) handleReloadRuntimeConfig(w http.ResponseWriter, r *http.Request) {
+	settings, changes, err := h.ReloadRuntimeConfig(r.Context(), ReloadSourceAPI)
+	if errors.Is(err, config.ErrNoRuntimeFile) {
+		respondError(w, http.StatusConflict, "NO_CONFIG_FILE", "Server was started without --runtime-config")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "INVALID_CONFIG", err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ReloadConfigResponse{
+		Settings: h.runtimeConfigResponse(settings),
+		Changes:  changes,
+	})
+}
+
+// handleSetReadOnly switches the server's read-only mode on or off.
+// @Summary Set read-only mode
+// @Description While enabled, the API keeps serving reads but rejects writes with 503 and Retry-After: 60, e.g. during migrations or incident response with agents still polling. The admin API, agent heartbeats, GraphQL and the Grafana endpoints stay usable. Servers sharing a Redis (--redis-url) switch together, and check-deadlines and auto-assign given the same Redis pause their passes; without Redis it applies to this server process only. Holds until switched again, or until a reload changes read_only in the runtime settings file. Recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.SetReadOnlyRequest true "Setting"
+// @Success 200 {object} dto.ReadOnlyResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/read-only [put]
+func (h *Handler) handleSetReadOnly(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetReadOnlyRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	changed, err := h.readOnly.Switch(r.Context(), req.Enabled)
+	if changed {
+		slog.Warn("read-only mode switched", "enabled", req.Enabled)
+		h.recordAudit(r.Context(), domain.AuditReadOnly, nil, map[string]any{"enabled": req.Enabled})
+	}
+	if err != nil {
+		slog.Error("failed to switch read-only mode on the other replicas", "enabled", req.Enabled, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Read-only mode was switched on this server only: failed to reach the other replicas")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ReadOnlyResponse{Enabled: req.Enabled})
+}
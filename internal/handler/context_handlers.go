@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/static"
+)
+
+// Sizes of the lists in GET /context; the full lists have their own endpoints.
+const (
+	contextMaxAssignments = 50
+	contextMaxEscalations = 10
+)
+
+// handleGetContext bundles what a freshly started agent needs to get going.
+// @Summary Get agent context
+// @Description Everything an agent needs on start-up in one call: itself as in GET /agents/me, the workspace's status deadlines (minutes per status, the status entered on expiry, the deadline warning share), a summary of the workspace's open tasks, its own open assignments (up to 50, most urgent first), its 10 most recent escalations, and the skill.md guide (for one role with ?role=, including the agent section).
+// @Tags agents
+// @Produce json
+// @Param role query string false "Guide for one role: worker, orchestrator or operator; the full skill.md by default"
+// @Success 200 {object} dto.ContextResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /context [get]
+func (h *Handler) handleGetContext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	role := r.URL.Query().Get("role")
+	if _, ok := skillRoleSections[role]; role != "" && !ok {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "role must be 'worker', 'orchestrator' or 'operator'")
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	me, err := h.describeAgent(ctx, agent, workspace)
+	if err != nil {
+		slog.Error("failed to describe agent", "agent_id", agent.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch agent tasks")
+		return
+	}
+
+	summary, err := h.taskRepo.GetWorkspaceTaskSummary(ctx, workspace.ID)
+	if err != nil {
+		slog.Error("failed to summarize workspace tasks", "workspace_id", workspace.ID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch workspace tasks")
+		return
+	}
+
+	statuses := make([]string, len(openTaskStatuses))
+	for i, status := range openTaskStatuses {
+		statuses[i] = string(status)
+	}
+	assignments, _, err := h.taskRepo.List(ctx, repository.TaskListFilters{
+		WorkspaceID: workspace.ID,
+		AgentID:     agent.ID,
+		Statuses:    statuses,
+		AssigneeID:  &agent.ID,
+		Limit:       contextMaxAssignments,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list tasks")
+		return
+	}
+
+	escalations, _, err := h.escalationService.ListAgentEscalations(ctx, agent.ID, contextMaxEscalations, 0)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch escalations")
+		return
+	}
+
+	skillMd := static.SkillMd
+	if role != "" {
+		skillMd = skillRoleDoc(role, skillAgentSection(role, agent, workspace))
+	}
+
+	now := time.Now().UTC()
+	response := dto.ContextResponse{
+		Agent:     me,
+		Deadlines: dto.ToWorkspaceDeadlines(workspace),
+		WorkspaceTasks: dto.WorkspaceTaskSummary{
+			ByStatus:  make(map[string]int),
+			Overdue:   summary.Overdue,
+			Claimable: summary.Claimable,
+		},
+		Assignments:       make([]dto.TaskListResponse, len(assignments)),
+		RecentEscalations: make([]dto.EscalationInfo, len(escalations)),
+		SkillMd:           skillMd,
+		ServerTime:        now,
+	}
+	for _, status := range openTaskStatuses {
+		count := summary.ByStatus[string(status)]
+		response.WorkspaceTasks.ByStatus[string(status)] = count
+		response.WorkspaceTasks.Open += count
+	}
+	for i, result := range assignments {
+		response.Assignments[i] = dto.ToTaskListResponse(result.Task, result.HasUnresolvedBlockers, result.IsOverdue, now)
+	}
+	for i, notification := range escalations {
+		response.RecentEscalations[i] = dto.ToEscalationInfo(notification)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
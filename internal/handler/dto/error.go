@@ -14,10 +14,12 @@ type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
 
-// ErrorDetail contains error code and message.
+// ErrorDetail contains error code and message, and for some codes structured
+// details listed in the error catalog.
 type ErrorDetail struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
 }
 
 // NewErrorResponse creates a new error response.
@@ -46,6 +48,99 @@ func MapDomainError(err error) (status int, code string, message string) {
 		return http.StatusConflict, "UNRESOLVED_BLOCKERS", message
 	case errors.Is(err, domain.ErrCyclicDependency):
 		return http.StatusConflict, "CYCLIC_DEPENDENCY", message
+	case errors.Is(err, domain.ErrHumanReviewHold):
+		return http.StatusConflict, "HUMAN_REVIEW_HOLD", message
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict, "VERSION_CONFLICT", message
+
+	case errors.Is(err, domain.ErrApprovalRequired):
+		return http.StatusConflict, "APPROVAL_REQUIRED", message
+
+	// Claim fairness errors
+	case errors.Is(err, domain.ErrClaimQuotaExceeded):
+		return http.StatusTooManyRequests, "CLAIM_QUOTA_EXCEEDED", message
+	case errors.Is(err, domain.ErrClaimTurn):
+		return http.StatusConflict, "CLAIM_TURN", message
+
+	// DONE validation errors
+	case errors.Is(err, domain.ErrDoneRejected):
+		return http.StatusUnprocessableEntity, "DONE_REJECTED", message
+	case errors.Is(err, domain.ErrDoneValidationUnavailable):
+		return http.StatusBadGateway, "DONE_VALIDATION_UNAVAILABLE", message
+
+	// External reference errors
+	case errors.Is(err, domain.ErrExternalRefNotFound):
+		return http.StatusNotFound, "EXTERNAL_REF_NOT_FOUND", message
+
+	// Schedule errors
+	case errors.Is(err, domain.ErrScheduleNotFound):
+		return http.StatusNotFound, "SCHEDULE_NOT_FOUND", message
+	case errors.Is(err, domain.ErrScheduleExists):
+		return http.StatusConflict, "SCHEDULE_EXISTS", message
+	case errors.Is(err, domain.ErrInvalidCron):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
+	case errors.Is(err, domain.ErrInvalidTimezone):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
+
+	// Report errors
+	case errors.Is(err, domain.ErrReportNotFound):
+		return http.StatusNotFound, "REPORT_NOT_FOUND", message
+	case errors.Is(err, domain.ErrReportExists):
+		return http.StatusConflict, "REPORT_EXISTS", message
+
+	// Message errors
+	case errors.Is(err, domain.ErrMessageNotFound):
+		return http.StatusNotFound, "MESSAGE_NOT_FOUND", message
+	case errors.Is(err, domain.ErrOutboxMessageNotFound):
+		return http.StatusNotFound, "OUTBOX_MESSAGE_NOT_FOUND", message
+
+	// Intake errors
+	case errors.Is(err, domain.ErrIntakeNotFound):
+		return http.StatusNotFound, "INTAKE_NOT_FOUND", message
+	case errors.Is(err, domain.ErrInvalidIntakeKey):
+		return http.StatusUnauthorized, "INVALID_INTAKE_KEY", message
+	case errors.Is(err, domain.ErrIntakeRateLimited):
+		return http.StatusTooManyRequests, "RATE_LIMITED", message
+
+	// Label errors
+	case errors.Is(err, domain.ErrLabelNotFound):
+		return http.StatusNotFound, "LABEL_NOT_FOUND", message
+	case errors.Is(err, domain.ErrLabelExists):
+		return http.StatusConflict, "LABEL_EXISTS", message
+	case errors.Is(err, domain.ErrUnknownLabel):
+		return http.StatusUnprocessableEntity, "UNKNOWN_LABEL", message
+
+	// Queue errors
+	case errors.Is(err, domain.ErrQueueNotFound):
+		return http.StatusNotFound, "QUEUE_NOT_FOUND", message
+	case errors.Is(err, domain.ErrQueueExists):
+		return http.StatusConflict, "QUEUE_EXISTS", message
+	case errors.Is(err, domain.ErrQueueNotEmpty):
+		return http.StatusConflict, "QUEUE_NOT_EMPTY", message
+	case errors.Is(err, domain.ErrNoClaimableTask):
+		return http.StatusNotFound, "NO_TASK_AVAILABLE", message
+
+	// Saved view errors
+	case errors.Is(err, domain.ErrViewNotFound):
+		return http.StatusNotFound, "VIEW_NOT_FOUND", message
+	case errors.Is(err, domain.ErrViewExists):
+		return http.StatusConflict, "VIEW_EXISTS", message
+
+	// Checklist errors
+	case errors.Is(err, domain.ErrChecklistItemNotFound):
+		return http.StatusNotFound, "CHECKLIST_ITEM_NOT_FOUND", message
+	case errors.Is(err, domain.ErrChecklistItemClaimed):
+		return http.StatusConflict, "CHECKLIST_ITEM_ALREADY_CLAIMED", message
+	case errors.Is(err, domain.ErrChecklistItemCompleted):
+		return http.StatusConflict, "CHECKLIST_ITEM_ALREADY_COMPLETED", message
+
+	// Comment errors
+	case errors.Is(err, domain.ErrCommentNotFound):
+		return http.StatusNotFound, "COMMENT_NOT_FOUND", message
+	case errors.Is(err, domain.ErrCommentEditWindowClosed):
+		return http.StatusConflict, "COMMENT_EDIT_WINDOW_CLOSED", message
+	case errors.Is(err, domain.ErrCommentRedacted):
+		return http.StatusConflict, "COMMENT_REDACTED", message
 
 	// Permission errors
 	case errors.Is(err, domain.ErrPermissionDenied):
@@ -54,6 +149,8 @@ func MapDomainError(err error) (status int, code string, message string) {
 		return http.StatusForbidden, "INSUFFICIENT_ACCESS", message
 	case errors.Is(err, domain.ErrNotTaskCreator):
 		return http.StatusForbidden, "INSUFFICIENT_ACCESS", message
+	case errors.Is(err, domain.ErrMissingCapabilities):
+		return http.StatusForbidden, "MISSING_CAPABILITIES", message
 
 	// Agent errors
 	case errors.Is(err, domain.ErrAgentNotFound):
@@ -66,8 +163,28 @@ func MapDomainError(err error) (status int, code string, message string) {
 	// Workspace errors
 	case errors.Is(err, domain.ErrWorkspaceNotFound):
 		return http.StatusNotFound, "WORKSPACE_NOT_FOUND", message
+	case errors.Is(err, domain.ErrWorkspaceArchived):
+		return http.StatusConflict, "WORKSPACE_ARCHIVED", message
+	case errors.Is(err, domain.ErrWorkspaceExists):
+		return http.StatusConflict, "WORKSPACE_EXISTS", message
+	case errors.Is(err, domain.ErrExportRequired):
+		return http.StatusConflict, "EXPORT_REQUIRED", message
+
+	// Read token errors
+	case errors.Is(err, domain.ErrReadTokenNotFound):
+		return http.StatusNotFound, "READ_TOKEN_NOT_FOUND", message
+
+	// Webhook secret errors
+	case errors.Is(err, domain.ErrWebhookSecretNotFound):
+		return http.StatusNotFound, "WEBHOOK_SECRET_NOT_FOUND", message
+	case errors.Is(err, domain.ErrRotationInProgress):
+		return http.StatusConflict, "ROTATION_IN_PROGRESS", message
+	case errors.Is(err, domain.ErrNoRotationInProgress):
+		return http.StatusConflict, "NO_ROTATION_IN_PROGRESS", message
 
 	// Validation errors
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
 	case errors.Is(err, domain.ErrInvalidStatus):
 		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
 	case errors.Is(err, domain.ErrInvalidVisibility):
@@ -80,6 +197,12 @@ func MapDomainError(err error) (status int, code string, message string) {
 		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
 	case errors.Is(err, domain.ErrInvalidArtefactURL):
 		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
+	case errors.Is(err, domain.ErrEventDataTooLarge):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
+	case errors.Is(err, domain.ErrTaskResultTooLarge):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
+	case errors.Is(err, domain.ErrInvalidExternalRef):
+		return http.StatusUnprocessableEntity, "VALIDATION_ERROR", message
 
 	// Default: internal server error
 	default:
@@ -0,0 +1,332 @@
+package dto
+
+import "net/http"
+
+// ErrorCatalogEntry describes an error code of the API.
+type ErrorCatalogEntry struct {
+	Code        string   `json:"code"`
+	Statuses    []int    `json:"statuses"`
+	Description string   `json:"description"`
+	Remediation string   `json:"remediation"`
+	Details     []string `json:"details,omitempty"` // keys of error.details
+}
+
+// ErrorCatalogResponse represents the response for GET /errors.
+type ErrorCatalogResponse struct {
+	Errors []ErrorCatalogEntry `json:"errors"`
+}
+
+// ErrorCatalog lists every error code the handlers answer with.
+var ErrorCatalog = []ErrorCatalogEntry{
+	// Requests
+	{
+		Code: "INVALID_JSON", Statuses: []int{http.StatusBadRequest},
+		Description: "The request body is not valid JSON for the endpoint.",
+		Remediation: "Fix the JSON and its field types, then retry.",
+	},
+	{
+		Code: "INVALID_REQUEST", Statuses: []int{http.StatusBadRequest},
+		Description: "A path parameter or form body is malformed, e.g. an ID that is not a UUID.",
+		Remediation: "Fix the request as the message says; retrying it unchanged fails again.",
+	},
+	{
+		Code: "UNKNOWN_FIELD", Statuses: []int{http.StatusUnprocessableEntity},
+		Description: "The request body has a field the endpoint doesn't take.",
+		Remediation: "Check the field's spelling against the API docs and drop fields the endpoint doesn't list.",
+	},
+	{
+		Code: "PAYLOAD_TOO_LARGE", Statuses: []int{http.StatusRequestEntityTooLarge},
+		Description: "The request body is over the server's limit, 1 MiB by default.",
+		Remediation: "Shorten the text or link to an artefact instead of inlining it.",
+	},
+	{
+		Code: "VALIDATION_ERROR", Statuses: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		Description: "A field is missing or invalid.",
+		Remediation: "Fix the field the message names, then retry.",
+		Details:     []string{"missing_blockers", "unknown_mentions", "hidden_mentions"},
+	},
+	{
+		Code: "INTERNAL_ERROR", Statuses: []int{http.StatusInternalServerError},
+		Description: "The server failed to handle the request.",
+		Remediation: "Retry later with backoff; report it if it persists.",
+	},
+
+	// Authentication and access
+	{
+		Code: "INVALID_TOKEN", Statuses: []int{http.StatusUnauthorized},
+		Description: "The bearer token is missing, unknown or of the wrong kind for the endpoint.",
+		Remediation: "Send Authorization: Bearer <token> with a valid agent token; read tokens only read.",
+	},
+	{
+		Code: "AGENT_INACTIVE", Statuses: []int{http.StatusUnauthorized},
+		Description: "The agent's account is disabled.",
+		Remediation: "Stop working and ask an operator to reactivate the agent.",
+	},
+	{
+		Code: "INSUFFICIENT_ACCESS", Statuses: []int{http.StatusForbidden},
+		Description: "The task is private, in another workspace, or the action belongs to its creator or assignee.",
+		Remediation: "Don't retry; leave the task to the agent the message names, or comment to ask them.",
+	},
+	{
+		Code: "MISSING_CAPABILITIES", Statuses: []int{http.StatusForbidden},
+		Description: "The agent lacks capabilities the task requires.",
+		Remediation: "Pick another task; an operator can grant the missing capabilities.",
+		Details:     []string{"missing_capabilities"},
+	},
+
+	// Tasks
+	{
+		Code: "TASK_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "The task doesn't exist or isn't visible to the caller.",
+		Remediation: "Check the ID; list tasks to find it.",
+	},
+	{
+		Code: "TASK_ALREADY_CLAIMED", Statuses: []int{http.StatusConflict},
+		Description: "Another agent claimed the task first.",
+		Remediation: "Pick another task, e.g. with POST /tasks/claim-next.",
+	},
+	{
+		Code: "INVALID_TRANSITION", Statuses: []int{http.StatusConflict},
+		Description: "The state machine doesn't allow the change from the task's current status.",
+		Remediation: "Re-read the task and pick a status from details.allowed_statuses, or use the operation the message names.",
+		Details:     []string{"status", "allowed_statuses"},
+	},
+	{
+		Code: "UNRESOLVED_BLOCKERS", Statuses: []int{http.StatusConflict},
+		Description: "Tasks this one depends on are not DONE yet.",
+		Remediation: "Wait for or help with the tasks in details.unresolved_blockers; remove blockers listed in details.missing_blockers.",
+		Details:     []string{"unresolved_blockers", "missing_blockers"},
+	},
+	{
+		Code: "CYCLIC_DEPENDENCY", Statuses: []int{http.StatusConflict},
+		Description: "The dependencies would form a cycle, or a dependency chain is more than 100 tasks deep.",
+		Remediation: "Remove one of the blocked_by links closing the cycle, or depend on a task nearer the end of the chain.",
+	},
+	{
+		Code: "VERSION_CONFLICT", Statuses: []int{http.StatusConflict},
+		Description: "The task changed since the version sent in If-Match or expected_version.",
+		Remediation: "Re-read the task, reapply the change to details.current_version and retry.",
+		Details:     []string{"current_version"},
+	},
+	{
+		Code: "PRECONDITION_REQUIRED", Statuses: []int{http.StatusPreconditionRequired},
+		Description: "Edits and status changes must name the task version they were based on.",
+		Remediation: "Read the task, then send its version as If-Match: \"3\" or \"expected_version\": 3.",
+	},
+	{
+		Code: "HUMAN_REVIEW_HOLD", Statuses: []int{http.StatusConflict},
+		Description: "The task changed hands too often and waits for an operator.",
+		Remediation: "Don't retry; pick other work until an operator clears the hold.",
+	},
+	{
+		Code: "APPROVAL_REQUIRED", Statuses: []int{http.StatusConflict},
+		Description: "The task needs an operator's approval to complete.",
+		Remediation: "Move it to WAITING_APPROVAL with your artefact, then wait for the operator.",
+	},
+	{
+		Code: "CLAIM_TURN", Statuses: []int{http.StatusConflict},
+		Description: "The agent made the last claim while another agent is idle.",
+		Remediation: "Let the idle agent claim first and retry later.",
+	},
+	{
+		Code: "CLAIM_QUOTA_EXCEEDED", Statuses: []int{http.StatusTooManyRequests},
+		Description: "The agent claimed its quota of tasks for the workspace's window.",
+		Remediation: "Finish claimed work and retry later.",
+	},
+	{
+		Code: "NO_TASK_AVAILABLE", Statuses: []int{http.StatusNotFound},
+		Description: "claim-next found no task the agent can claim.",
+		Remediation: "Long-poll GET /tasks/wait instead of retrying in a loop.",
+	},
+	{
+		Code: "DONE_REJECTED", Statuses: []int{http.StatusUnprocessableEntity},
+		Description: "The workspace's validator rejected the completion.",
+		Remediation: "Fix what the message reports, then mark the task DONE again.",
+	},
+	{
+		Code: "DONE_VALIDATION_UNAVAILABLE", Statuses: []int{http.StatusBadGateway},
+		Description: "The workspace's validator could not be reached.",
+		Remediation: "Retry the completion later.",
+	},
+	{
+		Code: "EXTERNAL_REF_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No task awaits the external reference.",
+		Remediation: "Check the system and ID of the reference.",
+	},
+	{
+		Code: "EXPORT_REQUIRED", Statuses: []int{http.StatusConflict},
+		Description: "An archived workspace must be exported before it is deleted.",
+		Remediation: "Export the workspace, then retry the deletion.",
+	},
+
+	// Checklists
+	{
+		Code: "CHECKLIST_ITEM_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "The task has no such checklist item.",
+		Remediation: "Re-read the task's checklist.",
+	},
+	{
+		Code: "CHECKLIST_ITEM_ALREADY_CLAIMED", Statuses: []int{http.StatusConflict},
+		Description: "Another agent claimed the checklist item.",
+		Remediation: "Pick another item.",
+	},
+	{
+		Code: "CHECKLIST_ITEM_ALREADY_COMPLETED", Statuses: []int{http.StatusConflict},
+		Description: "The checklist item is already completed.",
+		Remediation: "Nothing to do; pick another item.",
+	},
+
+	// Workspace resources
+	{
+		Code: "AGENT_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such agent in the workspace.",
+		Remediation: "List agents to find the ID.",
+	},
+	{
+		Code: "WORKSPACE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such workspace.",
+		Remediation: "Check the workspace ID.",
+	},
+	{
+		Code: "WORKSPACE_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "A workspace with the slug already exists.",
+		Remediation: "Choose another slug.",
+	},
+	{
+		Code: "WORKSPACE_ARCHIVED", Statuses: []int{http.StatusConflict},
+		Description: "The workspace is archived and takes no changes.",
+		Remediation: "Ask an operator; archived workspaces take no changes.",
+	},
+	{
+		Code: "QUEUE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No queue with the name in the workspace.",
+		Remediation: "List queues to find the name.",
+	},
+	{
+		Code: "QUEUE_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "The queue name is taken.",
+		Remediation: "Choose another name.",
+	},
+	{
+		Code: "QUEUE_NOT_EMPTY", Statuses: []int{http.StatusConflict},
+		Description: "The queue still has tasks.",
+		Remediation: "Move or finish its tasks first.",
+	},
+	{
+		Code: "VIEW_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "You have no saved view with the name.",
+		Remediation: "List your views with GET /views, or save it with POST /views.",
+	},
+	{
+		Code: "VIEW_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "You already have a view with the name.",
+		Remediation: "Change it with PUT /views/{name} or choose another name.",
+	},
+	{
+		Code: "LABEL_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such label in the workspace.",
+		Remediation: "List labels to find the name.",
+	},
+	{
+		Code: "LABEL_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "The label name is taken.",
+		Remediation: "Merge into the existing label instead.",
+	},
+	{
+		Code: "UNKNOWN_LABEL", Statuses: []int{http.StatusUnprocessableEntity},
+		Description: "The label is not registered in the workspace.",
+		Remediation: "Use a registered label or register it first.",
+	},
+	{
+		Code: "SCHEDULE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such schedule in the workspace.",
+		Remediation: "List schedules to find it.",
+	},
+	{
+		Code: "SCHEDULE_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "The schedule name is taken.",
+		Remediation: "Choose another name.",
+	},
+	{
+		Code: "REPORT_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such report in the workspace.",
+		Remediation: "List reports to find it.",
+	},
+	{
+		Code: "REPORT_EXISTS", Statuses: []int{http.StatusConflict},
+		Description: "The report name is taken.",
+		Remediation: "Choose another name.",
+	},
+	{
+		Code: "COMMENT_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such comment on the task; other events can't be edited.",
+		Remediation: "List the task's events with ?type=commented to find it.",
+	},
+	{
+		Code: "COMMENT_EDIT_WINDOW_CLOSED", Statuses: []int{http.StatusConflict},
+		Description: "The comment was posted more than 15 minutes ago and can no longer be edited or redacted.",
+		Remediation: "Post a new comment correcting it instead.",
+	},
+	{
+		Code: "COMMENT_REDACTED", Statuses: []int{http.StatusConflict},
+		Description: "The comment was redacted and can't be edited or redacted again.",
+		Remediation: "Post a new comment instead.",
+	},
+	{
+		Code: "MESSAGE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such message addressed to the agent.",
+		Remediation: "List your messages to find it.",
+	},
+	{
+		Code: "OUTBOX_MESSAGE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such outbox message in the workspace.",
+		Remediation: "List the outbox to find it.",
+	},
+	{
+		Code: "INTAKE_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such intake form.",
+		Remediation: "Check the form's slug.",
+	},
+	{
+		Code: "INVALID_INTAKE_KEY", Statuses: []int{http.StatusUnauthorized},
+		Description: "The intake key is missing or wrong.",
+		Remediation: "Send the form's key in X-Sloptask-Intake-Key.",
+	},
+	{
+		Code: "RATE_LIMITED", Statuses: []int{http.StatusTooManyRequests},
+		Description: "Too many submissions in the hour.",
+		Remediation: "Retry after the Retry-After header's delay.",
+	},
+	{
+		Code: "READ_TOKEN_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "No such read token.",
+		Remediation: "List the workspace's read tokens.",
+	},
+	{
+		Code: "WEBHOOK_SECRET_NOT_FOUND", Statuses: []int{http.StatusNotFound},
+		Description: "The workspace has no webhook secret.",
+		Remediation: "Create one first.",
+	},
+	{
+		Code: "ROTATION_IN_PROGRESS", Statuses: []int{http.StatusConflict},
+		Description: "A webhook secret rotation is already in progress.",
+		Remediation: "Finish the rotation before starting another.",
+	},
+	{
+		Code: "NO_ROTATION_IN_PROGRESS", Statuses: []int{http.StatusConflict},
+		Description: "No webhook secret rotation is in progress.",
+		Remediation: "Start a rotation first.",
+	},
+
+	// Server configuration
+	{
+		Code: "INVALID_CONFIG", Statuses: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		Description: "A workspace or runtime configuration is invalid.",
+		Remediation: "Fix the configuration the message names; nothing was applied.",
+	},
+	{
+		Code: "NO_CONFIG_FILE", Statuses: []int{http.StatusConflict},
+		Description: "The server was started without --runtime-config, so there is nothing to reload.",
+		Remediation: "Restart the server with --runtime-config to enable reloads.",
+	},
+}
@@ -0,0 +1,53 @@
+package dto_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorCatalog_CoversHandlerCodes checks the catalog against the codes the
+// handlers and MapDomainError answer with, so a new code can't go unlisted.
+func TestErrorCatalog_CoversHandlerCodes(t *testing.T) {
+	// http.StatusTooManyRequests and the like, by name
+	statuses := map[string]int{}
+	nonLetters := regexp.MustCompile(`[^A-Za-z]`)
+	for status := 100; status < 600; status++ {
+		if text := http.StatusText(status); text != "" {
+			statuses["Status"+nonLetters.ReplaceAllString(text, "")] = status
+		}
+	}
+
+	catalog := map[string][]int{}
+	for _, entry := range dto.ErrorCatalog {
+		_, duplicate := catalog[entry.Code]
+		assert.False(t, duplicate, entry.Code)
+		catalog[entry.Code] = entry.Statuses
+		assert.NotEmpty(t, entry.Description, entry.Code)
+		assert.NotEmpty(t, entry.Remediation, entry.Code)
+	}
+
+	files, err := filepath.Glob("../*.go")
+	require.NoError(t, err)
+	files = append(files, "error.go")
+	used := regexp.MustCompile(`(?:respondError\(w, |return )http\.(Status\w+), "([A-Z_]+)"`)
+	found := 0
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range used.FindAllStringSubmatch(string(source), -1) {
+			found++
+			status, ok := statuses[match[1]]
+			require.True(t, ok, match[1])
+			assert.True(t, slices.Contains(catalog[match[2]], status), "%s with %d in %s is not in the catalog", match[2], status, file)
+		}
+	}
+	assert.Greater(t, found, 100)
+}
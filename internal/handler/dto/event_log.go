@@ -0,0 +1,209 @@
+package dto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// EventLogGenesisHash is the prev_hash of the first record of an event log.
+var EventLogGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// EventLogSettleTime keeps events younger than this out of event logs, so an
+// event whose transaction commits late cannot land before records already
+// exported and change their hashes, and comments are exported only once their
+// authors can no longer edit them.
+const EventLogSettleTime = domain.CommentEditWindow
+
+// maxEventLogLine bounds one line of an event log read by VerifyEventLog; event
+// data is limited to domain.MaxEventDataBytes, comments to far less.
+const maxEventLogLine = 4 * domain.MaxEventDataBytes
+
+// EventLogRecord is one line of an event log. Hash is the hex SHA-256 of
+// PrevHash followed by Event exactly as written, so changing, removing or
+// reordering any record breaks the chain from there on.
+type EventLogRecord struct {
+	Seq      int             `json:"seq"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+	Event    json.RawMessage `json:"event" swaggertype:"object"`
+}
+
+// EventLogSummary describes an event log checked by VerifyEventLog.
+type EventLogSummary struct {
+	Records  int
+	FirstSeq int
+	LastSeq  int
+	Head     string // hash of the last record, or the genesis hash when empty
+}
+
+// eventLogHash chains one encoded event to the hash before it.
+func eventLogHash(prevHash string, event []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(prevHash))
+	sum.Write(event)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// encodeEventLogEvent encodes an event for the log. Times are written in UTC, so
+// the hashes do not depend on the time zone of the exporting process.
+func encodeEventLogEvent(event *domain.TaskEvent) ([]byte, error) {
+	response := ToTaskEventResponse(event)
+	response.CreatedAt = response.CreatedAt.UTC()
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("encode event %s: %w", event.ID, err)
+	}
+	return encoded, nil
+}
+
+// eventLogStart returns the seq and hash a log continues from: the checkpoint
+// recorded when older events were pruned, or the genesis hash.
+func eventLogStart(checkpoint *domain.EventLogCheckpoint) (int, string) {
+	if checkpoint == nil {
+		return 0, EventLogGenesisHash
+	}
+	return checkpoint.Seq, checkpoint.Hash
+}
+
+// WriteEventLog writes events as hash-chained NDJSON records numbered from 1, or
+// from the checkpoint of pruned events, which events must follow. The chain
+// always starts at the first event; only records after seq after are written,
+// so a log can be extended by appending a later export with ?after= set to its
+// last seq. Returns the number of records written and the head hash.
+func WriteEventLog(w io.Writer, events []*domain.TaskEvent, checkpoint *domain.EventLogCheckpoint, after int) (int, string, error) {
+	enc := json.NewEncoder(w)
+	startSeq, prevHash := eventLogStart(checkpoint)
+	written := 0
+	for i, event := range events {
+		encoded, err := encodeEventLogEvent(event)
+		if err != nil {
+			return written, prevHash, err
+		}
+
+		record := EventLogRecord{
+			Seq:      startSeq + i + 1,
+			PrevHash: prevHash,
+			Hash:     eventLogHash(prevHash, encoded),
+			Event:    encoded,
+		}
+		prevHash = record.Hash
+		if record.Seq <= after {
+			continue
+		}
+
+		if err := enc.Encode(record); err != nil {
+			return written, prevHash, fmt.Errorf("write record %d: %w", record.Seq, err)
+		}
+		written++
+	}
+
+	return written, prevHash, nil
+}
+
+// EventLogHead returns the hash of record seq of the log WriteEventLog writes for
+// events after checkpoint, or the genesis hash for seq 0. Records the checkpoint
+// covers are gone except for its last one.
+func EventLogHead(events []*domain.TaskEvent, checkpoint *domain.EventLogCheckpoint, seq int) (string, error) {
+	startSeq, head := eventLogStart(checkpoint)
+	switch {
+	case seq < startSeq:
+		return "", fmt.Errorf("records up to seq %d were pruned", startSeq)
+	case seq > startSeq+len(events):
+		return "", fmt.Errorf("log has %d records, not %d", startSeq+len(events), seq)
+	}
+
+	for _, event := range events[:seq-startSeq] {
+		encoded, err := encodeEventLogEvent(event)
+		if err != nil {
+			return "", err
+		}
+		head = eventLogHash(head, encoded)
+	}
+
+	return head, nil
+}
+
+// NextEventLogCheckpoint extends checkpoint, nil for none, over events, the
+// events of the workspace recorded since it and before before.
+func NextEventLogCheckpoint(workspaceID string, events []*domain.TaskEvent, checkpoint *domain.EventLogCheckpoint, before time.Time) (*domain.EventLogCheckpoint, error) {
+	startSeq, _ := eventLogStart(checkpoint)
+	head, err := EventLogHead(events, checkpoint, startSeq+len(events))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.EventLogCheckpoint{
+		WorkspaceID: workspaceID,
+		Seq:         startSeq + len(events),
+		Hash:        head,
+		Before:      before,
+	}, nil
+}
+
+// VerifyEventLog reads an event log and checks that its records are numbered
+// without gaps and that every hash matches. A log starting at seq 1 must start
+// from the genesis hash; a log written with ?after= or after a checkpoint is
+// checked from the prev_hash of its first record, which the caller compares
+// with the head of the log it extends.
+func VerifyEventLog(r io.Reader) (*EventLogSummary, error) {
+	summary := &EventLogSummary{Head: EventLogGenesisHash}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLogLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var record EventLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return summary, fmt.Errorf("line %d: %w", line, err)
+		}
+		var event bytes.Buffer
+		if err := json.Compact(&event, record.Event); err != nil {
+			return summary, fmt.Errorf("line %d: event: %w", line, err)
+		}
+
+		switch {
+		case summary.Records == 0 && record.Seq < 1:
+			return summary, fmt.Errorf("line %d: seq %d is not positive", line, record.Seq)
+		case summary.Records == 0 && record.Seq == 1 && record.PrevHash != EventLogGenesisHash:
+			return summary, fmt.Errorf("line %d: first record does not start from the genesis hash", line)
+		case summary.Records > 0 && record.Seq != summary.LastSeq+1:
+			return summary, fmt.Errorf("line %d: seq %d follows seq %d", line, record.Seq, summary.LastSeq)
+		case summary.Records > 0 && record.PrevHash != summary.Head:
+			return summary, fmt.Errorf("line %d: seq %d: prev_hash does not match the previous record", line, record.Seq)
+		}
+		if hash := eventLogHash(record.PrevHash, event.Bytes()); hash != record.Hash {
+			return summary, fmt.Errorf("line %d: seq %d: hash mismatch, event was changed", line, record.Seq)
+		}
+
+		if summary.Records == 0 {
+			summary.FirstSeq = record.Seq
+		}
+		summary.Records++
+		summary.LastSeq = record.Seq
+		summary.Head = record.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return summary, fmt.Errorf("line %d: longer than %d bytes", line+1, maxEventLogLine)
+		}
+		return summary, fmt.Errorf("read event log: %w", err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,64 @@
+package dto_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLog_ContinuesFromCheckpoint(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := make([]*domain.TaskEvent, 5)
+	for i := range events {
+		events[i] = &domain.TaskEvent{
+			ID:        fmt.Sprintf("event-%d", i),
+			TaskID:    "task",
+			Type:      domain.EventTypeCommented,
+			Comment:   fmt.Sprintf("comment %d", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		}
+	}
+
+	var stored bytes.Buffer
+	_, _, err := dto.WriteEventLog(&stored, events[:3], nil, 0)
+	require.NoError(t, err)
+
+	// The first three events are checkpointed, then pruned
+	checkpoint, err := dto.NextEventLogCheckpoint("workspace", events[:3], nil, events[3].CreatedAt)
+	require.NoError(t, err)
+	assert.Equal(t, 3, checkpoint.Seq)
+	summary, err := dto.VerifyEventLog(bytes.NewReader(stored.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, summary.Head, checkpoint.Hash)
+
+	head, err := dto.EventLogHead(events[3:], checkpoint, 3)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint.Hash, head)
+	_, err = dto.EventLogHead(events[3:], checkpoint, 2)
+	assert.ErrorContains(t, err, "pruned")
+
+	// The stored log is extended as if nothing had been pruned
+	var full bytes.Buffer
+	_, wantHead, err := dto.WriteEventLog(&full, events, nil, 0)
+	require.NoError(t, err)
+	written, gotHead, err := dto.WriteEventLog(&stored, events[3:], checkpoint, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+	assert.Equal(t, wantHead, gotHead)
+	assert.Equal(t, full.String(), stored.String())
+
+	// A new log starts at the checkpoint
+	var fresh bytes.Buffer
+	_, _, err = dto.WriteEventLog(&fresh, events[3:], checkpoint, 0)
+	require.NoError(t, err)
+	summary, err = dto.VerifyEventLog(&fresh)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.FirstSeq)
+	assert.Equal(t, wantHead, summary.Head)
+}
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Export formats accepted by the export endpoint and command.
+const (
+	ExportFormatJSON   = "json"   // one WorkspaceExportResponse document (default)
+	ExportFormatNDJSON = "ndjson" // one ExportRecord per line, streamable
+)
+
+// Record types of an NDJSON export, in the order they are written.
+const (
+	ExportRecordManifest  = "manifest"
+	ExportRecordWorkspace = "workspace"
+	ExportRecordAgent     = "agent"
+	ExportRecordQueue     = "queue"
+	ExportRecordLabel     = "label"
+	ExportRecordTask      = "task"
+	ExportRecordEvent     = "event"
+)
+
+// ExportRecord is one line of an NDJSON export. Data has the same shape as the
+// matching entry of the JSON export.
+type ExportRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// WriteExport writes an export in the given format. Records of an NDJSON export
+// keep the JSON export's order, so importers always see the manifest, workspace
+// and agents before the tasks and events referencing them.
+func WriteExport(w io.Writer, export WorkspaceExportResponse, format string) error {
+	switch format {
+	case ExportFormatJSON:
+		if err := json.NewEncoder(w).Encode(export); err != nil {
+			return fmt.Errorf("encode export: %w", err)
+		}
+		return nil
+	case ExportFormatNDJSON:
+		return writeExportNDJSON(w, export)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// writeExportNDJSON writes an export as one JSON record per line.
+func writeExportNDJSON(w io.Writer, export WorkspaceExportResponse) error {
+	enc := json.NewEncoder(w)
+	write := func(recordType string, data any) error {
+		if err := enc.Encode(ExportRecord{Type: recordType, Data: data}); err != nil {
+			return fmt.Errorf("encode %s record: %w", recordType, err)
+		}
+		return nil
+	}
+
+	if err := write(ExportRecordManifest, export.Manifest); err != nil {
+		return err
+	}
+	if err := write(ExportRecordWorkspace, export.Workspace); err != nil {
+		return err
+	}
+	for _, agent := range export.Agents {
+		if err := write(ExportRecordAgent, agent); err != nil {
+			return err
+		}
+	}
+	for _, queue := range export.Queues {
+		if err := write(ExportRecordQueue, queue); err != nil {
+			return err
+		}
+	}
+	for _, label := range export.Labels {
+		if err := write(ExportRecordLabel, label); err != nil {
+			return err
+		}
+	}
+	for _, task := range export.Tasks {
+		if err := write(ExportRecordTask, task); err != nil {
+			return err
+		}
+	}
+	for _, event := range export.Events {
+		if err := write(ExportRecordEvent, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
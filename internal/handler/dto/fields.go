@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// taskListFields are the fields of TaskListResponse by JSON name, the names
+// ?fields= on GET /tasks accepts.
+var taskListFields = jsonFieldNames(reflect.TypeOf(TaskListResponse{}))
+
+// jsonFieldNames returns the JSON names of the fields of struct type t.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// ValidateTaskListFields checks that fields only names fields of
+// TaskListResponse. The error lists the valid names.
+func ValidateTaskListFields(fields []string) error {
+	for _, field := range fields {
+		if !taskListFields[field] {
+			valid := make([]string, 0, len(taskListFields))
+			for name := range taskListFields {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			return fmt.Errorf("unknown field %q, valid fields: %s", field, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// SelectFields encodes v, which must encode as a JSON object, keeping only
+// the given fields. Fields v leaves out, such as empty omitempty ones, stay
+// out.
+func SelectFields(v any, fields []string) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode %T: %w", v, err)
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, fmt.Errorf("decode %T as an object: %w", v, err)
+	}
+
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,27 @@
+package dto_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectFields(t *testing.T) {
+	task := dto.TaskListResponse{ID: "42", Title: "Ship it", Status: "NEW", Priority: "high"}
+
+	require.NoError(t, dto.ValidateTaskListFields([]string{"id", "title", "status", "created_at_relative"}))
+	err := dto.ValidateTaskListFields([]string{"id", "description"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"description"`)
+	assert.Contains(t, err.Error(), "priority")
+
+	selected, err := dto.SelectFields(task, []string{"id", "title", "created_at_relative"})
+	require.NoError(t, err)
+	encoded, err := json.Marshal(selected)
+	require.NoError(t, err)
+	// Empty omitempty fields stay out
+	assert.JSONEq(t, `{"id": "42", "title": "Ship it"}`, string(encoded))
+}
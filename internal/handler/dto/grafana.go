@@ -0,0 +1,96 @@
+package dto
+
+import "time"
+
+// Grafana table names served by POST /grafana/query next to the event series.
+const (
+	GrafanaTableAgentStats    = "agent_stats"
+	GrafanaTableTasksByStatus = "tasks_by_status"
+	GrafanaTableQueueDepth    = "queue_depth"
+)
+
+// Grafana variable names served by POST /grafana/variable.
+const (
+	GrafanaVariableAgents   = "agents"
+	GrafanaVariableQueues   = "queues"
+	GrafanaVariableLabels   = "labels"
+	GrafanaVariableStatuses = "statuses"
+)
+
+// GrafanaRange is the dashboard time range sent with every query.
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaPayload narrows a query. Empty fields (e.g. an "All" variable) do not filter.
+type GrafanaPayload struct {
+	AgentID string `json:"agent_id,omitempty"`
+	Queue   string `json:"queue,omitempty"`
+	Label   string `json:"label,omitempty"`
+}
+
+// GrafanaTarget is one query of a panel.
+type GrafanaTarget struct {
+	RefID   string         `json:"refId"`
+	Target  string         `json:"target"`
+	Payload GrafanaPayload `json:"payload"`
+}
+
+// GrafanaQueryRequest is the body Grafana sends to POST /grafana/query.
+type GrafanaQueryRequest struct {
+	Range         *GrafanaRange   `json:"range"`
+	IntervalMs    int64           `json:"intervalMs"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+	Targets       []GrafanaTarget `json:"targets"`
+}
+
+// GrafanaTimeseries is a series of [value, unix milliseconds] pairs.
+type GrafanaTimeseries struct {
+	Target     string     `json:"target"`
+	RefID      string     `json:"refId,omitempty"`
+	Datapoints [][2]int64 `json:"datapoints"`
+}
+
+// GrafanaColumn describes a table column; Type is "string", "number" or "time".
+type GrafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaTable is a table query result.
+type GrafanaTable struct {
+	Type    string          `json:"type"` // always "table"
+	RefID   string          `json:"refId,omitempty"`
+	Columns []GrafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+// GrafanaMetricPayload describes a payload field the query editor offers.
+type GrafanaMetricPayload struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"` // "input"
+}
+
+// GrafanaMetric is one entry of POST /grafana/metrics.
+type GrafanaMetric struct {
+	Label    string                 `json:"label"`
+	Value    string                 `json:"value"`
+	Payloads []GrafanaMetricPayload `json:"payloads"`
+}
+
+// GrafanaVariableRequest is the body of POST /grafana/variable. Newer plugin
+// versions send the variable name in payload.target, older ones in target.
+type GrafanaVariableRequest struct {
+	Target  string `json:"target"`
+	Payload struct {
+		Target string `json:"target"`
+	} `json:"payload"`
+}
+
+// GrafanaVariableValue is one option of a dashboard variable.
+type GrafanaVariableValue struct {
+	Text  string `json:"__text"`
+	Value string `json:"__value"`
+}
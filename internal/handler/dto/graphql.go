@@ -0,0 +1,23 @@
+package dto
+
+// GraphQLRequest represents the request body for POST /graphql.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	// Extensions is accepted for clients that always send it, and ignored.
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse documents the GraphQL response: the data asked for and the
+// errors of fields that failed, which come with 200 OK like the data.
+type GraphQLResponse struct {
+	Data   map[string]any `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError is an error of a GraphQL query or of one of its fields.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
@@ -1,20 +1,35 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
 // CreateTaskRequest represents the request body for POST /tasks.
 type CreateTaskRequest struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	AssigneeID  *string  `json:"assignee_id,omitempty"`
-	Visibility  string   `json:"visibility,omitempty"`
-	Priority    string   `json:"priority,omitempty"`
-	BlockedBy   []string `json:"blocked_by,omitempty"`
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	AssigneeID           *string  `json:"assignee_id,omitempty"`
+	Visibility           string   `json:"visibility,omitempty"`
+	Priority             string   `json:"priority,omitempty"`
+	BlockedBy            []string `json:"blocked_by,omitempty"`
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+	Queue                *string  `json:"queue,omitempty"`
+	Labels               []string `json:"labels,omitempty"`
+	RequiresApproval     bool     `json:"requires_approval,omitempty"`
 }
 
 // TransitionStatusRequest represents the request body for PATCH /tasks/:id/status.
 type TransitionStatusRequest struct {
-	Status   string `json:"status"`
-	Comment  string `json:"comment"`
-	Artefact string `json:"artefact,omitempty"`
+	Status   string         `json:"status"`
+	Comment  string         `json:"comment"`
+	Artefact string         `json:"artefact,omitempty"`
+	Result   map[string]any `json:"result,omitempty"`
+	Data     map[string]any `json:"data,omitempty"`
+	// ExpectedVersion rejects the transition with 409 VERSION_CONFLICT if the
+	// task changed since it was read. Required unless If-Match is sent.
+	ExpectedVersion *int `json:"expected_version,omitempty"`
 }
 
 // ClaimTaskRequest represents the request body for POST /tasks/:id/claim.
@@ -22,18 +37,164 @@ type ClaimTaskRequest struct {
 	Comment string `json:"comment"`
 }
 
+// ClaimNextRequest represents the request body for POST /tasks/claim-next.
+type ClaimNextRequest struct {
+	Queue   *string `json:"queue,omitempty"`
+	Comment string  `json:"comment"`
+}
+
+// CreateQueueRequest represents the request body for POST /queues.
+type CreateQueueRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateQueueRequest represents the request body for PATCH /queues/:name.
+type UpdateQueueRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// CreateViewRequest represents the request body for POST /views.
+type CreateViewRequest struct {
+	Name   string            `json:"name"`
+	Params map[string]string `json:"params"` // GET /tasks query parameters, e.g. {"status": "NEW", "sort": "-priority"}
+}
+
+// UpdateViewRequest represents the request body for PUT /views/:name.
+type UpdateViewRequest struct {
+	Params map[string]string `json:"params"`
+}
+
+// PutLabelRequest represents the request body for PUT /labels/:name.
+// Omitted fields keep their current value (or the default for a new label).
+type PutLabelRequest struct {
+	Color       *string `json:"color,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// UpdateLabelRequest represents the request body for PATCH /labels/:name.
+type UpdateLabelRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Color       *string `json:"color,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// MergeLabelRequest represents the request body for POST /labels/:name/merge.
+type MergeLabelRequest struct {
+	Into string `json:"into"`
+}
+
+// SetTaskLabelsRequest represents the request body for PUT /tasks/:id/labels.
+type SetTaskLabelsRequest struct {
+	Labels  []string `json:"labels"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+// AwaitExternalRequest represents the request body for POST /tasks/:id/await-external.
+type AwaitExternalRequest struct {
+	System     string  `json:"system"`
+	ExternalID string  `json:"external_id"`
+	URL        *string `json:"url,omitempty"`
+	Comment    string  `json:"comment"`
+}
+
+// ResolveExternalRequest represents the request body for POST /admin/workspaces/:workspace_id/external/resolve.
+type ResolveExternalRequest struct {
+	System     string         `json:"system"`
+	ExternalID string         `json:"external_id"`
+	Comment    string         `json:"comment"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
 // EscalateTaskRequest represents the request body for POST /tasks/:id/escalate.
 type EscalateTaskRequest struct {
 	Comment string `json:"comment"`
 }
 
+// SendMessageRequest represents the request body for POST /tasks/:id/messages.
+type SendMessageRequest struct {
+	RecipientID string `json:"recipient_id"`
+	Body        string `json:"body"`
+}
+
 // TakeoverTaskRequest represents the request body for POST /tasks/:id/takeover.
 type TakeoverTaskRequest struct {
 	Comment string `json:"comment"`
 }
 
+// ReopenTaskRequest represents the request body for POST /tasks/:id/reopen.
+type ReopenTaskRequest struct {
+	Status  string `json:"status,omitempty"` // NEW (default) or IN_PROGRESS
+	Comment string `json:"comment"`
+}
+
+// EditTaskRequest represents the request body for PATCH /tasks/:id.
+type EditTaskRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Comment     string  `json:"comment,omitempty"`
+	// ExpectedVersion rejects the edit with 409 VERSION_CONFLICT if the task
+	// changed since it was read. Required unless If-Match is sent.
+	ExpectedVersion *int `json:"expected_version,omitempty"`
+}
+
+// ArchiveTaskRequest represents the optional request body for POST /tasks/:id/archive.
+type ArchiveTaskRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// DeleteTaskRequest represents the optional request body for DELETE /tasks/:id.
+type DeleteTaskRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// TransferTaskRequest represents the request body for POST /admin/workspaces/:workspace_id/tasks/:id/transfer.
+type TransferTaskRequest struct {
+	TargetWorkspaceID string  `json:"target_workspace_id"`
+	CreatorID         string  `json:"creator_id"`           // agent of the target workspace
+	Visibility        *string `json:"visibility,omitempty"` // defaults to the task's visibility
+	Comment           string  `json:"comment,omitempty"`
+}
+
+// ClearReviewRequest represents the optional request body for
+// POST /admin/workspaces/:workspace_id/tasks/:id/clear-review.
+type ClearReviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// ApproveTaskRequest represents the optional request body for
+// POST /admin/workspaces/:workspace_id/tasks/:id/approve.
+type ApproveTaskRequest struct {
+	Status  string `json:"status,omitempty"` // DONE (default) or IN_PROGRESS
+	Comment string `json:"comment,omitempty"`
+}
+
+// RejectTaskRequest represents the request body for
+// POST /admin/workspaces/:workspace_id/tasks/:id/reject.
+type RejectTaskRequest struct {
+	Comment string `json:"comment"`
+}
+
 // CommentTaskRequest represents the request body for POST /tasks/:id/comments.
 type CommentTaskRequest struct {
+	Comment        string         `json:"comment"`
+	Data           map[string]any `json:"data,omitempty"`
+	ReplyToEventID *string        `json:"reply_to_event_id,omitempty"` // comment of the same task to reply to
+}
+
+// EditCommentRequest represents the request body for PATCH /tasks/:id/comments/:event_id.
+type EditCommentRequest struct {
+	Comment string `json:"comment"`
+}
+
+// AddChecklistItemRequest represents the request body for POST /tasks/:id/checklist.
+type AddChecklistItemRequest struct {
+	Title string `json:"title"`
+}
+
+// ChecklistItemActionRequest represents the request body for claiming or completing a checklist item.
+type ChecklistItemActionRequest struct {
 	Comment string `json:"comment"`
 }
 
@@ -56,3 +217,222 @@ type StatsFilters struct {
 	Period  string  // day, week, month, all
 	AgentID *string // Filter by specific agent
 }
+
+// SetAgentCapabilitiesRequest represents the request body for PUT /admin/agents/:id/capabilities.
+type SetAgentCapabilitiesRequest struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+// SetAutoAssignStrategyRequest represents the request body for PUT /admin/workspaces/:workspace_id/auto-assign.
+type SetAutoAssignStrategyRequest struct {
+	Strategy string `json:"strategy"`
+}
+
+// SetPriorityInheritanceRequest represents the request body for PUT /admin/workspaces/:workspace_id/priority-inheritance.
+type SetPriorityInheritanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetAutoUnblockRequest represents the request body for PUT /admin/workspaces/:workspace_id/auto-unblock.
+type SetAutoUnblockRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetReadOnlyRequest represents the request body for PUT /admin/read-only.
+type SetReadOnlyRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetAgentStaleAfterRequest represents the request body for PUT /admin/workspaces/:workspace_id/agent-staleness.
+type SetAgentStaleAfterRequest struct {
+	StaleAfterSeconds int `json:"stale_after_seconds"`
+}
+
+// SetDoneValidationRequest represents the request body for PUT /admin/workspaces/:workspace_id/done-validation.
+type SetDoneValidationRequest struct {
+	URL            string `json:"url"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // default 5, at most 30
+	// FailOpen lets moves to DONE through when the webhook cannot be reached
+	FailOpen bool `json:"fail_open,omitempty"`
+}
+
+// SetEventWebhookRequest represents the request body for PUT /admin/workspaces/:workspace_id/event-webhook.
+type SetEventWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types,omitempty"` // published event types; all when empty
+}
+
+// SetEventBrokerRequest represents the request body for PUT /admin/workspaces/:workspace_id/event-broker.
+type SetEventBrokerRequest struct {
+	Topic      string   `json:"topic"`                 // NATS subject or Kafka topic
+	EventTypes []string `json:"event_types,omitempty"` // published event types; all when empty
+}
+
+// SetPriorityAgingRequest represents the request body for PUT /admin/workspaces/:workspace_id/priority-aging.
+type SetPriorityAgingRequest struct {
+	AfterSeconds int    `json:"after_seconds"`    // at least 60, at most 30 days
+	Action       string `json:"action,omitempty"` // bump (default) or warn
+}
+
+// SetClaimFairnessRequest represents the request body for PUT /admin/workspaces/:workspace_id/claim-fairness.
+type SetClaimFairnessRequest struct {
+	Quota         int  `json:"quota,omitempty"`          // claims per agent and window; 0 for none
+	WindowSeconds int  `json:"window_seconds,omitempty"` // default 3600
+	TakeTurns     bool `json:"take_turns,omitempty"`
+}
+
+// SetDeadlineWarningRequest represents the request body for PUT /admin/workspaces/:workspace_id/deadline-warning.
+type SetDeadlineWarningRequest struct {
+	Percent int `json:"percent"` // share of the status deadline left, 1-99
+}
+
+// SetDeadlineExpiryRequest represents the request body for PUT /admin/workspaces/:workspace_id/deadline-expiry.
+type SetDeadlineExpiryRequest struct {
+	Expiry map[string]string `json:"expiry"` // status -> status entered when its deadline expires; omitted statuses go STUCK
+}
+
+// SetMaxAttemptsRequest represents the request body for PUT /admin/workspaces/:workspace_id/max-attempts.
+type SetMaxAttemptsRequest struct {
+	MaxAttempts int `json:"max_attempts"` // 1-100
+}
+
+// SetIntakeFormRequest represents the request body for PUT /admin/workspaces/:workspace_id/intake.
+type SetIntakeFormRequest struct {
+	AgentID          string `json:"agent_id"`                      // recorded as the creator of submitted tasks
+	RateLimitPerHour int    `json:"rate_limit_per_hour,omitempty"` // default 10, at most 1000
+	RotateKey        bool   `json:"rotate_key,omitempty"`          // issue a new key, invalidating the old one
+}
+
+// IntakeSubmissionRequest represents the request body for POST /intake/:workspace.
+// HTML forms may post the same fields form-encoded.
+type IntakeSubmissionRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Contact     string `json:"contact,omitempty"`
+	// Key may be sent here instead of the X-Sloptask-Intake-Key header
+	Key string `json:"key,omitempty"`
+}
+
+// EscalationRouteRequest is one route of SetEscalationRoutesRequest.
+type EscalationRouteRequest struct {
+	Name       string  `json:"name"`
+	Label      *string `json:"label,omitempty"`      // match tasks carrying this label
+	Priority   *string `json:"priority,omitempty"`   // match tasks of this priority
+	CreatorID  *string `json:"creator_id,omitempty"` // match tasks created by this agent
+	TargetType string  `json:"target_type"`          // agent, webhook or email
+	Target     string  `json:"target"`               // agent ID, URL or comma-separated addresses
+}
+
+// SetEscalationRoutesRequest represents the request body for PUT /admin/workspaces/:workspace_id/escalation-routes.
+// Routes are evaluated in the given order.
+type SetEscalationRoutesRequest struct {
+	Routes []EscalationRouteRequest `json:"routes"`
+}
+
+// ToDomain converts the request to an escalation route.
+func (r EscalationRouteRequest) ToDomain() *domain.EscalationRoute {
+	route := &domain.EscalationRoute{
+		Name:       r.Name,
+		Label:      r.Label,
+		CreatorID:  r.CreatorID,
+		TargetType: domain.EscalationTargetType(r.TargetType),
+		Target:     r.Target,
+	}
+	if r.Priority != nil {
+		priority := domain.TaskPriority(*r.Priority)
+		route.Priority = &priority
+	}
+	return route
+}
+
+// ArchiveWorkspaceRequest represents the optional request body for POST /admin/workspaces/:workspace_id/archive.
+type ArchiveWorkspaceRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CreateSandboxRequest represents the optional request body for POST /admin/workspaces/:workspace_id/sandbox.
+type CreateSandboxRequest struct {
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+	// TTL is a Go duration such as "72h"; default 72h, at most 720h
+	TTL string `json:"ttl,omitempty"`
+}
+
+// RotateWebhookSecretRequest represents the optional request body for POST /admin/workspaces/:workspace_id/webhook-secret/rotate.
+type RotateWebhookSecretRequest struct {
+	// OverlapMinutes is how long the previous secret keeps signing; default 1440, 0 replaces it at once
+	OverlapMinutes *int `json:"overlap_minutes,omitempty"`
+}
+
+// CreateReadTokenRequest represents the request body for POST /admin/workspaces/:workspace_id/read-tokens.
+type CreateReadTokenRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ScheduleTaskTemplate describes the tasks a schedule creates.
+type ScheduleTaskTemplate struct {
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	AssigneeID           *string  `json:"assignee_id,omitempty"`
+	Visibility           string   `json:"visibility,omitempty"`
+	Priority             string   `json:"priority,omitempty"`
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+	Queue                *string  `json:"queue,omitempty"`
+}
+
+// ToDomain converts the template to domain.TaskTemplate.
+func (t ScheduleTaskTemplate) ToDomain() domain.TaskTemplate {
+	return domain.TaskTemplate{
+		Title:                t.Title,
+		Description:          t.Description,
+		Priority:             domain.TaskPriority(t.Priority),
+		Visibility:           domain.TaskVisibility(t.Visibility),
+		AssigneeID:           t.AssigneeID,
+		RequiredCapabilities: t.RequiredCapabilities,
+		Queue:                t.Queue,
+	}
+}
+
+// CreateScheduleRequest represents the request body for POST /schedules.
+type CreateScheduleRequest struct {
+	Name     string               `json:"name"`
+	Cron     string               `json:"cron"`
+	Timezone string               `json:"timezone,omitempty"` // IANA name, defaults to UTC
+	Task     ScheduleTaskTemplate `json:"task"`
+}
+
+// UpdateScheduleRequest represents the request body for PATCH /schedules/:id.
+// A task template, when given, replaces the whole template.
+type UpdateScheduleRequest struct {
+	Name     *string               `json:"name,omitempty"`
+	Cron     *string               `json:"cron,omitempty"`
+	Timezone *string               `json:"timezone,omitempty"`
+	Enabled  *bool                 `json:"enabled,omitempty"`
+	Task     *ScheduleTaskTemplate `json:"task,omitempty"`
+}
+
+// CreateReportRequest represents the request body for POST /reports.
+type CreateReportRequest struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`             // workspace_summary or agent_performance
+	Format     string `json:"format,omitempty"` // json or markdown (default)
+	Period     string `json:"period,omitempty"` // day, week or month; defaults by kind
+	Cron       string `json:"cron"`
+	Timezone   string `json:"timezone,omitempty"` // IANA name, defaults to UTC
+	TargetType string `json:"target_type"`        // webhook or email
+	Target     string `json:"target"`             // URL or comma-separated addresses
+}
+
+// UpdateReportRequest represents the request body for PATCH /reports/:id.
+type UpdateReportRequest struct {
+	Name       *string `json:"name,omitempty"`
+	Kind       *string `json:"kind,omitempty"`
+	Format     *string `json:"format,omitempty"`
+	Period     *string `json:"period,omitempty"`
+	Cron       *string `json:"cron,omitempty"`
+	Timezone   *string `json:"timezone,omitempty"`
+	TargetType *string `json:"target_type,omitempty"`
+	Target     *string `json:"target,omitempty"`
+	Enabled    *bool   `json:"enabled,omitempty"`
+}
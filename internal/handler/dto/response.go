@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/mtlprog/sloptask/internal/domain"
@@ -8,20 +9,40 @@ import (
 
 // TaskListResponse represents a task in the list view (without description and events).
 type TaskListResponse struct {
-	ID                    string     `json:"id"`
-	Title                 string     `json:"title"`
-	Status                string     `json:"status"`
-	Priority              string     `json:"priority"`
-	Visibility            string     `json:"visibility"`
-	CreatorID             string     `json:"creator_id"`
-	AssigneeID            *string    `json:"assignee_id"`
-	BlockedBy             []string   `json:"blocked_by"`
-	HasUnresolvedBlockers bool       `json:"has_unresolved_blockers"`
-	IsOverdue             bool       `json:"is_overdue"`
-	StatusDeadlineAt      *time.Time `json:"status_deadline_at"`
-	Artefact              *string    `json:"artefact"`
-	CreatedAt             time.Time  `json:"created_at"`
-	UpdatedAt             time.Time  `json:"updated_at"`
+	ID                    string           `json:"id"`
+	Number                int              `json:"number"` // sequential within the workspace
+	Key                   string           `json:"key"`    // e.g. TST-142; accepted wherever a task ID is
+	Title                 string           `json:"title"`
+	Status                string           `json:"status"`
+	Priority              string           `json:"priority"`
+	InheritedPriority     *string          `json:"inherited_priority"`
+	EffectivePriority     string           `json:"effective_priority"`
+	Visibility            string           `json:"visibility"`
+	CreatorID             string           `json:"creator_id"`
+	AssigneeID            *string          `json:"assignee_id"`
+	BlockedBy             []string         `json:"blocked_by"`
+	RequiredCapabilities  []string         `json:"required_capabilities"`
+	Queue                 *string          `json:"queue"`
+	Labels                []string         `json:"labels"`
+	ExternalRef           *ExternalRefInfo `json:"external_ref"`
+	HasUnresolvedBlockers bool             `json:"has_unresolved_blockers"`
+	IsOverdue             bool             `json:"is_overdue"`
+	RequiresApproval      bool             `json:"requires_approval"`
+	Attempts              int              `json:"attempts"`        // times taken over or returned to NEW
+	Version               int              `json:"version"`         // send as If-Match to edit or transition
+	HumanReviewAt         *time.Time       `json:"human_review_at"` // set while held for human review
+	StatusDeadlineAt      *time.Time       `json:"status_deadline_at"`
+	DeadlineInSeconds     *int64           `json:"deadline_in_seconds"`
+	Artefact              *string          `json:"artefact"`
+	ArchivedAt            *time.Time       `json:"archived_at"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+	ServerTime            time.Time        `json:"server_time"`
+
+	// Set with ?time_format=relative
+	CreatedAtRelative      string  `json:"created_at_relative,omitempty"`
+	UpdatedAtRelative      string  `json:"updated_at_relative,omitempty"`
+	StatusDeadlineRelative *string `json:"status_deadline_relative,omitempty"`
 }
 
 // TasksListResponse represents the response for GET /tasks.
@@ -32,6 +53,26 @@ type TasksListResponse struct {
 	Offset int                `json:"offset"`
 }
 
+// PartialTasksListResponse is TasksListResponse with each task trimmed to
+// the fields requested with ?fields=.
+type PartialTasksListResponse struct {
+	Tasks  []map[string]json.RawMessage `json:"tasks"`
+	Total  int                          `json:"total"`
+	Limit  int                          `json:"limit"`
+	Offset int                          `json:"offset"`
+}
+
+// TaskFacetsResponse represents the response for GET /tasks/facets: the
+// number of tasks matching the filters, by facet value.
+type TaskFacetsResponse struct {
+	Total      int            `json:"total"`
+	Status     map[string]int `json:"status"`
+	Priority   map[string]int `json:"priority"`
+	Assignee   map[string]int `json:"assignee"` // by agent ID
+	Unassigned int            `json:"unassigned"`
+	Label      map[string]int `json:"label"` // a task counts once per label
+}
+
 // TaskDetailResponse represents full task details with events.
 type TaskDetailResponse struct {
 	Task   TaskDetail      `json:"task"`
@@ -40,45 +81,151 @@ type TaskDetailResponse struct {
 
 // TaskDetail represents the full task object.
 type TaskDetail struct {
-	ID                    string     `json:"id"`
-	Title                 string     `json:"title"`
-	Description           string     `json:"description"`
-	Status                string     `json:"status"`
-	Priority              string     `json:"priority"`
-	Visibility            string     `json:"visibility"`
-	CreatorID             string     `json:"creator_id"`
-	AssigneeID            *string    `json:"assignee_id"`
-	BlockedBy             []string   `json:"blocked_by"`
-	HasUnresolvedBlockers bool       `json:"has_unresolved_blockers"`
-	IsOverdue             bool       `json:"is_overdue"`
-	StatusDeadlineAt      *time.Time `json:"status_deadline_at"`
-	Artefact              *string    `json:"artefact"`
-	CreatedAt             time.Time  `json:"created_at"`
-	UpdatedAt             time.Time  `json:"updated_at"`
+	ID                    string              `json:"id"`
+	Number                int                 `json:"number"` // sequential within the workspace
+	Key                   string              `json:"key"`    // e.g. TST-142; accepted wherever a task ID is
+	Title                 string              `json:"title"`
+	Description           string              `json:"description"`
+	Status                string              `json:"status"`
+	Priority              string              `json:"priority"`
+	InheritedPriority     *string             `json:"inherited_priority"`
+	EffectivePriority     string              `json:"effective_priority"`
+	Visibility            string              `json:"visibility"`
+	CreatorID             string              `json:"creator_id"`
+	AssigneeID            *string             `json:"assignee_id"`
+	BlockedBy             []string            `json:"blocked_by"`
+	RequiredCapabilities  []string            `json:"required_capabilities"`
+	Queue                 *string             `json:"queue"`
+	Labels                []string            `json:"labels"`
+	ExternalRef           *ExternalRefInfo    `json:"external_ref"`
+	HasUnresolvedBlockers bool                `json:"has_unresolved_blockers"`
+	IsOverdue             bool                `json:"is_overdue"`
+	RequiresApproval      bool                `json:"requires_approval"`
+	Attempts              int                 `json:"attempts"`        // times taken over or returned to NEW
+	Version               int                 `json:"version"`         // send as If-Match to edit or transition
+	HumanReviewAt         *time.Time          `json:"human_review_at"` // set while held for human review
+	StatusDeadlineAt      *time.Time          `json:"status_deadline_at"`
+	DeadlineInSeconds     *int64              `json:"deadline_in_seconds"`
+	Artefact              *string             `json:"artefact"`
+	Result                map[string]any      `json:"result"`
+	Checklist             []ChecklistItemInfo `json:"checklist"`
+	ChecklistProgress     ChecklistProgress   `json:"checklist_progress"`
+	ArchivedAt            *time.Time          `json:"archived_at"`
+	CreatedAt             time.Time           `json:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at"`
+	ServerTime            time.Time           `json:"server_time"`
+
+	// Set with ?time_format=relative
+	CreatedAtRelative      string  `json:"created_at_relative,omitempty"`
+	UpdatedAtRelative      string  `json:"updated_at_relative,omitempty"`
+	StatusDeadlineRelative *string `json:"status_deadline_relative,omitempty"`
+}
+
+// ChecklistItemInfo represents a checklist item of a task.
+type ChecklistItemInfo struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Position    int        `json:"position"`
+	ClaimedBy   *string    `json:"claimed_by"`
+	ClaimedAt   *time.Time `json:"claimed_at"`
+	CompletedBy *string    `json:"completed_by"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ChecklistProgress represents checklist completion of a task.
+type ChecklistProgress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
 }
 
 // TaskEventInfo represents a task event with actor information.
 type TaskEventInfo struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	ActorID   *string   `json:"actor_id"`
-	ActorName *string   `json:"actor_name"`
-	Comment   string    `json:"comment"`
-	OldStatus *string   `json:"old_status"`
-	NewStatus *string   `json:"new_status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	ActorID   *string        `json:"actor_id"`
+	ActorName *string        `json:"actor_name"`
+	Comment   string         `json:"comment"`
+	Data      map[string]any `json:"data,omitempty"`
+	OldStatus *string        `json:"old_status"`
+	NewStatus *string        `json:"new_status"`
+	CreatedAt time.Time      `json:"created_at"`
+
+	// Set on comments the author edited or redacted
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	RedactedAt *time.Time `json:"redacted_at,omitempty"`
+
+	// Set on replies: the comment replied to and the comment that started the thread
+	ReplyToEventID *string `json:"reply_to_event_id,omitempty"`
+	ThreadID       *string `json:"thread_id,omitempty"`
+
+	// W3C trace context of the request that caused the event
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+
+	// Set with ?time_format=relative
+	CreatedAtRelative string `json:"created_at_relative,omitempty"`
+}
+
+// TaskEventsListResponse represents the response for GET /tasks/:id/events.
+type TaskEventsListResponse struct {
+	Events []TaskEventInfo `json:"events"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// TaskCommentsResponse represents the response for GET /tasks/:id/comments.
+type TaskCommentsResponse struct {
+	Comments   []TaskEventInfo `json:"comments"`    // newest first
+	NextCursor *string         `json:"next_cursor"` // pass as cursor for older comments; null on the last page
+	Total      int             `json:"total"`
+	Limit      int             `json:"limit"`
 }
 
 // TaskEventResponse represents a single event response (for claim, escalate, etc).
 type TaskEventResponse struct {
-	ID        string    `json:"id"`
-	TaskID    string    `json:"task_id"`
-	Type      string    `json:"type"`
-	ActorID   *string   `json:"actor_id"`
-	OldStatus *string   `json:"old_status"`
-	NewStatus *string   `json:"new_status"`
-	Comment   string    `json:"comment"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string         `json:"id"`
+	TaskID    string         `json:"task_id"`
+	Type      string         `json:"type"`
+	ActorID   *string        `json:"actor_id"`
+	OldStatus *string        `json:"old_status"`
+	NewStatus *string        `json:"new_status"`
+	Comment   string         `json:"comment"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+
+	// Set on comments the author edited or redacted
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	RedactedAt *time.Time `json:"redacted_at,omitempty"`
+
+	// Set on replies: the comment replied to and the comment that started the thread
+	ReplyToEventID *string `json:"reply_to_event_id,omitempty"`
+	ThreadID       *string `json:"thread_id,omitempty"`
+
+	// W3C trace context of the request that caused the event
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+}
+
+// ExternalRefInfo represents the external item an AWAITING_EXTERNAL task waits on.
+type ExternalRefInfo struct {
+	System string  `json:"system"`
+	ID     string  `json:"id"`
+	URL    *string `json:"url"`
+}
+
+// ToExternalRefInfo converts domain.ExternalRef to ExternalRefInfo (nil stays nil).
+func ToExternalRefInfo(ref *domain.ExternalRef) *ExternalRefInfo {
+	if ref == nil {
+		return nil
+	}
+	return &ExternalRefInfo{System: ref.System, ID: ref.ID, URL: ref.URL}
+}
+
+// ResolveExternalResponse represents the response for POST /admin/workspaces/:workspace_id/external/resolve.
+type ResolveExternalResponse struct {
+	Events []TaskEventResponse `json:"events"`
 }
 
 // StatsResponse represents workspace statistics.
@@ -104,57 +251,109 @@ type AgentStats struct {
 	TasksTakenOverByAgent   int     `json:"tasks_taken_over_by_agent"`
 	EscalationsInitiated    int     `json:"escalations_initiated"`
 	EscalationsReceived     int     `json:"escalations_received"`
+	// LastSeenAt is the last heartbeat; Stale is set once it is older than the workspace's window
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Stale      bool       `json:"stale"`
 }
 
 // WorkspaceStats represents overall workspace statistics.
 type WorkspaceStats struct {
-	TotalTasksCreated     int            `json:"total_tasks_created"`
-	TasksByStatus         map[string]int `json:"tasks_by_status"`
-	AvgLeadTimeMinutes    float64        `json:"avg_lead_time_minutes"`
-	AvgCycleTimeMinutes   float64        `json:"avg_cycle_time_minutes"`
-	OverdueCount          int            `json:"overdue_count"`
-	StuckCount            int            `json:"stuck_count"`
-	CompletionRatePercent float64        `json:"completion_rate_percent"`
+	TotalTasksCreated        int            `json:"total_tasks_created"`
+	TasksByStatus            map[string]int `json:"tasks_by_status"`
+	AvgLeadTimeMinutes       float64        `json:"avg_lead_time_minutes"`
+	AvgCycleTimeMinutes      float64        `json:"avg_cycle_time_minutes"`
+	OverdueCount             int            `json:"overdue_count"`
+	StuckCount               int            `json:"stuck_count"`
+	StaleAgentCount          int            `json:"stale_agent_count"`
+	CompletionRatePercent    float64        `json:"completion_rate_percent"`
+	AwaitingExternalCount    int            `json:"awaiting_external_count"`
+	AwaitingExternalBySystem map[string]int `json:"awaiting_external_by_system"`
+}
+
+// QueueDepthResponse is a scaling signal: the amount of work waiting to be claimed.
+type QueueDepthResponse struct {
+	Queue                *string        `json:"queue,omitempty"`
+	Claimable            int            `json:"claimable"`
+	Unrestricted         int            `json:"unrestricted"`
+	ByPriority           map[string]int `json:"by_priority"`
+	ByCapability         map[string]int `json:"by_capability"`
+	ByQueue              map[string]int `json:"by_queue"`
+	OldestPendingSeconds int64          `json:"oldest_pending_seconds"`
+	ServerTime           time.Time      `json:"server_time"`
 }
 
 // ToTaskListResponse converts domain.Task to TaskListResponse.
-func ToTaskListResponse(task *domain.Task, hasUnresolvedBlockers, isOverdue bool) TaskListResponse {
+// now is reported as server_time and used to compute deadline_in_seconds.
+func ToTaskListResponse(task *domain.Task, hasUnresolvedBlockers, isOverdue bool, now time.Time) TaskListResponse {
 	return TaskListResponse{
 		ID:                    task.ID,
+		Number:                task.Number,
+		Key:                   task.Key,
 		Title:                 task.Title,
 		Status:                string(task.Status),
 		Priority:              string(task.Priority),
+		InheritedPriority:     (*string)(task.InheritedPriority),
+		EffectivePriority:     string(task.EffectivePriority()),
 		Visibility:            string(task.Visibility),
 		CreatorID:             task.CreatorID,
 		AssigneeID:            task.AssigneeID,
 		BlockedBy:             task.BlockedBy,
+		RequiredCapabilities:  task.RequiredCapabilities,
+		Queue:                 task.Queue,
+		Labels:                task.Labels,
+		ExternalRef:           ToExternalRefInfo(task.ExternalRef),
 		HasUnresolvedBlockers: hasUnresolvedBlockers,
 		IsOverdue:             isOverdue,
+		RequiresApproval:      task.RequiresApproval,
+		Attempts:              task.Attempts,
+		HumanReviewAt:         task.HumanReviewAt,
 		StatusDeadlineAt:      task.StatusDeadlineAt,
+		DeadlineInSeconds:     task.DeadlineInSeconds(now),
 		Artefact:              task.Artefact,
+		ArchivedAt:            task.ArchivedAt,
 		CreatedAt:             task.CreatedAt,
+		Version:               task.Version,
 		UpdatedAt:             task.UpdatedAt,
+		ServerTime:            now,
 	}
 }
 
 // ToTaskDetail converts domain.Task to TaskDetail.
-func ToTaskDetail(task *domain.Task, hasUnresolvedBlockers, isOverdue bool) TaskDetail {
+// now is reported as server_time and used to compute deadline_in_seconds.
+func ToTaskDetail(task *domain.Task, hasUnresolvedBlockers, isOverdue bool, now time.Time) TaskDetail {
 	return TaskDetail{
 		ID:                    task.ID,
+		Number:                task.Number,
+		Key:                   task.Key,
 		Title:                 task.Title,
 		Description:           task.Description,
 		Status:                string(task.Status),
 		Priority:              string(task.Priority),
+		InheritedPriority:     (*string)(task.InheritedPriority),
+		EffectivePriority:     string(task.EffectivePriority()),
 		Visibility:            string(task.Visibility),
 		CreatorID:             task.CreatorID,
 		AssigneeID:            task.AssigneeID,
 		BlockedBy:             task.BlockedBy,
+		RequiredCapabilities:  task.RequiredCapabilities,
+		Queue:                 task.Queue,
+		Labels:                task.Labels,
+		ExternalRef:           ToExternalRefInfo(task.ExternalRef),
 		HasUnresolvedBlockers: hasUnresolvedBlockers,
 		IsOverdue:             isOverdue,
+		RequiresApproval:      task.RequiresApproval,
+		Attempts:              task.Attempts,
+		HumanReviewAt:         task.HumanReviewAt,
 		StatusDeadlineAt:      task.StatusDeadlineAt,
+		DeadlineInSeconds:     task.DeadlineInSeconds(now),
 		Artefact:              task.Artefact,
+		Result:                task.Result,
+		Checklist:             []ChecklistItemInfo{},
+		ArchivedAt:            task.ArchivedAt,
 		CreatedAt:             task.CreatedAt,
+		Version:               task.Version,
 		UpdatedAt:             task.UpdatedAt,
+		ServerTime:            now,
 	}
 }
 
@@ -170,14 +369,1249 @@ func ToTaskEventResponse(event *domain.TaskEvent) TaskEventResponse {
 		newStatus = &s
 	}
 
-	return TaskEventResponse{
-		ID:        event.ID,
-		TaskID:    event.TaskID,
-		Type:      string(event.Type),
-		ActorID:   event.ActorID,
-		OldStatus: oldStatus,
-		NewStatus: newStatus,
-		Comment:   event.Comment,
-		CreatedAt: event.CreatedAt,
+	response := TaskEventResponse{
+		ID:         event.ID,
+		TaskID:     event.TaskID,
+		Type:       string(event.Type),
+		ActorID:    event.ActorID,
+		OldStatus:  oldStatus,
+		NewStatus:  newStatus,
+		Comment:    event.Comment,
+		Data:       event.Data,
+		CreatedAt:  event.CreatedAt,
+		EditedAt:   event.EditedAt,
+		RedactedAt: event.RedactedAt,
+
+		ReplyToEventID: event.ReplyToEventID,
+		ThreadID:       event.ThreadID,
+	}
+	if event.Trace != nil {
+		response.TraceParent = event.Trace.TraceParent
+		response.TraceState = event.Trace.TraceState
+	}
+	return response
+}
+
+// LineageNode represents a task within a lineage tree.
+type LineageNode struct {
+	ID         string        `json:"id"`
+	Title      string        `json:"title"`
+	Status     string        `json:"status"`
+	Priority   string        `json:"priority"`
+	AssigneeID *string       `json:"assignee_id"`
+	Relation   string        `json:"relation,omitempty"`
+	Children   []LineageNode `json:"children"`
+}
+
+// TaskLineageResponse represents the response for GET /tasks/:id/lineage.
+type TaskLineageResponse struct {
+	Task        LineageNode   `json:"task"`
+	Ancestors   []LineageNode `json:"ancestors"`
+	Descendants []LineageNode `json:"descendants"`
+}
+
+// ToTaskLineageResponse converts domain.TaskLineage to TaskLineageResponse.
+func ToTaskLineageResponse(lineage *domain.TaskLineage) TaskLineageResponse {
+	return TaskLineageResponse{
+		Task:        toLineageNode(&domain.LineageNode{Task: lineage.Task}),
+		Ancestors:   toLineageNodes(lineage.Ancestors),
+		Descendants: toLineageNodes(lineage.Descendants),
+	}
+}
+
+// TaskTimingsResponse represents the response for GET /tasks/:id/timings.
+// Durations are in whole seconds.
+type TaskTimingsResponse struct {
+	TaskID       string               `json:"task_id"`
+	Status       string               `json:"status"`
+	Since        time.Time            `json:"since"`
+	Until        time.Time            `json:"until"`
+	TotalSeconds int64                `json:"total_seconds"`
+	Statuses     []StatusTimingInfo   `json:"statuses"`
+	Assignees    []AssigneeTimingInfo `json:"assignees"`
+	Periods      []TimingPeriodInfo   `json:"periods"`
+}
+
+// StatusTimingInfo represents the time a task spent in one status.
+type StatusTimingInfo struct {
+	Status  string `json:"status"`
+	Seconds int64  `json:"seconds"`
+	Entries int    `json:"entries"`
+}
+
+// AssigneeTimingInfo represents the time a task was assigned to one agent.
+type AssigneeTimingInfo struct {
+	AgentID   string           `json:"agent_id"`
+	AgentName string           `json:"agent_name,omitempty"`
+	Seconds   int64            `json:"seconds"`
+	ByStatus  map[string]int64 `json:"by_status"`
+	Periods   int              `json:"periods"`
+}
+
+// TimingPeriodInfo represents a stretch with one status and assignee; ended_at is null for the current one.
+type TimingPeriodInfo struct {
+	Status     string     `json:"status"`
+	AssigneeID *string    `json:"assignee_id"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+	Seconds    int64      `json:"seconds"`
+}
+
+// ToTaskTimingsResponse converts domain.TaskTimings to TaskTimingsResponse.
+func ToTaskTimingsResponse(timings *domain.TaskTimings) TaskTimingsResponse {
+	response := TaskTimingsResponse{
+		TaskID:       timings.TaskID,
+		Status:       string(timings.Status),
+		Since:        timings.Since,
+		Until:        timings.Until,
+		TotalSeconds: int64(timings.Total.Seconds()),
+		Statuses:     make([]StatusTimingInfo, len(timings.Statuses)),
+		Assignees:    make([]AssigneeTimingInfo, len(timings.Assignees)),
+		Periods:      make([]TimingPeriodInfo, len(timings.Periods)),
+	}
+
+	for i, st := range timings.Statuses {
+		response.Statuses[i] = StatusTimingInfo{
+			Status:  string(st.Status),
+			Seconds: int64(st.Duration.Seconds()),
+			Entries: st.Entries,
+		}
+	}
+
+	for i, at := range timings.Assignees {
+		byStatus := make(map[string]int64, len(at.ByStatus))
+		for status, duration := range at.ByStatus {
+			byStatus[string(status)] = int64(duration.Seconds())
+		}
+		response.Assignees[i] = AssigneeTimingInfo{
+			AgentID:   at.AgentID,
+			AgentName: at.AgentName,
+			Seconds:   int64(at.Duration.Seconds()),
+			ByStatus:  byStatus,
+			Periods:   at.Periods,
+		}
+	}
+
+	for i, period := range timings.Periods {
+		response.Periods[i] = TimingPeriodInfo{
+			Status:     string(period.Status),
+			AssigneeID: period.AssigneeID,
+			StartedAt:  period.StartedAt,
+			EndedAt:    period.EndedAt,
+			Seconds:    int64(period.Duration.Seconds()),
+		}
+	}
+
+	return response
+}
+
+// toLineageNodes converts a slice of domain lineage nodes recursively.
+func toLineageNodes(nodes []*domain.LineageNode) []LineageNode {
+	result := make([]LineageNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = toLineageNode(node)
+	}
+	return result
+}
+
+// toLineageNode converts a single domain lineage node recursively.
+func toLineageNode(node *domain.LineageNode) LineageNode {
+	return LineageNode{
+		ID:         node.Task.ID,
+		Title:      node.Task.Title,
+		Status:     string(node.Task.Status),
+		Priority:   string(node.Task.Priority),
+		AssigneeID: node.Task.AssigneeID,
+		Relation:   string(node.Relation),
+		Children:   toLineageNodes(node.Children),
+	}
+}
+
+// ReadTokenInfo represents a workspace read token (without its secret).
+type ReadTokenInfo struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateReadTokenResponse represents the response for POST /admin/workspaces/:workspace_id/read-tokens.
+// Token is shown only once.
+type CreateReadTokenResponse struct {
+	Token     string        `json:"token"`
+	ReadToken ReadTokenInfo `json:"read_token"`
+}
+
+// ReadTokensListResponse represents the response for GET /admin/workspaces/:workspace_id/read-tokens.
+type ReadTokensListResponse struct {
+	ReadTokens []ReadTokenInfo `json:"read_tokens"`
+}
+
+// ToReadTokenInfo converts domain.ReadToken to ReadTokenInfo.
+func ToReadTokenInfo(token *domain.ReadToken) ReadTokenInfo {
+	return ReadTokenInfo{
+		ID:          token.ID,
+		WorkspaceID: token.WorkspaceID,
+		Name:        token.Name,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		RevokedAt:   token.RevokedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+// WebhookSecretInfo represents the state of a workspace webhook secret (without the secret).
+type WebhookSecretInfo struct {
+	WorkspaceID       string     `json:"workspace_id"`
+	Rotating          bool       `json:"rotating"`
+	PreviousExpiresAt *time.Time `json:"previous_expires_at"`
+	RotatedAt         time.Time  `json:"rotated_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// RotateWebhookSecretResponse represents the response for POST /admin/workspaces/:workspace_id/webhook-secret/rotate.
+// Secret is shown only once.
+type RotateWebhookSecretResponse struct {
+	Secret        string            `json:"secret"`
+	WebhookSecret WebhookSecretInfo `json:"webhook_secret"`
+}
+
+// ToWebhookSecretInfo converts domain.WebhookSecret to WebhookSecretInfo.
+func ToWebhookSecretInfo(secret *domain.WebhookSecret, now time.Time) WebhookSecretInfo {
+	info := WebhookSecretInfo{
+		WorkspaceID: secret.WorkspaceID,
+		Rotating:    secret.IsRotating(now),
+		RotatedAt:   secret.RotatedAt,
+		CreatedAt:   secret.CreatedAt,
+	}
+	if info.Rotating {
+		info.PreviousExpiresAt = secret.PreviousExpiresAt
+	}
+	return info
+}
+
+// EscalationRouteInfo represents an escalation route.
+type EscalationRouteInfo struct {
+	ID         string    `json:"id"`
+	Position   int       `json:"position"`
+	Name       string    `json:"name"`
+	Label      *string   `json:"label"`
+	Priority   *string   `json:"priority"`
+	CreatorID  *string   `json:"creator_id"`
+	TargetType string    `json:"target_type"`
+	Target     string    `json:"target"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// EscalationRoutesResponse represents the response for GET and PUT /admin/workspaces/:workspace_id/escalation-routes.
+type EscalationRoutesResponse struct {
+	Routes []EscalationRouteInfo `json:"routes"`
+}
+
+// ToEscalationRoutesResponse converts escalation routes to EscalationRoutesResponse.
+func ToEscalationRoutesResponse(routes []*domain.EscalationRoute) EscalationRoutesResponse {
+	response := EscalationRoutesResponse{Routes: make([]EscalationRouteInfo, len(routes))}
+	for i, route := range routes {
+		response.Routes[i] = EscalationRouteInfo{
+			ID:         route.ID,
+			Position:   route.Position,
+			Name:       route.Name,
+			Label:      route.Label,
+			Priority:   (*string)(route.Priority),
+			CreatorID:  route.CreatorID,
+			TargetType: string(route.TargetType),
+			Target:     route.Target,
+			CreatedAt:  route.CreatedAt,
+		}
+	}
+	return response
+}
+
+// EscalationInfo represents an escalation routed to the calling agent.
+type EscalationInfo struct {
+	ID         string         `json:"id"`
+	TaskID     string         `json:"task_id"`
+	EventID    string         `json:"event_id"`
+	Route      string         `json:"route"`
+	Escalation map[string]any `json:"escalation"` // the task and escalation when escalated
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// EscalationsResponse represents the response for GET /escalations.
+type EscalationsResponse struct {
+	Escalations []EscalationInfo `json:"escalations"`
+	Total       int              `json:"total"`
+	Limit       int              `json:"limit"`
+	Offset      int              `json:"offset"`
+}
+
+// MessageInfo represents a direct message between two agents about a task.
+type MessageInfo struct {
+	ID          string     `json:"id"`
+	TaskID      string     `json:"task_id"`
+	SenderID    string     `json:"sender_id"`
+	RecipientID string     `json:"recipient_id"`
+	Body        string     `json:"body"`
+	ReadAt      *time.Time `json:"read_at"` // null until the recipient acknowledges it
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// MessagesResponse represents the response for GET /messages.
+type MessagesResponse struct {
+	Messages []MessageInfo `json:"messages"`
+	Total    int           `json:"total"`
+	Limit    int           `json:"limit"`
+	Offset   int           `json:"offset"`
+}
+
+// ToMessageInfo converts domain.TaskMessage to MessageInfo.
+func ToMessageInfo(message *domain.TaskMessage) MessageInfo {
+	return MessageInfo{
+		ID:          message.ID,
+		TaskID:      message.TaskID,
+		SenderID:    message.SenderID,
+		RecipientID: message.RecipientID,
+		Body:        message.Body,
+		ReadAt:      message.ReadAt,
+		CreatedAt:   message.CreatedAt,
+	}
+}
+
+// WatchInfo represents an agent watching a task.
+type WatchInfo struct {
+	TaskID    string    `json:"task_id"`
+	AgentID   string    `json:"agent_id"`
+	CreatedAt time.Time `json:"created_at"` // notifications start here
+}
+
+// ToWatchInfo converts domain.TaskWatch to WatchInfo.
+func ToWatchInfo(watch *domain.TaskWatch) WatchInfo {
+	return WatchInfo{
+		TaskID:    watch.TaskID,
+		AgentID:   watch.AgentID,
+		CreatedAt: watch.CreatedAt,
+	}
+}
+
+// NotificationInfo represents an event on a watched task or a comment
+// mentioning the agent.
+type NotificationInfo struct {
+	TaskID    string `json:"task_id"`
+	TaskTitle string `json:"task_title"`
+	Mentioned bool   `json:"mentioned"` // the comment mentions you
+	TaskEventInfo
+}
+
+// NotificationsResponse represents the response for GET /notifications.
+type NotificationsResponse struct {
+	Notifications []NotificationInfo `json:"notifications"`
+	Total         int                `json:"total"`
+	Limit         int                `json:"limit"`
+	Offset        int                `json:"offset"`
+}
+
+// ToEscalationInfo converts domain.EscalationNotification to EscalationInfo.
+func ToEscalationInfo(notification *domain.EscalationNotification) EscalationInfo {
+	return EscalationInfo{
+		ID:         notification.ID,
+		TaskID:     notification.TaskID,
+		EventID:    notification.EventID,
+		Route:      notification.Route,
+		Escalation: notification.Payload,
+		CreatedAt:  notification.CreatedAt,
+	}
+}
+
+// ToChecklistItemInfo converts domain.ChecklistItem to ChecklistItemInfo.
+func ToChecklistItemInfo(item *domain.ChecklistItem) ChecklistItemInfo {
+	return ChecklistItemInfo{
+		ID:          item.ID,
+		Title:       item.Title,
+		Position:    item.Position,
+		ClaimedBy:   item.ClaimedBy,
+		ClaimedAt:   item.ClaimedAt,
+		CompletedBy: item.CompletedBy,
+		CompletedAt: item.CompletedAt,
+		CreatedAt:   item.CreatedAt,
+	}
+}
+
+// ToChecklist converts checklist items to their response form along with completion progress.
+func ToChecklist(items []*domain.ChecklistItem) ([]ChecklistItemInfo, ChecklistProgress) {
+	infos := make([]ChecklistItemInfo, len(items))
+	for i, item := range items {
+		infos[i] = ToChecklistItemInfo(item)
+	}
+	progress := domain.NewChecklistProgress(items)
+	return infos, ChecklistProgress{Total: progress.Total, Completed: progress.Completed}
+}
+
+// ExportManifest describes the snapshot an export was taken from.
+type ExportManifest struct {
+	WorkspaceID string    `json:"workspace_id"`
+	SnapshotAt  time.Time `json:"snapshot_at"`
+	SnapshotLSN string    `json:"snapshot_lsn"`
+	TxSnapshot  string    `json:"tx_snapshot"`
+	AgentCount  int       `json:"agent_count"`
+	TaskCount   int       `json:"task_count"`
+	EventCount  int       `json:"event_count"`
+}
+
+// ExportWorkspace represents the exported workspace settings.
+type ExportWorkspace struct {
+	ID                  string         `json:"id"`
+	Name                string         `json:"name"`
+	Slug                string         `json:"slug"`
+	StatusDeadlines     map[string]int `json:"status_deadlines"`
+	AutoAssignStrategy  string         `json:"auto_assign_strategy"`
+	PriorityInheritance bool           `json:"priority_inheritance"`
+	CreatedAt           time.Time      `json:"created_at"`
+}
+
+// ExportAgent represents an exported agent. Tokens are never exported.
+type ExportAgent struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	IsActive     bool      `json:"is_active"`
+	Capabilities []string  `json:"capabilities"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExportTask represents an exported task as stored.
+type ExportTask struct {
+	ID                   string           `json:"id"`
+	Title                string           `json:"title"`
+	Description          string           `json:"description"`
+	CreatorID            string           `json:"creator_id"`
+	AssigneeID           *string          `json:"assignee_id"`
+	Status               string           `json:"status"`
+	Visibility           string           `json:"visibility"`
+	Priority             string           `json:"priority"`
+	BlockedBy            []string         `json:"blocked_by"`
+	RequiredCapabilities []string         `json:"required_capabilities"`
+	Queue                *string          `json:"queue"`
+	Labels               []string         `json:"labels"`
+	ExternalRef          *ExternalRefInfo `json:"external_ref"`
+	StatusDeadlineAt     *time.Time       `json:"status_deadline_at"`
+	Artefact             *string          `json:"artefact"`
+	Result               map[string]any   `json:"result"`
+	ArchivedAt           *time.Time       `json:"archived_at"`
+	CreatedAt            time.Time        `json:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at"`
+}
+
+// WorkspaceExportResponse represents the response for GET /admin/workspaces/:workspace_id/export.
+type WorkspaceExportResponse struct {
+	Manifest  ExportManifest      `json:"manifest"`
+	Workspace ExportWorkspace     `json:"workspace"`
+	Agents    []ExportAgent       `json:"agents"`
+	Queues    []QueueResponse     `json:"queues"`
+	Labels    []LabelResponse     `json:"labels"`
+	Tasks     []ExportTask        `json:"tasks"`
+	Events    []TaskEventResponse `json:"events"`
+}
+
+// ToWorkspaceExportResponse converts domain.WorkspaceSnapshot to WorkspaceExportResponse.
+func ToWorkspaceExportResponse(snapshot *domain.WorkspaceSnapshot) WorkspaceExportResponse {
+	manifest := snapshot.Manifest
+	response := WorkspaceExportResponse{
+		Manifest: ExportManifest{
+			WorkspaceID: manifest.WorkspaceID,
+			SnapshotAt:  manifest.SnapshotAt,
+			SnapshotLSN: manifest.SnapshotLSN,
+			TxSnapshot:  manifest.TxSnapshot,
+			AgentCount:  manifest.AgentCount,
+			TaskCount:   manifest.TaskCount,
+			EventCount:  manifest.EventCount,
+		},
+		Workspace: ExportWorkspace{
+			ID:                  snapshot.Workspace.ID,
+			Name:                snapshot.Workspace.Name,
+			Slug:                snapshot.Workspace.Slug,
+			StatusDeadlines:     snapshot.Workspace.StatusDeadlines,
+			AutoAssignStrategy:  string(snapshot.Workspace.AutoAssignStrategy),
+			PriorityInheritance: snapshot.Workspace.PriorityInheritance,
+			CreatedAt:           snapshot.Workspace.CreatedAt,
+		},
+		Agents: make([]ExportAgent, len(snapshot.Agents)),
+		Queues: make([]QueueResponse, len(snapshot.Queues)),
+		Labels: make([]LabelResponse, len(snapshot.Labels)),
+		Tasks:  make([]ExportTask, len(snapshot.Tasks)),
+		Events: make([]TaskEventResponse, len(snapshot.Events)),
+	}
+
+	for i, agent := range snapshot.Agents {
+		response.Agents[i] = ExportAgent{
+			ID:           agent.ID,
+			Name:         agent.Name,
+			IsActive:     agent.IsActive,
+			Capabilities: agent.Capabilities,
+			CreatedAt:    agent.CreatedAt,
+		}
+	}
+
+	for i, queue := range snapshot.Queues {
+		response.Queues[i] = ToQueueResponse(queue)
+	}
+
+	for i, label := range snapshot.Labels {
+		response.Labels[i] = ToLabelResponse(label)
+	}
+
+	for i, task := range snapshot.Tasks {
+		response.Tasks[i] = ExportTask{
+			ID:                   task.ID,
+			Title:                task.Title,
+			Description:          task.Description,
+			CreatorID:            task.CreatorID,
+			AssigneeID:           task.AssigneeID,
+			Status:               string(task.Status),
+			Visibility:           string(task.Visibility),
+			Priority:             string(task.Priority),
+			BlockedBy:            task.BlockedBy,
+			RequiredCapabilities: task.RequiredCapabilities,
+			Queue:                task.Queue,
+			Labels:               task.Labels,
+			ExternalRef:          ToExternalRefInfo(task.ExternalRef),
+			StatusDeadlineAt:     task.StatusDeadlineAt,
+			Artefact:             task.Artefact,
+			Result:               task.Result,
+			ArchivedAt:           task.ArchivedAt,
+			CreatedAt:            task.CreatedAt,
+			UpdatedAt:            task.UpdatedAt,
+		}
+	}
+
+	for i, event := range snapshot.Events {
+		response.Events[i] = ToTaskEventResponse(event)
+	}
+
+	return response
+}
+
+// AgentCapabilitiesResponse represents the response for PUT /admin/agents/:id/capabilities.
+type AgentCapabilitiesResponse struct {
+	AgentID      string   `json:"agent_id"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// AutoAssignStrategyResponse represents the response for PUT /admin/workspaces/:workspace_id/auto-assign.
+type AutoAssignStrategyResponse struct {
+	WorkspaceID string `json:"workspace_id"`
+	Strategy    string `json:"strategy"`
+}
+
+// PriorityInheritanceResponse represents the response for PUT /admin/workspaces/:workspace_id/priority-inheritance.
+type PriorityInheritanceResponse struct {
+	WorkspaceID  string `json:"workspace_id"`
+	Enabled      bool   `json:"enabled"`
+	TasksUpdated int    `json:"tasks_updated"` // tasks whose inherited priority changed
+}
+
+// AutoUnblockResponse represents the response for PUT /admin/workspaces/:workspace_id/auto-unblock.
+type AutoUnblockResponse struct {
+	WorkspaceID string `json:"workspace_id"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// ArchiveWorkspaceResponse represents the response for POST /admin/workspaces/:workspace_id/archive.
+type ArchiveWorkspaceResponse struct {
+	WorkspaceID       string    `json:"workspace_id"`
+	Slug              string    `json:"slug"`
+	ArchivedAt        time.Time `json:"archived_at"`
+	AgentsRevoked     int64     `json:"agents_revoked"`
+	ReadTokensRevoked int64     `json:"read_tokens_revoked"`
+	SchedulesDisabled int64     `json:"schedules_disabled"`
+	ReportsDisabled   int64     `json:"reports_disabled"`
+}
+
+// WorkspaceOverview represents one workspace in the admin workspace list.
+type WorkspaceOverview struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	Slug          string         `json:"slug"`
+	ArchivedAt    *time.Time     `json:"archived_at,omitempty"`
+	ExpiresAt     *time.Time     `json:"expires_at,omitempty"` // set for sandboxes only
+	TasksByStatus map[string]int `json:"tasks_by_status"`
+	OverdueCount  int            `json:"overdue_count"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// WorkspacesResponse represents the response for GET /admin/workspaces.
+type WorkspacesResponse struct {
+	Workspaces []WorkspaceOverview `json:"workspaces"`
+}
+
+// RuntimeConfigResponse represents the active runtime settings of the server.
+type RuntimeConfigResponse struct {
+	File                  string `json:"file,omitempty"` // settings file read on reload, omitted when none is configured
+	LogLevel              string `json:"log_level"`
+	IntakeRequestsPerHour int    `json:"intake_requests_per_hour"`
+	ReadOnly              bool   `json:"read_only"` // whether API writes are rejected, also when switched by PUT /admin/read-only
+}
+
+// ReadOnlyResponse represents the response for PUT /admin/read-only.
+type ReadOnlyResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ReloadConfigResponse represents the response for POST /admin/config/reload.
+type ReloadConfigResponse struct {
+	Settings RuntimeConfigResponse `json:"settings"`
+	Changes  map[string]any        `json:"changes"` // setting name → {"old", "new"}; empty when nothing changed
+}
+
+// AgentInfo represents an agent of the caller's workspace with its liveness.
+type AgentInfo struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	IsActive     bool       `json:"is_active"`
+	Capabilities []string   `json:"capabilities"`
+	LastSeenAt   *time.Time `json:"last_seen_at"` // last heartbeat, null if none was sent
+	Stale        bool       `json:"stale"`
+}
+
+// AgentsResponse represents the response for GET /agents.
+type AgentsResponse struct {
+	Agents            []AgentInfo `json:"agents"`
+	StaleAfterSeconds int         `json:"stale_after_seconds"`
+}
+
+// HeartbeatResponse represents the response for POST /agents/me/heartbeat.
+type HeartbeatResponse struct {
+	AgentID           string    `json:"agent_id"`
+	LastSeenAt        time.Time `json:"last_seen_at"`
+	StaleAfterSeconds int       `json:"stale_after_seconds"` // send the next heartbeat well within this
+	UnreadMessages    int       `json:"unread_messages"`     // direct messages not acknowledged yet
+}
+
+// AgentMeResponse represents the response for GET /agents/me.
+type AgentMeResponse struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	Capabilities   []string         `json:"capabilities"`
+	LastSeenAt     *time.Time       `json:"last_seen_at"` // last heartbeat, null if none was sent
+	Stale          bool             `json:"stale"`
+	Workspace      WorkspaceSummary `json:"workspace"`
+	WIPCount       int              `json:"wip_count"` // IN_PROGRESS tasks assigned to the agent
+	OpenTasks      AgentOpenTasks   `json:"open_tasks"`
+	UnreadMessages int              `json:"unread_messages"` // direct messages not acknowledged yet
+}
+
+// WorkspaceSummary identifies a workspace, with the heartbeat window of its agents.
+type WorkspaceSummary struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Slug              string `json:"slug"`
+	StaleAfterSeconds int    `json:"stale_after_seconds"`
+}
+
+// AgentOpenTasks summarizes an agent's tasks that are not DONE or CANCELLED.
+type AgentOpenTasks struct {
+	Assigned         int            `json:"assigned"`
+	AssignedByStatus map[string]int `json:"assigned_by_status"` // every open status, 0 when none
+	Overdue          int            `json:"overdue"`            // assigned tasks past their status deadline
+	Created          int            `json:"created"`            // open tasks the agent created
+}
+
+// ContextResponse represents the response for GET /context: what a freshly
+// started agent needs before its first move, in one call.
+type ContextResponse struct {
+	Agent             AgentMeResponse      `json:"agent"`
+	Deadlines         WorkspaceDeadlines   `json:"deadlines"`
+	WorkspaceTasks    WorkspaceTaskSummary `json:"workspace_tasks"`
+	Assignments       []TaskListResponse   `json:"assignments"`        // open tasks assigned to the agent, most urgent first
+	RecentEscalations []EscalationInfo     `json:"recent_escalations"` // routed to the agent, newest first
+	SkillMd           string               `json:"skill_md"`
+	ServerTime        time.Time            `json:"server_time"`
+}
+
+// WorkspaceDeadlines describes the status deadlines of a workspace.
+type WorkspaceDeadlines struct {
+	StatusMinutes  map[string]int    `json:"status_minutes"`  // every status with a deadline -> minutes, 0 for none
+	Expiry         map[string]string `json:"expiry"`          // every status with a deadline -> status entered on expiry
+	WarningPercent *int              `json:"warning_percent"` // share of the deadline left at the deadline_warning event; null without warnings
+}
+
+// ToWorkspaceDeadlines converts a workspace's deadline settings.
+func ToWorkspaceDeadlines(workspace *domain.Workspace) WorkspaceDeadlines {
+	deadlines := WorkspaceDeadlines{
+		StatusMinutes: make(map[string]int),
+		Expiry:        ToDeadlineExpiryResponse(workspace.ID, workspace.DeadlineExpiry).Expiry,
+	}
+	for _, status := range []domain.TaskStatus{domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusBlocked} {
+		deadlines.StatusMinutes[string(status)] = workspace.GetDeadlineMinutes(status)
+	}
+	if workspace.DeadlineWarningPercent > 0 {
+		percent := workspace.DeadlineWarningPercent
+		deadlines.WarningPercent = &percent
+	}
+	return deadlines
+}
+
+// WorkspaceTaskSummary summarizes a workspace's tasks that are not DONE or CANCELLED.
+type WorkspaceTaskSummary struct {
+	Open      int            `json:"open"`
+	ByStatus  map[string]int `json:"by_status"` // every open status, 0 when none
+	Overdue   int            `json:"overdue"`   // past their status deadline
+	Claimable int            `json:"claimable"` // NEW tasks some agent could claim now
+}
+
+// AgentStaleAfterResponse represents the response for PUT /admin/workspaces/:workspace_id/agent-staleness.
+type AgentStaleAfterResponse struct {
+	WorkspaceID       string `json:"workspace_id"`
+	StaleAfterSeconds int    `json:"stale_after_seconds"`
+}
+
+// DoneValidationResponse represents a workspace's DONE validation webhook.
+type DoneValidationResponse struct {
+	WorkspaceID    string  `json:"workspace_id"`
+	Enabled        bool    `json:"enabled"`
+	URL            *string `json:"url"`
+	TimeoutSeconds int     `json:"timeout_seconds,omitempty"`
+	FailOpen       bool    `json:"fail_open"`
+}
+
+// ToDoneValidationResponse converts a workspace's DONE validation hook, nil when none is set.
+func ToDoneValidationResponse(workspaceID string, hook *domain.DoneValidationHook) DoneValidationResponse {
+	response := DoneValidationResponse{WorkspaceID: workspaceID}
+	if hook != nil {
+		response.Enabled = true
+		response.URL = &hook.URL
+		response.TimeoutSeconds = int(hook.Timeout / time.Second)
+		response.FailOpen = hook.FailOpen
 	}
+	return response
+}
+
+// EventWebhookResponse represents a workspace's event webhook.
+type EventWebhookResponse struct {
+	WorkspaceID string   `json:"workspace_id"`
+	Enabled     bool     `json:"enabled"`
+	URL         *string  `json:"url"`
+	EventTypes  []string `json:"event_types"` // empty for all
+}
+
+// ToEventWebhookResponse converts a workspace's event webhook, nil when none is set.
+func ToEventWebhookResponse(workspaceID string, hook *domain.EventWebhook) EventWebhookResponse {
+	response := EventWebhookResponse{WorkspaceID: workspaceID, EventTypes: []string{}}
+	if hook != nil {
+		response.Enabled = true
+		response.URL = &hook.URL
+		for _, eventType := range hook.EventTypes {
+			response.EventTypes = append(response.EventTypes, string(eventType))
+		}
+	}
+	return response
+}
+
+// EventBrokerResponse represents a workspace's event broker topic.
+type EventBrokerResponse struct {
+	WorkspaceID string   `json:"workspace_id"`
+	Enabled     bool     `json:"enabled"`
+	Topic       *string  `json:"topic"`
+	EventTypes  []string `json:"event_types"` // empty for all
+}
+
+// ToEventBrokerResponse converts a workspace's event broker topic, nil when none is set.
+func ToEventBrokerResponse(workspaceID string, topic *domain.EventBrokerTopic) EventBrokerResponse {
+	response := EventBrokerResponse{WorkspaceID: workspaceID, EventTypes: []string{}}
+	if topic != nil {
+		response.Enabled = true
+		response.Topic = &topic.Topic
+		for _, eventType := range topic.EventTypes {
+			response.EventTypes = append(response.EventTypes, string(eventType))
+		}
+	}
+	return response
+}
+
+// OutboxMessageInfo represents the delivery of one task event to an event
+// webhook or broker topic.
+type OutboxMessageInfo struct {
+	ID            string     `json:"id"`
+	Seq           int64      `json:"seq"`
+	TaskID        string     `json:"task_id"`
+	EventID       string     `json:"event_id"`
+	TargetType    string     `json:"target_type"` // webhook or broker
+	Target        string     `json:"target"`      // webhook URL or broker topic
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // pending messages only
+	LastError     *string    `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ToOutboxMessageInfo converts domain.OutboxMessage to OutboxMessageInfo.
+func ToOutboxMessageInfo(message *domain.OutboxMessage) OutboxMessageInfo {
+	info := OutboxMessageInfo{
+		ID:          message.ID,
+		Seq:         message.Seq,
+		TaskID:      message.TaskID,
+		EventID:     message.EventID,
+		TargetType:  string(message.TargetType),
+		Target:      message.Target,
+		Status:      string(message.Status),
+		Attempts:    message.Attempts,
+		LastError:   message.LastError,
+		DeliveredAt: message.DeliveredAt,
+		CreatedAt:   message.CreatedAt,
+	}
+	if message.Status == domain.OutboxPending {
+		info.NextAttemptAt = &message.NextAttemptAt
+	}
+	return info
+}
+
+// OutboxResponse represents the response for GET /admin/workspaces/:workspace_id/outbox.
+type OutboxResponse struct {
+	Messages []OutboxMessageInfo `json:"messages"`
+	Total    int                 `json:"total"`
+	Limit    int                 `json:"limit"`
+	Offset   int                 `json:"offset"`
+}
+
+// PriorityAgingResponse represents a workspace's priority aging policy.
+type PriorityAgingResponse struct {
+	WorkspaceID  string  `json:"workspace_id"`
+	Enabled      bool    `json:"enabled"`
+	AfterSeconds *int    `json:"after_seconds"`
+	Action       *string `json:"action"`
+}
+
+// ToPriorityAgingResponse converts a workspace's priority aging policy, nil when aging is off.
+func ToPriorityAgingResponse(workspaceID string, aging *domain.PriorityAging) PriorityAgingResponse {
+	response := PriorityAgingResponse{WorkspaceID: workspaceID}
+	if aging != nil {
+		afterSeconds := int(aging.After / time.Second)
+		action := string(aging.Action)
+		response.Enabled = true
+		response.AfterSeconds = &afterSeconds
+		response.Action = &action
+	}
+	return response
+}
+
+// ClaimFairnessResponse represents a workspace's claim limits.
+type ClaimFairnessResponse struct {
+	WorkspaceID   string `json:"workspace_id"`
+	Quota         *int   `json:"quota"` // null for no quota
+	WindowSeconds int    `json:"window_seconds"`
+	TakeTurns     bool   `json:"take_turns"`
+}
+
+// ToClaimFairnessResponse converts a workspace's claim limits.
+func ToClaimFairnessResponse(workspaceID string, fairness domain.ClaimFairness) ClaimFairnessResponse {
+	response := ClaimFairnessResponse{
+		WorkspaceID:   workspaceID,
+		WindowSeconds: int(fairness.Window / time.Second),
+		TakeTurns:     fairness.TakeTurns,
+	}
+	if fairness.Quota > 0 {
+		response.Quota = &fairness.Quota
+	}
+	return response
+}
+
+// DeadlineWarningResponse represents a workspace's deadline warning setting.
+type DeadlineWarningResponse struct {
+	WorkspaceID string `json:"workspace_id"`
+	Enabled     bool   `json:"enabled"`
+	Percent     *int   `json:"percent"` // null when warnings are off
+}
+
+// ToDeadlineWarningResponse converts a workspace's deadline warning share, 0 when warnings are off.
+func ToDeadlineWarningResponse(workspaceID string, percent int) DeadlineWarningResponse {
+	response := DeadlineWarningResponse{WorkspaceID: workspaceID}
+	if percent > 0 {
+		response.Enabled = true
+		response.Percent = &percent
+	}
+	return response
+}
+
+// DeadlineExpiryResponse represents what happens to tasks of a workspace when a status deadline expires.
+type DeadlineExpiryResponse struct {
+	WorkspaceID string            `json:"workspace_id"`
+	Expiry      map[string]string `json:"expiry"` // every status with a deadline -> status entered on expiry
+}
+
+// ToDeadlineExpiryResponse converts a workspace's expiry settings, filling in STUCK for statuses left out.
+func ToDeadlineExpiryResponse(workspaceID string, expiry map[string]string) DeadlineExpiryResponse {
+	workspace := &domain.Workspace{DeadlineExpiry: expiry}
+	response := DeadlineExpiryResponse{WorkspaceID: workspaceID, Expiry: make(map[string]string)}
+	for _, status := range []domain.TaskStatus{domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusBlocked} {
+		response.Expiry[string(status)] = string(workspace.ExpiryStatus(status))
+	}
+	return response
+}
+
+// MaxAttemptsResponse represents a workspace's attempt limit.
+type MaxAttemptsResponse struct {
+	WorkspaceID string `json:"workspace_id"`
+	Enabled     bool   `json:"enabled"`
+	MaxAttempts *int   `json:"max_attempts"` // null when tasks may change hands without limit
+}
+
+// ToMaxAttemptsResponse converts a workspace's attempt limit, 0 when there is none.
+func ToMaxAttemptsResponse(workspaceID string, limit int) MaxAttemptsResponse {
+	response := MaxAttemptsResponse{WorkspaceID: workspaceID}
+	if limit > 0 {
+		response.Enabled = true
+		response.MaxAttempts = &limit
+	}
+	return response
+}
+
+// IntakeFormResponse represents a workspace's intake form.
+type IntakeFormResponse struct {
+	WorkspaceID      string    `json:"workspace_id"`
+	AgentID          string    `json:"agent_id"`
+	RateLimitPerHour int       `json:"rate_limit_per_hour"`
+	SubmitPath       string    `json:"submit_path"`
+	Key              *string   `json:"key,omitempty"` // plaintext, returned only when issued
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ToIntakeFormResponse converts domain.IntakeForm to IntakeFormResponse.
+func ToIntakeFormResponse(form *domain.IntakeForm, slug, key string) IntakeFormResponse {
+	response := IntakeFormResponse{
+		WorkspaceID:      form.WorkspaceID,
+		AgentID:          form.AgentID,
+		RateLimitPerHour: form.RateLimitPerHour,
+		SubmitPath:       "/api/v1/intake/" + slug,
+		CreatedAt:        form.CreatedAt,
+		UpdatedAt:        form.UpdatedAt,
+	}
+	if key != "" {
+		response.Key = &key
+	}
+	return response
+}
+
+// IntakeSubmissionResponse represents the response for POST /intake/:workspace.
+type IntakeSubmissionResponse struct {
+	TaskID string `json:"task_id"`
+	Status string `json:"status"`
+}
+
+// SandboxAgentInfo is a cloned agent with its new token.
+type SandboxAgentInfo struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Token        string   `json:"token"`
+	IsActive     bool     `json:"is_active"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// CreateSandboxResponse represents the response for POST /admin/workspaces/:workspace_id/sandbox.
+// Agent tokens are shown only once.
+type CreateSandboxResponse struct {
+	WorkspaceID  string             `json:"workspace_id"`
+	Name         string             `json:"name"`
+	Slug         string             `json:"slug"`
+	SandboxOf    string             `json:"sandbox_of"`
+	ExpiresAt    time.Time          `json:"expires_at"`
+	Agents       []SandboxAgentInfo `json:"agents"`
+	TasksCloned  int64              `json:"tasks_cloned"`
+	QueuesCloned int64              `json:"queues_cloned"`
+	LabelsCloned int64              `json:"labels_cloned"`
+}
+
+// DeleteWorkspaceResponse represents the response for DELETE /admin/workspaces/:workspace_id.
+type DeleteWorkspaceResponse struct {
+	WorkspaceID  string     `json:"workspace_id"`
+	Slug         string     `json:"slug"`
+	TasksDeleted int64      `json:"tasks_deleted"`
+	Batches      int        `json:"batches"`
+	ExportedAt   *time.Time `json:"exported_at,omitempty"`
+}
+
+// AuditEntryResponse represents an entry of the admin audit log.
+type AuditEntryResponse struct {
+	ID          string         `json:"id"`
+	Action      string         `json:"action"`
+	WorkspaceID *string        `json:"workspace_id"`
+	Details     map[string]any `json:"details"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// AuditLogResponse represents the response for GET /admin/audit.
+type AuditLogResponse struct {
+	Entries []AuditEntryResponse `json:"entries"`
+}
+
+// ToAuditEntryResponse converts a domain.AuditEntry to AuditEntryResponse.
+func ToAuditEntryResponse(entry *domain.AuditEntry) AuditEntryResponse {
+	return AuditEntryResponse{
+		ID:          entry.ID,
+		Action:      string(entry.Action),
+		WorkspaceID: entry.WorkspaceID,
+		Details:     entry.Details,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
+
+// QueueResponse represents a task queue.
+type QueueResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QueuesListResponse represents the response for GET /queues.
+type QueuesListResponse struct {
+	Queues []QueueResponse `json:"queues"`
+}
+
+// ViewResponse represents a saved view.
+type ViewResponse struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Params    map[string]string `json:"params"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ViewsListResponse represents the response for GET /views.
+type ViewsListResponse struct {
+	Views []ViewResponse `json:"views"`
+}
+
+// ToViewResponse converts domain.SavedView to ViewResponse.
+func ToViewResponse(view *domain.SavedView) ViewResponse {
+	return ViewResponse{
+		ID:        view.ID,
+		Name:      view.Name,
+		Params:    view.Params,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+}
+
+// ToQueueResponse converts domain.Queue to QueueResponse.
+func ToQueueResponse(queue *domain.Queue) QueueResponse {
+	return QueueResponse{
+		ID:          queue.ID,
+		Name:        queue.Name,
+		Description: queue.Description,
+		CreatedAt:   queue.CreatedAt,
+	}
+}
+
+// LabelResponse represents a registered label.
+type LabelResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	Description string    `json:"description"`
+	UsageCount  int       `json:"usage_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// LabelsListResponse represents the response for GET /labels.
+type LabelsListResponse struct {
+	Labels []LabelResponse `json:"labels"`
+}
+
+// ToLabelResponse converts domain.Label to LabelResponse.
+func ToLabelResponse(label *domain.Label) LabelResponse {
+	return LabelResponse{
+		ID:          label.ID,
+		Name:        label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+		UsageCount:  label.UsageCount,
+		CreatedAt:   label.CreatedAt,
+		UpdatedAt:   label.UpdatedAt,
+	}
+}
+
+// TaskLabelsResponse represents the response for PUT /tasks/:id/labels.
+// Event is null when the task already had exactly these labels.
+type TaskLabelsResponse struct {
+	Labels []string           `json:"labels"`
+	Event  *TaskEventResponse `json:"event"`
+}
+
+// revisionDiffContext is the number of unchanged lines shown around revision changes.
+const revisionDiffContext = 3
+
+// TaskRevisionResponse represents one version of a task's title and description.
+// The diffs are unified line diffs against the previous revision, omitted when unchanged.
+type TaskRevisionResponse struct {
+	Revision        int       `json:"revision"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	AuthorID        string    `json:"author_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	TitleDiff       string    `json:"title_diff,omitempty"`
+	DescriptionDiff string    `json:"description_diff,omitempty"`
+}
+
+// TaskRevisionsResponse represents the response for GET /tasks/:id/revisions.
+type TaskRevisionsResponse struct {
+	TaskID    string                 `json:"task_id"`
+	Revisions []TaskRevisionResponse `json:"revisions"`
+}
+
+// ToTaskRevisionsResponse converts revisions, oldest first, diffing each against its predecessor.
+func ToTaskRevisionsResponse(taskID string, revisions []*domain.TaskRevision) TaskRevisionsResponse {
+	response := TaskRevisionsResponse{
+		TaskID:    taskID,
+		Revisions: make([]TaskRevisionResponse, len(revisions)),
+	}
+	for i, revision := range revisions {
+		response.Revisions[i] = TaskRevisionResponse{
+			Revision:    revision.Revision,
+			Title:       revision.Title,
+			Description: revision.Description,
+			AuthorID:    revision.AuthorID,
+			CreatedAt:   revision.CreatedAt,
+		}
+		if i > 0 {
+			previous := revisions[i-1]
+			response.Revisions[i].TitleDiff = domain.UnifiedDiff(previous.Title, revision.Title, revisionDiffContext)
+			response.Revisions[i].DescriptionDiff = domain.UnifiedDiff(previous.Description, revision.Description, revisionDiffContext)
+		}
+	}
+	return response
+}
+
+// EditTaskResponse represents the response for PATCH /tasks/:id.
+// Revision and event are null when the request changed nothing.
+type EditTaskResponse struct {
+	Revision *int               `json:"revision"`
+	Event    *TaskEventResponse `json:"event"`
+}
+
+// ScheduleResponse represents a recurring task schedule.
+type ScheduleResponse struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Cron       string               `json:"cron"`
+	Timezone   string               `json:"timezone"`
+	Enabled    bool                 `json:"enabled"`
+	CreatorID  string               `json:"creator_id"`
+	Task       ScheduleTaskTemplate `json:"task"`
+	NextRunAt  time.Time            `json:"next_run_at"`
+	LastRunAt  *time.Time           `json:"last_run_at"`
+	LastTaskID *string              `json:"last_task_id"`
+	LastError  *string              `json:"last_error"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// SchedulesListResponse represents the response for GET /schedules.
+type SchedulesListResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+}
+
+// ToScheduleResponse converts domain.Schedule to ScheduleResponse.
+func ToScheduleResponse(schedule *domain.Schedule) ScheduleResponse {
+	template := schedule.Template
+	return ScheduleResponse{
+		ID:        schedule.ID,
+		Name:      schedule.Name,
+		Cron:      schedule.CronExpr,
+		Timezone:  schedule.Timezone,
+		Enabled:   schedule.Enabled,
+		CreatorID: schedule.CreatorID,
+		Task: ScheduleTaskTemplate{
+			Title:                template.Title,
+			Description:          template.Description,
+			AssigneeID:           template.AssigneeID,
+			Visibility:           string(template.Visibility),
+			Priority:             string(template.Priority),
+			RequiredCapabilities: template.RequiredCapabilities,
+			Queue:                template.Queue,
+		},
+		NextRunAt:  schedule.NextRunAt,
+		LastRunAt:  schedule.LastRunAt,
+		LastTaskID: schedule.LastTaskID,
+		LastError:  schedule.LastError,
+		CreatedAt:  schedule.CreatedAt,
+		UpdatedAt:  schedule.UpdatedAt,
+	}
+}
+
+// ReportResponse represents a scheduled report.
+type ReportResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	Format     string     `json:"format"`
+	Period     string     `json:"period"`
+	Cron       string     `json:"cron"`
+	Timezone   string     `json:"timezone"`
+	TargetType string     `json:"target_type"`
+	Target     string     `json:"target"`
+	Enabled    bool       `json:"enabled"`
+	CreatorID  string     `json:"creator_id"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastError  *string    `json:"last_error"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ReportsListResponse represents the response for GET /reports.
+type ReportsListResponse struct {
+	Reports []ReportResponse `json:"reports"`
+}
+
+// ToReportResponse converts domain.Report to ReportResponse.
+func ToReportResponse(report *domain.Report) ReportResponse {
+	return ReportResponse{
+		ID:         report.ID,
+		Name:       report.Name,
+		Kind:       string(report.Kind),
+		Format:     string(report.Format),
+		Period:     string(report.Period),
+		Cron:       report.CronExpr,
+		Timezone:   report.Timezone,
+		TargetType: string(report.TargetType),
+		Target:     report.Target,
+		Enabled:    report.Enabled,
+		CreatorID:  report.CreatorID,
+		NextRunAt:  report.NextRunAt,
+		LastRunAt:  report.LastRunAt,
+		LastError:  report.LastError,
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+	}
+}
+
+// ClaimNextResponse represents the response for POST /tasks/claim-next.
+type ClaimNextResponse struct {
+	Task  TaskDetail        `json:"task"`
+	Event TaskEventResponse `json:"event"`
+}
+
+// WaitForTaskResponse represents the response for GET /tasks/wait.
+type WaitForTaskResponse struct {
+	Task TaskDetail `json:"task"`
+}
+
+// ImportRowErrorResponse is a row of an import that failed validation.
+type ImportRowErrorResponse struct {
+	Row   int    `json:"row"` // CSV/NDJSON line, or 1-based index in a JSON array
+	Error string `json:"error"`
+}
+
+// ImportTasksResponse represents the response for POST /tasks/import.
+type ImportTasksResponse struct {
+	DryRun   bool                     `json:"dry_run"`
+	Rows     int                      `json:"rows"`
+	Valid    int                      `json:"valid"`
+	Imported int                      `json:"imported"`
+	TaskIDs  []string                 `json:"task_ids"`
+	Errors   []ImportRowErrorResponse `json:"errors"`
 }
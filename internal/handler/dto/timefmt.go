@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// Time formats accepted by the time_format query parameter.
+const (
+	TimeFormatAbsolute = "absolute" // RFC 3339 timestamps only (default)
+	TimeFormatRelative = "relative" // timestamps plus *_relative strings such as "2h ago"
+)
+
+// FormatDuration renders d in its largest whole unit: "45s", "35m", "2h", "3d".
+// Hours are kept up to two days so "36h" stays more precise than "1d".
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// RelativeTime describes t as seen from now: "2h ago", "in 35m" or "just now".
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d > -time.Minute && d < time.Minute:
+		return "just now"
+	case d > 0:
+		return FormatDuration(d) + " ago"
+	default:
+		return "in " + FormatDuration(d)
+	}
+}
+
+// RelativeDeadline describes a deadline as seen from now: "due in 35m" or "overdue by 2h".
+func RelativeDeadline(deadline, now time.Time) string {
+	if deadline.After(now) {
+		return "due in " + FormatDuration(deadline.Sub(now))
+	}
+	return "overdue by " + FormatDuration(now.Sub(deadline))
+}
+
+// relativeDeadline is RelativeDeadline for an optional deadline.
+func relativeDeadline(deadline *time.Time, now time.Time) *string {
+	if deadline == nil {
+		return nil
+	}
+	s := RelativeDeadline(*deadline, now)
+	return &s
+}
+
+// AddRelativeTimes fills the *_relative fields of a list item.
+func (t *TaskListResponse) AddRelativeTimes(now time.Time) {
+	t.CreatedAtRelative = RelativeTime(t.CreatedAt, now)
+	t.UpdatedAtRelative = RelativeTime(t.UpdatedAt, now)
+	t.StatusDeadlineRelative = relativeDeadline(t.StatusDeadlineAt, now)
+}
+
+// AddRelativeTimes fills the *_relative fields of a task detail.
+func (t *TaskDetail) AddRelativeTimes(now time.Time) {
+	t.CreatedAtRelative = RelativeTime(t.CreatedAt, now)
+	t.UpdatedAtRelative = RelativeTime(t.UpdatedAt, now)
+	t.StatusDeadlineRelative = relativeDeadline(t.StatusDeadlineAt, now)
+}
+
+// AddRelativeTimes fills the *_relative fields of an event.
+func (e *TaskEventInfo) AddRelativeTimes(now time.Time) {
+	e.CreatedAtRelative = RelativeTime(e.CreatedAt, now)
+}
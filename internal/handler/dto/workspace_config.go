@@ -0,0 +1,452 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of a workspace configuration document.
+const (
+	ConfigFormatJSON = "json" // default
+	ConfigFormatYAML = "yaml"
+)
+
+// WorkspaceConfigDocument is the configuration of a workspace, as exported by
+// GET and imported by PUT /admin/workspaces/:workspace_id/config. Agents are
+// referred to by name. Omitted sections are left alone on import.
+type WorkspaceConfigDocument struct {
+	Version          int                      `json:"version" yaml:"version"`
+	Workspace        string                   `json:"workspace,omitempty" yaml:"workspace,omitempty"` // slug of the exported workspace, informational
+	Settings         *WorkspaceSettingsConfig `json:"settings,omitempty" yaml:"settings,omitempty"`
+	Labels           []LabelConfig            `json:"labels" yaml:"labels"`
+	Queues           []QueueConfig            `json:"queues" yaml:"queues"`
+	EscalationRoutes []EscalationRouteConfig  `json:"escalation_routes" yaml:"escalation_routes"`
+	Schedules        []ScheduleConfig         `json:"schedules" yaml:"schedules"`
+	Reports          []ReportConfig           `json:"reports" yaml:"reports"`
+}
+
+// WorkspaceSettingsConfig holds the workspace's own settings. On import the
+// whole section applies: omitted settings take their defaults.
+type WorkspaceSettingsConfig struct {
+	StatusDeadlines        map[string]int        `json:"status_deadlines" yaml:"status_deadlines"` // status -> minutes
+	AutoAssignStrategy     string                `json:"auto_assign_strategy" yaml:"auto_assign_strategy"`
+	PriorityInheritance    bool                  `json:"priority_inheritance" yaml:"priority_inheritance"`
+	AutoUnblock            bool                  `json:"auto_unblock" yaml:"auto_unblock"`
+	AgentStaleAfterSeconds int                   `json:"agent_stale_after_seconds" yaml:"agent_stale_after_seconds"`
+	DoneValidation         *DoneValidationConfig `json:"done_validation" yaml:"done_validation"` // null when off
+	PriorityAging          *PriorityAgingConfig  `json:"priority_aging" yaml:"priority_aging"`   // null when off
+	ClaimFairness          ClaimFairnessConfig   `json:"claim_fairness" yaml:"claim_fairness"`
+	DeadlineWarningPercent int                   `json:"deadline_warning_percent" yaml:"deadline_warning_percent"` // 0 when off
+	DeadlineExpiry         map[string]string     `json:"deadline_expiry" yaml:"deadline_expiry"`                   // status -> status entered on expiry; STUCK if left out
+	MaxAttempts            int                   `json:"max_attempts" yaml:"max_attempts"`                         // 0 for no limit
+	EventWebhook           *EventWebhookConfig   `json:"event_webhook" yaml:"event_webhook"`                       // null when off
+	EventBroker            *EventBrokerConfig    `json:"event_broker" yaml:"event_broker"`                         // null when off
+}
+
+// DoneValidationConfig is the webhook approving moves to DONE.
+type DoneValidationConfig struct {
+	URL            string `json:"url" yaml:"url"`
+	TimeoutSeconds int    `json:"timeout_seconds" yaml:"timeout_seconds"`
+	FailOpen       bool   `json:"fail_open" yaml:"fail_open"`
+}
+
+// EventWebhookConfig is the webhook receiving task events.
+type EventWebhookConfig struct {
+	URL        string   `json:"url" yaml:"url"`
+	EventTypes []string `json:"event_types" yaml:"event_types"` // empty for all
+}
+
+// EventBrokerConfig is the broker topic receiving task events.
+type EventBrokerConfig struct {
+	Topic      string   `json:"topic" yaml:"topic"`
+	EventTypes []string `json:"event_types" yaml:"event_types"` // empty for all
+}
+
+// PriorityAgingConfig is the policy for NEW tasks left unclaimed.
+type PriorityAgingConfig struct {
+	AfterSeconds int    `json:"after_seconds" yaml:"after_seconds"`
+	Action       string `json:"action" yaml:"action"`
+}
+
+// ClaimFairnessConfig holds the claim limits; zero values impose none.
+type ClaimFairnessConfig struct {
+	Quota         int  `json:"quota" yaml:"quota"`
+	WindowSeconds int  `json:"window_seconds" yaml:"window_seconds"`
+	TakeTurns     bool `json:"take_turns" yaml:"take_turns"`
+}
+
+// LabelConfig is a registered label.
+type LabelConfig struct {
+	Name        string `json:"name" yaml:"name"`
+	Color       string `json:"color,omitempty" yaml:"color,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// QueueConfig is a task queue.
+type QueueConfig struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// EscalationRouteConfig is an escalation route; routes are evaluated in order.
+type EscalationRouteConfig struct {
+	Name       string  `json:"name" yaml:"name"`
+	Label      *string `json:"label,omitempty" yaml:"label,omitempty"`
+	Priority   *string `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Creator    *string `json:"creator,omitempty" yaml:"creator,omitempty"` // agent name
+	TargetType string  `json:"target_type" yaml:"target_type"`
+	Target     string  `json:"target" yaml:"target"` // agent name, URL or comma-separated addresses
+}
+
+// ScheduleConfig is a recurring task schedule.
+type ScheduleConfig struct {
+	Name     string             `json:"name" yaml:"name"`
+	Creator  string             `json:"creator" yaml:"creator"` // agent name
+	Cron     string             `json:"cron" yaml:"cron"`
+	Timezone string             `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Enabled  bool               `json:"enabled" yaml:"enabled"`
+	Task     TaskTemplateConfig `json:"task" yaml:"task"`
+}
+
+// TaskTemplateConfig is the task a schedule creates.
+type TaskTemplateConfig struct {
+	Title                string   `json:"title" yaml:"title"`
+	Description          string   `json:"description" yaml:"description"`
+	Priority             string   `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Visibility           string   `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	Assignee             *string  `json:"assignee,omitempty" yaml:"assignee,omitempty"` // agent name
+	RequiredCapabilities []string `json:"required_capabilities,omitempty" yaml:"required_capabilities,omitempty"`
+	Queue                *string  `json:"queue,omitempty" yaml:"queue,omitempty"`
+}
+
+// ReportConfig is a scheduled report.
+type ReportConfig struct {
+	Name       string `json:"name" yaml:"name"`
+	Creator    string `json:"creator" yaml:"creator"` // agent name
+	Kind       string `json:"kind" yaml:"kind"`
+	Format     string `json:"format,omitempty" yaml:"format,omitempty"`
+	Period     string `json:"period,omitempty" yaml:"period,omitempty"`
+	Cron       string `json:"cron" yaml:"cron"`
+	Timezone   string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	TargetType string `json:"target_type" yaml:"target_type"`
+	Target     string `json:"target" yaml:"target"`
+	Enabled    bool   `json:"enabled" yaml:"enabled"`
+}
+
+// ConfigImportCountsResponse tells what an import did to one section.
+type ConfigImportCountsResponse struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Deleted   int `json:"deleted,omitempty"`
+}
+
+// ConfigImportResponse represents the response of PUT /admin/workspaces/:workspace_id/config.
+type ConfigImportResponse struct {
+	WorkspaceID      string                     `json:"workspace_id"`
+	SettingsChanged  bool                       `json:"settings_changed"`
+	Labels           ConfigImportCountsResponse `json:"labels"`
+	Queues           ConfigImportCountsResponse `json:"queues"`
+	EscalationRoutes ConfigImportCountsResponse `json:"escalation_routes"`
+	Schedules        ConfigImportCountsResponse `json:"schedules"`
+	Reports          ConfigImportCountsResponse `json:"reports"`
+}
+
+// WriteWorkspaceConfig writes a configuration document in the given format.
+func WriteWorkspaceConfig(w io.Writer, doc WorkspaceConfigDocument, format string) error {
+	switch format {
+	case ConfigFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("encode config: %w", err)
+		}
+		return nil
+	case ConfigFormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("encode config: %w", err)
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown config format %q", format)
+	}
+}
+
+// ReadWorkspaceConfig reads a configuration document in the given format.
+// Unknown fields are rejected, so a misspelt setting is not silently ignored.
+func ReadWorkspaceConfig(r io.Reader, format string) (WorkspaceConfigDocument, error) {
+	var doc WorkspaceConfigDocument
+	switch format {
+	case ConfigFormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return doc, err
+		}
+	case ConfigFormatYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&doc); err != nil {
+			return doc, err
+		}
+	default:
+		return doc, fmt.Errorf("unknown config format %q", format)
+	}
+
+	if doc.Version != domain.WorkspaceConfigVersion {
+		return doc, fmt.Errorf("unsupported config version %d, expected %d", doc.Version, domain.WorkspaceConfigVersion)
+	}
+	return doc, nil
+}
+
+// ToWorkspaceConfigDocument converts an exported configuration to its document form.
+func ToWorkspaceConfigDocument(workspace *domain.Workspace, cfg *domain.WorkspaceConfig) WorkspaceConfigDocument {
+	doc := WorkspaceConfigDocument{
+		Version:          domain.WorkspaceConfigVersion,
+		Workspace:        workspace.Slug,
+		Labels:           make([]LabelConfig, len(cfg.Labels)),
+		Queues:           make([]QueueConfig, len(cfg.Queues)),
+		EscalationRoutes: make([]EscalationRouteConfig, len(cfg.EscalationRoutes)),
+		Schedules:        make([]ScheduleConfig, len(cfg.Schedules)),
+		Reports:          make([]ReportConfig, len(cfg.Reports)),
+	}
+
+	if settings := cfg.Settings; settings != nil {
+		doc.Settings = &WorkspaceSettingsConfig{
+			StatusDeadlines:        settings.StatusDeadlines,
+			AutoAssignStrategy:     string(settings.AutoAssignStrategy),
+			PriorityInheritance:    settings.PriorityInheritance,
+			AutoUnblock:            settings.AutoUnblock,
+			AgentStaleAfterSeconds: settings.AgentStaleAfterSeconds,
+			DeadlineWarningPercent: settings.DeadlineWarningPercent,
+			DeadlineExpiry:         settings.DeadlineExpiry,
+			MaxAttempts:            settings.MaxAttempts,
+			ClaimFairness: ClaimFairnessConfig{
+				Quota:         settings.ClaimFairness.Quota,
+				WindowSeconds: int(settings.ClaimFairness.Window / time.Second),
+				TakeTurns:     settings.ClaimFairness.TakeTurns,
+			},
+		}
+		if hook := settings.DoneValidation; hook != nil {
+			doc.Settings.DoneValidation = &DoneValidationConfig{
+				URL:            hook.URL,
+				TimeoutSeconds: int(hook.Timeout / time.Second),
+				FailOpen:       hook.FailOpen,
+			}
+		}
+		if aging := settings.PriorityAging; aging != nil {
+			doc.Settings.PriorityAging = &PriorityAgingConfig{
+				AfterSeconds: int(aging.After / time.Second),
+				Action:       string(aging.Action),
+			}
+		}
+		if hook := settings.EventWebhook; hook != nil {
+			doc.Settings.EventWebhook = &EventWebhookConfig{URL: hook.URL, EventTypes: make([]string, len(hook.EventTypes))}
+			for i, eventType := range hook.EventTypes {
+				doc.Settings.EventWebhook.EventTypes[i] = string(eventType)
+			}
+		}
+		if topic := settings.EventBroker; topic != nil {
+			doc.Settings.EventBroker = &EventBrokerConfig{Topic: topic.Topic, EventTypes: make([]string, len(topic.EventTypes))}
+			for i, eventType := range topic.EventTypes {
+				doc.Settings.EventBroker.EventTypes[i] = string(eventType)
+			}
+		}
+	}
+
+	for i, label := range cfg.Labels {
+		doc.Labels[i] = LabelConfig{Name: label.Name, Color: label.Color, Description: label.Description}
+	}
+	for i, queue := range cfg.Queues {
+		doc.Queues[i] = QueueConfig{Name: queue.Name, Description: queue.Description}
+	}
+	for i, route := range cfg.EscalationRoutes {
+		doc.EscalationRoutes[i] = EscalationRouteConfig{
+			Name:       route.Name,
+			Label:      route.Label,
+			Creator:    route.CreatorID,
+			TargetType: string(route.TargetType),
+			Target:     route.Target,
+		}
+		if route.Priority != nil {
+			priority := string(*route.Priority)
+			doc.EscalationRoutes[i].Priority = &priority
+		}
+	}
+	for i, schedule := range cfg.Schedules {
+		template := schedule.Template
+		doc.Schedules[i] = ScheduleConfig{
+			Name:     schedule.Name,
+			Creator:  schedule.CreatorID,
+			Cron:     schedule.CronExpr,
+			Timezone: schedule.Timezone,
+			Enabled:  schedule.Enabled,
+			Task: TaskTemplateConfig{
+				Title:                template.Title,
+				Description:          template.Description,
+				Priority:             string(template.Priority),
+				Visibility:           string(template.Visibility),
+				Assignee:             template.AssigneeID,
+				RequiredCapabilities: template.RequiredCapabilities,
+				Queue:                template.Queue,
+			},
+		}
+	}
+	for i, report := range cfg.Reports {
+		doc.Reports[i] = ReportConfig{
+			Name:       report.Name,
+			Creator:    report.CreatorID,
+			Kind:       string(report.Kind),
+			Format:     string(report.Format),
+			Period:     string(report.Period),
+			Cron:       report.CronExpr,
+			Timezone:   report.Timezone,
+			TargetType: string(report.TargetType),
+			Target:     report.Target,
+			Enabled:    report.Enabled,
+		}
+	}
+
+	return doc
+}
+
+// ToDomain converts the document to domain.WorkspaceConfig. Sections missing
+// from the document stay nil.
+func (d WorkspaceConfigDocument) ToDomain() *domain.WorkspaceConfig {
+	cfg := &domain.WorkspaceConfig{}
+
+	if settings := d.Settings; settings != nil {
+		cfg.Settings = &domain.WorkspaceSettings{
+			StatusDeadlines:        settings.StatusDeadlines,
+			AutoAssignStrategy:     domain.AutoAssignStrategy(settings.AutoAssignStrategy),
+			PriorityInheritance:    settings.PriorityInheritance,
+			AutoUnblock:            settings.AutoUnblock,
+			AgentStaleAfterSeconds: settings.AgentStaleAfterSeconds,
+			DeadlineWarningPercent: settings.DeadlineWarningPercent,
+			DeadlineExpiry:         settings.DeadlineExpiry,
+			MaxAttempts:            settings.MaxAttempts,
+			ClaimFairness: domain.ClaimFairness{
+				Quota:     settings.ClaimFairness.Quota,
+				Window:    time.Duration(settings.ClaimFairness.WindowSeconds) * time.Second,
+				TakeTurns: settings.ClaimFairness.TakeTurns,
+			},
+		}
+		if hook := settings.DoneValidation; hook != nil {
+			cfg.Settings.DoneValidation = &domain.DoneValidationHook{
+				URL:      hook.URL,
+				Timeout:  time.Duration(hook.TimeoutSeconds) * time.Second,
+				FailOpen: hook.FailOpen,
+			}
+		}
+		if aging := settings.PriorityAging; aging != nil {
+			cfg.Settings.PriorityAging = &domain.PriorityAging{
+				After:  time.Duration(aging.AfterSeconds) * time.Second,
+				Action: domain.PriorityAgingAction(aging.Action),
+			}
+		}
+		if hook := settings.EventWebhook; hook != nil {
+			cfg.Settings.EventWebhook = &domain.EventWebhook{URL: hook.URL, EventTypes: make([]domain.EventType, len(hook.EventTypes))}
+			for i, eventType := range hook.EventTypes {
+				cfg.Settings.EventWebhook.EventTypes[i] = domain.EventType(eventType)
+			}
+		}
+		if topic := settings.EventBroker; topic != nil {
+			cfg.Settings.EventBroker = &domain.EventBrokerTopic{Topic: topic.Topic, EventTypes: make([]domain.EventType, len(topic.EventTypes))}
+			for i, eventType := range topic.EventTypes {
+				cfg.Settings.EventBroker.EventTypes[i] = domain.EventType(eventType)
+			}
+		}
+	}
+
+	if d.Labels != nil {
+		cfg.Labels = make([]*domain.Label, len(d.Labels))
+		for i, label := range d.Labels {
+			cfg.Labels[i] = &domain.Label{Name: label.Name, Color: label.Color, Description: label.Description}
+		}
+	}
+	if d.Queues != nil {
+		cfg.Queues = make([]*domain.Queue, len(d.Queues))
+		for i, queue := range d.Queues {
+			cfg.Queues[i] = &domain.Queue{Name: queue.Name, Description: queue.Description}
+		}
+	}
+	if d.EscalationRoutes != nil {
+		cfg.EscalationRoutes = make([]*domain.EscalationRoute, len(d.EscalationRoutes))
+		for i, route := range d.EscalationRoutes {
+			cfg.EscalationRoutes[i] = &domain.EscalationRoute{
+				Name:       route.Name,
+				Label:      route.Label,
+				CreatorID:  route.Creator,
+				TargetType: domain.EscalationTargetType(route.TargetType),
+				Target:     route.Target,
+			}
+			if route.Priority != nil {
+				priority := domain.TaskPriority(*route.Priority)
+				cfg.EscalationRoutes[i].Priority = &priority
+			}
+		}
+	}
+	if d.Schedules != nil {
+		cfg.Schedules = make([]*domain.Schedule, len(d.Schedules))
+		for i, schedule := range d.Schedules {
+			cfg.Schedules[i] = &domain.Schedule{
+				CreatorID: schedule.Creator,
+				Name:      schedule.Name,
+				CronExpr:  schedule.Cron,
+				Timezone:  schedule.Timezone,
+				Enabled:   schedule.Enabled,
+				Template: domain.TaskTemplate{
+					Title:                schedule.Task.Title,
+					Description:          schedule.Task.Description,
+					Priority:             domain.TaskPriority(schedule.Task.Priority),
+					Visibility:           domain.TaskVisibility(schedule.Task.Visibility),
+					AssigneeID:           schedule.Task.Assignee,
+					RequiredCapabilities: schedule.Task.RequiredCapabilities,
+					Queue:                schedule.Task.Queue,
+				},
+			}
+		}
+	}
+	if d.Reports != nil {
+		cfg.Reports = make([]*domain.Report, len(d.Reports))
+		for i, report := range d.Reports {
+			cfg.Reports[i] = &domain.Report{
+				CreatorID:  report.Creator,
+				Name:       report.Name,
+				Kind:       domain.ReportKind(report.Kind),
+				Format:     domain.ReportFormat(report.Format),
+				Period:     domain.ReportPeriod(report.Period),
+				CronExpr:   report.Cron,
+				Timezone:   report.Timezone,
+				TargetType: domain.ReportTargetType(report.TargetType),
+				Target:     report.Target,
+				Enabled:    report.Enabled,
+			}
+		}
+	}
+
+	return cfg
+}
+
+// ToConfigImportResponse converts an import result to its response form.
+func ToConfigImportResponse(workspaceID string, result *domain.ConfigImportResult) ConfigImportResponse {
+	counts := func(c domain.ConfigImportCounts) ConfigImportCountsResponse {
+		return ConfigImportCountsResponse{Created: c.Created, Updated: c.Updated, Unchanged: c.Unchanged, Deleted: c.Deleted}
+	}
+	return ConfigImportResponse{
+		WorkspaceID:      workspaceID,
+		SettingsChanged:  result.SettingsChanged,
+		Labels:           counts(result.Labels),
+		Queues:           counts(result.Queues),
+		EscalationRoutes: counts(result.EscalationRoutes),
+		Schedules:        counts(result.Schedules),
+		Reports:          counts(result.Reports),
+	}
+}
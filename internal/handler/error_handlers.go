@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// handleListErrors lists the API's error codes.
+// @Summary List error codes
+// @Description Machine-readable catalog of every error code: the HTTP statuses it comes with, what it means, what to do next, and the keys of error.details it carries. No authentication.
+// @Tags errors
+// @Produce json
+// @Success 200 {object} dto.ErrorCatalogResponse
+// @Router /errors [get]
+func (h *Handler) handleListErrors(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, dto.ErrorCatalogResponse{Errors: dto.ErrorCatalog})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+// handleListEscalations lists the escalations routed to the calling agent.
+// @Summary List my escalations
+// @Description Escalations routed to the calling agent by the workspace's escalation routes, or because the agent created the escalated task and no route matched. Newest first.
+// @Tags escalations
+// @Produce json
+// @Param limit query int false "Page size (1-200, default 50)"
+// @Param offset query int false "Page offset (default 0)"
+// @Success 200 {object} dto.EscalationsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /escalations [get]
+func (h *Handler) handleListEscalations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 50
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	notifications, total, err := h.escalationService.ListAgentEscalations(ctx, agent.ID, limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch escalations")
+		return
+	}
+
+	response := dto.EscalationsResponse{
+		Escalations: make([]dto.EscalationInfo, len(notifications)),
+		Total:       total,
+		Limit:       limit,
+		Offset:      offset,
+	}
+	for i, notification := range notifications {
+		response.Escalations[i] = dto.ToEscalationInfo(notification)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleGetEscalationRoutes lists a workspace's escalation routes.
+// @Summary Get escalation routes
+// @Description The workspace's escalation routing rules in evaluation order
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.EscalationRoutesResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/escalation-routes [get]
+func (h *Handler) handleGetEscalationRoutes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	routes, err := h.escalationService.ListRoutes(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToEscalationRoutesResponse(routes))
+}
+
+// handleSetEscalationRoutes replaces a workspace's escalation routes.
+// @Summary Set escalation routes
+// @Description Replace the workspace's escalation routing rules. On every escalation the rules are evaluated in the given order and the first whose criteria (label, priority, creator_id; all optional) all match the task decides who is notified: an agent (GET /escalations), a webhook or an email list. Without a match the task's creator is notified. Webhook and email notifications are sent by the scheduler worker.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetEscalationRoutesRequest true "Routes in evaluation order"
+// @Success 200 {object} dto.EscalationRoutesResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/escalation-routes [put]
+func (h *Handler) handleSetEscalationRoutes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetEscalationRoutesRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	routes := make([]*domain.EscalationRoute, len(req.Routes))
+	for i, route := range req.Routes {
+		routes[i] = route.ToDomain()
+	}
+
+	routes, err := h.escalationService.SetRoutes(ctx, workspaceID, routes)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	names := make([]string, len(routes))
+	for i, route := range routes {
+		names[i] = route.Name
+	}
+	h.recordAudit(ctx, domain.AuditEscalationRoutes, &workspaceID, map[string]any{"routes": names})
+
+	respondJSON(w, http.StatusOK, dto.ToEscalationRoutesResponse(routes))
+}
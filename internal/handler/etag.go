@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// weakETag returns a weak ETag identifying the state a response shows rather
+// than its bytes: server_time and deadline_in_seconds change every second and
+// would defeat it.
+func weakETag(parts ...any) string {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	for _, part := range parts {
+		// Values from the database always encode
+		_ = encoder.Encode(part)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// notModified sets the ETag of the response and answers 304 Not Modified if
+// the request's If-None-Match names it. Returns true if the 304 was sent.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !matchesETag(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matchesETag reports whether an If-None-Match header names etag, comparing
+// weakly as RFC 9110 requires for it.
+func matchesETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// taskVersionHeader carries the task's version on GET /tasks/{id}, to send
+// back in If-Match without reading it from the body. The ETag doesn't serve:
+// it hashes the whole response, events and checklist included.
+const taskVersionHeader = "X-Sloptask-Task-Version"
+
+// expectedTaskVersion returns the task version a write requires, from the
+// If-Match header, a quoted or bare version such as "3", or from the body's
+// expected_version. Responds 428 and returns false if neither names a version
+// ("*" names none), and 422 if the header is malformed or disagrees with the
+// body.
+func expectedTaskVersion(w http.ResponseWriter, r *http.Request, fromBody *int) (*int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		if fromBody == nil {
+			respondError(w, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED", "Send the task version you read as If-Match or expected_version")
+			return nil, false
+		}
+		return fromBody, true
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "If-Match takes the task version from the version field or the "+taskVersionHeader+" header, not the ETag")
+		return nil, false
+	}
+	if fromBody != nil && *fromBody != version {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "If-Match and expected_version disagree")
+		return nil, false
+	}
+	return &version, true
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// handleListTaskEvents returns a page of the task's event history.
+// @Summary List task events
+// @Description Get paginated event history of a task, optionally filtered by event type. Replies carry reply_to_event_id and the thread_id of the comment that started their thread; pass thread to read one thread.
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param type query string false "Comma-separated event types: commented,status_changed"
+// @Param thread query string false "Only this comment and the replies in its thread"
+// @Param limit query int false "Page size (1-200, default 50)"
+// @Param offset query int false "Page offset (default 0)"
+// @Param time_format query string false "absolute (default) or relative: adds created_at_relative such as '2h ago'"
+// @Success 200 {object} dto.TaskEventsListResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/events [get]
+func (h *Handler) handleListTaskEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	relativeTimes, ok := parseRelativeTimeFormat(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Parse event types (comma-separated)
+	var types []domain.EventType
+	if typeParam := query.Get("type"); typeParam != "" {
+		for _, t := range splitAndTrim(typeParam, ",") {
+			eventType := domain.EventType(t)
+			if !eventType.IsValid() {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "invalid event type: "+t)
+				return
+			}
+			types = append(types, eventType)
+		}
+	}
+
+	var thread *string
+	if threadParam := query.Get("thread"); threadParam != "" {
+		if _, err := uuid.Parse(threadParam); err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "thread must be a valid UUID")
+			return
+		}
+		thread = &threadParam
+	}
+
+	// Parse pagination
+	limit := 50
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	task, err := h.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if !task.IsVisibleTo(agent) {
+		respondError(w, http.StatusForbidden, "INSUFFICIENT_ACCESS", "Task not found")
+		return
+	}
+
+	events, total, err := h.eventRepo.ListByTaskIDWithActors(ctx, repository.TaskEventListFilters{
+		TaskID: taskID,
+		Types:  types,
+		Thread: thread,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch events")
+		return
+	}
+
+	response := dto.TaskEventsListResponse{
+		Events: toTaskEventInfos(events),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	if relativeTimes {
+		now := time.Now().UTC()
+		for i := range response.Events {
+			response.Events[i].AddRelativeTimes(now)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleListTaskComments returns the task's comments, newest first.
+// @Summary List task comments
+// @Description Get the task's comments without the rest of its event history, newest first. Pass next_cursor from a page as cursor to get the comments before it; next_cursor is null on the last page. Comments posted meanwhile don't shift the pages.
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param limit query int false "Page size (1-200, default 20)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param time_format query string false "absolute (default) or relative: adds created_at_relative such as '2h ago'"
+// @Success 200 {object} dto.TaskCommentsResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments [get]
+func (h *Handler) handleListTaskComments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	relativeTimes, ok := parseRelativeTimeFormat(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 20
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	var before *domain.EventCursor
+	if cursorParam := query.Get("cursor"); cursorParam != "" {
+		cursor, err := domain.ParseEventCursor(cursorParam)
+		if err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "cursor must be a next_cursor returned by this endpoint")
+			return
+		}
+		before = &cursor
+	}
+
+	task, err := h.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if !task.IsVisibleTo(agent) {
+		respondError(w, http.StatusForbidden, "INSUFFICIENT_ACCESS", "Task not found")
+		return
+	}
+
+	// One extra comment tells whether an older page exists
+	comments, total, err := h.eventRepo.ListByTaskIDWithActors(ctx, repository.TaskEventListFilters{
+		TaskID:      taskID,
+		Types:       []domain.EventType{domain.EventTypeCommented},
+		Limit:       limit + 1,
+		NewestFirst: true,
+		Before:      before,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch comments")
+		return
+	}
+
+	var nextCursor *string
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[limit-1]
+		cursor := domain.EventCursor{CreatedAt: last.CreatedAt, ID: last.ID}.String()
+		nextCursor = &cursor
+	}
+
+	response := dto.TaskCommentsResponse{
+		Comments:   toTaskEventInfos(comments),
+		NextCursor: nextCursor,
+		Total:      total,
+		Limit:      limit,
+	}
+	if relativeTimes {
+		now := time.Now().UTC()
+		for i := range response.Comments {
+			response.Comments[i].AddRelativeTimes(now)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// toTaskEventInfos converts repository events with actor names to response format.
+func toTaskEventInfos(events []repository.TaskEventWithActor) []dto.TaskEventInfo {
+	result := make([]dto.TaskEventInfo, len(events))
+	for i, event := range events {
+		var oldStatus, newStatus *string
+		if event.OldStatus != nil {
+			s := string(*event.OldStatus)
+			oldStatus = &s
+		}
+		if event.NewStatus != nil {
+			s := string(*event.NewStatus)
+			newStatus = &s
+		}
+
+		result[i] = dto.TaskEventInfo{
+			ID:         event.ID,
+			Type:       string(event.Type),
+			ActorID:    event.ActorID,
+			ActorName:  event.ActorName,
+			Comment:    event.Comment,
+			Data:       event.Data,
+			OldStatus:  oldStatus,
+			NewStatus:  newStatus,
+			CreatedAt:  event.CreatedAt,
+			EditedAt:   event.EditedAt,
+			RedactedAt: event.RedactedAt,
+
+			ReplyToEventID: event.ReplyToEventID,
+			ThreadID:       event.ThreadID,
+		}
+		if event.Trace != nil {
+			result[i].TraceParent = event.Trace.TraceParent
+			result[i].TraceState = event.Trace.TraceState
+		}
+	}
+	return result
+}
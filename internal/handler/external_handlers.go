@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleAwaitExternal parks the agent's task on an external system.
+// @Summary Await external system
+// @Description Assignee moves an IN_PROGRESS task to AWAITING_EXTERNAL, naming the external item it waits on (system, id, optional URL). The task has no deadline while waiting. Resume with PATCH /status (IN_PROGRESS) or let an integration resolve the reference.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.AwaitExternalRequest true "External reference"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/await-external [post]
+func (h *Handler) handleAwaitExternal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.AwaitExternalRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.AwaitExternal(ctx, service.AwaitExternalParams{
+		TaskID:  taskID,
+		AgentID: agent.ID,
+		Ref: domain.ExternalRef{
+			System: req.System,
+			ID:     req.ExternalID,
+			URL:    req.URL,
+		},
+		Comment: req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleResolveExternal resumes the tasks waiting on an external reference.
+// @Summary Resolve external reference
+// @Description Move every AWAITING_EXTERNAL task of the workspace waiting on the given system and id back to IN_PROGRESS. Meant for operators and integrations (CI, ticket tracker webhooks).
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.ResolveExternalRequest true "External reference"
+// @Success 200 {object} dto.ResolveExternalResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/external/resolve [post]
+func (h *Handler) handleResolveExternal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.ResolveExternalRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	events, err := h.taskService.ResolveExternal(ctx, service.ResolveExternalParams{
+		WorkspaceID: workspaceID,
+		System:      req.System,
+		ExternalID:  req.ExternalID,
+		Comment:     req.Comment,
+		Data:        req.Data,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ResolveExternalResponse{Events: make([]dto.TaskEventResponse, len(events))}
+	for i, event := range events {
+		response.Events[i] = dto.ToTaskEventResponse(event)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// Grafana asks for one point per pixel; buckets never get narrower than a
+// minute and a series never has more than grafanaMaxBuckets points.
+const (
+	grafanaMinInterval = time.Minute
+	grafanaMaxBuckets  = 2000
+)
+
+// grafanaPayloads are the filters every event series accepts.
+var grafanaPayloads = []dto.GrafanaMetricPayload{
+	{Name: "agent_id", Label: "Agent ID", Type: "input"},
+	{Name: "queue", Label: "Queue", Type: "input"},
+	{Name: "label", Label: "Label", Type: "input"},
+}
+
+// grafanaTables lists the table targets of POST /grafana/query.
+var grafanaTables = []string{dto.GrafanaTableAgentStats, dto.GrafanaTableTasksByStatus, dto.GrafanaTableQueueDepth}
+
+// handleGrafanaHealth answers the datasource's connection test.
+// @Summary Grafana datasource health
+// @Description Connection test of the Grafana JSON datasource. Configure the datasource URL as <server>/api/v1/grafana with an Authorization header carrying a read token.
+// @Tags grafana
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Security BearerAuth
+// @Router /grafana [get]
+func (h *Handler) handleGrafanaHealth(w http.ResponseWriter, r *http.Request) {
+	if _, err := middleware.GetWorkspaceIDFromContext(r.Context()); err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleGrafanaSearch lists query targets for the legacy SimpleJSON editor.
+// @Summary Grafana target search
+// @Description List the names of all timeseries and table targets
+// @Tags grafana
+// @Produce json
+// @Success 200 {array} string
+// @Security BearerAuth
+// @Router /grafana/search [post]
+func (h *Handler) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	if _, err := middleware.GetWorkspaceIDFromContext(r.Context()); err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	targets := make([]string, 0, len(repository.EventSeriesNames())+len(grafanaTables))
+	for _, series := range repository.EventSeriesNames() {
+		targets = append(targets, string(series))
+	}
+	targets = append(targets, grafanaTables...)
+
+	respondJSON(w, http.StatusOK, targets)
+}
+
+// handleGrafanaMetrics lists query targets with their payload options.
+// @Summary Grafana metrics
+// @Description List timeseries and table targets with the payload filters each accepts (agent_id, queue, label)
+// @Tags grafana
+// @Produce json
+// @Success 200 {array} dto.GrafanaMetric
+// @Security BearerAuth
+// @Router /grafana/metrics [post]
+func (h *Handler) handleGrafanaMetrics(w http.ResponseWriter, r *http.Request) {
+	if _, err := middleware.GetWorkspaceIDFromContext(r.Context()); err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	metrics := make([]dto.GrafanaMetric, 0, len(repository.EventSeriesNames())+len(grafanaTables))
+	for _, series := range repository.EventSeriesNames() {
+		metrics = append(metrics, dto.GrafanaMetric{Label: string(series), Value: string(series), Payloads: grafanaPayloads})
+	}
+	metrics = append(metrics,
+		dto.GrafanaMetric{Label: dto.GrafanaTableAgentStats, Value: dto.GrafanaTableAgentStats, Payloads: grafanaPayloads[:1]},
+		dto.GrafanaMetric{Label: dto.GrafanaTableTasksByStatus, Value: dto.GrafanaTableTasksByStatus, Payloads: []dto.GrafanaMetricPayload{}},
+		dto.GrafanaMetric{Label: dto.GrafanaTableQueueDepth, Value: dto.GrafanaTableQueueDepth, Payloads: grafanaPayloads[1:2]},
+	)
+
+	respondJSON(w, http.StatusOK, metrics)
+}
+
+// handleGrafanaVariable returns the options of a dashboard variable.
+// @Summary Grafana variable values
+// @Description Values for dashboard variables: agents (name/ID), queues, labels or statuses
+// @Tags grafana
+// @Accept json
+// @Produce json
+// @Param request body dto.GrafanaVariableRequest true "Variable query"
+// @Success 200 {array} dto.GrafanaVariableValue
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /grafana/variable [post]
+func (h *Handler) handleGrafanaVariable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	// Grafana sends fields of its own, so only the size is limited
+	var req dto.GrafanaVariableRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondDecodeError(w, err)
+		return
+	}
+	variable := req.Payload.Target
+	if variable == "" {
+		variable = req.Target
+	}
+
+	values := []dto.GrafanaVariableValue{}
+	switch variable {
+	case dto.GrafanaVariableAgents:
+		workloads, err := h.agentRepo.ListWorkloads(ctx, workspaceID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch agents")
+			return
+		}
+		for _, workload := range workloads {
+			values = append(values, dto.GrafanaVariableValue{Text: workload.Agent.Name, Value: workload.Agent.ID})
+		}
+	case dto.GrafanaVariableQueues:
+		queues, err := h.queueService.ListQueues(ctx, workspaceID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch queues")
+			return
+		}
+		for _, queue := range queues {
+			values = append(values, dto.GrafanaVariableValue{Text: queue.Name, Value: queue.Name})
+		}
+	case dto.GrafanaVariableLabels:
+		labels, err := h.labelService.ListLabels(ctx, workspaceID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch labels")
+			return
+		}
+		for _, label := range labels {
+			values = append(values, dto.GrafanaVariableValue{Text: label.Name, Value: label.Name})
+		}
+	case dto.GrafanaVariableStatuses:
+		for _, status := range []domain.TaskStatus{
+			domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusNeedsReview, domain.TaskStatusBlocked,
+			domain.TaskStatusAwaitingExternal, domain.TaskStatusWaitingApproval, domain.TaskStatusStuck, domain.TaskStatusDone,
+			domain.TaskStatusCancelled,
+		} {
+			values = append(values, dto.GrafanaVariableValue{Text: string(status), Value: string(status)})
+		}
+	default:
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "variable must be one of: agents, queues, labels, statuses")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, values)
+}
+
+// handleGrafanaQuery answers a panel's timeseries and table queries.
+// @Summary Grafana query
+// @Description Run the panel's targets over the dashboard range. Event series (events, tasks_created, tasks_completed, tasks_cancelled, tasks_stuck, escalations, takeovers) return datapoints counted per interval; agent_stats, tasks_by_status and queue_depth return tables. Buckets are at least a minute wide.
+// @Tags grafana
+// @Accept json
+// @Produce json
+// @Param request body dto.GrafanaQueryRequest true "Query"
+// @Success 200 {array} object "dto.GrafanaTimeseries or dto.GrafanaTable per target"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /grafana/query [post]
+func (h *Handler) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	// Grafana sends fields of its own, so only the size is limited
+	var req dto.GrafanaQueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes)).Decode(&req); err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+
+	if req.Range == nil || req.Range.From.IsZero() || !req.Range.From.Before(req.Range.To) {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "range.from must be before range.to")
+		return
+	}
+
+	interval := grafanaInterval(req)
+	results := make([]any, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		var result any
+		switch target.Target {
+		case dto.GrafanaTableAgentStats:
+			result, err = h.grafanaAgentStats(r, workspaceID, req.Range, target)
+		case dto.GrafanaTableTasksByStatus:
+			result, err = h.grafanaTasksByStatus(r, workspaceID, req.Range, target)
+		case dto.GrafanaTableQueueDepth:
+			result, err = h.grafanaQueueDepth(r, workspaceID, target)
+		default:
+			series := repository.EventSeries(target.Target)
+			if !series.IsValid() {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "unknown target: "+target.Target)
+				return
+			}
+			result, err = h.grafanaSeries(r, workspaceID, req.Range, interval, series, target)
+		}
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to query "+target.Target)
+			return
+		}
+		results = append(results, result)
+	}
+
+	respondJSON(w, http.StatusOK, results)
+}
+
+// grafanaInterval picks the bucket width for the request: Grafana's interval,
+// widened to at least a minute and so the range fits the point budget.
+func grafanaInterval(req dto.GrafanaQueryRequest) time.Duration {
+	interval := max(time.Duration(req.IntervalMs)*time.Millisecond, grafanaMinInterval)
+
+	maxBuckets := grafanaMaxBuckets
+	if req.MaxDataPoints > 0 && req.MaxDataPoints < maxBuckets {
+		maxBuckets = req.MaxDataPoints
+	}
+
+	span := req.Range.To.Sub(req.Range.From)
+	if minInterval := span / time.Duration(maxBuckets); interval < minInterval {
+		interval = (minInterval + grafanaMinInterval - 1).Truncate(grafanaMinInterval)
+	}
+
+	return interval
+}
+
+// grafanaSeries counts a target's events per interval.
+func (h *Handler) grafanaSeries(r *http.Request, workspaceID string, rng *dto.GrafanaRange, interval time.Duration, series repository.EventSeries, target dto.GrafanaTarget) (dto.GrafanaTimeseries, error) {
+	points, err := h.taskRepo.GetEventSeries(r.Context(), repository.EventSeriesFilters{
+		WorkspaceID: workspaceID,
+		Series:      series,
+		From:        rng.From,
+		To:          rng.To,
+		Interval:    interval,
+		ActorID:     optionalString(target.Payload.AgentID),
+		Queue:       optionalString(target.Payload.Queue),
+		Label:       optionalString(target.Payload.Label),
+	})
+	if err != nil {
+		return dto.GrafanaTimeseries{}, err
+	}
+
+	result := dto.GrafanaTimeseries{
+		Target:     target.Target,
+		RefID:      target.RefID,
+		Datapoints: make([][2]int64, len(points)),
+	}
+	for i, point := range points {
+		result.Datapoints[i] = [2]int64{int64(point.Count), point.Time.UnixMilli()}
+	}
+
+	return result, nil
+}
+
+// grafanaAgentStats tabulates per-agent statistics over the range.
+func (h *Handler) grafanaAgentStats(r *http.Request, workspaceID string, rng *dto.GrafanaRange, target dto.GrafanaTarget) (dto.GrafanaTable, error) {
+	stats, err := h.taskRepo.GetAgentStats(r.Context(), repository.StatsFilters{
+		WorkspaceID: workspaceID,
+		PeriodStart: rng.From,
+		PeriodEnd:   rng.To,
+		AgentID:     optionalString(target.Payload.AgentID),
+	})
+	if err != nil {
+		return dto.GrafanaTable{}, err
+	}
+
+	table := newGrafanaTable(target,
+		dto.GrafanaColumn{Text: "Agent", Type: "string"},
+		dto.GrafanaColumn{Text: "Completed", Type: "number"},
+		dto.GrafanaColumn{Text: "Cancelled", Type: "number"},
+		dto.GrafanaColumn{Text: "In progress", Type: "number"},
+		dto.GrafanaColumn{Text: "Stuck", Type: "number"},
+		dto.GrafanaColumn{Text: "Avg lead time (min)", Type: "number"},
+		dto.GrafanaColumn{Text: "Avg cycle time (min)", Type: "number"},
+	)
+	for _, stat := range stats {
+		table.Rows = append(table.Rows, []any{
+			stat.AgentName, stat.TasksCompleted, stat.TasksCancelled, stat.TasksInProgress, stat.TasksStuckCount,
+			stat.AvgLeadTimeMinutes, stat.AvgCycleTimeMinutes,
+		})
+	}
+
+	return table, nil
+}
+
+// grafanaTasksByStatus tabulates the current number of tasks per status.
+func (h *Handler) grafanaTasksByStatus(r *http.Request, workspaceID string, rng *dto.GrafanaRange, target dto.GrafanaTarget) (dto.GrafanaTable, error) {
+	stats, err := h.taskRepo.GetWorkspaceStats(r.Context(), repository.StatsFilters{
+		WorkspaceID: workspaceID,
+		PeriodStart: rng.From,
+		PeriodEnd:   rng.To,
+	})
+	if err != nil {
+		return dto.GrafanaTable{}, err
+	}
+
+	table := newGrafanaTable(target,
+		dto.GrafanaColumn{Text: "Status", Type: "string"},
+		dto.GrafanaColumn{Text: "Tasks", Type: "number"},
+	)
+	for _, status := range sortedKeys(stats.TasksByStatus) {
+		table.Rows = append(table.Rows, []any{status, stats.TasksByStatus[status]})
+	}
+
+	return table, nil
+}
+
+// grafanaQueueDepth tabulates the claimable tasks per queue right now.
+func (h *Handler) grafanaQueueDepth(r *http.Request, workspaceID string, target dto.GrafanaTarget) (dto.GrafanaTable, error) {
+	depth, err := h.taskRepo.GetQueueDepth(r.Context(), workspaceID, optionalString(target.Payload.Queue))
+	if err != nil {
+		return dto.GrafanaTable{}, err
+	}
+
+	table := newGrafanaTable(target,
+		dto.GrafanaColumn{Text: "Queue", Type: "string"},
+		dto.GrafanaColumn{Text: "Claimable", Type: "number"},
+	)
+	for _, queue := range sortedKeys(depth.ByQueue) {
+		table.Rows = append(table.Rows, []any{queue, depth.ByQueue[queue]})
+	}
+
+	return table, nil
+}
+
+// newGrafanaTable creates an empty table answering target.
+func newGrafanaTable(target dto.GrafanaTarget, columns ...dto.GrafanaColumn) dto.GrafanaTable {
+	return dto.GrafanaTable{
+		Type:    "table",
+		RefID:   target.RefID,
+		Columns: columns,
+		Rows:    [][]any{},
+	}
+}
+
+// optionalString returns nil for an empty filter value.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// sortedKeys returns the keys of a count map in order.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+// handleGraphQL answers a GraphQL query over tasks.
+// @Summary GraphQL query
+// @Description Read-only GraphQL view of the tasks the caller may see: task(id) and tasks(status, assignee, queue, labels, limit, offset), each task with its events, blockedBy and blocks tasks, so a planner fetches a dependency graph in one request. Queries nest at most 10 levels. Field errors come in errors with 200 OK. The schema is served by introspection.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body dto.GraphQLRequest true "GraphQL query"
+// @Success 200 {object} dto.GraphQLResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /graphql [post]
+func (h *Handler) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := middleware.GetWorkspaceIDFromContext(ctx); err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.GraphQLRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Query == "" {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "query is required")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.graphql.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
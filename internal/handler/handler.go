@@ -3,12 +3,22 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/mtlprog/sloptask/docs" // Import generated docs
+	"github.com/mtlprog/sloptask/internal/config"
+	"github.com/mtlprog/sloptask/internal/coordination"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/graphapi"
 	"github.com/mtlprog/sloptask/internal/handler/dto"
 	"github.com/mtlprog/sloptask/internal/middleware"
 	"github.com/mtlprog/sloptask/internal/repository"
@@ -17,39 +27,167 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// DefaultMaxBodyBytes bounds JSON request bodies unless Config sets a limit.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Config holds optional HTTP layer settings.
+type Config struct {
+	// AdminToken enables the /api/v1/admin endpoints when non-empty. It also
+	// reads any workspace's task list and stats as a coordinator.
+	AdminToken string
+	// ChangeFeed wakes long-polling requests on task changes. Without it they
+	// fall back to polling the database.
+	ChangeFeed *service.ChangeFeed
+	// Runtime holds the settings reloadable without a restart. Without it the
+	// defaults apply and reloads are disabled.
+	Runtime *config.RuntimeStore
+	// AgentCacheTTL is how long a server trusts an agent token it looked up.
+	// Zero looks tokens up on every request.
+	AgentCacheTTL time.Duration
+	// Shared coordinates rate limits and agent cache invalidations between
+	// server replicas. Without it each server keeps them in memory.
+	Shared coordination.Backend
+	// Drain tracks in-flight writes for a graceful shutdown; /healthz fails
+	// while it drains. Without it shutdown doesn't wait for writes.
+	Drain *middleware.Drain
+	// ReadOnly rejects API writes while switched on, from the runtime settings
+	// or PUT /admin/read-only. Without it the switch has no effect.
+	ReadOnly *middleware.ReadOnly
+	// MaxBodyBytes bounds JSON request bodies; DefaultMaxBodyBytes when zero.
+	// Imports, workspace configurations and intake submissions have their own
+	// limits.
+	MaxBodyBytes int64
+}
+
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	pool           *pgxpool.Pool
-	taskService    *service.TaskService
-	taskRepo       *repository.TaskRepository
-	eventRepo      *repository.TaskEventRepository
-	agentRepo      *repository.AgentRepository
-	workspaceRepo  *repository.WorkspaceRepository
-	authMiddleware *middleware.AuthMiddleware
+	pool              *pgxpool.Pool
+	taskService       *service.TaskService
+	readTokenService  *service.ReadTokenService
+	queueService      *service.QueueService
+	scheduleService   *service.ScheduleService
+	reportService     *service.ReportService
+	labelService      *service.LabelService
+	workspaceService  *service.WorkspaceService
+	webhookService    *service.WebhookSecretService
+	escalationService *service.EscalationService
+	outboxService     *service.OutboxService
+	messageService    *service.MessageService
+	watchService      *service.WatchService
+	viewService       *service.ViewService
+	intakeService     *service.IntakeService
+	configService     *service.WorkspaceConfigService
+	changeFeed        *service.ChangeFeed
+	taskRepo          repository.TaskRepository
+	eventRepo         repository.TaskEventRepository
+	agentRepo         repository.AgentRepository
+	workspaceRepo     repository.WorkspaceRepository
+	exportRepo        *repository.ExportRepository
+	auditRepo         *repository.AuditRepository
+	graphql           *graphql.Schema
+	authMiddleware    *middleware.AuthMiddleware
+	adminMiddleware   *middleware.AdminMiddleware
+	intakeLimiter     *middleware.RateLimiter
+	runtime           *config.RuntimeStore
+	drain             *middleware.Drain
+	readOnly          *middleware.ReadOnly
+	maxBodyBytes      int64
 }
 
 // New creates a new Handler instance with all dependencies.
-func New(pool *pgxpool.Pool) *Handler {
+func New(pool *pgxpool.Pool, cfg Config) *Handler {
 	// Create repositories
 	taskRepo := repository.NewTaskRepository(pool)
 	eventRepo := repository.NewTaskEventRepository(pool)
 	agentRepo := repository.NewAgentRepository(pool)
 	workspaceRepo := repository.NewWorkspaceRepository(pool)
+	readTokenRepo := repository.NewReadTokenRepository(pool)
+	checklistRepo := repository.NewChecklistRepository(pool)
+	queueRepo := repository.NewQueueRepository(pool)
+	labelRepo := repository.NewLabelRepository(pool)
+	scheduleRepo := repository.NewScheduleRepository(pool)
+	reportRepo := repository.NewReportRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	webhookSecretRepo := repository.NewWebhookSecretRepository(pool)
+	escalationRepo := repository.NewEscalationRepository(pool)
 
 	// Create services
-	taskService := service.NewTaskService(pool, taskRepo, eventRepo, agentRepo, workspaceRepo)
+	taskService := service.NewTaskService(pool, taskRepo, eventRepo, agentRepo, workspaceRepo, checklistRepo, queueRepo, labelRepo, escalationRepo, webhookSecretRepo)
+	readTokenService := service.NewReadTokenService(readTokenRepo, workspaceRepo)
+	queueService := service.NewQueueService(queueRepo)
+	scheduleService := service.NewScheduleService(pool, scheduleRepo, taskService)
+	reportService := service.NewReportService(pool, reportRepo, taskRepo, workspaceRepo, webhookSecretRepo, service.ReportDeliveryConfig{})
+	labelService := service.NewLabelService(pool, labelRepo)
+	escalationService := service.NewEscalationService(pool, escalationRepo, agentRepo, workspaceRepo, webhookSecretRepo, service.ReportDeliveryConfig{})
 
 	// Create middleware
-	authMiddleware := middleware.NewAuthMiddleware(agentRepo)
+	authMiddleware := middleware.NewAuthMiddleware(agentRepo, readTokenRepo, workspaceRepo, cfg.AdminToken, cfg.AgentCacheTTL, cfg.Shared)
+	adminMiddleware := middleware.NewAdminMiddleware(cfg.AdminToken)
+
+	runtime := cfg.Runtime
+	if runtime == nil {
+		// The defaults always validate
+		runtime, _ = config.NewRuntimeStore("", config.DefaultRuntime())
+	}
+	intakeLimiter := middleware.NewRateLimiter("intake", runtime.Current().IntakeRequestsPerHour, time.Hour, cfg.Shared)
+	runtime.OnChange(func(settings config.Runtime) {
+		intakeLimiter.SetLimit(settings.IntakeRequestsPerHour)
+	})
+
+	readOnly := cfg.ReadOnly
+	if readOnly == nil {
+		readOnly = middleware.NewReadOnly(runtime.Current().ReadOnly, cfg.Shared)
+	}
+	// Reloads run every listener whatever changed; only a change of read_only
+	// in the file overrides what PUT /admin/read-only set. Listeners run one at
+	// a time, so fileReadOnly needs no lock.
+	fileReadOnly := runtime.Current().ReadOnly
+	runtime.OnChange(func(settings config.Runtime) {
+		if settings.ReadOnly != fileReadOnly {
+			fileReadOnly = settings.ReadOnly
+			if _, err := readOnly.Switch(context.Background(), settings.ReadOnly); err != nil {
+				slog.Error("failed to switch read-only mode on the other replicas", "enabled", settings.ReadOnly, "error", err)
+			}
+		}
+	})
+
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
 
 	return &Handler{
-		pool:           pool,
-		taskService:    taskService,
-		taskRepo:       taskRepo,
-		eventRepo:      eventRepo,
-		agentRepo:      agentRepo,
-		workspaceRepo:  workspaceRepo,
-		authMiddleware: authMiddleware,
+		pool:              pool,
+		taskService:       taskService,
+		readTokenService:  readTokenService,
+		queueService:      queueService,
+		scheduleService:   scheduleService,
+		reportService:     reportService,
+		labelService:      labelService,
+		workspaceService:  service.NewWorkspaceService(pool, workspaceRepo, agentRepo, readTokenRepo, scheduleRepo, reportRepo, auditRepo),
+		webhookService:    service.NewWebhookSecretService(pool, webhookSecretRepo, workspaceRepo),
+		escalationService: escalationService,
+		outboxService:     service.NewOutboxService(pool, repository.NewOutboxRepository(pool), webhookSecretRepo, service.ReportDeliveryConfig{}, nil),
+		messageService:    service.NewMessageService(repository.NewMessageRepository(pool), taskRepo, agentRepo),
+		watchService:      service.NewWatchService(repository.NewWatchRepository(pool), taskRepo),
+		viewService:       service.NewViewService(repository.NewViewRepository(pool)),
+		intakeService:     service.NewIntakeService(pool, repository.NewIntakeRepository(pool), labelRepo, workspaceRepo, taskService),
+		configService:     service.NewWorkspaceConfigService(workspaceRepo, agentRepo, taskService, labelService, queueService, escalationService, scheduleService, reportService),
+		changeFeed:        cfg.ChangeFeed,
+		taskRepo:          taskRepo,
+		eventRepo:         eventRepo,
+		agentRepo:         agentRepo,
+		workspaceRepo:     workspaceRepo,
+		exportRepo:        repository.NewExportRepository(pool),
+		auditRepo:         auditRepo,
+		graphql:           graphapi.NewSchema(taskRepo, eventRepo),
+		authMiddleware:    authMiddleware,
+		adminMiddleware:   adminMiddleware,
+		intakeLimiter:     intakeLimiter,
+		runtime:           runtime,
+		drain:             cfg.Drain,
+		readOnly:          readOnly,
+		maxBodyBytes:      maxBodyBytes,
 	}
 }
 
@@ -62,21 +200,151 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /healthz", h.handleHealthz)
 
 	// Static files for AI agents
-	mux.HandleFunc("GET /skill.md", h.handleSkillMd)
+	mux.Handle("GET /skill.md", h.authMiddleware.OptionalAuthenticate(http.HandlerFunc(h.handleSkillMd)))
 
 	// Swagger UI
 	mux.HandleFunc("GET /swagger/", httpSwagger.Handler())
 
+	// Error catalog, public like the docs
+	mux.HandleFunc("GET /api/v1/errors", h.handleListErrors)
+
 	// API v1 routes with authentication
-	mux.Handle("GET /api/v1/tasks", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListTasks)))
+	mux.Handle("GET /api/v1/tasks", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleListTasks)))
 	mux.Handle("POST /api/v1/tasks", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCreateTask)))
-	mux.Handle("GET /api/v1/tasks/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetTask)))
+	mux.Handle("POST /api/v1/tasks/import", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleImportTasks)))
+	mux.Handle("GET /api/v1/tasks/facets", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleListTaskFacets)))
+	mux.Handle("GET /api/v1/tasks/wait", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleWaitForTask)))
+	mux.Handle("POST /api/v1/tasks/claim-next", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleClaimNext)))
+	mux.Handle("GET /api/v1/tasks/{id}", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGetTask)))
+	mux.Handle("PATCH /api/v1/tasks/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleEditTask)))
+	mux.Handle("DELETE /api/v1/tasks/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteTask)))
+	mux.Handle("GET /api/v1/tasks/{id}/revisions", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListTaskRevisions)))
 	mux.Handle("PATCH /api/v1/tasks/{id}/status", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleTransitionStatus)))
 	mux.Handle("POST /api/v1/tasks/{id}/claim", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleClaimTask)))
 	mux.Handle("POST /api/v1/tasks/{id}/escalate", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleEscalateTask)))
 	mux.Handle("POST /api/v1/tasks/{id}/takeover", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleTakeoverTask)))
+	mux.Handle("POST /api/v1/tasks/{id}/await-external", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleAwaitExternal)))
+	mux.Handle("POST /api/v1/tasks/{id}/reopen", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleReopenTask)))
+	mux.Handle("POST /api/v1/tasks/{id}/archive", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleArchiveTask)))
+	mux.Handle("PUT /api/v1/tasks/{id}/labels", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleSetTaskLabels)))
+	mux.Handle("GET /api/v1/tasks/{id}/comments", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListTaskComments)))
 	mux.Handle("POST /api/v1/tasks/{id}/comments", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCommentTask)))
-	mux.Handle("GET /api/v1/stats", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetStats)))
+	mux.Handle("PATCH /api/v1/tasks/{id}/comments/{event_id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleEditComment)))
+	mux.Handle("DELETE /api/v1/tasks/{id}/comments/{event_id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleRedactComment)))
+	mux.Handle("POST /api/v1/tasks/{id}/messages", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleSendMessage)))
+	mux.Handle("POST /api/v1/tasks/{id}/watch", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleWatchTask)))
+	mux.Handle("DELETE /api/v1/tasks/{id}/watch", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUnwatchTask)))
+	mux.Handle("GET /api/v1/tasks/{id}/events", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListTaskEvents)))
+	mux.Handle("GET /api/v1/tasks/{id}/lineage", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetTaskLineage)))
+	mux.Handle("GET /api/v1/tasks/{id}/timings", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetTaskTimings)))
+	mux.Handle("POST /api/v1/tasks/{id}/checklist", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleAddChecklistItem)))
+	mux.Handle("POST /api/v1/tasks/{id}/checklist/{item_id}/claim", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleClaimChecklistItem)))
+	mux.Handle("POST /api/v1/tasks/{id}/checklist/{item_id}/complete", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCompleteChecklistItem)))
+	mux.Handle("GET /api/v1/queues", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListQueues)))
+	mux.Handle("POST /api/v1/queues", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCreateQueue)))
+	mux.Handle("PATCH /api/v1/queues/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUpdateQueue)))
+	mux.Handle("DELETE /api/v1/queues/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteQueue)))
+	mux.Handle("GET /api/v1/views", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListViews)))
+	mux.Handle("POST /api/v1/views", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCreateView)))
+	mux.Handle("GET /api/v1/views/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetView)))
+	mux.Handle("PUT /api/v1/views/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUpdateView)))
+	mux.Handle("DELETE /api/v1/views/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteView)))
+	mux.Handle("GET /api/v1/labels", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListLabels)))
+	mux.Handle("GET /api/v1/labels/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetLabel)))
+	mux.Handle("PUT /api/v1/labels/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handlePutLabel)))
+	mux.Handle("PATCH /api/v1/labels/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUpdateLabel)))
+	mux.Handle("DELETE /api/v1/labels/{name}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteLabel)))
+	mux.Handle("POST /api/v1/labels/{name}/merge", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleMergeLabel)))
+	mux.Handle("GET /api/v1/schedules", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListSchedules)))
+	mux.Handle("POST /api/v1/schedules", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCreateSchedule)))
+	mux.Handle("GET /api/v1/schedules/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetSchedule)))
+	mux.Handle("PATCH /api/v1/schedules/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUpdateSchedule)))
+	mux.Handle("DELETE /api/v1/schedules/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteSchedule)))
+	mux.Handle("GET /api/v1/reports", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListReports)))
+	mux.Handle("POST /api/v1/reports", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleCreateReport)))
+	mux.Handle("GET /api/v1/reports/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetReport)))
+	mux.Handle("PATCH /api/v1/reports/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleUpdateReport)))
+	mux.Handle("DELETE /api/v1/reports/{id}", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleDeleteReport)))
+	mux.Handle("GET /api/v1/reports/{id}/preview", h.authMiddleware.Authenticate(http.HandlerFunc(h.handlePreviewReport)))
+	mux.Handle("POST /api/v1/intake/{workspace}", h.intakeLimiter.Limit(http.HandlerFunc(h.handleSubmitIntake)))
+	mux.Handle("GET /api/v1/agents", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleListAgents)))
+	mux.Handle("GET /api/v1/agents/me", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetMe)))
+	mux.Handle("GET /api/v1/context", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleGetContext)))
+	mux.Handle("POST /api/v1/agents/me/heartbeat", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleHeartbeat)))
+	mux.Handle("GET /api/v1/messages", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListMessages)))
+	mux.Handle("POST /api/v1/messages/{id}/read", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleAcknowledgeMessage)))
+	mux.Handle("GET /api/v1/notifications", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListNotifications)))
+	mux.Handle("GET /api/v1/escalations", h.authMiddleware.Authenticate(http.HandlerFunc(h.handleListEscalations)))
+	mux.Handle("GET /api/v1/stats", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGetStats)))
+	mux.Handle("GET /api/v1/stats/queue-depth", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGetQueueDepth)))
+	mux.Handle("GET /api/v1/stats/metrics", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGetMetrics)))
+	mux.Handle("GET /api/v1/grafana", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGrafanaHealth)))
+	mux.Handle("POST /api/v1/grafana/search", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGrafanaSearch)))
+	mux.Handle("POST /api/v1/grafana/metrics", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGrafanaMetrics)))
+	mux.Handle("POST /api/v1/grafana/variable", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGrafanaVariable)))
+	mux.Handle("POST /api/v1/grafana/query", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGrafanaQuery)))
+	mux.Handle("POST /api/v1/graphql", h.authMiddleware.AuthenticateReader(http.HandlerFunc(h.handleGraphQL)))
+
+	// Admin API (disabled unless an admin token is configured)
+	mux.Handle("GET /api/v1/admin/config", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetRuntimeConfig)))
+	mux.Handle("POST /api/v1/admin/config/reload", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleReloadRuntimeConfig)))
+	mux.Handle("PUT /api/v1/admin/read-only", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetReadOnly)))
+	mux.Handle("GET /api/v1/admin/audit", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleListAudit)))
+	mux.Handle("GET /api/v1/admin/workspaces", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleListWorkspaces)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/archive", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleArchiveWorkspace)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteWorkspace)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/sandbox", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleCreateSandbox)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/read-tokens", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleListReadTokens)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/read-tokens", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleCreateReadToken)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/export", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleExportWorkspace)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/event-log", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleExportEventLog)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/config", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleExportWorkspaceConfig)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/config", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleImportWorkspaceConfig)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/external/resolve", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleResolveExternal)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/tasks/{id}", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleAdminDeleteTask)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/tasks/{id}/transfer", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleTransferTask)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/tasks/{id}/clear-review", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleClearReview)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/tasks/{id}/approve", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleApproveTask)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/tasks/{id}/reject", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleRejectTask)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/webhook-secret", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetWebhookSecret)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/webhook-secret/rotate", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleRotateWebhookSecret)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/webhook-secret/complete", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleCompleteWebhookRotation)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/auto-assign", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetAutoAssignStrategy)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/priority-inheritance", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetPriorityInheritance)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/auto-unblock", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetAutoUnblock)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/intake", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetIntakeForm)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/intake", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetIntakeForm)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/intake", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteIntakeForm)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/done-validation", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetDoneValidation)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/done-validation", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetDoneValidation)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/done-validation", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteDoneValidation)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/event-webhook", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetEventWebhook)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/event-webhook", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetEventWebhook)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/event-webhook", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteEventWebhook)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/event-broker", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetEventBroker)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/event-broker", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetEventBroker)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/event-broker", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteEventBroker)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/outbox", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleListOutbox)))
+	mux.Handle("POST /api/v1/admin/workspaces/{workspace_id}/outbox/{id}/retry", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleRetryOutboxMessage)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/priority-aging", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetPriorityAging)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/priority-aging", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetPriorityAging)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/priority-aging", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeletePriorityAging)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/claim-fairness", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetClaimFairness)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/claim-fairness", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetClaimFairness)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/claim-fairness", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteClaimFairness)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/deadline-warning", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetDeadlineWarning)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/deadline-warning", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetDeadlineWarning)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/deadline-warning", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteDeadlineWarning)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/deadline-expiry", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetDeadlineExpiry)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/deadline-expiry", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetDeadlineExpiry)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/max-attempts", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetMaxAttempts)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/max-attempts", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetMaxAttempts)))
+	mux.Handle("DELETE /api/v1/admin/workspaces/{workspace_id}/max-attempts", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleDeleteMaxAttempts)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/agent-staleness", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetAgentStaleAfter)))
+	mux.Handle("GET /api/v1/admin/workspaces/{workspace_id}/escalation-routes", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleGetEscalationRoutes)))
+	mux.Handle("PUT /api/v1/admin/workspaces/{workspace_id}/escalation-routes", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetEscalationRoutes)))
+	mux.Handle("PUT /api/v1/admin/agents/{id}/capabilities", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleSetAgentCapabilities)))
+	mux.Handle("DELETE /api/v1/admin/read-tokens/{id}", h.adminMiddleware.RequireAdmin(http.HandlerFunc(h.handleRevokeReadToken)))
 }
 
 // handleIndex serves the landing page.
@@ -88,10 +356,16 @@ func (h *Handler) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleHealthz returns 200 OK if the database is reachable.
+// handleHealthz returns 200 OK if the database is reachable and the server is
+// not shutting down.
 func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if h.drain != nil && h.drain.Draining() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	if err := h.pool.Ping(ctx); err != nil {
 		slog.Error("database health check failed", "error", err)
 		http.Error(w, "database unavailable", http.StatusServiceUnavailable)
@@ -101,15 +375,6 @@ func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// handleSkillMd serves the embedded skill.md file for AI agents.
-func (h *Handler) handleSkillMd(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte(static.SkillMd)); err != nil {
-		slog.Error("failed to write skill.md response", "error", err)
-	}
-}
-
 // Ping checks if the database is reachable (used for testing).
 func (h *Handler) Ping(ctx context.Context) error {
 	return h.pool.Ping(ctx)
@@ -129,19 +394,115 @@ func respondError(w http.ResponseWriter, status int, code, message string) {
 	respondJSON(w, status, dto.NewErrorResponse(code, message))
 }
 
-// extractTaskID extracts and validates task ID from path parameter.
+// respondDomainError answers with the status and code MapDomainError gives err
+// and the details attached to it, if any.
+func respondDomainError(w http.ResponseWriter, err error) {
+	status, code, message := dto.MapDomainError(err)
+	response := dto.NewErrorResponse(code, message)
+	if status != http.StatusInternalServerError {
+		response.Error.Details = domain.ErrorDetails(err)
+	}
+	respondJSON(w, status, response)
+}
+
+// decodeJSON decodes the JSON request body into dst, rejecting bodies over the
+// size limit and unknown fields. Returns false if the error response was sent.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return h.decodeBody(w, r, dst, false)
+}
+
+// decodeOptionalJSON is decodeJSON for optional bodies: an empty body leaves
+// dst as it is.
+func (h *Handler) decodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return h.decodeBody(w, r, dst, true)
+}
+
+// decodeBody implements decodeJSON and decodeOptionalJSON.
+func (h *Handler) decodeBody(w http.ResponseWriter, r *http.Request, dst any, optional bool) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(dst)
+	if err == nil || (optional && errors.Is(err, io.EOF)) {
+		return true
+	}
+	respondDecodeError(w, err)
+	return false
+}
+
+// respondDecodeError writes the error response for a request body that could
+// not be decoded: 413 over the size limit, 422 for an unknown field and 400 for
+// anything else.
+func respondDecodeError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondError(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE",
+			fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit))
+		return
+	}
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		respondError(w, http.StatusUnprocessableEntity, "UNKNOWN_FIELD", "unknown field "+field)
+		return
+	}
+	respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+}
+
+// extractTaskID extracts the task from the path parameter, given by its ID or
+// its key such as TST-142, and returns its ID.
 // Returns (taskID, true) if valid, ("", false) if invalid (error already sent to client).
-func extractTaskID(w http.ResponseWriter, r *http.Request) (string, bool) {
-	taskID := r.PathValue("id")
-	if taskID == "" {
-		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "task id is required")
+func (h *Handler) extractTaskID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ref := r.PathValue("id")
+	if ref == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "task_id is required")
 		return "", false
 	}
 
-	if _, err := uuid.Parse(taskID); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "task_id must be a valid UUID")
+	taskID, err := repository.ResolveTaskRef(r.Context(), h.taskRepo, ref)
+	if err != nil {
+		if errors.Is(err, domain.ErrValidation) {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "task_id must be a valid UUID or a task key such as TST-42")
+			return "", false
+		}
+		respondDomainError(w, err)
 		return "", false
 	}
 
 	return taskID, true
 }
+
+// resolveTaskRefs returns the IDs of the tasks refs name by ID or key, in
+// order. A key of no task becomes uuid.Nil, which matches no task either, so
+// callers treat it like an unknown ID. Returns ErrValidation if a ref is
+// neither an ID nor a key.
+func (h *Handler) resolveTaskRefs(ctx context.Context, refs []string) ([]string, error) {
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		id, err := repository.ResolveTaskRef(ctx, h.taskRepo, ref)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			id, err = uuid.Nil.String(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// extractPathUUID extracts and validates a UUID path parameter.
+// label names the parameter in error messages.
+// Returns (value, true) if valid, ("", false) if invalid (error already sent to client).
+func extractPathUUID(w http.ResponseWriter, r *http.Request, name, label string) (string, bool) {
+	value := r.PathValue(name)
+	if value == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", label+" is required")
+		return "", false
+	}
+
+	if _, err := uuid.Parse(value); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", label+" must be a valid UUID")
+		return "", false
+	}
+
+	return value, true
+}
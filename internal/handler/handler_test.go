@@ -6,20 +6,31 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/mtlprog/sloptask/internal/config"
 	"github.com/mtlprog/sloptask/internal/database"
+	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/handler"
 	"github.com/mtlprog/sloptask/internal/handler/dto"
 	"github.com/mtlprog/sloptask/internal/middleware"
 	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/testutil/factory"
 )
 
+const testAdminToken = "test-admin-token"
+
 type HandlerTestSuite struct {
 	suite.Suite
 	pool    *pgxpool.Pool
@@ -40,45 +51,47 @@ func (s *HandlerTestSuite) SetupSuite() {
 	}
 
 	ctx := context.Background()
-	db, err := database.New(ctx, databaseURL)
+	db, err := database.New(ctx, databaseURL, database.DefaultPoolConfig)
 	s.Require().NoError(err)
 	s.pool = db.Pool()
 
 	err = database.RunMigrations(ctx, s.pool)
 	s.Require().NoError(err)
 
-	s.handler = handler.New(s.pool)
+	s.handler = handler.New(s.pool, handler.Config{AdminToken: testAdminToken})
 }
 
 func (s *HandlerTestSuite) SetupTest() {
 	ctx := context.Background()
 
 	// TRUNCATE all tables
-	_, err := s.pool.Exec(ctx, "TRUNCATE workspaces, agents, tasks, task_events CASCADE")
+	_, err := s.pool.Exec(ctx, "TRUNCATE workspaces, agents, tasks, task_events, admin_audit_log CASCADE")
 	s.Require().NoError(err)
 
 	// Create workspace
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO workspaces (id, name, slug, status_deadlines)
-		VALUES ('00000000-0000-0000-0000-000000000001', 'Test Workspace', 'test',
-				'{"NEW": 120, "IN_PROGRESS": 1440}'::jsonb)
-	`)
-	s.Require().NoError(err)
-	s.workspaceID = "00000000-0000-0000-0000-000000000001"
+	workspace := factory.CreateWorkspace(s.T(), s.pool,
+		factory.WithWorkspaceID("00000000-0000-0000-0000-000000000001"),
+		factory.WithSlug("test"),
+		factory.WithStatusDeadlines(map[string]int{"NEW": 120, "IN_PROGRESS": 1440}),
+	)
+	s.workspaceID = workspace.ID
 
 	// Create agents
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO agents (id, workspace_id, name, token, is_active)
-		VALUES
-			('00000000-0000-0000-0000-000000000011', $1, 'agent-1', 'token-1', true),
-			('00000000-0000-0000-0000-000000000012', $1, 'agent-2', 'token-2', true)
-	`, s.workspaceID)
-	s.Require().NoError(err)
-
-	s.agent1ID = "00000000-0000-0000-0000-000000000011"
-	s.agent1Token = "token-1"
-	s.agent2ID = "00000000-0000-0000-0000-000000000012"
-	s.agent2Token = "token-2"
+	agent1 := factory.CreateAgent(s.T(), s.pool, s.workspaceID,
+		factory.WithAgentID("00000000-0000-0000-0000-000000000011"),
+		factory.WithAgentName("agent-1"),
+		factory.WithToken("token-1"),
+	)
+	agent2 := factory.CreateAgent(s.T(), s.pool, s.workspaceID,
+		factory.WithAgentID("00000000-0000-0000-0000-000000000012"),
+		factory.WithAgentName("agent-2"),
+		factory.WithToken("token-2"),
+	)
+
+	s.agent1ID = agent1.ID
+	s.agent1Token = agent1.Token
+	s.agent2ID = agent2.ID
+	s.agent2Token = agent2.Token
 }
 
 func (s *HandlerTestSuite) TearDownSuite() {
@@ -112,7 +125,9 @@ func (s *HandlerTestSuite) makeRequest(method, path, token string, body interfac
 
 	// Register auth middleware and routes
 	agentRepo := repository.NewAgentRepository(s.pool)
-	authMiddleware := middleware.NewAuthMiddleware(agentRepo)
+	readTokenRepo := repository.NewReadTokenRepository(s.pool)
+	workspaceRepo := repository.NewWorkspaceRepository(s.pool)
+	authMiddleware := middleware.NewAuthMiddleware(agentRepo, readTokenRepo, workspaceRepo, testAdminToken, 0, nil)
 	s.handler.RegisterRoutes(mux)
 
 	// Wrap with auth middleware
@@ -135,16 +150,10 @@ func (s *HandlerTestSuite) TestCreateTask_Unauthorized() {
 
 // Test 2: Private task visibility check
 func (s *HandlerTestSuite) TestGetTask_PrivateTaskUnauthorized() {
-	ctx := context.Background()
-
 	// Create private task by agent1
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, visibility, status)
-		VALUES ($1, 'Private Task', 'Secret', $2, 'private', 'NEW')
-		RETURNING id
-	`, s.workspaceID, s.agent1ID).Scan(&taskID)
-	s.Require().NoError(err)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Private Task"), factory.WithDescription("Secret"), factory.Private(),
+	).ID
 
 	// Agent2 tries to access
 	w := s.makeRequest("GET", "/api/v1/tasks/"+taskID, s.agent2Token, nil)
@@ -154,21 +163,15 @@ func (s *HandlerTestSuite) TestGetTask_PrivateTaskUnauthorized() {
 
 // Test 3: Private task NOT in list for unauthorized agent
 func (s *HandlerTestSuite) TestListTasks_PrivateTaskFiltered() {
-	ctx := context.Background()
-
 	// Agent1 creates private task
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, visibility, status)
-		VALUES ($1, 'Private Task', 'Secret', $2, 'private', 'NEW')
-	`, s.workspaceID, s.agent1ID)
-	s.Require().NoError(err)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Private Task"), factory.WithDescription("Secret"), factory.Private(),
+	)
 
 	// Agent1 creates public task
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, visibility, status)
-		VALUES ($1, 'Public Task', 'Public', $2, 'public', 'NEW')
-	`, s.workspaceID, s.agent1ID)
-	s.Require().NoError(err)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Public Task"), factory.WithDescription("Public"),
+	)
 
 	// Agent2 lists tasks
 	w := s.makeRequest("GET", "/api/v1/tasks", s.agent2Token, nil)
@@ -176,7 +179,7 @@ func (s *HandlerTestSuite) TestListTasks_PrivateTaskFiltered() {
 	s.Equal(http.StatusOK, w.Code)
 
 	var respBody dto.TasksListResponse
-	err = json.NewDecoder(w.Body).Decode(&respBody)
+	err := json.NewDecoder(w.Body).Decode(&respBody)
 	s.Require().NoError(err)
 
 	// Should only see public task, not private
@@ -184,6 +187,282 @@ func (s *HandlerTestSuite) TestListTasks_PrivateTaskFiltered() {
 	s.Equal("Public Task", respBody.Tasks[0].Title)
 }
 
+func (s *HandlerTestSuite) TestListTasks_HumanReview() {
+	var heldIDs []string
+	for _, title := range []string{"Held Once", "Held Twice"} {
+		heldIDs = append(heldIDs, factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle(title)).ID)
+	}
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Not Held"))
+	_, err := s.pool.Exec(context.Background(), "UPDATE tasks SET human_review_at = NOW() WHERE id = ANY($1)", heldIDs)
+	s.Require().NoError(err)
+
+	list := func(query string) dto.TasksListResponse {
+		w := s.makeRequest("GET", "/api/v1/tasks?human_review=true&"+query, s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var respBody dto.TasksListResponse
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+		return respBody
+	}
+
+	// The total counts the held tasks only, on every page
+	all := list("")
+	s.Equal(2, all.Total)
+	s.Len(all.Tasks, 2)
+	s.Equal(2, list("limit=1").Total)
+	s.Equal(2, list("offset=10").Total)
+}
+
+func (s *HandlerTestSuite) TestListTasks_ByIDs() {
+	doneID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Done Blocker"), factory.WithStatus(domain.TaskStatusDone), factory.WithAssignee(s.agent1ID)).ID
+	openID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Open Blocker")).ID
+	privateID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Private Blocker"), factory.Private()).ID
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Unrelated Task"))
+
+	w := s.makeRequest("GET", "/api/v1/tasks?ids="+doneID+","+openID+","+privateID, s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var respBody dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+	s.Equal(2, respBody.Total, "another agent's private task is left out")
+	statuses := map[string]domain.TaskStatus{}
+	for _, task := range respBody.Tasks {
+		statuses[task.ID] = domain.TaskStatus(task.Status)
+	}
+	s.Equal(map[string]domain.TaskStatus{doneID: domain.TaskStatusDone, openID: domain.TaskStatusNew}, statuses)
+
+	// The total counts the requested tasks only, on every page
+	for _, page := range []string{"&limit=1", "&offset=10"} {
+		w = s.makeRequest("GET", "/api/v1/tasks?ids="+doneID+","+openID+","+privateID+page, s.agent2Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code)
+		respBody = dto.TasksListResponse{}
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+		s.Equal(2, respBody.Total, page)
+	}
+
+	// Asking for private tasks doesn't reveal another agent's
+	w = s.makeRequest("GET", "/api/v1/tasks?ids="+doneID+","+openID+","+privateID+"&visibility=private", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	respBody = dto.TasksListResponse{}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+	s.Equal(0, respBody.Total)
+	s.Empty(respBody.Tasks)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?ids="+doneID+",not-a-uuid", s.agent2Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestListTasks_ByCreatorAndDates() {
+	now := time.Now().UTC()
+	oldID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Old Mine"), factory.WithCreatedAt(now.Add(-10*24*time.Hour))).ID
+	recentID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Recent Mine"), factory.WithCreatedAt(now.Add(-2*24*time.Hour))).ID
+	theirsID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithTitle("Recent Theirs"), factory.WithCreatedAt(now.Add(-2*24*time.Hour))).ID
+
+	list := func(query string) []string {
+		w := s.makeRequest("GET", "/api/v1/tasks?"+query, s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var respBody dto.TasksListResponse
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+		ids := []string{}
+		for _, task := range respBody.Tasks {
+			ids = append(ids, task.ID)
+		}
+		s.Equal(len(ids), respBody.Total, "the total counts the filtered tasks: %s", query)
+		return ids
+	}
+
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	s.Equal([]string{recentID}, list("creator=me&created_after="+weekAgo.Format(time.RFC3339)))
+	s.Equal([]string{oldID}, list("creator=me&created_before="+weekAgo.Format(time.DateOnly)))
+	s.Equal([]string{theirsID}, list("creator="+s.agent2ID))
+
+	_, err := s.pool.Exec(context.Background(), "UPDATE tasks SET title = 'Old Mine, Revisited', updated_at = NOW() WHERE id = $1", oldID)
+	s.Require().NoError(err)
+	s.Equal([]string{oldID}, list("updated_after="+now.Add(-time.Hour).Format(time.RFC3339)))
+
+	// A page past the end still counts the filtered tasks
+	w := s.makeRequest("GET", "/api/v1/tasks?creator=me&offset=10", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var respBody dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+	s.Equal(2, respBody.Total)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?created_after=last-week", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = s.makeRequest("GET", "/api/v1/tasks?creator=someone", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestListTasks_ByDependencies() {
+	blocker := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Blocker"))
+	otherBlockerID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Other Blocker")).ID
+	dependentID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Dependent"), factory.WithBlockedBy(blocker.ID, otherBlockerID)).ID
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Unrelated"))
+
+	list := func(query string) []string {
+		w := s.makeRequest("GET", "/api/v1/tasks?sort=created_at&"+query, s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var respBody dto.TasksListResponse
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+		ids := []string{}
+		for _, task := range respBody.Tasks {
+			ids = append(ids, task.ID)
+		}
+		s.Equal(len(ids), respBody.Total, "the total counts the filtered tasks: %s", query)
+		return ids
+	}
+
+	s.Equal([]string{dependentID}, list("blocked_by="+blocker.ID))
+	s.Equal([]string{dependentID}, list("blocked_by="+blocker.Key))
+	s.ElementsMatch([]string{blocker.ID, otherBlockerID}, list("blocks="+dependentID))
+	s.Empty(list("blocks=" + blocker.ID))
+	s.Empty(list("blocked_by=TST-99999"))
+
+	// A page past the end still counts the filtered tasks
+	w := s.makeRequest("GET", "/api/v1/tasks?blocks="+dependentID+"&offset=10", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var respBody dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+	s.Equal(2, respBody.Total)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?blocks=nope", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestListTasks_TotalMatchesFilters() {
+	blockerID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Blocker")).ID
+	for range 3 {
+		factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithBlockedBy(blockerID))
+	}
+
+	total := func(query string) (int, int) {
+		w := s.makeRequest("GET", "/api/v1/tasks?"+query, s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var respBody dto.TasksListResponse
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&respBody))
+		return respBody.Total, len(respBody.Tasks)
+	}
+
+	// The total counts all matching tasks, not just the page
+	count, page := total("has_unresolved_blockers=true&limit=2")
+	s.Equal(3, count)
+	s.Equal(2, page)
+
+	// A page past the end still reports the total
+	count, page = total("has_unresolved_blockers=true&offset=10")
+	s.Equal(3, count)
+	s.Equal(0, page)
+}
+
+func (s *HandlerTestSuite) TestListTaskFacets() {
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Open One"))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Open Two"))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID, factory.Private())
+
+	w := s.makeRequest("GET", "/api/v1/tasks/facets", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var facets dto.TaskFacetsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&facets))
+	// The other agent's private task is left out
+	s.Equal(3, facets.Total)
+	s.Equal(map[string]int{"NEW": 2, "IN_PROGRESS": 1}, facets.Status)
+	s.Equal(map[string]int{s.agent1ID: 1}, facets.Assignee)
+	s.Equal(2, facets.Unassigned)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/facets?status=NEW&creator=me", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&facets))
+	s.Equal(2, facets.Total)
+	s.Empty(facets.Assignee)
+}
+
+func (s *HandlerTestSuite) TestListTaskFacets_PrivateVisibilityOwnTasksOnly() {
+	ownID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Own Private"), factory.Private()).ID
+	assignedID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID, factory.WithTitle("Assigned Private"),
+		factory.Private(), factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID)).ID
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID, factory.WithTitle("Other Private"), factory.Private())
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID, factory.WithTitle("Public"))
+
+	w := s.makeRequest("GET", "/api/v1/tasks?visibility=private", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var list dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Equal(2, list.Total)
+	ids := []string{}
+	for _, task := range list.Tasks {
+		ids = append(ids, task.ID)
+	}
+	s.ElementsMatch([]string{ownID, assignedID}, ids)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/facets?visibility=private", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var facets dto.TaskFacetsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&facets))
+	s.Equal(2, facets.Total, "the other agent's private task is not counted")
+	s.Equal(map[string]int{"NEW": 1, "IN_PROGRESS": 1}, facets.Status)
+}
+
+func (s *HandlerTestSuite) TestSavedViews() {
+	urgentID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Urgent Work"), factory.WithPriority(domain.TaskPriorityCritical)).ID
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Routine Work"))
+
+	w := s.makeRequest("POST", "/api/v1/views", s.agent1Token, dto.CreateViewRequest{
+		Name:   "Urgent",
+		Params: map[string]string{"priority": "critical", "status": "NEW", "fields": "id,title"},
+	})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var view dto.ViewResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&view))
+	s.Equal("urgent", view.Name)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?view=urgent", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Tasks []map[string]any `json:"tasks"`
+		Total int              `json:"total"`
+	}
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Equal(1, list.Total)
+	s.Equal(map[string]any{"id": urgentID, "title": "Urgent Work"}, list.Tasks[0])
+
+	// Request parameters override the view's
+	w = s.makeRequest("GET", "/api/v1/tasks?view=urgent&priority=normal", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Equal(1, list.Total)
+	s.Equal("Routine Work", list.Tasks[0]["title"])
+
+	// Views are private to their agent
+	w = s.makeRequest("GET", "/api/v1/tasks?view=urgent", s.agent2Token, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	w = s.makeRequest("POST", "/api/v1/views", s.agent1Token, dto.CreateViewRequest{Name: "urgent"})
+	s.Equal(http.StatusConflict, w.Code)
+	w = s.makeRequest("POST", "/api/v1/views", s.agent1Token, dto.CreateViewRequest{
+		Name: "paged", Params: map[string]string{"offset": "50"},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.makeRequest("PUT", "/api/v1/views/urgent", s.agent1Token, dto.UpdateViewRequest{
+		Params: map[string]string{"priority": "normal"},
+	})
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	w = s.makeRequest("GET", "/api/v1/tasks?view=urgent", s.agent1Token, nil)
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Equal("Routine Work", list.Tasks[0]["title"])
+
+	w = s.makeRequest("DELETE", "/api/v1/views/urgent", s.agent1Token, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+	w = s.makeRequest("GET", "/api/v1/views/urgent", s.agent1Token, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
 // Test 4: Validation error returns 422
 func (s *HandlerTestSuite) TestCreateTask_ValidationError() {
 	reqBody := dto.CreateTaskRequest{
@@ -201,18 +480,58 @@ func (s *HandlerTestSuite) TestCreateTask_ValidationError() {
 	s.Equal("VALIDATION_ERROR", errResp.Error.Code)
 }
 
+func (s *HandlerTestSuite) TestCreateTask_ValidatesBlockers() {
+	blockerID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID).ID
+	missingID := uuid.NewString()
+
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Blocked by a ghost",
+		Description: "One blocker doesn't exist",
+		BlockedBy:   []string{blockerID, missingID},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+	s.Equal("VALIDATION_ERROR", errResp.Error.Code)
+	s.Equal([]any{missingID}, errResp.Error.Details["missing_blockers"])
+
+	w = s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Blocked twice",
+		Description: "The same blocker is listed twice",
+		BlockedBy:   []string{blockerID, blockerID},
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var task dto.TaskDetail
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&task))
+	s.Equal([]string{blockerID}, task.BlockedBy)
+}
+
+func (s *HandlerTestSuite) TestCreateTask_BodyLimitAndUnknownFields() {
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, map[string]any{
+		"title":       "Runaway description",
+		"description": strings.Repeat("x", handler.DefaultMaxBodyBytes),
+	})
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+	s.Equal("PAYLOAD_TOO_LARGE", errResp.Error.Code)
+
+	w = s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, map[string]any{
+		"title":       "Misspelt priority",
+		"description": "The field name has a typo",
+		"priorty":     "high",
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+	s.Equal("UNKNOWN_FIELD", errResp.Error.Code)
+	s.Contains(errResp.Error.Message, "priorty")
+}
+
 // Test 5: Concurrent claims (race condition)
 func (s *HandlerTestSuite) TestClaimTask_Concurrent() {
-	ctx := context.Background()
 
 	// Create task
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, status)
-		VALUES ($1, 'Test Task', 'Test', $2, 'NEW')
-		RETURNING id
-	`, s.workspaceID, s.agent1ID).Scan(&taskID)
-	s.Require().NoError(err)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID).ID
 
 	// Two agents try to claim simultaneously
 	var wg sync.WaitGroup
@@ -252,11 +571,7 @@ func (s *HandlerTestSuite) TestListTasks_SQLInjectionBlocked() {
 	ctx := context.Background()
 
 	// Create a task
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, status)
-		VALUES ($1, 'Test Task', 'Test', $2, 'NEW')
-	`, s.workspaceID, s.agent1ID)
-	s.Require().NoError(err)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
 
 	// Try SQL injection via sort parameter
 	w := s.makeRequest("GET", "/api/v1/tasks?sort=created_at;DROP+TABLE+tasks;--", s.agent1Token, nil)
@@ -266,7 +581,7 @@ func (s *HandlerTestSuite) TestListTasks_SQLInjectionBlocked() {
 
 	// Verify tasks table still exists
 	var count int
-	err = s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tasks").Scan(&count)
+	err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tasks").Scan(&count)
 	s.NoError(err)
 	s.Equal(1, count)
 }
@@ -275,14 +590,20 @@ func (s *HandlerTestSuite) TestListTasks_SQLInjectionBlocked() {
 func (s *HandlerTestSuite) TestGetTask_BlockerErrorHandling() {
 	ctx := context.Background()
 
-	// Create task with non-existent blocker ID (should handle gracefully)
+	// Phantom blockers are rejected on insert (see 043_task_dependencies.sql)
 	nonExistentBlockerID := "99999999-9999-9999-9999-999999999999"
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, status, blocked_by)
-		VALUES ($1, 'Test Task', 'Test', $2, 'NEW', ARRAY[$3]::uuid[])
-		RETURNING id
-	`, s.workspaceID, s.agent1ID, nonExistentBlockerID).Scan(&taskID)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID).ID
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO task_dependencies (workspace_id, task_id, blocker_id, position)
+		VALUES ($1, $2, $3, 1)
+	`, s.workspaceID, taskID, nonExistentBlockerID)
+	s.Require().Error(err)
+
+	// A blocker can still disappear after the fact, taking the dependency with it
+	blockerID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Blocker Task")).ID
+	taskID = factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithBlockedBy(blockerID)).ID
+
+	_, err = s.pool.Exec(ctx, `DELETE FROM tasks WHERE id = $1`, blockerID)
 	s.Require().NoError(err)
 
 	// Get task should still work (blocker won't be found but should not crash)
@@ -301,14 +622,10 @@ func (s *HandlerTestSuite) TestGetTask_BlockerErrorHandling() {
 
 // Test 8: Agent can see private task they created
 func (s *HandlerTestSuite) TestListTasks_PrivateTaskVisibleToCreator() {
-	ctx := context.Background()
-
 	// Agent1 creates private task
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, visibility, status)
-		VALUES ($1, 'Private Task', 'Secret', $2, 'private', 'NEW')
-	`, s.workspaceID, s.agent1ID)
-	s.Require().NoError(err)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Private Task"), factory.WithDescription("Secret"), factory.Private(),
+	)
 
 	// Agent1 lists tasks
 	w := s.makeRequest("GET", "/api/v1/tasks", s.agent1Token, nil)
@@ -316,7 +633,7 @@ func (s *HandlerTestSuite) TestListTasks_PrivateTaskVisibleToCreator() {
 	s.Equal(http.StatusOK, w.Code)
 
 	var respBody dto.TasksListResponse
-	err = json.NewDecoder(w.Body).Decode(&respBody)
+	err := json.NewDecoder(w.Body).Decode(&respBody)
 	s.Require().NoError(err)
 
 	// Should see their private task
@@ -326,20 +643,16 @@ func (s *HandlerTestSuite) TestListTasks_PrivateTaskVisibleToCreator() {
 
 // Test: PATCH /tasks/:id/status - DONE without artefact returns 422
 func (s *HandlerTestSuite) TestTransitionStatus_DoneWithoutArtefact_Returns422() {
-	ctx := context.Background()
-
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, status)
-		VALUES ($1, 'Test Task', 'Test', $2, $2, 'IN_PROGRESS')
-		RETURNING id
-	`, s.workspaceID, s.agent1ID).Scan(&taskID)
-	s.Require().NoError(err)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	).ID
 
+	version := 1
 	reqBody := dto.TransitionStatusRequest{
 		Status:  "DONE",
 		Comment: "Done",
 		// no artefact
+		ExpectedVersion: &version,
 	}
 
 	w := s.makeRequest("PATCH", "/api/v1/tasks/"+taskID+"/status", s.agent1Token, reqBody)
@@ -347,27 +660,24 @@ func (s *HandlerTestSuite) TestTransitionStatus_DoneWithoutArtefact_Returns422()
 	s.Equal(http.StatusUnprocessableEntity, w.Code)
 
 	var errResp dto.ErrorResponse
-	err = json.NewDecoder(w.Body).Decode(&errResp)
+	err := json.NewDecoder(w.Body).Decode(&errResp)
 	s.Require().NoError(err)
 	s.Equal("VALIDATION_ERROR", errResp.Error.Code)
 }
 
 // Test: PATCH /tasks/:id/status - DONE with invalid artefact URL returns 422
 func (s *HandlerTestSuite) TestTransitionStatus_DoneWithInvalidArtefactURL_Returns422() {
-	ctx := context.Background()
-
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, status)
-		VALUES ($1, 'Test Task', 'Test', $2, $2, 'IN_PROGRESS')
-		RETURNING id
-	`, s.workspaceID, s.agent1ID).Scan(&taskID)
-	s.Require().NoError(err)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	).ID
 
+	version := 1
 	reqBody := dto.TransitionStatusRequest{
 		Status:   "DONE",
 		Comment:  "Done",
 		Artefact: "not-a-url",
+
+		ExpectedVersion: &version,
 	}
 
 	w := s.makeRequest("PATCH", "/api/v1/tasks/"+taskID+"/status", s.agent1Token, reqBody)
@@ -375,28 +685,25 @@ func (s *HandlerTestSuite) TestTransitionStatus_DoneWithInvalidArtefactURL_Retur
 	s.Equal(http.StatusUnprocessableEntity, w.Code)
 
 	var errResp dto.ErrorResponse
-	err = json.NewDecoder(w.Body).Decode(&errResp)
+	err := json.NewDecoder(w.Body).Decode(&errResp)
 	s.Require().NoError(err)
 	s.Equal("VALIDATION_ERROR", errResp.Error.Code)
 }
 
 // Test: PATCH /tasks/:id/status - DONE with valid artefact returns 200 and artefact in GET response
 func (s *HandlerTestSuite) TestTransitionStatus_DoneWithArtefact_Success() {
-	ctx := context.Background()
-
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, status)
-		VALUES ($1, 'Test Task', 'Test', $2, $2, 'IN_PROGRESS')
-		RETURNING id
-	`, s.workspaceID, s.agent1ID).Scan(&taskID)
-	s.Require().NoError(err)
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	).ID
 
 	artefactURL := "https://github.com/example/pr/42"
+	version := 1
 	reqBody := dto.TransitionStatusRequest{
 		Status:   "DONE",
 		Comment:  "Completed",
 		Artefact: artefactURL,
+
+		ExpectedVersion: &version,
 	}
 
 	w := s.makeRequest("PATCH", "/api/v1/tasks/"+taskID+"/status", s.agent1Token, reqBody)
@@ -408,7 +715,7 @@ func (s *HandlerTestSuite) TestTransitionStatus_DoneWithArtefact_Success() {
 	s.Equal(http.StatusOK, w.Code)
 
 	var respBody dto.TaskDetailResponse
-	err = json.NewDecoder(w.Body).Decode(&respBody)
+	err := json.NewDecoder(w.Body).Decode(&respBody)
 	s.Require().NoError(err)
 	s.Require().NotNil(respBody.Task.Artefact)
 	s.Equal(artefactURL, *respBody.Task.Artefact)
@@ -416,14 +723,11 @@ func (s *HandlerTestSuite) TestTransitionStatus_DoneWithArtefact_Success() {
 
 // Test 9: Agent can see private task they're assigned to
 func (s *HandlerTestSuite) TestListTasks_PrivateTaskVisibleToAssignee() {
-	ctx := context.Background()
-
 	// Agent1 creates private task assigned to Agent2
-	_, err := s.pool.Exec(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, visibility, status)
-		VALUES ($1, 'Private Task', 'Secret', $2, $3, 'private', 'IN_PROGRESS')
-	`, s.workspaceID, s.agent1ID, s.agent2ID)
-	s.Require().NoError(err)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Private Task"), factory.WithDescription("Secret"), factory.Private(),
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+	)
 
 	// Agent2 lists tasks (should see it because they're assignee)
 	w := s.makeRequest("GET", "/api/v1/tasks", s.agent2Token, nil)
@@ -431,10 +735,1750 @@ func (s *HandlerTestSuite) TestListTasks_PrivateTaskVisibleToAssignee() {
 	s.Equal(http.StatusOK, w.Code)
 
 	var respBody dto.TasksListResponse
-	err = json.NewDecoder(w.Body).Decode(&respBody)
+	err := json.NewDecoder(w.Body).Decode(&respBody)
 	s.Require().NoError(err)
 
 	// Should see the private task
 	s.Equal(1, respBody.Total)
 	s.Equal("Private Task", respBody.Tasks[0].Title)
 }
+
+// Test: GET /tasks/:id/events paginates and filters by type
+func (s *HandlerTestSuite) TestListTaskEvents_FilterAndPaginate() {
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID).ID
+
+	for i := 0; i < 3; i++ {
+		w := s.makeRequest("POST", "/api/v1/tasks/"+taskID+"/comments", s.agent2Token, dto.CommentTaskRequest{Comment: "Progress"})
+		s.Require().Equal(http.StatusCreated, w.Code)
+	}
+
+	w := s.makeRequest("GET", "/api/v1/tasks/"+taskID+"/events?type=commented&limit=2", s.agent1Token, nil)
+	s.Equal(http.StatusOK, w.Code)
+
+	var respBody dto.TaskEventsListResponse
+	err := json.NewDecoder(w.Body).Decode(&respBody)
+	s.Require().NoError(err)
+	s.Equal(3, respBody.Total)
+	s.Len(respBody.Events, 2)
+	for _, event := range respBody.Events {
+		s.Equal("commented", event.Type)
+	}
+
+	// Unknown event type is rejected
+	w = s.makeRequest("GET", "/api/v1/tasks/"+taskID+"/events?type=bogus", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+// serveRequest sends a request through the registered routes without the outer
+// agent auth wrapper, so admin and read-token authentication can be exercised.
+func (s *HandlerTestSuite) serveRequest(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var bodyReader *bytes.Reader
+	if body != nil {
+		bodyBytes, _ := json.Marshal(body)
+		bodyReader = bytes.NewReader(bodyBytes)
+	} else {
+		bodyReader = bytes.NewReader([]byte{})
+	}
+
+	req := httptest.NewRequest(method, path, bodyReader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	s.handler.RegisterRoutes(mux)
+	mux.ServeHTTP(w, req)
+
+	return w
+}
+
+func (s *HandlerTestSuite) TestReadToken_StatsAccessAndRevocation() {
+	tokensPath := "/api/v1/admin/workspaces/" + s.workspaceID + "/read-tokens"
+
+	// Admin endpoints require the admin token
+	w := s.serveRequest("POST", tokensPath, s.agent1Token, dto.CreateReadTokenRequest{Name: "grafana"})
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("POST", tokensPath, testAdminToken, dto.CreateReadTokenRequest{Name: "grafana"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	var created dto.CreateReadTokenResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	s.True(strings.HasPrefix(created.Token, domain.ReadTokenPrefix))
+	s.Equal(s.workspaceID, created.ReadToken.WorkspaceID)
+
+	// Read token can fetch workspace stats
+	w = s.serveRequest("GET", "/api/v1/stats", created.Token, nil)
+	s.Equal(http.StatusOK, w.Code)
+
+	// Read token cannot access agent endpoints
+	w = s.serveRequest("GET", "/api/v1/tasks", created.Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	// Revoked token is rejected
+	w = s.serveRequest("DELETE", "/api/v1/admin/read-tokens/"+created.ReadToken.ID, testAdminToken, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/stats", created.Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("GET", tokensPath, testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var list dto.ReadTokensListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Require().Len(list.ReadTokens, 1)
+	s.NotNil(list.ReadTokens[0].RevokedAt)
+}
+
+func (s *HandlerTestSuite) TestGetTask_DeadlineInSeconds() {
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Deadline Task"),
+		factory.WithStatus(domain.TaskStatusInProgress),
+		factory.WithAssignee(s.agent1ID),
+		factory.WithStatusDeadline(time.Now().Add(time.Hour)),
+	).ID
+
+	w := s.makeRequest("GET", "/api/v1/tasks/"+taskID, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&detail))
+	s.False(detail.Task.ServerTime.IsZero())
+	s.Require().NotNil(detail.Task.DeadlineInSeconds)
+	s.InDelta(3600, *detail.Task.DeadlineInSeconds, 60)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?assignee=me", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var list dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Require().Len(list.Tasks, 1)
+	s.Require().NotNil(list.Tasks[0].DeadlineInSeconds)
+	s.False(list.Tasks[0].ServerTime.IsZero())
+}
+
+func (s *HandlerTestSuite) TestGetTask_ETag() {
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Polled Task")).ID
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+s.agent1Token)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		mux := http.NewServeMux()
+		s.handler.RegisterRoutes(mux)
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/api/v1/tasks/" + taskID, "/api/v1/tasks"} {
+		w := get(path, "")
+		s.Require().Equal(http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		s.Require().True(strings.HasPrefix(etag, `W/"`), path)
+
+		w = get(path, etag)
+		s.Equal(http.StatusNotModified, w.Code, path)
+		s.Zero(w.Body.Len())
+	}
+
+	w := get("/api/v1/tasks/"+taskID, "")
+	etag := w.Header().Get("ETag")
+	w = s.makeRequest("POST", "/api/v1/tasks/"+taskID+"/comments", s.agent1Token, dto.CommentTaskRequest{Comment: "New detail"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	w = get("/api/v1/tasks/"+taskID, etag)
+	s.Equal(http.StatusOK, w.Code, "a new event changes the ETag")
+	s.NotEqual(etag, w.Header().Get("ETag"))
+
+	etag = w.Header().Get("ETag")
+	w = s.makeRequest("POST", "/api/v1/tasks/"+taskID+"/checklist", s.agent1Token, dto.AddChecklistItemRequest{Title: "Write the migration"})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	w = get("/api/v1/tasks/"+taskID, etag)
+	s.Require().Equal(http.StatusOK, w.Code, "a new checklist item changes the ETag")
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&detail))
+	s.Len(detail.Task.Checklist, 1)
+}
+
+func (s *HandlerTestSuite) TestTransitionStatus_ErrorDetails() {
+	blockerID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Blocker")).ID
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Blocked Task"),
+		factory.WithAssignee(s.agent1ID),
+		factory.WithBlockedBy(blockerID),
+	).ID
+	version := 1
+	transition := func(taskID string, status domain.TaskStatus) dto.ErrorDetail {
+		w := s.makeRequest("PATCH", "/api/v1/tasks/"+taskID+"/status", s.agent1Token, dto.TransitionStatusRequest{
+			Status: string(status), Comment: "Trying", Artefact: "https://example.com/pr/1",
+			ExpectedVersion: &version,
+		})
+		s.Require().Equal(http.StatusConflict, w.Code, w.Body.String())
+		var errResp dto.ErrorResponse
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+		return errResp.Error
+	}
+
+	detail := transition(taskID, domain.TaskStatusInProgress)
+	s.Equal("UNRESOLVED_BLOCKERS", detail.Code)
+	s.Equal([]any{map[string]any{"id": blockerID, "status": "NEW"}}, detail.Details["unresolved_blockers"])
+
+	doneID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusDone), factory.WithAssignee(s.agent1ID)).ID
+	detail = transition(doneID, domain.TaskStatusInProgress)
+	s.Equal("INVALID_TRANSITION", detail.Code)
+	s.Equal("DONE", detail.Details["status"])
+	s.Equal([]any{}, detail.Details["allowed_statuses"])
+
+	w := s.makeRequest("GET", "/api/v1/errors", "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var catalog dto.ErrorCatalogResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&catalog))
+	s.Contains(catalog.Errors, dto.ErrorCatalog[0])
+}
+
+func (s *HandlerTestSuite) TestEditTask_VersionConflict() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Shared Task"))
+	s.Require().Equal(1, task.Version)
+	edit := func(title string, ifMatch string, expected *int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(dto.EditTaskRequest{Title: &title, ExpectedVersion: expected})
+		req := httptest.NewRequest("PATCH", "/api/v1/tasks/"+task.ID, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+s.agent1Token)
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		mux := http.NewServeMux()
+		s.handler.RegisterRoutes(mux)
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := edit("First Edit", `"1"`, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// The second writer still holds version 1
+	w = edit("Second Edit", `"1"`, nil)
+	s.Equal(http.StatusConflict, w.Code)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+	s.Equal("VERSION_CONFLICT", errResp.Error.Code)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+task.ID, s.agent1Token, nil)
+	s.Equal("2", w.Header().Get("X-Sloptask-Task-Version"))
+	etag := w.Header().Get("ETag")
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&detail))
+	s.Equal("First Edit", detail.Task.Title)
+	s.Equal(2, detail.Task.Version)
+
+	version := 2
+	w = edit("Second Edit", "", &version)
+	s.Equal(http.StatusOK, w.Code)
+	w = edit("Third Edit", `"3"`, &version)
+	s.Equal(http.StatusUnprocessableEntity, w.Code, "header and body disagree")
+	w = edit("Third Edit", etag, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code, "the ETag is not a version")
+
+	// Writes must name the version they were based on
+	for _, ifMatch := range []string{"", "*"} {
+		w = edit("Third Edit", ifMatch, nil)
+		s.Equal(http.StatusPreconditionRequired, w.Code, ifMatch)
+		s.Require().NoError(json.NewDecoder(w.Body).Decode(&errResp))
+		s.Equal("PRECONDITION_REQUIRED", errResp.Error.Code)
+	}
+}
+
+func (s *HandlerTestSuite) TestGetTask_RelativeTimeFormat() {
+	now := time.Now()
+	taskID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Deadline Task"),
+		factory.WithStatus(domain.TaskStatusInProgress),
+		factory.WithAssignee(s.agent1ID),
+		factory.WithStatusDeadline(now.Add(35*time.Minute+30*time.Second)),
+		factory.WithCreatedAt(now.Add(-2*time.Hour)),
+	).ID
+
+	w := s.makeRequest("GET", "/api/v1/tasks/"+taskID+"?time_format=relative", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&detail))
+	s.Equal("2h ago", detail.Task.CreatedAtRelative)
+	s.Require().NotNil(detail.Task.StatusDeadlineRelative)
+	s.Equal("due in 35m", *detail.Task.StatusDeadlineRelative)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?assignee=me&time_format=relative", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var list dto.TasksListResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&list))
+	s.Require().Len(list.Tasks, 1)
+	s.Equal("2h ago", list.Tasks[0].CreatedAtRelative)
+
+	// Absolute timestamps only by default
+	w = s.makeRequest("GET", "/api/v1/tasks?assignee=me", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), "_relative")
+
+	w = s.makeRequest("GET", "/api/v1/tasks?time_format=fuzzy", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestEditTask_RevisionHistory() {
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Write the spec",
+		Description: "Step one\nStep two",
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var task dto.TaskDetail
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&task))
+
+	description := "Step one\nStep two, revised"
+	w = s.makeRequest("PATCH", "/api/v1/tasks/"+task.ID, s.agent1Token, dto.EditTaskRequest{Description: &description, ExpectedVersion: &task.Version})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var edit dto.EditTaskResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&edit))
+	s.Require().NotNil(edit.Revision)
+	s.Equal(2, *edit.Revision)
+	s.Require().NotNil(edit.Event)
+	s.Equal("edited", edit.Event.Type)
+
+	// Same text again is a no-op
+	version := task.Version + 1
+	w = s.makeRequest("PATCH", "/api/v1/tasks/"+task.ID, s.agent1Token, dto.EditTaskRequest{Description: &description, ExpectedVersion: &version})
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"revision": null, "event": null}`, w.Body.String())
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+task.ID+"/revisions", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var history dto.TaskRevisionsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&history))
+	s.Require().Len(history.Revisions, 2)
+	s.Equal("Step one\nStep two", history.Revisions[0].Description)
+	s.Empty(history.Revisions[0].DescriptionDiff)
+	s.Equal("@@ -1,2 +1,2 @@\n Step one\n-Step two\n+Step two, revised\n", history.Revisions[1].DescriptionDiff)
+	s.Empty(history.Revisions[1].TitleDiff)
+}
+
+func (s *HandlerTestSuite) TestExportWorkspace_Snapshot() {
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Exported task",
+		Description: "Included in the snapshot",
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/export", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), s.agent1Token)
+
+	var export dto.WorkspaceExportResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &export))
+	s.Equal(s.workspaceID, export.Manifest.WorkspaceID)
+	s.NotEmpty(export.Manifest.SnapshotLSN)
+	s.NotEmpty(export.Manifest.TxSnapshot)
+	s.Equal(len(export.Tasks), export.Manifest.TaskCount)
+	s.Equal(len(export.Events), export.Manifest.EventCount)
+	s.Require().Len(export.Tasks, 1)
+	s.Equal("Exported task", export.Tasks[0].Title)
+}
+
+func (s *HandlerTestSuite) TestExportWorkspace_NDJSON() {
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Exported task",
+		Description: "Included in the snapshot",
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/export?format=ndjson", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	counts := make(map[string]int)
+	for i, line := range lines {
+		var record struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		s.Require().NoError(json.Unmarshal([]byte(line), &record))
+		counts[record.Type]++
+		if i == 0 {
+			s.Equal(dto.ExportRecordManifest, record.Type)
+		}
+	}
+	s.Equal(1, counts[dto.ExportRecordWorkspace])
+	s.Equal(2, counts[dto.ExportRecordAgent])
+	s.Equal(1, counts[dto.ExportRecordTask])
+	s.Equal(1, counts[dto.ExportRecordEvent])
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/export?format=xml", testAdminToken, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestExportEventLog_HashChain() {
+	for _, title := range []string{"First logged task", "Second logged task"} {
+		w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+			Title:       title,
+			Description: "Recorded in the event log",
+		})
+		s.Require().Equal(http.StatusCreated, w.Code)
+	}
+	// Fresh events are held back until they settle
+	w := s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/event-log", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Empty(w.Body.String())
+
+	_, err := s.pool.Exec(context.Background(),
+		`UPDATE task_events SET created_at = created_at - INTERVAL '20 minutes'`)
+	s.Require().NoError(err)
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/event-log", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("application/x-ndjson", w.Header().Get("Content-Type"))
+	full := w.Body.String()
+
+	summary, err := dto.VerifyEventLog(strings.NewReader(full))
+	s.Require().NoError(err)
+	s.Equal(2, summary.Records)
+	s.Equal(2, summary.LastSeq)
+
+	// The tail continues the chain of the full log
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/event-log?after=1", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal(strings.SplitAfter(full, "\n")[1], w.Body.String())
+
+	// Editing an event breaks the chain
+	_, err = dto.VerifyEventLog(strings.NewReader(strings.Replace(full, `"comment":"Task created"`, `"comment":"Task forged"`, 1)))
+	s.Require().Error(err)
+	s.Contains(err.Error(), "hash mismatch")
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces/"+s.workspaceID+"/event-log?after=-1", testAdminToken, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestGetQueueDepth_CountsClaimableTasks() {
+	open := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithPriority(domain.TaskPriorityHigh))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithRequiredCapabilities("coder"))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithBlockedBy(open.ID))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.Private())
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	)
+
+	w := s.serveRequest("GET", "/api/v1/stats/queue-depth", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var depth dto.QueueDepthResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &depth))
+	s.Equal(2, depth.Claimable)
+	s.Equal(1, depth.Unrestricted)
+	s.Equal(map[string]int{"low": 0, "normal": 1, "high": 1, "critical": 0}, depth.ByPriority)
+	s.Equal(map[string]int{"coder": 1}, depth.ByCapability)
+	s.Equal(map[string]int{"_default": 2}, depth.ByQueue)
+	s.False(depth.ServerTime.IsZero())
+}
+
+func (s *HandlerTestSuite) TestGetMetrics_OpenMetrics() {
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithStatus(domain.TaskStatusDone))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithStatus(domain.TaskStatusDone))
+
+	w := s.serveRequest("GET", "/api/v1/stats/metrics", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("application/openmetrics-text; version=1.0.0; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	ws := `workspace_id="` + s.workspaceID + `"`
+	s.Contains(body, "# TYPE sloptask_tasks gauge\n")
+	s.Contains(body, "sloptask_tasks{"+ws+`,status="NEW"} 1`+"\n")
+	s.Contains(body, "sloptask_tasks{"+ws+`,status="DONE"} 2`+"\n")
+	s.Contains(body, "sloptask_tasks{"+ws+`,status="STUCK"} 0`+"\n")
+	s.Contains(body, "sloptask_tasks_claimable{"+ws+"} 1\n")
+	s.Contains(body, "sloptask_completion_ratio{"+ws+"} 0.5\n")
+	s.Contains(body, "sloptask_agent_tasks_in_progress{"+ws+`,agent_id="`+s.agent2ID+`",agent_name=`)
+	s.True(strings.HasSuffix(body, "# EOF\n"))
+
+	w = s.serveRequest("GET", "/api/v1/stats/metrics", "", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *HandlerTestSuite) TestCoordinatorToken_ReadsSelectedWorkspace() {
+	public := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	private := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.Private())
+	other := factory.CreateWorkspace(s.T(), s.pool, factory.WithSlug("other-team"))
+	otherAgent := factory.CreateAgent(s.T(), s.pool, other.ID)
+	factory.CreateTask(s.T(), s.pool, other.ID, otherAgent.ID, factory.WithStatus(domain.TaskStatusStuck))
+
+	// Selected by slug: public tasks of that workspace only
+	w := s.serveRequest("GET", "/api/v1/tasks?workspace=test", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var list dto.TasksListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Equal(1, list.Total)
+	s.Require().Len(list.Tasks, 1)
+	s.Equal(public.ID, list.Tasks[0].ID)
+
+	w = s.serveRequest("GET", "/api/v1/tasks/"+public.ID+"?workspace=test", testAdminToken, nil)
+	s.Equal(http.StatusOK, w.Code)
+	w = s.serveRequest("GET", "/api/v1/tasks/"+private.ID+"?workspace=test", testAdminToken, nil)
+	s.Equal(http.StatusForbidden, w.Code)
+	w = s.serveRequest("GET", "/api/v1/tasks/"+public.ID+"?workspace=other-team", testAdminToken, nil)
+	s.Equal(http.StatusForbidden, w.Code)
+
+	// Selected by ID through the header
+	req := httptest.NewRequest("GET", "/api/v1/stats", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set("X-Sloptask-Workspace", other.ID)
+	w = httptest.NewRecorder()
+	mux := http.NewServeMux()
+	s.handler.RegisterRoutes(mux)
+	mux.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var stats dto.StatsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &stats))
+	s.Equal(1, stats.Workspace.TasksByStatus["STUCK"])
+
+	w = s.serveRequest("GET", "/api/v1/tasks", testAdminToken, nil)
+	s.Equal(http.StatusBadRequest, w.Code)
+	w = s.serveRequest("GET", "/api/v1/tasks?workspace=missing", testAdminToken, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+	w = s.serveRequest("GET", "/api/v1/tasks?workspace=test&assignee=me", testAdminToken, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	// The admin token is no agent token
+	w = s.serveRequest("POST", "/api/v1/tasks?workspace=test", testAdminToken, dto.CreateTaskRequest{Title: "No", Description: "No"})
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/admin/workspaces", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var workspaces dto.WorkspacesResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &workspaces))
+	bySlug := map[string]dto.WorkspaceOverview{}
+	for _, workspace := range workspaces.Workspaces {
+		bySlug[workspace.Slug] = workspace
+	}
+	s.Equal(2, bySlug["test"].TasksByStatus["NEW"])
+	s.Equal(1, bySlug["other-team"].TasksByStatus["STUCK"])
+}
+
+func (s *HandlerTestSuite) TestIntakeForm_SubmissionsAndLimits() {
+	intakePath := "/api/v1/admin/workspaces/" + s.workspaceID + "/intake"
+
+	w := s.serveRequest("PUT", intakePath, testAdminToken, dto.SetIntakeFormRequest{AgentID: s.agent1ID, RateLimitPerHour: 2})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var form dto.IntakeFormResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &form))
+	s.Require().NotNil(form.Key)
+	s.Equal("/api/v1/intake/test", form.SubmitPath)
+
+	submit := func(contentType, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", form.SubmitPath, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if key != "" {
+			req.Header.Set("X-Sloptask-Intake-Key", key)
+		}
+		w := httptest.NewRecorder()
+		mux := http.NewServeMux()
+		s.handler.RegisterRoutes(mux)
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w = submit("application/json", `{"title":"Broken export button","description":"Export fails on Safari","contact":"ann@example.com"}`, *form.Key)
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var submitted dto.IntakeSubmissionResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &submitted))
+	s.Equal("NEW", submitted.Status)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+submitted.TaskID, s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &detail))
+	s.Equal([]string{"intake"}, detail.Task.Labels)
+	var created *dto.TaskEventInfo
+	for i := range detail.Events {
+		if detail.Events[i].Type == "created" {
+			created = &detail.Events[i]
+		}
+	}
+	s.Require().NotNil(created)
+	s.Equal("intake", created.Data["source"])
+	s.Equal("ann@example.com", created.Data["contact"])
+
+	// HTML form post with the key in a field; the title is within 200
+	// characters though longer than 200 bytes
+	title := strings.TrimSpace(strings.Repeat("Тёмная тема ", 10))
+	w = submit("application/x-www-form-urlencoded", url.Values{"title": {title}, "description": {"Please"}, "key": {*form.Key}}.Encode(), "")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	// The workspace accepts two submissions per hour
+	w = submit("application/json", `{"title":"Third request","description":"Over the limit"}`, *form.Key)
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.Contains(w.Body.String(), "RATE_LIMITED")
+
+	w = submit("application/json", `{"title":"Wrong key","description":"Rejected"}`, "sli_wrong")
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	// The form's key is never shown again
+	w = s.serveRequest("GET", intakePath, testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), *form.Key)
+
+	w = s.serveRequest("DELETE", intakePath, testAdminToken, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+	w = submit("application/json", `{"title":"After disabling","description":"Gone"}`, *form.Key)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	// Five requests per client IP and hour, whatever the outcome
+	w = submit("application/json", `{"title":"Sixth request","description":"Throttled"}`, *form.Key)
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.NotEmpty(w.Header().Get("Retry-After"))
+}
+
+func (s *HandlerTestSuite) TestRuntimeConfig_Reload() {
+	// The shared handler has no settings file
+	w := s.serveRequest("POST", "/api/v1/admin/config/reload", testAdminToken, nil)
+	s.Equal(http.StatusConflict, w.Code)
+
+	path := filepath.Join(s.T().TempDir(), "runtime.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 1}`), 0o600))
+	runtime, err := config.NewRuntimeStore(path, config.DefaultRuntime())
+	s.Require().NoError(err)
+
+	mux := http.NewServeMux()
+	handler.New(s.pool, handler.Config{AdminToken: testAdminToken, Runtime: runtime}).RegisterRoutes(mux)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w = serve("GET", "/api/v1/admin/config")
+	s.Require().Equal(http.StatusOK, w.Code)
+	var settings dto.RuntimeConfigResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&settings))
+	s.Equal(dto.RuntimeConfigResponse{File: path, LogLevel: "info", IntakeRequestsPerHour: 1}, settings)
+
+	// An invalid file keeps the running settings
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": -1}`), 0o600))
+	w = serve("POST", "/api/v1/admin/config/reload")
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Equal(1, runtime.Current().IntakeRequestsPerHour)
+
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 50}`), 0o600))
+	w = serve("POST", "/api/v1/admin/config/reload")
+	s.Require().Equal(http.StatusOK, w.Code)
+	var reloaded dto.ReloadConfigResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&reloaded))
+	s.Equal(50, reloaded.Settings.IntakeRequestsPerHour)
+	s.Contains(reloaded.Changes, "intake_requests_per_hour")
+
+	action := domain.AuditConfigReloaded
+	entries, err := repository.NewAuditRepository(s.pool).List(context.Background(), repository.AuditFilters{Action: &action, Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Equal(handler.ReloadSourceAPI, entries[0].Details["source"])
+	s.Nil(entries[0].WorkspaceID)
+}
+
+func (s *HandlerTestSuite) TestReadOnlyMode() {
+	readOnly := middleware.NewReadOnly(false, nil)
+	mux := http.NewServeMux()
+	handler.New(s.pool, handler.Config{AdminToken: testAdminToken, ReadOnly: readOnly}).RegisterRoutes(mux)
+	routes := readOnly.Guard(mux)
+	serve := func(method, path, token string, body any) *httptest.ResponseRecorder {
+		bodyBytes, err := json.Marshal(body)
+		s.Require().NoError(err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, req)
+		return w
+	}
+	createTask := dto.CreateTaskRequest{Title: "Maintenance", Description: "Written while read-only"}
+
+	w := serve("PUT", "/api/v1/admin/read-only", testAdminToken, dto.SetReadOnlyRequest{Enabled: true})
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.True(readOnly.Enabled())
+
+	// Writes wait for the maintenance to end, reads go on
+	w = serve("POST", "/api/v1/tasks", s.agent1Token, createTask)
+	s.Equal(http.StatusServiceUnavailable, w.Code)
+	s.Equal("60", w.Header().Get("Retry-After"))
+	w = serve("GET", "/api/v1/tasks", s.agent1Token, nil)
+	s.Equal(http.StatusOK, w.Code)
+
+	w = serve("GET", "/api/v1/admin/config", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var settings dto.RuntimeConfigResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&settings))
+	s.True(settings.ReadOnly)
+
+	w = serve("PUT", "/api/v1/admin/read-only", testAdminToken, dto.SetReadOnlyRequest{Enabled: false})
+	s.Require().Equal(http.StatusOK, w.Code)
+	w = serve("POST", "/api/v1/tasks", s.agent1Token, createTask)
+	s.Equal(http.StatusCreated, w.Code)
+
+	action := domain.AuditReadOnly
+	entries, err := repository.NewAuditRepository(s.pool).List(context.Background(), repository.AuditFilters{Action: &action, Limit: 10})
+	s.Require().NoError(err)
+	s.Len(entries, 2)
+}
+
+func (s *HandlerTestSuite) TestReadOnlyMode_SurvivesUnrelatedReload() {
+	path := filepath.Join(s.T().TempDir(), "runtime.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 1}`), 0o600))
+	runtime, err := config.NewRuntimeStore(path, config.DefaultRuntime())
+	s.Require().NoError(err)
+
+	readOnly := middleware.NewReadOnly(false, nil)
+	mux := http.NewServeMux()
+	handler.New(s.pool, handler.Config{AdminToken: testAdminToken, Runtime: runtime, ReadOnly: readOnly}).RegisterRoutes(mux)
+	serve := func(method, path string, body any) *httptest.ResponseRecorder {
+		bodyBytes, err := json.Marshal(body)
+		s.Require().NoError(err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("PUT", "/api/v1/admin/read-only", dto.SetReadOnlyRequest{Enabled: true})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	// Reloading other settings keeps the mode an operator switched on
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 50}`), 0o600))
+	w = serve("POST", "/api/v1/admin/config/reload", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.True(readOnly.Enabled())
+
+	// Changing read_only in the file applies it
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 50, "read_only": true}`), 0o600))
+	w = serve("POST", "/api/v1/admin/config/reload", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(os.WriteFile(path, []byte(`{"intake_requests_per_hour": 50}`), 0o600))
+	w = serve("POST", "/api/v1/admin/config/reload", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.False(readOnly.Enabled())
+}
+
+func (s *HandlerTestSuite) TestGrafanaQuery_SeriesAndTable() {
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title:       "Charted task",
+		Description: "Counted in tasks_created",
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	now := time.Now()
+	w = s.serveRequest("POST", "/api/v1/grafana/query", s.agent1Token, dto.GrafanaQueryRequest{
+		Range:      &dto.GrafanaRange{From: now.Add(-time.Hour), To: now.Add(time.Minute)},
+		IntervalMs: 1000,
+		Targets: []dto.GrafanaTarget{
+			{RefID: "A", Target: "tasks_created"},
+			{RefID: "B", Target: dto.GrafanaTableTasksByStatus},
+		},
+	})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var results []json.RawMessage
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &results))
+	s.Require().Len(results, 2)
+
+	// One-second intervals are widened to a minute
+	var series dto.GrafanaTimeseries
+	s.Require().NoError(json.Unmarshal(results[0], &series))
+	s.Equal("A", series.RefID)
+	s.InDelta(62, len(series.Datapoints), 1)
+	total := int64(0)
+	for _, point := range series.Datapoints {
+		total += point[0]
+	}
+	s.Equal(int64(1), total)
+
+	var table struct {
+		Type string  `json:"type"`
+		Rows [][]any `json:"rows"`
+	}
+	s.Require().NoError(json.Unmarshal(results[1], &table))
+	s.Equal("table", table.Type)
+	s.Contains(table.Rows, []any{"NEW", float64(1)})
+
+	w = s.serveRequest("POST", "/api/v1/grafana/query", s.agent1Token, dto.GrafanaQueryRequest{
+		Range:   &dto.GrafanaRange{From: now.Add(-time.Hour), To: now},
+		Targets: []dto.GrafanaTarget{{RefID: "A", Target: "nope"}},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serveRequest("POST", "/api/v1/grafana/variable", s.agent1Token, map[string]any{"payload": map[string]string{"target": "agents"}})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var agents []dto.GrafanaVariableValue
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &agents))
+	s.Len(agents, 2)
+}
+
+func (s *HandlerTestSuite) TestGetStats_LeadAndCycleTime() {
+	now := time.Now()
+	inProgress, done := domain.TaskStatusInProgress, domain.TaskStatusDone
+
+	// Lead time 180 and 70 minutes, cycle time 120 and 60 minutes
+	for _, timing := range []struct{ created, started, completed time.Duration }{
+		{4 * time.Hour, 3 * time.Hour, time.Hour},
+		{100 * time.Minute, 90 * time.Minute, 30 * time.Minute},
+	} {
+		task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+			factory.WithStatus(done), factory.WithAssignee(s.agent2ID), factory.WithCreatedAt(now.Add(-timing.created)),
+		)
+		factory.CreateEventChain(s.T(), s.pool, task.ID,
+			domain.TaskEvent{ActorID: &s.agent2ID, NewStatus: &inProgress, CreatedAt: now.Add(-timing.started)},
+			domain.TaskEvent{ActorID: &s.agent2ID, NewStatus: &done, CreatedAt: now.Add(-timing.completed)},
+		)
+	}
+
+	w := s.serveRequest("GET", "/api/v1/stats?period=day", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var stats dto.StatsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &stats))
+	s.InDelta(125, stats.Workspace.AvgLeadTimeMinutes, 0.1)
+	s.InDelta(90, stats.Workspace.AvgCycleTimeMinutes, 0.1)
+
+	byAgent := make(map[string]dto.AgentStats)
+	for _, agent := range stats.Agents {
+		byAgent[agent.AgentID] = agent
+	}
+	s.InDelta(125, byAgent[s.agent2ID].AvgLeadTimeMinutes, 0.1)
+	s.InDelta(90, byAgent[s.agent2ID].AvgCycleTimeMinutes, 0.1)
+	s.Zero(byAgent[s.agent1ID].AvgLeadTimeMinutes)
+}
+
+func (s *HandlerTestSuite) TestDeleteWorkspace_ArchiveExportDelete() {
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	)
+	path := "/api/v1/admin/workspaces/" + s.workspaceID
+
+	w := s.serveRequest("DELETE", path+"?confirm=wrong", testAdminToken, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	// The first deletion attempt archives the workspace and asks for an export
+	w = s.serveRequest("DELETE", path+"?confirm=test", testAdminToken, nil)
+	s.Require().Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "EXPORT_REQUIRED")
+
+	w = s.serveRequest("GET", "/api/v1/tasks", s.agent1Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code, "archiving revokes agent tokens")
+
+	w = s.serveRequest("POST", path+"/archive", testAdminToken, nil)
+	s.Equal(http.StatusConflict, w.Code)
+
+	w = s.serveRequest("GET", path+"/export", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.serveRequest("DELETE", path+"?confirm=test", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var deleted dto.DeleteWorkspaceResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &deleted))
+	s.Equal(int64(2), deleted.TasksDeleted)
+	s.NotNil(deleted.ExportedAt)
+
+	var remaining int
+	s.Require().NoError(s.pool.QueryRow(context.Background(),
+		"SELECT (SELECT COUNT(*) FROM workspaces WHERE id = $1) + (SELECT COUNT(*) FROM agents WHERE workspace_id = $1)",
+		s.workspaceID,
+	).Scan(&remaining))
+	s.Zero(remaining)
+
+	w = s.serveRequest("GET", "/api/v1/admin/audit?workspace_id="+s.workspaceID, testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var audit dto.AuditLogResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &audit))
+	actions := make([]string, len(audit.Entries))
+	for i, entry := range audit.Entries {
+		actions[i] = entry.Action
+	}
+	s.Equal([]string{"workspace.deleted", "workspace.exported", "workspace.archived"}, actions)
+}
+
+func (s *HandlerTestSuite) TestCreateSandbox() {
+	ctx := context.Background()
+	blocker := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID), factory.WithPriority(domain.TaskPriorityHigh),
+	)
+	blocked := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusBlocked), factory.WithBlockedBy(blocker.ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithStatus(domain.TaskStatusDone))
+	path := "/api/v1/admin/workspaces/" + s.workspaceID + "/sandbox"
+
+	w := s.serveRequest("POST", path, s.agent1Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("POST", path, testAdminToken, dto.CreateSandboxRequest{TTL: "soon"})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serveRequest("POST", path, testAdminToken, dto.CreateSandboxRequest{Slug: "test-sandbox", TTL: "24h"})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var sandbox dto.CreateSandboxResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &sandbox))
+	s.Equal("test-sandbox", sandbox.Slug)
+	s.Equal(s.workspaceID, sandbox.SandboxOf)
+	s.WithinDuration(time.Now().Add(24*time.Hour), sandbox.ExpiresAt, time.Minute)
+	s.Equal(int64(2), sandbox.TasksCloned, "closed tasks are not cloned")
+	s.Require().Len(sandbox.Agents, 2)
+	for _, agent := range sandbox.Agents {
+		s.NotContains([]string{s.agent1Token, s.agent2Token}, agent.Token)
+	}
+
+	// Cloned agents work in the sandbox, on tasks remapped to the clones
+	sandboxAgent2 := sandbox.Agents[1]
+	s.Equal("agent-2", sandboxAgent2.Name)
+	w = s.makeRequest("GET", "/api/v1/tasks?assignee=me", sandboxAgent2.Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var tasks dto.TasksListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &tasks))
+	s.Require().Len(tasks.Tasks, 1)
+	clonedBlocker := tasks.Tasks[0]
+	s.NotEqual(blocker.ID, clonedBlocker.ID)
+	s.Equal(blocker.Title, clonedBlocker.Title)
+	s.Equal(string(domain.TaskStatusInProgress), clonedBlocker.Status)
+
+	var clonedBlockedBy []string
+	s.Require().NoError(s.pool.QueryRow(ctx,
+		`SELECT ARRAY(SELECT d.blocker_id FROM task_dependencies d WHERE d.task_id = t.id)
+		FROM tasks t WHERE t.workspace_id = $1 AND t.title = $2`, sandbox.WorkspaceID, blocked.Title,
+	).Scan(&clonedBlockedBy))
+	s.Equal([]string{clonedBlocker.ID}, clonedBlockedBy)
+
+	w = s.serveRequest("POST", path, testAdminToken, dto.CreateSandboxRequest{Slug: "test-sandbox"})
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "WORKSPACE_EXISTS")
+
+	w = s.serveRequest("POST", "/api/v1/admin/workspaces/"+sandbox.WorkspaceID+"/sandbox", testAdminToken, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code, "sandboxes cannot be cloned")
+
+	// Expired sandboxes are purged; the source stays
+	workspaceService := service.NewWorkspaceService(
+		s.pool,
+		repository.NewWorkspaceRepository(s.pool),
+		repository.NewAgentRepository(s.pool),
+		repository.NewReadTokenRepository(s.pool),
+		repository.NewScheduleRepository(s.pool),
+		repository.NewReportRepository(s.pool),
+		repository.NewAuditRepository(s.pool),
+	)
+	purged, err := workspaceService.PurgeExpiredSandboxes(ctx, time.Now())
+	s.Require().NoError(err)
+	s.Zero(purged)
+
+	purged, err = workspaceService.PurgeExpiredSandboxes(ctx, time.Now().Add(25*time.Hour))
+	s.Require().NoError(err)
+	s.Equal(1, purged)
+
+	var workspaces []string
+	s.Require().NoError(s.pool.QueryRow(ctx, "SELECT array_agg(slug) FROM workspaces").Scan(&workspaces))
+	s.Equal([]string{"test"}, workspaces)
+
+	w = s.makeRequest("GET", "/api/v1/tasks", sandboxAgent2.Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *HandlerTestSuite) TestAgentHeartbeat() {
+	w := s.makeRequest("GET", "/api/v1/agents", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var agents dto.AgentsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &agents))
+	s.Equal(300, agents.StaleAfterSeconds)
+	s.Require().Len(agents.Agents, 2)
+	s.Nil(agents.Agents[0].LastSeenAt)
+	s.False(agents.Agents[0].Stale, "agents without heartbeats are not stale")
+
+	w = s.makeRequest("POST", "/api/v1/agents/me/heartbeat", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var heartbeat dto.HeartbeatResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &heartbeat))
+	s.Equal(s.agent1ID, heartbeat.AgentID)
+	s.WithinDuration(time.Now(), heartbeat.LastSeenAt, 5*time.Second)
+
+	_, err := s.pool.Exec(context.Background(),
+		"UPDATE agents SET last_seen_at = NOW() - INTERVAL '10 minutes' WHERE id = $1", s.agent2ID)
+	s.Require().NoError(err)
+
+	w = s.makeRequest("GET", "/api/v1/agents?stale=true", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &agents))
+	s.Require().Len(agents.Agents, 1)
+	s.Equal(s.agent2ID, agents.Agents[0].ID)
+	s.True(agents.Agents[0].Stale)
+
+	w = s.makeRequest("GET", "/api/v1/agents?stale=maybe", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/stats?period=day", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var stats dto.StatsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &stats))
+	s.Equal(1, stats.Workspace.StaleAgentCount)
+	for _, agent := range stats.Agents {
+		s.Equal(agent.AgentID == s.agent2ID, agent.Stale, agent.AgentName)
+	}
+
+	path := "/api/v1/admin/workspaces/" + s.workspaceID + "/agent-staleness"
+	w = s.serveRequest("PUT", path, testAdminToken, dto.SetAgentStaleAfterRequest{StaleAfterSeconds: 5})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = s.serveRequest("PUT", path, testAdminToken, dto.SetAgentStaleAfterRequest{StaleAfterSeconds: 3600})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/agents?stale=true", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &agents))
+	s.Equal(3600, agents.StaleAfterSeconds)
+	s.Empty(agents.Agents)
+}
+
+func (s *HandlerTestSuite) TestGetMe() {
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+		factory.WithStatusDeadline(time.Now().Add(-time.Minute)),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusBlocked), factory.WithAssignee(s.agent1ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusDone), factory.WithAssignee(s.agent1ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+
+	w := s.makeRequest("GET", "/api/v1/agents/me", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var me dto.AgentMeResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &me))
+	s.Equal(s.agent1ID, me.ID)
+	s.Equal(s.workspaceID, me.Workspace.ID)
+	s.Equal("test", me.Workspace.Slug)
+	s.NotNil(me.Capabilities)
+	s.Equal(1, me.WIPCount)
+	s.Equal(2, me.OpenTasks.Assigned)
+	s.Equal(1, me.OpenTasks.AssignedByStatus["BLOCKED"])
+	s.Equal(0, me.OpenTasks.AssignedByStatus["STUCK"])
+	s.NotContains(me.OpenTasks.AssignedByStatus, "DONE")
+	s.Equal(1, me.OpenTasks.Overdue)
+	s.Equal(1, me.OpenTasks.Created)
+
+	w = s.makeRequest("GET", "/api/v1/agents/me", "", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *HandlerTestSuite) TestGetContext() {
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+	)
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+
+	w := s.makeRequest("GET", "/api/v1/context?role=worker", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var response dto.ContextResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(s.agent1ID, response.Agent.ID)
+	s.Equal(1, response.Agent.WIPCount)
+	s.Equal(map[string]int{"NEW": 120, "IN_PROGRESS": 1440, "BLOCKED": 0}, response.Deadlines.StatusMinutes)
+	s.Equal("STUCK", response.Deadlines.Expiry["IN_PROGRESS"])
+	s.Nil(response.Deadlines.WarningPercent)
+	s.Equal(3, response.WorkspaceTasks.Open)
+	s.Equal(2, response.WorkspaceTasks.ByStatus["IN_PROGRESS"])
+	s.Equal(1, response.WorkspaceTasks.Claimable)
+	s.Require().Len(response.Assignments, 1)
+	s.Equal(s.agent1ID, *response.Assignments[0].AssigneeID)
+	s.NotNil(response.RecentEscalations)
+	s.Contains(response.SkillMd, "**Role: worker.**")
+	s.Contains(response.SkillMd, "agent-1")
+
+	w = s.makeRequest("GET", "/api/v1/context?role=boss", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/context", "", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *HandlerTestSuite) TestDirectMessages() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+
+	path := "/api/v1/tasks/" + task.ID + "/messages"
+	w := s.makeRequest("POST", path, s.agent1Token, dto.SendMessageRequest{
+		RecipientID: s.agent2ID,
+		Body:        "Use the staging database for this one",
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var sent dto.MessageInfo
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &sent))
+	s.Equal(s.agent1ID, sent.SenderID)
+	s.Equal(s.agent2ID, sent.RecipientID)
+	s.Nil(sent.ReadAt)
+
+	w = s.makeRequest("POST", path, s.agent1Token, dto.SendMessageRequest{RecipientID: s.agent1ID, Body: "Note to self"})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = s.makeRequest("POST", path, s.agent1Token, dto.SendMessageRequest{RecipientID: "nobody", Body: "Hello"})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = s.makeRequest("POST", path, s.agent1Token, dto.SendMessageRequest{RecipientID: s.agent2ID, Body: "  "})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	// Messages stay out of the task's events
+	w = s.makeRequest("GET", "/api/v1/tasks/"+task.ID+"/events", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), "staging database")
+
+	w = s.makeRequest("POST", "/api/v1/agents/me/heartbeat", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var heartbeat dto.HeartbeatResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &heartbeat))
+	s.Equal(1, heartbeat.UnreadMessages)
+
+	w = s.makeRequest("GET", "/api/v1/messages?unread=true", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var inbox dto.MessagesResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Require().Len(inbox.Messages, 1)
+	s.Equal(sent.ID, inbox.Messages[0].ID)
+
+	// Only the recipient can acknowledge
+	w = s.makeRequest("POST", "/api/v1/messages/"+sent.ID+"/read", s.agent1Token, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+	w = s.makeRequest("POST", "/api/v1/messages/"+sent.ID+"/read", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var read dto.MessageInfo
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &read))
+	s.NotNil(read.ReadAt)
+
+	w = s.makeRequest("GET", "/api/v1/agents/me", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var me dto.AgentMeResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &me))
+	s.Equal(0, me.UnreadMessages)
+
+	// The sender sees the conversation too
+	w = s.makeRequest("GET", "/api/v1/messages?task_id="+task.ID, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Equal(1, inbox.Total)
+
+	// Private tasks can only be discussed with agents that see them
+	private := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.Private())
+	w = s.makeRequest("POST", "/api/v1/tasks/"+private.ID+"/messages", s.agent1Token, dto.SendMessageRequest{
+		RecipientID: s.agent2ID,
+		Body:        "Secret",
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = s.makeRequest("POST", "/api/v1/tasks/"+private.ID+"/messages", s.agent2Token, dto.SendMessageRequest{
+		RecipientID: s.agent1ID,
+		Body:        "Let me in",
+	})
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *HandlerTestSuite) TestWatchTask_Notifications() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID)
+	path := "/api/v1/tasks/" + task.ID + "/watch"
+
+	w := s.makeRequest("POST", path, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var watch dto.WatchInfo
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &watch))
+	s.Equal(task.ID, watch.TaskID)
+	s.Equal(s.agent1ID, watch.AgentID)
+
+	// Watching again keeps the first watch
+	w = s.makeRequest("POST", path, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var again dto.WatchInfo
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &again))
+	s.True(watch.CreatedAt.Equal(again.CreatedAt))
+
+	// Other agents' events are notified, the watcher's own are not
+	comments := "/api/v1/tasks/" + task.ID + "/comments"
+	w = s.makeRequest("POST", comments, s.agent2Token, dto.CommentTaskRequest{Comment: "Schema is ready"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	w = s.makeRequest("POST", comments, s.agent1Token, dto.CommentTaskRequest{Comment: "Thanks"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/notifications", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var inbox dto.NotificationsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Require().Equal(1, inbox.Total)
+	s.Equal(task.ID, inbox.Notifications[0].TaskID)
+	s.Equal(task.Title, inbox.Notifications[0].TaskTitle)
+	s.Equal(string(domain.EventTypeCommented), inbox.Notifications[0].Type)
+	s.Equal("Schema is ready", inbox.Notifications[0].Comment)
+
+	// Agents that don't watch the task get nothing
+	w = s.makeRequest("GET", "/api/v1/notifications", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Equal(0, inbox.Total)
+
+	w = s.makeRequest("DELETE", path, s.agent1Token, nil)
+	s.Require().Equal(http.StatusNoContent, w.Code)
+	w = s.makeRequest("POST", comments, s.agent2Token, dto.CommentTaskRequest{Comment: "Migrations too"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	w = s.makeRequest("GET", "/api/v1/notifications", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Equal(0, inbox.Total)
+
+	// Private tasks can only be watched by agents that see them
+	private := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID, factory.Private())
+	w = s.makeRequest("POST", "/api/v1/tasks/"+private.ID+"/watch", s.agent1Token, nil)
+	s.Equal(http.StatusForbidden, w.Code)
+}
+
+func (s *HandlerTestSuite) TestCommentTask_Mentions() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	path := "/api/v1/tasks/" + task.ID + "/comments"
+
+	w := s.makeRequest("POST", path, s.agent1Token, dto.CommentTaskRequest{Comment: "@agent-2 can you review? Mail ops@example.com if stuck"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var event dto.TaskEventResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &event))
+	s.Equal([]any{s.agent2ID}, event.Data["mentions"])
+
+	// The mentioned agent is notified without watching the task
+	w = s.makeRequest("GET", "/api/v1/notifications?mentioned=true", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var inbox dto.NotificationsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Require().Equal(1, inbox.Total)
+	s.Equal(event.ID, inbox.Notifications[0].ID)
+	s.True(inbox.Notifications[0].Mentioned)
+
+	w = s.makeRequest("POST", path, s.agent1Token, dto.CommentTaskRequest{Comment: "cc @nobody and @agent-2"})
+	s.Require().Equal(http.StatusUnprocessableEntity, w.Code)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &errResp))
+	s.Equal([]any{"nobody"}, errResp.Error.Details["unknown_mentions"])
+
+	w = s.makeRequest("POST", path, s.agent1Token, dto.CommentTaskRequest{
+		Comment: "Done",
+		Data:    map[string]any{"mentions": []string{s.agent2ID}},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	// Agents that can't see a private task can't be mentioned on it
+	private := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.Private())
+	w = s.makeRequest("POST", "/api/v1/tasks/"+private.ID+"/comments", s.agent1Token, dto.CommentTaskRequest{Comment: "@agent-2 look"})
+	s.Require().Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &errResp))
+	s.Equal([]any{"agent-2"}, errResp.Error.Details["hidden_mentions"])
+}
+
+func (s *HandlerTestSuite) TestEditAndRedactComment() {
+	ctx := context.Background()
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	comments := "/api/v1/tasks/" + task.ID + "/comments"
+
+	w := s.makeRequest("POST", comments, s.agent1Token, dto.CommentTaskRequest{Comment: "Deploy with password hunter2"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var posted dto.TaskEventResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &posted))
+	path := comments + "/" + posted.ID
+
+	// Only the author may change it
+	w = s.makeRequest("PATCH", path, s.agent2Token, dto.EditCommentRequest{Comment: "Hijacked"})
+	s.Equal(http.StatusForbidden, w.Code)
+
+	w = s.makeRequest("PATCH", path, s.agent1Token, dto.EditCommentRequest{Comment: "Deploy with the usual password"})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var edited dto.TaskEventResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &edited))
+	s.Equal(posted.ID, edited.ID)
+	s.Equal("Deploy with the usual password", edited.Comment)
+	s.NotNil(edited.EditedAt)
+	s.Nil(edited.RedactedAt)
+
+	w = s.makeRequest("DELETE", path, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var redacted dto.TaskEventResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &redacted))
+	s.Empty(redacted.Comment)
+	s.NotNil(redacted.RedactedAt)
+
+	w = s.makeRequest("PATCH", path, s.agent1Token, dto.EditCommentRequest{Comment: "Again"})
+	s.Equal(http.StatusConflict, w.Code)
+
+	// Earlier bodies are kept for operators, not in the events
+	var previous []string
+	s.Require().NoError(s.pool.QueryRow(ctx, `SELECT previous_comments FROM task_events WHERE id = $1`, posted.ID).Scan(&previous))
+	s.Equal([]string{"Deploy with password hunter2", "Deploy with the usual password"}, previous)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+task.ID+"/events?type=comment_edited", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), "hunter2")
+	var events dto.TaskEventsListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &events))
+	s.Require().Equal(2, events.Total)
+
+	// Past the window, and for events other than comments, nothing changes
+	w = s.makeRequest("POST", comments, s.agent1Token, dto.CommentTaskRequest{Comment: "Old news"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var old dto.TaskEventResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &old))
+	_, err := s.pool.Exec(ctx, `UPDATE task_events SET created_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, old.ID)
+	s.Require().NoError(err)
+	w = s.makeRequest("PATCH", comments+"/"+old.ID, s.agent1Token, dto.EditCommentRequest{Comment: "Fresh news"})
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "COMMENT_EDIT_WINDOW_CLOSED")
+
+	w = s.makeRequest("DELETE", comments+"/"+events.Events[0].ID, s.agent1Token, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *HandlerTestSuite) TestTaskKeys() {
+	first := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	s.Equal(1, first.Number)
+	s.Equal("TEST-1", first.Key)
+
+	w := s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title: "Ship it", Description: "After TEST-1", BlockedBy: []string{"test-1"},
+	})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var created dto.TaskDetail
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	s.Equal(2, created.Number)
+	s.Equal("TEST-2", created.Key)
+	s.Equal([]string{first.ID}, created.BlockedBy)
+
+	// Keys work wherever task IDs do
+	w = s.makeRequest("GET", "/api/v1/tasks/TEST-2", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &detail))
+	s.Equal(created.ID, detail.Task.ID)
+
+	w = s.makeRequest("POST", "/api/v1/tasks/TEST-1/comments", s.agent2Token, dto.CommentTaskRequest{Comment: "On it"})
+	s.Equal(http.StatusCreated, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/tasks?ids=TEST-1,TEST-99", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var list dto.TasksListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Require().Len(list.Tasks, 1)
+	s.Equal("TEST-1", list.Tasks[0].Key)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/TEST-99", s.agent1Token, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+	w = s.makeRequest("GET", "/api/v1/tasks/not-a-task", s.agent1Token, nil)
+	s.Equal(http.StatusBadRequest, w.Code)
+	w = s.makeRequest("POST", "/api/v1/tasks", s.agent1Token, dto.CreateTaskRequest{
+		Title: "Orphan", Description: "Blocked by nothing", BlockedBy: []string{"TEST-99"},
+	})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Contains(w.Body.String(), "TEST-99")
+}
+
+func (s *HandlerTestSuite) TestListTaskComments() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	comments := "/api/v1/tasks/" + task.ID + "/comments"
+
+	var first dto.TaskEventResponse
+	for _, comment := range []string{"First", "Second", "Third"} {
+		w := s.makeRequest("POST", comments, s.agent1Token, dto.CommentTaskRequest{Comment: comment})
+		s.Require().Equal(http.StatusCreated, w.Code)
+		if first.ID == "" {
+			s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &first))
+		}
+	}
+	// Other events, like the comment_edited event of an edit, are left out
+	w := s.makeRequest("PATCH", comments+"/"+first.ID, s.agent1Token, dto.EditCommentRequest{Comment: "First!"})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	list := func(query string) dto.TaskCommentsResponse {
+		w := s.makeRequest("GET", comments+query, s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+		var page dto.TaskCommentsResponse
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+
+	page := list("?limit=2")
+	s.Equal(3, page.Total)
+	s.Require().Len(page.Comments, 2)
+	s.Equal("Third", page.Comments[0].Comment)
+	s.Equal("Second", page.Comments[1].Comment)
+	s.Require().NotNil(page.NextCursor)
+
+	// A comment posted meanwhile doesn't shift the next page
+	w = s.makeRequest("POST", comments, s.agent1Token, dto.CommentTaskRequest{Comment: "Fourth"})
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	page = list("?limit=2&cursor=" + *page.NextCursor)
+	s.Require().Len(page.Comments, 1)
+	s.Equal("First!", page.Comments[0].Comment)
+	s.Nil(page.NextCursor)
+
+	w = s.makeRequest("GET", comments+"?cursor=garbage", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestCommentThreads() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	other := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID)
+	comments := "/api/v1/tasks/" + task.ID + "/comments"
+
+	post := func(token, comment string, replyTo *string) (int, dto.TaskEventResponse) {
+		w := s.makeRequest("POST", comments, token, dto.CommentTaskRequest{Comment: comment, ReplyToEventID: replyTo})
+		var event dto.TaskEventResponse
+		if w.Code == http.StatusCreated {
+			s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &event))
+		}
+		return w.Code, event
+	}
+
+	code, root := post(s.agent1Token, "Escalating: staging is down", nil)
+	s.Require().Equal(http.StatusCreated, code)
+	s.Nil(root.ThreadID)
+
+	code, reply := post(s.agent2Token, "Looking into it", &root.ID)
+	s.Require().Equal(http.StatusCreated, code)
+	s.Equal(&root.ID, reply.ReplyToEventID)
+	s.Equal(&root.ID, reply.ThreadID)
+
+	// A reply to a reply stays in the thread
+	code, nested := post(s.agent1Token, "Any ETA?", &reply.ID)
+	s.Require().Equal(http.StatusCreated, code)
+	s.Equal(&reply.ID, nested.ReplyToEventID)
+	s.Equal(&root.ID, nested.ThreadID)
+
+	code, _ = post(s.agent1Token, "Unrelated", nil)
+	s.Require().Equal(http.StatusCreated, code)
+
+	w := s.makeRequest("GET", "/api/v1/tasks/"+task.ID+"/events?thread="+root.ID, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var thread dto.TaskEventsListResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &thread))
+	s.Require().Equal(3, thread.Total)
+	for _, event := range thread.Events {
+		s.NotEqual("Unrelated", event.Comment)
+	}
+
+	// Only comments of the same task can be replied to
+	w = s.makeRequest("POST", "/api/v1/tasks/"+other.ID+"/comments", s.agent1Token,
+		dto.CommentTaskRequest{Comment: "Wrong task", ReplyToEventID: &root.ID})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	notUUID := "not-a-uuid"
+	code, _ = post(s.agent1Token, "Bad parent", &notUUID)
+	s.Equal(http.StatusUnprocessableEntity, code)
+}
+
+func (s *HandlerTestSuite) TestGetStats_TakeoverAndEscalationCounters() {
+	working := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+	)
+	stuck := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusStuck), factory.WithAssignee(s.agent2ID),
+	)
+
+	w := s.makeRequest("POST", "/api/v1/tasks/"+working.ID+"/escalate", s.agent1Token, dto.EscalateTaskRequest{Comment: "Needs a decision first"})
+	s.Require().Equal(http.StatusOK, w.Code)
+	w = s.makeRequest("POST", "/api/v1/tasks/"+stuck.ID+"/takeover", s.agent1Token, dto.TakeoverTaskRequest{Comment: "Picking this up"})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.serveRequest("GET", "/api/v1/stats?period=day", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var stats dto.StatsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &stats))
+	byAgent := make(map[string]dto.AgentStats)
+	for _, agent := range stats.Agents {
+		byAgent[agent.AgentID] = agent
+	}
+
+	s.Equal(1, byAgent[s.agent1ID].TasksTakenOverByAgent)
+	s.Equal(1, byAgent[s.agent1ID].EscalationsInitiated)
+	s.Zero(byAgent[s.agent1ID].TasksTakenOverFromAgent)
+	s.Zero(byAgent[s.agent1ID].EscalationsReceived)
+
+	s.Equal(1, byAgent[s.agent2ID].TasksTakenOverFromAgent)
+	s.Equal(1, byAgent[s.agent2ID].EscalationsReceived)
+	s.Zero(byAgent[s.agent2ID].TasksTakenOverByAgent)
+	s.Zero(byAgent[s.agent2ID].EscalationsInitiated)
+}
+
+func (s *HandlerTestSuite) TestSkillMd_RoleGuides() {
+	w := s.serveRequest("GET", "/skill.md", "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	full := w.Body.String()
+	s.Contains(full, "### Schedules")
+	s.Contains(full, "### Takeover Task")
+
+	w = s.serveRequest("GET", "/skill.md?role=worker", "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal("text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	worker := w.Body.String()
+	s.Contains(worker, "### Claim Task")
+	s.Contains(worker, "| STUCK | IN_PROGRESS | other agent | POST /takeover |")
+	s.NotContains(worker, "### Schedules")
+	s.NotContains(worker, "POST /reopen")
+	s.NotContains(worker, "## Your Agent")
+
+	w = s.serveRequest("GET", "/skill.md?role=orchestrator", "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	orchestrator := w.Body.String()
+	s.Contains(orchestrator, "### Schedules")
+	s.Contains(orchestrator, "| DONE | NEW | creator | POST /reopen |")
+	s.NotContains(orchestrator, "### Takeover Task")
+
+	w = s.serveRequest("GET", "/skill.md?role=operator", "", nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), "GET /api/v1/admin/audit")
+	s.NotContains(w.Body.String(), "### Claim Task")
+
+	w = s.serveRequest("GET", "/skill.md?role=manager", "", nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+}
+
+func (s *HandlerTestSuite) TestSkillMd_AgentSection() {
+	_, err := s.pool.Exec(context.Background(), `UPDATE agents SET capabilities = ARRAY['coder', 'reviewer'] WHERE id = $1`, s.agent1ID)
+	s.Require().NoError(err)
+
+	w := s.serveRequest("GET", "/skill.md?role=worker", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), "## Your Agent")
+	s.Contains(w.Body.String(), "`test`")
+	s.Contains(w.Body.String(), "coder, reviewer")
+
+	w = s.serveRequest("GET", "/skill.md?role=worker", "invalid-token", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (s *HandlerTestSuite) TestWebhookSecret_RotateAndComplete() {
+	secretPath := "/api/v1/admin/workspaces/" + s.workspaceID + "/webhook-secret"
+
+	w := s.serveRequest("GET", secretPath, testAdminToken, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+
+	w = s.serveRequest("POST", secretPath+"/rotate", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var first dto.RotateWebhookSecretResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &first))
+	s.True(strings.HasPrefix(first.Secret, domain.WebhookSecretPrefix))
+	s.False(first.WebhookSecret.Rotating)
+
+	overlap := 60
+	w = s.serveRequest("POST", secretPath+"/rotate", testAdminToken, dto.RotateWebhookSecretRequest{OverlapMinutes: &overlap})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var second dto.RotateWebhookSecretResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &second))
+	s.NotEqual(first.Secret, second.Secret)
+	s.True(second.WebhookSecret.Rotating)
+	s.Require().NotNil(second.WebhookSecret.PreviousExpiresAt)
+	s.WithinDuration(time.Now().Add(time.Hour), *second.WebhookSecret.PreviousExpiresAt, time.Minute)
+
+	w = s.serveRequest("POST", secretPath+"/rotate", testAdminToken, nil)
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "ROTATION_IN_PROGRESS")
+
+	w = s.serveRequest("GET", secretPath, testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), second.Secret)
+
+	w = s.serveRequest("POST", secretPath+"/complete", testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var info dto.WebhookSecretInfo
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &info))
+	s.False(info.Rotating)
+
+	w = s.serveRequest("POST", secretPath+"/complete", testAdminToken, nil)
+	s.Equal(http.StatusConflict, w.Code)
+	s.Contains(w.Body.String(), "NO_ROTATION_IN_PROGRESS")
+}
+
+func (s *HandlerTestSuite) TestGetTaskTimings() {
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithCreatedAt(time.Now().Add(-time.Hour)))
+
+	w := s.makeRequest("POST", "/api/v1/tasks/"+task.ID+"/claim", s.agent2Token, dto.ClaimTaskRequest{Comment: "Claiming"})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+task.ID+"/timings", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var timings dto.TaskTimingsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &timings))
+	s.Equal("IN_PROGRESS", timings.Status)
+	s.Require().Len(timings.Statuses, 2)
+	s.Equal("NEW", timings.Statuses[0].Status)
+	s.InDelta(3600, timings.Statuses[0].Seconds, 5)
+	s.Equal("IN_PROGRESS", timings.Statuses[1].Status)
+
+	s.Require().Len(timings.Assignees, 1)
+	s.Equal(s.agent2ID, timings.Assignees[0].AgentID)
+	s.NotEmpty(timings.Assignees[0].AgentName)
+
+	s.Require().Len(timings.Periods, 2)
+	s.Nil(timings.Periods[0].AssigneeID)
+	s.NotNil(timings.Periods[0].EndedAt)
+	s.Nil(timings.Periods[1].EndedAt)
+}
+
+func (s *HandlerTestSuite) TestPriorityInheritance_Toggle() {
+	blocker := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithPriority(domain.TaskPriorityLow))
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithPriority(domain.TaskPriorityCritical), factory.WithBlockedBy(blocker.ID))
+	path := "/api/v1/admin/workspaces/" + s.workspaceID + "/priority-inheritance"
+
+	w := s.serveRequest("PUT", path, s.agent1Token, dto.SetPriorityInheritanceRequest{Enabled: true})
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("PUT", path, testAdminToken, dto.SetPriorityInheritanceRequest{Enabled: true})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var response dto.PriorityInheritanceResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.True(response.Enabled)
+	s.Equal(1, response.TasksUpdated)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/"+blocker.ID, s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var detail dto.TaskDetailResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &detail))
+	s.Equal("low", detail.Task.Priority)
+	s.Equal("critical", detail.Task.EffectivePriority)
+	s.Require().NotNil(detail.Task.InheritedPriority)
+	s.Equal("critical", *detail.Task.InheritedPriority)
+	s.Equal(string(domain.EventTypePriorityInherited), detail.Events[len(detail.Events)-1].Type)
+
+	w = s.serveRequest("PUT", path, testAdminToken, dto.SetPriorityInheritanceRequest{Enabled: false})
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(1, response.TasksUpdated)
+}
+
+func (s *HandlerTestSuite) TestEscalationRoutes() {
+	path := "/api/v1/admin/workspaces/" + s.workspaceID + "/escalation-routes"
+	label := "urgent"
+	request := dto.SetEscalationRoutesRequest{Routes: []dto.EscalationRouteRequest{
+		{Name: "urgent", Label: &label, TargetType: "agent", Target: s.agent2ID},
+	}}
+
+	w := s.serveRequest("PUT", path, s.agent1Token, request)
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	w = s.serveRequest("PUT", path, testAdminToken, dto.SetEscalationRoutesRequest{Routes: []dto.EscalationRouteRequest{
+		{Name: "ops", TargetType: "pager", Target: "ops"},
+	}})
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.serveRequest("PUT", path, testAdminToken, request)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.serveRequest("GET", path, testAdminToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var routes dto.EscalationRoutesResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &routes))
+	s.Require().Len(routes.Routes, 1)
+	s.Equal("urgent", routes.Routes[0].Name)
+	s.Equal(s.agent2ID, routes.Routes[0].Target)
+
+	// agent1 escalates agent2's task; the route sends it back to agent2's inbox
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent2ID,
+		factory.WithStatus(domain.TaskStatusInProgress),
+		factory.WithAssignee(s.agent2ID),
+		factory.WithLabels("urgent"),
+	)
+	w = s.makeRequest("POST", "/api/v1/tasks/"+task.ID+"/escalate", s.agent1Token, dto.EscalateTaskRequest{Comment: "Blocked on credentials"})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/escalations", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var inbox dto.EscalationsResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Equal(1, inbox.Total)
+	s.Require().Len(inbox.Escalations, 1)
+	s.Equal(task.ID, inbox.Escalations[0].TaskID)
+	s.Equal("urgent", inbox.Escalations[0].Route)
+	s.Equal("Blocked on credentials", inbox.Escalations[0].Escalation["comment"])
+
+	w = s.makeRequest("GET", "/api/v1/escalations", s.agent1Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &inbox))
+	s.Zero(inbox.Total)
+}
+
+func (s *HandlerTestSuite) TestWaitForTask() {
+	w := s.makeRequest("GET", "/api/v1/tasks/wait?timeout=2m", s.agent1Token, nil)
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+
+	w = s.makeRequest("GET", "/api/v1/tasks/wait?timeout=0s", s.agent2Token, nil)
+	s.Equal(http.StatusNoContent, w.Code)
+
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithTitle("Waiting for a taker"))
+
+	w = s.makeRequest("GET", "/api/v1/tasks/wait?timeout=5s", s.agent2Token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var response dto.WaitForTaskResponse
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(task.ID, response.Task.ID)
+	s.Equal("NEW", response.Task.Status)
+}
+
+// TestAgentCache_InvalidatedByAdminChanges tests that cached agent tokens are
+// reused until an operator changes the agent or revokes its workspace.
+func (s *HandlerTestSuite) TestAgentCache_InvalidatedByAdminChanges() {
+	ctx := context.Background()
+
+	mux := http.NewServeMux()
+	handler.New(s.pool, handler.Config{AdminToken: testAdminToken, AgentCacheTTL: time.Minute}).RegisterRoutes(mux)
+	serve := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		bodyBytes, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	me := func() dto.AgentMeResponse {
+		w := serve("GET", "/api/v1/agents/me", s.agent1Token, nil)
+		s.Require().Equal(http.StatusOK, w.Code)
+		var response dto.AgentMeResponse
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	s.Equal("agent-1", me().Name)
+
+	// Changes made behind the server's back wait for the entry to expire
+	_, err := s.pool.Exec(ctx, `UPDATE agents SET name = 'renamed' WHERE id = $1`, s.agent1ID)
+	s.Require().NoError(err)
+	s.Equal("agent-1", me().Name)
+
+	w := serve("PUT", "/api/v1/admin/agents/"+s.agent1ID+"/capabilities", testAdminToken, dto.SetAgentCapabilitiesRequest{Capabilities: []string{"go"}})
+	s.Require().Equal(http.StatusOK, w.Code)
+	agent := me()
+	s.Equal("renamed", agent.Name)
+	s.Equal([]string{"go"}, agent.Capabilities)
+
+	w = serve("POST", "/api/v1/admin/workspaces/"+s.workspaceID+"/archive", testAdminToken, dto.ArchiveWorkspaceRequest{})
+	s.Require().Equal(http.StatusOK, w.Code)
+	w = serve("GET", "/api/v1/agents/me", s.agent1Token, nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+}
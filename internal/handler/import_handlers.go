@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// maxImportBodyBytes bounds the body of POST /tasks/import.
+const maxImportBodyBytes = 10 << 20
+
+// handleImportTasks creates tasks from an export or a CSV file.
+// @Summary Import tasks
+// @Description Create tasks from a JSON export (or an array of tasks), an NDJSON export or a CSV file with a header row (title, description, priority, labels; optionally visibility, queue, required_capabilities; lists separated by ';'). All rows are validated first and nothing is created unless every row is valid; row errors are returned with 422. Imported tasks are NEW, unassigned and created by the caller; status, assignee and blockers of exported tasks are not carried over. At most 1000 tasks per request.
+// @Tags tasks
+// @Accept json
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv, json or ndjson; defaults to the Content-Type"
+// @Param dry_run query bool false "Validate without creating tasks"
+// @Success 200 {object} dto.ImportTasksResponse "Dry run"
+// @Success 201 {object} dto.ImportTasksResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ImportTasksResponse "Row errors; nothing was created"
+// @Security BearerAuth
+// @Router /tasks/import [post]
+func (h *Handler) handleImportTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = importFormatFromContentType(r.Header.Get("Content-Type"))
+	}
+	dryRun := query.Get("dry_run") == "true"
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "import body exceeds 10 MiB")
+			return
+		}
+		respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body")
+		return
+	}
+
+	rows, err := service.ParseImport(bytes.NewReader(body), format)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	result, err := h.taskService.ImportTasks(ctx, service.ImportTasksParams{
+		WorkspaceID: agent.WorkspaceID,
+		CreatorID:   agent.ID,
+		Rows:        rows,
+		DryRun:      dryRun,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ImportTasksResponse{
+		DryRun:   dryRun,
+		Rows:     len(rows),
+		Valid:    result.Valid,
+		Imported: len(result.Tasks),
+		TaskIDs:  make([]string, len(result.Tasks)),
+		Errors:   make([]dto.ImportRowErrorResponse, len(result.Errors)),
+	}
+	for i, task := range result.Tasks {
+		response.TaskIDs[i] = task.ID
+	}
+	for i, rowErr := range result.Errors {
+		response.Errors[i] = dto.ImportRowErrorResponse{Row: rowErr.Row, Error: rowErr.Message}
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		respondJSON(w, http.StatusUnprocessableEntity, response)
+	case dryRun:
+		respondJSON(w, http.StatusOK, response)
+	default:
+		respondJSON(w, http.StatusCreated, response)
+	}
+}
+
+// importFormatFromContentType picks the import format for a request without ?format.
+func importFormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return service.ImportFormatJSON
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return service.ImportFormatCSV
+	case "application/x-ndjson":
+		return service.ImportFormatNDJSON
+	default:
+		return service.ImportFormatJSON
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// headerIntakeKey carries the intake key of a submission.
+const headerIntakeKey = "X-Sloptask-Intake-Key"
+
+// maxIntakeBodyBytes bounds the body of an intake submission.
+const maxIntakeBodyBytes = 64 << 10
+
+// handleSubmitIntake turns a submission to a workspace's public intake form into a task.
+// @Summary Submit intake
+// @Description Unauthenticated intake for humans outside the system: creates a NEW public task labelled "intake" in the workspace, created by the form's agent. The intake key goes in the X-Sloptask-Intake-Key header or the key field. Accepts JSON or a form-encoded HTML form post. Limited per client IP and per workspace and hour; beyond either limit the response is 429.
+// @Tags intake
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param workspace path string true "Workspace slug"
+// @Param request body dto.IntakeSubmissionRequest true "Submission"
+// @Success 201 {object} dto.IntakeSubmissionResponse
+// @Failure 401 {object} dto.ErrorResponse "Invalid intake key"
+// @Failure 404 {object} dto.ErrorResponse "No intake form"
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /intake/{workspace} [post]
+func (h *Handler) handleSubmitIntake(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	r.Body = http.MaxBytesReader(w, r.Body, maxIntakeBodyBytes)
+
+	var req dto.IntakeSubmissionRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid form body")
+			return
+		}
+		req = dto.IntakeSubmissionRequest{
+			Title:       r.PostForm.Get("title"),
+			Description: r.PostForm.Get("description"),
+			Contact:     r.PostForm.Get("contact"),
+			Key:         r.PostForm.Get("key"),
+		}
+	} else if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	key := strings.TrimSpace(r.Header.Get(headerIntakeKey))
+	if key == "" {
+		key = strings.TrimSpace(req.Key)
+	}
+	if key == "" {
+		respondError(w, http.StatusUnauthorized, "INVALID_INTAKE_KEY", "intake key is required")
+		return
+	}
+
+	task, err := h.intakeService.SubmitIntake(ctx, r.PathValue("workspace"), key, domain.IntakeSubmission{
+		Title:       req.Title,
+		Description: req.Description,
+		Contact:     req.Contact,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.IntakeSubmissionResponse{
+		TaskID: task.ID,
+		Status: string(task.Status),
+	})
+}
+
+// handleGetIntakeForm returns a workspace's intake form.
+// @Summary Get intake form
+// @Description The workspace's public intake form. The key is never returned here.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.IntakeFormResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/intake [get]
+func (h *Handler) handleGetIntakeForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	form, err := h.intakeService.GetIntake(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToIntakeFormResponse(form, workspace.Slug, ""))
+}
+
+// handleSetIntakeForm enables or updates a workspace's intake form.
+// @Summary Set intake form
+// @Description Enable the workspace's public intake form at POST /intake/{slug}. Submissions become NEW public tasks labelled "intake", created by agent_id, at most rate_limit_per_hour per hour. The intake key is returned when the form is created or rotate_key is set, and cannot be retrieved again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetIntakeFormRequest true "Intake form"
+// @Success 200 {object} dto.IntakeFormResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Workspace archived"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/intake [put]
+func (h *Handler) handleSetIntakeForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetIntakeFormRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	form, key, err := h.intakeService.ConfigureIntake(ctx, service.ConfigureIntakeParams{
+		WorkspaceID:      workspaceID,
+		AgentID:          req.AgentID,
+		RateLimitPerHour: req.RateLimitPerHour,
+		RotateKey:        req.RotateKey,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditIntakeForm, &workspaceID, map[string]any{
+		"agent_id":            form.AgentID,
+		"rate_limit_per_hour": form.RateLimitPerHour,
+		"key_issued":          key != "",
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToIntakeFormResponse(form, workspace.Slug, key))
+}
+
+// handleDeleteIntakeForm disables a workspace's intake form.
+// @Summary Remove intake form
+// @Description Disable the workspace's public intake form; its key stops working
+// @Tags admin
+// @Param workspace_id path string true "Workspace ID"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/intake [delete]
+func (h *Handler) handleDeleteIntakeForm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if err := h.intakeService.DisableIntake(ctx, workspaceID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditIntakeForm, &workspaceID, map[string]any{"agent_id": nil})
+
+	w.WriteHeader(http.StatusNoContent)
+}
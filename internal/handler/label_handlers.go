@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleListLabels lists the label registry of the agent's workspace.
+// @Summary List labels
+// @Description Get all registered labels of the workspace with the number of tasks carrying each
+// @Tags labels
+// @Produce json
+// @Success 200 {object} dto.LabelsListResponse
+// @Security BearerAuth
+// @Router /labels [get]
+func (h *Handler) handleListLabels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	labels, err := h.labelService.ListLabels(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.LabelsListResponse{Labels: make([]dto.LabelResponse, len(labels))}
+	for i, label := range labels {
+		response.Labels[i] = dto.ToLabelResponse(label)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handlePutLabel registers a label or updates it.
+// @Summary Register label
+// @Description Idempotently register a label (lowercase letters, digits, '-', '_', '.', ':'). If it exists, the given color and description are updated and omitted fields are kept.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param name path string true "Label name"
+// @Param request body dto.PutLabelRequest false "Color and description"
+// @Success 200 {object} dto.LabelResponse "Label existed"
+// @Success 201 {object} dto.LabelResponse "Label created"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /labels/{name} [put]
+func (h *Handler) handlePutLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	// The body is optional: PUT /labels/{name} alone just ensures the label exists
+	var req dto.PutLabelRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	label, created, err := h.labelService.PutLabel(ctx, agent.WorkspaceID, r.PathValue("name"), req.Color, req.Description)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respondJSON(w, status, dto.ToLabelResponse(label))
+}
+
+// handleGetLabel returns a label with its usage count.
+// @Summary Get label
+// @Description Get a registered label with the number of tasks carrying it
+// @Tags labels
+// @Produce json
+// @Param name path string true "Label name"
+// @Success 200 {object} dto.LabelResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /labels/{name} [get]
+func (h *Handler) handleGetLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	label, err := h.labelService.GetLabel(ctx, agent.WorkspaceID, r.PathValue("name"))
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToLabelResponse(label))
+}
+
+// handleUpdateLabel renames a label or changes its color or description.
+// @Summary Update label
+// @Description Rename a label and/or change its color and description. A rename rewrites the labels of all tasks carrying it in the same transaction.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param name path string true "Label name"
+// @Param request body dto.UpdateLabelRequest true "Changes"
+// @Success 200 {object} dto.LabelResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /labels/{name} [patch]
+func (h *Handler) handleUpdateLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.UpdateLabelRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(ctx, service.UpdateLabelParams{
+		WorkspaceID: agent.WorkspaceID,
+		Name:        r.PathValue("name"),
+		NewName:     req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToLabelResponse(label))
+}
+
+// handleMergeLabel merges a label into another one.
+// @Summary Merge label
+// @Description Replace the label with another registered label on every task and remove it from the registry, in one transaction. Use it to fold near-duplicates together.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param name path string true "Label to merge away"
+// @Param request body dto.MergeLabelRequest true "Target label"
+// @Success 200 {object} dto.LabelResponse "Target label"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /labels/{name}/merge [post]
+func (h *Handler) handleMergeLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.MergeLabelRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	label, err := h.labelService.MergeLabel(ctx, agent.WorkspaceID, r.PathValue("name"), req.Into)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToLabelResponse(label))
+}
+
+// handleDeleteLabel removes a label from the registry and from all tasks.
+// @Summary Delete label
+// @Description Remove a label from the registry and from every task carrying it
+// @Tags labels
+// @Param name path string true "Label name"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /labels/{name} [delete]
+func (h *Handler) handleDeleteLabel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(ctx, agent.WorkspaceID, r.PathValue("name")); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetTaskLabels replaces the labels of a task.
+// @Summary Set task labels
+// @Description Creator or assignee replaces the task's labels. Labels must be registered in the workspace. Repeating the same request changes nothing and returns a null event.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.SetTaskLabelsRequest true "Labels"
+// @Success 200 {object} dto.TaskLabelsResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/labels [put]
+func (h *Handler) handleSetTaskLabels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SetTaskLabelsRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	labels, event, err := h.taskService.SetTaskLabels(ctx, service.SetTaskLabelsParams{
+		TaskID:  taskID,
+		AgentID: agent.ID,
+		Labels:  req.Labels,
+		Comment: req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.TaskLabelsResponse{Labels: labels}
+	if event != nil {
+		eventResponse := dto.ToTaskEventResponse(event)
+		response.Event = &eventResponse
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleSendMessage sends a direct message to another agent about a task.
+// @Summary Send direct message
+// @Description Send a message about the task to one other agent of the workspace. Messages are not task events: only the sender and the recipient see them. Both must be able to see the task. The recipient finds it in GET /messages and its unread count in GET /agents/me and heartbeat responses.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.SendMessageRequest true "Recipient and body (max 10000 characters)"
+// @Success 201 {object} dto.MessageInfo
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/messages [post]
+func (h *Handler) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if _, err := uuid.Parse(req.RecipientID); err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "recipient_id must be a valid UUID")
+		return
+	}
+
+	message, err := h.messageService.SendMessage(ctx, service.SendMessageParams{
+		TaskID:      taskID,
+		SenderID:    agent.ID,
+		RecipientID: req.RecipientID,
+		Body:        req.Body,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToMessageInfo(message))
+}
+
+// handleListMessages lists the direct messages the calling agent sent or received.
+// @Summary List my messages
+// @Description Direct messages sent or received by the calling agent, newest first. Messages about deleted tasks are left out.
+// @Tags messages
+// @Produce json
+// @Param task_id query string false "Only messages about this task (ID or key)"
+// @Param unread query bool false "Only messages to you that you have not acknowledged"
+// @Param limit query int false "Page size (1-200, default 50)"
+// @Param offset query int false "Page offset (default 0)"
+// @Success 200 {object} dto.MessagesResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /messages [get]
+func (h *Handler) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	filters := repository.MessageFilters{AgentID: agent.ID, Limit: 50}
+
+	if taskRef := query.Get("task_id"); taskRef != "" {
+		taskIDs, err := h.resolveTaskRefs(ctx, []string{taskRef})
+		if err != nil {
+			if errors.Is(err, domain.ErrValidation) {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "task_id must be a valid UUID or task key")
+				return
+			}
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to resolve task key")
+			return
+		}
+		filters.TaskID = &taskIDs[0]
+	}
+
+	if unreadParam := query.Get("unread"); unreadParam != "" {
+		unread, err := strconv.ParseBool(unreadParam)
+		if err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "unread must be true or false")
+			return
+		}
+		filters.UnreadOnly = unread
+	}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			filters.Limit = n
+		}
+	}
+
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			filters.Offset = n
+		}
+	}
+
+	messages, total, err := h.messageService.ListMessages(ctx, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch messages")
+		return
+	}
+
+	response := dto.MessagesResponse{
+		Messages: make([]dto.MessageInfo, len(messages)),
+		Total:    total,
+		Limit:    filters.Limit,
+		Offset:   filters.Offset,
+	}
+	for i, message := range messages {
+		response.Messages[i] = dto.ToMessageInfo(message)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleAcknowledgeMessage marks a message to the calling agent as read.
+// @Summary Acknowledge message
+// @Description Mark a direct message addressed to you as read. Repeating it keeps the first read_at.
+// @Tags messages
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageInfo
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /messages/{id}/read [post]
+func (h *Handler) handleAcknowledgeMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	messageID, ok := extractPathUUID(w, r, "id", "message id")
+	if !ok {
+		return
+	}
+
+	message, err := h.messageService.AcknowledgeMessage(ctx, messageID, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToMessageInfo(message))
+}
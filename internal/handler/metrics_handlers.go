@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// openMetricsContentType is the media type of the OpenMetrics 1.0 text format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsLabelEscaper escapes label values as the text format requires.
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// openMetricsWriter renders metric families in the OpenMetrics text format.
+type openMetricsWriter struct {
+	buf         bytes.Buffer
+	workspaceID string
+}
+
+// family starts a metric family with its type and help text.
+func (m *openMetricsWriter) family(name, metricType, unit, help string) {
+	fmt.Fprintf(&m.buf, "# TYPE %s %s\n", name, metricType)
+	if unit != "" {
+		fmt.Fprintf(&m.buf, "# UNIT %s %s\n", name, unit)
+	}
+	fmt.Fprintf(&m.buf, "# HELP %s %s\n", name, help)
+}
+
+// sample writes one sample of the current family. Every sample carries the
+// workspace_id label, followed by the given label name/value pairs.
+func (m *openMetricsWriter) sample(name string, value float64, labels ...string) {
+	fmt.Fprintf(&m.buf, `%s{workspace_id="%s"`, name, m.workspaceID)
+	for i := 0; i+1 < len(labels); i += 2 {
+		fmt.Fprintf(&m.buf, `,%s="%s"`, labels[i], openMetricsLabelEscaper.Replace(labels[i+1]))
+	}
+	fmt.Fprintf(&m.buf, "} %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// gauge writes a family with a single sample.
+func (m *openMetricsWriter) gauge(name, unit, help string, value float64) {
+	m.family(name, "gauge", unit, help)
+	m.sample(name, value)
+}
+
+// handleGetMetrics exposes the workspace's KPIs for Prometheus-compatible scrapers.
+// @Summary Get workspace metrics
+// @Description Workspace business metrics in the OpenMetrics text format, for scraping by Prometheus: tasks by status, overdue, stuck and claimable tasks, the completion ratio, stale agents and each agent's IN_PROGRESS tasks. All values describe the current state. Every sample carries a workspace_id label.
+// @Tags stats
+// @Produce plain
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Success 200 {string} string "OpenMetrics text"
+// @Security BearerAuth
+// @Router /stats/metrics [get]
+func (h *Handler) handleGetMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	now := time.Now()
+	filters := repository.StatsFilters{WorkspaceID: workspaceID, PeriodEnd: now}
+
+	workspaceStats, err := h.taskRepo.GetWorkspaceStats(ctx, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch workspace stats")
+		return
+	}
+
+	agentStats, err := h.taskRepo.GetAgentStats(ctx, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch agent stats")
+		return
+	}
+
+	depth, err := h.taskRepo.GetQueueDepth(ctx, workspaceID, nil)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch queue depth")
+		return
+	}
+
+	m := &openMetricsWriter{workspaceID: workspaceID}
+
+	// Report every status so absent series read as zero rather than as gaps
+	totalTasks := 0
+	m.family("sloptask_tasks", "gauge", "", "Tasks by current status.")
+	for _, status := range []domain.TaskStatus{
+		domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusNeedsReview, domain.TaskStatusBlocked,
+		domain.TaskStatusAwaitingExternal, domain.TaskStatusWaitingApproval, domain.TaskStatusStuck, domain.TaskStatusDone,
+		domain.TaskStatusCancelled,
+	} {
+		count := workspaceStats.TasksByStatus[string(status)]
+		totalTasks += count
+		m.sample("sloptask_tasks", float64(count), "status", string(status))
+	}
+
+	m.gauge("sloptask_tasks_overdue", "", "Open tasks past their status deadline.", float64(workspaceStats.OverdueCount))
+	m.gauge("sloptask_tasks_stuck", "", "Tasks in STUCK status.", float64(workspaceStats.StuckCount))
+	m.gauge("sloptask_tasks_claimable", "", "Tasks an agent could claim right now.", float64(depth.Total))
+
+	oldestPending := 0.0
+	if depth.OldestAt != nil {
+		oldestPending = now.Sub(*depth.OldestAt).Seconds()
+	}
+	m.gauge("sloptask_tasks_claimable_oldest_age_seconds", "seconds",
+		"Age of the oldest claimable task, 0 when there is none.", oldestPending)
+
+	completionRatio := 0.0
+	if totalTasks > 0 {
+		completionRatio = float64(workspaceStats.TasksByStatus[string(domain.TaskStatusDone)]) / float64(totalTasks)
+	}
+	m.gauge("sloptask_completion_ratio", "ratio", "Share of the workspace's tasks that are DONE.", completionRatio)
+
+	m.gauge("sloptask_agents_stale", "", "Active agents whose last heartbeat is older than the workspace's staleness window.",
+		float64(workspaceStats.StaleAgentCount))
+
+	m.family("sloptask_agent_tasks_in_progress", "gauge", "", "IN_PROGRESS tasks by assignee.")
+	for _, stat := range agentStats {
+		m.sample("sloptask_agent_tasks_in_progress", float64(stat.TasksInProgress),
+			"agent_id", stat.AgentID, "agent_name", stat.AgentName)
+	}
+
+	m.buf.WriteString("# EOF\n")
+
+	w.Header().Set("Content-Type", openMetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(m.buf.Bytes())
+}
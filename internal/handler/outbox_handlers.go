@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// handleGetEventWebhook returns a workspace's event webhook.
+// @Summary Get event webhook
+// @Description The webhook receiving the workspace's task events, if any, and the event types it receives
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.EventWebhookResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-webhook [get]
+func (h *Handler) handleGetEventWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToEventWebhookResponse(workspaceID, workspace.EventWebhook))
+}
+
+// handleSetEventWebhook sets a workspace's event webhook.
+// @Summary Set event webhook
+// @Description Publish the workspace's task events to url, all of them or only event_types. Every event is queued in an outbox in the same transaction that records it, and the scheduler POSTs it as a signed JSON document (delivery_id, workspace_id, event; see pkg/client WebhookPayload), one task's events in order. Any non-2xx response is retried after 1, 4, 9... minutes, 10 attempts in all. Retries reuse the delivery ID in X-Sloptask-Delivery, so receivers can drop events they already processed. Events queued before a change keep going to the previous url.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetEventWebhookRequest true "Webhook"
+// @Success 200 {object} dto.EventWebhookResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-webhook [put]
+func (h *Handler) handleSetEventWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetEventWebhookRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	hook := &domain.EventWebhook{URL: req.URL, EventTypes: make([]domain.EventType, len(req.EventTypes))}
+	for i, eventType := range req.EventTypes {
+		hook.EventTypes[i] = domain.EventType(eventType)
+	}
+
+	hook, err := h.taskService.SetEventWebhook(ctx, workspaceID, hook)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ToEventWebhookResponse(workspaceID, hook)
+	h.recordAudit(ctx, domain.AuditEventWebhook, &workspaceID, map[string]any{
+		"url":         hook.URL,
+		"event_types": response.EventTypes,
+	})
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleDeleteEventWebhook removes a workspace's event webhook.
+// @Summary Remove event webhook
+// @Description Stop publishing the workspace's task events. Events already queued are still delivered.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.EventWebhookResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-webhook [delete]
+func (h *Handler) handleDeleteEventWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskService.SetEventWebhook(ctx, workspaceID, nil); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditEventWebhook, &workspaceID, map[string]any{"url": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToEventWebhookResponse(workspaceID, nil))
+}
+
+// handleGetEventBroker returns a workspace's event broker topic.
+// @Summary Get event broker topic
+// @Description The NATS subject or Kafka topic receiving the workspace's task events, if any, and the event types it receives
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.EventBrokerResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-broker [get]
+func (h *Handler) handleGetEventBroker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToEventBrokerResponse(workspaceID, workspace.EventBroker))
+}
+
+// handleSetEventBroker sets a workspace's event broker topic.
+// @Summary Set event broker topic
+// @Description Publish the workspace's task events, all of them or only event_types, to topic on the broker the scheduler runs with (--broker-url, NATS or Kafka). Events go through the same outbox as the event webhook, with the same payload, retries and per-task order, independently of the webhook. Messages carry X-Sloptask-Event and X-Sloptask-Delivery headers; NATS messages also Nats-Msg-Id, and Kafka messages are keyed by task ID. While the scheduler has no broker, events stay pending in the outbox. The topic is made of dot-separated parts of letters, digits, _ and -, at most 249 characters.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.SetEventBrokerRequest true "Broker topic"
+// @Success 200 {object} dto.EventBrokerResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-broker [put]
+func (h *Handler) handleSetEventBroker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	var req dto.SetEventBrokerRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	topic := &domain.EventBrokerTopic{Topic: req.Topic, EventTypes: make([]domain.EventType, len(req.EventTypes))}
+	for i, eventType := range req.EventTypes {
+		topic.EventTypes[i] = domain.EventType(eventType)
+	}
+
+	topic, err := h.taskService.SetEventBroker(ctx, workspaceID, topic)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ToEventBrokerResponse(workspaceID, topic)
+	h.recordAudit(ctx, domain.AuditEventBroker, &workspaceID, map[string]any{
+		"topic":       topic.Topic,
+		"event_types": response.EventTypes,
+	})
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleDeleteEventBroker removes a workspace's event broker topic.
+// @Summary Remove event broker topic
+// @Description Stop publishing the workspace's task events to the broker. Events already queued are still delivered.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.EventBrokerResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/event-broker [delete]
+func (h *Handler) handleDeleteEventBroker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	if _, err := h.taskService.SetEventBroker(ctx, workspaceID, nil); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditEventBroker, &workspaceID, map[string]any{"topic": nil})
+
+	respondJSON(w, http.StatusOK, dto.ToEventBrokerResponse(workspaceID, nil))
+}
+
+// handleListOutbox lists a workspace's event deliveries.
+// @Summary List outbox
+// @Description The workspace's task events queued for its event webhook and broker topic, newest first: pending ones with their next attempt, delivered ones, and failed ones with the last error after giving up
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param limit query int false "Results per page (default 50, max 200)"
+// @Param offset query int false "Number of results to skip"
+// @Success 200 {object} dto.OutboxResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/outbox [get]
+func (h *Handler) handleListOutbox(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	var status *domain.OutboxStatus
+	if statusParam := query.Get("status"); statusParam != "" {
+		s := domain.OutboxStatus(statusParam)
+		if !s.IsValid() {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "status must be pending, delivered or failed")
+			return
+		}
+		status = &s
+	}
+
+	limit := 50
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	if _, err := h.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	messages, total, err := h.outboxService.ListMessages(ctx, workspaceID, status, limit, offset)
+	if err != nil {
+		slog.Error("failed to list outbox", "workspace_id", workspaceID, "error", err)
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list outbox")
+		return
+	}
+
+	response := dto.OutboxResponse{
+		Messages: make([]dto.OutboxMessageInfo, len(messages)),
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	for i, message := range messages {
+		response.Messages[i] = dto.ToOutboxMessageInfo(message)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleRetryOutboxMessage requeues a failed event delivery.
+// @Summary Retry event delivery
+// @Description Queue a failed delivery again, due at once and with a fresh set of attempts, e.g. after fixing the receiver
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param id path string true "Outbox message ID"
+// @Success 200 {object} dto.OutboxMessageInfo
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/outbox/{id}/retry [post]
+func (h *Handler) handleRetryOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace id")
+	if !ok {
+		return
+	}
+	messageID, ok := extractPathUUID(w, r, "id", "outbox message id")
+	if !ok {
+		return
+	}
+
+	message, err := h.outboxService.RetryMessage(ctx, workspaceID, messageID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditOutboxRetried, &workspaceID, map[string]any{
+		"message_id": message.ID,
+		"event_id":   message.EventID,
+	})
+
+	respondJSON(w, http.StatusOK, dto.ToOutboxMessageInfo(message))
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleListQueues lists the queues of the agent's workspace.
+// @Summary List queues
+// @Description Get all named task queues of the workspace
+// @Tags queues
+// @Produce json
+// @Success 200 {object} dto.QueuesListResponse
+// @Security BearerAuth
+// @Router /queues [get]
+func (h *Handler) handleListQueues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	queues, err := h.queueService.ListQueues(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.QueuesListResponse{Queues: make([]dto.QueueResponse, len(queues))}
+	for i, queue := range queues {
+		response.Queues[i] = dto.ToQueueResponse(queue)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleCreateQueue creates a queue in the agent's workspace.
+// @Summary Create queue
+// @Description Create a named task queue. Names are lowercase letters, digits, '-' and '_'.
+// @Tags queues
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateQueueRequest true "Queue"
+// @Success 201 {object} dto.QueueResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /queues [post]
+func (h *Handler) handleCreateQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.CreateQueueRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	queue, err := h.queueService.CreateQueue(ctx, agent.WorkspaceID, req.Name, req.Description)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToQueueResponse(queue))
+}
+
+// handleUpdateQueue renames a queue or changes its description.
+// @Summary Update queue
+// @Description Rename a queue and/or change its description. Tasks in the queue follow a rename.
+// @Tags queues
+// @Accept json
+// @Produce json
+// @Param name path string true "Queue name"
+// @Param request body dto.UpdateQueueRequest true "Changes"
+// @Success 200 {object} dto.QueueResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /queues/{name} [patch]
+func (h *Handler) handleUpdateQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.UpdateQueueRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	queue, err := h.queueService.UpdateQueue(ctx, service.UpdateQueueParams{
+		WorkspaceID: agent.WorkspaceID,
+		Name:        r.PathValue("name"),
+		NewName:     req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToQueueResponse(queue))
+}
+
+// handleDeleteQueue deletes an empty queue.
+// @Summary Delete queue
+// @Description Delete a queue. Queues that still have tasks cannot be deleted.
+// @Tags queues
+// @Param name path string true "Queue name"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /queues/{name} [delete]
+func (h *Handler) handleDeleteQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	if err := h.queueService.DeleteQueue(ctx, agent.WorkspaceID, r.PathValue("name")); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleListReports lists the scheduled reports of the agent's workspace.
+// @Summary List reports
+// @Description Get all scheduled reports of the workspace
+// @Tags reports
+// @Produce json
+// @Success 200 {object} dto.ReportsListResponse
+// @Security BearerAuth
+// @Router /reports [get]
+func (h *Handler) handleListReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	reports, err := h.reportService.ListReports(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ReportsListResponse{Reports: make([]dto.ReportResponse, len(reports))}
+	for i, report := range reports {
+		response.Reports[i] = dto.ToReportResponse(report)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleCreateReport creates a scheduled report owned by the agent.
+// @Summary Create report
+// @Description Create a scheduled report. Each time the cron expression fires (evaluated in the report's timezone) the scheduler renders the report for the period ending then and delivers it to the webhook URL or email addresses.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateReportRequest true "Report"
+// @Success 201 {object} dto.ReportResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /reports [post]
+func (h *Handler) handleCreateReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.CreateReportRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.reportService.CreateReport(ctx, service.CreateReportParams{
+		WorkspaceID: agent.WorkspaceID,
+		CreatorID:   agent.ID,
+		Name:        req.Name,
+		Kind:        domain.ReportKind(req.Kind),
+		Format:      domain.ReportFormat(req.Format),
+		Period:      domain.ReportPeriod(req.Period),
+		CronExpr:    req.Cron,
+		Timezone:    req.Timezone,
+		TargetType:  domain.ReportTargetType(req.TargetType),
+		Target:      req.Target,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToReportResponse(report))
+}
+
+// handleGetReport returns a report with the outcome of its last delivery.
+// @Summary Get report
+// @Description Get a scheduled report, its next run and the outcome of its last delivery
+// @Tags reports
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success 200 {object} dto.ReportResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /reports/{id} [get]
+func (h *Handler) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	reportID, ok := extractPathUUID(w, r, "id", "report_id")
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(ctx, agent.WorkspaceID, reportID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToReportResponse(report))
+}
+
+// handleUpdateReport changes a report.
+// @Summary Update report
+// @Description Creator changes the content, timing or target of a report, or pauses/resumes it. Changing the timing or resuming recomputes the next run from now.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param request body dto.UpdateReportRequest true "Changes"
+// @Success 200 {object} dto.ReportResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /reports/{id} [patch]
+func (h *Handler) handleUpdateReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	reportID, ok := extractPathUUID(w, r, "id", "report_id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateReportRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := service.UpdateReportParams{
+		WorkspaceID: agent.WorkspaceID,
+		ReportID:    reportID,
+		AgentID:     agent.ID,
+		Name:        req.Name,
+		CronExpr:    req.Cron,
+		Timezone:    req.Timezone,
+		Target:      req.Target,
+		Enabled:     req.Enabled,
+	}
+	if req.Kind != nil {
+		kind := domain.ReportKind(*req.Kind)
+		params.Kind = &kind
+	}
+	if req.Format != nil {
+		format := domain.ReportFormat(*req.Format)
+		params.Format = &format
+	}
+	if req.Period != nil {
+		period := domain.ReportPeriod(*req.Period)
+		params.Period = &period
+	}
+	if req.TargetType != nil {
+		targetType := domain.ReportTargetType(*req.TargetType)
+		params.TargetType = &targetType
+	}
+
+	report, err := h.reportService.UpdateReport(ctx, params)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToReportResponse(report))
+}
+
+// handleDeleteReport deletes a report.
+// @Summary Delete report
+// @Description Creator deletes a scheduled report
+// @Tags reports
+// @Param id path string true "Report ID"
+// @Success 204
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /reports/{id} [delete]
+func (h *Handler) handleDeleteReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	reportID, ok := extractPathUUID(w, r, "id", "report_id")
+	if !ok {
+		return
+	}
+
+	if err := h.reportService.DeleteReport(ctx, agent.WorkspaceID, reportID, agent.ID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePreviewReport renders a report without delivering it.
+// @Summary Preview report
+// @Description Render the report for the period ending now, exactly as it would be delivered (application/json or text/markdown)
+// @Tags reports
+// @Produce json
+// @Produce plain
+// @Param id path string true "Report ID"
+// @Success 200 {string} string "Rendered report"
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /reports/{id}/preview [get]
+func (h *Handler) handlePreviewReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	reportID, ok := extractPathUUID(w, r, "id", "report_id")
+	if !ok {
+		return
+	}
+
+	rendered, err := h.reportService.PreviewReport(ctx, agent.WorkspaceID, reportID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", rendered.ContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(rendered.Body); err != nil {
+		slog.Error("failed to write report preview response", "error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleEditTask changes the title and/or description of a task.
+// @Summary Edit a task
+// @Description Task creator changes the title and/or description of a task that is not DONE or CANCELLED. Every change is kept as a revision. Repeating the same request changes nothing and returns null revision and event.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.EditTaskRequest true "Changed fields"
+// @Param If-Match header string false "Version the edit expects the task to be at, e.g. \"3\"; required unless expected_version is sent"
+// @Success 200 {object} dto.EditTaskResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 428 {object} dto.ErrorResponse "Neither If-Match nor expected_version sent"
+// @Security BearerAuth
+// @Router /tasks/{id} [patch]
+func (h *Handler) handleEditTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.EditTaskRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Title == nil && req.Description == nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "title or description is required")
+		return
+	}
+	expectedVersion, ok := expectedTaskVersion(w, r, req.ExpectedVersion)
+	if !ok {
+		return
+	}
+
+	revision, event, err := h.taskService.EditTask(ctx, service.EditTaskParams{
+		TaskID:      taskID,
+		AgentID:     agent.ID,
+		Title:       req.Title,
+		Description: req.Description,
+		Comment:     req.Comment,
+
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	var response dto.EditTaskResponse
+	if revision != nil {
+		response.Revision = &revision.Revision
+	}
+	if event != nil {
+		eventResponse := dto.ToTaskEventResponse(event)
+		response.Event = &eventResponse
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleListTaskRevisions returns the version history of a task's title and description.
+// @Summary List task revisions
+// @Description Get every version of the task's title and description, oldest first, each with a unified line diff against the previous one
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Success 200 {object} dto.TaskRevisionsResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/revisions [get]
+func (h *Handler) handleListTaskRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := h.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if !task.IsVisibleTo(agent) {
+		respondError(w, http.StatusForbidden, "INSUFFICIENT_ACCESS", "Task not found")
+		return
+	}
+
+	revisions, err := h.taskService.ListTaskRevisions(ctx, task)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch revisions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskRevisionsResponse(task.ID, revisions))
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleListSchedules lists the schedules of the agent's workspace.
+// @Summary List schedules
+// @Description Get all recurring task schedules of the workspace
+// @Tags schedules
+// @Produce json
+// @Success 200 {object} dto.SchedulesListResponse
+// @Security BearerAuth
+// @Router /schedules [get]
+func (h *Handler) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(ctx, agent.WorkspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.SchedulesListResponse{Schedules: make([]dto.ScheduleResponse, len(schedules))}
+	for i, schedule := range schedules {
+		response.Schedules[i] = dto.ToScheduleResponse(schedule)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleCreateSchedule creates a schedule owned by the agent.
+// @Summary Create schedule
+// @Description Create a recurring schedule. Each time the cron expression fires (evaluated in the schedule's timezone) the scheduler creates a task from the template, with the agent as creator.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateScheduleRequest true "Schedule"
+// @Success 201 {object} dto.ScheduleResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /schedules [post]
+func (h *Handler) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.CreateScheduleRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(ctx, service.CreateScheduleParams{
+		WorkspaceID: agent.WorkspaceID,
+		CreatorID:   agent.ID,
+		Name:        req.Name,
+		CronExpr:    req.Cron,
+		Timezone:    req.Timezone,
+		Template:    req.Task.ToDomain(),
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToScheduleResponse(schedule))
+}
+
+// handleGetSchedule returns a schedule with the outcome of its last run.
+// @Summary Get schedule
+// @Description Get a schedule, its next run and the outcome of its last run
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /schedules/{id} [get]
+func (h *Handler) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	scheduleID, ok := extractPathUUID(w, r, "id", "schedule_id")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(ctx, agent.WorkspaceID, scheduleID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToScheduleResponse(schedule))
+}
+
+// handleUpdateSchedule changes a schedule.
+// @Summary Update schedule
+// @Description Creator changes the name, cron expression, timezone, template or pauses/resumes the schedule. Changing the timing or resuming recomputes the next run from now.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body dto.UpdateScheduleRequest true "Changes"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /schedules/{id} [patch]
+func (h *Handler) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	scheduleID, ok := extractPathUUID(w, r, "id", "schedule_id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateScheduleRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	var template *domain.TaskTemplate
+	if req.Task != nil {
+		t := req.Task.ToDomain()
+		template = &t
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(ctx, service.UpdateScheduleParams{
+		WorkspaceID: agent.WorkspaceID,
+		ScheduleID:  scheduleID,
+		AgentID:     agent.ID,
+		Name:        req.Name,
+		CronExpr:    req.Cron,
+		Timezone:    req.Timezone,
+		Enabled:     req.Enabled,
+		Template:    template,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToScheduleResponse(schedule))
+}
+
+// handleDeleteSchedule deletes a schedule.
+// @Summary Delete schedule
+// @Description Creator deletes a schedule. Tasks it already created are kept.
+// @Tags schedules
+// @Param id path string true "Schedule ID"
+// @Success 204
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /schedules/{id} [delete]
+func (h *Handler) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	scheduleID, ok := extractPathUUID(w, r, "id", "schedule_id")
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(ctx, agent.WorkspaceID, scheduleID, agent.ID); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/static"
+)
+
+// Roles accepted by GET /skill.md?role=.
+const (
+	skillRoleWorker       = "worker"
+	skillRoleOrchestrator = "orchestrator"
+	skillRoleOperator     = "operator"
+)
+
+// skillRoleSections lists the skill.md sections each role's guide keeps, in
+// document order. Subsections are kept only when listed themselves.
+var skillRoleSections = map[string][]string{
+	skillRoleWorker: {
+		"Authentication", "Quick Start", "Critical Rules", "Task Statuses", "State Transitions",
+		"API Endpoints", "List Tasks", "Get Task", "Task Events", "Task Timings", "Change Status", "Claim Task",
+		"Claim Next Task", "Wait for Work", "Escalate Task", "Escalations Inbox", "Takeover Task", "Await External System", "Add Comment",
+		"Direct Messages", "Checklist", "Current Agent", "Agent Context", "Heartbeats", "Coordination Patterns", "Common Errors", "Agent Workflow (TL;DR)",
+	},
+	skillRoleOrchestrator: {
+		"Authentication", "Critical Rules", "Task Statuses", "State Transitions",
+		"API Endpoints", "List Tasks", "Get Task", "Task Events", "Task Lineage", "Task Timings", "GraphQL", "Create Task",
+		"Import Tasks", "Edit Task", "Change Status", "Escalations Inbox", "Direct Messages", "Reopen Task", "Archive Task", "Delete Task",
+		"Checklist", "Queues", "Labels", "Schedules", "Reports", "Current Agent", "Agent Context", "Heartbeats", "Statistics",
+		"Common Errors", "Quick Reference",
+	},
+	skillRoleOperator: {"Task Statuses", "State Transitions"},
+}
+
+// skillRoleIntros introduces each role's guide.
+var skillRoleIntros = map[string]string{
+	skillRoleWorker:       "**Role: worker.** You claim tasks, do the work and hand it back. This guide covers what you need for that; request `/skill.md` for everything.",
+	skillRoleOrchestrator: "**Role: orchestrator.** You create and organize tasks for other agents, review their results and track progress. This guide covers what you need for that; request `/skill.md` for everything.",
+	skillRoleOperator:     "**Role: operator.** You run the workspace: tokens, capabilities, exports, archiving and monitoring. Agents do the task work; the state machine below is what they can do.",
+}
+
+// skillRoleActors are the task relations shown in each role's transitions table.
+var skillRoleActors = map[string][]service.TransitionActor{
+	skillRoleWorker:       {service.ActorAssignee, service.ActorOtherAgent},
+	skillRoleOrchestrator: {service.ActorCreator},
+	skillRoleOperator:     {service.ActorAssignee, service.ActorCreator, service.ActorOtherAgent, service.ActorOperator},
+}
+
+// handleSkillMd serves the agent guide: the full embedded skill.md, or with
+// ?role= a guide for one role generated from its sections and the state machine.
+// Requests with an agent token also get a section on the calling agent.
+func (h *Handler) handleSkillMd(w http.ResponseWriter, r *http.Request) {
+	doc := static.SkillMd
+
+	if role := r.URL.Query().Get("role"); role != "" {
+		if _, ok := skillRoleSections[role]; !ok {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "role must be 'worker', 'orchestrator' or 'operator'")
+			return
+		}
+
+		var agentSection string
+		if agent, err := middleware.GetAgentFromContext(r.Context()); err == nil {
+			workspace, err := h.workspaceRepo.GetByID(r.Context(), agent.WorkspaceID)
+			if err != nil {
+				slog.Error("failed to get workspace for skill.md", "workspace_id", agent.WorkspaceID, "error", err)
+				respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+				return
+			}
+			agentSection = skillAgentSection(role, agent, workspace)
+		}
+
+		doc = skillRoleDoc(role, agentSection)
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(doc)); err != nil {
+		slog.Error("failed to write skill.md response", "error", err)
+	}
+}
+
+// skillRoleDoc composes the guide for a role.
+func skillRoleDoc(role, agentSection string) string {
+	preamble, sections := static.SplitSections(static.SkillMd)
+
+	keep := make(map[string]bool)
+	for _, title := range skillRoleSections[role] {
+		keep[title] = true
+	}
+
+	var b strings.Builder
+	b.WriteString(preamble)
+	b.WriteString(skillRoleIntros[role] + "\n\n")
+	b.WriteString(agentSection)
+
+	for _, section := range sections {
+		if !keep[section.Title] {
+			continue
+		}
+		if section.Title == "State Transitions" {
+			b.WriteString(skillTransitionsSection(skillRoleActors[role]))
+			continue
+		}
+		b.WriteString(section.Body)
+	}
+
+	if role == skillRoleOperator {
+		b.WriteString(static.OperatorMd)
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// skillTransitionsSection renders the transitions the given task relations may
+// make, generated from the state machine so it matches what the API enforces.
+func skillTransitionsSection(actors []service.TransitionActor) string {
+	var b strings.Builder
+	b.WriteString("## State Transitions\n\n")
+	b.WriteString("Generated from the state machine. **Who** is the agent's relation to the task: its assignee, its creator, or any other agent of the workspace; operator transitions need the admin token. A missed status deadline moves a task to STUCK automatically, unless the workspace configured another status.\n\n")
+	b.WriteString("| From | To | Who | How |\n|------|----|-----|-----|\n")
+
+	for _, rule := range service.StatusTransitions() {
+		var who []string
+		for _, actor := range actors {
+			if rule.HasActor(actor) {
+				who = append(who, string(actor))
+			}
+		}
+		if len(who) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", rule.From, rule.To, strings.Join(who, ", "), rule.Operation)
+	}
+
+	b.WriteString("\n")
+	return b.String()
+}
+
+// skillAgentSection describes the calling agent and what its capabilities allow.
+func skillAgentSection(role string, agent *domain.Agent, workspace *domain.Workspace) string {
+	var b strings.Builder
+	b.WriteString("## Your Agent\n\n")
+	fmt.Fprintf(&b, "- **Name:** %s\n", agent.Name)
+	fmt.Fprintf(&b, "- **Workspace:** %s (`%s`)\n", workspace.Name, workspace.Slug)
+
+	if len(agent.Capabilities) == 0 {
+		b.WriteString("- **Capabilities:** none\n\n")
+	} else {
+		fmt.Fprintf(&b, "- **Capabilities:** %s\n\n", strings.Join(agent.Capabilities, ", "))
+	}
+
+	switch role {
+	case skillRoleWorker:
+		if len(agent.Capabilities) == 0 {
+			b.WriteString("You can only claim tasks without `required_capabilities`.\n\n")
+		} else {
+			b.WriteString("You can claim tasks whose `required_capabilities` are all among yours; `claim-next` skips the others for you.\n\n")
+		}
+	case skillRoleOrchestrator:
+		b.WriteString("Tasks you create with `required_capabilities` can only be claimed by, or assigned to, agents that have all of them. Ask an operator which agents offer which capabilities.\n\n")
+	}
+
+	return b.String()
+}
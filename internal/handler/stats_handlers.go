@@ -11,9 +11,10 @@ import (
 
 // handleGetStats returns workspace and agent statistics.
 // @Summary Get statistics
-// @Description Get workspace and agent statistics for a given period
+// @Description Get workspace and agent statistics for a given period. Average lead time (created to DONE) and cycle time (first IN_PROGRESS to DONE) cover tasks completed in the period; per agent, those assigned to the agent. Takeover and escalation counters count events in the period by the agent and on tasks held by the agent.
 // @Tags stats
 // @Produce json
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
 // @Param period query string false "Period: day, week (default), month, all"
 // @Param agent_id query string false "Filter by specific agent UUID"
 // @Success 200 {object} dto.StatsResponse
@@ -22,7 +23,8 @@ import (
 func (h *Handler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	agent, err := middleware.GetAgentFromContext(ctx)
+	// Agents and workspace read tokens may both read stats
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
 		return
@@ -60,7 +62,7 @@ func (h *Handler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 
 	// Get agent stats
 	agentStats, err := h.taskRepo.GetAgentStats(ctx, repository.StatsFilters{
-		WorkspaceID: agent.WorkspaceID,
+		WorkspaceID: workspaceID,
 		PeriodStart: periodStart,
 		PeriodEnd:   now,
 		AgentID:     agentIDFilter,
@@ -72,7 +74,7 @@ func (h *Handler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 
 	// Get workspace stats
 	workspaceStats, err := h.taskRepo.GetWorkspaceStats(ctx, repository.StatsFilters{
-		WorkspaceID: agent.WorkspaceID,
+		WorkspaceID: workspaceID,
 		PeriodStart: periodStart,
 		PeriodEnd:   now,
 	})
@@ -85,19 +87,20 @@ func (h *Handler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	agents := make([]dto.AgentStats, len(agentStats))
 	for i, stat := range agentStats {
 		agents[i] = dto.AgentStats{
-			AgentID:         stat.AgentID,
-			AgentName:       stat.AgentName,
-			TasksCompleted:  stat.TasksCompleted,
-			TasksCancelled:  stat.TasksCancelled,
-			TasksStuckCount: stat.TasksStuckCount,
-			TasksInProgress: stat.TasksInProgress,
-			// Other fields defaulted to 0 (not implemented in MVP)
-			AvgLeadTimeMinutes:      0,
-			AvgCycleTimeMinutes:     0,
-			TasksTakenOverFromAgent: 0,
-			TasksTakenOverByAgent:   0,
-			EscalationsInitiated:    0,
-			EscalationsReceived:     0,
+			AgentID:                 stat.AgentID,
+			AgentName:               stat.AgentName,
+			TasksCompleted:          stat.TasksCompleted,
+			TasksCancelled:          stat.TasksCancelled,
+			TasksStuckCount:         stat.TasksStuckCount,
+			TasksInProgress:         stat.TasksInProgress,
+			AvgLeadTimeMinutes:      stat.AvgLeadTimeMinutes,
+			AvgCycleTimeMinutes:     stat.AvgCycleTimeMinutes,
+			TasksTakenOverFromAgent: stat.TasksTakenOverFromAgent,
+			TasksTakenOverByAgent:   stat.TasksTakenOverByAgent,
+			EscalationsInitiated:    stat.EscalationsInitiated,
+			EscalationsReceived:     stat.EscalationsReceived,
+			LastSeenAt:              stat.LastSeenAt,
+			Stale:                   stat.Stale,
 		}
 	}
 
@@ -118,13 +121,63 @@ func (h *Handler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 		PeriodEnd:   now,
 		Agents:      agents,
 		Workspace: dto.WorkspaceStats{
-			TotalTasksCreated:     workspaceStats.TotalTasksCreated,
-			TasksByStatus:         workspaceStats.TasksByStatus,
-			AvgLeadTimeMinutes:    0, // Not implemented in MVP
-			AvgCycleTimeMinutes:   0, // Not implemented in MVP
-			OverdueCount:          workspaceStats.OverdueCount,
-			StuckCount:            workspaceStats.StuckCount,
-			CompletionRatePercent: completionRate,
+			TotalTasksCreated:        workspaceStats.TotalTasksCreated,
+			TasksByStatus:            workspaceStats.TasksByStatus,
+			AvgLeadTimeMinutes:       workspaceStats.AvgLeadTimeMinutes,
+			AvgCycleTimeMinutes:      workspaceStats.AvgCycleTimeMinutes,
+			OverdueCount:             workspaceStats.OverdueCount,
+			StuckCount:               workspaceStats.StuckCount,
+			StaleAgentCount:          workspaceStats.StaleAgentCount,
+			CompletionRatePercent:    completionRate,
+			AwaitingExternalCount:    workspaceStats.AwaitingExternalCount,
+			AwaitingExternalBySystem: workspaceStats.AwaitingExternalBySystem,
 		},
 	})
 }
+
+// handleGetQueueDepth returns the number of claimable tasks for autoscalers.
+// @Summary Get queue depth
+// @Description Count tasks that could be claimed right now (NEW, unassigned, public, all blockers DONE), by priority, required capability and queue. Intended as a scaling signal for agent fleets. Tasks outside a named queue are reported under "_default".
+// @Tags stats
+// @Produce json
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Param queue query string false "Only count tasks in this queue"
+// @Success 200 {object} dto.QueueDepthResponse
+// @Security BearerAuth
+// @Router /stats/queue-depth [get]
+func (h *Handler) handleGetQueueDepth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var queue *string
+	if name := r.URL.Query().Get("queue"); name != "" {
+		queue = &name
+	}
+
+	depth, err := h.taskRepo.GetQueueDepth(ctx, workspaceID, queue)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch queue depth")
+		return
+	}
+
+	now := time.Now()
+	response := dto.QueueDepthResponse{
+		Queue:        queue,
+		Claimable:    depth.Total,
+		Unrestricted: depth.Unrestricted,
+		ByPriority:   depth.ByPriority,
+		ByCapability: depth.ByCapability,
+		ByQueue:      depth.ByQueue,
+		ServerTime:   now,
+	}
+	if depth.OldestAt != nil {
+		response.OldestPendingSeconds = int64(now.Sub(*depth.OldestAt).Seconds())
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
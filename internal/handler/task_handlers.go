@@ -2,12 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/handler/dto"
 	"github.com/mtlprog/sloptask/internal/middleware"
@@ -17,7 +21,7 @@ import (
 
 // handleCreateTask creates a new task.
 // @Summary Create a new task
-// @Description Creates a new task. If assignee_id is provided, task automatically transitions to IN_PROGRESS.
+// @Description Creates a new task. If assignee_id is provided, task automatically transitions to IN_PROGRESS. With requires_approval, agents cannot complete the task: it goes to WAITING_APPROVAL and an operator approves or rejects it.
 // @Tags tasks
 // @Accept json
 // @Produce json
@@ -39,8 +43,7 @@ func (h *Handler) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req dto.CreateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -74,49 +77,80 @@ func (h *Handler) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	blockedBy, err := h.resolveTaskRefs(ctx, req.BlockedBy)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	var unknownKeys []string
+	for i, id := range blockedBy {
+		if id == uuid.Nil.String() {
+			unknownKeys = append(unknownKeys, req.BlockedBy[i])
+		}
+	}
+	if len(unknownKeys) > 0 {
+		respondDomainError(w, domain.WithDetails(
+			fmt.Errorf("%w: blocker tasks not found: %v", domain.ErrValidation, unknownKeys),
+			map[string]any{"missing_blockers": unknownKeys},
+		))
+		return
+	}
+
 	// Create task
 	task, err := h.taskService.CreateTask(ctx, service.CreateTaskParams{
-		WorkspaceID: agent.WorkspaceID,
-		CreatorID:   agent.ID,
-		Title:       req.Title,
-		Description: req.Description,
-		AssigneeID:  req.AssigneeID,
-		Visibility:  visibility,
-		Priority:    priority,
-		BlockedBy:   req.BlockedBy,
+		WorkspaceID:          agent.WorkspaceID,
+		CreatorID:            agent.ID,
+		Title:                req.Title,
+		Description:          req.Description,
+		AssigneeID:           req.AssigneeID,
+		Visibility:           visibility,
+		Priority:             priority,
+		BlockedBy:            blockedBy,
+		RequiredCapabilities: req.RequiredCapabilities,
+		Queue:                req.Queue,
+		Labels:               req.Labels,
+		RequiresApproval:     req.RequiresApproval,
 	})
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
 	// Return created task
-	respondJSON(w, http.StatusCreated, dto.ToTaskDetail(task, false, false))
+	respondJSON(w, http.StatusCreated, dto.ToTaskDetail(task, false, false, time.Now().UTC()))
 }
 
 // handleGetTask retrieves task details with events.
 // @Summary Get task details
-// @Description Get full task details including description and event history
+// @Description Get full task details including description and event history. Read tokens and the admin token see public tasks only.
 // @Tags tasks
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Param time_format query string false "absolute (default) or relative: adds *_relative strings such as 'due in 35m'"
+// @Param If-None-Match header string false "ETag of an earlier response; 304 while the task is unchanged"
 // @Success 200 {object} dto.TaskDetailResponse
+// @Header 200 {string} X-Sloptask-Task-Version "Task version to send as If-Match on an edit or status change"
+// @Success 304 "Task unchanged"
 // @Failure 404 {object} dto.ErrorResponse
 // @Security BearerAuth
 // @Router /tasks/{id} [get]
 func (h *Handler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Extract authenticated agent
-	agent, err := middleware.GetAgentFromContext(ctx)
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
 		return
 	}
 
 	// Extract and validate task ID
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	relativeTimes, ok := parseRelativeTimeFormat(w, r)
 	if !ok {
 		return
 	}
@@ -124,22 +158,19 @@ func (h *Handler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 	// Get task
 	task, err := h.taskRepo.GetByID(ctx, taskID)
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
-	// Check visibility
-	if task.WorkspaceID != agent.WorkspaceID {
+	// Read tokens and coordinators have no agent: they see public tasks only
+	visible := task.WorkspaceID == workspaceID && task.Visibility == domain.TaskVisibilityPublic
+	if agent, err := middleware.GetAgentFromContext(ctx); err == nil {
+		visible = task.IsVisibleTo(agent)
+	}
+	if !visible {
 		respondError(w, http.StatusForbidden, "INSUFFICIENT_ACCESS", "Task not found")
 		return
 	}
-	if task.Visibility == domain.TaskVisibilityPrivate {
-		if task.CreatorID != agent.ID && (task.AssigneeID == nil || *task.AssigneeID != agent.ID) {
-			respondError(w, http.StatusForbidden, "INSUFFICIENT_ACCESS", "Task not found")
-			return
-		}
-	}
 
 	// Get events with actor names
 	events, err := h.eventRepo.GetByTaskIDWithActors(ctx, taskID)
@@ -170,34 +201,37 @@ func (h *Handler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	isOverdue := task.StatusDeadlineAt != nil && task.StatusDeadlineAt.Before(time.Now())
+	checklist, err := h.taskService.GetChecklist(ctx, taskID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch checklist")
+		return
+	}
+
+	now := time.Now().UTC()
+	isOverdue := task.StatusDeadlineAt != nil && task.StatusDeadlineAt.Before(now)
+
+	// The stored task covers updated_at and every field changed without an
+	// event; the last event covers the history. Checklist items change
+	// without either, so they are hashed as loaded
+	var lastEventID string
+	if len(events) > 0 {
+		lastEventID = events[len(events)-1].ID
+	}
+	w.Header().Set(taskVersionHeader, strconv.Itoa(task.Version))
+	if notModified(w, r, weakETag(task, lastEventID, checklist, hasUnresolvedBlockers, isOverdue, relativeTimes)) {
+		return
+	}
 
 	// Build response
 	response := dto.TaskDetailResponse{
-		Task:   dto.ToTaskDetail(task, hasUnresolvedBlockers, isOverdue),
-		Events: make([]dto.TaskEventInfo, len(events)),
+		Task:   dto.ToTaskDetail(task, hasUnresolvedBlockers, isOverdue, now),
+		Events: toTaskEventInfos(events),
 	}
-
-	for i, event := range events {
-		var oldStatus, newStatus *string
-		if event.OldStatus != nil {
-			s := string(*event.OldStatus)
-			oldStatus = &s
-		}
-		if event.NewStatus != nil {
-			s := string(*event.NewStatus)
-			newStatus = &s
-		}
-
-		response.Events[i] = dto.TaskEventInfo{
-			ID:        event.ID,
-			Type:      string(event.Type),
-			ActorID:   event.ActorID,
-			ActorName: event.ActorName,
-			Comment:   event.Comment,
-			OldStatus: oldStatus,
-			NewStatus: newStatus,
-			CreatedAt: event.CreatedAt,
+	response.Task.Checklist, response.Task.ChecklistProgress = dto.ToChecklist(checklist)
+	if relativeTimes {
+		response.Task.AddRelativeTimes(now)
+		for i := range response.Events {
+			response.Events[i].AddRelativeTimes(now)
 		}
 	}
 
@@ -206,14 +240,16 @@ func (h *Handler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 
 // handleTransitionStatus changes task status.
 // @Summary Transition task status
-// @Description Change task status with comment
+// @Description Change task status with comment. NEEDS_REVIEW submits work for review; another agent then approves (DONE) or rejects (IN_PROGRESS). WAITING_APPROVAL (artefact required) hands finished work to an operator; tasks created with requires_approval cannot go to DONE any other way (409 APPROVAL_REQUIRED)
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
 // @Param request body dto.TransitionStatusRequest true "Status transition request"
+// @Param If-Match header string false "Version the transition expects the task to be at, e.g. \"3\"; required unless expected_version is sent"
 // @Success 200 {object} dto.TaskEventResponse
 // @Failure 409 {object} dto.ErrorResponse
+// @Failure 428 {object} dto.ErrorResponse "Neither If-Match nor expected_version sent"
 // @Security BearerAuth
 // @Router /tasks/{id}/status [patch]
 func (h *Handler) handleTransitionStatus(w http.ResponseWriter, r *http.Request) {
@@ -225,14 +261,13 @@ func (h *Handler) handleTransitionStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
 	if !ok {
 		return
 	}
 
 	var req dto.TransitionStatusRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -250,11 +285,24 @@ func (h *Handler) handleTransitionStatus(w http.ResponseWriter, r *http.Request)
 		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "invalid status")
 		return
 	}
+	expectedVersion, ok := expectedTaskVersion(w, r, req.ExpectedVersion)
+	if !ok {
+		return
+	}
 
-	event, err := h.taskService.TransitionStatus(ctx, taskID, agent.ID, newStatus, req.Comment, req.Artefact)
+	event, err := h.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   agent.ID,
+		NewStatus: newStatus,
+		Comment:   req.Comment,
+		Artefact:  req.Artefact,
+		Result:    req.Result,
+		Data:      req.Data,
+
+		ExpectedVersion: expectedVersion,
+	})
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
@@ -263,14 +311,15 @@ func (h *Handler) handleTransitionStatus(w http.ResponseWriter, r *http.Request)
 
 // handleClaimTask claims an unassigned NEW task.
 // @Summary Claim a task
-// @Description Agent claims an unassigned NEW task
+// @Description Agent claims an unassigned NEW task. Workspaces may limit claims: 429 CLAIM_QUOTA_EXCEEDED once the agent used up its quota, 409 CLAIM_TURN when the agent made the last claim while another agent is idle.
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
 // @Param request body dto.ClaimTaskRequest true "Claim request"
 // @Success 200 {object} dto.TaskEventResponse
 // @Failure 409 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Claim quota exceeded"
 // @Security BearerAuth
 // @Router /tasks/{id}/claim [post]
 func (h *Handler) handleClaimTask(w http.ResponseWriter, r *http.Request) {
@@ -282,14 +331,13 @@ func (h *Handler) handleClaimTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
 	if !ok {
 		return
 	}
 
 	var req dto.ClaimTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -300,8 +348,274 @@ func (h *Handler) handleClaimTask(w http.ResponseWriter, r *http.Request) {
 
 	event, err := h.taskService.ClaimTask(ctx, taskID, agent.ID, req.Comment)
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleClaimNext claims the most urgent task the agent is able to claim.
+// @Summary Claim the next available task
+// @Description Claims the highest-priority, oldest unassigned public NEW task whose blockers are DONE and whose required capabilities the agent has. Optionally scoped to one queue. Concurrent callers receive different tasks. The workspace's claim limits apply as for claim.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body dto.ClaimNextRequest true "Claim-next request"
+// @Success 200 {object} dto.ClaimNextResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Not the agent's turn"
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse "Claim quota exceeded"
+// @Security BearerAuth
+// @Router /tasks/claim-next [post]
+func (h *Handler) handleClaimNext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.ClaimNextRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Comment == "" {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "comment is required")
+		return
+	}
+
+	task, event, err := h.taskService.ClaimNext(ctx, service.ClaimNextParams{
+		AgentID: agent.ID,
+		Queue:   req.Queue,
+		Comment: req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ClaimNextResponse{
+		Task:  dto.ToTaskDetail(task, false, false, time.Now().UTC()),
+		Event: dto.ToTaskEventResponse(event),
+	})
+}
+
+// Bounds of the timeout query parameter of GET /tasks/wait.
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 60 * time.Second
+)
+
+// handleWaitForTask long-polls for a task the agent is able to claim.
+// @Summary Wait for claimable work
+// @Description Returns the task claim-next would claim as soon as there is one, waiting up to timeout (Go duration, default 30s, at most 60s). The task is not claimed: claim it with POST /tasks/{id}/claim or claim-next, and wait again if another agent was faster. 204 when nothing appeared in time.
+// @Tags tasks
+// @Produce json
+// @Param timeout query string false "How long to wait, e.g. 30s"
+// @Param queue query string false "Only wait for tasks of this queue"
+// @Success 200 {object} dto.WaitForTaskResponse
+// @Success 204 "No claimable task appeared in time"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/wait [get]
+func (h *Handler) handleWaitForTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+
+	timeout := defaultWaitTimeout
+	if timeoutParam := query.Get("timeout"); timeoutParam != "" {
+		timeout, err = time.ParseDuration(timeoutParam)
+		if err != nil || timeout < 0 || timeout > maxWaitTimeout {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "timeout must be a duration between 0s and 60s")
+			return
+		}
+	}
+
+	var queue *string
+	if queueParam := query.Get("queue"); queueParam != "" {
+		queue = &queueParam
+	}
+
+	// Outlive the server's write timeout. Fails on test recorders, and on any
+	// middleware writer that doesn't unwrap, which cuts long-polls off
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second)); err != nil {
+		slog.Warn("failed to extend write deadline for long-poll", "error", err)
+	}
+
+	var changes <-chan *domain.TaskChange
+	if h.changeFeed != nil {
+		var unsubscribe func()
+		changes, unsubscribe = h.changeFeed.Subscribe(agent.WorkspaceID)
+		defer unsubscribe()
+	}
+
+	task, err := h.taskService.WaitForClaimable(ctx, changes, service.WaitForClaimableParams{
+		AgentID: agent.ID,
+		Queue:   queue,
+		Timeout: timeout,
+	})
+	switch {
+	case errors.Is(err, domain.ErrNoClaimableTask), ctx.Err() != nil:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case err != nil:
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.WaitForTaskResponse{
+		Task: dto.ToTaskDetail(task, false, false, time.Now().UTC()),
+	})
+}
+
+// handleReopenTask reopens a DONE task.
+// @Summary Reopen a task
+// @Description Task creator reopens a DONE task to NEW (back to the pool) or IN_PROGRESS (same assignee). IN_PROGRESS dependents become BLOCKED.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.ReopenTaskRequest true "Reopen request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/reopen [post]
+func (h *Handler) handleReopenTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ReopenTaskRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Comment == "" {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "comment is required")
+		return
+	}
+
+	newStatus := domain.TaskStatusNew
+	if req.Status != "" {
+		newStatus = domain.TaskStatus(req.Status)
+	}
+
+	event, err := h.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    taskID,
+		AgentID:   agent.ID,
+		NewStatus: newStatus,
+		Comment:   req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleArchiveTask archives a finished task.
+// @Summary Archive a task
+// @Description Task creator or assignee hides a DONE or CANCELLED task from default task lists. The task stays readable by ID and is listed with archived=include or archived=only. Reopening an archived task unarchives it. The body is optional.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.ArchiveTaskRequest false "Archive request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/archive [post]
+func (h *Handler) handleArchiveTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ArchiveTaskRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.ArchiveTask(ctx, taskID, agent.ID, req.Comment)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleDeleteTask soft-deletes a task.
+// @Summary Delete a task
+// @Description Task creator deletes a task created by mistake or containing sensitive data. The task and its events disappear from every API and are removed for good by `sloptask purge`. Dependent tasks stop being blocked by it. The body is optional.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param request body dto.DeleteTaskRequest false "Delete request"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id} [delete]
+func (h *Handler) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.DeleteTaskRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	event, err := h.taskService.DeleteTask(ctx, service.DeleteTaskParams{
+		TaskID:  taskID,
+		AgentID: &agent.ID,
+		Comment: req.Comment,
+	})
+	if err != nil {
+		respondDomainError(w, err)
 		return
 	}
 
@@ -314,7 +628,7 @@ func (h *Handler) handleClaimTask(w http.ResponseWriter, r *http.Request) {
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
 // @Param request body dto.EscalateTaskRequest true "Escalate request"
 // @Success 200 {object} dto.TaskEventResponse
 // @Failure 409 {object} dto.ErrorResponse
@@ -329,14 +643,13 @@ func (h *Handler) handleEscalateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
 	if !ok {
 		return
 	}
 
 	var req dto.EscalateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -347,8 +660,7 @@ func (h *Handler) handleEscalateTask(w http.ResponseWriter, r *http.Request) {
 
 	event, err := h.taskService.EscalateTask(ctx, taskID, agent.ID, req.Comment)
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
@@ -361,7 +673,7 @@ func (h *Handler) handleEscalateTask(w http.ResponseWriter, r *http.Request) {
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
 // @Param request body dto.TakeoverTaskRequest true "Takeover request"
 // @Success 200 {object} dto.TaskEventResponse
 // @Failure 409 {object} dto.ErrorResponse
@@ -376,14 +688,13 @@ func (h *Handler) handleTakeoverTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
 	if !ok {
 		return
 	}
 
 	var req dto.TakeoverTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -394,8 +705,7 @@ func (h *Handler) handleTakeoverTask(w http.ResponseWriter, r *http.Request) {
 
 	event, err := h.taskService.TakeoverTask(ctx, taskID, agent.ID, req.Comment)
 	if err != nil {
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
@@ -404,14 +714,15 @@ func (h *Handler) handleTakeoverTask(w http.ResponseWriter, r *http.Request) {
 
 // handleCommentTask adds a comment to a task.
 // @Summary Add comment to task
-// @Description Add a comment without changing task status
+// @Description Add a comment without changing task status. Mention other agents as @name to notify them: each name must be an active agent of the workspace that can see the task. Their IDs are recorded in data.mentions, which is reserved, and the comment shows up in their GET /notifications. Set reply_to_event_id to another comment of the task to reply to it; the reply joins that comment's thread.
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param id path string true "Task ID"
+// @Param id path string true "Task ID or key such as TST-42"
 // @Param request body dto.CommentTaskRequest true "Comment request"
 // @Success 201 {object} dto.TaskEventResponse
 // @Failure 403 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
 // @Security BearerAuth
 // @Router /tasks/{id}/comments [post]
 func (h *Handler) handleCommentTask(w http.ResponseWriter, r *http.Request) {
@@ -423,14 +734,13 @@ func (h *Handler) handleCommentTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taskID, ok := extractTaskID(w, r)
+	taskID, ok := h.extractTaskID(w, r)
 	if !ok {
 		return
 	}
 
 	var req dto.CommentTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid request body")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -438,53 +748,353 @@ func (h *Handler) handleCommentTask(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "comment is required")
 		return
 	}
+	if req.ReplyToEventID != nil {
+		if _, err := uuid.Parse(*req.ReplyToEventID); err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "reply_to_event_id must be a valid UUID")
+			return
+		}
+	}
 
 	// DELEGATE TO SERVICE LAYER
-	event, err := h.taskService.CommentTask(ctx, taskID, agent.ID, req.Comment)
+	event, err := h.taskService.CommentTask(ctx, taskID, agent.ID, req.Comment, req.Data, req.ReplyToEventID)
 	if err != nil {
 		slog.Error("failed to add comment",
 			"task_id", taskID,
 			"agent_id", agent.ID,
 			"error", err,
 		)
-		status, code, message := dto.MapDomainError(err)
-		respondError(w, status, code, message)
+		respondDomainError(w, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, dto.ToTaskEventResponse(event))
 }
 
+// handleEditComment replaces the body of the calling agent's comment.
+// @Summary Edit comment
+// @Description Replace the body of your own comment within 15 minutes of posting it. The comment keeps its place among the task's events and gets edited_at; a comment_edited event records the change. The previous body is kept for operators only. Mentions stay as posted.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param event_id path string true "Event ID of the comment"
+// @Param request body dto.EditCommentRequest true "New body"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{event_id} [patch]
+func (h *Handler) handleEditComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+	eventID, ok := extractPathUUID(w, r, "event_id", "event id")
+	if !ok {
+		return
+	}
+
+	var req dto.EditCommentRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Comment == "" {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "comment is required")
+		return
+	}
+
+	event, err := h.taskService.EditComment(ctx, taskID, eventID, agent.ID, req.Comment)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// handleRedactComment empties the calling agent's comment.
+// @Summary Redact comment
+// @Description Empty your own comment within 15 minutes of posting it, e.g. to take back a secret. The comment stays among the task's events with an empty body and redacted_at; a comment_edited event records the redaction. The previous body is kept for operators only, and a redacted comment can't be edited again.
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Param event_id path string true "Event ID of the comment"
+// @Success 200 {object} dto.TaskEventResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{event_id} [delete]
+func (h *Handler) handleRedactComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+	eventID, ok := extractPathUUID(w, r, "event_id", "event id")
+	if !ok {
+		return
+	}
+
+	event, err := h.taskService.RedactComment(ctx, taskID, eventID, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskEventResponse(event))
+}
+
+// maxListIDs bounds the ids filter of GET /tasks to one page of the largest size.
+const maxListIDs = 200
+
 // handleListTasks returns a list of tasks with filters.
 // @Summary List tasks
-// @Description Get a list of tasks with optional filters
+// @Description Get a list of tasks with optional filters. Read tokens and the admin token see public tasks only; the admin token selects the workspace with the workspace parameter or the X-Sloptask-Workspace header.
 // @Tags tasks
 // @Produce json
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Param view query string false "Name of one of your saved views; parameters given here override the view's"
+// @Param ids query string false "Comma-separated task IDs or keys, at most 200, e.g. a task's blocked_by: fetches them in one request, archived ones included unless archived is set. Tasks not found or not visible are left out"
 // @Param status query string false "Comma-separated statuses: NEW,STUCK"
 // @Param assignee query string false "Filter by assignee: 'me' or agent UUID"
 // @Param unassigned query bool false "Show only unassigned tasks"
+// @Param creator query string false "Filter by creator: 'me' or agent UUID"
+// @Param blocked_by query string false "Task ID or key: only the tasks it blocks, e.g. what finishing it unblocks"
+// @Param blocks query string false "Task ID or key: only its blockers"
+// @Param created_after query string false "Only tasks created at or after this time: RFC 3339 or a UTC date such as 2026-10-12"
+// @Param created_before query string false "Only tasks created before this time: RFC 3339 or a UTC date"
+// @Param updated_after query string false "Only tasks updated at or after this time: RFC 3339 or a UTC date"
 // @Param visibility query string false "Filter by visibility: public or private"
 // @Param priority query string false "Comma-separated priorities: high,critical"
+// @Param queue query string false "Filter by queue name"
+// @Param label query string false "Comma-separated labels; tasks must carry all of them"
+// @Param archived query string false "exclude (default), include or only: archived tasks are hidden unless requested"
 // @Param overdue query bool false "Show only overdue tasks"
+// @Param human_review query bool false "Show only tasks held for human review after changing hands too often"
 // @Param has_unresolved_blockers query bool false "Show only tasks with unresolved blockers"
 // @Param sort query string false "Sort fields: -priority,created_at"
+// @Param fields query string false "Only return these task fields, e.g. id,title,status,priority"
 // @Param limit query int false "Page size (1-200, default 50)"
 // @Param offset query int false "Page offset (default 0)"
+// @Param time_format query string false "absolute (default) or relative: adds *_relative strings such as '2h ago'"
+// @Param If-None-Match header string false "ETag of an earlier response; 304 while the page is unchanged"
 // @Success 200 {object} dto.TasksListResponse
+// @Success 304 "Page unchanged"
 // @Security BearerAuth
 // @Router /tasks [get]
 func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	agent, err := middleware.GetAgentFromContext(ctx)
+	r, ok := h.applyView(w, r)
+	if !ok {
+		return
+	}
+
+	filters, ok := h.parseTaskListFilters(w, r)
+	if !ok {
+		return
+	}
+
+	relativeTimes, ok := parseRelativeTimeFormat(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Parse sort (comma-separated)
+	var sort []string
+	if sortParam := query.Get("sort"); sortParam != "" {
+		sort = splitAndTrim(sortParam, ",")
+	}
+
+	// Parse sparse fieldset (comma-separated)
+	var fields []string
+	if fieldsParam := query.Get("fields"); fieldsParam != "" {
+		fields = splitAndTrim(fieldsParam, ",")
+		if err := dto.ValidateTaskListFields(fields); err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "fields: "+err.Error())
+			return
+		}
+	}
+
+	// Parse pagination; all requested IDs fit on one page
+	limit := 50
+	if len(filters.IDs) > 0 {
+		limit = len(filters.IDs)
+	}
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	offset := 0
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	// Call repository
+	filters.Sort = sort
+	filters.Limit = limit
+	filters.Offset = offset
+	results, total, err := h.taskRepo.List(ctx, filters)
 	if err != nil {
-		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list tasks")
+		return
+	}
+
+	if notModified(w, r, weakETag(results, total, limit, offset, relativeTimes, fields)) {
+		return
+	}
+
+	// Convert to response format
+	now := time.Now().UTC()
+	tasks := make([]dto.TaskListResponse, len(results))
+	for i, result := range results {
+		tasks[i] = dto.ToTaskListResponse(result.Task, result.HasUnresolvedBlockers, result.IsOverdue, now)
+		if relativeTimes {
+			tasks[i].AddRelativeTimes(now)
+		}
+	}
+
+	if len(fields) > 0 {
+		partial := make([]map[string]json.RawMessage, len(tasks))
+		for i, task := range tasks {
+			if partial[i], err = dto.SelectFields(task, fields); err != nil {
+				respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to select task fields")
+				return
+			}
+		}
+		respondJSON(w, http.StatusOK, dto.PartialTasksListResponse{
+			Tasks:  partial,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.TasksListResponse{
+		Tasks:  tasks,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// handleListTaskFacets counts the tasks matching the list filters by facet.
+// @Summary Count tasks by facet
+// @Description Counts of the tasks matching the GET /tasks filters, by status, priority, assignee and label, computed in one query. A task counts once per label it carries. Read tokens and the admin token count public tasks only.
+// @Tags tasks
+// @Produce json
+// @Param workspace query string false "Workspace ID or slug; required with the admin token"
+// @Param view query string false "Name of one of your saved views; parameters given here override the view's"
+// @Param status query string false "Comma-separated statuses: NEW,STUCK"
+// @Param assignee query string false "Filter by assignee: 'me' or agent UUID"
+// @Param unassigned query bool false "Count only unassigned tasks"
+// @Param creator query string false "Filter by creator: 'me' or agent UUID"
+// @Param created_after query string false "Only tasks created at or after this time: RFC 3339 or a UTC date such as 2026-10-12"
+// @Param created_before query string false "Only tasks created before this time: RFC 3339 or a UTC date"
+// @Param updated_after query string false "Only tasks updated at or after this time: RFC 3339 or a UTC date"
+// @Param visibility query string false "Filter by visibility: public or private"
+// @Param priority query string false "Comma-separated priorities: high,critical"
+// @Param queue query string false "Filter by queue name"
+// @Param label query string false "Comma-separated labels; tasks must carry all of them"
+// @Param archived query string false "exclude (default), include or only"
+// @Param overdue query bool false "Count only overdue tasks"
+// @Param has_unresolved_blockers query bool false "Count only tasks with unresolved blockers"
+// @Success 200 {object} dto.TaskFacetsResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/facets [get]
+func (h *Handler) handleListTaskFacets(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.applyView(w, r)
+	if !ok {
+		return
+	}
+
+	filters, ok := h.parseTaskListFilters(w, r)
+	if !ok {
+		return
+	}
+
+	facets, err := h.taskRepo.Facets(r.Context(), filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to count tasks")
 		return
 	}
 
+	respondJSON(w, http.StatusOK, dto.TaskFacetsResponse{
+		Total:      facets.Total,
+		Status:     facets.Status,
+		Priority:   facets.Priority,
+		Assignee:   facets.Assignee,
+		Unassigned: facets.Unassigned,
+		Label:      facets.Label,
+	})
+}
+
+// parseTaskListFilters parses the filters GET /tasks and GET /tasks/facets
+// share, for the calling agent's workspace.
+// Returns (filters, true) if valid, (filters, false) if invalid (error already sent to client).
+func (h *Handler) parseTaskListFilters(w http.ResponseWriter, r *http.Request) (repository.TaskListFilters, bool) {
+	ctx := r.Context()
+
+	workspaceID, err := middleware.GetWorkspaceIDFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return repository.TaskListFilters{}, false
+	}
+
+	// Read tokens and coordinators have no agent: they list public tasks only
+	var agentID string
+	if agent, err := middleware.GetAgentFromContext(ctx); err == nil {
+		agentID = agent.ID
+	}
+
 	// Parse query parameters
 	query := r.URL.Query()
 
+	// Parse task IDs (comma-separated), e.g. to resolve a blocked_by list
+	var ids []string
+	if idsParam := query.Get("ids"); idsParam != "" {
+		ids = splitAndTrim(idsParam, ",")
+		if len(ids) > maxListIDs {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", fmt.Sprintf("ids takes at most %d task IDs", maxListIDs))
+			return repository.TaskListFilters{}, false
+		}
+		if ids, err = h.resolveTaskRefs(ctx, ids); err != nil {
+			if errors.Is(err, domain.ErrValidation) {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "ids must be task UUIDs or keys")
+				return repository.TaskListFilters{}, false
+			}
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to resolve task keys")
+			return repository.TaskListFilters{}, false
+		}
+	}
+
 	// Parse statuses (comma-separated)
 	var statuses []string
 	if statusParam := query.Get("status"); statusParam != "" {
@@ -496,7 +1106,11 @@ func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
 	unassigned := false
 	if assigneeParam := query.Get("assignee"); assigneeParam != "" {
 		if assigneeParam == "me" {
-			assigneeID = &agent.ID
+			if agentID == "" {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "assignee=me requires an agent token")
+				return repository.TaskListFilters{}, false
+			}
+			assigneeID = &agentID
 		} else {
 			assigneeID = &assigneeParam
 		}
@@ -505,6 +1119,48 @@ func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
 		unassigned = true
 	}
 
+	// Parse creator
+	var creatorID *string
+	if creatorParam := query.Get("creator"); creatorParam != "" {
+		if creatorParam == "me" {
+			if agentID == "" {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "creator=me requires an agent token")
+				return repository.TaskListFilters{}, false
+			}
+			creatorID = &agentID
+		} else {
+			if _, err := uuid.Parse(creatorParam); err != nil {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "creator must be 'me' or an agent UUID")
+				return repository.TaskListFilters{}, false
+			}
+			creatorID = &creatorParam
+		}
+	}
+
+	// Parse dependency filters
+	blockedBy, ok := h.parseTaskRefParam(w, r, "blocked_by")
+	if !ok {
+		return repository.TaskListFilters{}, false
+	}
+	blocks, ok := h.parseTaskRefParam(w, r, "blocks")
+	if !ok {
+		return repository.TaskListFilters{}, false
+	}
+
+	// Parse date ranges
+	createdAfter, ok := parseTimeParam(w, query, "created_after")
+	if !ok {
+		return repository.TaskListFilters{}, false
+	}
+	createdBefore, ok := parseTimeParam(w, query, "created_before")
+	if !ok {
+		return repository.TaskListFilters{}, false
+	}
+	updatedAfter, ok := parseTimeParam(w, query, "updated_after")
+	if !ok {
+		return repository.TaskListFilters{}, false
+	}
+
 	// Parse visibility
 	var visibility *string
 	if visibilityParam := query.Get("visibility"); visibilityParam != "" {
@@ -517,63 +1173,119 @@ func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
 		priorities = splitAndTrim(priorityParam, ",")
 	}
 
-	// Parse boolean filters
-	overdue := query.Get("overdue") == "true"
-	hasUnresolvedBlockers := query.Get("has_unresolved_blockers") == "true"
-
-	// Parse sort (comma-separated)
-	var sort []string
-	if sortParam := query.Get("sort"); sortParam != "" {
-		sort = splitAndTrim(sortParam, ",")
+	// Parse queue
+	var queue *string
+	if queueParam := query.Get("queue"); queueParam != "" {
+		queueName := strings.ToLower(strings.TrimSpace(queueParam))
+		queue = &queueName
 	}
 
-	// Parse pagination
-	limit := 50
-	if limitParam := query.Get("limit"); limitParam != "" {
-		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
-			limit = n
+	// Parse labels (comma-separated, all must match)
+	var labels []string
+	if labelParam := query.Get("label"); labelParam != "" {
+		for _, label := range splitAndTrim(labelParam, ",") {
+			labels = append(labels, strings.ToLower(label))
 		}
 	}
 
-	offset := 0
-	if offsetParam := query.Get("offset"); offsetParam != "" {
-		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
-			offset = n
+	// Parse archived mode; finished blockers may be archived, so fetching by
+	// IDs includes archived tasks unless asked otherwise
+	archived := repository.ArchivedExclude
+	if len(ids) > 0 {
+		archived = repository.ArchivedInclude
+	}
+	if archivedParam := query.Get("archived"); archivedParam != "" {
+		switch archivedParam {
+		case repository.ArchivedExclude, repository.ArchivedInclude, repository.ArchivedOnly:
+			archived = archivedParam
+		default:
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "archived must be exclude, include or only")
+			return repository.TaskListFilters{}, false
 		}
 	}
 
-	// Call repository
-	results, total, err := h.taskRepo.List(ctx, repository.TaskListFilters{
-		WorkspaceID:           agent.WorkspaceID,
-		AgentID:               agent.ID, // SECURITY: Required for private task filtering
+	// Parse boolean filters
+	overdue := query.Get("overdue") == "true"
+	humanReview := query.Get("human_review") == "true"
+	hasUnresolvedBlockers := query.Get("has_unresolved_blockers") == "true"
+
+	return repository.TaskListFilters{
+		WorkspaceID:           workspaceID,
+		AgentID:               agentID, // SECURITY: Required for private task filtering
+		IDs:                   ids,
 		Statuses:              statuses,
 		AssigneeID:            assigneeID,
 		Unassigned:            unassigned,
+		CreatorID:             creatorID,
+		BlockedBy:             blockedBy,
+		Blocks:                blocks,
 		Visibility:            visibility,
 		Priorities:            priorities,
+		Queue:                 queue,
+		Labels:                labels,
+		Archived:              archived,
 		Overdue:               overdue,
+		HumanReview:           humanReview,
 		HasUnresolvedBlockers: hasUnresolvedBlockers,
-		Sort:                  sort,
-		Limit:                 limit,
-		Offset:                offset,
-	})
+		CreatedAfter:          createdAfter,
+		CreatedBefore:         createdBefore,
+		UpdatedAfter:          updatedAfter,
+	}, true
+}
+
+// parseTimeParam parses the optional time query parameter name, given in RFC
+// 3339 or as a date, which stands for its start in UTC.
+// Returns (t, true) if valid or absent, (nil, false) if invalid (error already sent to client).
+func parseTimeParam(w http.ResponseWriter, query url.Values, name string) (*time.Time, bool) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list tasks")
-		return
+		t, err = time.Parse(time.DateOnly, value)
 	}
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", name+" must be an RFC 3339 time or a date such as 2026-10-12")
+		return nil, false
+	}
+	return &t, true
+}
 
-	// Convert to response format
-	tasks := make([]dto.TaskListResponse, len(results))
-	for i, result := range results {
-		tasks[i] = dto.ToTaskListResponse(result.Task, result.HasUnresolvedBlockers, result.IsOverdue)
+// parseTaskRefParam resolves the task UUID or key in query parameter name.
+// A key no task has resolves to uuid.Nil, so it matches nothing.
+// Returns (nil, true) if absent, (nil, false) if invalid (error already sent to client).
+func (h *Handler) parseTaskRefParam(w http.ResponseWriter, r *http.Request, name string) (*string, bool) {
+	ref := r.URL.Query().Get(name)
+	if ref == "" {
+		return nil, true
 	}
 
-	respondJSON(w, http.StatusOK, dto.TasksListResponse{
-		Tasks:  tasks,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
-	})
+	taskIDs, err := h.resolveTaskRefs(r.Context(), []string{ref})
+	if err != nil {
+		if errors.Is(err, domain.ErrValidation) {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", name+" must be a valid UUID or task key")
+			return nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to resolve task key")
+		return nil, false
+	}
+	return &taskIDs[0], true
+}
+
+// parseRelativeTimeFormat reports whether ?time_format=relative was requested.
+// Returns (relative, true) if valid, (false, false) if invalid (error already sent to client).
+func parseRelativeTimeFormat(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("time_format") {
+	case "", dto.TimeFormatAbsolute:
+		return false, true
+	case dto.TimeFormatRelative:
+		return true, true
+	default:
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "time_format must be absolute or relative")
+		return false, false
+	}
 }
 
 // splitAndTrim splits a string by delimiter and trims whitespace.
@@ -587,3 +1299,71 @@ func splitAndTrim(s, sep string) []string {
 	}
 	return result
 }
+
+// handleGetTaskLineage returns the ancestry and descendant trees of a task.
+// @Summary Get task lineage
+// @Description Get the tree of tasks this task depends on (ancestors) and tasks depending on it (descendants), with statuses
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Success 200 {object} dto.TaskLineageResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/lineage [get]
+func (h *Handler) handleGetTaskLineage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	lineage, err := h.taskService.GetLineage(ctx, taskID, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskLineageResponse(lineage))
+}
+
+// handleGetTaskTimings returns how long a task spent in each status and with each assignee.
+// @Summary Get task timings
+// @Description Break down, from the task's events, how long the task spent in each status and with each assignee, with the underlying periods. Open tasks are measured until now; time in DONE or CANCELLED only counts once the task was reopened. Durations are in seconds.
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Success 200 {object} dto.TaskTimingsResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/timings [get]
+func (h *Handler) handleGetTaskTimings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	timings, err := h.taskService.GetTimings(ctx, taskID, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToTaskTimingsResponse(timings))
+}
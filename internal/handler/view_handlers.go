@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+// handleListViews lists the calling agent's saved views.
+// @Summary List my views
+// @Description Get your saved task list views, by name
+// @Tags views
+// @Produce json
+// @Success 200 {object} dto.ViewsListResponse
+// @Security BearerAuth
+// @Router /views [get]
+func (h *Handler) handleListViews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	views, err := h.viewService.ListViews(ctx, agent.ID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ViewsListResponse{Views: make([]dto.ViewResponse, len(views))}
+	for i, view := range views {
+		response.Views[i] = dto.ToViewResponse(view)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleCreateView saves a named task list query for the calling agent.
+// @Summary Create view
+// @Description Save GET /tasks filters, sort, fields and limit under a name, then list them with GET /tasks?view=<name>. Views are private to you. Names are lowercase letters, digits, '-' and '_'.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateViewRequest true "View"
+// @Success 201 {object} dto.ViewResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /views [post]
+func (h *Handler) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.CreateViewRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	view, err := h.viewService.CreateView(ctx, agent.ID, req.Name, req.Params)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dto.ToViewResponse(view))
+}
+
+// handleGetView returns one of the calling agent's saved views.
+// @Summary Get view
+// @Tags views
+// @Produce json
+// @Param name path string true "View name"
+// @Success 200 {object} dto.ViewResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /views/{name} [get]
+func (h *Handler) handleGetView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	view, err := h.viewService.GetView(ctx, agent.ID, r.PathValue("name"))
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToViewResponse(view))
+}
+
+// handleUpdateView replaces the query of a saved view.
+// @Summary Update view
+// @Description Replace the saved GET /tasks parameters of a view. Pollers using the view list by the new ones from their next request.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param name path string true "View name"
+// @Param request body dto.UpdateViewRequest true "New parameters"
+// @Success 200 {object} dto.ViewResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /views/{name} [put]
+func (h *Handler) handleUpdateView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	var req dto.UpdateViewRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	view, err := h.viewService.UpdateView(ctx, agent.ID, r.PathValue("name"), req.Params)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToViewResponse(view))
+}
+
+// handleDeleteView deletes a saved view.
+// @Summary Delete view
+// @Tags views
+// @Param name path string true "View name"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /views/{name} [delete]
+func (h *Handler) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	if err := h.viewService.DeleteView(ctx, agent.ID, r.PathValue("name")); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyView fills in the query of a request naming ?view= with the calling
+// agent's saved view. Parameters the request sets itself win, e.g. offset to
+// page through the view.
+// Returns (r, true) without a view, (nil, false) if it can't be applied (error already sent to client).
+func (h *Handler) applyView(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	query := r.URL.Query()
+	name := query.Get("view")
+	if name == "" {
+		return r, true
+	}
+
+	agent, err := middleware.GetAgentFromContext(r.Context())
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "view requires an agent token")
+		return nil, false
+	}
+
+	view, err := h.viewService.GetView(r.Context(), agent.ID, name)
+	if err != nil {
+		respondDomainError(w, err)
+		return nil, false
+	}
+
+	for param, value := range view.Params {
+		if !query.Has(param) {
+			query.Set(param, value)
+		}
+	}
+	query.Del("view")
+
+	r = r.Clone(r.Context())
+	r.URL.RawQuery = query.Encode()
+	return r, true
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// handleWatchTask subscribes the calling agent to a task's events.
+// @Summary Watch task
+// @Description Subscribe to the events of a task you can see, whether or not you created or own it. From now on its events show up in GET /notifications. Watching a task again keeps the first watch.
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Task ID or key such as TST-42"
+// @Success 200 {object} dto.WatchInfo
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/watch [post]
+func (h *Handler) handleWatchTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	watch, err := h.watchService.Watch(ctx, taskID, agent)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToWatchInfo(watch))
+}
+
+// handleUnwatchTask unsubscribes the calling agent from a task's events.
+// @Summary Unwatch task
+// @Description Stop receiving notifications of the task's events. Unwatching a task you don't watch succeeds too.
+// @Tags notifications
+// @Param id path string true "Task ID or key such as TST-42"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/watch [delete]
+func (h *Handler) handleUnwatchTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	taskID, ok := h.extractTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchService.Unwatch(ctx, taskID, agent); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListNotifications lists the events on the tasks the calling agent
+// watches and the comments mentioning it.
+// @Summary List my notifications
+// @Description Events on the tasks you watch since you started watching, and comments mentioning you as @name, newest first. Your own events are left out, as are events of deleted tasks and of private tasks you can no longer see.
+// @Tags notifications
+// @Produce json
+// @Param task_id query string false "Only events of this task (ID or key)"
+// @Param mentioned query bool false "Only comments mentioning you"
+// @Param limit query int false "Page size (1-200, default 50)"
+// @Param offset query int false "Page offset (default 0)"
+// @Success 200 {object} dto.NotificationsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *Handler) handleListNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agent, err := middleware.GetAgentFromContext(ctx)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "INVALID_TOKEN", "Authentication required")
+		return
+	}
+
+	query := r.URL.Query()
+	filters := repository.NotificationFilters{AgentID: agent.ID, WorkspaceID: agent.WorkspaceID, Limit: 50}
+
+	if taskRef := query.Get("task_id"); taskRef != "" {
+		taskIDs, err := h.resolveTaskRefs(ctx, []string{taskRef})
+		if err != nil {
+			if errors.Is(err, domain.ErrValidation) {
+				respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "task_id must be a valid UUID or task key")
+				return
+			}
+			respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to resolve task key")
+			return
+		}
+		filters.TaskID = &taskIDs[0]
+	}
+
+	if mentionedParam := query.Get("mentioned"); mentionedParam != "" {
+		mentioned, err := strconv.ParseBool(mentionedParam)
+		if err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "mentioned must be true or false")
+			return
+		}
+		filters.MentionedOnly = mentioned
+	}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 200 {
+			filters.Limit = n
+		}
+	}
+
+	if offsetParam := query.Get("offset"); offsetParam != "" {
+		if n, err := strconv.Atoi(offsetParam); err == nil && n >= 0 {
+			filters.Offset = n
+		}
+	}
+
+	notifications, total, err := h.watchService.ListNotifications(ctx, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch notifications")
+		return
+	}
+
+	events := make([]repository.TaskEventWithActor, len(notifications))
+	for i, notification := range notifications {
+		events[i] = notification.TaskEventWithActor
+	}
+
+	response := dto.NotificationsResponse{
+		Notifications: make([]dto.NotificationInfo, len(notifications)),
+		Total:         total,
+		Limit:         filters.Limit,
+		Offset:        filters.Offset,
+	}
+	for i, event := range toTaskEventInfos(events) {
+		response.Notifications[i] = dto.NotificationInfo{
+			TaskID:        notifications[i].TaskID,
+			TaskTitle:     notifications[i].TaskTitle,
+			Mentioned:     notifications[i].Mentioned,
+			TaskEventInfo: event,
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
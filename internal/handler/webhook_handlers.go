@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// handleGetWebhookSecret shows the state of a workspace's webhook secret.
+// @Summary Get webhook secret state
+// @Description Show whether the workspace has its own webhook signing secret and whether a rotation is in progress. The secret itself is only returned when it is issued.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.WebhookSecretInfo
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/webhook-secret [get]
+func (h *Handler) handleGetWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	secret, err := h.webhookService.GetWebhookSecret(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.ToWebhookSecretInfo(secret, time.Now()))
+}
+
+// handleRotateWebhookSecret issues a new webhook secret for a workspace.
+// @Summary Rotate webhook secret
+// @Description Issue a new secret signing the workspace's webhook deliveries, replacing the server-wide secret for this workspace. The new secret is returned only once. During the overlap (default 24h, up to 30 days) deliveries carry signatures under both the previous and the new secret, so receivers can switch without rejecting deliveries. Complete the rotation to stop signing with the previous secret early. overlap_minutes=0 replaces the secret at once. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.RotateWebhookSecretRequest false "Rotation request"
+// @Success 200 {object} dto.RotateWebhookSecretResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Rotation in progress or workspace archived"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/webhook-secret/rotate [post]
+func (h *Handler) handleRotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.RotateWebhookSecretRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	overlap := domain.DefaultWebhookRotationOverlap
+	if req.OverlapMinutes != nil {
+		overlap = time.Duration(*req.OverlapMinutes) * time.Minute
+	}
+
+	secret, err := h.webhookService.RotateWebhookSecret(ctx, workspaceID, overlap)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditWebhookRotated, &workspaceID, map[string]any{
+		"previous_expires_at": secret.PreviousExpiresAt,
+	})
+
+	respondJSON(w, http.StatusOK, dto.RotateWebhookSecretResponse{
+		Secret:        secret.Secret,
+		WebhookSecret: dto.ToWebhookSecretInfo(secret, time.Now()),
+	})
+}
+
+// handleCompleteWebhookRotation stops signing with the previous webhook secret.
+// @Summary Complete webhook secret rotation
+// @Description End the overlap of a rotation: deliveries are signed with the new secret only. Call it once every receiver verifies with the new secret.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Success 200 {object} dto.WebhookSecretInfo
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "No rotation in progress"
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/webhook-secret/complete [post]
+func (h *Handler) handleCompleteWebhookRotation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	secret, err := h.webhookService.CompleteWebhookSecretRotation(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	h.recordAudit(ctx, domain.AuditWebhookCompleted, &workspaceID, nil)
+
+	respondJSON(w, http.StatusOK, dto.ToWebhookSecretInfo(secret, time.Now()))
+}
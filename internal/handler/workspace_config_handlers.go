@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// maxConfigBodyBytes bounds the body of PUT /admin/workspaces/:workspace_id/config.
+const maxConfigBodyBytes = 1 << 20
+
+// handleExportWorkspaceConfig exports the configuration of a workspace.
+// @Summary Export workspace configuration
+// @Description Export the workspace's configuration without its tasks, agents or events: settings (status deadlines, auto-assignment, priority inheritance, agent staleness, DONE validation webhook, priority aging, deadline warning, deadline expiry, max attempts, claim fairness, event webhook, event broker topic), labels, queues, escalation routes, schedules with their task templates, and reports. Agents are referred to by name, so the document can be imported into another deployment. Lists are sorted by name (escalation routes keep their evaluation order) so the same configuration always exports the same document.
+// @Tags admin
+// @Produce json
+// @Produce application/yaml
+// @Param workspace_id path string true "Workspace ID"
+// @Param format query string false "Output format: json (default), yaml"
+// @Success 200 {object} dto.WorkspaceConfigDocument
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/config [get]
+func (h *Handler) handleExportWorkspaceConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = dto.ConfigFormatJSON
+	}
+	if format != dto.ConfigFormatJSON && format != dto.ConfigFormatYAML {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "format must be json or yaml")
+		return
+	}
+
+	workspace, cfg, err := h.configService.ExportConfig(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if format == dto.ConfigFormatJSON {
+		respondJSON(w, http.StatusOK, dto.ToWorkspaceConfigDocument(workspace, cfg))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	if err := dto.WriteWorkspaceConfig(w, dto.ToWorkspaceConfigDocument(workspace, cfg), format); err != nil {
+		slog.Error("failed to write workspace configuration", "workspace_id", workspaceID, "error", err)
+	}
+}
+
+// handleImportWorkspaceConfig applies a configuration document to a workspace.
+// @Summary Import workspace configuration
+// @Description Apply a document exported by GET /admin/workspaces/{workspace_id}/config, from this or another deployment. Sections left out of the document are not touched; within settings, omitted values take their defaults. Labels, queues, schedules and reports are matched by name, created or updated, and kept when missing from the document; escalation routes are replaced as a whole. Agent names must belong to agents of the workspace and are checked before anything changes; other errors stop the import at the failing item, and importing the same document again is safe. The response counts what was created, updated and left unchanged.
+// @Tags admin
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param format query string false "json or yaml; defaults to the Content-Type"
+// @Param request body dto.WorkspaceConfigDocument true "Configuration"
+// @Success 200 {object} dto.ConfigImportResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/config [put]
+func (h *Handler) handleImportWorkspaceConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = configFormatFromContentType(r.Header.Get("Content-Type"))
+	}
+	if format != dto.ConfigFormatJSON && format != dto.ConfigFormatYAML {
+		respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "format must be json or yaml")
+		return
+	}
+
+	doc, err := dto.ReadWorkspaceConfig(http.MaxBytesReader(w, r.Body, maxConfigBodyBytes), format)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "configuration exceeds 1 MiB")
+			return
+		}
+		respondError(w, http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+		return
+	}
+
+	result, err := h.configService.ImportConfig(ctx, workspaceID, doc.ToDomain())
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.ToConfigImportResponse(workspaceID, result)
+	h.recordAudit(ctx, domain.AuditConfigImported, &workspaceID, map[string]any{
+		"format":            format,
+		"source_workspace":  doc.Workspace,
+		"settings_changed":  response.SettingsChanged,
+		"labels":            response.Labels,
+		"queues":            response.Queues,
+		"escalation_routes": response.EscalationRoutes,
+		"schedules":         response.Schedules,
+		"reports":           response.Reports,
+	})
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// configFormatFromContentType picks the configuration format for a request without ?format.
+func configFormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return dto.ConfigFormatJSON
+	}
+
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return dto.ConfigFormatYAML
+	default:
+		return dto.ConfigFormatJSON
+	}
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/service"
+)
+
+// handleListWorkspaces lists every workspace with its current task counts.
+// @Summary List workspaces
+// @Description Consolidated view across workspaces: every workspace, archived ones and sandboxes included, with its current tasks by status and overdue count. Use a slug or ID from the list to select the workspace when reading tasks or stats with the admin token.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.WorkspacesResponse
+// @Security BearerAuth
+// @Router /admin/workspaces [get]
+func (h *Handler) handleListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaces, err := h.workspaceRepo.List(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list workspaces")
+		return
+	}
+
+	counts, err := h.taskRepo.CountTasksByWorkspace(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to count tasks")
+		return
+	}
+
+	response := dto.WorkspacesResponse{Workspaces: make([]dto.WorkspaceOverview, len(workspaces))}
+	for i, workspace := range workspaces {
+		overview := dto.WorkspaceOverview{
+			ID:            workspace.ID,
+			Name:          workspace.Name,
+			Slug:          workspace.Slug,
+			ArchivedAt:    workspace.ArchivedAt,
+			ExpiresAt:     workspace.ExpiresAt,
+			TasksByStatus: map[string]int{},
+			CreatedAt:     workspace.CreatedAt,
+		}
+		if workspaceCounts, ok := counts[workspace.ID]; ok {
+			overview.TasksByStatus = workspaceCounts.TasksByStatus
+			overview.OverdueCount = workspaceCounts.OverdueCount
+		}
+		response.Workspaces[i] = overview
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleArchiveWorkspace freezes a workspace.
+// @Summary Archive workspace
+// @Description Freeze a workspace: its agents are deactivated and their tokens replaced, read tokens are revoked, schedules and reports are disabled, and deadline checks and auto-assignment skip it. The data stays available to the admin export. Archiving is the first step of deleting a workspace. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.ArchiveWorkspaceRequest false "Archive request"
+// @Success 200 {object} dto.ArchiveWorkspaceResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Already archived"
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/archive [post]
+func (h *Handler) handleArchiveWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.ArchiveWorkspaceRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	result, err := h.workspaceService.ArchiveWorkspace(ctx, workspaceID, req.Reason)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	h.authMiddleware.InvalidateWorkspace(ctx, workspaceID)
+
+	respondJSON(w, http.StatusOK, dto.ArchiveWorkspaceResponse{
+		WorkspaceID:       result.Workspace.ID,
+		Slug:              result.Workspace.Slug,
+		ArchivedAt:        *result.Workspace.ArchivedAt,
+		AgentsRevoked:     result.AgentsRevoked,
+		ReadTokensRevoked: result.ReadTokensRevoked,
+		SchedulesDisabled: result.SchedulesDisabled,
+		ReportsDisabled:   result.ReportsDisabled,
+	})
+}
+
+// handleDeleteWorkspace permanently deletes a workspace.
+// @Summary Delete workspace
+// @Description Permanently delete a workspace with its agents, tasks, events, queues, labels, schedules, reports and read tokens. confirm must equal the workspace slug. A live workspace is archived first, which revokes its tokens. Unless skip_export=true, deletion requires an export (GET /admin/workspaces/{workspace_id}/export) taken after archiving, so the first call on a live workspace archives it and returns 409 EXPORT_REQUIRED. Tasks are deleted in batches of separate transactions; retry an interrupted deletion to resume it.
+// @Tags admin
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param confirm query string true "Workspace slug"
+// @Param skip_export query bool false "Delete without a prior export"
+// @Success 200 {object} dto.DeleteWorkspaceResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Export required"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id} [delete]
+func (h *Handler) handleDeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	result, err := h.workspaceService.DeleteWorkspace(ctx, service.DeleteWorkspaceParams{
+		WorkspaceID: workspaceID,
+		Confirm:     query.Get("confirm"),
+		SkipExport:  query.Get("skip_export") == "true",
+	})
+	// A live workspace is archived, revoking its agents, even when the export is still missing
+	h.authMiddleware.InvalidateWorkspace(ctx, workspaceID)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.DeleteWorkspaceResponse{
+		WorkspaceID:  result.Workspace.ID,
+		Slug:         result.Workspace.Slug,
+		TasksDeleted: result.TasksDeleted,
+		Batches:      result.Batches,
+		ExportedAt:   result.ExportedAt,
+	})
+}
+
+// handleCreateSandbox clones a workspace into an expiring sandbox.
+// @Summary Create sandbox
+// @Description Clone the workspace's settings, queues, labels, agents and open tasks (not DONE, CANCELLED, archived or deleted) into a new sandbox workspace, for testing new agent versions against realistic data. Cloned tasks keep status, priority, assignee and blockers; blockers that were not cloned are dropped. Every agent gets a fresh token, returned only here. The sandbox expires after ttl and is then deleted by the purge job. Archived workspaces and sandboxes cannot be cloned. The body is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param workspace_id path string true "Workspace ID"
+// @Param request body dto.CreateSandboxRequest false "Sandbox request"
+// @Success 201 {object} dto.CreateSandboxResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "Slug in use or workspace archived"
+// @Failure 422 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/workspaces/{workspace_id}/sandbox [post]
+func (h *Handler) handleCreateSandbox(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceID, ok := extractPathUUID(w, r, "workspace_id", "workspace_id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSandboxRequest
+	if !h.decodeOptionalJSON(w, r, &req) {
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "ttl must be a duration such as 72h")
+			return
+		}
+	}
+
+	result, err := h.workspaceService.CreateSandbox(ctx, service.CreateSandboxParams{
+		SourceID: workspaceID,
+		Name:     req.Name,
+		Slug:     req.Slug,
+		TTL:      ttl,
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	response := dto.CreateSandboxResponse{
+		WorkspaceID:  result.Workspace.ID,
+		Name:         result.Workspace.Name,
+		Slug:         result.Workspace.Slug,
+		SandboxOf:    workspaceID,
+		ExpiresAt:    *result.Workspace.ExpiresAt,
+		Agents:       make([]dto.SandboxAgentInfo, len(result.Agents)),
+		TasksCloned:  result.Clone.Tasks,
+		QueuesCloned: result.Clone.Queues,
+		LabelsCloned: result.Clone.Labels,
+	}
+	for i, agent := range result.Agents {
+		capabilities := agent.Capabilities
+		if capabilities == nil {
+			capabilities = []string{}
+		}
+		response.Agents[i] = dto.SandboxAgentInfo{
+			ID:           agent.ID,
+			Name:         agent.Name,
+			Token:        agent.Token,
+			IsActive:     agent.IsActive,
+			Capabilities: capabilities,
+		}
+	}
+
+	respondJSON(w, http.StatusCreated, response)
+}
+
+// handleListAudit lists admin audit log entries.
+// @Summary List audit log
+// @Description List operator actions newest first: read token changes, capability and auto-assign changes, task deletions, exports, sandbox creation, archiving and deletion of workspaces. Entries of deleted workspaces are kept.
+// @Tags admin
+// @Produce json
+// @Param workspace_id query string false "Filter by workspace UUID"
+// @Param action query string false "Filter by action, e.g. workspace.deleted"
+// @Param limit query int false "Page size (1-500, default 100)"
+// @Success 200 {object} dto.AuditLogResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/audit [get]
+func (h *Handler) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filters := repository.AuditFilters{Limit: 100}
+	if workspaceID := query.Get("workspace_id"); workspaceID != "" {
+		if _, err := uuid.Parse(workspaceID); err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_REQUEST", "workspace_id must be a valid UUID")
+			return
+		}
+		filters.WorkspaceID = &workspaceID
+	}
+	if action := query.Get("action"); action != "" {
+		auditAction := domain.AuditAction(action)
+		filters.Action = &auditAction
+	}
+	if limitParam := query.Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 && n <= 500 {
+			filters.Limit = n
+		}
+	}
+
+	entries, err := h.auditRepo.List(ctx, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch audit log")
+		return
+	}
+
+	response := dto.AuditLogResponse{Entries: make([]dto.AuditEntryResponse, len(entries))}
+	for i, entry := range entries {
+		response.Entries[i] = dto.ToAuditEntryResponse(entry)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
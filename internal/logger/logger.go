@@ -4,20 +4,37 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
 
+// level is the minimum level of the global logger. It is shared by every
+// Setup call so SetLevel can change it while the application runs.
+var level = new(slog.LevelVar)
+
 // Setup initializes the global slog logger with JSON output and source location.
 // Source location tracking helps identify exactly where log entries originated.
-func Setup(level slog.Level) {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+func Setup(minLevel slog.Level) {
+	SetupWriter(os.Stdout, minLevel)
+}
+
+// SetupWriter is Setup with logs written to w instead of stdout. Commands that
+// write their own output to stdout log to stderr this way.
+func SetupWriter(w io.Writer, minLevel slog.Level) {
+	level.Set(minLevel)
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
 		AddSource: true,
 		Level:     level,
 	})
 	slog.SetDefault(slog.New(handler))
 }
 
+// SetLevel changes the minimum level of the global logger in place.
+func SetLevel(minLevel slog.Level) {
+	level.Set(minLevel)
+}
+
 // ParseLevel converts a string log level to slog.Level.
 // Valid values: "debug", "info", "warn", "error".
 // Unrecognized values default to info level.
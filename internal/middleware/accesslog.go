@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLog logs one line per request: method, path, status, response size,
+// duration and the client IP, as resolved by TrustedProxies when the request
+// passed through it first.
+type AccessLog struct {
+	logger *slog.Logger
+}
+
+// NewAccessLog creates an AccessLog writing to logger, or to the default
+// logger when it is nil.
+func NewAccessLog(logger *slog.Logger) *AccessLog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AccessLog{logger: logger}
+}
+
+// Handle logs each request once next has answered it.
+func (a *AccessLog) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessLogWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		a.logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", ClientIP(r)),
+		)
+	})
+}
+
+// accessLogWriter passes a response on while counting its status and size.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (rw *accessLogWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *accessLogWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *accessLogWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,40 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog_LogsResolvedClientIP(t *testing.T) {
+	proxies, err := middleware.NewTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	accessLog := middleware.NewAccessLog(slog.New(slog.NewJSONHandler(&out, nil)))
+	handler := proxies.Resolve(accessLog.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks?view=mine", nil)
+	req.RemoteAddr = "10.1.2.3:4000"
+	req.Header.Set(middleware.HeaderForwardedFor, "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "request", line["msg"])
+	assert.Equal(t, "POST", line["method"])
+	assert.Equal(t, "/api/v1/tasks", line["path"])
+	assert.EqualValues(t, http.StatusCreated, line["status"])
+	assert.EqualValues(t, 10, line["bytes"])
+	assert.Equal(t, "203.0.113.7", line["client_ip"], "the client behind the trusted proxy, not the proxy")
+}
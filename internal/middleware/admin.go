@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminMiddleware guards operator endpoints with a static admin token.
+type AdminMiddleware struct {
+	adminToken string
+}
+
+// NewAdminMiddleware creates a new AdminMiddleware.
+// An empty adminToken disables the admin API entirely.
+func NewAdminMiddleware(adminToken string) *AdminMiddleware {
+	return &AdminMiddleware{adminToken: adminToken}
+}
+
+// RequireAdmin validates that the Bearer token equals the configured admin token.
+func (m *AdminMiddleware) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.adminToken == "" {
+			http.Error(w, "admin API disabled", http.StatusNotFound)
+			return
+		}
+
+		token, ok := parseBearerToken(r.Header.Get("Authorization"))
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(m.adminToken)) != 1 {
+			rejectUnauthorized(w, r, "invalid admin token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
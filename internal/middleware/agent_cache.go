@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// maxAgentCacheEntries bounds the number of cached tokens before expired
+// entries are swept.
+const maxAgentCacheEntries = 10000
+
+// agentCache keeps the agents of recently used tokens for a short time, so
+// polling agents don't hit the agents table on every request. Entries live in
+// one server instance; without a shared backend, changes made through another
+// instance or the CLI show up once the entry expires.
+type agentCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]agentCacheEntry // by token
+}
+
+// agentCacheEntry is the agent of one token and when it must be looked up again.
+type agentCacheEntry struct {
+	agent     *domain.Agent
+	expiresAt time.Time
+}
+
+// newAgentCache creates a cache keeping agents for ttl, or nil when ttl is not
+// positive. A nil cache caches nothing.
+func newAgentCache(ttl time.Duration) *agentCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &agentCache{ttl: ttl, entries: make(map[string]agentCacheEntry)}
+}
+
+// get returns a copy of the cached agent of token, so requests can't change
+// each other's agent.
+func (c *agentCache) get(token string, now time.Time) (*domain.Agent, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[token]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return copyAgent(entry.agent), true
+}
+
+// put caches the agent of token. When the cache is full of unexpired entries
+// the agent is not cached.
+func (c *agentCache) put(token string, agent *domain.Agent, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxAgentCacheEntries {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxAgentCacheEntries {
+			return
+		}
+	}
+
+	c.entries[token] = agentCacheEntry{agent: copyAgent(agent), expiresAt: now.Add(c.ttl)}
+}
+
+// evict drops the cached entries whose agent matches.
+func (c *agentCache) evict(matches func(*domain.Agent) bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if matches(entry.agent) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// copyAgent returns a copy of agent that shares no slices with it.
+func copyAgent(agent *domain.Agent) *domain.Agent {
+	copied := *agent
+	copied.Capabilities = slices.Clone(agent.Capabilities)
+	return &copied
+}
@@ -2,30 +2,131 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/coordination"
 	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/repository"
 )
 
+// HeaderWorkspace selects the workspace a coordinator request reads.
+// The workspace query parameter does the same.
+const HeaderWorkspace = "X-Sloptask-Workspace"
+
 type contextKey string
 
 const (
 	// ContextKeyAgent is the key for storing agent in request context.
 	ContextKeyAgent contextKey = "agent"
+
+	// ContextKeyReadToken is the key for storing a read token in request context.
+	ContextKeyReadToken contextKey = "read_token"
+
+	// ContextKeyCoordinatorWorkspace is the key for storing the workspace an
+	// admin token selected in request context.
+	ContextKeyCoordinatorWorkspace contextKey = "coordinator_workspace"
 )
 
 // AuthMiddleware handles Bearer token authentication.
 type AuthMiddleware struct {
-	agentRepo *repository.AgentRepository
+	agentRepo     repository.AgentRepository
+	readTokenRepo *repository.ReadTokenRepository
+	workspaceRepo repository.WorkspaceRepository
+	adminToken    string
+	agents        *agentCache
+	shared        coordination.Backend
+}
+
+// agentCacheChannel carries agent cache invalidations between server replicas:
+// "agent:<id>" or "workspace:<id>".
+const agentCacheChannel = "agent-cache"
+
+// NewAuthMiddleware creates a new AuthMiddleware. A non-empty adminToken is
+// accepted by AuthenticateReader for any workspace the request selects. Agent
+// tokens are looked up once per agentCacheTTL; zero looks them up on every
+// request. With a shared backend, invalidations reach the caches of every
+// replica.
+func NewAuthMiddleware(agentRepo repository.AgentRepository, readTokenRepo *repository.ReadTokenRepository,
+	workspaceRepo repository.WorkspaceRepository, adminToken string, agentCacheTTL time.Duration,
+	shared coordination.Backend) *AuthMiddleware {
+	m := &AuthMiddleware{
+		agentRepo:     agentRepo,
+		readTokenRepo: readTokenRepo,
+		workspaceRepo: workspaceRepo,
+		adminToken:    adminToken,
+		agents:        newAgentCache(agentCacheTTL),
+		shared:        shared,
+	}
+	if m.agents != nil && shared != nil {
+		go m.followInvalidations(shared.Subscribe(agentCacheChannel))
+	}
+	return m
+}
+
+// InvalidateAgent makes the next request of an agent look it up again, after
+// its capabilities, activity or token changed.
+func (m *AuthMiddleware) InvalidateAgent(ctx context.Context, agentID string) {
+	m.invalidate(ctx, "agent:"+agentID)
+}
+
+// RefreshAgent makes the next request of an agent to this server look it up
+// again, after a heartbeat moved its last_seen_at. Unlike InvalidateAgent it is
+// not broadcast: the other replicas may report the previous last_seen_at until
+// their cache entry expires, which is not worth a message per heartbeat.
+func (m *AuthMiddleware) RefreshAgent(agentID string) {
+	m.evict("agent:" + agentID)
 }
 
-// NewAuthMiddleware creates a new AuthMiddleware.
-func NewAuthMiddleware(agentRepo *repository.AgentRepository) *AuthMiddleware {
-	return &AuthMiddleware{agentRepo: agentRepo}
+// InvalidateWorkspace makes the next request of every agent of a workspace look
+// it up again, after the workspace revoked or deleted its agents.
+func (m *AuthMiddleware) InvalidateWorkspace(ctx context.Context, workspaceID string) {
+	m.invalidate(ctx, "workspace:"+workspaceID)
+}
+
+// PublishWorkspaceInvalidation tells every server sharing the backend to drop
+// the agents of a workspace from its cache, for processes without an
+// AuthMiddleware such as the CLI.
+func PublishWorkspaceInvalidation(ctx context.Context, shared coordination.Backend, workspaceID string) error {
+	return shared.Publish(ctx, agentCacheChannel, "workspace:"+workspaceID)
+}
+
+// invalidate evicts the agents a message names here and broadcasts it to the
+// other replicas. A failed broadcast leaves them to the cache expiry.
+func (m *AuthMiddleware) invalidate(ctx context.Context, message string) {
+	m.evict(message)
+	if m.shared == nil {
+		return
+	}
+	if err := m.shared.Publish(ctx, agentCacheChannel, message); err != nil {
+		slog.Warn("failed to broadcast agent cache invalidation", "message", message, "error", err)
+	}
+}
+
+// followInvalidations applies the invalidations broadcast by every replica
+// until the backend is closed.
+func (m *AuthMiddleware) followInvalidations(messages <-chan string) {
+	for message := range messages {
+		m.evict(message)
+	}
+}
+
+// evict drops the cached agents an invalidation message names.
+func (m *AuthMiddleware) evict(message string) {
+	kind, id, _ := strings.Cut(message, ":")
+	switch kind {
+	case "agent":
+		m.agents.evict(func(agent *domain.Agent) bool { return agent.ID == id })
+	case "workspace":
+		m.agents.evict(func(agent *domain.Agent) bool { return agent.WorkspaceID == id })
+	default:
+		slog.Warn("unknown agent cache invalidation", "message", message)
+	}
 }
 
 // Authenticate validates Bearer token and adds agent to request context.
@@ -33,26 +134,99 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token, ok := parseBearerToken(r.Header.Get("Authorization"))
 		if !ok {
-			http.Error(w, "invalid authorization header format", http.StatusUnauthorized)
+			rejectUnauthorized(w, r, "invalid authorization header format")
+			return
+		}
+
+		agent, ok := m.authenticateAgent(w, r, token)
+		if !ok {
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKeyAgent, agent)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AuthenticateReader accepts an agent token, a workspace read token or the admin
+// token. The admin token acts as a coordinator: it must select the workspace to
+// read with the workspace query parameter or the X-Sloptask-Workspace header.
+// Use it only for read-only endpoints that need nothing beyond the workspace.
+func (m *AuthMiddleware) AuthenticateReader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := parseBearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			rejectUnauthorized(w, r, "invalid authorization header format")
+			return
+		}
+
+		if m.isAdminToken(token) {
+			workspace, ok := m.selectWorkspace(w, r)
+			if !ok {
+				return
+			}
+			ctx := context.WithValue(r.Context(), ContextKeyCoordinatorWorkspace, workspace)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		if !strings.HasPrefix(token, domain.ReadTokenPrefix) {
+			agent, ok := m.authenticateAgent(w, r, token)
+			if !ok {
+				return
+			}
+			ctx := context.WithValue(r.Context(), ContextKeyAgent, agent)
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		agent, err := m.agentRepo.GetByToken(r.Context(), token)
+		readToken, err := m.readTokenRepo.GetByTokenHash(r.Context(), domain.HashReadToken(token))
 		if err != nil {
-			if errors.Is(err, domain.ErrAgentNotFound) {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+			if errors.Is(err, domain.ErrReadTokenNotFound) {
+				rejectUnauthorized(w, r, "invalid token")
 				return
 			}
-			slog.Error("failed to fetch agent by token",
+			slog.Error("failed to fetch read token",
 				"error", err,
-				"remote_addr", r.RemoteAddr,
+				"client_ip", ClientIP(r),
 			)
 			http.Error(w, "internal server error", http.StatusInternalServerError)
 			return
 		}
 
-		if !agent.IsActive {
-			http.Error(w, "agent inactive", http.StatusUnauthorized)
+		if !readToken.IsUsable(time.Now()) {
+			rejectUnauthorized(w, r, "read token expired or revoked")
+			return
+		}
+
+		if err := m.readTokenRepo.TouchLastUsed(r.Context(), readToken.ID); err != nil {
+			// Usage tracking is best-effort; never fail the read because of it
+			slog.Warn("failed to record read token usage", "read_token_id", readToken.ID, "error", err)
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKeyReadToken, readToken)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalAuthenticate adds the agent to the context when the request carries an
+// agent token and passes anonymous requests through. A token that is sent but
+// invalid is still rejected.
+func (m *AuthMiddleware) OptionalAuthenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := parseBearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			rejectUnauthorized(w, r, "invalid authorization header format")
+			return
+		}
+
+		agent, ok := m.authenticateAgent(w, r, token)
+		if !ok {
 			return
 		}
 
@@ -61,6 +235,78 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// authenticateAgent resolves an agent token and writes the error response on
+// failure. Only active agents are cached; unknown tokens are looked up every time.
+func (m *AuthMiddleware) authenticateAgent(w http.ResponseWriter, r *http.Request, token string) (*domain.Agent, bool) {
+	now := time.Now()
+	if agent, ok := m.agents.get(token, now); ok {
+		return agent, true
+	}
+
+	agent, err := m.agentRepo.GetByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, domain.ErrAgentNotFound) {
+			rejectUnauthorized(w, r, "invalid token")
+			return nil, false
+		}
+		slog.Error("failed to fetch agent by token",
+			"error", err,
+			"client_ip", ClientIP(r),
+		)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if !agent.IsActive {
+		rejectUnauthorized(w, r, "agent inactive")
+		return nil, false
+	}
+
+	m.agents.put(token, agent, now)
+
+	return agent, true
+}
+
+// isAdminToken reports whether token is the configured admin token.
+func (m *AuthMiddleware) isAdminToken(token string) bool {
+	return m.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(m.adminToken)) == 1
+}
+
+// selectWorkspace resolves the workspace a coordinator request selected by ID or
+// slug and writes the error response on failure.
+func (m *AuthMiddleware) selectWorkspace(w http.ResponseWriter, r *http.Request) (*domain.Workspace, bool) {
+	selector := strings.TrimSpace(r.Header.Get(HeaderWorkspace))
+	if selector == "" {
+		selector = strings.TrimSpace(r.URL.Query().Get("workspace"))
+	}
+	if selector == "" {
+		http.Error(w, "admin token requires a workspace query parameter or "+HeaderWorkspace+" header", http.StatusBadRequest)
+		return nil, false
+	}
+
+	var workspace *domain.Workspace
+	var err error
+	if _, parseErr := uuid.Parse(selector); parseErr == nil {
+		workspace, err = m.workspaceRepo.GetByID(r.Context(), selector)
+	} else {
+		workspace, err = m.workspaceRepo.GetBySlug(r.Context(), selector)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			http.Error(w, "workspace not found", http.StatusNotFound)
+			return nil, false
+		}
+		slog.Error("failed to fetch coordinator workspace",
+			"error", err,
+			"client_ip", ClientIP(r),
+		)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return workspace, true
+}
+
 // GetAgentFromContext retrieves the authenticated agent from request context.
 func GetAgentFromContext(ctx context.Context) (*domain.Agent, error) {
 	agent, ok := ctx.Value(ContextKeyAgent).(*domain.Agent)
@@ -70,6 +316,32 @@ func GetAgentFromContext(ctx context.Context) (*domain.Agent, error) {
 	return agent, nil
 }
 
+// GetWorkspaceIDFromContext returns the workspace of the authenticated agent or
+// read token, or the workspace a coordinator request selected.
+func GetWorkspaceIDFromContext(ctx context.Context) (string, error) {
+	if agent, err := GetAgentFromContext(ctx); err == nil {
+		return agent.WorkspaceID, nil
+	}
+	if readToken, ok := ctx.Value(ContextKeyReadToken).(*domain.ReadToken); ok && readToken != nil {
+		return readToken.WorkspaceID, nil
+	}
+	if workspace, ok := ctx.Value(ContextKeyCoordinatorWorkspace).(*domain.Workspace); ok && workspace != nil {
+		return workspace.ID, nil
+	}
+	return "", domain.ErrInvalidToken
+}
+
+// rejectUnauthorized answers 401 and logs the failure with the client IP, so
+// token guessing shows up in the logs.
+func rejectUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	slog.Warn("authentication failed",
+		"reason", message,
+		"client_ip", ClientIP(r),
+		"path", r.URL.Path,
+	)
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
 // parseBearerToken extracts the token from a "Bearer <token>" authorization header.
 // Returns the token and true if valid, or empty string and false otherwise.
 func parseBearerToken(header string) (string, bool) {
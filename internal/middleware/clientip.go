@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Headers proxies put the address of the client they forwarded for in.
+const (
+	HeaderForwardedFor = "X-Forwarded-For"
+	HeaderRealIP       = "X-Real-IP"
+)
+
+// contextKeyClientIP is the key for storing the resolved client IP in request
+// context.
+const contextKeyClientIP contextKey = "client_ip"
+
+// TrustedProxies resolves the client IP of requests that come in through
+// trusted proxies, such as a load balancer, from the headers those set.
+// Requests from anywhere else are attributed to the connection's peer, so
+// clients cannot pick their own IP by sending the headers.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// NewTrustedProxies parses the trusted proxy addresses and networks, e.g.
+// "10.0.0.0/8" or "192.0.2.10". Without any, the peer is always the client.
+func NewTrustedProxies(proxies []string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy network %q: %w", proxy, err)
+			}
+			t.prefixes = append(t.prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy address %q: %w", proxy, err)
+		}
+		t.prefixes = append(t.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return t, nil
+}
+
+// Resolve stores the client IP of each request in its context for ClientIP.
+func (t *TrustedProxies) Resolve(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), contextKeyClientIP, t.clientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientIP walks X-Forwarded-For from the nearest hop back while the hops are
+// trusted proxies; the first one that isn't is the client. X-Real-IP is used
+// when a trusted peer sends no X-Forwarded-For.
+func (t *TrustedProxies) clientIP(r *http.Request) string {
+	peer := peerIP(r)
+	if !t.trusted(peer) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values(HeaderForwardedFor) {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	if len(hops) == 0 {
+		if realIP := strings.TrimSpace(r.Header.Get(HeaderRealIP)); validIP(realIP) {
+			return realIP
+		}
+		return peer
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !validIP(hops[i]) {
+			// Garbage in the chain: the last hop we could trust is the client
+			break
+		}
+		client = hops[i]
+		if !t.trusted(client) {
+			break
+		}
+	}
+	return client
+}
+
+// trusted reports whether ip belongs to a trusted proxy.
+func (t *TrustedProxies) trusted(ip string) bool {
+	if len(t.prefixes) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client a request came from: as resolved by
+// TrustedProxies when the request passed through it, else the connection's peer.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(contextKeyClientIP).(string); ok {
+		return ip
+	}
+	return peerIP(r)
+}
+
+// peerIP returns the IP of the connection a request came in on.
+func peerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// validIP reports whether s is an IP address.
+func validIP(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
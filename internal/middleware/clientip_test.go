@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := middleware.NewTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	require.NoError(t, err)
+
+	resolve := func(remoteAddr string, headers map[string]string) string {
+		var ip string
+		handler := proxies.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip = middleware.ClientIP(r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return ip
+	}
+
+	assert.Equal(t, "203.0.113.7", resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "203.0.113.7"}))
+	assert.Equal(t, "203.0.113.7", resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 192.0.2.10"}),
+		"hops are trusted from the nearest back to the first untrusted one")
+	assert.Equal(t, "203.0.113.7", resolve("192.0.2.10:4000", map[string]string{"X-Real-IP": "203.0.113.7"}))
+	assert.Equal(t, "10.1.2.3", resolve("10.1.2.3:4000", map[string]string{"X-Forwarded-For": "not-an-ip"}))
+	assert.Equal(t, "198.51.100.1", resolve("198.51.100.1:4000", map[string]string{"X-Forwarded-For": "203.0.113.7"}),
+		"headers from untrusted peers are ignored")
+
+	_, err = middleware.NewTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func TestRateLimiter_PerClientBehindProxy(t *testing.T) {
+	proxies, err := middleware.NewTrustedProxies([]string{"10.0.0.1"})
+	require.NoError(t, err)
+	limiter := middleware.NewRateLimiter("intake", 1, time.Hour, nil)
+	handler := proxies.Resolve(limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	submit := func(client string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/intake/demo", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, submit("203.0.113.7"))
+	assert.Equal(t, http.StatusOK, submit("203.0.113.8"), "clients behind one load balancer have their own limits")
+	assert.Equal(t, http.StatusTooManyRequests, submit("203.0.113.7"))
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressMinSize is the smallest response worth compressing: below it
+// the gzip framing costs about as much as it saves.
+const DefaultCompressMinSize = 1024
+
+// Compress gzips responses for clients that accept it, such as the task lists
+// and statistics large workspaces return on every poll. Small responses and
+// content that is not text are sent as they are.
+type Compress struct {
+	minSize int
+	writers sync.Pool
+}
+
+// NewCompress creates a Compress for responses of at least minSize bytes,
+// DefaultCompressMinSize when zero.
+func NewCompress(minSize int) *Compress {
+	if minSize <= 0 {
+		minSize = DefaultCompressMinSize
+	}
+	return &Compress{
+		minSize: minSize,
+		writers: sync.Pool{New: func() any {
+			// The default level; the best ones cost a lot of CPU for little gain on JSON
+			return gzip.NewWriter(nil)
+		}},
+	}
+}
+
+// Handle compresses the responses of next.
+func (c *Compress) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, compress: c, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter holds the start of a response back until it knows whether the
+// response is large enough to compress.
+type compressWriter struct {
+	http.ResponseWriter
+	compress *Compress
+
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer
+}
+
+// Unwrap gives http.ResponseController the underlying writer, so handlers can
+// still extend their write deadline or flush through it.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started {
+		return
+	}
+	cw.status = status
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.started {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.compress.minSize {
+			return len(p), nil
+		}
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// start sends the header and what was held back, compressed if the response
+// may be.
+func (cw *compressWriter) start(large bool) error {
+	cw.started = true
+	header := cw.Header()
+	if large && compressible(cw.status, header) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		cw.gz = cw.compress.writers.Get().(*gzip.Writer)
+		cw.gz.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.gz != nil {
+		_, err = cw.gz.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends a response too small to compress, or finishes the gzip stream.
+func (cw *compressWriter) close() {
+	if !cw.started {
+		// A write error here means the client went away; nothing to answer
+		_ = cw.start(false)
+	}
+	if cw.gz != nil {
+		_ = cw.gz.Close()
+		cw.compress.writers.Put(cw.gz)
+		cw.gz = nil
+	}
+}
+
+// compressible reports whether a response with status and header may be
+// compressed: a text body the handler didn't encode itself.
+func compressible(status int, header http.Header) bool {
+	if status == http.StatusNoContent || status == http.StatusNotModified || status < http.StatusOK {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/x-ndjson" ||
+		mediaType == "application/yaml" ||
+		mediaType == "application/openmetrics-text"
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		// gzip;q=0 refuses it
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
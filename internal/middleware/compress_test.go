@@ -0,0 +1,90 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress_LargeResponses(t *testing.T) {
+	large := `{"tasks": [` + strings.Repeat(`{"title": "Fix the flaky CI job"},`, 100) + `{}]}`
+	handler := middleware.NewCompress(0).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/small" {
+			_, _ = io.WriteString(w, `{"ok": true}`)
+			return
+		}
+		// Written in pieces, as json.Encoder may do
+		_, _ = io.WriteString(w, large[:100])
+		_, _ = io.WriteString(w, large[100:])
+	}))
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/tasks", "br, gzip")
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.Less(t, rec.Body.Len(), len(large))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	rec = serve("/small", "gzip")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"), "small responses are not worth it")
+	assert.Equal(t, `{"ok": true}`, rec.Body.String())
+
+	rec = serve("/tasks", "")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, rec.Body.String())
+
+	rec = serve("/tasks", "gzip;q=0, identity")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+}
+
+func TestCompress_LongPollExtendsWriteDeadline(t *testing.T) {
+	large := strings.Repeat(`{"title": "Fix the flaky CI job"},`, 100)
+	server := httptest.NewUnstartedServer(middleware.NewCompress(0).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Like GET /tasks/wait: outlive the server's write timeout
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Second)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, large)
+	})))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/tasks/wait", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err, "the response arrives after the server's write timeout")
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	reader, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(body))
+}
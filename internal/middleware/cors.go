@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsMaxAge is how long browsers may cache a preflight response.
+const corsMaxAge = 10 * time.Minute
+
+// Defaults of CORS for the methods and request headers the API uses.
+var (
+	DefaultCORSMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+	DefaultCORSHeaders = []string{
+		"Authorization", "Content-Type", "If-Match", "If-None-Match", HeaderWorkspace, "X-Sloptask-Intake-Key",
+		HeaderTraceParent, HeaderTraceState, HeaderIdempotencyKey,
+	}
+)
+
+// corsExposedHeaders are the response headers browser clients may read.
+var corsExposedHeaders = []string{"Retry-After", "ETag", "X-Sloptask-Task-Version", HeaderIdempotentReplayed}
+
+// CORS lets browsers on the allowed origins call the API. Tokens travel in the
+// Authorization header rather than cookies, so credentials are not allowed.
+type CORS struct {
+	origins []string // "*" allows every origin
+	methods string
+	headers string
+}
+
+// NewCORS creates a CORS allowing origins, e.g. "https://dashboard.example.com"
+// or "*", to call the API with methods and request headers, DefaultCORSMethods
+// and DefaultCORSHeaders when empty. Without origins it adds no headers and
+// browsers keep blocking cross-origin calls.
+func NewCORS(origins, methods, headers []string) *CORS {
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	normalized := make([]string, len(origins))
+	for i, origin := range origins {
+		normalized[i] = strings.TrimSuffix(strings.ToLower(origin), "/")
+	}
+	return &CORS{
+		origins: normalized,
+		methods: strings.Join(methods, ", "),
+		headers: strings.Join(headers, ", "),
+	}
+}
+
+// Handle adds the CORS headers to responses for allowed origins and answers
+// their preflight requests itself, before routing, which has no OPTIONS routes.
+func (c *CORS) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || len(c.origins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", c.methods)
+			w.Header().Set("Access-Control-Allow-Headers", c.headers)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowed reports whether browsers on origin may call the API.
+func (c *CORS) allowed(origin string) bool {
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	return slices.ContainsFunc(c.origins, func(allowed string) bool {
+		return allowed == "*" || allowed == origin
+	})
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS_AllowedOrigins(t *testing.T) {
+	reached := false
+	handler := middleware.NewCORS([]string{"https://dashboard.example.com"}, nil, nil).
+		Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+		}))
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		reached = false
+		req := httptest.NewRequest(method, "/api/v1/tasks", nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodOptions, "https://dashboard.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.False(t, reached, "preflight is answered before routing")
+	assert.Equal(t, "https://dashboard.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+
+	rec = request(http.MethodGet, "https://dashboard.example.com")
+	assert.True(t, reached)
+	assert.Equal(t, "https://dashboard.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Retry-After, ETag, X-Sloptask-Task-Version, Idempotent-Replayed", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	rec = request(http.MethodGet, "https://evil.example.com")
+	assert.True(t, reached, "the browser, not the server, blocks other origins")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+
+	// Without origins nothing changes
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	req.Header.Set("Origin", "https://dashboard.example.com")
+	middleware.NewCORS(nil, nil, nil).Handle(http.NotFoundHandler()).ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// drainRetryAfter is the Retry-After sent with writes rejected while draining:
+// by then another instance, or this one restarted, takes them.
+const drainRetryAfter = 5 * time.Second
+
+// Drain tracks in-flight write requests, so shutdown can wait for their
+// transactions to finish before the database pool closes. Once draining
+// starts, new writes are rejected with 503; reads keep being served.
+type Drain struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{} // closed when draining and nothing is in flight
+}
+
+// NewDrain creates a Drain that is not draining.
+func NewDrain() *Drain {
+	return &Drain{idle: make(chan struct{})}
+}
+
+// Track counts the write requests (anything but GET, HEAD and OPTIONS) passing
+// through it, or rejects them while draining.
+func (d *Drain) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !d.enter() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(drainRetryAfter.Seconds())))
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		defer d.leave()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Draining reports whether Start was called.
+func (d *Drain) Draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// Start rejects new writes from now on.
+func (d *Drain) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return
+	}
+	d.draining = true
+	if d.inFlight == 0 {
+		close(d.idle)
+	}
+}
+
+// Wait starts draining and returns once no write is in flight, or with the
+// number still in flight when ctx is done first.
+func (d *Drain) Wait(ctx context.Context) (int, error) {
+	d.Start()
+	select {
+	case <-d.idle:
+		return 0, nil
+	case <-ctx.Done():
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.inFlight, ctx.Err()
+	}
+}
+
+// enter counts a write in, unless draining.
+func (d *Drain) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inFlight++
+	return true
+}
+
+// leave counts a write out.
+func (d *Drain) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inFlight--
+	if d.draining && d.inFlight == 0 {
+		close(d.idle)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain_WaitsForInFlightWrites(t *testing.T) {
+	drain := middleware.NewDrain()
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := drain.Track(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	done := make(chan int, 1)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil))
+		done <- rec.Code
+	}()
+	<-entered
+
+	// The write in flight keeps Wait from returning before its deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	pending, err := drain.Wait(ctx)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, pending)
+	assert.True(t, drain.Draining())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/1", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "new writes are rejected while draining")
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "reads are still served while draining")
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	pending, err = drain.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
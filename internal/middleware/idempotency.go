@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/coordination"
+)
+
+// HeaderIdempotencyKey carries the client's key for retrying a write safely.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed marks a response replayed for a repeated key.
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+const (
+	// maxIdempotencyKeyLength bounds the keys clients may send
+	maxIdempotencyKeyLength = 255
+	// idempotencyKeyTTL is how long a response is replayed for its key
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyPendingTTL releases the key of a request whose server died
+	// before it answered
+	idempotencyPendingTTL = 5 * time.Minute
+	// maxIdempotencyKeys bounds the keys kept in memory before expired ones are
+	// swept
+	maxIdempotencyKeys = 10000
+)
+
+// idempotentHeaders are the response headers replayed with the body.
+var idempotentHeaders = []string{"Content-Type", "Location", "ETag", "X-Sloptask-Task-Version"}
+
+// idempotentRecord is what is kept for a key: the request it was first used
+// with and, once answered, the response.
+type idempotentRecord struct {
+	Request string            `json:"request"`
+	Pending bool              `json:"pending,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Header  map[string]string `json:"header,omitempty"`
+	Body    []byte            `json:"body,omitempty"`
+}
+
+// Idempotency lets clients retry writes safely: a write sent with an
+// Idempotency-Key header is executed once, and repeating it with the same key
+// replays the first response instead of writing again. Keys are scoped to the
+// caller's credentials, method and path, and kept for 24 hours. Responses are
+// kept in the shared backend, so a retry may reach any replica, or in memory
+// without one.
+type Idempotency struct {
+	shared  coordination.Backend
+	maxBody int64
+
+	mu      sync.Mutex
+	records map[string]idempotentEntry
+}
+
+// idempotentEntry is an encoded record kept in memory until it expires.
+type idempotentEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewIdempotency creates an Idempotency keeping responses in shared, which may
+// be nil. Requests with bodies larger than maxBody are passed on unguarded; the
+// handlers reject them anyway.
+func NewIdempotency(shared coordination.Backend, maxBody int64) *Idempotency {
+	return &Idempotency{shared: shared, maxBody: maxBody, records: make(map[string]idempotentEntry)}
+}
+
+// Handle executes writes carrying an Idempotency-Key once per key. A repeat
+// with the same request gets the first response with an Idempotent-Replayed
+// header; a repeat while the first is still running gets 409, and reusing the
+// key for a different request 422. Server errors release the key, so the
+// request can be retried.
+func (i *Idempotency) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get(HeaderIdempotencyKey)
+		if idempotencyKey == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, i.maxBody+1))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > i.maxBody {
+			r.Body = splicedBody{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			next.ServeHTTP(w, r)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		key := "idempotency:" + idempotencyDigest(r.Header.Get("Authorization"), r.Header.Get(HeaderWorkspace), r.Method, r.URL.RequestURI(), idempotencyKey)
+		request := idempotencyDigest(string(body))
+
+		record, reserved, err := i.reserve(ctx, key, idempotentRecord{Request: request, Pending: true})
+		if err != nil {
+			slog.Error("failed to reserve idempotency key", "error", err)
+			http.Error(w, "failed to check Idempotency-Key", http.StatusInternalServerError)
+			return
+		}
+		if !reserved {
+			switch {
+			case record.Request != request:
+				http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+			case record.Pending:
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "a request with this Idempotency-Key is still in progress", http.StatusConflict)
+			default:
+				for name, value := range record.Header {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set(HeaderIdempotentReplayed, "true")
+				w.WriteHeader(record.Status)
+				_, _ = w.Write(record.Body)
+			}
+			return
+		}
+
+		rec := &idempotentWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			// Answered or not, the key must not stay pending after a panic
+			if p := recover(); p != nil {
+				i.release(ctx, key)
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			i.release(ctx, key)
+			return
+		}
+		header := make(map[string]string)
+		for _, name := range idempotentHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				header[name] = value
+			}
+		}
+		i.store(ctx, key, idempotentRecord{Request: request, Status: rec.status, Header: header, Body: rec.body.Bytes()})
+	})
+}
+
+// reserve keeps record for key unless a record is kept for it already. Returns
+// the kept record and whether it is the given one.
+func (i *Idempotency) reserve(ctx context.Context, key string, record idempotentRecord) (idempotentRecord, bool, error) {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return record, false, err
+	}
+
+	var held string
+	var reserved bool
+	if i.shared != nil {
+		held, reserved, err = i.shared.Reserve(ctx, key, string(encoded), idempotencyPendingTTL)
+		if err != nil {
+			slog.Warn("shared idempotency keys unavailable, keeping them locally", "error", err)
+		}
+	}
+	if i.shared == nil || err != nil {
+		held, reserved = i.reserveLocal(key, string(encoded), time.Now())
+	}
+	if reserved {
+		return record, true, nil
+	}
+
+	var kept idempotentRecord
+	if err := json.Unmarshal([]byte(held), &kept); err != nil {
+		return record, false, err
+	}
+	return kept, false, nil
+}
+
+// store replaces the record of key with the answered one.
+func (i *Idempotency) store(ctx context.Context, key string, record idempotentRecord) {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		slog.Error("failed to encode idempotent response", "error", err)
+		i.release(ctx, key)
+		return
+	}
+
+	if i.shared != nil {
+		err := i.shared.Set(context.WithoutCancel(ctx), key, string(encoded), idempotencyKeyTTL)
+		if err == nil {
+			return
+		}
+		slog.Warn("shared idempotency keys unavailable, keeping the response locally", "error", err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.records[key] = idempotentEntry{value: string(encoded), expires: time.Now().Add(idempotencyKeyTTL)}
+}
+
+// release forgets key, so the request can be retried.
+func (i *Idempotency) release(ctx context.Context, key string) {
+	if i.shared != nil {
+		if err := i.shared.Delete(context.WithoutCancel(ctx), key); err != nil {
+			slog.Warn("failed to release shared idempotency key", "error", err)
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.records, key)
+}
+
+// reserveLocal is reserve in memory.
+func (i *Idempotency) reserveLocal(key, value string, now time.Time) (string, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if len(i.records) >= maxIdempotencyKeys {
+		for k, record := range i.records {
+			if !now.Before(record.expires) {
+				delete(i.records, k)
+			}
+		}
+	}
+
+	if record, ok := i.records[key]; ok && now.Before(record.expires) {
+		return record.value, false
+	}
+	i.records[key] = idempotentEntry{value: value, expires: now.Add(idempotencyPendingTTL)}
+	return value, true
+}
+
+// idempotencyDigest returns the hex SHA-256 of parts, each ended by a newline.
+func idempotencyDigest(parts ...string) string {
+	sum := sha256.New()
+	for _, part := range parts {
+		sum.Write([]byte(part))
+		sum.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// splicedBody reads a body partly read already and closes the original.
+type splicedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// idempotentWriter passes a response on while keeping its status and body.
+type idempotentWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *idempotentWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *idempotentWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *idempotentWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingHandler creates a task per request, numbering them, and fails with
+// 500 while failing is set.
+func countingHandler(created *atomic.Int32, failing *atomic.Bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "database unavailable", http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		n := created.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Only", "not replayed")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":` + strconv.Itoa(int(n)) + `,"body":` + string(body) + `}`))
+	})
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	var created atomic.Int32
+	var failing atomic.Bool
+	handler := NewIdempotency(nil, 1024).Handle(countingHandler(&created, &failing))
+	send := func(token, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("agent-1", "retry-1", `{"title":"a"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+
+	again := send("agent-1", "retry-1", `{"title":"a"}`)
+	assert.Equal(t, http.StatusCreated, again.Code)
+	assert.Equal(t, first.Body.String(), again.Body.String())
+	assert.Equal(t, "application/json", again.Header().Get("Content-Type"))
+	assert.Empty(t, again.Header().Get("X-Request-Only"))
+	assert.Equal(t, "true", again.Header().Get(HeaderIdempotentReplayed))
+	assert.EqualValues(t, 1, created.Load(), "the task is created once")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, send("agent-1", "retry-1", `{"title":"b"}`).Code)
+
+	// Keys are scoped to the caller, and requests without one always run
+	assert.Equal(t, http.StatusCreated, send("agent-2", "retry-1", `{"title":"a"}`).Code)
+	assert.Equal(t, http.StatusCreated, send("agent-1", "", `{"title":"a"}`).Code)
+	assert.EqualValues(t, 3, created.Load())
+
+	// Server errors release the key for the retry
+	failing.Store(true)
+	assert.Equal(t, http.StatusInternalServerError, send("agent-1", "retry-2", `{}`).Code)
+	failing.Store(false)
+	assert.Equal(t, http.StatusCreated, send("agent-1", "retry-2", `{}`).Code)
+
+	assert.Equal(t, http.StatusBadRequest, send("agent-1", strings.Repeat("k", 256), `{}`).Code)
+}
+
+func TestIdempotency_RejectsConcurrentRepeat(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := NewIdempotency(nil, 1024).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, "retry-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send() }()
+	<-started
+
+	rec := send()
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+	assert.Equal(t, http.StatusCreated, send().Code)
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/coordination"
+)
+
+// maxRateLimitClients bounds the number of tracked clients. Expired windows are
+// swept once it is reached, and the oldest live one is dropped if none expired.
+const maxRateLimitClients = 10000
+
+// RateLimiter allows each client IP, as ClientIP resolves it, a fixed number of
+// requests per window.
+// Counts are kept in the shared backend, so the limit holds across server
+// replicas, or in memory without one, so every replica limits on its own.
+type RateLimiter struct {
+	name   string
+	window time.Duration
+	shared coordination.Backend
+
+	mu      sync.Mutex
+	limit   int
+	clients map[string]*rateWindow
+}
+
+// rateWindow counts the requests of one client in the current window.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter creates a RateLimiter allowing limit requests per window and
+// client IP. name keeps the counters of different limiters apart in shared, which
+// may be nil.
+func NewRateLimiter(name string, limit int, window time.Duration, shared coordination.Backend) *RateLimiter {
+	return &RateLimiter{name: name, limit: limit, window: window, shared: shared, clients: make(map[string]*rateWindow)}
+}
+
+// SetLimit changes the number of requests allowed per window. Requests already
+// counted in the current windows count against the new limit.
+func (l *RateLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+// Limit rejects requests beyond the limit with 429 and a Retry-After header.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, ok := l.allowShared(r, ClientIP(r)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowShared counts a request of client in the shared backend. When the backend
+// is unavailable the request is counted in memory instead.
+func (l *RateLimiter) allowShared(r *http.Request, client string) (time.Duration, bool) {
+	if l.shared == nil {
+		return l.allow(client, time.Now())
+	}
+
+	count, resetIn, err := l.shared.Increment(r.Context(), "ratelimit:"+l.name+":"+client, l.window)
+	if err != nil {
+		slog.Warn("shared rate limit unavailable, counting locally", "limiter", l.name, "error", err)
+		return l.allow(client, time.Now())
+	}
+
+	l.mu.Lock()
+	limit := l.limit
+	l.mu.Unlock()
+
+	if count > limit {
+		return resetIn, false
+	}
+	return 0, true
+}
+
+// allow counts a request of client in memory and reports whether it is within
+// the limit, or how long until the client's window resets.
+func (l *RateLimiter) allow(client string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.clients[client]
+	if !ok && len(l.clients) >= maxRateLimitClients {
+		l.sweep(now)
+	}
+	if !ok || now.Sub(window.start) >= l.window {
+		window = &rateWindow{start: now}
+		l.clients[client] = window
+	}
+	if window.count >= l.limit {
+		return window.start.Add(l.window).Sub(now), false
+	}
+	window.count++
+	return 0, true
+}
+
+// sweep drops the expired windows, or the oldest one when all are live, so a
+// flood of new clients cannot grow the map without bound. The dropped client
+// starts over, which errs on letting it through rather than rejecting everyone
+// new. Called with l.mu held.
+func (l *RateLimiter) sweep(now time.Time) {
+	var oldestKey string
+	var oldest *rateWindow
+	for key, window := range l.clients {
+		if now.Sub(window.start) >= l.window {
+			delete(l.clients, key)
+			continue
+		}
+		if oldest == nil || window.start.Before(oldest.start) {
+			oldestKey, oldest = key, window
+		}
+	}
+	if len(l.clients) >= maxRateLimitClients && oldest != nil {
+		delete(l.clients, oldestKey)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_BoundsLiveClients(t *testing.T) {
+	l := NewRateLimiter("test", 1, time.Hour, nil)
+	start := time.Now()
+
+	for i := range maxRateLimitClients {
+		_, ok := l.allow("client-"+strconv.Itoa(i), start.Add(time.Duration(i)*time.Millisecond))
+		assert.True(t, ok)
+	}
+
+	// Every window is live, so the oldest makes room for the new client
+	now := start.Add(time.Minute)
+	_, ok := l.allow("newcomer", now)
+	assert.True(t, ok)
+	assert.Len(t, l.clients, maxRateLimitClients)
+	assert.NotContains(t, l.clients, "client-0")
+
+	// Known clients are still limited
+	_, ok = l.allow("client-1", now)
+	assert.False(t, ok)
+	_, ok = l.allow("newcomer", now)
+	assert.False(t, ok)
+}
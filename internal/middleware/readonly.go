@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/coordination"
+)
+
+// readOnlyRetryAfter is the Retry-After sent with writes rejected in read-only
+// mode: maintenance usually takes minutes, and agents polling sooner only add
+// load.
+const readOnlyRetryAfter = 60 * time.Second
+
+// readOnlyExempt are the path prefixes still written to in read-only mode: the
+// admin API, so operators can work and switch the mode off, heartbeats, so
+// agents aren't released as stale once the maintenance ends, and the POST
+// endpoints that only read.
+var readOnlyExempt = []string{
+	"/api/v1/admin/",
+	"/api/v1/agents/me/heartbeat",
+	"/api/v1/graphql",
+	"/api/v1/grafana/",
+}
+
+// readOnlyChannel carries read-only switches between server replicas: "on" or
+// "off".
+const readOnlyChannel = "read-only"
+
+// readOnlyKey keeps the shared mode, "on" or "off", for replicas starting later
+// and for the background workers.
+const readOnlyKey = "read-only"
+
+// ReadOnly switches the API to read-only mode, e.g. during migrations or
+// incident response: reads keep being served while writes are rejected with
+// 503 and a Retry-After header. With a shared backend the mode is switched on
+// every replica at once.
+type ReadOnly struct {
+	enabled atomic.Bool
+	shared  coordination.Backend
+}
+
+// NewReadOnly creates a ReadOnly, in read-only mode when enabled is true,
+// following the switches broadcast through shared, which may be nil.
+func NewReadOnly(enabled bool, shared coordination.Backend) *ReadOnly {
+	readOnly := &ReadOnly{shared: shared}
+	readOnly.enabled.Store(enabled)
+	if shared != nil {
+		go readOnly.followSwitches(shared.Subscribe(readOnlyChannel))
+	}
+	return readOnly
+}
+
+// Enabled reports whether writes are rejected.
+func (ro *ReadOnly) Enabled() bool {
+	return ro.enabled.Load()
+}
+
+// Set switches read-only mode on or off in this process and reports whether it
+// changed.
+func (ro *ReadOnly) Set(enabled bool) bool {
+	return ro.enabled.Swap(enabled) != enabled
+}
+
+// Switch switches read-only mode on or off here and on every replica sharing
+// the backend, and reports whether it changed here. On error the mode is only
+// switched here.
+func (ro *ReadOnly) Switch(ctx context.Context, enabled bool) (bool, error) {
+	changed := ro.Set(enabled)
+	if ro.shared == nil {
+		return changed, nil
+	}
+
+	if err := ro.shared.Set(ctx, readOnlyKey, readOnlyValue(enabled), 0); err != nil {
+		return changed, err
+	}
+	if err := ro.shared.Publish(ctx, readOnlyChannel, readOnlyValue(enabled)); err != nil {
+		return changed, err
+	}
+	return changed, nil
+}
+
+// Sync brings a starting server in line with its replicas: switched on, it
+// switches them all on; otherwise it takes the mode they share.
+func (ro *ReadOnly) Sync(ctx context.Context) error {
+	if ro.shared == nil {
+		return nil
+	}
+	if ro.Enabled() {
+		_, err := ro.Switch(ctx, true)
+		return err
+	}
+
+	enabled, err := SharedReadOnly(ctx, ro.shared)
+	if err != nil {
+		return err
+	}
+	ro.Set(enabled)
+	return nil
+}
+
+// SharedReadOnly reports whether the replicas sharing the backend are in
+// read-only mode, for processes without a ReadOnly such as the workers.
+func SharedReadOnly(ctx context.Context, shared coordination.Backend) (bool, error) {
+	value, ok, err := shared.Get(ctx, readOnlyKey)
+	if err != nil {
+		return false, err
+	}
+	return ok && value == readOnlyValue(true), nil
+}
+
+// followSwitches applies the switches broadcast by every replica until the
+// backend is closed.
+func (ro *ReadOnly) followSwitches(messages <-chan string) {
+	for message := range messages {
+		if ro.Set(message == readOnlyValue(true)) {
+			slog.Warn("read-only mode switched by another replica", "enabled", ro.Enabled())
+		}
+	}
+}
+
+// readOnlyValue encodes the mode for the shared backend.
+func readOnlyValue(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// Guard rejects write requests (anything but GET, HEAD and OPTIONS) in
+// read-only mode, except on the exempt paths.
+func (ro *ReadOnly) Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ro.Enabled() || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range readOnlyExempt {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(readOnlyRetryAfter.Seconds())))
+		http.Error(w, "server is read-only for maintenance", http.StatusServiceUnavailable)
+	})
+}
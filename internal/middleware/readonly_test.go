@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly_RejectsWrites(t *testing.T) {
+	readOnly := middleware.NewReadOnly(false, nil)
+	handler := readOnly.Guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/tasks").Code)
+
+	assert.True(t, readOnly.Set(true))
+	assert.False(t, readOnly.Set(true), "switching on twice changes nothing")
+	assert.True(t, readOnly.Enabled())
+
+	rec := serve(http.MethodPost, "/api/v1/tasks")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPatch, "/api/v1/tasks/1/status").Code)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/tasks").Code, "reads are still served")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/graphql").Code, "GraphQL queries only read")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "/api/v1/admin/read-only").Code, "operators can switch it off")
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/agents/me/heartbeat").Code, "agents stay alive")
+
+	assert.True(t, readOnly.Set(false))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/tasks").Code)
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mtlprog/sloptask/internal/coordination"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicas connects two servers' worth of backends to one Redis.
+func newReplicas(t *testing.T) (*miniredis.Miniredis, coordination.Backend, coordination.Backend) {
+	server := miniredis.RunT(t)
+	connect := func() coordination.Backend {
+		backend, err := coordination.NewRedis(context.Background(), "redis://"+server.Addr())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = backend.Close() })
+		return backend
+	}
+	return server, connect(), connect()
+}
+
+func TestRateLimiter_SharedAcrossReplicas(t *testing.T) {
+	server, first, second := newReplicas(t)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	replicas := []http.Handler{
+		NewRateLimiter("intake", 2, time.Hour, first).Limit(ok),
+		NewRateLimiter("intake", 2, time.Hour, second).Limit(ok),
+	}
+	request := func(replica int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/intake/acme", nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		w := httptest.NewRecorder()
+		replicas[replica].ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request(0).Code)
+	assert.Equal(t, http.StatusOK, request(1).Code)
+	w := request(0)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Other limiters and clients count separately
+	other := NewRateLimiter("login", 2, time.Hour, second)
+	allowed, _, err := other.shared.Increment(context.Background(), "ratelimit:login:203.0.113.7", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, allowed)
+
+	server.FastForward(time.Hour)
+	assert.Equal(t, http.StatusOK, request(1).Code)
+}
+
+func TestIdempotency_SharedAcrossReplicas(t *testing.T) {
+	server, first, second := newReplicas(t)
+
+	var created atomic.Int32
+	var failing atomic.Bool
+	replicas := []http.Handler{
+		NewIdempotency(first, 1024).Handle(countingHandler(&created, &failing)),
+		NewIdempotency(second, 1024).Handle(countingHandler(&created, &failing)),
+	}
+	send := func(replica int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"title":"a"}`))
+		req.Header.Set("Authorization", "Bearer agent-1")
+		req.Header.Set(HeaderIdempotencyKey, "retry-1")
+		w := httptest.NewRecorder()
+		replicas[replica].ServeHTTP(w, req)
+		return w
+	}
+
+	original := send(0)
+	assert.Equal(t, http.StatusCreated, original.Code)
+	retried := send(1)
+	assert.Equal(t, http.StatusCreated, retried.Code)
+	assert.Equal(t, "true", retried.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, original.Body.String(), retried.Body.String())
+	assert.EqualValues(t, 1, created.Load())
+
+	// Responses are kept for a day
+	server.FastForward(idempotencyKeyTTL)
+	assert.Empty(t, send(1).Header().Get(HeaderIdempotentReplayed))
+	assert.EqualValues(t, 2, created.Load())
+}
+
+func TestReadOnly_SwitchesEveryReplica(t *testing.T) {
+	_, first, second := newReplicas(t)
+	ctx := context.Background()
+
+	replicas := []*ReadOnly{NewReadOnly(false, first), NewReadOnly(false, second)}
+	// Subscriptions are confirmed before NewReadOnly returns
+	changed, err := replicas[0].Switch(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Eventually(t, replicas[1].Enabled, time.Second, 10*time.Millisecond)
+
+	// Workers and replicas starting later read the shared mode
+	enabled, err := SharedReadOnly(ctx, second)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	late := NewReadOnly(false, second)
+	require.NoError(t, late.Sync(ctx))
+	assert.True(t, late.Enabled())
+
+	_, err = replicas[1].Switch(ctx, false)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !replicas[0].Enabled() && !late.Enabled() }, time.Second, 10*time.Millisecond)
+	enabled, err = SharedReadOnly(ctx, first)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestAuthMiddleware_InvalidationReachesReplicas(t *testing.T) {
+	_, first, second := newReplicas(t)
+
+	replicas := []*AuthMiddleware{
+		NewAuthMiddleware(nil, nil, nil, "", time.Minute, first),
+		NewAuthMiddleware(nil, nil, nil, "", time.Minute, second),
+	}
+	now := time.Now()
+	for _, m := range replicas {
+		m.agents.put("token-1", &domain.Agent{ID: "agent-1", WorkspaceID: "workspace-1", IsActive: true}, now)
+		m.agents.put("token-2", &domain.Agent{ID: "agent-2", WorkspaceID: "workspace-2", IsActive: true}, now)
+	}
+	cached := func(m *AuthMiddleware, token string) bool {
+		_, ok := m.agents.get(token, now)
+		return ok
+	}
+
+	replicas[0].InvalidateAgent(context.Background(), "agent-1")
+	assert.False(t, cached(replicas[0], "token-1"))
+	assert.Eventually(t, func() bool { return !cached(replicas[1], "token-1") }, time.Second, 10*time.Millisecond)
+	assert.True(t, cached(replicas[1], "token-2"))
+
+	// The CLI reaches the servers without an AuthMiddleware of its own
+	require.NoError(t, PublishWorkspaceInvalidation(context.Background(), second, "workspace-2"))
+	for _, m := range replicas {
+		assert.Eventually(t, func() bool { return !cached(m, "token-2") }, time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestAuthMiddleware_RefreshStaysLocal(t *testing.T) {
+	_, first, second := newReplicas(t)
+
+	m := NewAuthMiddleware(nil, nil, nil, "", time.Minute, first)
+	now := time.Now()
+	m.agents.put("token-1", &domain.Agent{ID: "agent-1", WorkspaceID: "workspace-1", IsActive: true}, now)
+	messages := second.Subscribe(agentCacheChannel)
+
+	m.RefreshAgent("agent-1")
+	_, ok := m.agents.get("token-1", now)
+	assert.False(t, ok)
+
+	// The first broadcast the other replicas see is the next invalidation
+	m.InvalidateAgent(context.Background(), "agent-2")
+	select {
+	case message := <-messages:
+		assert.Equal(t, "agent:agent-2", message)
+	case <-time.After(time.Second):
+		t.Fatal("invalidation was not broadcast")
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// W3C Trace Context request headers.
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+// PropagateTrace captures the W3C trace context of incoming requests into the
+// request context, so events caused by the request carry it. Requests without
+// a valid traceparent pass through unchanged.
+func PropagateTrace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := domain.ParseTraceContext(r.Header.Get(HeaderTraceParent), r.Header.Get(HeaderTraceState))
+		if tc != nil {
+			r = r.WithContext(domain.ContextWithTrace(r.Context(), tc))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
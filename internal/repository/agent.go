@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
@@ -12,16 +13,20 @@ import (
 )
 
 // agentColumns is the shared list of columns for agent queries.
-var agentColumns = []string{"id", "workspace_id", "name", "token", "is_active", "created_at"}
+var agentColumns = []string{"id", "workspace_id", "name", "token", "is_active", "capabilities", "last_seen_at", "created_at"}
 
-// AgentRepository handles database operations for agents.
-type AgentRepository struct {
+// staleAgentCondition matches agents (aliased a) of workspaces (aliased w) whose last
+// heartbeat is older than the workspace's staleness window.
+const staleAgentCondition = "a.last_seen_at < NOW() - make_interval(secs => w.agent_stale_after_seconds)"
+
+// PgAgentRepository handles database operations for agents.
+type PgAgentRepository struct {
 	pool *pgxpool.Pool
 }
 
-// NewAgentRepository creates a new AgentRepository.
-func NewAgentRepository(pool *pgxpool.Pool) *AgentRepository {
-	return &AgentRepository{pool: pool}
+// NewAgentRepository creates a new PgAgentRepository.
+func NewAgentRepository(pool *pgxpool.Pool) *PgAgentRepository {
+	return &PgAgentRepository{pool: pool}
 }
 
 // scanAgent scans a single row into an Agent struct.
@@ -33,6 +38,8 @@ func scanAgent(row pgx.Row) (*domain.Agent, error) {
 		&agent.Name,
 		&agent.Token,
 		&agent.IsActive,
+		&agent.Capabilities,
+		&agent.LastSeenAt,
 		&agent.CreatedAt,
 	)
 	if err != nil {
@@ -44,8 +51,34 @@ func scanAgent(row pgx.Row) (*domain.Agent, error) {
 	return &agent, nil
 }
 
+// Create inserts an active agent (within transaction) and sets its ID and
+// creation time.
+func (r *PgAgentRepository) Create(ctx context.Context, tx pgx.Tx, agent *domain.Agent) error {
+	capabilities := agent.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+
+	query, args, err := psql.
+		Insert("agents").
+		Columns("workspace_id", "name", "token", "is_active", "capabilities").
+		Values(agent.WorkspaceID, agent.Name, agent.Token, true, capabilities).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for agent: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&agent.ID, &agent.CreatedAt); err != nil {
+		return fmt.Errorf("create agent: %w", err)
+	}
+	agent.IsActive = true
+
+	return nil
+}
+
 // GetByToken finds an agent by authentication token.
-func (r *AgentRepository) GetByToken(ctx context.Context, token string) (*domain.Agent, error) {
+func (r *PgAgentRepository) GetByToken(ctx context.Context, token string) (*domain.Agent, error) {
 	query, args, err := psql.
 		Select(agentColumns...).
 		From("agents").
@@ -59,7 +92,7 @@ func (r *AgentRepository) GetByToken(ctx context.Context, token string) (*domain
 }
 
 // GetByID retrieves an agent by ID.
-func (r *AgentRepository) GetByID(ctx context.Context, agentID string) (*domain.Agent, error) {
+func (r *PgAgentRepository) GetByID(ctx context.Context, agentID string) (*domain.Agent, error) {
 	query, args, err := psql.
 		Select(agentColumns...).
 		From("agents").
@@ -71,3 +104,180 @@ func (r *AgentRepository) GetByID(ctx context.Context, agentID string) (*domain.
 
 	return scanAgent(r.pool.QueryRow(ctx, query, args...))
 }
+
+// ListByWorkspace returns every agent of a workspace, active or not, by name.
+func (r *PgAgentRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Agent, error) {
+	query, args, err := psql.
+		Select(agentColumns...).
+		From("agents").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("name ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for workspace %s: %w", workspaceID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*domain.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return agents, nil
+}
+
+// SetCapabilities replaces the capabilities of an agent.
+func (r *PgAgentRepository) SetCapabilities(ctx context.Context, agentID string, capabilities []string) error {
+	query, args, err := psql.
+		Update("agents").
+		Set("capabilities", capabilities).
+		Where(sq.Eq{"id": agentID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetCapabilities query for agent %s: %w", agentID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set agent capabilities: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAgentNotFound
+	}
+
+	return nil
+}
+
+// Heartbeat records that an agent is alive and returns the recorded time.
+func (r *PgAgentRepository) Heartbeat(ctx context.Context, agentID string) (time.Time, error) {
+	query, args, err := psql.
+		Update("agents").
+		Set("last_seen_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": agentID}).
+		Suffix("RETURNING last_seen_at").
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("build Heartbeat query for agent %s: %w", agentID, err)
+	}
+
+	var lastSeenAt time.Time
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&lastSeenAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, domain.ErrAgentNotFound
+		}
+		return time.Time{}, fmt.Errorf("record heartbeat: %w", err)
+	}
+
+	return lastSeenAt, nil
+}
+
+// ListWorkloads returns active agents of a workspace with their current task counts.
+func (r *PgAgentRepository) ListWorkloads(ctx context.Context, workspaceID string) ([]*domain.AgentWorkload, error) {
+	columns := make([]string, 0, len(agentColumns)+3)
+	for _, column := range agentColumns {
+		columns = append(columns, "a."+column)
+	}
+	columns = append(columns,
+		"(SELECT COUNT(*) FROM tasks t WHERE t.assignee_id = a.id AND t.status = 'IN_PROGRESS' AND t.deleted_at IS NULL)",
+		"(SELECT COUNT(*) FROM tasks t WHERE t.assignee_id = a.id AND t.status NOT IN ('DONE', 'CANCELLED') AND t.deleted_at IS NULL)",
+		"a.last_auto_assigned_at",
+	)
+
+	query, args, err := psql.
+		Select(columns...).
+		From("agents a").
+		Where(sq.Eq{"a.workspace_id": workspaceID, "a.is_active": true}).
+		OrderBy("a.created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListWorkloads query for workspace %s: %w", workspaceID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query agent workloads: %w", err)
+	}
+	defer rows.Close()
+
+	var workloads []*domain.AgentWorkload
+	for rows.Next() {
+		var agent domain.Agent
+		workload := domain.AgentWorkload{Agent: &agent}
+		err := rows.Scan(
+			&agent.ID,
+			&agent.WorkspaceID,
+			&agent.Name,
+			&agent.Token,
+			&agent.IsActive,
+			&agent.Capabilities,
+			&agent.LastSeenAt,
+			&agent.CreatedAt,
+			&workload.InProgressCount,
+			&workload.ActiveCount,
+			&workload.LastAutoAssignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan agent workload: %w", err)
+		}
+		workloads = append(workloads, &workload)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate agent workloads: %w", err)
+	}
+
+	return workloads, nil
+}
+
+// MarkAutoAssigned records that the agent was just given a task by the auto-assign job.
+func (r *PgAgentRepository) MarkAutoAssigned(ctx context.Context, tx pgx.Tx, agentID string) error {
+	query, args, err := psql.
+		Update("agents").
+		Set("last_auto_assigned_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": agentID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build MarkAutoAssigned query for agent %s: %w", agentID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("mark agent auto-assigned: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeByWorkspace deactivates the active agents of a workspace and replaces
+// their tokens, so the old tokens stop working even if an agent is reactivated
+// (within transaction).
+func (r *PgAgentRepository) RevokeByWorkspace(ctx context.Context, tx pgx.Tx, workspaceID string) (int64, error) {
+	query, args, err := psql.
+		Update("agents").
+		Set("is_active", false).
+		Set("token", sq.Expr("'revoked:' || id::text")).
+		Where(sq.Eq{"workspace_id": workspaceID, "is_active": true}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build RevokeByWorkspace query for agents of workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("revoke workspace agents: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// auditColumns is the shared list of columns for admin audit log queries.
+var auditColumns = []string{"id", "action", "workspace_id", "details", "created_at"}
+
+// rowQuerier is satisfied by *pgxpool.Pool and pgx.Tx.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// AuditRepository handles database operations for the admin audit log.
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new AuditRepository.
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// AuditFilters narrows ListAudit.
+type AuditFilters struct {
+	WorkspaceID *string
+	Action      *domain.AuditAction
+	Limit       int
+}
+
+// Record appends an entry to the audit log outside any transaction.
+func (r *AuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.insert(ctx, r.pool, entry)
+}
+
+// Create appends an entry to the audit log within a transaction, so the entry
+// is only kept if the audited change commits.
+func (r *AuditRepository) Create(ctx context.Context, tx pgx.Tx, entry *domain.AuditEntry) error {
+	return r.insert(ctx, tx, entry)
+}
+
+// insert writes an entry and populates ID and CreatedAt.
+func (r *AuditRepository) insert(ctx context.Context, db rowQuerier, entry *domain.AuditEntry) error {
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("encode audit details: %w", err)
+	}
+
+	query, args, err := psql.
+		Insert("admin_audit_log").
+		Columns("action", "workspace_id", "details").
+		Values(entry.Action, entry.WorkspaceID, data).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for audit entry: %w", err)
+	}
+
+	if err := db.QueryRow(ctx, query, args...).Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit entries, newest first.
+func (r *AuditRepository) List(ctx context.Context, filters AuditFilters) ([]*domain.AuditEntry, error) {
+	builder := psql.
+		Select(auditColumns...).
+		From("admin_audit_log").
+		OrderBy("created_at DESC", "id").
+		Limit(uint64(filters.Limit))
+	if filters.WorkspaceID != nil {
+		builder = builder.Where(sq.Eq{"workspace_id": *filters.WorkspaceID})
+	}
+	if filters.Action != nil {
+		builder = builder.Where(sq.Eq{"action": *filters.Action})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build List query for audit entries: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AuditEntry, 0)
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.Action, &entry.WorkspaceID, &details, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, fmt.Errorf("parse audit details: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// LastAt returns when an action was last recorded for a workspace, or nil if never.
+func (r *AuditRepository) LastAt(ctx context.Context, workspaceID string, action domain.AuditAction) (*time.Time, error) {
+	query, args, err := psql.
+		Select("MAX(created_at)").
+		From("admin_audit_log").
+		Where(sq.Eq{"workspace_id": workspaceID, "action": action}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LastAt query for audit entries: %w", err)
+	}
+
+	var last *time.Time
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&last); err != nil {
+		return nil, fmt.Errorf("query last audit entry: %w", err)
+	}
+
+	return last, nil
+}
@@ -0,0 +1,291 @@
+package repository_test
+
+// Repository benchmarks against a large seeded workspace.
+//
+// They need a dedicated database (the seed is kept between runs) and are
+// skipped unless BENCH_DATABASE_URL is set:
+//
+//	BENCH_DATABASE_URL=postgres://... make bench
+//
+// BENCH_TASKS (default 1000000) and BENCH_EVENTS_PER_TASK (default 10) size
+// the workspace. Changing them reseeds it.
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/database"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+const (
+	benchWorkspaceID = "00000000-0000-0000-0000-0000000be9c0"
+	benchAgentCount  = 50
+)
+
+var (
+	benchOnce  sync.Once
+	benchPool  *pgxpool.Pool
+	benchAgent string
+	benchErr   error
+)
+
+// benchEnvInt reads a positive integer from the environment, falling back to def.
+func benchEnvInt(name string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return def
+}
+
+// setupBench connects to the benchmark database and seeds it once per process.
+func setupBench(b *testing.B) (repository.TaskRepository, string) {
+	b.Helper()
+
+	databaseURL := os.Getenv("BENCH_DATABASE_URL")
+	if databaseURL == "" {
+		b.Skip("BENCH_DATABASE_URL not set")
+	}
+
+	benchOnce.Do(func() {
+		ctx := context.Background()
+
+		db, err := database.New(ctx, databaseURL, database.DefaultPoolConfig)
+		if err != nil {
+			benchErr = err
+			return
+		}
+		benchPool = db.Pool()
+
+		if err := database.RunMigrations(ctx, benchPool); err != nil {
+			benchErr = err
+			return
+		}
+
+		benchAgent, benchErr = seedBenchWorkspace(ctx, benchPool,
+			benchEnvInt("BENCH_TASKS", 1_000_000),
+			benchEnvInt("BENCH_EVENTS_PER_TASK", 10),
+		)
+	})
+	if benchErr != nil {
+		b.Fatalf("setup benchmark database: %v", benchErr)
+	}
+
+	return repository.NewTaskRepository(benchPool), benchAgent
+}
+
+// seedBenchWorkspace creates the benchmark workspace with the requested number of
+// tasks and events, unless it already has exactly that many. Returns an agent ID.
+func seedBenchWorkspace(ctx context.Context, pool *pgxpool.Pool, tasks, eventsPerTask int) (string, error) {
+	var taskCount, eventCount int
+	err := pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM tasks WHERE workspace_id = $1),
+			(SELECT COUNT(*) FROM task_events e JOIN tasks t ON t.id = e.task_id WHERE t.workspace_id = $1)
+	`, benchWorkspaceID).Scan(&taskCount, &eventCount)
+	if err != nil {
+		return "", err
+	}
+
+	if taskCount != tasks || eventCount != tasks*eventsPerTask {
+		if err := reseedBenchWorkspace(ctx, pool, tasks, eventsPerTask); err != nil {
+			return "", err
+		}
+	}
+
+	var agentID string
+	err = pool.QueryRow(ctx, `
+		SELECT id FROM agents WHERE workspace_id = $1 ORDER BY name LIMIT 1
+	`, benchWorkspaceID).Scan(&agentID)
+	return agentID, err
+}
+
+// reseedBenchWorkspace drops and regenerates the benchmark workspace with set-based inserts.
+func reseedBenchWorkspace(ctx context.Context, pool *pgxpool.Pool, tasks, eventsPerTask int) error {
+	statements := []struct {
+		sql  string
+		args []any
+	}{
+		{`DELETE FROM workspaces WHERE id = $1`, []any{benchWorkspaceID}},
+		{`INSERT INTO workspaces (id, name, slug) VALUES ($1, 'Benchmark', 'bench')`, []any{benchWorkspaceID}},
+		{`
+			INSERT INTO agents (workspace_id, name, token)
+			SELECT $1, 'bench-agent-' || lpad(i::text, 3, '0'), 'bench-token-' || i
+			FROM generate_series(1, $2) AS i
+		`, []any{benchWorkspaceID, benchAgentCount}},
+		// Status mix roughly matching a long-running workspace: mostly finished work
+		{`
+			WITH agent_ids AS (
+				SELECT array_agg(id ORDER BY name) AS ids FROM agents WHERE workspace_id = $1
+			), seeded AS (
+				SELECT i,
+					(ARRAY['DONE','DONE','DONE','DONE','CANCELLED','NEW','IN_PROGRESS','BLOCKED','STUCK','NEEDS_REVIEW'])[1 + i % 10] AS status
+				FROM generate_series(1, $2) AS i
+			)
+			INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, status,
+				visibility, priority, status_deadline_at, created_at, updated_at)
+			SELECT $1,
+				'Benchmark task ' || i,
+				'Seeded for repository benchmarks',
+				ids[1 + i % $3],
+				CASE WHEN status = 'NEW' THEN NULL ELSE ids[1 + (i * 7) % $3] END,
+				status,
+				CASE WHEN i % 20 = 0 THEN 'private' ELSE 'public' END,
+				(ARRAY['low','normal','normal','high','critical'])[1 + i % 5],
+				CASE WHEN status IN ('NEW','IN_PROGRESS','BLOCKED')
+					THEN NOW() + ((i % 2000) - 1000) * INTERVAL '1 minute' END,
+				NOW() - (i % 365) * INTERVAL '1 day',
+				NOW() - (i % 30) * INTERVAL '1 day'
+			FROM seeded, agent_ids
+		`, []any{benchWorkspaceID, tasks, benchAgentCount}},
+		{`
+			INSERT INTO task_events (task_id, actor_id, type, new_status, comment, created_at)
+			SELECT t.id, t.creator_id,
+				CASE WHEN n = 1 THEN 'created' ELSE 'commented' END,
+				CASE WHEN n = 1 THEN 'NEW' END,
+				'Benchmark event ' || n,
+				t.created_at + n * INTERVAL '1 minute'
+			FROM tasks t, generate_series(1, $2) AS n
+			WHERE t.workspace_id = $1
+		`, []any{benchWorkspaceID, eventsPerTask}},
+		{`ANALYZE workspaces, agents, tasks, task_events`, nil},
+	}
+
+	for _, statement := range statements {
+		if _, err := pool.Exec(ctx, statement.sql, statement.args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BenchmarkList_FirstPage measures the default task list (priority, created_at).
+func BenchmarkList_FirstPage(b *testing.B) {
+	repo, agentID := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, _, err := repo.List(ctx, repository.TaskListFilters{
+			WorkspaceID: benchWorkspaceID,
+			AgentID:     agentID,
+			Limit:       50,
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkList_DeepOffset measures paging far into the list.
+func BenchmarkList_DeepOffset(b *testing.B) {
+	repo, agentID := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, _, err := repo.List(ctx, repository.TaskListFilters{
+			WorkspaceID: benchWorkspaceID,
+			AgentID:     agentID,
+			Limit:       50,
+			Offset:      500_000,
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkList_ActiveStatuses measures the typical agent query for open work.
+func BenchmarkList_ActiveStatuses(b *testing.B) {
+	repo, agentID := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, _, err := repo.List(ctx, repository.TaskListFilters{
+			WorkspaceID: benchWorkspaceID,
+			AgentID:     agentID,
+			Statuses:    []string{"NEW", "IN_PROGRESS", "BLOCKED", "STUCK"},
+			Limit:       50,
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkList_Overdue measures the overdue filter.
+func BenchmarkList_Overdue(b *testing.B) {
+	repo, agentID := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, _, err := repo.List(ctx, repository.TaskListFilters{
+			WorkspaceID: benchWorkspaceID,
+			AgentID:     agentID,
+			Overdue:     true,
+			Limit:       50,
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStats_Workspace measures workspace statistics over the last 30 days.
+func BenchmarkStats_Workspace(b *testing.B) {
+	repo, _ := setupBench(b)
+	ctx := context.Background()
+	filters := repository.StatsFilters{
+		WorkspaceID: benchWorkspaceID,
+		PeriodStart: time.Now().AddDate(0, 0, -30),
+		PeriodEnd:   time.Now(),
+	}
+
+	for b.Loop() {
+		if _, err := repo.GetWorkspaceStats(ctx, filters); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStats_Agents measures per-agent statistics over the last 30 days.
+func BenchmarkStats_Agents(b *testing.B) {
+	repo, _ := setupBench(b)
+	ctx := context.Background()
+	filters := repository.StatsFilters{
+		WorkspaceID: benchWorkspaceID,
+		PeriodStart: time.Now().AddDate(0, 0, -30),
+		PeriodEnd:   time.Now(),
+	}
+
+	for b.Loop() {
+		if _, err := repo.GetAgentStats(ctx, filters); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFindExpiredDeadlines measures the deadline checker query.
+func BenchmarkFindExpiredDeadlines(b *testing.B) {
+	repo, _ := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := repo.FindExpiredDeadlines(ctx, nil, 500); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkQueueDepth measures the autoscaling signal query.
+func BenchmarkQueueDepth(b *testing.B) {
+	repo, _ := setupBench(b)
+	ctx := context.Background()
+
+	for b.Loop() {
+		if _, err := repo.GetQueueDepth(ctx, benchWorkspaceID, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
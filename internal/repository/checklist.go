@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// checklistItemColumns is the shared list of columns for checklist item queries.
+var checklistItemColumns = []string{
+	"id", "task_id", "title", "position",
+	"claimed_by", "claimed_at", "completed_by", "completed_at", "created_at",
+}
+
+// PgChecklistRepository handles database operations for task checklist items.
+type PgChecklistRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewChecklistRepository creates a new PgChecklistRepository.
+func NewChecklistRepository(pool *pgxpool.Pool) *PgChecklistRepository {
+	return &PgChecklistRepository{pool: pool}
+}
+
+// scanChecklistItem scans a single row into a ChecklistItem struct.
+func scanChecklistItem(row pgx.Row) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	err := row.Scan(
+		&item.ID,
+		&item.TaskID,
+		&item.Title,
+		&item.Position,
+		&item.ClaimedBy,
+		&item.ClaimedAt,
+		&item.CompletedBy,
+		&item.CompletedAt,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrChecklistItemNotFound
+		}
+		return nil, fmt.Errorf("scan checklist item: %w", err)
+	}
+	return &item, nil
+}
+
+// Create appends a checklist item to the end of the task's checklist within a transaction.
+// The caller must hold a lock on the parent task so positions don't collide.
+func (r *PgChecklistRepository) Create(ctx context.Context, tx pgx.Tx, item *domain.ChecklistItem) error {
+	query, args, err := psql.
+		Insert("task_checklist_items").
+		Columns("task_id", "title", "position").
+		Values(
+			item.TaskID,
+			item.Title,
+			sq.Expr("(SELECT COALESCE(MAX(position), 0) + 1 FROM task_checklist_items WHERE task_id = ?)", item.TaskID),
+		).
+		Suffix("RETURNING id, position, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for checklist item: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&item.ID, &item.Position, &item.CreatedAt); err != nil {
+		return fmt.Errorf("create checklist item: %w", err)
+	}
+
+	return nil
+}
+
+// ListByTaskID returns the checklist of a task ordered by position.
+func (r *PgChecklistRepository) ListByTaskID(ctx context.Context, taskID string) ([]*domain.ChecklistItem, error) {
+	query, args, err := psql.
+		Select(checklistItemColumns...).
+		From("task_checklist_items").
+		Where(sq.Eq{"task_id": taskID}).
+		OrderBy("position").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByTaskID query for checklist items: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query checklist items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*domain.ChecklistItem
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate checklist items: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetByIDForUpdate retrieves a checklist item of the given task with a row lock.
+func (r *PgChecklistRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID, itemID string) (*domain.ChecklistItem, error) {
+	query, args, err := psql.
+		Select(checklistItemColumns...).
+		From("task_checklist_items").
+		Where(sq.Eq{"id": itemID, "task_id": taskID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByIDForUpdate query for checklist item %s: %w", itemID, err)
+	}
+
+	return scanChecklistItem(tx.QueryRow(ctx, query, args...))
+}
+
+// Claim assigns an unclaimed, incomplete checklist item to an agent.
+func (r *PgChecklistRepository) Claim(ctx context.Context, tx pgx.Tx, itemID, agentID string) error {
+	query, args, err := psql.
+		Update("task_checklist_items").
+		Set("claimed_by", agentID).
+		Set("claimed_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": itemID, "claimed_by": nil, "completed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Claim query for checklist item %s: %w", itemID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("claim checklist item: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChecklistItemClaimed
+	}
+
+	return nil
+}
+
+// Complete marks an incomplete checklist item as completed by an agent.
+func (r *PgChecklistRepository) Complete(ctx context.Context, tx pgx.Tx, itemID, agentID string) error {
+	query, args, err := psql.
+		Update("task_checklist_items").
+		Set("completed_by", agentID).
+		Set("completed_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": itemID, "completed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Complete query for checklist item %s: %w", itemID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("complete checklist item: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChecklistItemCompleted
+	}
+
+	return nil
+}
+
+// ReleaseClaims unclaims the incomplete checklist items of a task (within
+// transaction). Returns the number of items released.
+func (r *PgChecklistRepository) ReleaseClaims(ctx context.Context, tx pgx.Tx, taskID string) (int64, error) {
+	query, args, err := psql.
+		Update("task_checklist_items").
+		Set("claimed_by", nil).
+		Set("claimed_at", nil).
+		Where(sq.Eq{"task_id": taskID, "completed_at": nil}).
+		Where(sq.NotEq{"claimed_by": nil}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build ReleaseClaims query for task %s: %w", taskID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("release checklist claims: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// CountClaimsSince counts the tasks an agent claimed since the given time.
+// Auto-assignments are not claims and are not counted.
+func (r *PgTaskEventRepository) CountClaimsSince(ctx context.Context, agentID string, since time.Time) (int, error) {
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From("task_events").
+		Where(sq.Eq{"actor_id": agentID, "type": "claimed"}).
+		Where(sq.Gt{"created_at": since}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build CountClaimsSince query for agent %s: %w", agentID, err)
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count agent claims: %w", err)
+	}
+
+	return count, nil
+}
+
+// LastClaimantID returns the agent that made the workspace's most recent claim,
+// or nil if nothing was claimed yet.
+func (r *PgTaskEventRepository) LastClaimantID(ctx context.Context, workspaceID string) (*string, error) {
+	query, args, err := psql.
+		Select("te.actor_id").
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		Where(sq.Eq{"t.workspace_id": workspaceID, "te.type": "claimed"}).
+		OrderBy("te.created_at DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LastClaimantID query for workspace %s: %w", workspaceID, err)
+	}
+
+	var agentID *string
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&agentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query last claimant: %w", err)
+	}
+
+	return agentID, nil
+}
+
+// CountIdleAgents counts the workspace's active agents other than exceptAgentID
+// that are idle and live: holding no IN_PROGRESS task, with a heartbeat within
+// the staleness window, and with every capability in required. Agents that never
+// sent a heartbeat are not known to be running and are not counted.
+func (r *PgAgentRepository) CountIdleAgents(ctx context.Context, workspaceID, exceptAgentID string, required []string) (int, error) {
+	if required == nil {
+		required = []string{}
+	}
+
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From("agents a").
+		Join("workspaces w ON w.id = a.workspace_id").
+		Where(sq.Eq{"a.workspace_id": workspaceID, "a.is_active": true}).
+		Where(sq.NotEq{"a.id": exceptAgentID}).
+		Where("a.last_seen_at IS NOT NULL AND NOT (" + staleAgentCondition + ")").
+		Where(sq.Expr("a.capabilities @> ?::text[]", required)).
+		Where("NOT EXISTS (SELECT 1 FROM tasks t WHERE t.assignee_id = a.id AND t.status = 'IN_PROGRESS' AND t.deleted_at IS NULL)").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build CountIdleAgents query for workspace %s: %w", workspaceID, err)
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count idle agents: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// escalationRouteColumns is the shared list of columns for escalation route queries.
+var escalationRouteColumns = []string{
+	"id", "workspace_id", "position", "name", "label", "priority", "creator_id", "target_type", "target", "created_at",
+}
+
+// escalationNotificationColumns is the shared list of columns for escalation notification queries.
+var escalationNotificationColumns = []string{
+	"id", "workspace_id", "task_id", "event_id", "route", "target_type", "target", "payload",
+	"status", "attempts", "next_attempt_at", "last_error", "delivered_at", "created_at",
+}
+
+// PgEscalationRepository handles database operations for escalation routes and notifications.
+type PgEscalationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewEscalationRepository creates a new PgEscalationRepository.
+func NewEscalationRepository(pool *pgxpool.Pool) *PgEscalationRepository {
+	return &PgEscalationRepository{pool: pool}
+}
+
+// scanEscalationRoute scans a single row into an EscalationRoute struct.
+func scanEscalationRoute(row pgx.Row) (*domain.EscalationRoute, error) {
+	var route domain.EscalationRoute
+	err := row.Scan(
+		&route.ID,
+		&route.WorkspaceID,
+		&route.Position,
+		&route.Name,
+		&route.Label,
+		&route.Priority,
+		&route.CreatorID,
+		&route.TargetType,
+		&route.Target,
+		&route.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("scan escalation route: %w", err)
+	}
+	return &route, nil
+}
+
+// scanEscalationNotification scans a single row into an EscalationNotification struct.
+func scanEscalationNotification(row pgx.Row) (*domain.EscalationNotification, error) {
+	var notification domain.EscalationNotification
+	err := row.Scan(
+		&notification.ID,
+		&notification.WorkspaceID,
+		&notification.TaskID,
+		&notification.EventID,
+		&notification.Route,
+		&notification.TargetType,
+		&notification.Target,
+		&notification.Payload,
+		&notification.Status,
+		&notification.Attempts,
+		&notification.NextAttemptAt,
+		&notification.LastError,
+		&notification.DeliveredAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEscalationNotificationNotFound
+		}
+		return nil, fmt.Errorf("scan escalation notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// ListRoutes returns the escalation routes of a workspace in evaluation order.
+func (r *PgEscalationRepository) ListRoutes(ctx context.Context, workspaceID string) ([]*domain.EscalationRoute, error) {
+	query, args, err := psql.
+		Select(escalationRouteColumns...).
+		From("escalation_routes").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("position ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListRoutes query for workspace %s: %w", workspaceID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query escalation routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := []*domain.EscalationRoute{}
+	for rows.Next() {
+		route, err := scanEscalationRoute(rows)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return routes, nil
+}
+
+// ReplaceRoutes replaces all escalation routes of a workspace (within transaction).
+// Routes get their position from their index and their ID and creation time from the insert.
+func (r *PgEscalationRepository) ReplaceRoutes(ctx context.Context, tx pgx.Tx, workspaceID string, routes []*domain.EscalationRoute) error {
+	query, args, err := psql.
+		Delete("escalation_routes").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build ReplaceRoutes delete query for workspace %s: %w", workspaceID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("delete escalation routes: %w", err)
+	}
+
+	for i, route := range routes {
+		route.WorkspaceID = workspaceID
+		route.Position = i
+
+		query, args, err := psql.
+			Insert("escalation_routes").
+			Columns("workspace_id", "position", "name", "label", "priority", "creator_id", "target_type", "target").
+			Values(route.WorkspaceID, route.Position, route.Name, route.Label, route.Priority, route.CreatorID, route.TargetType, route.Target).
+			Suffix("RETURNING id, created_at").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build ReplaceRoutes insert query for workspace %s: %w", workspaceID, err)
+		}
+
+		if err := tx.QueryRow(ctx, query, args...).Scan(&route.ID, &route.CreatedAt); err != nil {
+			return fmt.Errorf("create escalation route %q: %w", route.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateNotification stores an escalation notification (within transaction).
+func (r *PgEscalationRepository) CreateNotification(ctx context.Context, tx pgx.Tx, notification *domain.EscalationNotification) error {
+	query, args, err := psql.
+		Insert("escalation_notifications").
+		Columns("workspace_id", "task_id", "event_id", "route", "target_type", "target", "payload", "status", "delivered_at").
+		Values(
+			notification.WorkspaceID,
+			notification.TaskID,
+			notification.EventID,
+			notification.Route,
+			notification.TargetType,
+			notification.Target,
+			notification.Payload,
+			notification.Status,
+			notification.DeliveredAt,
+		).
+		Suffix("RETURNING id, next_attempt_at, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build CreateNotification query: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&notification.ID, &notification.NextAttemptAt, &notification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create escalation notification: %w", err)
+	}
+
+	return nil
+}
+
+// ListAgentNotifications returns the escalation notifications routed to an agent,
+// newest first, together with their total count. Notifications of deleted tasks are skipped.
+func (r *PgEscalationRepository) ListAgentNotifications(ctx context.Context, agentID string, limit, offset int) ([]*domain.EscalationNotification, int, error) {
+	where := sq.And{
+		sq.Eq{"n.target_type": domain.EscalationTargetAgent, "n.target": agentID},
+		sq.Expr("EXISTS (SELECT 1 FROM tasks t WHERE t.id = n.task_id AND t.deleted_at IS NULL)"),
+	}
+
+	columns := make([]string, len(escalationNotificationColumns))
+	for i, column := range escalationNotificationColumns {
+		columns[i] = "n." + column
+	}
+
+	query, args, err := psql.
+		Select(columns...).
+		From("escalation_notifications n").
+		Where(where).
+		OrderBy("n.created_at DESC", "n.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build ListAgentNotifications query for agent %s: %w", agentID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query escalation notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*domain.EscalationNotification{}
+	for rows.Next() {
+		notification, err := scanEscalationNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	countQuery, countArgs, err := psql.
+		Select("COUNT(*)").
+		From("escalation_notifications n").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build ListAgentNotifications count query for agent %s: %w", agentID, err)
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count escalation notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// LockNextPendingDelivery locks the webhook or email notification that has been due
+// for delivery the longest at now. Rows locked by concurrent schedulers are skipped.
+// Returns ErrEscalationNotificationNotFound if nothing is due.
+func (r *PgEscalationRepository) LockNextPendingDelivery(ctx context.Context, tx pgx.Tx, now time.Time) (*domain.EscalationNotification, error) {
+	query, args, err := psql.
+		Select(escalationNotificationColumns...).
+		From("escalation_notifications").
+		Where(sq.Eq{"status": domain.EscalationPending}).
+		Where(sq.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at ASC").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockNextPendingDelivery query: %w", err)
+	}
+
+	return scanEscalationNotification(tx.QueryRow(ctx, query, args...))
+}
+
+// RecordDelivery stores the outcome of a delivery attempt (within transaction).
+// deliveryErr is nil when the notification was delivered; a failed notification
+// stays pending until nextAttemptAt, or is marked failed when nextAttemptAt is nil.
+func (r *PgEscalationRepository) RecordDelivery(
+	ctx context.Context,
+	tx pgx.Tx,
+	notificationID string,
+	now time.Time,
+	deliveryErr *string,
+	nextAttemptAt *time.Time,
+) error {
+	qb := psql.
+		Update("escalation_notifications").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", deliveryErr).
+		Where(sq.Eq{"id": notificationID})
+
+	switch {
+	case deliveryErr == nil:
+		qb = qb.Set("status", domain.EscalationDelivered).Set("delivered_at", now)
+	case nextAttemptAt == nil:
+		qb = qb.Set("status", domain.EscalationFailed)
+	default:
+		qb = qb.Set("next_attempt_at", *nextAttemptAt)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build RecordDelivery query for notification %s: %w", notificationID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("record escalation delivery: %w", err)
+	}
+
+	return nil
+}
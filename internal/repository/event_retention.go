@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// PruneBefore deletes up to limit events of a workspace recorded before before.
+// Every task keeps its created event. Comment threads are kept or deleted as a
+// whole, and a thread is kept while any of its comments was recorded since
+// before or is still referenced: by the outbox, by a mention or by an
+// escalation notification, whose rows would go with it. Returns the number of
+// events deleted.
+func (r *PgTaskEventRepository) PruneBefore(ctx context.Context, workspaceID string, before time.Time, limit int) (int64, error) {
+	batchQuery, batchArgs, err := sq.
+		Select("te.id").
+		From("task_events te").
+		Where(sq.Lt{"te.created_at": before}).
+		Where(sq.NotEq{"te.type": domain.EventTypeCreated}).
+		Where("te.task_id IN (SELECT id FROM tasks WHERE workspace_id = ?)", workspaceID).
+		// An event outside a thread is its own thread
+		Where(`NOT EXISTS (SELECT 1 FROM task_events k
+			WHERE (k.id = COALESCE(te.thread_id, te.id) OR k.thread_id = COALESCE(te.thread_id, te.id))
+			AND (k.created_at >= ?
+				OR EXISTS (SELECT 1 FROM event_outbox o WHERE o.event_id = k.id)
+				OR EXISTS (SELECT 1 FROM task_mentions m WHERE m.event_id = k.id)
+				OR EXISTS (SELECT 1 FROM escalation_notifications n WHERE n.event_id = k.id)))`, before).
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build PruneBefore batch query: %w", err)
+	}
+
+	query, args, err := psql.
+		Delete("task_events").
+		Where("id IN ("+batchQuery+")", batchArgs...).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build PruneBefore query: %w", err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("prune task events: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ExportRepository reads consistent snapshots of workspace data.
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewExportRepository creates a new ExportRepository.
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{pool: pool}
+}
+
+// Snapshot reads a workspace with its agents, tasks and events inside one
+// REPEATABLE READ, READ ONLY transaction, so tasks and events are mutually
+// consistent even while agents keep writing.
+func (r *ExportRepository) Snapshot(ctx context.Context, workspaceID string) (*domain.WorkspaceSnapshot, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("begin snapshot transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	snapshot := &domain.WorkspaceSnapshot{}
+
+	// The first statement pins the snapshot; NOW() is the transaction start time.
+	err = tx.QueryRow(ctx, `
+		SELECT
+			NOW(),
+			pg_current_snapshot()::text,
+			(CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END)::text
+	`).Scan(&snapshot.Manifest.SnapshotAt, &snapshot.Manifest.TxSnapshot, &snapshot.Manifest.SnapshotLSN)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot position: %w", err)
+	}
+
+	if snapshot.Workspace, err = r.snapshotWorkspace(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+	if snapshot.Agents, err = r.snapshotAgents(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+	if snapshot.Queues, err = r.snapshotQueues(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+	if snapshot.Labels, err = r.snapshotLabels(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+	if snapshot.Tasks, err = r.snapshotTasks(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+	if snapshot.Events, err = r.snapshotEvents(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit snapshot transaction: %w", err)
+	}
+
+	snapshot.Manifest.WorkspaceID = workspaceID
+	snapshot.Manifest.AgentCount = len(snapshot.Agents)
+	snapshot.Manifest.TaskCount = len(snapshot.Tasks)
+	snapshot.Manifest.EventCount = len(snapshot.Events)
+
+	return snapshot, nil
+}
+
+// snapshotWorkspace reads the workspace row within the snapshot transaction.
+func (r *ExportRepository) snapshotWorkspace(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for workspace %s: %w", workspaceID, err)
+	}
+
+	return scanWorkspace(tx.QueryRow(ctx, query, args...))
+}
+
+// snapshotQueues reads the workspace's queues within the snapshot transaction.
+func (r *ExportRepository) snapshotQueues(ctx context.Context, tx pgx.Tx, workspaceID string) ([]*domain.Queue, error) {
+	query, args, err := psql.
+		Select(queueColumns...).
+		From("task_queues").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for queues: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query snapshot queues: %w", err)
+	}
+	defer rows.Close()
+
+	queues := []*domain.Queue{}
+	for rows.Next() {
+		queue, err := scanQueue(rows)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate snapshot queues: %w", err)
+	}
+
+	return queues, nil
+}
+
+// snapshotLabels reads the workspace's label registry within the snapshot transaction.
+func (r *ExportRepository) snapshotLabels(ctx context.Context, tx pgx.Tx, workspaceID string) ([]*domain.Label, error) {
+	query, args, err := psql.
+		Select(labelColumns...).
+		From("labels").
+		Where(sq.Eq{"labels.workspace_id": workspaceID}).
+		OrderBy("labels.name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for labels: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query snapshot labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []*domain.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate snapshot labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// snapshotAgents reads the workspace's agents within the snapshot transaction.
+func (r *ExportRepository) snapshotAgents(ctx context.Context, tx pgx.Tx, workspaceID string) ([]*domain.Agent, error) {
+	query, args, err := psql.
+		Select(agentColumns...).
+		From("agents").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for agents: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*domain.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate agents: %w", err)
+	}
+
+	return agents, nil
+}
+
+// snapshotTasks reads the workspace's tasks within the snapshot transaction.
+func (r *ExportRepository) snapshotTasks(ctx context.Context, tx pgx.Tx, workspaceID string) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Where(notDeleted).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for tasks: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// exportEventColumns are the task_events columns (aliased te) read by exports.
+var exportEventColumns = []string{
+	"te.id", "te.task_id", "te.actor_id", "te.type", "te.old_status", "te.new_status", "te.comment", "te.data",
+	"te.traceparent", "te.tracestate", "te.created_at", "te.reply_to_event_id", "te.thread_id",
+}
+
+// snapshotEvents reads events of the workspace's tasks within the snapshot transaction.
+func (r *ExportRepository) snapshotEvents(ctx context.Context, tx pgx.Tx, workspaceID string) ([]*domain.TaskEvent, error) {
+	query, args, err := psql.
+		Select(exportEventColumns...).
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		Where(sq.Eq{"t.workspace_id": workspaceID, "t.deleted_at": nil}).
+		OrderBy("te.created_at", "te.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build snapshot query for task events: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query task events: %w", err)
+	}
+
+	return scanExportEvents(rows)
+}
+
+// EventLog reads every event recorded on the workspace's tasks before the given
+// time, in the order they were recorded (creation time, then ID). Unlike the
+// snapshot it keeps the events of deleted tasks, which stay until purged. With
+// a checkpoint only the events it does not cover are read.
+func (r *ExportRepository) EventLog(ctx context.Context, workspaceID string, checkpoint *domain.EventLogCheckpoint, before time.Time) ([]*domain.TaskEvent, error) {
+	builder := psql.
+		Select(exportEventColumns...).
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		Where(sq.Eq{"t.workspace_id": workspaceID}).
+		Where(sq.Lt{"te.created_at": before}).
+		OrderBy("te.created_at", "te.id")
+	if checkpoint != nil {
+		builder = builder.Where(sq.GtOrEq{"te.created_at": checkpoint.Before})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build event log query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query event log: %w", err)
+	}
+
+	return scanExportEvents(rows)
+}
+
+// EventLogCheckpoint returns the event log checkpoint of a workspace, or nil if
+// its events were never pruned.
+func (r *ExportRepository) EventLogCheckpoint(ctx context.Context, workspaceID string) (*domain.EventLogCheckpoint, error) {
+	query, args, err := psql.
+		Select("workspace_id", "seq", "hash", "covers_before", "created_at").
+		From("event_log_checkpoints").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build EventLogCheckpoint query: %w", err)
+	}
+
+	var checkpoint domain.EventLogCheckpoint
+	err = r.pool.QueryRow(ctx, query, args...).Scan(
+		&checkpoint.WorkspaceID,
+		&checkpoint.Seq,
+		&checkpoint.Hash,
+		&checkpoint.Before,
+		&checkpoint.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get event log checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// SaveEventLogCheckpoint records the event log checkpoint of a workspace. An
+// existing checkpoint is only replaced by one covering more events, so
+// concurrent prunes cannot move it back.
+func (r *ExportRepository) SaveEventLogCheckpoint(ctx context.Context, checkpoint *domain.EventLogCheckpoint) error {
+	query, args, err := psql.
+		Insert("event_log_checkpoints").
+		Columns("workspace_id", "seq", "hash", "covers_before").
+		Values(checkpoint.WorkspaceID, checkpoint.Seq, checkpoint.Hash, checkpoint.Before).
+		Suffix(`ON CONFLICT (workspace_id) DO UPDATE SET
+			seq = EXCLUDED.seq,
+			hash = EXCLUDED.hash,
+			covers_before = EXCLUDED.covers_before,
+			created_at = NOW()
+			WHERE event_log_checkpoints.covers_before < EXCLUDED.covers_before`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SaveEventLogCheckpoint query: %w", err)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("save event log checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// scanExportEvents scans rows selected with exportEventColumns.
+func scanExportEvents(rows pgx.Rows) ([]*domain.TaskEvent, error) {
+	defer rows.Close()
+
+	var events []*domain.TaskEvent
+	for rows.Next() {
+		var event domain.TaskEvent
+		var traceParent, traceState *string
+		err := rows.Scan(
+			&event.ID,
+			&event.TaskID,
+			&event.ActorID,
+			&event.Type,
+			&event.OldStatus,
+			&event.NewStatus,
+			&event.Comment,
+			&event.Data,
+			&traceParent,
+			&traceState,
+			&event.CreatedAt,
+			&event.ReplyToEventID,
+			&event.ThreadID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan task event: %w", err)
+		}
+		event.Trace = toTraceContext(traceParent, traceState)
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// intakeFormColumns is the shared list of columns for intake form queries.
+var intakeFormColumns = []string{
+	"workspace_id", "agent_id", "key_hash", "rate_limit_per_hour", "created_at", "updated_at",
+}
+
+// IntakeRepository handles database operations for workspace intake forms.
+type IntakeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIntakeRepository creates a new IntakeRepository.
+func NewIntakeRepository(pool *pgxpool.Pool) *IntakeRepository {
+	return &IntakeRepository{pool: pool}
+}
+
+// scanIntakeForm scans a single row into an IntakeForm struct.
+func scanIntakeForm(row pgx.Row) (*domain.IntakeForm, error) {
+	var form domain.IntakeForm
+	err := row.Scan(
+		&form.WorkspaceID,
+		&form.AgentID,
+		&form.KeyHash,
+		&form.RateLimitPerHour,
+		&form.CreatedAt,
+		&form.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrIntakeNotFound
+		}
+		return nil, fmt.Errorf("scan intake form: %w", err)
+	}
+	return &form, nil
+}
+
+// Get retrieves the intake form of a workspace.
+func (r *IntakeRepository) Get(ctx context.Context, workspaceID string) (*domain.IntakeForm, error) {
+	query, args, err := psql.
+		Select(intakeFormColumns...).
+		From("intake_forms").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build Get query for intake form: %w", err)
+	}
+
+	return scanIntakeForm(r.pool.QueryRow(ctx, query, args...))
+}
+
+// GetForUpdate retrieves the intake form of a workspace with a row lock, which
+// serializes submissions so the hourly limit holds under concurrency.
+func (r *IntakeRepository) GetForUpdate(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.IntakeForm, error) {
+	query, args, err := psql.
+		Select(intakeFormColumns...).
+		From("intake_forms").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetForUpdate query for intake form: %w", err)
+	}
+
+	return scanIntakeForm(tx.QueryRow(ctx, query, args...))
+}
+
+// Upsert creates or replaces the intake form of a workspace and populates its timestamps.
+func (r *IntakeRepository) Upsert(ctx context.Context, form *domain.IntakeForm) error {
+	query, args, err := psql.
+		Insert("intake_forms").
+		Columns("workspace_id", "agent_id", "key_hash", "rate_limit_per_hour").
+		Values(form.WorkspaceID, form.AgentID, form.KeyHash, form.RateLimitPerHour).
+		Suffix(`ON CONFLICT (workspace_id) DO UPDATE SET
+			agent_id = EXCLUDED.agent_id,
+			key_hash = EXCLUDED.key_hash,
+			rate_limit_per_hour = EXCLUDED.rate_limit_per_hour,
+			updated_at = NOW()
+			RETURNING created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Upsert query for intake form: %w", err)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&form.CreatedAt, &form.UpdatedAt); err != nil {
+		return fmt.Errorf("upsert intake form: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the intake form of a workspace.
+func (r *IntakeRepository) Delete(ctx context.Context, workspaceID string) error {
+	query, args, err := psql.
+		Delete("intake_forms").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for intake form: %w", err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete intake form: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrIntakeNotFound
+	}
+
+	return nil
+}
+
+// CountSubmissionsSince counts the tasks of a workspace submitted through its
+// intake form since the given time, going by their created events.
+func (r *IntakeRepository) CountSubmissionsSince(ctx context.Context, tx pgx.Tx, workspaceID string, since time.Time) (int, error) {
+	var count int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM task_events e
+		JOIN tasks t ON t.id = e.task_id
+		WHERE t.workspace_id = $1
+		  AND e.type = $2
+		  AND e.data->>'source' = 'intake'
+		  AND e.created_at >= $3
+	`, workspaceID, domain.EventTypeCreated, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count intake submissions: %w", err)
+	}
+	return count, nil
+}
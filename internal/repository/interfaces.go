@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// The services and handlers depend on the interfaces below rather than on the
+// pgx implementations, so their logic can be unit-tested against fakes. Methods
+// taking a pgx.Tx run within the caller's transaction.
+
+// TaskRepository stores tasks. PgTaskRepository implements it.
+type TaskRepository interface {
+	Create(ctx context.Context, tx pgx.Tx, task *domain.Task) (*domain.Task, error)
+	GetByID(ctx context.Context, taskID string) (*domain.Task, error)
+	GetIDByKey(ctx context.Context, workspaceSlug string, number int) (string, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID string) (*domain.Task, error)
+	GetBlockedByTasks(ctx context.Context, blockedBy []string) ([]*domain.Task, error)
+	GetDependentTasks(ctx context.Context, taskIDs []string) ([]*domain.Task, error)
+	FindDependentIDs(ctx context.Context, tx pgx.Tx, blockerID string) ([]string, error)
+	CountUnresolvedBlockers(ctx context.Context, tx pgx.Tx, taskID, exceptID string) (int, error)
+	List(ctx context.Context, filters TaskListFilters) ([]TaskListResult, int, error)
+	Facets(ctx context.Context, filters TaskListFilters) (*TaskFacets, error)
+
+	// Updates
+	UpdateStatus(
+		ctx context.Context,
+		tx pgx.Tx,
+		taskID string,
+		oldStatus domain.TaskStatus,
+		newStatus domain.TaskStatus,
+		assigneeID *string,
+		statusDeadlineAt *time.Time,
+		artefact *string,
+	) error
+	UpdateText(ctx context.Context, tx pgx.Tx, taskID, title, description string) error
+	SetResult(ctx context.Context, tx pgx.Tx, taskID string, result map[string]any) error
+	SetExternalRef(ctx context.Context, tx pgx.Tx, taskID string, ref *domain.ExternalRef) error
+	SetPriority(ctx context.Context, tx pgx.Tx, taskID string, priority domain.TaskPriority) error
+	SetInheritedPriority(ctx context.Context, tx pgx.Tx, taskID string, priority *domain.TaskPriority) error
+	SetLabels(ctx context.Context, tx pgx.Tx, taskID string, labels []string) error
+	SetArchived(ctx context.Context, tx pgx.Tx, taskID string, archived bool) error
+	Transfer(ctx context.Context, tx pgx.Tx, taskID string, transfer TaskTransfer) error
+	RemoveBlocker(ctx context.Context, tx pgx.Tx, blockerID string) (int64, error)
+	IncrementAttempts(ctx context.Context, tx pgx.Tx, taskID string) (int, error)
+	HoldForHumanReview(ctx context.Context, tx pgx.Tx, taskID string) (bool, error)
+	ClearHumanReview(ctx context.Context, tx pgx.Tx, taskID string) error
+	SoftDelete(ctx context.Context, tx pgx.Tx, taskID string, deletedBy *string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// Revisions
+	CreateRevision(ctx context.Context, tx pgx.Tx, revision *domain.TaskRevision) error
+	LatestRevision(ctx context.Context, tx pgx.Tx, taskID string) (int, error)
+	ListRevisions(ctx context.Context, taskID string) ([]*domain.TaskRevision, error)
+
+	// Claiming and scheduled checks
+	FindClaimable(ctx context.Context, agent *domain.Agent, queue *string) (*domain.Task, error)
+	FindNextClaimable(ctx context.Context, tx pgx.Tx, agent *domain.Agent, queue *string) (*domain.Task, error)
+	FindAutoAssignable(ctx context.Context, workspaceID string) ([]*domain.Task, error)
+	FindExpiredDeadlines(ctx context.Context, after *DeadlineCursor, limit int) ([]*domain.Task, error)
+	FindDeadlineWarnings(ctx context.Context) ([]*domain.Task, error)
+	HasDeadlineWarning(ctx context.Context, tx pgx.Tx, taskID string, since time.Time) (bool, error)
+	FindAbandoned(ctx context.Context) ([]*domain.Task, error)
+	FindAging(ctx context.Context) ([]*domain.Task, error)
+	WaitingSince(ctx context.Context, tx pgx.Tx, taskID string) (time.Time, error)
+	FindAwaitingExternalForUpdate(ctx context.Context, tx pgx.Tx, workspaceID, system, externalID string) ([]*domain.Task, error)
+	FindPriorityInheritanceCandidates(ctx context.Context, tx pgx.Tx, workspaceID string) ([]string, error)
+	FindPriorityInheritanceSource(ctx context.Context, tx pgx.Tx, blockerID string) (*domain.Task, error)
+	GetPriorityInheritanceState(ctx context.Context, tx pgx.Tx, taskID string) (*PriorityInheritanceState, error)
+
+	// Statistics
+	CountTasksByWorkspace(ctx context.Context) (map[string]*WorkspaceTaskCounts, error)
+	GetAgentStats(ctx context.Context, filters StatsFilters) ([]AgentStatsResult, error)
+	GetAgentTaskSummary(ctx context.Context, agentID string) (*AgentTaskSummary, error)
+	GetWorkspaceStats(ctx context.Context, filters StatsFilters) (*WorkspaceStatsResult, error)
+	GetWorkspaceTaskSummary(ctx context.Context, workspaceID string) (*WorkspaceTaskSummary, error)
+	GetEventSeries(ctx context.Context, filters EventSeriesFilters) ([]SeriesPoint, error)
+	GetQueueDepth(ctx context.Context, workspaceID string, queue *string) (*QueueDepthResult, error)
+}
+
+// TaskEventRepository stores the event history of tasks. PgTaskEventRepository
+// implements it.
+type TaskEventRepository interface {
+	Create(ctx context.Context, tx pgx.Tx, event *domain.TaskEvent) error
+	GetByTaskID(ctx context.Context, taskID string) ([]*domain.TaskEvent, error)
+	GetLastStatusChange(ctx context.Context, tx pgx.Tx, taskID string, newStatus domain.TaskStatus) (*domain.TaskEvent, error)
+	GetByTaskIDWithActors(ctx context.Context, taskID string) ([]TaskEventWithActor, error)
+	ListByTaskIDWithActors(ctx context.Context, filters TaskEventListFilters) ([]TaskEventWithActor, int, error)
+	CreateMentions(ctx context.Context, tx pgx.Tx, eventID string, agentIDs []string) error
+	GetComment(ctx context.Context, tx pgx.Tx, taskID, eventID string) (*domain.TaskEvent, error)
+	GetCommentForUpdate(ctx context.Context, tx pgx.Tx, taskID, eventID string) (*domain.TaskEvent, error)
+	UpdateComment(ctx context.Context, tx pgx.Tx, eventID, comment string, redact bool) (*domain.TaskEvent, error)
+	CountClaimsSince(ctx context.Context, agentID string, since time.Time) (int, error)
+	LastClaimantID(ctx context.Context, workspaceID string) (*string, error)
+	ListenChanges(ctx context.Context, ready func(), handle func(*domain.TaskChange)) error
+	PruneBefore(ctx context.Context, workspaceID string, before time.Time, limit int) (int64, error)
+}
+
+// AgentRepository stores agents. PgAgentRepository implements it.
+type AgentRepository interface {
+	GetByID(ctx context.Context, agentID string) (*domain.Agent, error)
+	GetByToken(ctx context.Context, token string) (*domain.Agent, error)
+	ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Agent, error)
+	ListWorkloads(ctx context.Context, workspaceID string) ([]*domain.AgentWorkload, error)
+	Create(ctx context.Context, tx pgx.Tx, agent *domain.Agent) error
+	CountIdleAgents(ctx context.Context, workspaceID, exceptAgentID string, required []string) (int, error)
+	Heartbeat(ctx context.Context, agentID string) (time.Time, error)
+	SetCapabilities(ctx context.Context, agentID string, capabilities []string) error
+	MarkAutoAssigned(ctx context.Context, tx pgx.Tx, agentID string) error
+	RevokeByWorkspace(ctx context.Context, tx pgx.Tx, workspaceID string) (int64, error)
+}
+
+// WorkspaceRepository stores workspaces and their settings. PgWorkspaceRepository
+// implements it.
+type WorkspaceRepository interface {
+	GetByID(ctx context.Context, workspaceID string) (*domain.Workspace, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.Workspace, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Workspace, error)
+	List(ctx context.Context) ([]*domain.Workspace, error)
+	Create(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace) error
+	ListWithAutoAssign(ctx context.Context) ([]*domain.Workspace, error)
+
+	// Settings
+	SetAgentStaleAfter(ctx context.Context, workspaceID string, seconds int) error
+	SetAutoAssignStrategy(ctx context.Context, workspaceID string, strategy domain.AutoAssignStrategy) error
+	SetAutoUnblock(ctx context.Context, workspaceID string, enabled bool) error
+	SetClaimFairness(ctx context.Context, workspaceID string, fairness domain.ClaimFairness) error
+	SetDeadlineExpiry(ctx context.Context, workspaceID string, expiry map[string]string) error
+	SetDeadlineWarning(ctx context.Context, workspaceID string, percent int) error
+	SetDoneValidation(ctx context.Context, workspaceID string, hook *domain.DoneValidationHook) error
+	SetEventBroker(ctx context.Context, workspaceID string, topic *domain.EventBrokerTopic) error
+	SetEventWebhook(ctx context.Context, workspaceID string, hook *domain.EventWebhook) error
+	SetMaxAttempts(ctx context.Context, workspaceID string, limit int) error
+	SetPriorityAging(ctx context.Context, workspaceID string, aging *domain.PriorityAging) error
+	SetPriorityInheritance(ctx context.Context, tx pgx.Tx, workspaceID string, enabled bool) error
+	SetStatusDeadlines(ctx context.Context, workspaceID string, deadlines map[string]int) error
+
+	// Archiving, deletion and sandboxes
+	Archive(ctx context.Context, tx pgx.Tx, workspaceID string) (time.Time, error)
+	Delete(ctx context.Context, tx pgx.Tx, workspaceID string) error
+	DeleteTaskBatch(ctx context.Context, workspaceID string, limit int) (int64, error)
+	CloneIntoSandbox(ctx context.Context, tx pgx.Tx, sourceID, sandboxID string, tokens map[string]string) (*SandboxClone, error)
+	ListExpiredSandboxes(ctx context.Context, now time.Time) ([]*domain.Workspace, error)
+}
+
+// ChecklistRepository stores the checklist items of tasks.
+// PgChecklistRepository implements it.
+type ChecklistRepository interface {
+	Create(ctx context.Context, tx pgx.Tx, item *domain.ChecklistItem) error
+	ListByTaskID(ctx context.Context, taskID string) ([]*domain.ChecklistItem, error)
+	GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID, itemID string) (*domain.ChecklistItem, error)
+	Claim(ctx context.Context, tx pgx.Tx, itemID, agentID string) error
+	Complete(ctx context.Context, tx pgx.Tx, itemID, agentID string) error
+	ReleaseClaims(ctx context.Context, tx pgx.Tx, taskID string) (int64, error)
+}
+
+// QueueRepository stores the task queues of workspaces. PgQueueRepository
+// implements it.
+type QueueRepository interface {
+	Create(ctx context.Context, queue *domain.Queue) error
+	GetByName(ctx context.Context, workspaceID, name string) (*domain.Queue, error)
+	ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Queue, error)
+	Update(ctx context.Context, workspaceID, name string, newName, description *string) (*domain.Queue, error)
+	Delete(ctx context.Context, workspaceID, name string) error
+}
+
+// LabelRepository stores the label registries of workspaces. PgLabelRepository
+// implements it.
+type LabelRepository interface {
+	Upsert(ctx context.Context, workspaceID, name string, color, description *string) (*domain.Label, bool, error)
+	GetByName(ctx context.Context, workspaceID, name string) (*domain.Label, error)
+	ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Label, error)
+	Register(ctx context.Context, tx pgx.Tx, workspaceID, name string) error
+	LockRegistered(ctx context.Context, tx pgx.Tx, workspaceID string, names []string) ([]string, error)
+	LockByName(ctx context.Context, tx pgx.Tx, workspaceID, name string) (*domain.Label, error)
+	Update(ctx context.Context, tx pgx.Tx, workspaceID, name string, newName, color, description *string) error
+	Delete(ctx context.Context, tx pgx.Tx, workspaceID, name string) error
+	RewriteTaskLabels(ctx context.Context, tx pgx.Tx, workspaceID, from string, to *string) (int64, error)
+}
+
+// EscalationRepository stores escalation routes and the notifications they
+// produce. PgEscalationRepository implements it.
+type EscalationRepository interface {
+	ListRoutes(ctx context.Context, workspaceID string) ([]*domain.EscalationRoute, error)
+	ReplaceRoutes(ctx context.Context, tx pgx.Tx, workspaceID string, routes []*domain.EscalationRoute) error
+	CreateNotification(ctx context.Context, tx pgx.Tx, notification *domain.EscalationNotification) error
+	ListAgentNotifications(ctx context.Context, agentID string, limit, offset int) ([]*domain.EscalationNotification, int, error)
+	LockNextPendingDelivery(ctx context.Context, tx pgx.Tx, now time.Time) (*domain.EscalationNotification, error)
+	RecordDelivery(ctx context.Context, tx pgx.Tx, notificationID string, now time.Time, deliveryErr *string, nextAttemptAt *time.Time) error
+}
+
+// WebhookSecretRepository stores the webhook signing secrets of workspaces.
+// PgWebhookSecretRepository implements it.
+type WebhookSecretRepository interface {
+	GetByWorkspace(ctx context.Context, workspaceID string) (*domain.WebhookSecret, error)
+	GetByWorkspaceForUpdate(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.WebhookSecret, error)
+	Save(ctx context.Context, tx pgx.Tx, secret *domain.WebhookSecret) error
+	ClearPrevious(ctx context.Context, tx pgx.Tx, workspaceID string) error
+}
+
+var (
+	_ TaskRepository          = (*PgTaskRepository)(nil)
+	_ TaskEventRepository     = (*PgTaskEventRepository)(nil)
+	_ AgentRepository         = (*PgAgentRepository)(nil)
+	_ WorkspaceRepository     = (*PgWorkspaceRepository)(nil)
+	_ ChecklistRepository     = (*PgChecklistRepository)(nil)
+	_ QueueRepository         = (*PgQueueRepository)(nil)
+	_ LabelRepository         = (*PgLabelRepository)(nil)
+	_ EscalationRepository    = (*PgEscalationRepository)(nil)
+	_ WebhookSecretRepository = (*PgWebhookSecretRepository)(nil)
+)
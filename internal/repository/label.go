@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// labelUsageCount counts the tasks carrying a label; @> lets it use the GIN index on tasks.labels.
+const labelUsageCount = "(SELECT COUNT(*) FROM tasks t WHERE t.workspace_id = labels.workspace_id AND t.labels @> ARRAY[labels.name]::text[] AND t.deleted_at IS NULL)"
+
+// labelColumns is the shared list of columns for label queries.
+var labelColumns = []string{
+	"labels.id", "labels.workspace_id", "labels.name", "labels.color", "labels.description",
+	labelUsageCount, "labels.created_at", "labels.updated_at",
+}
+
+// PgLabelRepository handles database operations for the label registry.
+type PgLabelRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLabelRepository creates a new PgLabelRepository.
+func NewLabelRepository(pool *pgxpool.Pool) *PgLabelRepository {
+	return &PgLabelRepository{pool: pool}
+}
+
+// scanLabel scans a single row into a Label struct.
+func scanLabel(row pgx.Row, extra ...any) (*domain.Label, error) {
+	var label domain.Label
+	dest := []any{
+		&label.ID,
+		&label.WorkspaceID,
+		&label.Name,
+		&label.Color,
+		&label.Description,
+		&label.UsageCount,
+		&label.CreatedAt,
+		&label.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLabelNotFound
+		}
+		return nil, fmt.Errorf("scan label: %w", err)
+	}
+	return &label, nil
+}
+
+// Upsert registers a label or, if it exists, updates the given fields; nil fields keep
+// their current value (or the default for a new label). Repeating the same call is a no-op.
+// Reports whether the label was created.
+func (r *PgLabelRepository) Upsert(ctx context.Context, workspaceID, name string, color, description *string) (*domain.Label, bool, error) {
+	insertColor, colorExpr := domain.DefaultLabelColor, "labels.color"
+	if color != nil {
+		insertColor, colorExpr = *color, "EXCLUDED.color"
+	}
+	insertDescription, descriptionExpr := "", "labels.description"
+	if description != nil {
+		insertDescription, descriptionExpr = *description, "EXCLUDED.description"
+	}
+
+	query, args, err := psql.
+		Insert("labels").
+		Columns("workspace_id", "name", "color", "description").
+		Values(workspaceID, name, insertColor, insertDescription).
+		Suffix(fmt.Sprintf(`ON CONFLICT (workspace_id, name) DO UPDATE SET
+			color = %[1]s,
+			description = %[2]s,
+			updated_at = CASE WHEN (labels.color, labels.description) IS DISTINCT FROM (%[1]s, %[2]s)
+				THEN NOW() ELSE labels.updated_at END
+			RETURNING %[3]s, (xmax = 0)`, colorExpr, descriptionExpr, strings.Join(labelColumns, ", "))).
+		ToSql()
+	if err != nil {
+		return nil, false, fmt.Errorf("build Upsert query for label %s: %w", name, err)
+	}
+
+	var created bool
+	label, err := scanLabel(r.pool.QueryRow(ctx, query, args...), &created)
+	if err != nil {
+		return nil, false, fmt.Errorf("upsert label: %w", err)
+	}
+
+	return label, created, nil
+}
+
+// GetByName retrieves a label of a workspace with its usage count.
+func (r *PgLabelRepository) GetByName(ctx context.Context, workspaceID, name string) (*domain.Label, error) {
+	query, args, err := psql.
+		Select(labelColumns...).
+		From("labels").
+		Where(sq.Eq{"labels.workspace_id": workspaceID, "labels.name": name}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByName query for label %s: %w", name, err)
+	}
+
+	return scanLabel(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByWorkspace returns all labels of a workspace with usage counts, ordered by name.
+func (r *PgLabelRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Label, error) {
+	query, args, err := psql.
+		Select(labelColumns...).
+		From("labels").
+		Where(sq.Eq{"labels.workspace_id": workspaceID}).
+		OrderBy("labels.name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for labels: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []*domain.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// Register makes sure a label of a workspace exists (within transaction),
+// creating it with the default color if it doesn't; an existing label is left
+// as it is.
+func (r *PgLabelRepository) Register(ctx context.Context, tx pgx.Tx, workspaceID, name string) error {
+	query, args, err := psql.
+		Insert("labels").
+		Columns("workspace_id", "name", "color", "description").
+		Values(workspaceID, name, domain.DefaultLabelColor, "").
+		Suffix("ON CONFLICT (workspace_id, name) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Register query for label %s: %w", name, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("register label: %w", err)
+	}
+	return nil
+}
+
+// LockRegistered share-locks the given labels of a workspace (within transaction) so they
+// cannot be renamed or deleted concurrently, and returns the names that are not registered.
+func (r *PgLabelRepository) LockRegistered(ctx context.Context, tx pgx.Tx, workspaceID string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := psql.
+		Select("name").
+		From("labels").
+		Where(sq.Eq{"workspace_id": workspaceID, "name": names}).
+		Suffix("FOR SHARE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockRegistered query for labels: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query registered labels: %w", err)
+	}
+	registered, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("collect registered labels: %w", err)
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !slices.Contains(registered, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// LockByName locks a label of a workspace for update (within transaction).
+func (r *PgLabelRepository) LockByName(ctx context.Context, tx pgx.Tx, workspaceID, name string) (*domain.Label, error) {
+	query, args, err := psql.
+		Select(labelColumns...).
+		From("labels").
+		Where(sq.Eq{"labels.workspace_id": workspaceID, "labels.name": name}).
+		Suffix("FOR UPDATE OF labels").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockByName query for label %s: %w", name, err)
+	}
+
+	return scanLabel(tx.QueryRow(ctx, query, args...))
+}
+
+// Update renames a label and/or changes its color and description (within transaction).
+// Nil fields are left unchanged. Tasks are not touched; see RewriteTaskLabels.
+func (r *PgLabelRepository) Update(ctx context.Context, tx pgx.Tx, workspaceID, name string, newName, color, description *string) error {
+	qb := psql.
+		Update("labels").
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"workspace_id": workspaceID, "name": name})
+	if newName != nil {
+		qb = qb.Set("name", *newName)
+	}
+	if color != nil {
+		qb = qb.Set("color", *color)
+	}
+	if description != nil {
+		qb = qb.Set("description", *description)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build Update query for label %s: %w", name, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrLabelExists, *newName)
+		}
+		return fmt.Errorf("update label: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLabelNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a label from the registry (within transaction).
+func (r *PgLabelRepository) Delete(ctx context.Context, tx pgx.Tx, workspaceID, name string) error {
+	query, args, err := psql.
+		Delete("labels").
+		Where(sq.Eq{"workspace_id": workspaceID, "name": name}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for label %s: %w", name, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete label: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLabelNotFound
+	}
+
+	return nil
+}
+
+// RewriteTaskLabels replaces label from with to on every task of the workspace, keeping
+// each task's labels sorted and unique (within transaction). A nil to removes the label.
+// Returns the number of tasks changed.
+func (r *PgLabelRepository) RewriteTaskLabels(ctx context.Context, tx pgx.Tx, workspaceID, from string, to *string) (int64, error) {
+	query, args, err := psql.
+		Update("tasks").
+		// array_replace with NULL leaves a NULL element, which the WHERE drops
+		Set("labels", sq.Expr(
+			"ARRAY(SELECT DISTINCT l FROM unnest(array_replace(labels, ?::text, ?::text)) AS l WHERE l IS NOT NULL ORDER BY l)",
+			from, to,
+		)).
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Where(sq.Expr("labels @> ARRAY[?::text]", from)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build RewriteTaskLabels query for label %s: %w", from, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("rewrite task labels: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// messageColumns is the shared list of columns for task message queries.
+var messageColumns = []string{"id", "task_id", "sender_id", "recipient_id", "body", "read_at", "created_at"}
+
+// messageOfLiveTask skips messages (aliased m) about deleted tasks.
+const messageOfLiveTask = "EXISTS (SELECT 1 FROM tasks t WHERE t.id = m.task_id AND t.deleted_at IS NULL)"
+
+// MessageRepository handles database operations for direct messages between agents.
+type MessageRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewMessageRepository creates a new MessageRepository.
+func NewMessageRepository(pool *pgxpool.Pool) *MessageRepository {
+	return &MessageRepository{pool: pool}
+}
+
+// MessageFilters selects the messages an agent sent or received.
+type MessageFilters struct {
+	AgentID    string
+	TaskID     *string // Optional: only messages about this task
+	UnreadOnly bool    // only messages to the agent it has not acknowledged yet
+	Limit      int
+	Offset     int
+}
+
+// scanMessage scans a single row into a TaskMessage struct.
+func scanMessage(row pgx.Row) (*domain.TaskMessage, error) {
+	var message domain.TaskMessage
+	err := row.Scan(
+		&message.ID,
+		&message.TaskID,
+		&message.SenderID,
+		&message.RecipientID,
+		&message.Body,
+		&message.ReadAt,
+		&message.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("scan message: %w", err)
+	}
+	return &message, nil
+}
+
+// Create stores a message and sets its ID and creation time.
+func (r *MessageRepository) Create(ctx context.Context, message *domain.TaskMessage) error {
+	query, args, err := psql.
+		Insert("task_messages").
+		Columns("task_id", "sender_id", "recipient_id", "body").
+		Values(message.TaskID, message.SenderID, message.RecipientID, message.Body).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create message query: %w", err)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&message.ID, &message.CreatedAt); err != nil {
+		return fmt.Errorf("create message: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the messages matching filters, newest first, together with their
+// total count. Messages about deleted tasks are skipped.
+func (r *MessageRepository) List(ctx context.Context, filters MessageFilters) ([]*domain.TaskMessage, int, error) {
+	where := sq.And{sq.Expr(messageOfLiveTask)}
+	if filters.UnreadOnly {
+		where = append(where, sq.Eq{"m.recipient_id": filters.AgentID, "m.read_at": nil})
+	} else {
+		where = append(where, sq.Or{sq.Eq{"m.sender_id": filters.AgentID}, sq.Eq{"m.recipient_id": filters.AgentID}})
+	}
+	if filters.TaskID != nil {
+		where = append(where, sq.Eq{"m.task_id": *filters.TaskID})
+	}
+
+	columns := make([]string, len(messageColumns))
+	for i, column := range messageColumns {
+		columns[i] = "m." + column
+	}
+
+	query, args, err := psql.
+		Select(columns...).
+		From("task_messages m").
+		Where(where).
+		OrderBy("m.created_at DESC", "m.id DESC").
+		Limit(uint64(filters.Limit)).
+		Offset(uint64(filters.Offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build List messages query for agent %s: %w", filters.AgentID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*domain.TaskMessage{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	countQuery, countArgs, err := psql.
+		Select("COUNT(*)").
+		From("task_messages m").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build List messages count query for agent %s: %w", filters.AgentID, err)
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// CountUnread counts the messages to an agent it has not acknowledged yet.
+func (r *MessageRepository) CountUnread(ctx context.Context, agentID string) (int, error) {
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From("task_messages m").
+		Where(sq.Eq{"m.recipient_id": agentID, "m.read_at": nil}).
+		Where(messageOfLiveTask).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build CountUnread query for agent %s: %w", agentID, err)
+	}
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count unread messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead acknowledges a message on behalf of its recipient. Acknowledging it
+// again keeps the first read time. Returns ErrMessageNotFound if the message does
+// not exist, is not addressed to the recipient or is about a deleted task.
+func (r *MessageRepository) MarkRead(ctx context.Context, messageID, recipientID string) (*domain.TaskMessage, error) {
+	query, args, err := psql.
+		Update("task_messages m").
+		Set("read_at", sq.Expr("COALESCE(m.read_at, NOW())")).
+		Where(sq.Eq{"m.id": messageID, "m.recipient_id": recipientID}).
+		Where(messageOfLiveTask).
+		Suffix("RETURNING " + strings.Join(messageColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build MarkRead query for message %s: %w", messageID, err)
+	}
+
+	return scanMessage(r.pool.QueryRow(ctx, query, args...))
+}
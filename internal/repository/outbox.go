@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// outboxColumns are the event_outbox columns (aliased o) read by scanOutboxMessage.
+var outboxColumns = []string{
+	"o.id", "o.seq", "o.workspace_id", "o.task_id", "o.event_id", "o.target_type", "o.target", "o.status",
+	"o.attempts", "o.next_attempt_at", "o.last_error", "o.delivered_at", "o.created_at",
+}
+
+// outboxTargets are the workspace columns holding each outbox target and the
+// event types published to it.
+var outboxTargets = []struct {
+	targetType   domain.OutboxTargetType
+	targetColumn string
+	typesColumn  string
+}{
+	{domain.OutboxTargetWebhook, "event_webhook_url", "event_webhook_types"},
+	{domain.OutboxTargetBroker, "event_broker_topic", "event_broker_types"},
+}
+
+// enqueueOutbox queues the event for the event webhook and broker topic of its
+// task's workspace (within transaction), if the workspace publishes events of
+// its type there. The messages commit or roll back together with the event.
+func (r *PgTaskEventRepository) enqueueOutbox(ctx context.Context, tx pgx.Tx, event *domain.TaskEvent) error {
+	for _, target := range outboxTargets {
+		query, args, err := psql.
+			Insert("event_outbox").
+			Columns("workspace_id", "task_id", "event_id", "target_type", "target").
+			Select(psql.
+				Select("w.id", "t.id").
+				Column(sq.Expr("?::uuid", event.ID)).
+				Column(sq.Expr("?", string(target.targetType))).
+				Column("w." + target.targetColumn).
+				From("tasks t").
+				Join("workspaces w ON w.id = t.workspace_id").
+				Where(sq.Eq{"t.id": event.TaskID}).
+				Where("w." + target.targetColumn + " IS NOT NULL AND w.archived_at IS NULL").
+				Where(sq.Expr("(cardinality(w."+target.typesColumn+") = 0 OR ? = ANY(w."+target.typesColumn+"))", string(event.Type)))).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build enqueueOutbox query for task event %s: %w", event.ID, err)
+		}
+
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("enqueue %s outbox message: %w", target.targetType, err)
+		}
+	}
+
+	return nil
+}
+
+// OutboxRepository handles the delivery state of outbox messages.
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new OutboxRepository.
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+// scanOutboxMessage scans a row selected with outboxColumns, followed by
+// exportEventColumns when the message is read with its event.
+func scanOutboxMessage(row pgx.Row, withEvent bool) (*domain.OutboxMessage, error) {
+	var message domain.OutboxMessage
+	dest := []any{
+		&message.ID,
+		&message.Seq,
+		&message.WorkspaceID,
+		&message.TaskID,
+		&message.EventID,
+		&message.TargetType,
+		&message.Target,
+		&message.Status,
+		&message.Attempts,
+		&message.NextAttemptAt,
+		&message.LastError,
+		&message.DeliveredAt,
+		&message.CreatedAt,
+	}
+
+	var event domain.TaskEvent
+	var traceParent, traceState *string
+	if withEvent {
+		dest = append(dest,
+			&event.ID,
+			&event.TaskID,
+			&event.ActorID,
+			&event.Type,
+			&event.OldStatus,
+			&event.NewStatus,
+			&event.Comment,
+			&event.Data,
+			&traceParent,
+			&traceState,
+			&event.CreatedAt,
+			&event.ReplyToEventID,
+			&event.ThreadID,
+		)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOutboxMessageNotFound
+		}
+		return nil, fmt.Errorf("scan outbox message: %w", err)
+	}
+
+	if withEvent {
+		event.Trace = toTraceContext(traceParent, traceState)
+		message.Event = &event
+	}
+
+	return &message, nil
+}
+
+// LockNextPending locks the outbox message to one of targetTypes that has been
+// due for delivery the longest at now, with its event. A message waits while an
+// earlier message of the same task and target type is pending, so each task's
+// events arrive in order. Rows locked by concurrent schedulers are skipped.
+// Returns ErrOutboxMessageNotFound if nothing is due.
+func (r *OutboxRepository) LockNextPending(ctx context.Context, tx pgx.Tx, now time.Time, targetTypes []domain.OutboxTargetType) (*domain.OutboxMessage, error) {
+	query, args, err := psql.
+		Select(append(append([]string{}, outboxColumns...), exportEventColumns...)...).
+		From("event_outbox o").
+		Join("task_events te ON te.id = o.event_id").
+		Where(sq.Eq{"o.status": domain.OutboxPending, "o.target_type": targetTypes}).
+		Where(sq.LtOrEq{"o.next_attempt_at": now}).
+		Where(sq.Expr(`NOT EXISTS (
+			SELECT 1 FROM event_outbox p
+			WHERE p.task_id = o.task_id AND p.target_type = o.target_type AND p.status = ? AND p.seq < o.seq
+		)`, domain.OutboxPending)).
+		OrderBy("o.next_attempt_at ASC", "o.seq ASC").
+		Limit(1).
+		Suffix("FOR UPDATE OF o SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockNextPending query: %w", err)
+	}
+
+	return scanOutboxMessage(tx.QueryRow(ctx, query, args...), true)
+}
+
+// RecordDelivery stores the outcome of a delivery attempt (within transaction).
+// deliveryErr is nil when the message was delivered; a failed message stays
+// pending until nextAttemptAt, or is marked failed when nextAttemptAt is nil.
+func (r *OutboxRepository) RecordDelivery(
+	ctx context.Context,
+	tx pgx.Tx,
+	messageID string,
+	now time.Time,
+	deliveryErr *string,
+	nextAttemptAt *time.Time,
+) error {
+	qb := psql.
+		Update("event_outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", deliveryErr).
+		Where(sq.Eq{"id": messageID})
+
+	switch {
+	case deliveryErr == nil:
+		qb = qb.Set("status", domain.OutboxDelivered).Set("delivered_at", now)
+	case nextAttemptAt == nil:
+		qb = qb.Set("status", domain.OutboxFailed)
+	default:
+		qb = qb.Set("next_attempt_at", *nextAttemptAt)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build RecordDelivery query for outbox message %s: %w", messageID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("record outbox delivery: %w", err)
+	}
+
+	return nil
+}
+
+// List returns a workspace's outbox messages, newest first, optionally only
+// those with the given status, together with their total count.
+func (r *OutboxRepository) List(ctx context.Context, workspaceID string, status *domain.OutboxStatus, limit, offset int) ([]*domain.OutboxMessage, int, error) {
+	where := sq.Eq{"o.workspace_id": workspaceID}
+	if status != nil {
+		where["o.status"] = *status
+	}
+
+	query, args, err := psql.
+		Select(outboxColumns...).
+		From("event_outbox o").
+		Where(where).
+		OrderBy("o.seq DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build List query for outbox of workspace %s: %w", workspaceID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*domain.OutboxMessage{}
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	countQuery, countArgs, err := psql.
+		Select("COUNT(*)").
+		From("event_outbox o").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build List count query for outbox of workspace %s: %w", workspaceID, err)
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count outbox messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetByID retrieves an outbox message of a workspace.
+func (r *OutboxRepository) GetByID(ctx context.Context, workspaceID, messageID string) (*domain.OutboxMessage, error) {
+	query, args, err := psql.
+		Select(outboxColumns...).
+		From("event_outbox o").
+		Where(sq.Eq{"o.id": messageID, "o.workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByID query for outbox message %s: %w", messageID, err)
+	}
+
+	return scanOutboxMessage(r.pool.QueryRow(ctx, query, args...), false)
+}
+
+// Requeue makes a failed message pending again with a fresh set of attempts,
+// due at once. Returns ErrOutboxMessageNotFound unless the message has failed.
+func (r *OutboxRepository) Requeue(ctx context.Context, messageID string) error {
+	query, args, err := psql.
+		Update("event_outbox").
+		Set("status", domain.OutboxPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": messageID, "status": domain.OutboxFailed}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Requeue query for outbox message %s: %w", messageID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("requeue outbox message: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOutboxMessageNotFound
+	}
+
+	return nil
+}
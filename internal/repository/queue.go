@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// queueColumns is the shared list of columns for queue queries.
+var queueColumns = []string{"id", "workspace_id", "name", "description", "created_at"}
+
+// PostgreSQL error codes mapped to queue errors.
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+// PgQueueRepository handles database operations for task queues.
+type PgQueueRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQueueRepository creates a new PgQueueRepository.
+func NewQueueRepository(pool *pgxpool.Pool) *PgQueueRepository {
+	return &PgQueueRepository{pool: pool}
+}
+
+// scanQueue scans a single row into a Queue struct.
+func scanQueue(row pgx.Row) (*domain.Queue, error) {
+	var queue domain.Queue
+	err := row.Scan(
+		&queue.ID,
+		&queue.WorkspaceID,
+		&queue.Name,
+		&queue.Description,
+		&queue.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrQueueNotFound
+		}
+		return nil, fmt.Errorf("scan queue: %w", err)
+	}
+	return &queue, nil
+}
+
+// isPgError reports whether err is a PostgreSQL error with the given code.
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// Create inserts a new queue and populates ID and CreatedAt.
+func (r *PgQueueRepository) Create(ctx context.Context, queue *domain.Queue) error {
+	query, args, err := psql.
+		Insert("task_queues").
+		Columns("workspace_id", "name", "description").
+		Values(queue.WorkspaceID, queue.Name, queue.Description).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for queue: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&queue.ID, &queue.CreatedAt)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrQueueExists, queue.Name)
+		}
+		return fmt.Errorf("create queue: %w", err)
+	}
+
+	return nil
+}
+
+// GetByName retrieves a queue of a workspace by name.
+func (r *PgQueueRepository) GetByName(ctx context.Context, workspaceID, name string) (*domain.Queue, error) {
+	query, args, err := psql.
+		Select(queueColumns...).
+		From("task_queues").
+		Where(sq.Eq{"workspace_id": workspaceID, "name": name}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByName query for queue %s: %w", name, err)
+	}
+
+	return scanQueue(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByWorkspace returns all queues of a workspace ordered by name.
+func (r *PgQueueRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Queue, error) {
+	query, args, err := psql.
+		Select(queueColumns...).
+		From("task_queues").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for queues: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query queues: %w", err)
+	}
+	defer rows.Close()
+
+	queues := []*domain.Queue{}
+	for rows.Next() {
+		queue, err := scanQueue(rows)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate queues: %w", err)
+	}
+
+	return queues, nil
+}
+
+// Update renames a queue and/or changes its description. Tasks follow a rename.
+func (r *PgQueueRepository) Update(ctx context.Context, workspaceID, name string, newName, description *string) (*domain.Queue, error) {
+	qb := psql.
+		Update("task_queues").
+		Where(sq.Eq{"workspace_id": workspaceID, "name": name}).
+		Suffix("RETURNING " + strings.Join(queueColumns, ", "))
+	if newName != nil {
+		qb = qb.Set("name", *newName)
+	}
+	if description != nil {
+		qb = qb.Set("description", *description)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build Update query for queue %s: %w", name, err)
+	}
+
+	queue, err := scanQueue(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrQueueExists, *newName)
+		}
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// Delete removes an empty queue. Fails with ErrQueueNotEmpty if tasks still reference it.
+func (r *PgQueueRepository) Delete(ctx context.Context, workspaceID, name string) error {
+	query, args, err := psql.
+		Delete("task_queues").
+		Where(sq.Eq{"workspace_id": workspaceID, "name": name}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for queue %s: %w", name, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		if isPgError(err, pgForeignKeyViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrQueueNotEmpty, name)
+		}
+		return fmt.Errorf("delete queue: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrQueueNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// readTokenColumns is the shared list of columns for read token queries.
+var readTokenColumns = []string{
+	"id", "workspace_id", "name", "token_hash", "expires_at", "last_used_at", "revoked_at", "created_at",
+}
+
+// ReadTokenRepository handles database operations for workspace read tokens.
+type ReadTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReadTokenRepository creates a new ReadTokenRepository.
+func NewReadTokenRepository(pool *pgxpool.Pool) *ReadTokenRepository {
+	return &ReadTokenRepository{pool: pool}
+}
+
+// scanReadToken scans a single row into a ReadToken struct.
+func scanReadToken(row pgx.Row) (*domain.ReadToken, error) {
+	var token domain.ReadToken
+	err := row.Scan(
+		&token.ID,
+		&token.WorkspaceID,
+		&token.Name,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReadTokenNotFound
+		}
+		return nil, fmt.Errorf("scan read token: %w", err)
+	}
+	return &token, nil
+}
+
+// Create inserts a new read token and populates ID and CreatedAt.
+func (r *ReadTokenRepository) Create(ctx context.Context, token *domain.ReadToken) error {
+	query, args, err := psql.
+		Insert("read_tokens").
+		Columns("workspace_id", "name", "token_hash", "expires_at").
+		Values(token.WorkspaceID, token.Name, token.TokenHash, token.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for read token: %w", err)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&token.ID, &token.CreatedAt); err != nil {
+		return fmt.Errorf("create read token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash finds a read token by the hash of its secret.
+func (r *ReadTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*domain.ReadToken, error) {
+	query, args, err := psql.
+		Select(readTokenColumns...).
+		From("read_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByTokenHash query for read token: %w", err)
+	}
+
+	return scanReadToken(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByWorkspace retrieves all read tokens of a workspace, newest first.
+func (r *ReadTokenRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.ReadToken, error) {
+	query, args, err := psql.
+		Select(readTokenColumns...).
+		From("read_tokens").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for read tokens: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query read tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.ReadToken, 0)
+	for rows.Next() {
+		token, err := scanReadToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke marks a read token as revoked. Revoking an already revoked token is a no-op.
+func (r *ReadTokenRepository) Revoke(ctx context.Context, tokenID string) error {
+	query, args, err := psql.
+		Update("read_tokens").
+		Set("revoked_at", sq.Expr("COALESCE(revoked_at, NOW())")).
+		Where(sq.Eq{"id": tokenID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Revoke query for read token %s: %w", tokenID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("revoke read token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReadTokenNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records usage of a read token.
+// Writes at most once per minute per token to keep hot dashboards cheap.
+func (r *ReadTokenRepository) TouchLastUsed(ctx context.Context, tokenID string) error {
+	query, args, err := psql.
+		Update("read_tokens").
+		Set("last_used_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": tokenID}).
+		Where("(last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build TouchLastUsed query for read token %s: %w", tokenID, err)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("touch read token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeByWorkspace revokes every unrevoked read token of a workspace (within transaction).
+func (r *ReadTokenRepository) RevokeByWorkspace(ctx context.Context, tx pgx.Tx, workspaceID string) (int64, error) {
+	query, args, err := psql.
+		Update("read_tokens").
+		Set("revoked_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"workspace_id": workspaceID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build RevokeByWorkspace query for read tokens of workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("revoke workspace read tokens: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// reportColumns is the shared list of columns for report queries.
+var reportColumns = []string{
+	"id", "workspace_id", "creator_id", "name", "kind", "format", "period", "cron_expr", "timezone",
+	"target_type", "target", "enabled", "next_run_at", "last_run_at", "last_error", "created_at", "updated_at",
+}
+
+// ReportRepository handles database operations for scheduled reports.
+type ReportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReportRepository creates a new ReportRepository.
+func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
+	return &ReportRepository{pool: pool}
+}
+
+// scanReport scans a single row into a Report struct.
+func scanReport(row pgx.Row) (*domain.Report, error) {
+	var report domain.Report
+	err := row.Scan(
+		&report.ID,
+		&report.WorkspaceID,
+		&report.CreatorID,
+		&report.Name,
+		&report.Kind,
+		&report.Format,
+		&report.Period,
+		&report.CronExpr,
+		&report.Timezone,
+		&report.TargetType,
+		&report.Target,
+		&report.Enabled,
+		&report.NextRunAt,
+		&report.LastRunAt,
+		&report.LastError,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("scan report: %w", err)
+	}
+	return &report, nil
+}
+
+// Create inserts a new report and populates ID, CreatedAt and UpdatedAt.
+func (r *ReportRepository) Create(ctx context.Context, report *domain.Report) error {
+	query, args, err := psql.
+		Insert("reports").
+		Columns(
+			"workspace_id", "creator_id", "name", "kind", "format", "period", "cron_expr", "timezone",
+			"target_type", "target", "enabled", "next_run_at",
+		).
+		Values(
+			report.WorkspaceID,
+			report.CreatorID,
+			report.Name,
+			report.Kind,
+			report.Format,
+			report.Period,
+			report.CronExpr,
+			report.Timezone,
+			report.TargetType,
+			report.Target,
+			report.Enabled,
+			report.NextRunAt,
+		).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for report: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&report.ID, &report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrReportExists, report.Name)
+		}
+		return fmt.Errorf("create report: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a report of a workspace.
+func (r *ReportRepository) GetByID(ctx context.Context, workspaceID, reportID string) (*domain.Report, error) {
+	query, args, err := psql.
+		Select(reportColumns...).
+		From("reports").
+		Where(sq.Eq{"id": reportID, "workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByID query for report %s: %w", reportID, err)
+	}
+
+	return scanReport(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByWorkspace returns all reports of a workspace ordered by name.
+func (r *ReportRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Report, error) {
+	query, args, err := psql.
+		Select(reportColumns...).
+		From("reports").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for reports: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*domain.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Update saves the editable fields of a report and refreshes UpdatedAt.
+func (r *ReportRepository) Update(ctx context.Context, report *domain.Report) error {
+	query, args, err := psql.
+		Update("reports").
+		Set("name", report.Name).
+		Set("kind", report.Kind).
+		Set("format", report.Format).
+		Set("period", report.Period).
+		Set("cron_expr", report.CronExpr).
+		Set("timezone", report.Timezone).
+		Set("target_type", report.TargetType).
+		Set("target", report.Target).
+		Set("enabled", report.Enabled).
+		Set("next_run_at", report.NextRunAt).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": report.ID, "workspace_id": report.WorkspaceID}).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Update query for report %s: %w", report.ID, err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&report.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrReportNotFound
+		}
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrReportExists, report.Name)
+		}
+		return fmt.Errorf("update report: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a report.
+func (r *ReportRepository) Delete(ctx context.Context, workspaceID, reportID string) error {
+	query, args, err := psql.
+		Delete("reports").
+		Where(sq.Eq{"id": reportID, "workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for report %s: %w", reportID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete report: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReportNotFound
+	}
+
+	return nil
+}
+
+// LockNextDue locks the enabled report that has been due the longest at now.
+// Rows locked by concurrent schedulers are skipped, so several workers can run
+// without delivering a report twice. Returns ErrReportNotFound if nothing is due.
+func (r *ReportRepository) LockNextDue(ctx context.Context, tx pgx.Tx, now time.Time) (*domain.Report, error) {
+	query, args, err := psql.
+		Select(reportColumns...).
+		From("reports").
+		Where(sq.Eq{"enabled": true}).
+		Where(sq.LtOrEq{"next_run_at": now}).
+		OrderBy("next_run_at ASC").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockNextDue query: %w", err)
+	}
+
+	return scanReport(tx.QueryRow(ctx, query, args...))
+}
+
+// RecordRun stores the outcome of a run and the time of the next one (within transaction).
+// runErr is nil when the report was delivered.
+func (r *ReportRepository) RecordRun(
+	ctx context.Context,
+	tx pgx.Tx,
+	reportID string,
+	runAt, nextRunAt time.Time,
+	runErr *string,
+) error {
+	query, args, err := psql.
+		Update("reports").
+		Set("last_run_at", runAt).
+		Set("next_run_at", nextRunAt).
+		Set("last_error", runErr).
+		Where(sq.Eq{"id": reportID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build RecordRun query for report %s: %w", reportID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("record report run: %w", err)
+	}
+
+	return nil
+}
+
+// Disable turns a report off with the reason stored as its last error (within transaction).
+func (r *ReportRepository) Disable(ctx context.Context, tx pgx.Tx, reportID, reason string) error {
+	query, args, err := psql.
+		Update("reports").
+		Set("enabled", false).
+		Set("last_error", strings.TrimSpace(reason)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": reportID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Disable query for report %s: %w", reportID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("disable report: %w", err)
+	}
+
+	return nil
+}
+
+// DisableByWorkspace turns off every enabled report of a workspace (within transaction).
+func (r *ReportRepository) DisableByWorkspace(ctx context.Context, tx pgx.Tx, workspaceID, reason string) (int64, error) {
+	query, args, err := psql.
+		Update("reports").
+		Set("enabled", false).
+		Set("last_error", strings.TrimSpace(reason)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"workspace_id": workspaceID, "enabled": true}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build DisableByWorkspace query for reports of workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("disable workspace reports: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
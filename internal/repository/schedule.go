@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// scheduleColumns is the shared list of columns for schedule queries.
+var scheduleColumns = []string{
+	"id", "workspace_id", "creator_id", "name", "cron_expr", "timezone", "task_template",
+	"enabled", "next_run_at", "last_run_at", "last_task_id", "last_error", "created_at", "updated_at",
+}
+
+// scheduleTemplate is the JSONB encoding of domain.TaskTemplate.
+type scheduleTemplate struct {
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	Priority             string   `json:"priority"`
+	Visibility           string   `json:"visibility"`
+	AssigneeID           *string  `json:"assignee_id,omitempty"`
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+	Queue                *string  `json:"queue,omitempty"`
+}
+
+func toScheduleTemplate(t domain.TaskTemplate) scheduleTemplate {
+	return scheduleTemplate{
+		Title:                t.Title,
+		Description:          t.Description,
+		Priority:             string(t.Priority),
+		Visibility:           string(t.Visibility),
+		AssigneeID:           t.AssigneeID,
+		RequiredCapabilities: t.RequiredCapabilities,
+		Queue:                t.Queue,
+	}
+}
+
+func (t scheduleTemplate) toDomain() domain.TaskTemplate {
+	capabilities := t.RequiredCapabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	return domain.TaskTemplate{
+		Title:                t.Title,
+		Description:          t.Description,
+		Priority:             domain.TaskPriority(t.Priority),
+		Visibility:           domain.TaskVisibility(t.Visibility),
+		AssigneeID:           t.AssigneeID,
+		RequiredCapabilities: capabilities,
+		Queue:                t.Queue,
+	}
+}
+
+// ScheduleRepository handles database operations for recurring task schedules.
+type ScheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewScheduleRepository creates a new ScheduleRepository.
+func NewScheduleRepository(pool *pgxpool.Pool) *ScheduleRepository {
+	return &ScheduleRepository{pool: pool}
+}
+
+// scanSchedule scans a single row into a Schedule struct.
+func scanSchedule(row pgx.Row) (*domain.Schedule, error) {
+	var schedule domain.Schedule
+	var template scheduleTemplate
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.WorkspaceID,
+		&schedule.CreatorID,
+		&schedule.Name,
+		&schedule.CronExpr,
+		&schedule.Timezone,
+		&template,
+		&schedule.Enabled,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.LastTaskID,
+		&schedule.LastError,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("scan schedule: %w", err)
+	}
+	schedule.Template = template.toDomain()
+	return &schedule, nil
+}
+
+// Create inserts a new schedule and populates ID, CreatedAt and UpdatedAt.
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	query, args, err := psql.
+		Insert("schedules").
+		Columns("workspace_id", "creator_id", "name", "cron_expr", "timezone", "task_template", "enabled", "next_run_at").
+		Values(
+			schedule.WorkspaceID,
+			schedule.CreatorID,
+			schedule.Name,
+			schedule.CronExpr,
+			schedule.Timezone,
+			toScheduleTemplate(schedule.Template),
+			schedule.Enabled,
+			schedule.NextRunAt,
+		).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for schedule: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrScheduleExists, schedule.Name)
+		}
+		return fmt.Errorf("create schedule: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a schedule of a workspace.
+func (r *ScheduleRepository) GetByID(ctx context.Context, workspaceID, scheduleID string) (*domain.Schedule, error) {
+	query, args, err := psql.
+		Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Eq{"id": scheduleID, "workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByID query for schedule %s: %w", scheduleID, err)
+	}
+
+	return scanSchedule(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByWorkspace returns all schedules of a workspace ordered by name.
+func (r *ScheduleRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.Schedule, error) {
+	query, args, err := psql.
+		Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByWorkspace query for schedules: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*domain.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// Update saves the editable fields of a schedule and refreshes UpdatedAt.
+func (r *ScheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	query, args, err := psql.
+		Update("schedules").
+		Set("name", schedule.Name).
+		Set("cron_expr", schedule.CronExpr).
+		Set("timezone", schedule.Timezone).
+		Set("task_template", toScheduleTemplate(schedule.Template)).
+		Set("enabled", schedule.Enabled).
+		Set("next_run_at", schedule.NextRunAt).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": schedule.ID, "workspace_id": schedule.WorkspaceID}).
+		Suffix("RETURNING updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Update query for schedule %s: %w", schedule.ID, err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&schedule.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrScheduleNotFound
+		}
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrScheduleExists, schedule.Name)
+		}
+		return fmt.Errorf("update schedule: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a schedule. Tasks it already created are kept.
+func (r *ScheduleRepository) Delete(ctx context.Context, workspaceID, scheduleID string) error {
+	query, args, err := psql.
+		Delete("schedules").
+		Where(sq.Eq{"id": scheduleID, "workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for schedule %s: %w", scheduleID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete schedule: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// LockNextDue locks the enabled schedule that has been due the longest at now.
+// Rows locked by concurrent schedulers are skipped, so several workers can run.
+// Returns ErrScheduleNotFound if nothing is due.
+func (r *ScheduleRepository) LockNextDue(ctx context.Context, tx pgx.Tx, now time.Time) (*domain.Schedule, error) {
+	query, args, err := psql.
+		Select(scheduleColumns...).
+		From("schedules").
+		Where(sq.Eq{"enabled": true}).
+		Where(sq.LtOrEq{"next_run_at": now}).
+		OrderBy("next_run_at ASC").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build LockNextDue query: %w", err)
+	}
+
+	return scanSchedule(tx.QueryRow(ctx, query, args...))
+}
+
+// RecordRun stores the outcome of a run and the time of the next one (within transaction).
+// taskID is nil and runErr set when the run created no task.
+func (r *ScheduleRepository) RecordRun(
+	ctx context.Context,
+	tx pgx.Tx,
+	scheduleID string,
+	runAt, nextRunAt time.Time,
+	taskID *string,
+	runErr *string,
+) error {
+	update := psql.
+		Update("schedules").
+		Set("last_run_at", runAt).
+		Set("next_run_at", nextRunAt).
+		Set("last_error", runErr).
+		Where(sq.Eq{"id": scheduleID})
+	if taskID != nil {
+		update = update.Set("last_task_id", *taskID)
+	}
+
+	query, args, err := update.ToSql()
+	if err != nil {
+		return fmt.Errorf("build RecordRun query for schedule %s: %w", scheduleID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("record schedule run: %w", err)
+	}
+
+	return nil
+}
+
+// Disable turns a schedule off with the reason stored as its last error (within transaction).
+func (r *ScheduleRepository) Disable(ctx context.Context, tx pgx.Tx, scheduleID, reason string) error {
+	query, args, err := psql.
+		Update("schedules").
+		Set("enabled", false).
+		Set("last_error", strings.TrimSpace(reason)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": scheduleID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Disable query for schedule %s: %w", scheduleID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("disable schedule: %w", err)
+	}
+
+	return nil
+}
+
+// DisableByWorkspace turns off every enabled schedule of a workspace (within transaction).
+func (r *ScheduleRepository) DisableByWorkspace(ctx context.Context, tx pgx.Tx, workspaceID, reason string) (int64, error) {
+	query, args, err := psql.
+		Update("schedules").
+		Set("enabled", false).
+		Set("last_error", strings.TrimSpace(reason)).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"workspace_id": workspaceID, "enabled": true}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build DisableByWorkspace query for schedules of workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("disable workspace schedules: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/mtlprog/sloptask/internal/domain"
 )
 
@@ -24,6 +25,19 @@ type AgentStatsResult struct {
 	TasksCancelled  int
 	TasksStuckCount int
 	TasksInProgress int
+
+	// Averages over the agent's tasks completed in the period, 0 when there are none
+	AvgLeadTimeMinutes  float64
+	AvgCycleTimeMinutes float64
+
+	// Takeovers and escalations in the period, by the agent or of its tasks
+	TasksTakenOverFromAgent int
+	TasksTakenOverByAgent   int
+	EscalationsInitiated    int
+	EscalationsReceived     int
+
+	LastSeenAt *time.Time // last heartbeat, nil if none was sent
+	Stale      bool       // the last heartbeat is older than the workspace's window
 }
 
 // WorkspaceStatsResult holds overall workspace statistics.
@@ -32,20 +46,217 @@ type WorkspaceStatsResult struct {
 	TasksByStatus     map[string]int
 	OverdueCount      int
 	StuckCount        int
+	StaleAgentCount   int // active agents whose last heartbeat is older than the window
+
+	// Averages over tasks completed in the period, 0 when there are none
+	AvgLeadTimeMinutes  float64
+	AvgCycleTimeMinutes float64
+
+	// Waits on external systems, kept apart from agent-to-agent BLOCKED
+	AwaitingExternalCount    int
+	AwaitingExternalBySystem map[string]int
+}
+
+// DefaultQueueKey is the ByQueue key for tasks outside any named queue.
+// Queue names cannot start with '_', so it never collides with a real queue.
+const DefaultQueueKey = "_default"
+
+// QueueDepthResult holds the number of currently claimable tasks, broken down
+// for autoscalers. A task counts once per required capability.
+type QueueDepthResult struct {
+	Total        int
+	Unrestricted int // tasks without required capabilities
+	ByPriority   map[string]int
+	ByCapability map[string]int
+	ByQueue      map[string]int
+	OldestAt     *time.Time // creation time of the oldest claimable task
+}
+
+// AgentTaskSummary counts an agent's open (not DONE or CANCELLED) tasks.
+type AgentTaskSummary struct {
+	AssignedByStatus map[string]int // open tasks assigned to the agent
+	Overdue          int            // assigned tasks past their status deadline
+	CreatedOpen      int            // open tasks the agent created
+}
+
+// GetAgentTaskSummary counts the open tasks assigned to and created by an agent
+// in one statement.
+func (r *PgTaskRepository) GetAgentTaskSummary(ctx context.Context, agentID string) (*AgentTaskSummary, error) {
+	query := `
+		WITH open AS (
+			SELECT status, assignee_id, creator_id, status_deadline_at
+			FROM tasks
+			WHERE (assignee_id = $1 OR creator_id = $1)
+			  AND deleted_at IS NULL
+			  AND status NOT IN ($2, $3)
+		)
+		SELECT
+			COALESCE((SELECT jsonb_object_agg(status, n)
+				FROM (SELECT status, COUNT(*) AS n FROM open WHERE assignee_id = $1 GROUP BY status) s), '{}'),
+			(SELECT COUNT(*) FROM open WHERE assignee_id = $1 AND status_deadline_at < NOW()),
+			(SELECT COUNT(*) FROM open WHERE creator_id = $1)
+	`
+
+	summary := &AgentTaskSummary{}
+	err := r.pool.QueryRow(ctx, query, agentID, domain.TaskStatusDone, domain.TaskStatusCancelled).Scan(
+		&summary.AssignedByStatus,
+		&summary.Overdue,
+		&summary.CreatedOpen,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query agent task summary: %w", err)
+	}
+
+	return summary, nil
 }
 
-// GetAgentStats retrieves statistics for agents in a workspace.
-func (r *TaskRepository) GetAgentStats(ctx context.Context, filters StatsFilters) ([]AgentStatsResult, error) {
+// WorkspaceTaskSummary counts a workspace's open (not DONE or CANCELLED) tasks.
+type WorkspaceTaskSummary struct {
+	ByStatus  map[string]int
+	Overdue   int // tasks past their status deadline
+	Claimable int // tasks some agent could claim now, as counted by GetQueueDepth
+}
+
+// GetWorkspaceTaskSummary counts the open tasks of a workspace in one statement.
+func (r *PgTaskRepository) GetWorkspaceTaskSummary(ctx context.Context, workspaceID string) (*WorkspaceTaskSummary, error) {
 	query := `
+		WITH open AS (
+			SELECT status, status_deadline_at
+			FROM tasks
+			WHERE workspace_id = $1
+			  AND deleted_at IS NULL
+			  AND status NOT IN ($2, $3)
+		)
+		SELECT
+			COALESCE((SELECT jsonb_object_agg(status, n)
+				FROM (SELECT status, COUNT(*) AS n FROM open GROUP BY status) s), '{}'),
+			(SELECT COUNT(*) FROM open WHERE status_deadline_at < NOW()),
+			(SELECT COUNT(*) FROM tasks t WHERE t.workspace_id = $1 AND ` + claimableTaskCondition + `)
+	`
+
+	summary := &WorkspaceTaskSummary{}
+	err := r.pool.QueryRow(ctx, query, workspaceID, domain.TaskStatusDone, domain.TaskStatusCancelled).Scan(
+		&summary.ByStatus,
+		&summary.Overdue,
+		&summary.Claimable,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query workspace task summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// completedTasksCTE selects the DONE tasks of workspace $1 completed between $2
+// and $3 from their events: done_at is the last move to DONE (a reopened task
+// counts from its final completion), started_at the first move to IN_PROGRESS.
+// Lead time runs from creation to done_at, cycle time from started_at to done_at;
+// tasks that never were IN_PROGRESS have no cycle time.
+const completedTasksCTE = `
+	completed AS (
+		SELECT * FROM (
+			SELECT
+				t.id,
+				t.assignee_id,
+				t.created_at,
+				MAX(te.created_at) FILTER (WHERE te.new_status = 'DONE') AS done_at,
+				MIN(te.created_at) FILTER (WHERE te.new_status = 'IN_PROGRESS') AS started_at
+			FROM tasks t
+			JOIN task_events te ON te.task_id = t.id
+			WHERE t.workspace_id = $1 AND t.status = 'DONE' AND t.deleted_at IS NULL
+			GROUP BY t.id
+		) c
+		WHERE c.done_at >= $2 AND c.done_at <= $3
+	)`
+
+// Average lead and cycle time of the completed CTE's rows, in minutes rounded to 0.1.
+const (
+	avgLeadTimeMinutes  = `COALESCE(ROUND((AVG(EXTRACT(EPOCH FROM c.done_at - c.created_at)) / 60)::numeric, 1), 0)::float8`
+	avgCycleTimeMinutes = `COALESCE(ROUND((AVG(EXTRACT(EPOCH FROM c.done_at - c.started_at)) / 60)::numeric, 1), 0)::float8`
+)
+
+// handoffsCTE selects the taken_over and escalated events of workspace $1 between
+// $2 and $3 with the agent on the receiving end: the assignee the task was taken
+// from, or the assignee of the escalated task. Events record that agent in their
+// data; for older events it is the agent of the latest earlier claim, takeover or
+// auto-assignment, and for escalations, failing that, the current assignee.
+const handoffsCTE = `
+	handoffs AS (
+		SELECT
+			te.type,
+			te.actor_id,
+			COALESCE(
+				(te.data->>'previous_assignee_id')::uuid,
+				(te.data->>'assignee_id')::uuid,
+				prev.agent_id,
+				CASE WHEN te.type = 'escalated' THEN t.assignee_id END
+			) AS assignee_id
+		FROM task_events te
+		JOIN tasks t ON t.id = te.task_id
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(p.actor_id, (p.data->>'agent_id')::uuid) AS agent_id
+			FROM task_events p
+			WHERE p.task_id = te.task_id
+				AND p.created_at < te.created_at
+				AND (p.type IN ('claimed', 'taken_over') OR p.data ? 'auto_assigned')
+			ORDER BY p.created_at DESC
+			LIMIT 1
+		) prev ON true
+		WHERE t.workspace_id = $1 AND t.deleted_at IS NULL
+			AND te.type IN ('taken_over', 'escalated')
+			AND te.created_at >= $2 AND te.created_at <= $3
+	),
+	handoff_counts AS (
+		SELECT
+			agent_id,
+			COUNT(*) FILTER (WHERE role = 'taken_over_from') AS taken_over_from,
+			COUNT(*) FILTER (WHERE role = 'taken_over_by') AS taken_over_by,
+			COUNT(*) FILTER (WHERE role = 'escalated_by') AS escalations_initiated,
+			COUNT(*) FILTER (WHERE role = 'escalated_to') AS escalations_received
+		FROM (
+			SELECT actor_id AS agent_id, CASE type WHEN 'taken_over' THEN 'taken_over_by' ELSE 'escalated_by' END AS role
+			FROM handoffs
+			UNION ALL
+			SELECT assignee_id, CASE type WHEN 'taken_over' THEN 'taken_over_from' ELSE 'escalated_to' END
+			FROM handoffs
+			WHERE assignee_id IS NOT NULL
+		) roles
+		GROUP BY agent_id
+	)`
+
+// GetAgentStats retrieves statistics for agents in a workspace. Lead and cycle
+// times are attributed to the assignee of the completed task.
+func (r *PgTaskRepository) GetAgentStats(ctx context.Context, filters StatsFilters) ([]AgentStatsResult, error) {
+	query := `
+		WITH ` + completedTasksCTE + `,
+		cycle_times AS (
+			SELECT
+				c.assignee_id,
+				` + avgLeadTimeMinutes + ` AS avg_lead_time,
+				` + avgCycleTimeMinutes + ` AS avg_cycle_time
+			FROM completed c
+			GROUP BY c.assignee_id
+		),` + handoffsCTE + `
 		SELECT
 			a.id,
 			a.name,
 			COUNT(CASE WHEN t.status = 'DONE' AND t.updated_at >= $2 AND t.updated_at <= $3 THEN 1 END) as tasks_completed,
 			COUNT(CASE WHEN t.status = 'CANCELLED' AND t.updated_at >= $2 AND t.updated_at <= $3 THEN 1 END) as tasks_cancelled,
 			COUNT(CASE WHEN t.status = 'STUCK' THEN 1 END) as tasks_stuck_count,
-			COUNT(CASE WHEN t.status = 'IN_PROGRESS' THEN 1 END) as tasks_in_progress
+			COUNT(CASE WHEN t.status = 'IN_PROGRESS' THEN 1 END) as tasks_in_progress,
+			COALESCE(MAX(ct.avg_lead_time), 0),
+			COALESCE(MAX(ct.avg_cycle_time), 0),
+			COALESCE(MAX(hc.taken_over_from), 0),
+			COALESCE(MAX(hc.taken_over_by), 0),
+			COALESCE(MAX(hc.escalations_initiated), 0),
+			COALESCE(MAX(hc.escalations_received), 0),
+			a.last_seen_at,
+			COALESCE(` + staleAgentCondition + `, false)
 		FROM agents a
-		LEFT JOIN tasks t ON t.assignee_id = a.id AND t.workspace_id = $1
+		JOIN workspaces w ON w.id = a.workspace_id
+		LEFT JOIN tasks t ON t.assignee_id = a.id AND t.workspace_id = $1 AND t.deleted_at IS NULL
+		LEFT JOIN cycle_times ct ON ct.assignee_id = a.id
+		LEFT JOIN handoff_counts hc ON hc.agent_id = a.id
 		WHERE a.workspace_id = $1 AND a.is_active = true
 	`
 
@@ -57,7 +268,7 @@ func (r *TaskRepository) GetAgentStats(ctx context.Context, filters StatsFilters
 		args = append(args, *filters.AgentID)
 	}
 
-	query += " GROUP BY a.id, a.name ORDER BY a.name"
+	query += " GROUP BY a.id, a.name, w.agent_stale_after_seconds ORDER BY a.name"
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -75,6 +286,14 @@ func (r *TaskRepository) GetAgentStats(ctx context.Context, filters StatsFilters
 			&result.TasksCancelled,
 			&result.TasksStuckCount,
 			&result.TasksInProgress,
+			&result.AvgLeadTimeMinutes,
+			&result.AvgCycleTimeMinutes,
+			&result.TasksTakenOverFromAgent,
+			&result.TasksTakenOverByAgent,
+			&result.EscalationsInitiated,
+			&result.EscalationsReceived,
+			&result.LastSeenAt,
+			&result.Stale,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan agent stats: %w", err)
@@ -90,13 +309,13 @@ func (r *TaskRepository) GetAgentStats(ctx context.Context, filters StatsFilters
 }
 
 // GetWorkspaceStats retrieves overall workspace statistics.
-func (r *TaskRepository) GetWorkspaceStats(ctx context.Context, filters StatsFilters) (*WorkspaceStatsResult, error) {
+func (r *PgTaskRepository) GetWorkspaceStats(ctx context.Context, filters StatsFilters) (*WorkspaceStatsResult, error) {
 	// Get total tasks created in period
 	var totalCreated int
 	err := r.pool.QueryRow(ctx, `
 		SELECT COUNT(*)
 		FROM tasks
-		WHERE workspace_id = $1 AND created_at >= $2 AND created_at <= $3
+		WHERE workspace_id = $1 AND deleted_at IS NULL AND created_at >= $2 AND created_at <= $3
 	`, filters.WorkspaceID, filters.PeriodStart, filters.PeriodEnd).Scan(&totalCreated)
 	if err != nil {
 		return nil, fmt.Errorf("count total tasks: %w", err)
@@ -107,7 +326,7 @@ func (r *TaskRepository) GetWorkspaceStats(ctx context.Context, filters StatsFil
 	rows, err := r.pool.Query(ctx, `
 		SELECT status, COUNT(*)
 		FROM tasks
-		WHERE workspace_id = $1
+		WHERE workspace_id = $1 AND deleted_at IS NULL
 		GROUP BY status
 	`, filters.WorkspaceID)
 	if err != nil {
@@ -134,6 +353,7 @@ func (r *TaskRepository) GetWorkspaceStats(ctx context.Context, filters StatsFil
 		SELECT COUNT(*)
 		FROM tasks
 		WHERE workspace_id = $1
+		  AND deleted_at IS NULL
 		  AND status IN ($2, $3, $4)
 		  AND status_deadline_at < NOW()
 	`, filters.WorkspaceID,
@@ -145,13 +365,270 @@ func (r *TaskRepository) GetWorkspaceStats(ctx context.Context, filters StatsFil
 		return nil, fmt.Errorf("count overdue tasks: %w", err)
 	}
 
+	var avgLeadTime, avgCycleTime float64
+	err = r.pool.QueryRow(ctx, `
+		WITH `+completedTasksCTE+`
+		SELECT `+avgLeadTimeMinutes+`, `+avgCycleTimeMinutes+`
+		FROM completed c
+	`, filters.WorkspaceID, filters.PeriodStart, filters.PeriodEnd).Scan(&avgLeadTime, &avgCycleTime)
+	if err != nil {
+		return nil, fmt.Errorf("compute lead and cycle time: %w", err)
+	}
+
+	var staleAgentCount int
+	err = r.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM agents a
+		JOIN workspaces w ON w.id = a.workspace_id
+		WHERE a.workspace_id = $1 AND a.is_active = true AND `+staleAgentCondition+`
+	`, filters.WorkspaceID).Scan(&staleAgentCount)
+	if err != nil {
+		return nil, fmt.Errorf("count stale agents: %w", err)
+	}
+
 	// Stuck count is already in tasksByStatus
 	stuckCount := tasksByStatus[string(domain.TaskStatusStuck)]
 
+	awaitingBySystem := make(map[string]int)
+	err = r.pool.QueryRow(ctx, `
+		SELECT COALESCE(jsonb_object_agg(external_system, n), '{}')
+		FROM (
+			SELECT external_system, COUNT(*) AS n
+			FROM tasks
+			WHERE workspace_id = $1 AND status = $2 AND deleted_at IS NULL
+			GROUP BY external_system
+		) s
+	`, filters.WorkspaceID, domain.TaskStatusAwaitingExternal).Scan(&awaitingBySystem)
+	if err != nil {
+		return nil, fmt.Errorf("count awaiting external tasks: %w", err)
+	}
+
 	return &WorkspaceStatsResult{
-		TotalTasksCreated: totalCreated,
-		TasksByStatus:     tasksByStatus,
-		OverdueCount:      overdueCount,
-		StuckCount:        stuckCount,
+		TotalTasksCreated:        totalCreated,
+		TasksByStatus:            tasksByStatus,
+		OverdueCount:             overdueCount,
+		StuckCount:               stuckCount,
+		StaleAgentCount:          staleAgentCount,
+		AvgLeadTimeMinutes:       avgLeadTime,
+		AvgCycleTimeMinutes:      avgCycleTime,
+		AwaitingExternalCount:    tasksByStatus[string(domain.TaskStatusAwaitingExternal)],
+		AwaitingExternalBySystem: awaitingBySystem,
 	}, nil
 }
+
+// WorkspaceTaskCounts holds the current task counts of one workspace.
+type WorkspaceTaskCounts struct {
+	TasksByStatus map[string]int
+	OverdueCount  int
+}
+
+// CountTasksByWorkspace counts the current tasks of every workspace by status in
+// one statement, keyed by workspace ID. Workspaces without tasks are absent.
+func (r *PgTaskRepository) CountTasksByWorkspace(ctx context.Context) (map[string]*WorkspaceTaskCounts, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT workspace_id, status, COUNT(*),
+		       COUNT(*) FILTER (WHERE status IN ($1, $2, $3) AND status_deadline_at < NOW())
+		FROM tasks
+		WHERE deleted_at IS NULL
+		GROUP BY workspace_id, status
+	`, domain.TaskStatusNew, domain.TaskStatusInProgress, domain.TaskStatusBlocked)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks by workspace: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]*WorkspaceTaskCounts)
+	for rows.Next() {
+		var workspaceID, status string
+		var count, overdue int
+		if err := rows.Scan(&workspaceID, &status, &count, &overdue); err != nil {
+			return nil, fmt.Errorf("scan workspace status count: %w", err)
+		}
+		workspaceCounts, ok := counts[workspaceID]
+		if !ok {
+			workspaceCounts = &WorkspaceTaskCounts{TasksByStatus: make(map[string]int)}
+			counts[workspaceID] = workspaceCounts
+		}
+		workspaceCounts.TasksByStatus[status] = count
+		workspaceCounts.OverdueCount += overdue
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workspace status rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetQueueDepth counts claimable tasks of a workspace (NEW, unassigned, public,
+// blockers DONE), optionally within one queue. All breakdowns come from one statement.
+func (r *PgTaskRepository) GetQueueDepth(ctx context.Context, workspaceID string, queue *string) (*QueueDepthResult, error) {
+	query := `
+		WITH claimable AS (
+			SELECT t.priority, t.required_capabilities, t.queue, t.created_at
+			FROM tasks t
+			WHERE t.workspace_id = $1
+			  AND ($2::text IS NULL OR t.queue = $2)
+			  AND ` + claimableTaskCondition + `
+		)
+		SELECT
+			(SELECT COUNT(*) FROM claimable),
+			(SELECT COUNT(*) FROM claimable WHERE cardinality(required_capabilities) = 0),
+			(SELECT MIN(created_at) FROM claimable),
+			COALESCE((SELECT jsonb_object_agg(priority, n)
+				FROM (SELECT priority, COUNT(*) AS n FROM claimable GROUP BY priority) p), '{}'),
+			COALESCE((SELECT jsonb_object_agg(capability, n)
+				FROM (SELECT capability, COUNT(*) AS n
+					FROM claimable, unnest(required_capabilities) AS capability
+					GROUP BY capability) c), '{}'),
+			COALESCE((SELECT jsonb_object_agg(queue, n)
+				FROM (SELECT COALESCE(queue, $3) AS queue, COUNT(*) AS n
+					FROM claimable GROUP BY 1) q), '{}')
+	`
+
+	result := &QueueDepthResult{}
+	err := r.pool.QueryRow(ctx, query, workspaceID, queue, DefaultQueueKey).Scan(
+		&result.Total,
+		&result.Unrestricted,
+		&result.OldestAt,
+		&result.ByPriority,
+		&result.ByCapability,
+		&result.ByQueue,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query queue depth: %w", err)
+	}
+
+	// Report every priority so consumers can rely on the keys
+	for _, priority := range []domain.TaskPriority{
+		domain.TaskPriorityLow, domain.TaskPriorityNormal, domain.TaskPriorityHigh, domain.TaskPriorityCritical,
+	} {
+		if _, ok := result.ByPriority[string(priority)]; !ok {
+			result.ByPriority[string(priority)] = 0
+		}
+	}
+
+	return result, nil
+}
+
+// EventSeries names a per-interval count of task events, used by dashboards.
+type EventSeries string
+
+const (
+	EventSeriesEvents         EventSeries = "events"
+	EventSeriesTasksCreated   EventSeries = "tasks_created"
+	EventSeriesTasksCompleted EventSeries = "tasks_completed"
+	EventSeriesTasksCancelled EventSeries = "tasks_cancelled"
+	EventSeriesTasksStuck     EventSeries = "tasks_stuck"
+	EventSeriesEscalations    EventSeries = "escalations"
+	EventSeriesTakeovers      EventSeries = "takeovers"
+)
+
+// eventSeriesConditions selects the events counted by each series.
+var eventSeriesConditions = map[EventSeries]sq.Sqlizer{
+	EventSeriesEvents:         sq.Expr("TRUE"),
+	EventSeriesTasksCreated:   sq.Eq{"te.type": domain.EventTypeCreated},
+	EventSeriesTasksCompleted: sq.Eq{"te.new_status": domain.TaskStatusDone},
+	EventSeriesTasksCancelled: sq.Eq{"te.new_status": domain.TaskStatusCancelled},
+	EventSeriesTasksStuck:     sq.Eq{"te.new_status": domain.TaskStatusStuck},
+	EventSeriesEscalations:    sq.Eq{"te.type": domain.EventTypeEscalated},
+	EventSeriesTakeovers:      sq.Eq{"te.type": domain.EventTypeTakenOver},
+}
+
+// EventSeriesNames lists the available series in display order.
+func EventSeriesNames() []EventSeries {
+	return []EventSeries{
+		EventSeriesEvents, EventSeriesTasksCreated, EventSeriesTasksCompleted, EventSeriesTasksCancelled,
+		EventSeriesTasksStuck, EventSeriesEscalations, EventSeriesTakeovers,
+	}
+}
+
+// IsValid checks if the series is one of the known values.
+func (s EventSeries) IsValid() bool {
+	_, ok := eventSeriesConditions[s]
+	return ok
+}
+
+// EventSeriesFilters selects the events and time buckets of an event series.
+type EventSeriesFilters struct {
+	WorkspaceID string
+	Series      EventSeries
+	From        time.Time
+	To          time.Time
+	Interval    time.Duration // bucket width, at least a millisecond
+	ActorID     *string       // Optional: only events caused by this agent
+	Queue       *string       // Optional: only events of tasks in this queue
+	Label       *string       // Optional: only events of tasks carrying this label
+}
+
+// SeriesPoint is the number of events in the bucket starting at Time.
+type SeriesPoint struct {
+	Time  time.Time
+	Count int
+}
+
+// GetEventSeries counts matching events per interval between From and To.
+// Buckets are aligned to multiples of Interval since the Unix epoch, and empty
+// buckets are returned with a zero count so graphs do not interpolate over gaps.
+func (r *PgTaskRepository) GetEventSeries(ctx context.Context, filters EventSeriesFilters) ([]SeriesPoint, error) {
+	condition, ok := eventSeriesConditions[filters.Series]
+	if !ok {
+		return nil, fmt.Errorf("unknown event series %q", filters.Series)
+	}
+
+	intervalMs := filters.Interval.Milliseconds()
+	where := sq.And{
+		sq.Eq{"t.workspace_id": filters.WorkspaceID, "t.deleted_at": nil},
+		sq.GtOrEq{"te.created_at": filters.From},
+		sq.Lt{"te.created_at": filters.To},
+		condition,
+	}
+	if filters.ActorID != nil {
+		where = append(where, sq.Eq{"te.actor_id": *filters.ActorID})
+	}
+	if filters.Queue != nil {
+		where = append(where, sq.Eq{"t.queue": *filters.Queue})
+	}
+	if filters.Label != nil {
+		where = append(where, sq.Expr("t.labels @> ARRAY[?::text]", *filters.Label))
+	}
+
+	query, args, err := psql.
+		Select(fmt.Sprintf("(floor(extract(epoch FROM te.created_at) * 1000 / %d) * %d)::bigint AS bucket", intervalMs, intervalMs), "COUNT(*)").
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		Where(where).
+		GroupBy("bucket").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetEventSeries query for %s: %w", filters.Series, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query event series: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var bucket int64
+		var count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("scan event series bucket: %w", err)
+		}
+		counts[bucket] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event series rows: %w", err)
+	}
+
+	var points []SeriesPoint
+	start := filters.From.UnixMilli() / intervalMs * intervalMs
+	for bucket := start; bucket < filters.To.UnixMilli(); bucket += intervalMs {
+		points = append(points, SeriesPoint{Time: time.UnixMilli(bucket), Count: counts[bucket]})
+	}
+
+	return points, nil
+}
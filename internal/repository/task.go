@@ -12,29 +12,76 @@ import (
 	"github.com/mtlprog/sloptask/internal/domain"
 )
 
-// taskColumns is the shared list of columns for task queries.
-var taskColumns = []string{
-	"id", "workspace_id", "title", "description", "creator_id", "assignee_id",
-	"status", "visibility", "priority", "blocked_by", "status_deadline_at",
-	"artefact", "created_at", "updated_at",
+// taskColumns is the shared list of columns for task queries from "tasks".
+var taskColumns = taskColumnsOf("tasks")
+
+// taskColumnNames are the columns scanTask reads, in order. blocked_by and key
+// are not columns of tasks but are read from task_dependencies and workspaces,
+// see taskColumnsOf.
+var taskColumnNames = []string{
+	"id", "workspace_id", "number", "key", "title", "description", "creator_id", "assignee_id",
+	"status", "visibility", "priority", "inherited_priority", "blocked_by", "status_deadline_at",
+	"artefact", "result", "required_capabilities", "queue", "labels",
+	"external_system", "external_id", "external_url", "archived_at", "attempts", "human_review_at",
+	"requires_approval", "version", "created_at", "updated_at",
+}
+
+// taskColumnsOf lists the task columns qualified by table, the name or alias
+// tasks go by in the query, with blocked_by in the order the blockers were given.
+func taskColumnsOf(table string) []string {
+	columns := make([]string, len(taskColumnNames))
+	for i, name := range taskColumnNames {
+		switch name {
+		case "blocked_by":
+			columns[i] = blockedByExpr(table) + " AS blocked_by"
+		case "key":
+			columns[i] = taskKeyExpr(table) + " AS key"
+		default:
+			columns[i] = table + "." + name
+		}
+	}
+	return columns
+}
+
+// taskKeyExpr reads the key of the tasks row named table, such as TST-142: the
+// slug of its workspace in upper case and its number.
+func taskKeyExpr(table string) string {
+	return "(SELECT UPPER(w.slug) FROM workspaces w WHERE w.id = " + table + ".workspace_id) || '-' || " + table + ".number"
 }
 
-// TaskRepository handles database operations for tasks.
-type TaskRepository struct {
+// blockedByExpr reads the blockers of the tasks row named table as a uuid[],
+// in the order they were given.
+func blockedByExpr(table string) string {
+	return "ARRAY(SELECT d.blocker_id FROM task_dependencies d WHERE d.task_id = " + table + ".id ORDER BY d.position)"
+}
+
+// effectivePriorityRank orders tasks most urgent first by the priority they are
+// ranked by: an inherited priority while set, otherwise their own.
+const effectivePriorityRank = "CASE COALESCE(inherited_priority, priority) WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'normal' THEN 3 WHEN 'low' THEN 4 END"
+
+// notDeleted hides soft-deleted tasks. Every read of tasks applies it; deleted
+// rows are only touched again by PurgeDeleted.
+var notDeleted = sq.Eq{"deleted_at": nil}
+
+// PgTaskRepository handles database operations for tasks.
+type PgTaskRepository struct {
 	pool *pgxpool.Pool
 }
 
-// NewTaskRepository creates a new TaskRepository.
-func NewTaskRepository(pool *pgxpool.Pool) *TaskRepository {
-	return &TaskRepository{pool: pool}
+// NewTaskRepository creates a new PgTaskRepository.
+func NewTaskRepository(pool *pgxpool.Pool) *PgTaskRepository {
+	return &PgTaskRepository{pool: pool}
 }
 
 // scanTask scans a single row into a Task struct.
 func scanTask(row pgx.Row) (*domain.Task, error) {
 	var task domain.Task
+	var externalSystem, externalID, externalURL *string
 	err := row.Scan(
 		&task.ID,
 		&task.WorkspaceID,
+		&task.Number,
+		&task.Key,
 		&task.Title,
 		&task.Description,
 		&task.CreatorID,
@@ -42,9 +89,22 @@ func scanTask(row pgx.Row) (*domain.Task, error) {
 		&task.Status,
 		&task.Visibility,
 		&task.Priority,
+		&task.InheritedPriority,
 		&task.BlockedBy,
 		&task.StatusDeadlineAt,
 		&task.Artefact,
+		&task.Result,
+		&task.RequiredCapabilities,
+		&task.Queue,
+		&task.Labels,
+		&externalSystem,
+		&externalID,
+		&externalURL,
+		&task.ArchivedAt,
+		&task.Attempts,
+		&task.HumanReviewAt,
+		&task.RequiresApproval,
+		&task.Version,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -54,6 +114,9 @@ func scanTask(row pgx.Row) (*domain.Task, error) {
 		}
 		return nil, fmt.Errorf("scan task: %w", err)
 	}
+	if externalSystem != nil && externalID != nil {
+		task.ExternalRef = &domain.ExternalRef{System: *externalSystem, ID: *externalID, URL: externalURL}
+	}
 	return &task, nil
 }
 
@@ -75,12 +138,37 @@ func scanTasks(rows pgx.Rows) ([]*domain.Task, error) {
 	return tasks, nil
 }
 
+// GetIDByKey returns the ID of the task numbered number in the workspace with
+// slug, the parts of a task key. Returns ErrTaskNotFound if there is none.
+func (r *PgTaskRepository) GetIDByKey(ctx context.Context, workspaceSlug string, number int) (string, error) {
+	query, args, err := psql.
+		Select("t.id").
+		From("tasks t").
+		Join("workspaces w ON w.id = t.workspace_id").
+		Where(sq.Eq{"w.slug": workspaceSlug, "t.number": number, "t.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("build GetIDByKey query for task %d: %w", number, err)
+	}
+
+	var taskID string
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&taskID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrTaskNotFound
+		}
+		return "", fmt.Errorf("get task by key: %w", err)
+	}
+
+	return taskID, nil
+}
+
 // GetByID retrieves a task by ID.
-func (r *TaskRepository) GetByID(ctx context.Context, taskID string) (*domain.Task, error) {
+func (r *PgTaskRepository) GetByID(ctx context.Context, taskID string) (*domain.Task, error) {
 	query, args, err := psql.
 		Select(taskColumns...).
 		From("tasks").
 		Where(sq.Eq{"id": taskID}).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build GetByID query for task: %w", err)
@@ -90,11 +178,12 @@ func (r *TaskRepository) GetByID(ctx context.Context, taskID string) (*domain.Ta
 }
 
 // GetByIDForUpdate retrieves a task by ID with FOR UPDATE lock (within transaction).
-func (r *TaskRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID string) (*domain.Task, error) {
+func (r *PgTaskRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID string) (*domain.Task, error) {
 	query, args, err := psql.
 		Select(taskColumns...).
 		From("tasks").
 		Where(sq.Eq{"id": taskID}).
+		Where(notDeleted).
 		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
@@ -106,7 +195,7 @@ func (r *TaskRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, taskID
 
 // UpdateStatus updates the task status with optimistic locking.
 // Returns ErrTaskAlreadyClaimed if the task was modified (oldStatus doesn't match).
-func (r *TaskRepository) UpdateStatus(
+func (r *PgTaskRepository) UpdateStatus(
 	ctx context.Context,
 	tx pgx.Tx,
 	taskID string,
@@ -148,8 +237,230 @@ func (r *TaskRepository) UpdateStatus(
 	return nil
 }
 
-// GetBlockedByTasks retrieves all tasks from the blocked_by array.
-func (r *TaskRepository) GetBlockedByTasks(ctx context.Context, blockedBy []string) ([]*domain.Task, error) {
+// SetResult stores the structured completion result of a task (within transaction).
+// A nil result clears it.
+func (r *PgTaskRepository) SetResult(ctx context.Context, tx pgx.Tx, taskID string, result map[string]any) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("result", result).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetResult query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set task result: %w", err)
+	}
+
+	return nil
+}
+
+// SetExternalRef stores the external reference a task waits on (within transaction).
+// A nil ref clears it.
+func (r *PgTaskRepository) SetExternalRef(ctx context.Context, tx pgx.Tx, taskID string, ref *domain.ExternalRef) error {
+	update := psql.Update("tasks").Where(sq.Eq{"id": taskID})
+	if ref != nil {
+		update = update.
+			Set("external_system", ref.System).
+			Set("external_id", ref.ID).
+			Set("external_url", ref.URL)
+	} else {
+		update = update.
+			Set("external_system", nil).
+			Set("external_id", nil).
+			Set("external_url", nil)
+	}
+
+	query, args, err := update.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetExternalRef query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set task external reference: %w", err)
+	}
+
+	return nil
+}
+
+// SetLabels replaces the labels of a task (within transaction).
+func (r *PgTaskRepository) SetLabels(ctx context.Context, tx pgx.Tx, taskID string, labels []string) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("labels", labels).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetLabels query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set task labels: %w", err)
+	}
+
+	return nil
+}
+
+// SetArchived archives a task, or unarchives it when archived is false (within transaction).
+func (r *PgTaskRepository) SetArchived(ctx context.Context, tx pgx.Tx, taskID string, archived bool) error {
+	update := psql.Update("tasks").Where(sq.Eq{"id": taskID})
+	if archived {
+		update = update.Set("archived_at", sq.Expr("NOW()"))
+	} else {
+		update = update.Set("archived_at", nil)
+	}
+
+	query, args, err := update.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetArchived query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set task archived: %w", err)
+	}
+
+	return nil
+}
+
+// SoftDelete marks a task deleted, hiding it from all reads (within transaction).
+// deletedBy is nil when an operator deletes the task.
+func (r *PgTaskRepository) SoftDelete(ctx context.Context, tx pgx.Tx, taskID string, deletedBy *string) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Set("deleted_by", deletedBy).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SoftDelete query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("soft delete task: %w", err)
+	}
+
+	return nil
+}
+
+// TaskTransfer holds the fields a task takes on in the workspace it moves to.
+type TaskTransfer struct {
+	WorkspaceID      string
+	CreatorID        string
+	Visibility       domain.TaskVisibility
+	Labels           []string // registered in the target workspace
+	StatusDeadlineAt *time.Time
+}
+
+// Transfer moves a task to another workspace (within transaction). It arrives NEW
+// and unassigned, outside any queue, without blockers, external reference or
+// inherited priority.
+func (r *PgTaskRepository) Transfer(ctx context.Context, tx pgx.Tx, taskID string, transfer TaskTransfer) error {
+	// The dependencies' foreign keys keep the task in place while it has blockers;
+	// its dependents must have been released with RemoveBlocker.
+	if _, err := tx.Exec(ctx, `DELETE FROM task_dependencies WHERE task_id = $1`, taskID); err != nil {
+		return fmt.Errorf("drop task blockers: %w", err)
+	}
+
+	query, args, err := psql.
+		Update("tasks").
+		Set("workspace_id", transfer.WorkspaceID).
+		Set("creator_id", transfer.CreatorID).
+		Set("assignee_id", nil).
+		Set("status", domain.TaskStatusNew).
+		Set("visibility", transfer.Visibility).
+		Set("labels", transfer.Labels).
+		Set("status_deadline_at", transfer.StatusDeadlineAt).
+		Set("inherited_priority", nil).
+		Set("queue", nil).
+		Set("external_system", nil).
+		Set("external_id", nil).
+		Set("external_url", nil).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Transfer query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("transfer task: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBlocker drops a task from the blocked_by list of every task depending on it
+// (within transaction), bumping their versions. Returns the number of tasks changed.
+func (r *PgTaskRepository) RemoveBlocker(ctx context.Context, tx pgx.Tx, blockerID string) (int64, error) {
+	tag, err := tx.Exec(ctx, `
+		WITH removed AS (
+			DELETE FROM task_dependencies WHERE blocker_id = $1 RETURNING task_id
+		)
+		UPDATE tasks SET updated_at = NOW() WHERE id IN (SELECT task_id FROM removed)`,
+		blockerID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("remove blocker from dependent tasks: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// PurgeDeleted hard-deletes tasks soft-deleted before the given time. Their events,
+// checklist items and revisions are removed by cascade. Returns the number of tasks removed.
+func (r *PgTaskRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query, args, err := psql.
+		Delete("tasks").
+		Where(sq.Lt{"deleted_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build PurgeDeleted query: %w", err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("purge deleted tasks: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// FindAwaitingExternalForUpdate locks the AWAITING_EXTERNAL tasks of a workspace
+// that wait on the given external reference.
+func (r *PgTaskRepository) FindAwaitingExternalForUpdate(
+	ctx context.Context,
+	tx pgx.Tx,
+	workspaceID, system, externalID string,
+) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Eq{
+			"workspace_id":    workspaceID,
+			"status":          domain.TaskStatusAwaitingExternal,
+			"external_system": system,
+			"external_id":     externalID,
+		}).
+		Where(notDeleted).
+		OrderBy("created_at ASC").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindAwaitingExternalForUpdate query: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query awaiting external tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// GetBlockedByTasks retrieves the tasks with the given IDs, e.g. a task's blocked_by.
+func (r *PgTaskRepository) GetBlockedByTasks(ctx context.Context, blockedBy []string) ([]*domain.Task, error) {
 	if len(blockedBy) == 0 {
 		return []*domain.Task{}, nil
 	}
@@ -158,6 +469,7 @@ func (r *TaskRepository) GetBlockedByTasks(ctx context.Context, blockedBy []stri
 		Select(taskColumns...).
 		From("tasks").
 		Where(sq.Eq{"id": blockedBy}).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build GetBlockedByTasks query: %w", err)
@@ -171,9 +483,18 @@ func (r *TaskRepository) GetBlockedByTasks(ctx context.Context, blockedBy []stri
 	return scanTasks(rows)
 }
 
-// FindExpiredDeadlines finds all tasks with expired deadlines.
-func (r *TaskRepository) FindExpiredDeadlines(ctx context.Context) ([]*domain.Task, error) {
-	query, args, err := psql.
+// DeadlineCursor positions FindExpiredDeadlines after the last task of the
+// previous batch.
+type DeadlineCursor struct {
+	DeadlineAt time.Time
+	ID         string
+}
+
+// FindExpiredDeadlines finds up to limit tasks with expired deadlines, oldest
+// deadline first, after the cursor if one is given. Tasks of archived
+// workspaces are frozen and never expire.
+func (r *PgTaskRepository) FindExpiredDeadlines(ctx context.Context, after *DeadlineCursor, limit int) ([]*domain.Task, error) {
+	qb := psql.
 		Select(taskColumns...).
 		From("tasks").
 		Where("status_deadline_at < NOW()").
@@ -182,7 +503,15 @@ func (r *TaskRepository) FindExpiredDeadlines(ctx context.Context) ([]*domain.Ta
 			domain.TaskStatusInProgress,
 			domain.TaskStatusBlocked,
 		}}).
-		ToSql()
+		Where(notDeleted).
+		Where("workspace_id NOT IN (SELECT id FROM workspaces WHERE archived_at IS NOT NULL)").
+		OrderBy("status_deadline_at", "id").
+		Limit(uint64(limit))
+	if after != nil {
+		qb = qb.Where("(status_deadline_at, id) > (?, ?)", after.DeadlineAt, after.ID)
+	}
+
+	query, args, err := qb.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build FindExpiredDeadlines query: %w", err)
 	}
@@ -195,9 +524,139 @@ func (r *TaskRepository) FindExpiredDeadlines(ctx context.Context) ([]*domain.Ta
 	return scanTasks(rows)
 }
 
+// FindAbandoned finds IN_PROGRESS tasks whose assignee was deactivated or went
+// stale (see staleAgentCondition), outside archived workspaces.
+func (r *PgTaskRepository) FindAbandoned(ctx context.Context) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Eq{"status": domain.TaskStatusInProgress}).
+		Where(notDeleted).
+		Where(`assignee_id IN (
+			SELECT a.id FROM agents a JOIN workspaces w ON w.id = a.workspace_id
+			WHERE w.archived_at IS NULL AND (NOT a.is_active OR ` + staleAgentCondition + `))`).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindAbandoned query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query abandoned tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// FindAutoAssignable finds unassigned public NEW tasks of a workspace that are
+// not held for human review, most urgent first.
+func (r *PgTaskRepository) FindAutoAssignable(ctx context.Context, workspaceID string) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Eq{
+			"workspace_id": workspaceID,
+			"status":       domain.TaskStatusNew,
+			"assignee_id":  nil,
+			"visibility":   domain.TaskVisibilityPublic,
+		}).
+		Where(sq.Eq{"human_review_at": nil}).
+		Where(notDeleted).
+		OrderBy(
+			effectivePriorityRank+" ASC",
+			"created_at ASC",
+		).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindAutoAssignable query for workspace %s: %w", workspaceID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query auto-assignable tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// claimableTaskCondition matches tasks (aliased t) that some agent could claim right now:
+// NEW, unassigned, public, not deleted or held for human review, and with every blocker DONE.
+const claimableTaskCondition = `t.status = 'NEW' AND t.assignee_id IS NULL AND t.visibility = 'public' AND t.deleted_at IS NULL
+	AND t.human_review_at IS NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+		WHERE d.task_id = t.id AND b.status <> 'DONE'
+	)`
+
+// claimableTaskQuery selects the tasks the agent could claim, most urgent first:
+// NEW, unassigned, public, all blockers DONE and required capabilities covered.
+// When queue is non-nil only that queue is searched.
+func claimableTaskQuery(agent *domain.Agent, queue *string) sq.SelectBuilder {
+	capabilities := agent.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+
+	qb := psql.
+		Select(taskColumnsOf("t")...).
+		From("tasks t").
+		Where(sq.Eq{"t.workspace_id": agent.WorkspaceID}).
+		Where(claimableTaskCondition).
+		Where(sq.Expr("t.required_capabilities <@ ?::text[]", capabilities)).
+		OrderBy(
+			effectivePriorityRank+" ASC",
+			"t.created_at ASC",
+		).
+		Limit(1)
+	if queue != nil {
+		qb = qb.Where(sq.Eq{"t.queue": *queue})
+	}
+	return qb
+}
+
+// FindNextClaimable locks and returns the most urgent task the agent could claim
+// (see claimableTaskQuery). Rows locked by concurrent callers are skipped, so
+// parallel claimers receive different tasks.
+// Returns ErrNoClaimableTask if there is none.
+func (r *PgTaskRepository) FindNextClaimable(
+	ctx context.Context,
+	tx pgx.Tx,
+	agent *domain.Agent,
+	queue *string,
+) (*domain.Task, error) {
+	query, args, err := claimableTaskQuery(agent, queue).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindNextClaimable query: %w", err)
+	}
+
+	task, err := scanTask(tx.QueryRow(ctx, query, args...))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		return nil, domain.ErrNoClaimableTask
+	}
+	return task, err
+}
+
+// FindClaimable returns the most urgent task the agent could claim without
+// locking it, so another agent may claim it first.
+// Returns ErrNoClaimableTask if there is none.
+func (r *PgTaskRepository) FindClaimable(ctx context.Context, agent *domain.Agent, queue *string) (*domain.Task, error) {
+	query, args, err := claimableTaskQuery(agent, queue).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindClaimable query: %w", err)
+	}
+
+	task, err := scanTask(r.pool.QueryRow(ctx, query, args...))
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		return nil, domain.ErrNoClaimableTask
+	}
+	return task, err
+}
+
 // Create creates a new task in the database within a transaction.
 // Returns the created task with ID, CreatedAt, and UpdatedAt populated.
-func (r *TaskRepository) Create(ctx context.Context, tx pgx.Tx, task *domain.Task) (*domain.Task, error) {
+func (r *PgTaskRepository) Create(ctx context.Context, tx pgx.Tx, task *domain.Task) (*domain.Task, error) {
 	// Set defaults
 	if task.Visibility == "" {
 		task.Visibility = domain.TaskVisibilityPublic
@@ -208,13 +667,19 @@ func (r *TaskRepository) Create(ctx context.Context, tx pgx.Tx, task *domain.Tas
 	if task.BlockedBy == nil {
 		task.BlockedBy = []string{}
 	}
+	if task.RequiredCapabilities == nil {
+		task.RequiredCapabilities = []string{}
+	}
+	if task.Labels == nil {
+		task.Labels = []string{}
+	}
 
 	query, args, err := psql.
 		Insert("tasks").
 		Columns(
 			"workspace_id", "title", "description", "creator_id", "assignee_id",
-			"status", "visibility", "priority", "blocked_by", "status_deadline_at",
-			"artefact",
+			"status", "visibility", "priority", "status_deadline_at",
+			"artefact", "required_capabilities", "queue", "labels", "requires_approval",
 		).
 		Values(
 			task.WorkspaceID,
@@ -225,20 +690,108 @@ func (r *TaskRepository) Create(ctx context.Context, tx pgx.Tx, task *domain.Tas
 			task.Status,
 			task.Visibility,
 			task.Priority,
-			task.BlockedBy,
 			task.StatusDeadlineAt,
 			task.Artefact,
+			task.RequiredCapabilities,
+			task.Queue,
+			task.Labels,
+			task.RequiresApproval,
 		).
-		Suffix("RETURNING id, created_at, updated_at").
+		Suffix("RETURNING id, number, " + taskKeyExpr("tasks") + ", created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build Create query for task: %w", err)
 	}
 
-	err = tx.QueryRow(ctx, query, args...).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+	err = tx.QueryRow(ctx, query, args...).Scan(&task.ID, &task.Number, &task.Key, &task.CreatedAt, &task.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("create task: %w", err)
 	}
 
+	if len(task.BlockedBy) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_dependencies (workspace_id, task_id, blocker_id, position)
+			SELECT $1, $2, b.id, b.position FROM unnest($3::uuid[]) WITH ORDINALITY AS b(id, position)`,
+			task.WorkspaceID, task.ID, task.BlockedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create task dependencies: %w", err)
+		}
+	}
+
 	return task, nil
 }
+
+// GetDependentTasks retrieves all tasks blocked by any of the given task IDs.
+func (r *PgTaskRepository) GetDependentTasks(ctx context.Context, taskIDs []string) ([]*domain.Task, error) {
+	if len(taskIDs) == 0 {
+		return []*domain.Task{}, nil
+	}
+
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Expr("id IN (SELECT task_id FROM task_dependencies WHERE blocker_id = ANY(?::uuid[]))", taskIDs)).
+		Where(notDeleted).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetDependentTasks query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query dependent tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// FindDependentIDs returns the IDs of the tasks blockerID blocks, within the
+// transaction. They are sorted, so transactions locking them one by one take
+// the locks in the same order.
+func (r *PgTaskRepository) FindDependentIDs(ctx context.Context, tx pgx.Tx, blockerID string) ([]string, error) {
+	query, args, err := psql.
+		Select("d.task_id").
+		From("task_dependencies d").
+		Join("tasks t ON t.id = d.task_id").
+		Where(sq.Eq{"d.blocker_id": blockerID}).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		OrderBy("d.task_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindDependentIDs query for task %s: %w", blockerID, err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query dependent task IDs: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan dependent task IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// CountUnresolvedBlockers counts the blockers of a task other than exceptID
+// that are not DONE or were deleted, within the transaction.
+func (r *PgTaskRepository) CountUnresolvedBlockers(ctx context.Context, tx pgx.Tx, taskID, exceptID string) (int, error) {
+	query, args, err := psql.
+		Select("COUNT(*)").
+		From("task_dependencies d").
+		LeftJoin("tasks b ON b.id = d.blocker_id AND b.deleted_at IS NULL").
+		Where(sq.Eq{"d.task_id": taskID}).
+		Where(sq.NotEq{"d.blocker_id": exceptID}).
+		Where(sq.Or{sq.Eq{"b.id": nil}, sq.NotEq{"b.status": domain.TaskStatusDone}}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build CountUnresolvedBlockers query for task %s: %w", taskID, err)
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count unresolved blockers: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// waitingSinceExpr is when a task (aliased t) started waiting for a claim: when
+// it last became NEW or last aged, whichever is later.
+const waitingSinceExpr = `COALESCE((
+	SELECT MAX(e.created_at) FROM task_events e
+	WHERE e.task_id = t.id AND (e.new_status = 'NEW' OR e.type = 'priority_aged')
+), t.created_at)`
+
+// FindAging finds unassigned NEW tasks that have waited longer than their
+// workspace's priority aging threshold, outside archived workspaces. In
+// workspaces that bump priorities, critical tasks have nowhere to go and are skipped.
+func (r *PgTaskRepository) FindAging(ctx context.Context) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumnsOf("t")...).
+		From("tasks t").
+		Join("workspaces w ON w.id = t.workspace_id").
+		Where(sq.Eq{"t.status": domain.TaskStatusNew, "t.assignee_id": nil, "t.deleted_at": nil}).
+		Where("w.archived_at IS NULL AND w.priority_aging_after_seconds IS NOT NULL").
+		Where(sq.Or{
+			sq.Eq{"w.priority_aging_action": domain.PriorityAgingWarn},
+			sq.NotEq{"t.priority": domain.TaskPriorityCritical},
+		}).
+		Where(waitingSinceExpr + " <= NOW() - w.priority_aging_after_seconds * INTERVAL '1 second'").
+		OrderBy("t.created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindAging query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query aging tasks: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// WaitingSince returns when a task started waiting for a claim: when it last
+// became NEW or last aged (within transaction).
+func (r *PgTaskRepository) WaitingSince(ctx context.Context, tx pgx.Tx, taskID string) (time.Time, error) {
+	query, args, err := psql.
+		Select(waitingSinceExpr).
+		From("tasks t").
+		Where(sq.Eq{"t.id": taskID}).
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("build WaitingSince query for task %s: %w", taskID, err)
+	}
+
+	var since time.Time
+	if err := tx.QueryRow(ctx, query, args...).Scan(&since); err != nil {
+		return time.Time{}, fmt.Errorf("query task waiting time: %w", err)
+	}
+
+	return since, nil
+}
+
+// SetPriority changes a task's own priority (within transaction).
+func (r *PgTaskRepository) SetPriority(ctx context.Context, tx pgx.Tx, taskID string, priority domain.TaskPriority) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("priority", priority).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetPriority query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set task priority: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// IncrementAttempts counts one more attempt on a task (within transaction) and
+// returns the new count.
+func (r *PgTaskRepository) IncrementAttempts(ctx context.Context, tx pgx.Tx, taskID string) (int, error) {
+	query, args, err := psql.
+		Update("tasks").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Where(sq.Eq{"id": taskID}).
+		Suffix("RETURNING attempts").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build IncrementAttempts query for task %s: %w", taskID, err)
+	}
+
+	var attempts int
+	if err := tx.QueryRow(ctx, query, args...).Scan(&attempts); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrTaskNotFound
+		}
+		return 0, fmt.Errorf("increment attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// HoldForHumanReview marks a task as held for human review (within transaction).
+// Returns false if it already was.
+func (r *PgTaskRepository) HoldForHumanReview(ctx context.Context, tx pgx.Tx, taskID string) (bool, error) {
+	query, args, err := psql.
+		Update("tasks").
+		Set("human_review_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID, "human_review_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build HoldForHumanReview query for task %s: %w", taskID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("hold task for human review: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClearHumanReview lifts the human review hold of a task and resets its
+// attempts (within transaction).
+func (r *PgTaskRepository) ClearHumanReview(ctx context.Context, tx pgx.Tx, taskID string) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("human_review_at", nil).
+		Set("attempts", 0).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build ClearHumanReview query for task %s: %w", taskID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("clear human review: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTaskNotFound
+	}
+
+	return nil
+}
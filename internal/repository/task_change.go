@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// TaskChangesChannel is the Postgres NOTIFY channel carrying a domain.TaskChange
+// for every task event written.
+const TaskChangesChannel = "sloptask_task_changes"
+
+// notifyChange queues a notification of the event on TaskChangesChannel (within
+// transaction). Postgres delivers it to listeners on commit and drops it on rollback.
+func (r *PgTaskEventRepository) notifyChange(ctx context.Context, tx pgx.Tx, event *domain.TaskEvent) error {
+	query, args, err := psql.
+		Select().
+		Column(sq.Expr(
+			"pg_notify(?, json_build_object('event_id', ?::text, 'task_id', t.id, 'workspace_id', t.workspace_id, 'type', ?::text, 'new_status', ?::text)::text)",
+			TaskChangesChannel, event.ID, event.Type, event.NewStatus,
+		)).
+		From("tasks t").
+		Where(sq.Eq{"t.id": event.TaskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build notifyChange query for task event %s: %w", event.ID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("notify task change: %w", err)
+	}
+
+	return nil
+}
+
+// ListenChanges listens on TaskChangesChannel on a dedicated connection and passes
+// every change to handle, in commit order, until ctx is done or the connection
+// fails. ready is called once the listener is registered. Always returns an error:
+// ctx.Err() after cancellation, else the connection error.
+func (r *PgTaskEventRepository) ListenChanges(ctx context.Context, ready func(), handle func(*domain.TaskChange)) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	// The connection leaves the pool: after a cancelled wait it cannot be reused
+	pgConn := conn.Hijack()
+	defer func() {
+		_ = pgConn.Close(context.Background())
+	}()
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{TaskChangesChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen on %s: %w", TaskChangesChannel, err)
+	}
+	ready()
+
+	for {
+		notification, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		var change domain.TaskChange
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			slog.Warn("skipping malformed task change", "payload", notification.Payload, "error", err)
+			continue
+		}
+		handle(&change)
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// deadlineLengthExpr is the status deadline of a task (aliased t) in its
+// workspace (aliased w), as configured now.
+const deadlineLengthExpr = `(w.status_deadlines ->> t.status::text)::int * INTERVAL '1 minute'`
+
+// FindDeadlineWarnings finds tasks whose status deadline has not passed yet but
+// has no more than the workspace's deadline_warning_percent left, and that were
+// not warned about it yet, outside archived workspaces. The share is measured
+// against the workspace's current deadline for the task's status.
+func (r *PgTaskRepository) FindDeadlineWarnings(ctx context.Context) ([]*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumnsOf("t")...).
+		From("tasks t").
+		Join("workspaces w ON w.id = t.workspace_id").
+		Where(sq.Eq{"t.status": []domain.TaskStatus{
+			domain.TaskStatusNew,
+			domain.TaskStatusInProgress,
+			domain.TaskStatusBlocked,
+		}, "t.deleted_at": nil}).
+		Where("w.archived_at IS NULL AND w.deadline_warning_percent IS NOT NULL").
+		Where("t.status_deadline_at > NOW()").
+		Where("(w.status_deadlines ->> t.status::text)::int > 0").
+		Where("t.status_deadline_at - " + deadlineLengthExpr + " * w.deadline_warning_percent / 100 <= NOW()").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM task_events e
+			WHERE e.task_id = t.id AND e.type = 'deadline_warning'
+			  AND e.created_at >= t.status_deadline_at - ` + deadlineLengthExpr + `
+		)`).
+		OrderBy("t.status_deadline_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindDeadlineWarnings query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query deadline warnings: %w", err)
+	}
+
+	return scanTasks(rows)
+}
+
+// HasDeadlineWarning reports whether a task got a deadline_warning event at or
+// after since (within transaction).
+func (r *PgTaskRepository) HasDeadlineWarning(ctx context.Context, tx pgx.Tx, taskID string, since time.Time) (bool, error) {
+	query, args, err := psql.
+		Select().
+		Column(sq.Expr(
+			"EXISTS (SELECT 1 FROM task_events WHERE task_id = ? AND type = ? AND created_at >= ?)",
+			taskID, domain.EventTypeDeadlineWarning, since,
+		)).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build HasDeadlineWarning query for task %s: %w", taskID, err)
+	}
+
+	var warned bool
+	if err := tx.QueryRow(ctx, query, args...).Scan(&warned); err != nil {
+		return false, fmt.Errorf("query deadline warning: %w", err)
+	}
+
+	return warned, nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -11,26 +13,41 @@ import (
 	"github.com/mtlprog/sloptask/internal/domain"
 )
 
-// TaskEventRepository handles database operations for task events.
-type TaskEventRepository struct {
+// PgTaskEventRepository handles database operations for task events.
+type PgTaskEventRepository struct {
 	pool *pgxpool.Pool
 }
 
-// NewTaskEventRepository creates a new TaskEventRepository.
-func NewTaskEventRepository(pool *pgxpool.Pool) *TaskEventRepository {
-	return &TaskEventRepository{pool: pool}
+// NewTaskEventRepository creates a new PgTaskEventRepository.
+func NewTaskEventRepository(pool *pgxpool.Pool) *PgTaskEventRepository {
+	return &PgTaskEventRepository{pool: pool}
 }
 
-// Create creates a new task event.
-func (r *TaskEventRepository) Create(
+// Create creates a new task event, announces it on TaskChangesChannel once the
+// transaction commits and queues it for the workspace's event webhook, if any.
+func (r *PgTaskEventRepository) Create(
 	ctx context.Context,
 	tx pgx.Tx,
 	event *domain.TaskEvent,
 ) error {
+	// Store SQL NULL rather than JSON null when no payload is attached
+	var data any
+	if len(event.Data) > 0 {
+		data = event.Data
+	}
+
+	var traceParent, traceState *string
+	if event.Trace != nil {
+		traceParent = &event.Trace.TraceParent
+		if event.Trace.TraceState != "" {
+			traceState = &event.Trace.TraceState
+		}
+	}
+
 	query, args, err := psql.
 		Insert("task_events").
-		Columns("task_id", "actor_id", "type", "old_status", "new_status", "comment").
-		Values(event.TaskID, event.ActorID, event.Type, event.OldStatus, event.NewStatus, event.Comment).
+		Columns("task_id", "actor_id", "type", "old_status", "new_status", "comment", "data", "traceparent", "tracestate", "reply_to_event_id", "thread_id").
+		Values(event.TaskID, event.ActorID, event.Type, event.OldStatus, event.NewStatus, event.Comment, data, traceParent, traceState, event.ReplyToEventID, event.ThreadID).
 		Suffix("RETURNING id, created_at").
 		ToSql()
 	if err != nil {
@@ -42,13 +59,146 @@ func (r *TaskEventRepository) Create(
 		return fmt.Errorf("create task event: %w", err)
 	}
 
+	if err := r.notifyChange(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return r.enqueueOutbox(ctx, tx, event)
+}
+
+// CreateMentions records the agents a comment event mentions (within transaction).
+func (r *PgTaskEventRepository) CreateMentions(ctx context.Context, tx pgx.Tx, eventID string, agentIDs []string) error {
+	if len(agentIDs) == 0 {
+		return nil
+	}
+
+	insert := psql.Insert("task_mentions").Columns("event_id", "agent_id")
+	for _, agentID := range agentIDs {
+		insert = insert.Values(eventID, agentID)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("build CreateMentions query for event %s: %w", eventID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("create mentions: %w", err)
+	}
+
 	return nil
 }
 
+// eventColumns are the task_events columns read by scanEvent.
+var eventColumns = []string{
+	"id", "task_id", "actor_id", "type", "old_status", "new_status", "comment", "data",
+	"traceparent", "tracestate", "created_at", "edited_at", "redacted_at", "reply_to_event_id", "thread_id",
+}
+
+// scanEvent scans a single event selected with eventColumns. A missing row
+// becomes notFound.
+func scanEvent(row pgx.Row, notFound error) (*domain.TaskEvent, error) {
+	var event domain.TaskEvent
+	var traceParent, traceState *string
+	err := row.Scan(
+		&event.ID,
+		&event.TaskID,
+		&event.ActorID,
+		&event.Type,
+		&event.OldStatus,
+		&event.NewStatus,
+		&event.Comment,
+		&event.Data,
+		&traceParent,
+		&traceState,
+		&event.CreatedAt,
+		&event.EditedAt,
+		&event.RedactedAt,
+		&event.ReplyToEventID,
+		&event.ThreadID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, notFound
+		}
+		return nil, fmt.Errorf("scan task event: %w", err)
+	}
+	event.Trace = toTraceContext(traceParent, traceState)
+	return &event, nil
+}
+
+// GetComment retrieves a commented event of a task (within transaction).
+// Returns ErrCommentNotFound for other events.
+func (r *PgTaskEventRepository) GetComment(ctx context.Context, tx pgx.Tx, taskID, eventID string) (*domain.TaskEvent, error) {
+	query, args, err := psql.
+		Select(eventColumns...).
+		From("task_events").
+		Where(sq.Eq{"id": eventID, "task_id": taskID, "type": domain.EventTypeCommented}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetComment query for event %s: %w", eventID, err)
+	}
+
+	return scanEvent(tx.QueryRow(ctx, query, args...), domain.ErrCommentNotFound)
+}
+
+// GetCommentForUpdate retrieves a commented event of a task with FOR UPDATE lock
+// (within transaction). Returns ErrCommentNotFound for other events.
+func (r *PgTaskEventRepository) GetCommentForUpdate(ctx context.Context, tx pgx.Tx, taskID, eventID string) (*domain.TaskEvent, error) {
+	query, args, err := psql.
+		Select(eventColumns...).
+		From("task_events").
+		Where(sq.Eq{"id": eventID, "task_id": taskID, "type": domain.EventTypeCommented}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetCommentForUpdate query for event %s: %w", eventID, err)
+	}
+
+	return scanEvent(tx.QueryRow(ctx, query, args...), domain.ErrCommentNotFound)
+}
+
+// GetLastStatusChange retrieves the latest event of a task that moved it to
+// newStatus (within transaction). Returns nil if there is none.
+func (r *PgTaskEventRepository) GetLastStatusChange(ctx context.Context, tx pgx.Tx, taskID string, newStatus domain.TaskStatus) (*domain.TaskEvent, error) {
+	query, args, err := psql.
+		Select(eventColumns...).
+		From("task_events").
+		Where(sq.Eq{"task_id": taskID, "new_status": newStatus}).
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetLastStatusChange query for task %s: %w", taskID, err)
+	}
+
+	return scanEvent(tx.QueryRow(ctx, query, args...), nil)
+}
+
+// UpdateComment replaces the body of a comment event (within transaction),
+// keeping the previous body in previous_comments. Redacting also sets redacted_at.
+func (r *PgTaskEventRepository) UpdateComment(ctx context.Context, tx pgx.Tx, eventID, comment string, redact bool) (*domain.TaskEvent, error) {
+	update := psql.
+		Update("task_events").
+		Set("previous_comments", sq.Expr("array_append(previous_comments, comment)")).
+		Set("comment", comment).
+		Set("edited_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": eventID})
+	if redact {
+		update = update.Set("redacted_at", sq.Expr("NOW()"))
+	}
+
+	query, args, err := update.Suffix("RETURNING " + strings.Join(eventColumns, ", ")).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build UpdateComment query for event %s: %w", eventID, err)
+	}
+
+	return scanEvent(tx.QueryRow(ctx, query, args...), domain.ErrCommentNotFound)
+}
+
 // GetByTaskID retrieves all events for a task.
-func (r *TaskEventRepository) GetByTaskID(ctx context.Context, taskID string) ([]*domain.TaskEvent, error) {
+func (r *PgTaskEventRepository) GetByTaskID(ctx context.Context, taskID string) ([]*domain.TaskEvent, error) {
 	query, args, err := psql.
-		Select("id", "task_id", "actor_id", "type", "old_status", "new_status", "comment", "created_at").
+		Select("id", "task_id", "actor_id", "type", "old_status", "new_status", "comment", "data", "traceparent", "tracestate", "created_at").
 		From("task_events").
 		Where(sq.Eq{"task_id": taskID}).
 		OrderBy("created_at ASC").
@@ -66,6 +216,7 @@ func (r *TaskEventRepository) GetByTaskID(ctx context.Context, taskID string) ([
 	var events []*domain.TaskEvent
 	for rows.Next() {
 		var event domain.TaskEvent
+		var traceParent, traceState *string
 		err := rows.Scan(
 			&event.ID,
 			&event.TaskID,
@@ -74,11 +225,15 @@ func (r *TaskEventRepository) GetByTaskID(ctx context.Context, taskID string) ([
 			&event.OldStatus,
 			&event.NewStatus,
 			&event.Comment,
+			&event.Data,
+			&traceParent,
+			&traceState,
 			&event.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan task event: %w", err)
 		}
+		event.Trace = toTraceContext(traceParent, traceState)
 		events = append(events, &event)
 	}
 
@@ -89,6 +244,18 @@ func (r *TaskEventRepository) GetByTaskID(ctx context.Context, taskID string) ([
 	return events, nil
 }
 
+// toTraceContext builds an event's trace context from its nullable columns.
+func toTraceContext(traceParent, traceState *string) *domain.TraceContext {
+	if traceParent == nil {
+		return nil
+	}
+	tc := &domain.TraceContext{TraceParent: *traceParent}
+	if traceState != nil {
+		tc.TraceState = *traceState
+	}
+	return tc
+}
+
 // TaskEventWithActor extends TaskEvent with actor name.
 type TaskEventWithActor struct {
 	ID        string
@@ -99,15 +266,27 @@ type TaskEventWithActor struct {
 	OldStatus *domain.TaskStatus
 	NewStatus *domain.TaskStatus
 	Comment   string
+	Data      map[string]any
+	Trace     *domain.TraceContext
 	CreatedAt time.Time
+
+	// Set on comments the author edited or redacted
+	EditedAt   *time.Time
+	RedactedAt *time.Time
+
+	// Set on replies
+	ReplyToEventID *string
+	ThreadID       *string
 }
 
 // GetByTaskIDWithActors retrieves all events for a task with actor names.
-func (r *TaskEventRepository) GetByTaskIDWithActors(ctx context.Context, taskID string) ([]TaskEventWithActor, error) {
+func (r *PgTaskEventRepository) GetByTaskIDWithActors(ctx context.Context, taskID string) ([]TaskEventWithActor, error) {
 	query := `
 		SELECT
 			te.id, te.task_id, te.actor_id, a.name as actor_name,
-			te.type, te.old_status, te.new_status, te.comment, te.created_at
+			te.type, te.old_status, te.new_status, te.comment, te.data,
+			te.traceparent, te.tracestate, te.created_at, te.edited_at, te.redacted_at,
+			te.reply_to_event_id, te.thread_id
 		FROM task_events te
 		LEFT JOIN agents a ON te.actor_id = a.id
 		WHERE te.task_id = $1
@@ -123,6 +302,7 @@ func (r *TaskEventRepository) GetByTaskIDWithActors(ctx context.Context, taskID
 	var events []TaskEventWithActor
 	for rows.Next() {
 		var event TaskEventWithActor
+		var traceParent, traceState *string
 		err := rows.Scan(
 			&event.ID,
 			&event.TaskID,
@@ -132,11 +312,19 @@ func (r *TaskEventRepository) GetByTaskIDWithActors(ctx context.Context, taskID
 			&event.OldStatus,
 			&event.NewStatus,
 			&event.Comment,
+			&event.Data,
+			&traceParent,
+			&traceState,
 			&event.CreatedAt,
+			&event.EditedAt,
+			&event.RedactedAt,
+			&event.ReplyToEventID,
+			&event.ThreadID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan task event with actor: %w", err)
 		}
+		event.Trace = toTraceContext(traceParent, traceState)
 		events = append(events, event)
 	}
 
@@ -146,3 +334,110 @@ func (r *TaskEventRepository) GetByTaskIDWithActors(ctx context.Context, taskID
 
 	return events, nil
 }
+
+// TaskEventListFilters holds filters for paginated event listing.
+type TaskEventListFilters struct {
+	TaskID string             // Required: filter by task
+	Types  []domain.EventType // Optional: filter by event type
+	Thread *string            // Optional: only this event and the replies in its thread
+	Limit  int                // Required: page size
+	Offset int                // Required: page offset
+
+	NewestFirst bool                // list newest events first instead of oldest
+	Before      *domain.EventCursor // Optional: only events older than the cursor
+}
+
+// ListByTaskIDWithActors retrieves a page of events for a task with actor names.
+// Returns the events and the total number of events matching the filters,
+// not counting the Before cursor.
+func (r *PgTaskEventRepository) ListByTaskIDWithActors(ctx context.Context, filters TaskEventListFilters) ([]TaskEventWithActor, int, error) {
+	where := sq.And{sq.Eq{"te.task_id": filters.TaskID}}
+	if len(filters.Types) > 0 {
+		where = append(where, sq.Eq{"te.type": filters.Types})
+	}
+	if filters.Thread != nil {
+		where = append(where, sq.Or{sq.Eq{"te.id": *filters.Thread}, sq.Eq{"te.thread_id": *filters.Thread}})
+	}
+
+	page := append(sq.And{}, where...)
+	if filters.Before != nil {
+		page = append(page, sq.Expr("(te.created_at, te.id) < (?, ?)", filters.Before.CreatedAt, filters.Before.ID))
+	}
+	order := []string{"te.created_at ASC", "te.id ASC"}
+	if filters.NewestFirst {
+		order = []string{"te.created_at DESC", "te.id DESC"}
+	}
+
+	query, args, err := psql.
+		Select(
+			"te.id", "te.task_id", "te.actor_id", "a.name",
+			"te.type", "te.old_status", "te.new_status", "te.comment", "te.data",
+			"te.traceparent", "te.tracestate", "te.created_at", "te.edited_at", "te.redacted_at",
+			"te.reply_to_event_id", "te.thread_id",
+		).
+		From("task_events te").
+		LeftJoin("agents a ON te.actor_id = a.id").
+		Where(page).
+		OrderBy(order...).
+		Limit(uint64(filters.Limit)).
+		Offset(uint64(filters.Offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build ListByTaskIDWithActors query for task %s: %w", filters.TaskID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query task events with actors: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]TaskEventWithActor, 0)
+	for rows.Next() {
+		var event TaskEventWithActor
+		var traceParent, traceState *string
+		err := rows.Scan(
+			&event.ID,
+			&event.TaskID,
+			&event.ActorID,
+			&event.ActorName,
+			&event.Type,
+			&event.OldStatus,
+			&event.NewStatus,
+			&event.Comment,
+			&event.Data,
+			&traceParent,
+			&traceState,
+			&event.CreatedAt,
+			&event.EditedAt,
+			&event.RedactedAt,
+			&event.ReplyToEventID,
+			&event.ThreadID,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan task event with actor: %w", err)
+		}
+		event.Trace = toTraceContext(traceParent, traceState)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	countQuery, countArgs, err := psql.
+		Select("COUNT(*)").
+		From("task_events te").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build count query for task events: %w", err)
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count task events: %w", err)
+	}
+
+	return events, total, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// inheritablePriorities are the priorities a blocker can inherit from the tasks it blocks.
+var inheritablePriorities = []string{string(domain.TaskPriorityCritical), string(domain.TaskPriorityHigh)}
+
+// PriorityInheritanceState is what priority inheritance needs to know about a task.
+type PriorityInheritanceState struct {
+	TaskID            string
+	Enabled           bool // the workspace has priority inheritance on
+	Open              bool // not DONE, CANCELLED or deleted
+	Priority          domain.TaskPriority
+	InheritedPriority *domain.TaskPriority
+	BlockedBy         []string
+}
+
+// GetPriorityInheritanceState reads a task's priorities, blockers and workspace
+// setting (within transaction). Deleted tasks are found too, as closed.
+func (r *PgTaskRepository) GetPriorityInheritanceState(ctx context.Context, tx pgx.Tx, taskID string) (*PriorityInheritanceState, error) {
+	query, args, err := psql.
+		Select(
+			"t.priority", "t.inherited_priority", blockedByExpr("t"),
+			"t.status NOT IN ('DONE', 'CANCELLED') AND t.deleted_at IS NULL",
+			"w.priority_inheritance",
+		).
+		From("tasks t").
+		Join("workspaces w ON w.id = t.workspace_id").
+		Where(sq.Eq{"t.id": taskID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetPriorityInheritanceState query for task %s: %w", taskID, err)
+	}
+
+	state := &PriorityInheritanceState{TaskID: taskID}
+	err = tx.QueryRow(ctx, query, args...).Scan(
+		&state.Priority, &state.InheritedPriority, &state.BlockedBy, &state.Open, &state.Enabled,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("query priority inheritance state: %w", err)
+	}
+
+	return state, nil
+}
+
+// FindPriorityInheritanceSource returns the most urgent open task blocked by
+// blockerID whose effective priority is high or critical (within transaction).
+// Returns ErrTaskNotFound if there is none.
+func (r *PgTaskRepository) FindPriorityInheritanceSource(ctx context.Context, tx pgx.Tx, blockerID string) (*domain.Task, error) {
+	query, args, err := psql.
+		Select(taskColumns...).
+		From("tasks").
+		Where(sq.Expr("EXISTS (SELECT 1 FROM task_dependencies d WHERE d.task_id = tasks.id AND d.blocker_id = ?)", blockerID)).
+		Where(sq.NotEq{"status": []domain.TaskStatus{domain.TaskStatusDone, domain.TaskStatusCancelled}}).
+		Where(sq.Expr("COALESCE(inherited_priority, priority) = ANY(?)", inheritablePriorities)).
+		Where(notDeleted).
+		OrderBy(effectivePriorityRank+" ASC", "created_at ASC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build FindPriorityInheritanceSource query for task %s: %w", blockerID, err)
+	}
+
+	return scanTask(tx.QueryRow(ctx, query, args...))
+}
+
+// SetInheritedPriority sets or, with nil, clears a task's inherited priority (within transaction).
+func (r *PgTaskRepository) SetInheritedPriority(ctx context.Context, tx pgx.Tx, taskID string, priority *domain.TaskPriority) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("inherited_priority", priority).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetInheritedPriority query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set inherited priority: %w", err)
+	}
+
+	return nil
+}
+
+// FindPriorityInheritanceCandidates returns the tasks of a workspace whose
+// inherited priority may need to change when the setting is toggled: those
+// holding one, and the blockers of open high and critical tasks (within transaction).
+func (r *PgTaskRepository) FindPriorityInheritanceCandidates(ctx context.Context, tx pgx.Tx, workspaceID string) ([]string, error) {
+	query := `
+		SELECT id FROM tasks
+		WHERE workspace_id = $1 AND deleted_at IS NULL
+			AND (inherited_priority IS NOT NULL OR id IN (
+				SELECT dep.blocker_id FROM task_dependencies dep
+				JOIN tasks d ON d.id = dep.task_id
+				WHERE d.workspace_id = $1 AND d.deleted_at IS NULL
+					AND d.status NOT IN ('DONE', 'CANCELLED')
+					AND d.priority = ANY($2)
+			))
+		ORDER BY created_at`
+
+	rows, err := tx.Query(ctx, query, workspaceID, inheritablePriorities)
+	if err != nil {
+		return nil, fmt.Errorf("query priority inheritance candidates: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan priority inheritance candidates: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ResolveTaskRef returns the ID of the task ref names, either by its ID or by
+// its key such as TST-142. Returns ErrValidation if ref is neither and
+// ErrTaskNotFound if no task has the key.
+func ResolveTaskRef(ctx context.Context, tasks TaskRepository, ref string) (string, error) {
+	if _, err := uuid.Parse(ref); err == nil {
+		return ref, nil
+	}
+
+	slug, number, ok := domain.ParseTaskKey(ref)
+	if !ok {
+		return "", fmt.Errorf("%w: %q is neither a task ID nor a task key such as TST-42", domain.ErrValidation, ref)
+	}
+	return tasks.GetIDByKey(ctx, slug, number)
+}
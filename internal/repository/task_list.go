@@ -8,6 +8,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/mtlprog/sloptask/internal/domain"
 )
 
@@ -22,20 +23,40 @@ var allowedSortFields = map[string]bool{
 	"title":      true,
 }
 
+// Archived task modes for TaskListFilters.Archived.
+const (
+	ArchivedExclude = "exclude" // default: only live tasks
+	ArchivedInclude = "include" // live and archived tasks
+	ArchivedOnly    = "only"    // only archived tasks
+)
+
 // TaskListFilters holds all supported filters for task listing.
 type TaskListFilters struct {
 	WorkspaceID           string   // Required: filter by workspace
-	AgentID               string   // Required: for filtering private tasks
+	AgentID               string   // Filters private tasks; empty limits the list to public tasks
+	IDs                   []string // Optional: only these tasks
 	Statuses              []string // Optional: filter by status
 	AssigneeID            *string  // Optional: filter by assignee
 	Unassigned            bool     // Optional: show only unassigned
+	CreatorID             *string  // Optional: filter by creator
+	BlockedBy             *string  // Optional: only tasks this task blocks
+	Blocks                *string  // Optional: only the blockers of this task
 	Visibility            *string  // Optional: filter by visibility
 	Priorities            []string // Optional: filter by priority
+	Queue                 *string  // Optional: filter by queue name
+	Labels                []string // Optional: only tasks carrying all of these labels
+	Archived              string   // Optional: ArchivedExclude (default), ArchivedInclude or ArchivedOnly
 	Overdue               bool     // Optional: show only overdue
+	HumanReview           bool     // Optional: show only tasks held for human review
 	HasUnresolvedBlockers bool     // Optional: show only with unresolved blockers
 	Sort                  []string // Optional: sort fields (with - prefix for DESC)
 	Limit                 int      // Required: page size
 	Offset                int      // Required: page offset
+
+	// Optional time ranges
+	CreatedAfter  *time.Time // created at or after
+	CreatedBefore *time.Time // created before
+	UpdatedAfter  *time.Time // updated at or after
 }
 
 // TaskListResult holds a task with computed fields.
@@ -47,7 +68,7 @@ type TaskListResult struct {
 
 // batchLoadBlockers fetches all blocker tasks in a single query.
 // Returns a map of task_id -> list of blocker tasks.
-func (r *TaskRepository) batchLoadBlockers(ctx context.Context, tasks []*domain.Task) (map[string][]*domain.Task, error) {
+func (r *PgTaskRepository) batchLoadBlockers(ctx context.Context, tasks []*domain.Task) (map[string][]*domain.Task, error) {
 	// Collect all unique blocker IDs
 	allBlockerIDs := make([]string, 0)
 	seen := make(map[string]bool)
@@ -92,32 +113,83 @@ func (r *TaskRepository) batchLoadBlockers(ctx context.Context, tasks []*domain.
 	return result, nil
 }
 
-// List retrieves tasks with filters and pagination.
-func (r *TaskRepository) List(ctx context.Context, filters TaskListFilters) ([]TaskListResult, int, error) {
-	// Build base query
-	qb := psql.Select(taskColumns...).From("tasks").
-		Where(sq.Eq{"workspace_id": filters.WorkspaceID})
+// withTotal scans the COUNT(*) OVER () column List adds after the task
+// columns into total.
+type withTotal struct {
+	pgx.Rows
+	total *int
+}
+
+func (r withTotal) Scan(dest ...any) error {
+	return r.Rows.Scan(append(dest, r.total)...)
+}
+
+// hasUnresolvedBlockers matches tasks waiting on a live blocker that isn't DONE.
+const hasUnresolvedBlockers = `EXISTS (SELECT 1 FROM task_dependencies d
+	JOIN tasks b ON b.id = d.blocker_id
+	WHERE d.task_id = tasks.id AND b.deleted_at IS NULL AND b.status <> 'DONE')`
+
+// listConditions returns the conditions selecting the tasks filters lists,
+// shared by List, its count and Facets.
+func listConditions(filters TaskListFilters) sq.And {
+	where := sq.And{
+		sq.Eq{"workspace_id": filters.WorkspaceID},
+		notDeleted,
+	}
+
+	// Apply ID filter
+	if len(filters.IDs) > 0 {
+		where = append(where, sq.Eq{"id": filters.IDs})
+	}
 
 	// Apply status filter
 	if len(filters.Statuses) > 0 {
-		qb = qb.Where(sq.Eq{"status": filters.Statuses})
+		where = append(where, sq.Eq{"status": filters.Statuses})
 	}
 
 	// Apply assignee filter
 	if filters.Unassigned {
-		qb = qb.Where(sq.Eq{"assignee_id": nil})
+		where = append(where, sq.Eq{"assignee_id": nil})
 	} else if filters.AssigneeID != nil {
-		qb = qb.Where(sq.Eq{"assignee_id": *filters.AssigneeID})
+		where = append(where, sq.Eq{"assignee_id": *filters.AssigneeID})
+	}
+
+	// Apply creator filter
+	if filters.CreatorID != nil {
+		where = append(where, sq.Eq{"creator_id": *filters.CreatorID})
+	}
+
+	// Apply dependency filters
+	if filters.BlockedBy != nil {
+		where = append(where, sq.Expr("EXISTS (SELECT 1 FROM task_dependencies d WHERE d.task_id = tasks.id AND d.blocker_id = ?)", *filters.BlockedBy))
+	}
+	if filters.Blocks != nil {
+		where = append(where, sq.Expr("EXISTS (SELECT 1 FROM task_dependencies d WHERE d.blocker_id = tasks.id AND d.task_id = ?)", *filters.Blocks))
+	}
+
+	// Apply date range filters
+	if filters.CreatedAfter != nil {
+		where = append(where, sq.GtOrEq{"created_at": *filters.CreatedAfter})
+	}
+	if filters.CreatedBefore != nil {
+		where = append(where, sq.Lt{"created_at": *filters.CreatedBefore})
+	}
+	if filters.UpdatedAfter != nil {
+		where = append(where, sq.GtOrEq{"updated_at": *filters.UpdatedAfter})
 	}
 
 	// Apply visibility filter with agent context
 	// SECURITY: Prevent private task leaks to unauthorized agents
-	if filters.Visibility != nil {
-		qb = qb.Where(sq.Eq{"visibility": *filters.Visibility})
+	if filters.AgentID == "" {
+		// Read-only callers without an agent only ever see public tasks
+		where = append(where, sq.Eq{"visibility": "public"})
+		if filters.Visibility != nil {
+			where = append(where, sq.Eq{"visibility": *filters.Visibility})
+		}
 	} else {
-		// When no visibility specified, filter out private tasks
-		// that the agent is not creator or assignee of
-		qb = qb.Where(sq.Or{
+		// Agents see public tasks and the private ones they created or are
+		// assigned, whatever visibility they ask for
+		where = append(where, sq.Or{
 			sq.Eq{"visibility": "public"},
 			sq.And{
 				sq.Eq{"visibility": "private"},
@@ -127,21 +199,62 @@ func (r *TaskRepository) List(ctx context.Context, filters TaskListFilters) ([]T
 				},
 			},
 		})
+		if filters.Visibility != nil {
+			where = append(where, sq.Eq{"visibility": *filters.Visibility})
+		}
 	}
 
 	// Apply priority filter
 	if len(filters.Priorities) > 0 {
-		qb = qb.Where(sq.Eq{"priority": filters.Priorities})
+		where = append(where, sq.Eq{"priority": filters.Priorities})
+	}
+
+	// Apply queue filter
+	if filters.Queue != nil {
+		where = append(where, sq.Eq{"queue": *filters.Queue})
+	}
+
+	// Apply label filter
+	if len(filters.Labels) > 0 {
+		where = append(where, sq.Expr("labels @> ?::text[]", filters.Labels))
+	}
+
+	// Apply archived filter (archived tasks are hidden by default)
+	switch filters.Archived {
+	case ArchivedInclude:
+	case ArchivedOnly:
+		where = append(where, sq.NotEq{"archived_at": nil})
+	default:
+		where = append(where, sq.Eq{"archived_at": nil})
 	}
 
 	// Apply overdue filter
 	if filters.Overdue {
-		qb = qb.Where("status_deadline_at < NOW()")
+		where = append(where, sq.Expr("status_deadline_at < NOW()"))
+	}
+
+	// Apply human review filter
+	if filters.HumanReview {
+		where = append(where, sq.NotEq{"human_review_at": nil})
 	}
 
+	// Apply unresolved blockers filter
+	if filters.HasUnresolvedBlockers {
+		where = append(where, sq.Expr(hasUnresolvedBlockers))
+	}
+
+	return where
+}
+
+// List retrieves tasks with filters and pagination.
+func (r *PgTaskRepository) List(ctx context.Context, filters TaskListFilters) ([]TaskListResult, int, error) {
+	// The total rides along on every row: window functions run before LIMIT
+	where := listConditions(filters)
+	qb := psql.Select(taskColumns...).Column("COUNT(*) OVER () AS total").From("tasks").Where(where)
+
 	// Apply sorting (default: -priority,created_at)
 	if len(filters.Sort) == 0 {
-		qb = qb.OrderBy("CASE priority WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'normal' THEN 3 WHEN 'low' THEN 4 END ASC")
+		qb = qb.OrderBy(effectivePriorityRank + " ASC")
 		qb = qb.OrderBy("created_at ASC")
 	} else {
 		for _, sort := range filters.Sort {
@@ -161,9 +274,9 @@ func (r *TaskRepository) List(ctx context.Context, filters TaskListFilters) ([]T
 			if field == "priority" {
 				// Special CASE handling for priority sorting
 				if descending {
-					qb = qb.OrderBy("CASE priority WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'normal' THEN 3 WHEN 'low' THEN 4 END DESC")
+					qb = qb.OrderBy(effectivePriorityRank + " DESC")
 				} else {
-					qb = qb.OrderBy("CASE priority WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'normal' THEN 3 WHEN 'low' THEN 4 END ASC")
+					qb = qb.OrderBy(effectivePriorityRank + " ASC")
 				}
 			} else {
 				if descending {
@@ -189,54 +302,21 @@ func (r *TaskRepository) List(ctx context.Context, filters TaskListFilters) ([]T
 		return nil, 0, fmt.Errorf("query tasks: %w", err)
 	}
 
-	tasks, err := scanTasks(rows)
+	var total int
+	tasks, err := scanTasks(withTotal{Rows: rows, total: &total})
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get total count (without pagination)
-	countQb := psql.Select("COUNT(*)").From("tasks").
-		Where(sq.Eq{"workspace_id": filters.WorkspaceID})
-
-	// Apply same filters for count
-	if len(filters.Statuses) > 0 {
-		countQb = countQb.Where(sq.Eq{"status": filters.Statuses})
-	}
-	if filters.Unassigned {
-		countQb = countQb.Where(sq.Eq{"assignee_id": nil})
-	} else if filters.AssigneeID != nil {
-		countQb = countQb.Where(sq.Eq{"assignee_id": *filters.AssigneeID})
-	}
-	// Apply visibility filter with agent context (same as main query)
-	if filters.Visibility != nil {
-		countQb = countQb.Where(sq.Eq{"visibility": *filters.Visibility})
-	} else {
-		countQb = countQb.Where(sq.Or{
-			sq.Eq{"visibility": "public"},
-			sq.And{
-				sq.Eq{"visibility": "private"},
-				sq.Or{
-					sq.Eq{"creator_id": filters.AgentID},
-					sq.Eq{"assignee_id": filters.AgentID},
-				},
-			},
-		})
-	}
-	if len(filters.Priorities) > 0 {
-		countQb = countQb.Where(sq.Eq{"priority": filters.Priorities})
-	}
-	if filters.Overdue {
-		countQb = countQb.Where("status_deadline_at < NOW()")
-	}
-
-	countQuery, countArgs, err := countQb.ToSql()
-	if err != nil {
-		return nil, 0, fmt.Errorf("build count query: %w", err)
-	}
-
-	var total int
-	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("count tasks: %w", err)
+	// A page past the end has no row to carry the total
+	if len(tasks) == 0 && filters.Offset > 0 {
+		countQuery, countArgs, err := psql.Select("COUNT(*)").From("tasks").Where(where).ToSql()
+		if err != nil {
+			return nil, 0, fmt.Errorf("build count query: %w", err)
+		}
+		if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count tasks: %w", err)
+		}
 	}
 
 	// Batch load all blockers at once (fixes N+1 query problem)
@@ -267,17 +347,75 @@ func (r *TaskRepository) List(ctx context.Context, filters TaskListFilters) ([]T
 		}
 	}
 
-	// Apply has_unresolved_blockers filter if requested
-	if filters.HasUnresolvedBlockers {
-		filtered := make([]TaskListResult, 0)
-		for _, result := range results {
-			if result.HasUnresolvedBlockers {
-				filtered = append(filtered, result)
-			}
+	return results, total, nil
+}
+
+// TaskFacets counts the tasks a list filter selects, by facet value.
+type TaskFacets struct {
+	Total      int
+	Status     map[string]int
+	Priority   map[string]int
+	Assignee   map[string]int // by agent ID
+	Unassigned int
+	Label      map[string]int
+}
+
+// facetCounts groups the filtered tasks by each facet in turn; a task counts
+// once per label it carries.
+const facetCounts = `
+SELECT 'status', status, COUNT(*) FROM filtered GROUP BY status
+UNION ALL SELECT 'priority', priority, COUNT(*) FROM filtered GROUP BY priority
+UNION ALL SELECT 'assignee', assignee_id::text, COUNT(*) FROM filtered GROUP BY assignee_id
+UNION ALL SELECT 'label', label, COUNT(*) FROM filtered, unnest(labels) AS label GROUP BY label`
+
+// Facets counts the tasks filters selects by status, priority, assignee and
+// label in one query. Sort and pagination are ignored.
+func (r *PgTaskRepository) Facets(ctx context.Context, filters TaskListFilters) (*TaskFacets, error) {
+	filtered, args, err := psql.
+		Select("status", "priority", "assignee_id", "labels").
+		From("tasks").
+		Where(listConditions(filters)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build Facets query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, "WITH filtered AS ("+filtered+")"+facetCounts, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query task facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &TaskFacets{
+		Status:   map[string]int{},
+		Priority: map[string]int{},
+		Assignee: map[string]int{},
+		Label:    map[string]int{},
+	}
+	for rows.Next() {
+		var facet string
+		var value *string
+		var count int
+		if err := rows.Scan(&facet, &value, &count); err != nil {
+			return nil, fmt.Errorf("scan task facet: %w", err)
+		}
+		switch {
+		case facet == "status":
+			facets.Status[*value] = count
+			facets.Total += count
+		case facet == "priority":
+			facets.Priority[*value] = count
+		case facet == "assignee" && value == nil:
+			facets.Unassigned = count
+		case facet == "assignee":
+			facets.Assignee[*value] = count
+		case facet == "label":
+			facets.Label[*value] = count
 		}
-		results = filtered
-		total = len(results)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task facets: %w", err)
 	}
 
-	return results, total, nil
+	return facets, nil
 }
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// taskRevisionColumns is the shared list of columns for task revision queries.
+var taskRevisionColumns = []string{
+	"id", "task_id", "revision", "title", "description", "author_id", "created_at",
+}
+
+// UpdateText replaces the title and description of a task (within transaction).
+func (r *PgTaskRepository) UpdateText(ctx context.Context, tx pgx.Tx, taskID, title, description string) error {
+	query, args, err := psql.
+		Update("tasks").
+		Set("title", title).
+		Set("description", description).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": taskID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build UpdateText query for task %s: %w", taskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("update task text: %w", err)
+	}
+
+	return nil
+}
+
+// LatestRevision returns the highest revision number of a task, 0 if it was never edited
+// (within transaction). The caller must hold a lock on the task.
+func (r *PgTaskRepository) LatestRevision(ctx context.Context, tx pgx.Tx, taskID string) (int, error) {
+	query, args, err := psql.
+		Select("COALESCE(MAX(revision), 0)").
+		From("task_revisions").
+		Where(sq.Eq{"task_id": taskID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build LatestRevision query for task %s: %w", taskID, err)
+	}
+
+	var revision int
+	if err := tx.QueryRow(ctx, query, args...).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("get latest task revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// CreateRevision inserts a task revision and populates ID and CreatedAt (within transaction).
+// A zero CreatedAt is set to the current time.
+func (r *PgTaskRepository) CreateRevision(ctx context.Context, tx pgx.Tx, revision *domain.TaskRevision) error {
+	var createdAt any = sq.Expr("NOW()")
+	if !revision.CreatedAt.IsZero() {
+		createdAt = revision.CreatedAt
+	}
+
+	query, args, err := psql.
+		Insert("task_revisions").
+		Columns("task_id", "revision", "title", "description", "author_id", "created_at").
+		Values(revision.TaskID, revision.Revision, revision.Title, revision.Description, revision.AuthorID, createdAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build CreateRevision query for task %s: %w", revision.TaskID, err)
+	}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&revision.ID, &revision.CreatedAt); err != nil {
+		return fmt.Errorf("create task revision: %w", err)
+	}
+
+	return nil
+}
+
+// ListRevisions returns the revisions of a task, oldest first.
+func (r *PgTaskRepository) ListRevisions(ctx context.Context, taskID string) ([]*domain.TaskRevision, error) {
+	query, args, err := psql.
+		Select(taskRevisionColumns...).
+		From("task_revisions").
+		Where(sq.Eq{"task_id": taskID}).
+		OrderBy("revision ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListRevisions query for task %s: %w", taskID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query task revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*domain.TaskRevision{}
+	for rows.Next() {
+		var revision domain.TaskRevision
+		if err := rows.Scan(
+			&revision.ID,
+			&revision.TaskID,
+			&revision.Revision,
+			&revision.Title,
+			&revision.Description,
+			&revision.AuthorID,
+			&revision.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan task revision: %w", err)
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task revisions: %w", err)
+	}
+
+	return revisions, nil
+}
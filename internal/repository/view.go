@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// viewColumns is the shared list of columns for saved view queries.
+var viewColumns = []string{"id", "agent_id", "name", "params", "created_at", "updated_at"}
+
+// ViewRepository handles database operations for saved views.
+type ViewRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewViewRepository creates a new ViewRepository.
+func NewViewRepository(pool *pgxpool.Pool) *ViewRepository {
+	return &ViewRepository{pool: pool}
+}
+
+// scanView scans a single row into a SavedView struct.
+func scanView(row pgx.Row) (*domain.SavedView, error) {
+	var view domain.SavedView
+	err := row.Scan(
+		&view.ID,
+		&view.AgentID,
+		&view.Name,
+		&view.Params,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrViewNotFound
+		}
+		return nil, fmt.Errorf("scan view: %w", err)
+	}
+	return &view, nil
+}
+
+// Create inserts a new view and populates ID, CreatedAt and UpdatedAt.
+func (r *ViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	query, args, err := psql.
+		Insert("saved_views").
+		Columns("agent_id", "name", "params").
+		Values(view.AgentID, view.Name, view.Params).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for view: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&view.ID, &view.CreatedAt, &view.UpdatedAt)
+	if err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrViewExists, view.Name)
+		}
+		return fmt.Errorf("create view: %w", err)
+	}
+
+	return nil
+}
+
+// GetByName retrieves a view of an agent by name.
+func (r *ViewRepository) GetByName(ctx context.Context, agentID, name string) (*domain.SavedView, error) {
+	query, args, err := psql.
+		Select(viewColumns...).
+		From("saved_views").
+		Where(sq.Eq{"agent_id": agentID, "name": name}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByName query for view %s: %w", name, err)
+	}
+
+	return scanView(r.pool.QueryRow(ctx, query, args...))
+}
+
+// ListByAgent returns all views of an agent ordered by name.
+func (r *ViewRepository) ListByAgent(ctx context.Context, agentID string) ([]*domain.SavedView, error) {
+	query, args, err := psql.
+		Select(viewColumns...).
+		From("saved_views").
+		Where(sq.Eq{"agent_id": agentID}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListByAgent query for views: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []*domain.SavedView{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate views: %w", err)
+	}
+
+	return views, nil
+}
+
+// UpdateParams replaces the query parameters of a view.
+func (r *ViewRepository) UpdateParams(ctx context.Context, agentID, name string, params map[string]string) (*domain.SavedView, error) {
+	query, args, err := psql.
+		Update("saved_views").
+		Set("params", params).
+		Set("updated_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"agent_id": agentID, "name": name}).
+		Suffix("RETURNING " + strings.Join(viewColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build UpdateParams query for view %s: %w", name, err)
+	}
+
+	return scanView(r.pool.QueryRow(ctx, query, args...))
+}
+
+// Delete removes a view of an agent.
+func (r *ViewRepository) Delete(ctx context.Context, agentID, name string) error {
+	query, args, err := psql.
+		Delete("saved_views").
+		Where(sq.Eq{"agent_id": agentID, "name": name}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for view %s: %w", name, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete view: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrViewNotFound
+	}
+
+	return nil
+}
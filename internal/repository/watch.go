@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// notificationVisible limits events te of tasks t to those on live tasks the
+// agent ? can still see, leaving out the agent's own events.
+const notificationVisible = `t.deleted_at IS NULL
+	AND (t.visibility = 'public' OR t.creator_id = ? OR t.assignee_id = ?)
+	AND te.actor_id IS DISTINCT FROM ?`
+
+// eventWatched matches events te the agent ? watched: on its tasks, from the
+// time the watch started.
+const eventWatched = `EXISTS (SELECT 1 FROM task_watchers w
+	WHERE w.task_id = te.task_id AND w.agent_id = ? AND te.created_at >= w.created_at)`
+
+// eventMentioned matches events te that mention the agent ?.
+const eventMentioned = "EXISTS (SELECT 1 FROM task_mentions m WHERE m.event_id = te.id AND m.agent_id = ?)"
+
+// WatchRepository handles database operations for task watchers and mentions.
+type WatchRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWatchRepository creates a new WatchRepository.
+func NewWatchRepository(pool *pgxpool.Pool) *WatchRepository {
+	return &WatchRepository{pool: pool}
+}
+
+// NotificationFilters selects the events on the tasks an agent watches and the
+// comments mentioning it.
+type NotificationFilters struct {
+	AgentID       string
+	WorkspaceID   string  // tasks transferred out of the agent's workspace are left out
+	TaskID        *string // Optional: only events of this task
+	MentionedOnly bool    // only comments mentioning the agent
+	Limit         int
+	Offset        int
+}
+
+// Notification is an event on a watched task or a comment mentioning the agent.
+type Notification struct {
+	TaskEventWithActor
+	TaskTitle string
+	Mentioned bool // the event mentions the agent
+}
+
+// Watch subscribes an agent to a task. Watching it again keeps the first
+// watch and its creation time.
+func (r *WatchRepository) Watch(ctx context.Context, taskID, agentID string) (*domain.TaskWatch, error) {
+	query, args, err := psql.
+		Insert("task_watchers").
+		Columns("task_id", "agent_id").
+		Values(taskID, agentID).
+		Suffix("ON CONFLICT (task_id, agent_id) DO UPDATE SET created_at = task_watchers.created_at RETURNING task_id, agent_id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build Watch query for task %s: %w", taskID, err)
+	}
+
+	var watch domain.TaskWatch
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&watch.TaskID, &watch.AgentID, &watch.CreatedAt); err != nil {
+		return nil, fmt.Errorf("watch task: %w", err)
+	}
+
+	return &watch, nil
+}
+
+// Unwatch unsubscribes an agent from a task. Unwatching a task the agent
+// doesn't watch does nothing.
+func (r *WatchRepository) Unwatch(ctx context.Context, taskID, agentID string) error {
+	query, args, err := psql.
+		Delete("task_watchers").
+		Where(sq.Eq{"task_id": taskID, "agent_id": agentID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Unwatch query for task %s: %w", taskID, err)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unwatch task: %w", err)
+	}
+
+	return nil
+}
+
+// ListNotifications returns the events on the tasks an agent watches and the
+// comments mentioning it, newest first, together with their total count.
+func (r *WatchRepository) ListNotifications(ctx context.Context, filters NotificationFilters) ([]Notification, int, error) {
+	agentID := filters.AgentID
+	where := sq.And{
+		sq.Eq{"t.workspace_id": filters.WorkspaceID},
+		sq.Expr(notificationVisible, agentID, agentID, agentID),
+	}
+	if filters.MentionedOnly {
+		where = append(where, sq.Expr(eventMentioned, agentID))
+	} else {
+		where = append(where, sq.Or{sq.Expr(eventWatched, agentID), sq.Expr(eventMentioned, agentID)})
+	}
+	if filters.TaskID != nil {
+		where = append(where, sq.Eq{"te.task_id": *filters.TaskID})
+	}
+
+	query, args, err := psql.
+		Select(
+			"te.id", "te.task_id", "te.actor_id", "a.name",
+			"te.type", "te.old_status", "te.new_status", "te.comment", "te.data",
+			"te.traceparent", "te.tracestate", "te.created_at", "te.edited_at", "te.redacted_at",
+			"te.reply_to_event_id", "te.thread_id", "t.title",
+		).
+		Column(sq.Expr(eventMentioned, agentID)).
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		LeftJoin("agents a ON te.actor_id = a.id").
+		Where(where).
+		OrderBy("te.created_at DESC", "te.id DESC").
+		Limit(uint64(filters.Limit)).
+		Offset(uint64(filters.Offset)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build ListNotifications query for agent %s: %w", filters.AgentID, err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]Notification, 0)
+	for rows.Next() {
+		var notification Notification
+		var traceParent, traceState *string
+		err := rows.Scan(
+			&notification.ID,
+			&notification.TaskID,
+			&notification.ActorID,
+			&notification.ActorName,
+			&notification.Type,
+			&notification.OldStatus,
+			&notification.NewStatus,
+			&notification.Comment,
+			&notification.Data,
+			&traceParent,
+			&traceState,
+			&notification.CreatedAt,
+			&notification.EditedAt,
+			&notification.RedactedAt,
+			&notification.ReplyToEventID,
+			&notification.ThreadID,
+			&notification.TaskTitle,
+			&notification.Mentioned,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan notification: %w", err)
+		}
+		notification.Trace = toTraceContext(traceParent, traceState)
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	countQuery, countArgs, err := psql.
+		Select("COUNT(*)").
+		From("task_events te").
+		Join("tasks t ON t.id = te.task_id").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build ListNotifications count query for agent %s: %w", filters.AgentID, err)
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
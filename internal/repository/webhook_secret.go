@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// webhookSecretColumns is the shared list of columns for webhook secret queries.
+var webhookSecretColumns = []string{
+	"workspace_id", "secret", "previous_secret", "previous_expires_at", "rotated_at", "created_at",
+}
+
+// PgWebhookSecretRepository handles database operations for workspace webhook secrets.
+type PgWebhookSecretRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookSecretRepository creates a new PgWebhookSecretRepository.
+func NewWebhookSecretRepository(pool *pgxpool.Pool) *PgWebhookSecretRepository {
+	return &PgWebhookSecretRepository{pool: pool}
+}
+
+// scanWebhookSecret scans a single row into a WebhookSecret struct.
+func scanWebhookSecret(row pgx.Row) (*domain.WebhookSecret, error) {
+	var secret domain.WebhookSecret
+	err := row.Scan(
+		&secret.WorkspaceID,
+		&secret.Secret,
+		&secret.PreviousSecret,
+		&secret.PreviousExpiresAt,
+		&secret.RotatedAt,
+		&secret.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookSecretNotFound
+		}
+		return nil, fmt.Errorf("scan webhook secret: %w", err)
+	}
+	return &secret, nil
+}
+
+// GetByWorkspace retrieves the webhook secret of a workspace.
+func (r *PgWebhookSecretRepository) GetByWorkspace(ctx context.Context, workspaceID string) (*domain.WebhookSecret, error) {
+	query, args, err := psql.
+		Select(webhookSecretColumns...).
+		From("webhook_secrets").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByWorkspace query for webhook secret: %w", err)
+	}
+
+	return scanWebhookSecret(r.pool.QueryRow(ctx, query, args...))
+}
+
+// GetByWorkspaceForUpdate retrieves the webhook secret of a workspace with a row
+// lock (within transaction).
+func (r *PgWebhookSecretRepository) GetByWorkspaceForUpdate(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.WebhookSecret, error) {
+	query, args, err := psql.
+		Select(webhookSecretColumns...).
+		From("webhook_secrets").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByWorkspaceForUpdate query for webhook secret: %w", err)
+	}
+
+	return scanWebhookSecret(tx.QueryRow(ctx, query, args...))
+}
+
+// Save inserts or replaces the webhook secret of a workspace and populates
+// RotatedAt and CreatedAt (within transaction).
+func (r *PgWebhookSecretRepository) Save(ctx context.Context, tx pgx.Tx, secret *domain.WebhookSecret) error {
+	query, args, err := psql.
+		Insert("webhook_secrets").
+		Columns("workspace_id", "secret", "previous_secret", "previous_expires_at").
+		Values(secret.WorkspaceID, secret.Secret, secret.PreviousSecret, secret.PreviousExpiresAt).
+		Suffix(`ON CONFLICT (workspace_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			previous_secret = EXCLUDED.previous_secret,
+			previous_expires_at = EXCLUDED.previous_expires_at,
+			rotated_at = NOW()
+		RETURNING rotated_at, created_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Save query for webhook secret: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&secret.RotatedAt, &secret.CreatedAt); err != nil {
+		return fmt.Errorf("save webhook secret: %w", err)
+	}
+
+	return nil
+}
+
+// ClearPrevious drops the previous secret of a workspace, ending its rotation (within transaction).
+func (r *PgWebhookSecretRepository) ClearPrevious(ctx context.Context, tx pgx.Tx, workspaceID string) error {
+	query, args, err := psql.
+		Update("webhook_secrets").
+		Set("previous_secret", nil).
+		Set("previous_expires_at", nil).
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build ClearPrevious query for webhook secret: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("clear previous webhook secret: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookSecretNotFound
+	}
+
+	return nil
+}
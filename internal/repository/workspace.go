@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
@@ -12,35 +13,73 @@ import (
 	"github.com/mtlprog/sloptask/internal/domain"
 )
 
-// WorkspaceRepository handles database operations for workspaces.
-type WorkspaceRepository struct {
-	pool *pgxpool.Pool
+// workspaceColumns is the shared list of columns for workspace queries.
+var workspaceColumns = []string{
+	"id", "name", "slug", "status_deadlines", "auto_assign_strategy", "priority_inheritance", "auto_unblock", "agent_stale_after_seconds",
+	"done_validation_url", "done_validation_timeout_seconds", "done_validation_fail_open",
+	"priority_aging_after_seconds", "priority_aging_action",
+	"claim_quota", "claim_quota_window_seconds", "claim_take_turns",
+	"deadline_warning_percent", "deadline_expiry", "max_attempts", "event_webhook_url", "event_webhook_types",
+	"event_broker_topic", "event_broker_types",
+	"archived_at", "sandbox_of", "expires_at", "created_at",
 }
 
-// NewWorkspaceRepository creates a new WorkspaceRepository.
-func NewWorkspaceRepository(pool *pgxpool.Pool) *WorkspaceRepository {
-	return &WorkspaceRepository{pool: pool}
+// PgWorkspaceRepository handles database operations for workspaces.
+type PgWorkspaceRepository struct {
+	pool *pgxpool.Pool
 }
 
-// GetByID retrieves a workspace by ID.
-func (r *WorkspaceRepository) GetByID(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
-	query, args, err := psql.
-		Select("id", "name", "slug", "status_deadlines", "created_at").
-		From("workspaces").
-		Where(sq.Eq{"id": workspaceID}).
-		ToSql()
-	if err != nil {
-		return nil, fmt.Errorf("build GetByID query for workspace %s: %w", workspaceID, err)
-	}
+// NewWorkspaceRepository creates a new PgWorkspaceRepository.
+func NewWorkspaceRepository(pool *pgxpool.Pool) *PgWorkspaceRepository {
+	return &PgWorkspaceRepository{pool: pool}
+}
 
+// scanWorkspace scans a single row into a Workspace struct.
+func scanWorkspace(row pgx.Row) (*domain.Workspace, error) {
 	var workspace domain.Workspace
 	var statusDeadlinesJSON []byte
+	var doneValidationURL *string
+	var doneValidationTimeoutSeconds int
+	var doneValidationFailOpen bool
+	var priorityAgingAfterSeconds *int
+	var priorityAgingAction domain.PriorityAgingAction
+	var claimQuota *int
+	var claimQuotaWindowSeconds int
+	var deadlineWarningPercent *int
+	var deadlineExpiryJSON []byte
+	var maxAttempts *int
+	var eventWebhookURL *string
+	var eventWebhookTypes []string
+	var eventBrokerTopic *string
+	var eventBrokerTypes []string
 
-	err = r.pool.QueryRow(ctx, query, args...).Scan(
+	err := row.Scan(
 		&workspace.ID,
 		&workspace.Name,
 		&workspace.Slug,
 		&statusDeadlinesJSON,
+		&workspace.AutoAssignStrategy,
+		&workspace.PriorityInheritance,
+		&workspace.AutoUnblock,
+		&workspace.AgentStaleAfterSeconds,
+		&doneValidationURL,
+		&doneValidationTimeoutSeconds,
+		&doneValidationFailOpen,
+		&priorityAgingAfterSeconds,
+		&priorityAgingAction,
+		&claimQuota,
+		&claimQuotaWindowSeconds,
+		&workspace.ClaimFairness.TakeTurns,
+		&deadlineWarningPercent,
+		&deadlineExpiryJSON,
+		&maxAttempts,
+		&eventWebhookURL,
+		&eventWebhookTypes,
+		&eventBrokerTopic,
+		&eventBrokerTypes,
+		&workspace.ArchivedAt,
+		&workspace.SandboxOf,
+		&workspace.ExpiresAt,
 		&workspace.CreatedAt,
 	)
 	if err != nil {
@@ -53,6 +92,690 @@ func (r *WorkspaceRepository) GetByID(ctx context.Context, workspaceID string) (
 	if err := json.Unmarshal(statusDeadlinesJSON, &workspace.StatusDeadlines); err != nil {
 		return nil, fmt.Errorf("parse status_deadlines: %w", err)
 	}
+	if err := json.Unmarshal(deadlineExpiryJSON, &workspace.DeadlineExpiry); err != nil {
+		return nil, fmt.Errorf("parse deadline_expiry: %w", err)
+	}
+
+	if doneValidationURL != nil {
+		workspace.DoneValidation = &domain.DoneValidationHook{
+			URL:      *doneValidationURL,
+			Timeout:  time.Duration(doneValidationTimeoutSeconds) * time.Second,
+			FailOpen: doneValidationFailOpen,
+		}
+	}
+
+	if priorityAgingAfterSeconds != nil {
+		workspace.PriorityAging = &domain.PriorityAging{
+			After:  time.Duration(*priorityAgingAfterSeconds) * time.Second,
+			Action: priorityAgingAction,
+		}
+	}
+
+	if claimQuota != nil {
+		workspace.ClaimFairness.Quota = *claimQuota
+	}
+	workspace.ClaimFairness.Window = time.Duration(claimQuotaWindowSeconds) * time.Second
+
+	if deadlineWarningPercent != nil {
+		workspace.DeadlineWarningPercent = *deadlineWarningPercent
+	}
+	if maxAttempts != nil {
+		workspace.MaxAttempts = *maxAttempts
+	}
+	if eventWebhookURL != nil {
+		workspace.EventWebhook = &domain.EventWebhook{URL: *eventWebhookURL, EventTypes: []domain.EventType{}}
+		for _, eventType := range eventWebhookTypes {
+			workspace.EventWebhook.EventTypes = append(workspace.EventWebhook.EventTypes, domain.EventType(eventType))
+		}
+	}
+	if eventBrokerTopic != nil {
+		workspace.EventBroker = &domain.EventBrokerTopic{Topic: *eventBrokerTopic, EventTypes: []domain.EventType{}}
+		for _, eventType := range eventBrokerTypes {
+			workspace.EventBroker.EventTypes = append(workspace.EventBroker.EventTypes, domain.EventType(eventType))
+		}
+	}
 
 	return &workspace, nil
 }
+
+// GetByID retrieves a workspace by ID.
+func (r *PgWorkspaceRepository) GetByID(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByID query for workspace %s: %w", workspaceID, err)
+	}
+
+	return scanWorkspace(r.pool.QueryRow(ctx, query, args...))
+}
+
+// GetBySlug retrieves a workspace by its slug.
+func (r *PgWorkspaceRepository) GetBySlug(ctx context.Context, slug string) (*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.Eq{"slug": slug}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetBySlug query for workspace %s: %w", slug, err)
+	}
+
+	return scanWorkspace(r.pool.QueryRow(ctx, query, args...))
+}
+
+// GetByIDForUpdate retrieves a workspace by ID and locks it for the transaction.
+func (r *PgWorkspaceRepository) GetByIDForUpdate(ctx context.Context, tx pgx.Tx, workspaceID string) (*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.Eq{"id": workspaceID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build GetByIDForUpdate query for workspace %s: %w", workspaceID, err)
+	}
+
+	return scanWorkspace(tx.QueryRow(ctx, query, args...))
+}
+
+// List returns all workspaces, archived ones and sandboxes included, by name.
+func (r *PgWorkspaceRepository) List(ctx context.Context) ([]*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		OrderBy("name", "created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build List query for workspaces: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaces []*domain.Workspace
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// ListWithAutoAssign returns live workspaces that have an auto-assignment strategy enabled.
+func (r *PgWorkspaceRepository) ListWithAutoAssign(ctx context.Context) ([]*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.NotEq{"auto_assign_strategy": domain.AutoAssignNone}).
+		Where(sq.Eq{"archived_at": nil}).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListWithAutoAssign query for workspaces: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var workspaces []*domain.Workspace
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// SetStatusDeadlines replaces the per-status deadlines of a workspace.
+func (r *PgWorkspaceRepository) SetStatusDeadlines(ctx context.Context, workspaceID string, deadlines map[string]int) error {
+	if deadlines == nil {
+		deadlines = map[string]int{}
+	}
+	deadlinesJSON, err := json.Marshal(deadlines)
+	if err != nil {
+		return fmt.Errorf("encode status_deadlines: %w", err)
+	}
+
+	query, args, err := psql.
+		Update("workspaces").
+		Set("status_deadlines", deadlinesJSON).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetStatusDeadlines query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set status deadlines: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetAutoAssignStrategy changes the auto-assignment strategy of a workspace.
+func (r *PgWorkspaceRepository) SetAutoAssignStrategy(ctx context.Context, workspaceID string, strategy domain.AutoAssignStrategy) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("auto_assign_strategy", strategy).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetAutoAssignStrategy query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set auto-assign strategy: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetPriorityInheritance turns priority inheritance on or off (within transaction).
+func (r *PgWorkspaceRepository) SetPriorityInheritance(ctx context.Context, tx pgx.Tx, workspaceID string, enabled bool) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("priority_inheritance", enabled).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetPriorityInheritance query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set priority inheritance: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetAutoUnblock turns auto-unblocking of BLOCKED tasks on or off.
+func (r *PgWorkspaceRepository) SetAutoUnblock(ctx context.Context, workspaceID string, enabled bool) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("auto_unblock", enabled).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetAutoUnblock query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set auto-unblock: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetAgentStaleAfter changes how long agents of a workspace may go without a heartbeat.
+func (r *PgWorkspaceRepository) SetAgentStaleAfter(ctx context.Context, workspaceID string, seconds int) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("agent_stale_after_seconds", seconds).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetAgentStaleAfter query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set agent stale window: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetDoneValidation sets the webhook approving moves to DONE in a workspace, or
+// removes it when hook is nil.
+func (r *PgWorkspaceRepository) SetDoneValidation(ctx context.Context, workspaceID string, hook *domain.DoneValidationHook) error {
+	qb := psql.Update("workspaces").Where(sq.Eq{"id": workspaceID})
+	if hook == nil {
+		qb = qb.
+			Set("done_validation_url", nil).
+			Set("done_validation_timeout_seconds", sq.Expr("DEFAULT")).
+			Set("done_validation_fail_open", false)
+	} else {
+		qb = qb.
+			Set("done_validation_url", hook.URL).
+			Set("done_validation_timeout_seconds", int(hook.Timeout/time.Second)).
+			Set("done_validation_fail_open", hook.FailOpen)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetDoneValidation query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set done validation: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// Archive marks a workspace as archived and returns the archive time.
+// Archiving an archived workspace keeps the original time.
+func (r *PgWorkspaceRepository) Archive(ctx context.Context, tx pgx.Tx, workspaceID string) (time.Time, error) {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("archived_at", sq.Expr("COALESCE(archived_at, NOW())")).
+		Where(sq.Eq{"id": workspaceID}).
+		Suffix("RETURNING archived_at").
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("build Archive query for workspace %s: %w", workspaceID, err)
+	}
+
+	var archivedAt time.Time
+	if err := tx.QueryRow(ctx, query, args...).Scan(&archivedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, domain.ErrWorkspaceNotFound
+		}
+		return time.Time{}, fmt.Errorf("archive workspace: %w", err)
+	}
+
+	return archivedAt, nil
+}
+
+// DeleteTaskBatch hard-deletes up to limit tasks of a workspace, with their events,
+// checklist items and revisions, and returns how many were removed. Each call is
+// its own transaction, so deleting a large workspace never holds long locks.
+func (r *PgWorkspaceRepository) DeleteTaskBatch(ctx context.Context, workspaceID string, limit int) (int64, error) {
+	// The subquery keeps '?' placeholders; the outer builder numbers them
+	batch := sq.
+		Select("id").
+		From("tasks").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Limit(uint64(limit))
+
+	query, args, err := psql.
+		Delete("tasks").
+		Where(sq.Expr("id IN (?)", batch)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build DeleteTaskBatch query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete workspace tasks: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Delete removes a workspace with its remaining tasks; agents, queues, labels,
+// schedules, reports and read tokens go with it through ON DELETE CASCADE.
+func (r *PgWorkspaceRepository) Delete(ctx context.Context, tx pgx.Tx, workspaceID string) error {
+	// Tasks first: their creator_id references agents with ON DELETE RESTRICT
+	query, args, err := psql.
+		Delete("tasks").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for workspace %s tasks: %w", workspaceID, err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("delete workspace tasks: %w", err)
+	}
+
+	query, args, err = psql.
+		Delete("workspaces").
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Delete query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete workspace: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// priorityAgingColumns returns the column values storing a priority aging
+// policy; a nil policy turns aging off.
+func priorityAgingColumns(aging *domain.PriorityAging) (*int, domain.PriorityAgingAction) {
+	if aging == nil {
+		return nil, domain.PriorityAgingBump
+	}
+	seconds := int(aging.After / time.Second)
+	return &seconds, aging.Action
+}
+
+// SetPriorityAging sets the priority aging policy of a workspace, or turns
+// aging off when aging is nil.
+func (r *PgWorkspaceRepository) SetPriorityAging(ctx context.Context, workspaceID string, aging *domain.PriorityAging) error {
+	afterSeconds, action := priorityAgingColumns(aging)
+
+	query, args, err := psql.
+		Update("workspaces").
+		Set("priority_aging_after_seconds", afterSeconds).
+		Set("priority_aging_action", action).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetPriorityAging query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set priority aging: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// claimQuotaColumn returns the claim_quota value of a fairness policy: NULL for no quota.
+func claimQuotaColumn(fairness domain.ClaimFairness) *int {
+	if fairness.Quota == 0 {
+		return nil
+	}
+	return &fairness.Quota
+}
+
+// SetClaimFairness sets the claim limits of a workspace. The zero value lifts them.
+func (r *PgWorkspaceRepository) SetClaimFairness(ctx context.Context, workspaceID string, fairness domain.ClaimFairness) error {
+	qb := psql.Update("workspaces").
+		Set("claim_quota", claimQuotaColumn(fairness)).
+		Set("claim_take_turns", fairness.TakeTurns).
+		Where(sq.Eq{"id": workspaceID})
+	if fairness.Window == 0 {
+		qb = qb.Set("claim_quota_window_seconds", sq.Expr("DEFAULT"))
+	} else {
+		qb = qb.Set("claim_quota_window_seconds", int(fairness.Window/time.Second))
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetClaimFairness query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set claim fairness: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// deadlineWarningColumn returns the deadline_warning_percent value: NULL for no warnings.
+func deadlineWarningColumn(percent int) *int {
+	if percent == 0 {
+		return nil
+	}
+	return &percent
+}
+
+// SetDeadlineWarning sets the share of a status deadline left when tasks of a
+// workspace are warned. 0 turns warnings off.
+func (r *PgWorkspaceRepository) SetDeadlineWarning(ctx context.Context, workspaceID string, percent int) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("deadline_warning_percent", deadlineWarningColumn(percent)).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetDeadlineWarning query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set deadline warning: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetDeadlineExpiry replaces the statuses tasks of a workspace enter when their
+// deadline in a status expires.
+func (r *PgWorkspaceRepository) SetDeadlineExpiry(ctx context.Context, workspaceID string, expiry map[string]string) error {
+	if expiry == nil {
+		expiry = map[string]string{}
+	}
+	expiryJSON, err := json.Marshal(expiry)
+	if err != nil {
+		return fmt.Errorf("encode deadline_expiry: %w", err)
+	}
+
+	query, args, err := psql.
+		Update("workspaces").
+		Set("deadline_expiry", expiryJSON).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetDeadlineExpiry query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set deadline expiry: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// maxAttemptsColumn maps an attempt limit to its column value: NULL for no limit.
+func maxAttemptsColumn(limit int) *int {
+	if limit == 0 {
+		return nil
+	}
+	return &limit
+}
+
+// SetMaxAttempts sets how many attempts tasks of a workspace get before they are
+// held for human review. 0 removes the limit.
+func (r *PgWorkspaceRepository) SetMaxAttempts(ctx context.Context, workspaceID string, limit int) error {
+	query, args, err := psql.
+		Update("workspaces").
+		Set("max_attempts", maxAttemptsColumn(limit)).
+		Where(sq.Eq{"id": workspaceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetMaxAttempts query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set max attempts: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetEventWebhook sets the webhook receiving a workspace's task events, or stops
+// publishing them when hook is nil. Events already in the outbox keep their target.
+func (r *PgWorkspaceRepository) SetEventWebhook(ctx context.Context, workspaceID string, hook *domain.EventWebhook) error {
+	qb := psql.Update("workspaces").Where(sq.Eq{"id": workspaceID})
+	if hook == nil {
+		qb = qb.Set("event_webhook_url", nil).Set("event_webhook_types", sq.Expr("DEFAULT"))
+	} else {
+		eventTypes := make([]string, len(hook.EventTypes))
+		for i, eventType := range hook.EventTypes {
+			eventTypes[i] = string(eventType)
+		}
+		qb = qb.Set("event_webhook_url", hook.URL).Set("event_webhook_types", eventTypes)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetEventWebhook query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set event webhook: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// SetEventBroker sets the broker topic receiving a workspace's task events, or
+// stops publishing them to the broker when topic is nil. Events already in the
+// outbox keep their target.
+func (r *PgWorkspaceRepository) SetEventBroker(ctx context.Context, workspaceID string, topic *domain.EventBrokerTopic) error {
+	qb := psql.Update("workspaces").Where(sq.Eq{"id": workspaceID})
+	if topic == nil {
+		qb = qb.Set("event_broker_topic", nil).Set("event_broker_types", sq.Expr("DEFAULT"))
+	} else {
+		eventTypes := make([]string, len(topic.EventTypes))
+		for i, eventType := range topic.EventTypes {
+			eventTypes[i] = string(eventType)
+		}
+		qb = qb.Set("event_broker_topic", topic.Topic).Set("event_broker_types", eventTypes)
+	}
+
+	query, args, err := qb.ToSql()
+	if err != nil {
+		return fmt.Errorf("build SetEventBroker query for workspace %s: %w", workspaceID, err)
+	}
+
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("set event broker topic: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWorkspaceNotFound
+	}
+
+	return nil
+}
+
+// Create inserts a workspace (within transaction), a sandbox when SandboxOf is
+// set, and sets its ID and creation time. Returns ErrWorkspaceExists if the slug
+// is taken.
+func (r *PgWorkspaceRepository) Create(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace) error {
+	statusDeadlines, err := json.Marshal(workspace.StatusDeadlines)
+	if err != nil {
+		return fmt.Errorf("encode status_deadlines: %w", err)
+	}
+	deadlineExpiry, err := json.Marshal(workspace.DeadlineExpiry)
+	if err != nil {
+		return fmt.Errorf("encode deadline_expiry: %w", err)
+	}
+	agingAfterSeconds, agingAction := priorityAgingColumns(workspace.PriorityAging)
+
+	query, args, err := psql.
+		Insert("workspaces").
+		Columns(
+			"name", "slug", "status_deadlines", "auto_assign_strategy", "priority_inheritance", "auto_unblock", "agent_stale_after_seconds",
+			"priority_aging_after_seconds", "priority_aging_action",
+			"claim_quota", "claim_quota_window_seconds", "claim_take_turns", "deadline_warning_percent", "deadline_expiry",
+			"max_attempts", "sandbox_of", "expires_at",
+		).
+		Values(
+			workspace.Name,
+			workspace.Slug,
+			statusDeadlines,
+			workspace.AutoAssignStrategy,
+			workspace.PriorityInheritance,
+			workspace.AutoUnblock,
+			workspace.AgentStaleAfterSeconds,
+			agingAfterSeconds,
+			agingAction,
+			claimQuotaColumn(workspace.ClaimFairness),
+			int(workspace.ClaimFairness.Window/time.Second),
+			workspace.ClaimFairness.TakeTurns,
+			deadlineWarningColumn(workspace.DeadlineWarningPercent),
+			deadlineExpiry,
+			maxAttemptsColumn(workspace.MaxAttempts),
+			workspace.SandboxOf,
+			workspace.ExpiresAt,
+		).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build Create query for workspace: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&workspace.ID, &workspace.CreatedAt); err != nil {
+		if isPgError(err, pgUniqueViolation) {
+			return fmt.Errorf("%w: %s", domain.ErrWorkspaceExists, workspace.Slug)
+		}
+		return fmt.Errorf("create workspace: %w", err)
+	}
+
+	return nil
+}
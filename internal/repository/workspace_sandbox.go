@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// openTaskCondition matches the tasks (aliased t) a sandbox clones: open, not
+// archived and not deleted.
+const openTaskCondition = "t.status NOT IN ('DONE', 'CANCELLED') AND t.archived_at IS NULL AND t.deleted_at IS NULL"
+
+// Temporary tables mapping source IDs to sandbox IDs while cloning; dropped on commit.
+const (
+	sandboxAgentMap = "sandbox_agent_map"
+	sandboxTaskMap  = "sandbox_task_map"
+)
+
+// SandboxClone reports what CloneIntoSandbox copied.
+type SandboxClone struct {
+	Agents int64
+	Tasks  int64
+	Queues int64
+	Labels int64
+}
+
+// CloneIntoSandbox copies a workspace's queues, labels, agents and open tasks into
+// a sandbox (within transaction). tokens holds the sandbox token for every agent of
+// the source, by source agent ID. Tasks keep their state, with creators, assignees
+// and blockers pointing at the clones; blockers that are not cloned are dropped.
+// Each cloned task gets a system created event naming the task it was cloned from.
+func (r *PgWorkspaceRepository) CloneIntoSandbox(
+	ctx context.Context,
+	tx pgx.Tx,
+	sourceID, sandboxID string,
+	tokens map[string]string,
+) (*SandboxClone, error) {
+	clone := &SandboxClone{}
+	var err error
+
+	if clone.Queues, err = r.cloneRows(ctx, tx, "task_queues", []string{"name", "description"}, sourceID, sandboxID); err != nil {
+		return nil, err
+	}
+	if clone.Labels, err = r.cloneRows(ctx, tx, "labels", []string{"name", "color", "description"}, sourceID, sandboxID); err != nil {
+		return nil, err
+	}
+	if clone.Agents, err = r.cloneAgents(ctx, tx, sourceID, sandboxID, tokens); err != nil {
+		return nil, err
+	}
+	if clone.Tasks, err = r.cloneOpenTasks(ctx, tx, sourceID, sandboxID); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// cloneRows copies the given columns of a workspace-scoped table into the sandbox.
+func (r *PgWorkspaceRepository) cloneRows(ctx context.Context, tx pgx.Tx, table string, columns []string, sourceID, sandboxID string) (int64, error) {
+	// The subquery keeps '?' placeholders; the outer builder numbers them
+	source := sq.
+		Select(columns...).
+		Column(sq.Expr("?::uuid", sandboxID)).
+		From(table).
+		Where(sq.Eq{"workspace_id": sourceID})
+
+	query, args, err := psql.
+		Insert(table).
+		Columns(append(slices.Clip(columns), "workspace_id")...).
+		Select(source).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build clone query for %s: %w", table, err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("clone %s: %w", table, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// cloneAgents copies every agent of the source with its sandbox token, keeping
+// names, capabilities and whether it is active, and records the new IDs in
+// sandboxAgentMap.
+func (r *PgWorkspaceRepository) cloneAgents(ctx context.Context, tx pgx.Tx, sourceID, sandboxID string, tokens map[string]string) (int64, error) {
+	if _, err := tx.Exec(ctx, "CREATE TEMP TABLE "+sandboxAgentMap+
+		" (old_id UUID PRIMARY KEY, new_id UUID NOT NULL DEFAULT uuid_generate_v4(), token TEXT NOT NULL) ON COMMIT DROP"); err != nil {
+		return 0, fmt.Errorf("create agent map: %w", err)
+	}
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	mapping := psql.Insert(sandboxAgentMap).Columns("old_id", "token")
+	for agentID, token := range tokens {
+		mapping = mapping.Values(agentID, token)
+	}
+	query, args, err := mapping.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build agent map query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("fill agent map: %w", err)
+	}
+
+	source := sq.
+		Select("m.new_id").
+		Column(sq.Expr("?::uuid", sandboxID)).
+		Columns("a.name", "m.token", "a.is_active", "a.capabilities").
+		From("agents a").
+		Join(sandboxAgentMap + " m ON m.old_id = a.id").
+		Where(sq.Eq{"a.workspace_id": sourceID})
+
+	query, args, err = psql.
+		Insert("agents").
+		Columns("id", "workspace_id", "name", "token", "is_active", "capabilities").
+		Select(source).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build clone query for agents: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("clone agents: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// cloneOpenTasks copies the source's open tasks using sandboxAgentMap, then points
+// their blockers at the cloned tasks and records a created event for each.
+func (r *PgWorkspaceRepository) cloneOpenTasks(ctx context.Context, tx pgx.Tx, sourceID, sandboxID string) (int64, error) {
+	if _, err := tx.Exec(ctx, "CREATE TEMP TABLE "+sandboxTaskMap+
+		" (old_id UUID PRIMARY KEY, new_id UUID NOT NULL DEFAULT uuid_generate_v4()) ON COMMIT DROP"); err != nil {
+		return 0, fmt.Errorf("create task map: %w", err)
+	}
+
+	query, args, err := psql.
+		Insert(sandboxTaskMap).
+		Columns("old_id").
+		Select(sq.Select("t.id").From("tasks t").Where(sq.Eq{"t.workspace_id": sourceID}).Where(openTaskCondition)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build task map query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("fill task map: %w", err)
+	}
+
+	// Blockers are cloned afterwards: their foreign keys want both tasks in the workspace
+	source := sq.
+		Select("m.new_id").
+		Column(sq.Expr("?::uuid", sandboxID)).
+		Columns(
+			"t.title", "t.description", "c.new_id", "a.new_id",
+			"t.status", "t.visibility", "t.priority", "t.inherited_priority", "t.status_deadline_at",
+			"t.artefact", "t.result", "t.required_capabilities", "t.queue", "t.labels",
+			"t.external_system", "t.external_id", "t.external_url", "t.attempts", "t.human_review_at", "t.requires_approval",
+			"t.created_at",
+		).
+		From("tasks t").
+		Join(sandboxTaskMap + " m ON m.old_id = t.id").
+		Join(sandboxAgentMap + " c ON c.old_id = t.creator_id").
+		LeftJoin(sandboxAgentMap + " a ON a.old_id = t.assignee_id")
+
+	query, args, err = psql.
+		Insert("tasks").
+		Columns(
+			"id", "workspace_id", "title", "description", "creator_id", "assignee_id",
+			"status", "visibility", "priority", "inherited_priority", "status_deadline_at",
+			"artefact", "result", "required_capabilities", "queue", "labels",
+			"external_system", "external_id", "external_url", "attempts", "human_review_at", "requires_approval",
+			"created_at",
+		).
+		Select(source).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build clone query for tasks: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("clone tasks: %w", err)
+	}
+	cloned := tag.RowsAffected()
+
+	dependencies := sq.
+		Select("m.new_id", "bm.new_id", "d.position").
+		Column(sq.Expr("?::uuid", sandboxID)).
+		From("task_dependencies d").
+		Join(sandboxTaskMap + " m ON m.old_id = d.task_id").
+		Join(sandboxTaskMap + " bm ON bm.old_id = d.blocker_id")
+
+	query, args, err = psql.
+		Insert("task_dependencies").
+		Columns("task_id", "blocker_id", "position", "workspace_id").
+		Select(dependencies).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build clone query for blockers: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("clone blockers: %w", err)
+	}
+
+	events := sq.
+		Select("m.new_id", "'created'", "t.status", "'Cloned into sandbox'", "jsonb_build_object('cloned_from', t.id)").
+		From("tasks t").
+		Join(sandboxTaskMap + " m ON m.old_id = t.id")
+
+	query, args, err = psql.
+		Insert("task_events").
+		Columns("task_id", "type", "new_status", "comment", "data").
+		Select(events).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build clone query for events: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return 0, fmt.Errorf("record cloned tasks: %w", err)
+	}
+
+	return cloned, nil
+}
+
+// ListExpiredSandboxes returns the sandboxes that expired by now, oldest first.
+func (r *PgWorkspaceRepository) ListExpiredSandboxes(ctx context.Context, now time.Time) ([]*domain.Workspace, error) {
+	query, args, err := psql.
+		Select(workspaceColumns...).
+		From("workspaces").
+		Where(sq.LtOrEq{"expires_at": now}).
+		OrderBy("expires_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build ListExpiredSandboxes query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query expired sandboxes: %w", err)
+	}
+	defer rows.Close()
+
+	var sandboxes []*domain.Workspace
+	for rows.Next() {
+		sandbox, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		sandboxes = append(sandboxes, sandbox)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+
+	return sandboxes, nil
+}
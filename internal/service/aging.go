@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// SetPriorityAging sets the workspace's policy for NEW tasks left unclaimed, or
+// turns aging off when aging is nil. An empty action means bump.
+func (s *TaskService) SetPriorityAging(ctx context.Context, workspaceID string, aging *domain.PriorityAging) (*domain.PriorityAging, error) {
+	if aging != nil {
+		if aging.Action == "" {
+			aging.Action = domain.PriorityAgingBump
+		}
+		if !aging.Action.IsValid() {
+			return nil, fmt.Errorf("%w: action must be bump or warn", domain.ErrValidation)
+		}
+		seconds := aging.After / time.Second
+		if aging.After%time.Second != 0 || seconds < domain.MinPriorityAgingAfterSeconds || seconds > domain.MaxPriorityAgingAfterSeconds {
+			return nil, fmt.Errorf("%w: after must be whole seconds between %d and %d",
+				domain.ErrValidation, domain.MinPriorityAgingAfterSeconds, domain.MaxPriorityAgingAfterSeconds)
+		}
+	}
+
+	if err := s.workspaceRepo.SetPriorityAging(ctx, workspaceID, aging); err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace priority aging updated", "workspace_id", workspaceID, "enabled", aging != nil)
+
+	return aging, nil
+}
+
+// AgeWaitingTasks applies each workspace's priority aging policy to NEW tasks
+// nobody claimed within its threshold: their priority is raised one step, or
+// only a priority_aged event is recorded, depending on the policy. Either way
+// the wait starts over, so a task still unclaimed ages again one threshold later.
+// It is meant to run periodically, like ProcessExpiredDeadlines. Returns the
+// number of tasks aged, and an error if any task failed.
+func (s *TaskService) AgeWaitingTasks(ctx context.Context) (int, error) {
+	tasks, err := s.taskRepo.FindAging(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("find aging tasks: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		slog.Info("no aging tasks found")
+		return 0, nil
+	}
+
+	count := 0
+	var errs []error // Accumulate errors
+	for _, task := range tasks {
+		aged, err := s.ageTask(ctx, task.ID)
+		if err != nil {
+			slog.Error("failed to age waiting task",
+				"task_id", task.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
+			continue
+		}
+		if aged {
+			count++
+		}
+	}
+
+	slog.Info("aged waiting tasks",
+		"total", len(tasks),
+		"aged", count,
+		"failed", len(errs),
+	)
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("aged %d/%d tasks, %d failures: %v",
+			count, len(tasks), len(errs), errs)
+	}
+
+	return count, nil
+}
+
+// ageTask applies the workspace's aging policy to one task with a system
+// priority_aged event. The task, the policy and the wait are re-checked under
+// the row lock, so a task claimed or aged meanwhile is left alone (returns false).
+func (s *TaskService) ageTask(ctx context.Context, taskID string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return false, err
+	}
+	if task.Status != domain.TaskStatusNew || task.AssigneeID != nil {
+		return false, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return false, fmt.Errorf("get workspace: %w", err)
+	}
+	aging := workspace.PriorityAging
+	if aging == nil || workspace.IsArchived() {
+		return false, nil
+	}
+
+	since, err := s.taskRepo.WaitingSince(ctx, tx, task.ID)
+	if err != nil {
+		return false, err
+	}
+	waited := time.Since(since)
+	if waited < aging.After {
+		return false, nil
+	}
+	waitedSeconds := int(waited / time.Second)
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: nil, // system event
+		Type:    domain.EventTypePriorityAged,
+	}
+
+	switch aging.Action {
+	case domain.PriorityAgingBump:
+		raised, ok := task.Priority.Raised()
+		if !ok {
+			return false, nil
+		}
+		if err := s.taskRepo.SetPriority(ctx, tx, task.ID, raised); err != nil {
+			return false, err
+		}
+		// A more urgent task may pass its priority on to its blockers
+		if err := s.syncPriorityInheritance(ctx, tx, task.ID); err != nil {
+			return false, err
+		}
+		event.Comment = fmt.Sprintf("Priority raised from %s to %s: unclaimed for %s.",
+			task.Priority, raised, waited.Round(time.Minute))
+		event.Data = map[string]any{
+			"action":         aging.Action,
+			"old_priority":   task.Priority,
+			"new_priority":   raised,
+			"waited_seconds": waitedSeconds,
+		}
+	default:
+		event.Comment = fmt.Sprintf("Unclaimed for %s.", waited.Round(time.Minute))
+		event.Data = map[string]any{
+			"action":         aging.Action,
+			"priority":       task.Priority,
+			"waited_seconds": waitedSeconds,
+		}
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return false, err
+	}
+
+	slog.Info("waiting task aged",
+		"task_id", task.ID,
+		"action", aging.Action,
+		"waited_seconds", waitedSeconds,
+	)
+
+	return true, nil
+}
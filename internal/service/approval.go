@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ApproveTask accepts a WAITING_APPROVAL task of the workspace on behalf of an
+// operator. newStatus is DONE, or IN_PROGRESS to send the assignee back to work
+// before another approval round. The comment is optional.
+func (s *TaskService) ApproveTask(
+	ctx context.Context,
+	workspaceID, taskID string,
+	newStatus domain.TaskStatus,
+	comment string,
+) (*domain.TaskEvent, error) {
+	if newStatus != domain.TaskStatusDone && newStatus != domain.TaskStatusInProgress {
+		return nil, fmt.Errorf("%w: status must be DONE or IN_PROGRESS", domain.ErrValidation)
+	}
+
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		comment = fmt.Sprintf("Approved by an operator, moved to %s.", newStatus)
+	}
+
+	return s.resolveApproval(ctx, workspaceID, taskID, newStatus, domain.EventTypeApprovalGranted, comment)
+}
+
+// RejectTask turns down a WAITING_APPROVAL task of the workspace on behalf of an
+// operator: it returns to NEW, unassigned, with the comment explaining why.
+func (s *TaskService) RejectTask(ctx context.Context, workspaceID, taskID, comment string) (*domain.TaskEvent, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+
+	return s.resolveApproval(ctx, workspaceID, taskID, domain.TaskStatusNew, domain.EventTypeApprovalRejected, comment)
+}
+
+// resolveApproval moves a WAITING_APPROVAL task to newStatus with a fresh
+// deadline and records the operator's decision. The DONE validation webhook is
+// not consulted: the operator's approval is the check.
+func (s *TaskService) resolveApproval(
+	ctx context.Context,
+	workspaceID, taskID string,
+	newStatus domain.TaskStatus,
+	eventType domain.EventType,
+	comment string,
+) (*domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("%w: task %s", domain.ErrTaskNotFound, taskID)
+	}
+
+	if err := s.validator.CanResolveApproval(task, newStatus); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get workspace: %w", err)
+	}
+
+	newAssignee := task.AssigneeID
+	if ShouldClearAssignee(newStatus) {
+		newAssignee = nil
+	}
+
+	oldStatus := task.Status
+	err = s.taskRepo.UpdateStatus(ctx, tx, taskID,
+		oldStatus, newStatus,
+		newAssignee, CalculateDeadline(workspace, newStatus), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]any
+	if task.AssigneeID != nil {
+		data = map[string]any{"assignee_id": *task.AssigneeID}
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:    taskID,
+		ActorID:   nil, // operator action
+		Type:      eventType,
+		OldStatus: &oldStatus,
+		NewStatus: &newStatus,
+		Comment:   comment,
+		Data:      data,
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task approval resolved",
+		"task_id", taskID,
+		"type", eventType,
+		"new_status", newStatus,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
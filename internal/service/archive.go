@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ArchiveTask hides a DONE or CANCELLED task from default task lists. Only the task
+// creator or assignee may archive it. The task and its events stay readable by ID,
+// and reopening an archived DONE task unarchives it.
+func (s *TaskService) ArchiveTask(ctx context.Context, taskID, agentID, comment string) (*domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.WorkspaceID != agent.WorkspaceID || (!task.IsCreatedBy(agent.ID) && !task.IsOwnedBy(agent.ID)) {
+		return nil, fmt.Errorf("%w: agent %s is neither creator nor assignee of task %s", domain.ErrPermissionDenied, agent.ID, task.ID)
+	}
+
+	if !task.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: only DONE or CANCELLED tasks can be archived, task is %s", domain.ErrInvalidTransition, task.Status)
+	}
+	if task.IsArchived() {
+		return nil, fmt.Errorf("%w: task %s is already archived", domain.ErrInvalidTransition, task.ID)
+	}
+
+	if err := s.taskRepo.SetArchived(ctx, tx, task.ID, true); err != nil {
+		return nil, err
+	}
+
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		comment = "Task archived"
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: &agent.ID,
+		Type:    domain.EventTypeArchived,
+		Comment: comment,
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task archived",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"status", task.Status,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
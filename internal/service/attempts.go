@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// SetMaxAttempts sets how many times tasks of a workspace may change hands
+// (taken over or returned to NEW) before they are held for human review.
+// 0 removes the limit.
+func (s *TaskService) SetMaxAttempts(ctx context.Context, workspaceID string, limit int) error {
+	if limit < 0 || limit > domain.MaxAttemptsLimit {
+		return fmt.Errorf("%w: max_attempts must be between 1 and %d", domain.ErrValidation, domain.MaxAttemptsLimit)
+	}
+
+	if err := s.workspaceRepo.SetMaxAttempts(ctx, workspaceID, limit); err != nil {
+		return err
+	}
+
+	slog.Info("workspace max attempts updated", "workspace_id", workspaceID, "max_attempts", limit)
+
+	return nil
+}
+
+// createHandOffAndCommit persists the event of a task changing hands (taken over
+// or returned to NEW) within the transaction, counts the attempt, then commits.
+func (s *TaskService) createHandOffAndCommit(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace, event *domain.TaskEvent) error {
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+	if err := s.countAttempt(ctx, tx, workspace, event.TaskID); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// countAttempt counts one more attempt on a task within the transaction. Once
+// the attempts reach the workspace's limit, the task is held for human review
+// with a system attempts_exceeded event: agents can no longer claim or take it
+// over until an operator clears the hold.
+func (s *TaskService) countAttempt(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace, taskID string) error {
+	attempts, err := s.taskRepo.IncrementAttempts(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+
+	limit := workspace.MaxAttempts
+	if limit == 0 || attempts < limit {
+		return nil
+	}
+
+	held, err := s.taskRepo.HoldForHumanReview(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return nil
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  taskID,
+		ActorID: nil, // system event
+		Type:    domain.EventTypeAttemptsExceeded,
+		Comment: fmt.Sprintf("Changed hands %d times, the workspace allows %d: held for human review. "+
+			"Agents cannot claim or take over the task until an operator clears the hold.", attempts, limit),
+		Data: map[string]any{
+			"attempts":     attempts,
+			"max_attempts": limit,
+		},
+	}
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+
+	slog.Warn("task held for human review",
+		"task_id", taskID,
+		"attempts", attempts,
+		"max_attempts", limit,
+	)
+
+	return nil
+}
+
+// ClearHumanReview lifts the human review hold of a task in the workspace and
+// resets its attempts, so agents may claim or take it over again. The comment
+// is optional.
+func (s *TaskService) ClearHumanReview(ctx context.Context, workspaceID, taskID, comment string) (*domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("%w: task %s", domain.ErrTaskNotFound, taskID)
+	}
+	if !task.IsHeldForReview() {
+		return nil, fmt.Errorf("%w: task %s is not held for human review", domain.ErrInvalidTransition, taskID)
+	}
+
+	if err := s.taskRepo.ClearHumanReview(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
+	if comment == "" {
+		comment = "Human review hold cleared by an operator."
+	}
+	event := &domain.TaskEvent{
+		TaskID:  taskID,
+		ActorID: nil, // operator action
+		Type:    domain.EventTypeHumanReviewCleared,
+		Comment: comment,
+		Data:    map[string]any{"attempts": task.Attempts},
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task human review cleared",
+		"task_id", taskID,
+		"attempts", task.Attempts,
+	)
+
+	return event, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// AutoAssign hands NEW tasks to idle agents in every workspace that has an
+// auto-assignment strategy enabled. It is meant to run periodically, like
+// ProcessExpiredDeadlines. Returns the number of tasks assigned, and an error
+// if any workspace or task failed.
+func (s *TaskService) AutoAssign(ctx context.Context) (int, error) {
+	workspaces, err := s.workspaceRepo.ListWithAutoAssign(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list auto-assign workspaces: %w", err)
+	}
+
+	if len(workspaces) == 0 {
+		slog.Info("no workspaces with auto-assignment enabled")
+		return 0, nil
+	}
+
+	count := 0
+	var errs []error // Accumulate errors
+	for _, workspace := range workspaces {
+		assigned, err := s.autoAssignWorkspace(ctx, workspace)
+		count += assigned
+		if err != nil {
+			slog.Error("failed to auto-assign tasks",
+				"workspace_id", workspace.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("workspace %s: %w", workspace.ID, err))
+		}
+	}
+
+	slog.Info("auto-assignment finished",
+		"workspaces", len(workspaces),
+		"assigned", count,
+	)
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("auto-assigned %d tasks, %d failures: %v", count, len(errs), errs)
+	}
+
+	return count, nil
+}
+
+// autoAssignWorkspace assigns NEW tasks of one workspace, most urgent first,
+// until no idle agent is left.
+func (s *TaskService) autoAssignWorkspace(ctx context.Context, workspace *domain.Workspace) (int, error) {
+	tasks, err := s.taskRepo.FindAutoAssignable(ctx, workspace.ID)
+	if err != nil {
+		return 0, fmt.Errorf("find assignable tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	workloads, err := s.agentRepo.ListWorkloads(ctx, workspace.ID)
+	if err != nil {
+		return 0, fmt.Errorf("list agent workloads: %w", err)
+	}
+
+	count := 0
+	var errs []error
+	for _, task := range tasks {
+		workload := pickAgent(workspace.AutoAssignStrategy, task, workloads, s.validator)
+		if workload == nil {
+			continue
+		}
+
+		err := s.autoAssignTask(ctx, workspace, task, workload.Agent)
+		if errors.Is(err, domain.ErrUnresolvedBlockers) ||
+			errors.Is(err, domain.ErrTaskAlreadyClaimed) ||
+			errors.Is(err, domain.ErrInvalidTransition) {
+			// Not ready yet, or claimed by an agent meanwhile: leave it for a later run
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
+			continue
+		}
+
+		// Keep the in-memory view in sync so the next task sees the new load
+		now := time.Now()
+		workload.InProgressCount++
+		workload.ActiveCount++
+		workload.LastAutoAssignedAt = &now
+		count++
+	}
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("%d failures: %v", len(errs), errs)
+	}
+
+	return count, nil
+}
+
+// pickAgent selects an idle agent that may claim the task according to the strategy.
+// Returns nil if no agent is eligible.
+func pickAgent(
+	strategy domain.AutoAssignStrategy,
+	task *domain.Task,
+	workloads []*domain.AgentWorkload,
+	validator *Validator,
+) *domain.AgentWorkload {
+	var best *domain.AgentWorkload
+	for _, candidate := range workloads {
+		if !candidate.IsIdle() {
+			continue
+		}
+		if err := validator.CanClaim(task, candidate.Agent); err != nil {
+			continue
+		}
+		if best == nil || betterCandidate(strategy, task, candidate, best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// betterCandidate reports whether candidate should be preferred over current.
+// Ties keep the current (earlier registered) agent.
+func betterCandidate(strategy domain.AutoAssignStrategy, task *domain.Task, candidate, current *domain.AgentWorkload) bool {
+	switch strategy {
+	case domain.AutoAssignLeastLoaded:
+		return candidate.ActiveCount < current.ActiveCount
+	case domain.AutoAssignCapabilityMatch:
+		// Prefer the most specialised agent, leaving generalists free for other work
+		candidateExtra := len(candidate.Agent.Capabilities) - len(task.RequiredCapabilities)
+		currentExtra := len(current.Agent.Capabilities) - len(task.RequiredCapabilities)
+		if candidateExtra != currentExtra {
+			return candidateExtra < currentExtra
+		}
+		return candidate.ActiveCount < current.ActiveCount
+	default: // round_robin: the agent that waited longest since its last auto-assignment
+		if current.LastAutoAssignedAt == nil {
+			return false
+		}
+		if candidate.LastAutoAssignedAt == nil {
+			return true
+		}
+		return candidate.LastAutoAssignedAt.Before(*current.LastAutoAssignedAt)
+	}
+}
+
+// autoAssignTask moves a NEW task to IN_PROGRESS with the chosen agent as assignee.
+func (s *TaskService) autoAssignTask(
+	ctx context.Context,
+	workspace *domain.Workspace,
+	task *domain.Task,
+	agent *domain.Agent,
+) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	// Re-check under lock: the task may have been claimed since it was listed
+	locked, err := s.taskRepo.GetByIDForUpdate(ctx, tx, task.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validator.CanClaim(locked, agent); err != nil {
+		return err
+	}
+	if err := s.validator.CheckBlockedByResolved(ctx, locked.BlockedBy); err != nil {
+		return err
+	}
+
+	newDeadline := CalculateDeadline(workspace, domain.TaskStatusInProgress)
+
+	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+		domain.TaskStatusNew, domain.TaskStatusInProgress,
+		&agent.ID, newDeadline, nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := s.agentRepo.MarkAutoAssigned(ctx, tx, agent.ID); err != nil {
+		return err
+	}
+
+	oldStatus := domain.TaskStatusNew
+	newStatus := domain.TaskStatusInProgress
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		ActorID:   nil, // system event
+		Type:      domain.EventTypeStatusChanged,
+		OldStatus: &oldStatus,
+		NewStatus: &newStatus,
+		Comment:   fmt.Sprintf("Auto-assigned to %s (%s).", agent.Name, workspace.AutoAssignStrategy),
+		Data: map[string]any{
+			"auto_assigned": true,
+			"strategy":      string(workspace.AutoAssignStrategy),
+			"agent_id":      agent.ID,
+		},
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return err
+	}
+
+	slog.Info("task auto-assigned",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"strategy", workspace.AutoAssignStrategy,
+	)
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+const (
+	// changeSubscriptionBuffer is how many changes a subscriber may fall behind
+	// before further changes are dropped for it.
+	changeSubscriptionBuffer = 64
+	// maxChangeFeedBackoff caps the wait between reconnection attempts.
+	maxChangeFeedBackoff = 30 * time.Second
+)
+
+// ChangeFeed fans committed task changes, announced by Postgres NOTIFY from any
+// process writing task events, out to in-process subscribers, so features that
+// react to changes need not poll.
+type ChangeFeed struct {
+	eventRepo repository.TaskEventRepository
+
+	mu          sync.Mutex
+	subscribers map[*changeSubscription]struct{}
+	closed      bool
+
+	readyOnce sync.Once
+	ready     chan struct{}
+}
+
+// changeSubscription is one subscriber's channel and workspace filter.
+type changeSubscription struct {
+	workspaceID string
+	changes     chan *domain.TaskChange
+}
+
+// NewChangeFeed creates a new ChangeFeed. Nothing is delivered until Run is called.
+func NewChangeFeed(eventRepo repository.TaskEventRepository) *ChangeFeed {
+	return &ChangeFeed{
+		eventRepo:   eventRepo,
+		subscribers: make(map[*changeSubscription]struct{}),
+		ready:       make(chan struct{}),
+	}
+}
+
+// Run listens for task changes until ctx is done, reconnecting with growing
+// delays when the connection is lost. Changes committed while disconnected are
+// not replayed; subscribers that cannot miss changes must re-read state after
+// a gap, or poll as a fallback.
+func (f *ChangeFeed) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		listening := false
+		err := f.eventRepo.ListenChanges(ctx, func() {
+			listening = true
+			f.markReady()
+		}, f.publish)
+		if ctx.Err() != nil {
+			return
+		}
+		if listening {
+			// The connection worked and then dropped: start over with short delays
+			backoff = time.Second
+		}
+
+		slog.Warn("task change listener disconnected, reconnecting", "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxChangeFeedBackoff)
+	}
+}
+
+// Ready is closed once the feed first listens for changes.
+func (f *ChangeFeed) Ready() <-chan struct{} {
+	return f.ready
+}
+
+// markReady closes the ready channel on the first successful listen.
+func (f *ChangeFeed) markReady() {
+	f.readyOnce.Do(func() { close(f.ready) })
+}
+
+// Subscribe returns a channel receiving the changes of a workspace, or of all
+// workspaces when workspaceID is empty, and a function ending the subscription.
+// A subscriber that falls behind misses changes rather than stalling the feed.
+// The channel is closed when the feed is closed.
+func (f *ChangeFeed) Subscribe(workspaceID string) (<-chan *domain.TaskChange, func()) {
+	sub := &changeSubscription{
+		workspaceID: workspaceID,
+		changes:     make(chan *domain.TaskChange, changeSubscriptionBuffer),
+	}
+
+	f.mu.Lock()
+	if f.closed {
+		close(sub.changes)
+	} else {
+		f.subscribers[sub] = struct{}{}
+	}
+	f.mu.Unlock()
+
+	var once sync.Once
+	return sub.changes, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.subscribers, sub)
+			f.mu.Unlock()
+		})
+	}
+}
+
+// Close ends every subscription, e.g. so long-polling requests return during
+// shutdown. Later subscriptions are closed right away.
+func (f *ChangeFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for sub := range f.subscribers {
+		close(sub.changes)
+		delete(f.subscribers, sub)
+	}
+}
+
+// publish hands a change to every matching subscriber without blocking.
+func (f *ChangeFeed) publish(change *domain.TaskChange) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subscribers {
+		if sub.workspaceID != "" && sub.workspaceID != change.WorkspaceID {
+			continue
+		}
+		select {
+		case sub.changes <- change:
+		default:
+			slog.Warn("task change subscriber is behind, dropping change",
+				"event_id", change.EventID,
+				"task_id", change.TaskID,
+			)
+		}
+	}
+}
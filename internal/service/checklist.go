@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// maxChecklistTitleLength matches the task_checklist_items.title column.
+const maxChecklistTitleLength = 200
+
+// ChecklistItemParams identifies a checklist item operation by an agent.
+type ChecklistItemParams struct {
+	TaskID  string
+	ItemID  string
+	AgentID string
+	Comment string
+}
+
+// AddChecklistItem appends an item to a task's checklist. Only the creator or assignee may add items.
+func (s *TaskService) AddChecklistItem(ctx context.Context, taskID, agentID, title string) (*domain.ChecklistItem, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > maxChecklistTitleLength {
+		return nil, fmt.Errorf("%w: checklist item title must be 1-%d characters", domain.ErrValidation, maxChecklistTitleLength)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.WorkspaceID != agent.WorkspaceID || (!task.IsCreatedBy(agentID) && !task.IsOwnedBy(agentID)) {
+		return nil, fmt.Errorf("%w: agent %s is neither creator nor assignee of task %s", domain.ErrPermissionDenied, agentID, taskID)
+	}
+
+	if task.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: task %s is in terminal status %s", domain.ErrInvalidTransition, taskID, task.Status)
+	}
+
+	item := &domain.ChecklistItem{
+		TaskID: taskID,
+		Title:  title,
+	}
+	if err := s.checklistRepo.Create(ctx, tx, item); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("checklist item added",
+		"task_id", taskID,
+		"agent_id", agentID,
+		"checklist_item_id", item.ID,
+	)
+
+	return item, nil
+}
+
+// ClaimChecklistItem lets a helper agent take a single checklist item of an IN_PROGRESS task
+// without reassigning the task itself.
+func (s *TaskService) ClaimChecklistItem(ctx context.Context, params ChecklistItemParams) (*domain.TaskEvent, error) {
+	return s.updateChecklistItem(ctx, params, domain.EventTypeChecklistClaimed)
+}
+
+// CompleteChecklistItem marks a checklist item done. Allowed for the agent that claimed it
+// and for the task assignee.
+func (s *TaskService) CompleteChecklistItem(ctx context.Context, params ChecklistItemParams) (*domain.TaskEvent, error) {
+	return s.updateChecklistItem(ctx, params, domain.EventTypeChecklistCompleted)
+}
+
+// updateChecklistItem claims or completes a checklist item and records a scoped event on the task.
+func (s *TaskService) updateChecklistItem(
+	ctx context.Context,
+	params ChecklistItemParams,
+	eventType domain.EventType,
+) (*domain.TaskEvent, error) {
+	if params.Comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !task.IsVisibleTo(agent) {
+		return nil, fmt.Errorf("%w: task %s is not visible to agent %s", domain.ErrPermissionDenied, task.ID, agent.ID)
+	}
+
+	item, err := s.checklistRepo.GetByIDForUpdate(ctx, tx, task.ID, params.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.IsCompleted() {
+		return nil, fmt.Errorf("%w: checklist item %s", domain.ErrChecklistItemCompleted, item.ID)
+	}
+
+	switch eventType {
+	case domain.EventTypeChecklistClaimed:
+		if task.Status != domain.TaskStatusInProgress {
+			return nil, fmt.Errorf("%w: task %s is in %s status, expected IN_PROGRESS", domain.ErrInvalidTransition, task.ID, task.Status)
+		}
+		if item.ClaimedBy != nil {
+			return nil, fmt.Errorf("%w: checklist item %s claimed by %s", domain.ErrChecklistItemClaimed, item.ID, *item.ClaimedBy)
+		}
+		if err := s.checklistRepo.Claim(ctx, tx, item.ID, agent.ID); err != nil {
+			return nil, err
+		}
+	case domain.EventTypeChecklistCompleted:
+		if task.Status.IsTerminal() {
+			return nil, fmt.Errorf("%w: task %s is in terminal status %s", domain.ErrInvalidTransition, task.ID, task.Status)
+		}
+		if !item.IsClaimedBy(agent.ID) && !task.IsOwnedBy(agent.ID) {
+			return nil, fmt.Errorf("%w: agent %s neither claimed checklist item %s nor owns task %s", domain.ErrPermissionDenied, agent.ID, item.ID, task.ID)
+		}
+		if err := s.checklistRepo.Complete(ctx, tx, item.ID, agent.ID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported checklist event type %s", eventType)
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: &agent.ID,
+		Type:    eventType,
+		Comment: params.Comment,
+		Data: map[string]any{
+			"checklist_item_id":    item.ID,
+			"checklist_item_title": item.Title,
+		},
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("checklist item updated",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"checklist_item_id", item.ID,
+		"event_type", eventType,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
+
+// GetChecklist returns the checklist items of a task in order.
+func (s *TaskService) GetChecklist(ctx context.Context, taskID string) ([]*domain.ChecklistItem, error) {
+	return s.checklistRepo.ListByTaskID(ctx, taskID)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// SetClaimFairness sets the claim limits of a workspace; the zero value lifts
+// them. A quota without a window counts claims per hour.
+func (s *TaskService) SetClaimFairness(ctx context.Context, workspaceID string, fairness domain.ClaimFairness) (domain.ClaimFairness, error) {
+	if fairness.Quota < 0 || fairness.Quota > domain.MaxClaimQuota {
+		return domain.ClaimFairness{}, fmt.Errorf("%w: quota must be between 1 and %d, or 0 for none", domain.ErrValidation, domain.MaxClaimQuota)
+	}
+	if fairness.Window == 0 {
+		fairness.Window = domain.DefaultClaimQuotaWindowSeconds * time.Second
+	}
+	seconds := fairness.Window / time.Second
+	if fairness.Window%time.Second != 0 || seconds < domain.MinClaimQuotaWindowSeconds || seconds > domain.MaxClaimQuotaWindowSeconds {
+		return domain.ClaimFairness{}, fmt.Errorf("%w: window must be whole seconds between %d and %d",
+			domain.ErrValidation, domain.MinClaimQuotaWindowSeconds, domain.MaxClaimQuotaWindowSeconds)
+	}
+
+	if err := s.workspaceRepo.SetClaimFairness(ctx, workspaceID, fairness); err != nil {
+		return domain.ClaimFairness{}, err
+	}
+
+	slog.Info("workspace claim fairness updated",
+		"workspace_id", workspaceID,
+		"quota", fairness.Quota,
+		"window", fairness.Window,
+		"take_turns", fairness.TakeTurns,
+	)
+
+	return fairness, nil
+}
+
+// checkClaimFairness refuses a claim that would break the workspace's claim
+// limits: ErrClaimQuotaExceeded once the agent used up its quota for the window,
+// ErrClaimTurn when the agent made the last claim and another live agent able to
+// take the task is idle. Auto-assignment is not subject to these limits.
+func (s *TaskService) checkClaimFairness(ctx context.Context, workspace *domain.Workspace, task *domain.Task, agent *domain.Agent) error {
+	fairness := workspace.ClaimFairness
+
+	if fairness.Quota > 0 {
+		claims, err := s.eventRepo.CountClaimsSince(ctx, agent.ID, time.Now().Add(-fairness.Window))
+		if err != nil {
+			return err
+		}
+		if claims >= fairness.Quota {
+			return fmt.Errorf("%w: agent %s claimed %d tasks within %s, the workspace allows %d",
+				domain.ErrClaimQuotaExceeded, agent.ID, claims, fairness.Window, fairness.Quota)
+		}
+	}
+
+	if fairness.TakeTurns {
+		lastClaimant, err := s.eventRepo.LastClaimantID(ctx, workspace.ID)
+		if err != nil {
+			return err
+		}
+		if lastClaimant == nil || *lastClaimant != agent.ID {
+			return nil
+		}
+
+		idle, err := s.agentRepo.CountIdleAgents(ctx, workspace.ID, agent.ID, task.RequiredCapabilities)
+		if err != nil {
+			return err
+		}
+		if idle > 0 {
+			return fmt.Errorf("%w: agent %s made the last claim and %d other agents are idle",
+				domain.ErrClaimTurn, agent.ID, idle)
+		}
+	}
+
+	return nil
+}
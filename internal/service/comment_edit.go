@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// EditComment replaces the body of the agent's own comment, posted no longer
+// than domain.CommentEditWindow ago. The previous body is kept for operators
+// and a comment_edited event records the change. Mentions stay as posted.
+func (s *TaskService) EditComment(ctx context.Context, taskID, eventID, agentID, comment string) (*domain.TaskEvent, error) {
+	if comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+	return s.changeComment(ctx, taskID, eventID, agentID, comment, domain.CommentActionEdited)
+}
+
+// RedactComment empties the agent's own comment within domain.CommentEditWindow,
+// e.g. to take back a secret posted by accident. Like an edit, it keeps the
+// previous body for operators and records a comment_edited event.
+func (s *TaskService) RedactComment(ctx context.Context, taskID, eventID, agentID string) (*domain.TaskEvent, error) {
+	return s.changeComment(ctx, taskID, eventID, agentID, "", domain.CommentActionRedacted)
+}
+
+// changeComment edits or redacts a comment, as action says.
+func (s *TaskService) changeComment(ctx context.Context, taskID, eventID, agentID, comment, action string) (*domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err, "task_id", taskID)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+
+	agent, err := s.getActiveAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("get agent: %w", err)
+	}
+	if !task.IsVisibleTo(agent) {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	original, err := s.eventRepo.GetCommentForUpdate(ctx, tx, task.ID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if original.ActorID == nil || *original.ActorID != agent.ID {
+		return nil, fmt.Errorf("%w: only the author can change a comment", domain.ErrPermissionDenied)
+	}
+	if original.RedactedAt != nil {
+		return nil, domain.ErrCommentRedacted
+	}
+	if time.Since(original.CreatedAt) > domain.CommentEditWindow {
+		return nil, fmt.Errorf("%w: comments can be changed for %s after posting", domain.ErrCommentEditWindowClosed, domain.CommentEditWindow)
+	}
+
+	updated, err := s.eventRepo.UpdateComment(ctx, tx, original.ID, comment, action == domain.CommentActionRedacted)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: &agent.ID,
+		Type:    domain.EventTypeCommentEdited,
+		Comment: fmt.Sprintf("Comment %s.", action),
+		Data:    map[string]any{"event_id": original.ID, "action": action},
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("comment changed",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"event_id", original.ID,
+		"action", action,
+	)
+
+	return updated, nil
+}
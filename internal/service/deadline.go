@@ -7,7 +7,8 @@ import (
 )
 
 // CalculateDeadline calculates the deadline for a task based on workspace configuration.
-// Returns nil for statuses without deadlines (DONE, CANCELLED, STUCK).
+// Returns nil for statuses without deadlines (DONE, CANCELLED, STUCK, and
+// WAITING_APPROVAL, which waits on an operator rather than on agents).
 func CalculateDeadline(workspace *domain.Workspace, status domain.TaskStatus) *time.Time {
 	if !status.HasDeadline() {
 		return nil
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// SetDeadlineExpiry sets the status tasks of a workspace enter when their
+// deadline in a status expires (status -> status). Statuses left out go to
+// STUCK; an empty map restores that default everywhere.
+func (s *TaskService) SetDeadlineExpiry(ctx context.Context, workspaceID string, expiry map[string]string) error {
+	for status, target := range expiry {
+		targets := domain.TaskStatus(status).ExpiryTargets()
+		if targets == nil {
+			return fmt.Errorf("%w: deadline_expiry: %q has no deadline; use NEW, IN_PROGRESS or BLOCKED", domain.ErrValidation, status)
+		}
+		if !slices.Contains(targets, domain.TaskStatus(target)) {
+			return fmt.Errorf("%w: deadline_expiry: an expired %s deadline may move the task to %v, not %q",
+				domain.ErrValidation, status, targets, target)
+		}
+	}
+
+	if err := s.workspaceRepo.SetDeadlineExpiry(ctx, workspaceID, expiry); err != nil {
+		return err
+	}
+
+	slog.Info("workspace deadline expiry updated", "workspace_id", workspaceID, "expiry", expiry)
+
+	return nil
+}
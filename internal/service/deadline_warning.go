@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// SetDeadlineWarning sets the share of a status deadline, in percent, left when
+// tasks of a workspace get a deadline_warning event. 0 turns warnings off.
+func (s *TaskService) SetDeadlineWarning(ctx context.Context, workspaceID string, percent int) error {
+	if percent != 0 && (percent < domain.MinDeadlineWarningPercent || percent > domain.MaxDeadlineWarningPercent) {
+		return fmt.Errorf("%w: percent must be between %d and %d",
+			domain.ErrValidation, domain.MinDeadlineWarningPercent, domain.MaxDeadlineWarningPercent)
+	}
+
+	if err := s.workspaceRepo.SetDeadlineWarning(ctx, workspaceID, percent); err != nil {
+		return err
+	}
+
+	slog.Info("workspace deadline warning updated", "workspace_id", workspaceID, "percent", percent)
+
+	return nil
+}
+
+// WarnApproachingDeadlines records a system deadline_warning event on every task
+// with no more than its workspace's warning share of the status deadline left,
+// once per deadline, so agents can act before ProcessExpiredDeadlines moves the
+// task on. It is meant to run periodically, like ProcessExpiredDeadlines.
+// Returns the number of tasks warned, and an error if any task failed.
+func (s *TaskService) WarnApproachingDeadlines(ctx context.Context) (int, error) {
+	tasks, err := s.taskRepo.FindDeadlineWarnings(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("find approaching deadlines: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		slog.Info("no approaching deadlines found")
+		return 0, nil
+	}
+
+	count := 0
+	var errs []error // Accumulate errors
+	for _, task := range tasks {
+		warned, err := s.warnDeadline(ctx, task.ID)
+		if err != nil {
+			slog.Error("failed to warn about approaching deadline",
+				"task_id", task.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
+			continue
+		}
+		if warned {
+			count++
+		}
+	}
+
+	slog.Info("warned about approaching deadlines",
+		"total", len(tasks),
+		"warned", count,
+		"failed", len(errs),
+	)
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("warned %d/%d tasks, %d failures: %v",
+			count, len(tasks), len(errs), errs)
+	}
+
+	return count, nil
+}
+
+// warnDeadline records a deadline_warning event on one task. The deadline, the
+// workspace setting and earlier warnings are re-checked under the row lock, so a
+// task that moved on or was warned meanwhile is left alone (returns false).
+func (s *TaskService) warnDeadline(ctx context.Context, taskID string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return false, err
+	}
+	if task.StatusDeadlineAt == nil || !task.Status.HasDeadline() {
+		return false, nil
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return false, fmt.Errorf("get workspace: %w", err)
+	}
+	percent := workspace.DeadlineWarningPercent
+	minutes := workspace.GetDeadlineMinutes(task.Status)
+	if percent == 0 || minutes == 0 || workspace.IsArchived() {
+		return false, nil
+	}
+
+	length := time.Duration(minutes) * time.Minute
+	remaining := time.Until(*task.StatusDeadlineAt)
+	if remaining <= 0 || remaining > length*time.Duration(percent)/100 {
+		return false, nil
+	}
+
+	warned, err := s.taskRepo.HasDeadlineWarning(ctx, tx, task.ID, task.StatusDeadlineAt.Add(-length))
+	if err != nil {
+		return false, err
+	}
+	if warned {
+		return false, nil
+	}
+
+	remainingSeconds := int(remaining / time.Second)
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: nil, // system event
+		Type:    domain.EventTypeDeadlineWarning,
+		Comment: fmt.Sprintf("Status deadline in %s: the task goes %s unless it leaves %s by then.",
+			remaining.Round(time.Minute), workspace.ExpiryStatus(task.Status), task.Status),
+		Data: map[string]any{
+			"status":            task.Status,
+			"deadline_at":       task.StatusDeadlineAt.UTC().Format(time.RFC3339),
+			"remaining_seconds": remainingSeconds,
+			"percent":           percent,
+		},
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return false, err
+	}
+
+	slog.Info("task deadline warning",
+		"task_id", task.ID,
+		"status", task.Status,
+		"remaining_seconds", remainingSeconds,
+	)
+
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// DeleteTaskParams holds parameters for soft-deleting a task.
+type DeleteTaskParams struct {
+	TaskID      string
+	AgentID     *string // Deleting agent; nil when an operator deletes through the admin API
+	WorkspaceID string  // Required for operator deletes: the task must belong to this workspace
+	Comment     string
+}
+
+// DeleteTask soft-deletes a task: it disappears from every API and is hard-deleted
+// with its events by `sloptask purge`. Only the task creator or an operator may
+// delete it. The task is dropped from the blocked_by list of its dependents so
+// they are not blocked forever by a task nobody can see.
+func (s *TaskService) DeleteTask(ctx context.Context, params DeleteTaskParams) (*domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.AgentID != nil {
+		agent, err := s.getActiveAgent(ctx, *params.AgentID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Tasks of other workspaces are reported as missing, like every other read
+		if task.WorkspaceID != agent.WorkspaceID {
+			return nil, fmt.Errorf("%w: task %s", domain.ErrTaskNotFound, task.ID)
+		}
+		if !task.IsCreatedBy(agent.ID) {
+			return nil, fmt.Errorf("%w: agent %s did not create task %s", domain.ErrNotTaskCreator, agent.ID, task.ID)
+		}
+	} else if task.WorkspaceID != params.WorkspaceID {
+		return nil, fmt.Errorf("%w: task %s", domain.ErrTaskNotFound, task.ID)
+	}
+
+	if err := s.taskRepo.SoftDelete(ctx, tx, task.ID, params.AgentID); err != nil {
+		return nil, err
+	}
+
+	unblocked, err := s.taskRepo.RemoveBlocker(ctx, tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := strings.TrimSpace(params.Comment)
+	if comment == "" {
+		comment = "Task deleted"
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: params.AgentID,
+		Type:    domain.EventTypeDeleted,
+		Comment: comment,
+		Data:    map[string]any{"removed_from_blocked_by": unblocked},
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task deleted",
+		"task_id", task.ID,
+		"workspace_id", task.WorkspaceID,
+		"by_operator", params.AgentID == nil,
+		"removed_from_blocked_by", unblocked,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
+
+// PurgeDeletedTasks hard-deletes tasks soft-deleted more than olderThan ago,
+// together with their events, checklist items and revisions.
+// Returns the number of tasks removed.
+func (s *TaskService) PurgeDeletedTasks(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan < 0 {
+		return 0, fmt.Errorf("older-than must not be negative, got %s", olderThan)
+	}
+
+	count, err := s.taskRepo.PurgeDeleted(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
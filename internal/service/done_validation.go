@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/pkg/client"
+)
+
+// maxDoneRejectionReason limits how much of a rejection body is returned to the agent.
+const maxDoneRejectionReason = 500
+
+// Outcomes of DONE validation recorded in the event data under "done_validation".
+const (
+	doneValidationAccepted    = "accepted"
+	doneValidationUnavailable = "unavailable" // let through because the hook fails open
+)
+
+// SetDoneValidation sets the webhook asked to approve every move to DONE in a
+// workspace, or removes it when hook is nil. A zero timeout means the default.
+func (s *TaskService) SetDoneValidation(ctx context.Context, workspaceID string, hook *domain.DoneValidationHook) (*domain.DoneValidationHook, error) {
+	if hook != nil {
+		target, err := normalizeReportTarget(domain.ReportTargetWebhook, hook.URL)
+		if err != nil {
+			return nil, err
+		}
+		hook.URL = target
+
+		if hook.Timeout == 0 {
+			hook.Timeout = domain.DefaultDoneValidationTimeout
+		}
+		if hook.Timeout < time.Second || hook.Timeout > domain.MaxDoneValidationTimeout || hook.Timeout%time.Second != 0 {
+			return nil, fmt.Errorf("%w: timeout must be whole seconds between 1 and %d", domain.ErrValidation, int(domain.MaxDoneValidationTimeout/time.Second))
+		}
+	}
+
+	if err := s.workspaceRepo.SetDoneValidation(ctx, workspaceID, hook); err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace done validation updated", "workspace_id", workspaceID, "enabled", hook != nil)
+
+	return hook, nil
+}
+
+// validateDone asks the workspace's validation webhook to approve moving a task
+// to DONE. It returns the outcome to record on the event, ErrDoneRejected with
+// the webhook's reason, or ErrDoneValidationUnavailable.
+func (s *TaskService) validateDone(ctx context.Context, hook *domain.DoneValidationHook, request *client.DoneValidationRequest) (string, error) {
+	err := s.callDoneValidation(ctx, hook, request)
+	if err == nil {
+		return doneValidationAccepted, nil
+	}
+	if errors.Is(err, domain.ErrDoneRejected) {
+		return "", err
+	}
+
+	if hook.FailOpen {
+		slog.Warn("done validation unavailable, letting completion through",
+			"task_id", request.TaskID,
+			"error", err,
+		)
+		return doneValidationUnavailable, nil
+	}
+	return "", fmt.Errorf("%w: %w", domain.ErrDoneValidationUnavailable, err)
+}
+
+// callDoneValidation POSTs a signed validation request and interprets the response.
+func (s *TaskService) callDoneValidation(ctx context.Context, hook *domain.DoneValidationHook, request *client.DoneValidationRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encode validation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build validation request: %w", err)
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sloptask-validation")
+	req.Header.Set(client.HeaderDelivery, uuid.NewString())
+	req.Header.Set(client.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	secrets, err := webhookSecrets(ctx, ReportDeliveryConfig{}, s.webhookSecretRepo, request.WorkspaceID, now)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		req.Header.Set(client.HeaderSignature, signWebhook(secrets, now, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("call validation webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("read validation response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode <= 499:
+		return fmt.Errorf("%w: %s", domain.ErrDoneRejected, rejectionReason(resp.Status, respBody))
+	default:
+		return fmt.Errorf("validation webhook %s responded %s", req.URL.Host, resp.Status)
+	}
+}
+
+// rejectionReason extracts the reason of a rejection: the reason field of a JSON
+// body, else the body as text, else the HTTP status.
+func rejectionReason(status string, body []byte) string {
+	var response client.DoneValidationResponse
+	reason := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &response) == nil {
+		reason = strings.TrimSpace(response.Reason)
+	}
+	if reason == "" {
+		return status
+	}
+	if len(reason) > maxDoneRejectionReason {
+		reason = strings.ToValidUTF8(reason[:maxDoneRejectionReason], "") + "…"
+	}
+	return reason
+}
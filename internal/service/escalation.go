@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// HeaderEscalationID carries the ID of the notification in escalation webhook deliveries.
+const HeaderEscalationID = "X-Sloptask-Escalation"
+
+// matchEscalationRoute picks who is told about an escalation of the task: the
+// first of the workspace's routes that matches, else the task's creator. Returns
+// nil when nobody is left to tell, i.e. the creator escalates their own task.
+func (s *TaskService) matchEscalationRoute(ctx context.Context, task *domain.Task, escalatorID string) (*domain.EscalationRoute, error) {
+	routes, err := s.escalationRepo.ListRoutes(ctx, task.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("list escalation routes: %w", err)
+	}
+
+	if route := domain.MatchEscalationRoute(routes, task); route != nil {
+		return route, nil
+	}
+	if task.CreatorID == escalatorID {
+		return nil, nil
+	}
+
+	return &domain.EscalationRoute{
+		WorkspaceID: task.WorkspaceID,
+		Name:        domain.DefaultEscalationRoute,
+		TargetType:  domain.EscalationTargetAgent,
+		Target:      task.CreatorID,
+	}, nil
+}
+
+// notifyEscalation stores the notification of an escalated event for the route's
+// target (within transaction). Agents find it in their inbox right away; webhook
+// and email notifications are left to the scheduler.
+func (s *TaskService) notifyEscalation(
+	ctx context.Context,
+	tx pgx.Tx,
+	route *domain.EscalationRoute,
+	task *domain.Task,
+	event *domain.TaskEvent,
+	escalator *domain.Agent,
+) error {
+	notification := &domain.EscalationNotification{
+		WorkspaceID: task.WorkspaceID,
+		TaskID:      task.ID,
+		EventID:     event.ID,
+		Route:       route.Name,
+		TargetType:  route.TargetType,
+		Target:      route.Target,
+		Payload: map[string]any{
+			"type":              "task.escalated",
+			"workspace_id":      task.WorkspaceID,
+			"task_id":           task.ID,
+			"task_title":        task.Title,
+			"task_priority":     task.Priority,
+			"task_labels":       task.Labels,
+			"assignee_id":       task.AssigneeID,
+			"escalated_by":      escalator.ID,
+			"escalated_by_name": escalator.Name,
+			"comment":           event.Comment,
+			"route":             route.Name,
+			"escalated_at":      event.CreatedAt,
+		},
+		Status: domain.EscalationPending,
+	}
+	if route.TargetType == domain.EscalationTargetAgent {
+		now := time.Now()
+		notification.Status = domain.EscalationDelivered
+		notification.DeliveredAt = &now
+	}
+
+	if err := s.escalationRepo.CreateNotification(ctx, tx, notification); err != nil {
+		return fmt.Errorf("create escalation notification: %w", err)
+	}
+
+	return nil
+}
+
+// EscalationService manages escalation routes, agents' escalation inboxes and the
+// delivery of webhook and email notifications.
+type EscalationService struct {
+	pool           *pgxpool.Pool
+	escalationRepo repository.EscalationRepository
+	agentRepo      repository.AgentRepository
+	workspaceRepo  repository.WorkspaceRepository
+	secretRepo     repository.WebhookSecretRepository
+	delivery       ReportDeliveryConfig
+}
+
+// NewEscalationService creates a new EscalationService. The delivery configuration
+// is only used by DeliverPendingEscalations.
+func NewEscalationService(
+	pool *pgxpool.Pool,
+	escalationRepo repository.EscalationRepository,
+	agentRepo repository.AgentRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	secretRepo repository.WebhookSecretRepository,
+	delivery ReportDeliveryConfig,
+) *EscalationService {
+	return &EscalationService{
+		pool:           pool,
+		escalationRepo: escalationRepo,
+		agentRepo:      agentRepo,
+		workspaceRepo:  workspaceRepo,
+		secretRepo:     secretRepo,
+		delivery:       delivery,
+	}
+}
+
+// ListRoutes returns the escalation routes of a workspace in evaluation order.
+func (s *EscalationService) ListRoutes(ctx context.Context, workspaceID string) ([]*domain.EscalationRoute, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.escalationRepo.ListRoutes(ctx, workspaceID)
+}
+
+// SetRoutes validates and stores the escalation routes of a workspace, replacing
+// the existing ones. Routes are evaluated in the given order.
+func (s *EscalationService) SetRoutes(ctx context.Context, workspaceID string, routes []*domain.EscalationRoute) ([]*domain.EscalationRoute, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	if len(routes) > domain.MaxEscalationRoutes {
+		return nil, fmt.Errorf("%w: at most %d escalation routes allowed", domain.ErrValidation, domain.MaxEscalationRoutes)
+	}
+
+	names := make(map[string]bool, len(routes))
+	for i, route := range routes {
+		if err := s.validateRoute(ctx, workspaceID, route); err != nil {
+			return nil, fmt.Errorf("route %d: %w", i+1, err)
+		}
+		if names[route.Name] {
+			return nil, fmt.Errorf("%w: route %d: duplicate route name %q", domain.ErrValidation, i+1, route.Name)
+		}
+		names[route.Name] = true
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if err := s.escalationRepo.ReplaceRoutes(ctx, tx, workspaceID, routes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("escalation routes updated",
+		"workspace_id", workspaceID,
+		"routes", len(routes),
+	)
+
+	return routes, nil
+}
+
+// validateRoute checks a route's name, criteria and target, and normalizes them.
+func (s *EscalationService) validateRoute(ctx context.Context, workspaceID string, route *domain.EscalationRoute) error {
+	route.Name = strings.TrimSpace(route.Name)
+	if route.Name == "" || len(route.Name) > domain.MaxEscalationRouteNameLength {
+		return fmt.Errorf("%w: name must be 1-%d characters", domain.ErrValidation, domain.MaxEscalationRouteNameLength)
+	}
+	if route.Name == domain.DefaultEscalationRoute {
+		return fmt.Errorf("%w: %q is reserved for the fallback to the task creator", domain.ErrValidation, domain.DefaultEscalationRoute)
+	}
+
+	if route.Label != nil {
+		label, err := domain.NormalizeLabelName(*route.Label)
+		if err != nil {
+			return err
+		}
+		route.Label = &label
+	}
+	if route.Priority != nil && !route.Priority.IsValid() {
+		return fmt.Errorf("%w: priority must be 'low', 'normal', 'high', or 'critical'", domain.ErrValidation)
+	}
+	if route.CreatorID != nil {
+		if err := s.requireWorkspaceAgent(ctx, workspaceID, *route.CreatorID, "creator_id"); err != nil {
+			return err
+		}
+	}
+
+	switch route.TargetType {
+	case domain.EscalationTargetAgent:
+		route.Target = strings.TrimSpace(route.Target)
+		return s.requireWorkspaceAgent(ctx, workspaceID, route.Target, "target")
+	case domain.EscalationTargetWebhook, domain.EscalationTargetEmail:
+		target, err := normalizeReportTarget(domain.ReportTargetType(route.TargetType), route.Target)
+		if err != nil {
+			return err
+		}
+		route.Target = target
+		return nil
+	default:
+		return fmt.Errorf("%w: target_type must be agent, webhook or email", domain.ErrValidation)
+	}
+}
+
+// requireWorkspaceAgent checks that agentID names an agent of the workspace.
+func (s *EscalationService) requireWorkspaceAgent(ctx context.Context, workspaceID, agentID, field string) error {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if errors.Is(err, domain.ErrAgentNotFound) || (err == nil && agent.WorkspaceID != workspaceID) {
+		return fmt.Errorf("%w: %s must be an agent of the workspace", domain.ErrValidation, field)
+	}
+	return err
+}
+
+// ListAgentEscalations returns the escalation notifications routed to an agent,
+// newest first, and their total count.
+func (s *EscalationService) ListAgentEscalations(ctx context.Context, agentID string, limit, offset int) ([]*domain.EscalationNotification, int, error) {
+	return s.escalationRepo.ListAgentNotifications(ctx, agentID, limit, offset)
+}
+
+// DeliverPendingEscalations delivers every webhook and email notification that is
+// due. Failed deliveries are retried with growing delays and marked failed after
+// MaxEscalationDeliveryAttempts. Returns the number of notifications delivered.
+func (s *EscalationService) DeliverPendingEscalations(ctx context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+	for {
+		delivered, ok, err := s.deliverNextPending(ctx, now)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+		if delivered {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// deliverNextPending delivers one due notification while holding its row lock, so
+// concurrent schedulers never deliver it twice. ok is false when none is due.
+func (s *EscalationService) deliverNextPending(ctx context.Context, now time.Time) (delivered, ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	notification, err := s.escalationRepo.LockNextPendingDelivery(ctx, tx, now)
+	if err != nil {
+		if errors.Is(err, domain.ErrEscalationNotificationNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	var deliveryErr *string
+	var nextAttemptAt *time.Time
+	if err := s.deliver(ctx, notification); err != nil {
+		message := err.Error()
+		deliveryErr = &message
+		if attempts := notification.Attempts + 1; attempts < domain.MaxEscalationDeliveryAttempts {
+			next := now.Add(domain.EscalationRetryDelay(attempts))
+			nextAttemptAt = &next
+		}
+	}
+
+	if err := s.escalationRepo.RecordDelivery(ctx, tx, notification.ID, now, deliveryErr, nextAttemptAt); err != nil {
+		return false, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if deliveryErr != nil {
+		slog.Warn("escalation delivery failed",
+			"notification_id", notification.ID,
+			"task_id", notification.TaskID,
+			"target_type", notification.TargetType,
+			"attempts", notification.Attempts+1,
+			"gave_up", nextAttemptAt == nil,
+			"error", *deliveryErr,
+		)
+		return false, true, nil
+	}
+
+	slog.Info("escalation delivered",
+		"notification_id", notification.ID,
+		"task_id", notification.TaskID,
+		"target_type", notification.TargetType,
+	)
+
+	return true, true, nil
+}
+
+// deliver sends a notification to its webhook or email target.
+func (s *EscalationService) deliver(ctx context.Context, notification *domain.EscalationNotification) error {
+	headers := map[string]string{HeaderEscalationID: notification.ID}
+
+	switch notification.TargetType {
+	case domain.EscalationTargetWebhook:
+		body, err := json.Marshal(notification.Payload)
+		if err != nil {
+			return fmt.Errorf("encode escalation: %w", err)
+		}
+		return postWebhook(ctx, s.delivery, s.secretRepo, webhookDelivery{
+			WorkspaceID: notification.WorkspaceID,
+			URL:         notification.Target,
+			UserAgent:   "sloptask-escalations",
+			ContentType: "application/json",
+			Headers:     headers,
+			Body:        body,
+		})
+	case domain.EscalationTargetEmail:
+		subject, body := renderEscalationEmail(notification)
+		return sendEmail(s.delivery, emailDelivery{
+			Recipients:  strings.Split(notification.Target, ","),
+			Subject:     subject,
+			ContentType: "text/plain; charset=utf-8",
+			Headers:     headers,
+			Body:        body,
+		})
+	default:
+		return fmt.Errorf("escalation target type %q is not delivered by the scheduler", notification.TargetType)
+	}
+}
+
+// renderEscalationEmail writes the subject and plain-text body of an escalation email.
+func renderEscalationEmail(notification *domain.EscalationNotification) (string, []byte) {
+	field := func(name string) string {
+		value, _ := notification.Payload[name].(string)
+		return value
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task %q (%s priority) was escalated by %s.\n\n", field("task_title"), field("task_priority"), field("escalated_by_name"))
+	fmt.Fprintf(&b, "%s\n\n", field("comment"))
+	fmt.Fprintf(&b, "Task: %s\n", notification.TaskID)
+	fmt.Fprintf(&b, "Route: %s\n", notification.Route)
+
+	return "Escalated: " + field("task_title"), []byte(b.String())
+}
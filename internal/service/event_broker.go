@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/pkg/client"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// brokerTopicPattern matches names valid both as a Kafka topic and as a NATS
+// subject without wildcards: dot-separated tokens of letters, digits, _ and -.
+var brokerTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// maxBrokerTopicLength is the longest topic name Kafka accepts.
+const maxBrokerTopicLength = 249
+
+// brokerPublishTimeout bounds how long a broker may take to acknowledge a message.
+const brokerPublishTimeout = 10 * time.Second
+
+// SetEventBroker sets the broker topic receiving a workspace's task events, or
+// stops publishing them to the broker when topic is nil. No event types means
+// all of them.
+func (s *TaskService) SetEventBroker(ctx context.Context, workspaceID string, topic *domain.EventBrokerTopic) (*domain.EventBrokerTopic, error) {
+	if topic != nil {
+		topic.Topic = strings.TrimSpace(topic.Topic)
+		if len(topic.Topic) > maxBrokerTopicLength || !brokerTopicPattern.MatchString(topic.Topic) {
+			return nil, fmt.Errorf("%w: topic must be at most %d characters of letters, digits, _ and -, in dot-separated parts", domain.ErrValidation, maxBrokerTopicLength)
+		}
+
+		eventTypes := []domain.EventType{}
+		for _, eventType := range topic.EventTypes {
+			if !eventType.IsValid() {
+				return nil, fmt.Errorf("%w: unknown event type %q", domain.ErrValidation, eventType)
+			}
+			if !slices.Contains(eventTypes, eventType) {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+		slices.Sort(eventTypes)
+		topic.EventTypes = eventTypes
+	}
+
+	if err := s.workspaceRepo.SetEventBroker(ctx, workspaceID, topic); err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace event broker topic updated", "workspace_id", workspaceID, "enabled", topic != nil)
+
+	return topic, nil
+}
+
+// BrokerMessage is a task event published to a broker topic.
+type BrokerMessage struct {
+	Topic   string
+	Key     string // the task ID, so a task's events share a Kafka partition
+	ID      string // the delivery ID, stable across retries
+	Headers map[string]string
+	Body    []byte
+}
+
+// EventBroker publishes task events to a message broker.
+type EventBroker interface {
+	// Publish returns once the broker has accepted the message.
+	Publish(ctx context.Context, message BrokerMessage) error
+	Close() error
+}
+
+// NewEventBroker connects to the broker at rawURL: nats://host:4222 (or tls://)
+// for NATS, kafka://host:9092,host:9092 for Kafka.
+func NewEventBroker(rawURL string) (EventBroker, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("broker url %q has no scheme, expected nats:// or kafka://", rawURL)
+	}
+
+	switch scheme {
+	case "nats", "tls":
+		conn, err := nats.Connect(rawURL, nats.Name("sloptask-scheduler"))
+		if err != nil {
+			return nil, fmt.Errorf("connect to NATS: %w", err)
+		}
+		return &natsBroker{conn: conn}, nil
+	case "kafka":
+		brokers := strings.Split(rest, ",")
+		if slices.Contains(brokers, "") {
+			return nil, fmt.Errorf("broker url %q lists an empty Kafka broker", rawURL)
+		}
+		return &kafkaBroker{writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchSize:              1, // messages are published one at a time; don't wait for a batch
+			WriteTimeout:           brokerPublishTimeout,
+			AllowAutoTopicCreation: true,
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %q, expected nats, tls or kafka", scheme)
+	}
+}
+
+// natsBroker publishes to NATS subjects. The delivery ID is sent as
+// Nats-Msg-Id, so JetStream streams capturing the subject drop redeliveries.
+type natsBroker struct {
+	conn *nats.Conn
+}
+
+func (b *natsBroker) Publish(ctx context.Context, message BrokerMessage) error {
+	msg := nats.NewMsg(message.Topic)
+	msg.Data = message.Body
+	msg.Header.Set(nats.MsgIdHdr, message.ID)
+	msg.Header.Set(client.HeaderDelivery, message.ID)
+	for key, value := range message.Headers {
+		msg.Header.Set(key, value)
+	}
+
+	if err := b.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("publish to NATS subject %s: %w", message.Topic, err)
+	}
+
+	// A flush round trip confirms the server received the message
+	ctx, cancel := context.WithTimeout(ctx, brokerPublishTimeout)
+	defer cancel()
+	if err := b.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("flush NATS connection: %w", err)
+	}
+
+	return nil
+}
+
+func (b *natsBroker) Close() error {
+	return b.conn.Drain()
+}
+
+// kafkaBroker publishes to Kafka topics, keyed by task.
+type kafkaBroker struct {
+	writer *kafka.Writer
+}
+
+func (b *kafkaBroker) Publish(ctx context.Context, message BrokerMessage) error {
+	msg := kafka.Message{
+		Topic:   message.Topic,
+		Key:     []byte(message.Key),
+		Value:   message.Body,
+		Headers: []kafka.Header{{Key: client.HeaderDelivery, Value: []byte(message.ID)}},
+	}
+	for key, value := range message.Headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	if err := b.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("publish to Kafka topic %s: %w", message.Topic, err)
+	}
+
+	return nil
+}
+
+func (b *kafkaBroker) Close() error {
+	return b.writer.Close()
+}
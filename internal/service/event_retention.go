@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// pruneEventsBatchSize bounds each delete of PruneEvents so it holds its locks
+// briefly.
+const pruneEventsBatchSize = 10000
+
+// PruneEvents deletes the task events of a workspace recorded before before, in
+// batches. Tasks keep their created event, and comment threads stay whole while
+// any of their comments is recent or still referenced. Events younger than the
+// comment edit window are never pruned. Returns the number of events deleted.
+func (s *TaskService) PruneEvents(ctx context.Context, workspaceID string, before time.Time) (int64, error) {
+	if time.Since(before) < domain.CommentEditWindow {
+		return 0, fmt.Errorf("events younger than %s cannot be pruned", domain.CommentEditWindow)
+	}
+
+	var total int64
+	for {
+		count, err := s.eventRepo.PruneBefore(ctx, workspaceID, before, pruneEventsBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += count
+		if count < pruneEventsBatchSize {
+			break
+		}
+		slog.Debug("pruned task events batch", "workspace_id", workspaceID, "events_pruned", total)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// AwaitExternalParams holds parameters for parking a task on an external system.
+type AwaitExternalParams struct {
+	TaskID  string
+	AgentID string
+	Ref     domain.ExternalRef
+	Comment string
+}
+
+// AwaitExternal moves the agent's IN_PROGRESS task to AWAITING_EXTERNAL.
+// The task keeps its assignee and has no deadline while it waits.
+func (s *TaskService) AwaitExternal(ctx context.Context, params AwaitExternalParams) (*domain.TaskEvent, error) {
+	if params.Comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+
+	ref, err := domain.NormalizeExternalRef(params.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if ref.URL != nil {
+		if err := validateArtefactURL(*ref.URL); err != nil {
+			return nil, fmt.Errorf("%w: url must be a valid http:// or https:// URL", domain.ErrInvalidExternalRef)
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.CanAwaitExternal(task, agent); err != nil {
+		return nil, err
+	}
+
+	// The reference goes in first: a waiting task must always have one
+	if err := s.taskRepo.SetExternalRef(ctx, tx, task.ID, &ref); err != nil {
+		return nil, err
+	}
+
+	oldStatus := domain.TaskStatusInProgress
+	newStatus := domain.TaskStatusAwaitingExternal
+	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+		oldStatus, newStatus,
+		task.AssigneeID, nil, nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		ActorID:   &agent.ID,
+		Type:      domain.EventTypeAwaitingExternal,
+		OldStatus: &oldStatus,
+		NewStatus: &newStatus,
+		Comment:   params.Comment,
+		Data:      externalRefData(ref),
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task awaiting external",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"external_system", ref.System,
+		"external_id", ref.ID,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
+
+// ResolveExternalParams holds parameters for resolving an external reference.
+type ResolveExternalParams struct {
+	WorkspaceID string
+	System      string
+	ExternalID  string
+	Comment     string
+	Data        map[string]any // Optional payload from the integration, merged into the event data
+}
+
+// ResolveExternal returns every task of the workspace waiting on the reference to
+// IN_PROGRESS with its assignee, recording a system event on each. It is meant for
+// operators and integrations (webhooks from CI, ticket trackers) that know the
+// external item but not the tasks waiting on it.
+// Returns ErrExternalRefNotFound if no task waits on the reference.
+func (s *TaskService) ResolveExternal(ctx context.Context, params ResolveExternalParams) ([]*domain.TaskEvent, error) {
+	if params.Comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+
+	if err := validateEventData(params.Data); err != nil {
+		return nil, err
+	}
+
+	ref, err := domain.NormalizeExternalRef(domain.ExternalRef{System: params.System, ID: params.ExternalID})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	tasks, err := s.taskRepo.FindAwaitingExternalForUpdate(ctx, tx, params.WorkspaceID, ref.System, ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", domain.ErrExternalRefNotFound, ref.System, ref.ID)
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, params.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get workspace: %w", err)
+	}
+
+	data := externalRefData(ref)
+	maps.Copy(data, params.Data)
+
+	oldStatus := domain.TaskStatusAwaitingExternal
+	newStatus := domain.TaskStatusInProgress
+	events := make([]*domain.TaskEvent, 0, len(tasks))
+	for _, task := range tasks {
+		err := s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+			oldStatus, newStatus,
+			task.AssigneeID, CalculateDeadline(workspace, newStatus), nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.taskRepo.SetExternalRef(ctx, tx, task.ID, nil); err != nil {
+			return nil, err
+		}
+
+		event := &domain.TaskEvent{
+			TaskID:    task.ID,
+			ActorID:   nil, // system event
+			Type:      domain.EventTypeExternalResolved,
+			OldStatus: &oldStatus,
+			NewStatus: &newStatus,
+			Comment:   params.Comment,
+			Data:      data,
+		}
+		if err := s.createEvent(ctx, tx, event); err != nil {
+			return nil, fmt.Errorf("create event for task %s: %w", task.ID, err)
+		}
+		events = append(events, event)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("external reference resolved",
+		"workspace_id", params.WorkspaceID,
+		"external_system", ref.System,
+		"external_id", ref.ID,
+		"tasks_resumed", len(events),
+	)
+
+	return events, nil
+}
+
+// externalRefData builds the event payload describing an external reference.
+func externalRefData(ref domain.ExternalRef) map[string]any {
+	data := map[string]any{
+		"external_system": ref.System,
+		"external_id":     ref.ID,
+	}
+	if ref.URL != nil {
+		data["external_url"] = *ref.URL
+	}
+	return data
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// Import formats accepted by ParseImport.
+const (
+	ImportFormatCSV    = "csv"    // header row, then one task per row
+	ImportFormatJSON   = "json"   // an export document or an array of tasks
+	ImportFormatNDJSON = "ndjson" // an NDJSON export; only task records are read
+)
+
+// MaxImportRows bounds a single import, which runs in one transaction.
+const MaxImportRows = 1000
+
+// importCSVColumns are the recognized CSV header names. Title and description are required.
+var importCSVColumns = map[string]bool{
+	"title": true, "description": true, "priority": true, "labels": true,
+	"visibility": true, "queue": true, "required_capabilities": true,
+}
+
+// ImportRow is one task to import.
+type ImportRow struct {
+	Row                  int      `json:"-"` // line for CSV and NDJSON, 1-based index for JSON
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	Priority             string   `json:"priority"`
+	Visibility           string   `json:"visibility"`
+	Queue                *string  `json:"queue"`
+	Labels               []string `json:"labels"`
+	RequiredCapabilities []string `json:"required_capabilities"`
+}
+
+// ImportRowError is a row that failed validation.
+type ImportRowError struct {
+	Row     int
+	Message string
+}
+
+// ImportTasksParams holds parameters for importing tasks.
+type ImportTasksParams struct {
+	WorkspaceID string
+	CreatorID   string
+	Rows        []ImportRow
+	DryRun      bool // validate every row without creating tasks
+}
+
+// ImportResult reports the outcome of an import. Tasks is empty unless every row
+// was valid and the import was not a dry run.
+type ImportResult struct {
+	Valid  int // rows that passed validation
+	Tasks  []*domain.Task
+	Errors []ImportRowError
+}
+
+// ParseImport reads import rows in the given format. Exported tasks keep their
+// title, description, priority, visibility, queue, labels and required
+// capabilities; status, assignee and blockers are not carried over.
+func ParseImport(r io.Reader, format string) ([]ImportRow, error) {
+	var rows []ImportRow
+	var err error
+
+	switch format {
+	case ImportFormatCSV:
+		rows, err = parseImportCSV(r)
+	case ImportFormatJSON:
+		rows, err = parseImportJSON(r)
+	case ImportFormatNDJSON:
+		rows, err = parseImportNDJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: format must be csv, json or ndjson", domain.ErrValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no tasks to import", domain.ErrValidation)
+	}
+	if len(rows) > MaxImportRows {
+		return nil, fmt.Errorf("%w: at most %d tasks can be imported at once, got %d", domain.ErrValidation, MaxImportRows, len(rows))
+	}
+
+	return rows, nil
+}
+
+// parseImportCSV reads a CSV file with a header row. Labels and required
+// capabilities are separated by ';' or ',' within their cell.
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: read csv header: %v", domain.ErrValidation, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !importCSVColumns[name] {
+			return nil, fmt.Errorf("%w: unknown csv column %q", domain.ErrValidation, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"title", "description"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: csv header must include %q", domain.ErrValidation, name)
+		}
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: read csv: %v", domain.ErrValidation, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := ImportRow{
+			Row:                  line,
+			Title:                cell("title"),
+			Description:          cell("description"),
+			Priority:             cell("priority"),
+			Visibility:           cell("visibility"),
+			Labels:               splitImportList(cell("labels")),
+			RequiredCapabilities: splitImportList(cell("required_capabilities")),
+		}
+		if queue := cell("queue"); queue != "" {
+			row.Queue = &queue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseImportJSON reads an export document ({"tasks": [...]}) or a bare array of tasks.
+func parseImportJSON(r io.Reader) ([]ImportRow, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: invalid json: %v", domain.ErrValidation, err)
+	}
+
+	var rows []ImportRow
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("%w: invalid task array: %v", domain.ErrValidation, err)
+		}
+	} else {
+		var document struct {
+			Tasks []ImportRow `json:"tasks"`
+		}
+		if err := json.Unmarshal(raw, &document); err != nil {
+			return nil, fmt.Errorf("%w: invalid export document: %v", domain.ErrValidation, err)
+		}
+		rows = document.Tasks
+	}
+
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+
+	return rows, nil
+}
+
+// parseImportNDJSON reads the task records of an NDJSON export and skips the rest.
+func parseImportNDJSON(r io.Reader) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	var rows []ImportRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid json: %v", domain.ErrValidation, line, err)
+		}
+		if record.Type != "task" {
+			continue
+		}
+
+		var row ImportRow
+		if err := json.Unmarshal(record.Data, &row); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid task: %v", domain.ErrValidation, line, err)
+		}
+		row.Row = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: read ndjson: %v", domain.ErrValidation, err)
+	}
+
+	return rows, nil
+}
+
+// splitImportList splits a CSV cell holding several names.
+func splitImportList(cell string) []string {
+	return strings.FieldsFunc(cell, func(r rune) bool {
+		return r == ';' || r == ','
+	})
+}
+
+// ImportTasks creates one task per row as the creator, all or nothing: every row
+// is validated, and tasks are only committed when no row failed and DryRun is
+// false. Row failures are reported in the result rather than returned as errors.
+func (s *TaskService) ImportTasks(ctx context.Context, params ImportTasksParams) (*ImportResult, error) {
+	creator, err := s.getActiveAgent(ctx, params.CreatorID)
+	if err != nil {
+		return nil, fmt.Errorf("validate creator: %w", err)
+	}
+	if creator.WorkspaceID != params.WorkspaceID {
+		return nil, fmt.Errorf("%w: creator must be in the workspace", domain.ErrPermissionDenied)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	result := &ImportResult{}
+	var tasks []*domain.Task
+	for _, row := range params.Rows {
+		createParams, err := importCreateParams(params, row)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportRowError{Row: row.Row, Message: err.Error()})
+			continue
+		}
+
+		// A savepoint per row keeps one failed insert from aborting the transaction
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("begin savepoint: %w", err)
+		}
+		task, err := s.createTaskInTx(ctx, savepoint, createParams)
+		if err != nil {
+			if rbErr := savepoint.Rollback(ctx); rbErr != nil {
+				return nil, fmt.Errorf("rollback savepoint: %w", rbErr)
+			}
+			result.Errors = append(result.Errors, ImportRowError{Row: row.Row, Message: err.Error()})
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("release savepoint: %w", err)
+		}
+
+		result.Valid++
+		tasks = append(tasks, task)
+	}
+
+	if len(result.Errors) > 0 || params.DryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	result.Tasks = tasks
+
+	slog.Info("tasks imported",
+		"workspace_id", params.WorkspaceID,
+		"creator_id", params.CreatorID,
+		"count", len(tasks),
+	)
+
+	return result, nil
+}
+
+// importCreateParams validates a row's fields and applies the defaults of POST /tasks.
+func importCreateParams(params ImportTasksParams, row ImportRow) (CreateTaskParams, error) {
+	title := strings.TrimSpace(row.Title)
+	if len(title) < 5 || len(title) > 200 {
+		return CreateTaskParams{}, fmt.Errorf("title must be between 5 and 200 characters")
+	}
+	if strings.TrimSpace(row.Description) == "" {
+		return CreateTaskParams{}, fmt.Errorf("description is required")
+	}
+
+	priority := domain.TaskPriorityNormal
+	if row.Priority != "" {
+		priority = domain.TaskPriority(strings.ToLower(row.Priority))
+		if !priority.IsValid() {
+			return CreateTaskParams{}, fmt.Errorf("priority must be 'low', 'normal', 'high', or 'critical'")
+		}
+	}
+
+	visibility := domain.TaskVisibilityPublic
+	if row.Visibility != "" {
+		visibility = domain.TaskVisibility(strings.ToLower(row.Visibility))
+		if !visibility.IsValid() {
+			return CreateTaskParams{}, fmt.Errorf("visibility must be 'public' or 'private'")
+		}
+	}
+
+	return CreateTaskParams{
+		WorkspaceID:          params.WorkspaceID,
+		CreatorID:            params.CreatorID,
+		Title:                title,
+		Description:          row.Description,
+		Visibility:           visibility,
+		Priority:             priority,
+		RequiredCapabilities: row.RequiredCapabilities,
+		Queue:                row.Queue,
+		Labels:               row.Labels,
+	}, nil
+}
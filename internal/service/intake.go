@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// intakeSource marks the created events of tasks submitted through an intake form.
+const intakeSource = "intake"
+
+// IntakeService manages workspace intake forms and turns submissions into tasks.
+type IntakeService struct {
+	pool          *pgxpool.Pool
+	intakeRepo    *repository.IntakeRepository
+	labelRepo     repository.LabelRepository
+	workspaceRepo repository.WorkspaceRepository
+	taskService   *TaskService
+}
+
+// NewIntakeService creates a new IntakeService. Tasks are created through taskService.
+func NewIntakeService(
+	pool *pgxpool.Pool,
+	intakeRepo *repository.IntakeRepository,
+	labelRepo repository.LabelRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	taskService *TaskService,
+) *IntakeService {
+	return &IntakeService{
+		pool:          pool,
+		intakeRepo:    intakeRepo,
+		labelRepo:     labelRepo,
+		workspaceRepo: workspaceRepo,
+		taskService:   taskService,
+	}
+}
+
+// ConfigureIntakeParams holds the settings of a workspace's intake form.
+type ConfigureIntakeParams struct {
+	WorkspaceID      string
+	AgentID          string // recorded as the creator of submitted tasks
+	RateLimitPerHour int    // 0 means the default
+	RotateKey        bool   // issue a new key even if the form already has one
+}
+
+// ConfigureIntake enables or updates the intake form of a workspace. A key is
+// issued when the form is created or RotateKey is set; the plaintext is returned
+// only then and is empty otherwise.
+func (s *IntakeService) ConfigureIntake(ctx context.Context, params ConfigureIntakeParams) (*domain.IntakeForm, string, error) {
+	rateLimit := params.RateLimitPerHour
+	if rateLimit == 0 {
+		rateLimit = domain.DefaultIntakeRateLimit
+	}
+	if rateLimit < 1 || rateLimit > domain.MaxIntakeRateLimit {
+		return nil, "", fmt.Errorf("%w: rate_limit_per_hour must be between 1 and %d", domain.ErrValidation, domain.MaxIntakeRateLimit)
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, params.WorkspaceID)
+	if err != nil {
+		return nil, "", err
+	}
+	if workspace.IsArchived() {
+		return nil, "", fmt.Errorf("%w: cannot enable intake for workspace %s", domain.ErrWorkspaceArchived, workspace.Slug)
+	}
+
+	agent, err := s.taskService.agentRepo.GetByID(ctx, params.AgentID)
+	if err != nil || agent.WorkspaceID != workspace.ID {
+		return nil, "", fmt.Errorf("%w: agent_id must be an agent of the workspace", domain.ErrValidation)
+	}
+	if !agent.IsActive {
+		return nil, "", fmt.Errorf("%w: agent %s is inactive", domain.ErrValidation, agent.ID)
+	}
+
+	form := &domain.IntakeForm{
+		WorkspaceID:      workspace.ID,
+		AgentID:          agent.ID,
+		RateLimitPerHour: rateLimit,
+	}
+
+	var key string
+	existing, err := s.intakeRepo.Get(ctx, workspace.ID)
+	switch {
+	case err == nil && !params.RotateKey:
+		form.KeyHash = existing.KeyHash
+	case err == nil || errors.Is(err, domain.ErrIntakeNotFound):
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, "", fmt.Errorf("generate intake key: %w", err)
+		}
+		key = domain.IntakeKeyPrefix + hex.EncodeToString(secret)
+		form.KeyHash = domain.HashIntakeKey(key)
+	default:
+		return nil, "", err
+	}
+
+	if err := s.intakeRepo.Upsert(ctx, form); err != nil {
+		return nil, "", err
+	}
+
+	slog.Info("intake form configured",
+		"workspace_id", workspace.ID,
+		"agent_id", agent.ID,
+		"rate_limit_per_hour", rateLimit,
+		"key_issued", key != "",
+	)
+
+	return form, key, nil
+}
+
+// GetIntake returns the intake form of a workspace.
+func (s *IntakeService) GetIntake(ctx context.Context, workspaceID string) (*domain.IntakeForm, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.intakeRepo.Get(ctx, workspaceID)
+}
+
+// DisableIntake removes the intake form of a workspace; its key stops working.
+func (s *IntakeService) DisableIntake(ctx context.Context, workspaceID string) error {
+	if err := s.intakeRepo.Delete(ctx, workspaceID); err != nil {
+		return err
+	}
+	slog.Info("intake form disabled", "workspace_id", workspaceID)
+	return nil
+}
+
+// SubmitIntake creates a NEW public task labelled "intake" from a submission to
+// the intake form of the workspace with the given slug. Unknown and archived
+// workspaces and workspaces without a form all report ErrIntakeNotFound.
+func (s *IntakeService) SubmitIntake(ctx context.Context, slug, key string, submission domain.IntakeSubmission) (*domain.Task, error) {
+	workspace, err := s.workspaceRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return nil, domain.ErrIntakeNotFound
+		}
+		return nil, err
+	}
+	if workspace.IsArchived() {
+		return nil, domain.ErrIntakeNotFound
+	}
+
+	form, err := s.intakeRepo.Get(ctx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(domain.HashIntakeKey(key)), []byte(form.KeyHash)) != 1 {
+		return nil, domain.ErrInvalidIntakeKey
+	}
+
+	submission.Title = strings.TrimSpace(submission.Title)
+	submission.Description = strings.TrimSpace(submission.Description)
+	submission.Contact = strings.TrimSpace(submission.Contact)
+	// Characters, not bytes, as the tasks table counts them
+	if titleLength := utf8.RuneCountInString(submission.Title); titleLength < 5 || titleLength > 200 {
+		return nil, fmt.Errorf("%w: title must be between 5 and 200 characters", domain.ErrValidation)
+	}
+	if submission.Description == "" || utf8.RuneCountInString(submission.Description) > domain.MaxIntakeDescriptionLength {
+		return nil, fmt.Errorf("%w: description must be between 1 and %d characters", domain.ErrValidation, domain.MaxIntakeDescriptionLength)
+	}
+	if utf8.RuneCountInString(submission.Contact) > domain.MaxIntakeContactLength {
+		return nil, fmt.Errorf("%w: contact must be at most %d characters", domain.ErrValidation, domain.MaxIntakeContactLength)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	form, err = s.intakeRepo.GetForUpdate(ctx, tx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted, err := s.intakeRepo.CountSubmissionsSince(ctx, tx, workspace.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if submitted >= form.RateLimitPerHour {
+		return nil, fmt.Errorf("%w: the workspace accepts %d submissions per hour", domain.ErrIntakeRateLimited, form.RateLimitPerHour)
+	}
+
+	// Registering is a no-op once the label exists, and brings it back if it was deleted
+	if err := s.labelRepo.Register(ctx, tx, workspace.ID, domain.IntakeLabel); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.createTaskInTx(ctx, tx, CreateTaskParams{
+		WorkspaceID: workspace.ID,
+		CreatorID:   form.AgentID,
+		Title:       submission.Title,
+		Description: submission.Description,
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityNormal,
+		Labels:      []string{domain.IntakeLabel},
+		Intake:      &submission,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrAgentInactive) || errors.Is(err, domain.ErrAgentNotFound) {
+			return nil, fmt.Errorf("%w: its agent is no longer active", domain.ErrIntakeNotFound)
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("intake submission accepted",
+		"task_id", task.ID,
+		"workspace_id", workspace.ID,
+	)
+
+	return task, nil
+}
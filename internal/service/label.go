@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// maxLabelDescriptionLength limits label descriptions.
+const maxLabelDescriptionLength = 1000
+
+// LabelService manages the label registry of a workspace.
+type LabelService struct {
+	pool      *pgxpool.Pool
+	labelRepo repository.LabelRepository
+}
+
+// NewLabelService creates a new LabelService.
+func NewLabelService(pool *pgxpool.Pool, labelRepo repository.LabelRepository) *LabelService {
+	return &LabelService{
+		pool:      pool,
+		labelRepo: labelRepo,
+	}
+}
+
+// UpdateLabelParams holds the changes for UpdateLabel. Nil fields are left unchanged.
+type UpdateLabelParams struct {
+	WorkspaceID string
+	Name        string
+	NewName     *string
+	Color       *string
+	Description *string
+}
+
+// PutLabel registers a label, or updates the color and description of an existing one.
+// Nil fields keep their current value, so agents can safely "ensure" a label exists.
+// Reports whether the label was created.
+func (s *LabelService) PutLabel(ctx context.Context, workspaceID, name string, color, description *string) (*domain.Label, bool, error) {
+	name, err := domain.NormalizeLabelName(name)
+	if err != nil {
+		return nil, false, err
+	}
+	color, description, err = normalizeLabelFields(color, description)
+	if err != nil {
+		return nil, false, err
+	}
+
+	label, created, err := s.labelRepo.Upsert(ctx, workspaceID, name, color, description)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		slog.Info("label created",
+			"workspace_id", workspaceID,
+			"label", name,
+		)
+	}
+
+	return label, created, nil
+}
+
+// GetLabel returns a label of the workspace with its usage count.
+func (s *LabelService) GetLabel(ctx context.Context, workspaceID, name string) (*domain.Label, error) {
+	name, err := domain.NormalizeLabelName(name)
+	if err != nil {
+		return nil, domain.ErrLabelNotFound
+	}
+	return s.labelRepo.GetByName(ctx, workspaceID, name)
+}
+
+// ListLabels returns all labels of a workspace with usage counts.
+func (s *LabelService) ListLabels(ctx context.Context, workspaceID string) ([]*domain.Label, error) {
+	return s.labelRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+// UpdateLabel renames a label and/or changes its color and description.
+// A rename rewrites the labels of every task carrying it in the same transaction.
+func (s *LabelService) UpdateLabel(ctx context.Context, params UpdateLabelParams) (*domain.Label, error) {
+	name, err := domain.NormalizeLabelName(params.Name)
+	if err != nil {
+		return nil, domain.ErrLabelNotFound
+	}
+
+	var newName *string
+	if params.NewName != nil {
+		normalized, err := domain.NormalizeLabelName(*params.NewName)
+		if err != nil {
+			return nil, err
+		}
+		if normalized != name {
+			newName = &normalized
+		}
+	}
+
+	color, description, err := normalizeLabelFields(params.Color, params.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	if newName == nil && color == nil && description == nil {
+		return s.labelRepo.GetByName(ctx, params.WorkspaceID, name)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if _, err := s.labelRepo.LockByName(ctx, tx, params.WorkspaceID, name); err != nil {
+		return nil, err
+	}
+	if err := s.labelRepo.Update(ctx, tx, params.WorkspaceID, name, newName, color, description); err != nil {
+		return nil, err
+	}
+	var rewritten int64
+	if newName != nil {
+		if rewritten, err = s.labelRepo.RewriteTaskLabels(ctx, tx, params.WorkspaceID, name, newName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	current := name
+	if newName != nil {
+		current = *newName
+		slog.Info("label renamed",
+			"workspace_id", params.WorkspaceID,
+			"label", name,
+			"new_name", current,
+			"tasks_updated", rewritten,
+		)
+	}
+
+	return s.labelRepo.GetByName(ctx, params.WorkspaceID, current)
+}
+
+// MergeLabel folds label from into label into: every task carrying from carries into
+// instead, and from is removed from the registry, all in one transaction.
+func (s *LabelService) MergeLabel(ctx context.Context, workspaceID, from, into string) (*domain.Label, error) {
+	from, err := domain.NormalizeLabelName(from)
+	if err != nil {
+		return nil, domain.ErrLabelNotFound
+	}
+	into, err = domain.NormalizeLabelName(into)
+	if err != nil {
+		return nil, err
+	}
+	if from == into {
+		return nil, fmt.Errorf("%w: cannot merge label %s into itself", domain.ErrValidation, from)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	// Lock in name order so concurrent merges of the same pair cannot deadlock
+	for _, name := range slices.Sorted(slices.Values([]string{from, into})) {
+		if _, err := s.labelRepo.LockByName(ctx, tx, workspaceID, name); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+	}
+	rewritten, err := s.labelRepo.RewriteTaskLabels(ctx, tx, workspaceID, from, &into)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.labelRepo.Delete(ctx, tx, workspaceID, from); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("label merged",
+		"workspace_id", workspaceID,
+		"label", from,
+		"into", into,
+		"tasks_updated", rewritten,
+	)
+
+	return s.labelRepo.GetByName(ctx, workspaceID, into)
+}
+
+// DeleteLabel removes a label from the registry and from every task carrying it.
+func (s *LabelService) DeleteLabel(ctx context.Context, workspaceID, name string) error {
+	name, err := domain.NormalizeLabelName(name)
+	if err != nil {
+		return domain.ErrLabelNotFound
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if _, err := s.labelRepo.LockByName(ctx, tx, workspaceID, name); err != nil {
+		return err
+	}
+	rewritten, err := s.labelRepo.RewriteTaskLabels(ctx, tx, workspaceID, name, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.labelRepo.Delete(ctx, tx, workspaceID, name); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("label deleted",
+		"workspace_id", workspaceID,
+		"label", name,
+		"tasks_updated", rewritten,
+	)
+
+	return nil
+}
+
+// normalizeLabelFields validates the optional color and description of a label.
+func normalizeLabelFields(color, description *string) (*string, *string, error) {
+	if color != nil {
+		normalized, err := domain.NormalizeLabelColor(*color)
+		if err != nil {
+			return nil, nil, err
+		}
+		color = &normalized
+	}
+	if description != nil {
+		trimmed := strings.TrimSpace(*description)
+		if len(trimmed) > maxLabelDescriptionLength {
+			return nil, nil, fmt.Errorf("%w: description must be at most %d characters", domain.ErrValidation, maxLabelDescriptionLength)
+		}
+		description = &trimmed
+	}
+	return color, description, nil
+}
+
+// resolveLabels normalizes task labels and checks that all are registered in the workspace,
+// share-locking them until tx ends.
+func (s *TaskService) resolveLabels(ctx context.Context, tx pgx.Tx, workspaceID string, labels []string) ([]string, error) {
+	labels, err := domain.NormalizeLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	missing, err := s.labelRepo.LockRegistered(ctx, tx, workspaceID, labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s (register with PUT /api/v1/labels/{name})", domain.ErrUnknownLabel, strings.Join(missing, ", "))
+	}
+	return labels, nil
+}
+
+// SetTaskLabelsParams holds parameters for replacing the labels of a task.
+type SetTaskLabelsParams struct {
+	TaskID  string
+	AgentID string
+	Labels  []string
+	Comment string // Optional
+}
+
+// SetTaskLabels replaces the labels of a task. Allowed for its creator and assignee.
+// Returns the labels_changed event, or nil if the labels were already as requested.
+func (s *TaskService) SetTaskLabels(ctx context.Context, params SetTaskLabelsParams) ([]string, *domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if task.WorkspaceID != agent.WorkspaceID || (!task.IsCreatedBy(agent.ID) && !task.IsOwnedBy(agent.ID)) {
+		return nil, nil, fmt.Errorf("%w: agent %s is neither creator nor assignee of task %s", domain.ErrPermissionDenied, agent.ID, task.ID)
+	}
+
+	labels, err := s.resolveLabels(ctx, tx, task.WorkspaceID, params.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added, removed := diffLabels(task.Labels, labels)
+	if len(added) == 0 && len(removed) == 0 {
+		return labels, nil, nil
+	}
+
+	if err := s.taskRepo.SetLabels(ctx, tx, task.ID, labels); err != nil {
+		return nil, nil, err
+	}
+
+	comment := strings.TrimSpace(params.Comment)
+	if comment == "" {
+		comment = "Labels changed"
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: &agent.ID,
+		Type:    domain.EventTypeLabelsChanged,
+		Comment: comment,
+		Data:    map[string]any{"added": added, "removed": removed},
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("task labels changed",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"labels", labels,
+	)
+
+	return labels, event, nil
+}
+
+// diffLabels returns the labels in next but not in prev, and in prev but not in next.
+func diffLabels(prev, next []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, label := range next {
+		if !slices.Contains(prev, label) {
+			added = append(added, label)
+		}
+	}
+	for _, label := range prev {
+		if !slices.Contains(next, label) {
+			removed = append(removed, label)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// maxLineageDepth bounds how far lineage traversal follows relations in each direction.
+const maxLineageDepth = 20
+
+// GetLineage builds the ancestry and descendant trees of a task.
+// Ancestors are tasks the task depends on (transitively via blocked_by);
+// descendants are tasks that depend on it. Tasks the agent cannot view are omitted,
+// and each task appears at most once per direction.
+func (s *TaskService) GetLineage(ctx context.Context, taskID, agentID string) (*domain.TaskLineage, error) {
+	agent, err := s.getActiveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !task.IsVisibleTo(agent) {
+		return nil, fmt.Errorf("%w: task %s is not visible to agent %s", domain.ErrPermissionDenied, taskID, agentID)
+	}
+
+	ancestors, err := s.expandLineage(ctx, task, agent, domain.LineageRelationBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("expand ancestors: %w", err)
+	}
+
+	descendants, err := s.expandLineage(ctx, task, agent, domain.LineageRelationBlockedBy)
+	if err != nil {
+		return nil, fmt.Errorf("expand descendants: %w", err)
+	}
+
+	return &domain.TaskLineage{
+		Task:        task,
+		Ancestors:   ancestors,
+		Descendants: descendants,
+	}, nil
+}
+
+// expandLineage walks the dependency graph breadth-first in one direction,
+// loading each level with a single query.
+func (s *TaskService) expandLineage(
+	ctx context.Context,
+	root *domain.Task,
+	agent *domain.Agent,
+	relation domain.LineageRelation,
+) ([]*domain.LineageNode, error) {
+	visited := map[string]bool{root.ID: true}
+	rootNode := &domain.LineageNode{Task: root}
+	level := []*domain.LineageNode{rootNode}
+
+	for depth := 0; depth < maxLineageDepth && len(level) > 0; depth++ {
+		related, err := s.loadRelated(ctx, level, relation)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*domain.LineageNode
+		for _, parent := range level {
+			for _, candidate := range related {
+				if visited[candidate.ID] || !isRelated(parent.Task, candidate, relation) {
+					continue
+				}
+				if !candidate.IsVisibleTo(agent) {
+					continue
+				}
+				visited[candidate.ID] = true
+				child := &domain.LineageNode{Task: candidate, Relation: relation}
+				parent.Children = append(parent.Children, child)
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+
+	return rootNode.Children, nil
+}
+
+// loadRelated fetches all tasks related to any task in the level.
+func (s *TaskService) loadRelated(ctx context.Context, level []*domain.LineageNode, relation domain.LineageRelation) ([]*domain.Task, error) {
+	if relation == domain.LineageRelationBlocks {
+		var blockerIDs []string
+		for _, node := range level {
+			blockerIDs = append(blockerIDs, node.Task.BlockedBy...)
+		}
+		return s.taskRepo.GetBlockedByTasks(ctx, blockerIDs)
+	}
+
+	ids := make([]string, len(level))
+	for i, node := range level {
+		ids[i] = node.Task.ID
+	}
+	return s.taskRepo.GetDependentTasks(ctx, ids)
+}
+
+// isRelated reports whether candidate is related to parent in the given direction.
+func isRelated(parent, candidate *domain.Task, relation domain.LineageRelation) bool {
+	if relation == domain.LineageRelationBlocks {
+		return slices.Contains(parent.BlockedBy, candidate.ID)
+	}
+	return slices.Contains(candidate.BlockedBy, parent.ID)
+}
@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/testutil/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgentRepo serves agents from memory.
+type fakeAgentRepo struct {
+	repository.AgentRepository
+	agents map[string]*domain.Agent
+}
+
+func (r *fakeAgentRepo) GetByID(_ context.Context, agentID string) (*domain.Agent, error) {
+	agent, ok := r.agents[agentID]
+	if !ok {
+		return nil, domain.ErrAgentNotFound
+	}
+	return agent, nil
+}
+
+func TestGetLineage_WithoutDatabase(t *testing.T) {
+	ctx := context.Background()
+	task := func(id, creatorID string, visibility domain.TaskVisibility, blockedBy ...string) *domain.Task {
+		return &domain.Task{ID: id, WorkspaceID: "ws", CreatorID: creatorID, Visibility: visibility, BlockedBy: blockedBy}
+	}
+	tasks := &fake.TaskRepository{Tasks: map[string]*domain.Task{
+		"design": task("design", "agent", domain.TaskVisibilityPublic),
+		"build":  task("build", "agent", domain.TaskVisibilityPublic, "design"),
+		"ship":   task("ship", "agent", domain.TaskVisibilityPublic, "build"),
+		"secret": task("secret", "other", domain.TaskVisibilityPrivate, "build"),
+	}}
+	agents := &fakeAgentRepo{agents: map[string]*domain.Agent{
+		"agent":   {ID: "agent", WorkspaceID: "ws", IsActive: true},
+		"retired": {ID: "retired", WorkspaceID: "ws"},
+	}}
+	svc := service.NewTaskService(nil, tasks, nil, agents, nil, nil, nil, nil, nil, nil)
+
+	lineage, err := svc.GetLineage(ctx, "build", "agent")
+	require.NoError(t, err)
+	require.Len(t, lineage.Ancestors, 1)
+	assert.Equal(t, "design", lineage.Ancestors[0].Task.ID)
+	require.Len(t, lineage.Descendants, 1, "private task of another agent is omitted")
+	assert.Equal(t, "ship", lineage.Descendants[0].Task.ID)
+
+	_, err = svc.GetLineage(ctx, "build", "retired")
+	assert.ErrorIs(t, err, domain.ErrAgentInactive)
+}
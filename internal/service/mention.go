@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// MentionsDataKey is the key of the comment event data listing the IDs of the
+// mentioned agents, so event webhooks can route the comment to them.
+const MentionsDataKey = "mentions"
+
+// resolveMentions returns the IDs of the agents a comment on task mentions as
+// @name, in order of first mention. Every name must be an active agent of the
+// task's workspace that can see the task. Authors mentioning themselves are
+// left out.
+func (s *TaskService) resolveMentions(ctx context.Context, task *domain.Task, author *domain.Agent, comment string) ([]string, error) {
+	names := domain.ParseMentions(comment)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	agents, err := s.agentRepo.ListByWorkspace(ctx, task.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("list workspace agents: %w", err)
+	}
+	byName := make(map[string]*domain.Agent, len(agents))
+	for _, agent := range agents {
+		byName[agent.Name] = agent
+	}
+
+	ids := []string{}
+	unknown := []string{}
+	hidden := []string{}
+	for _, name := range names {
+		agent, ok := byName[name]
+		switch {
+		case !ok || !agent.IsActive:
+			unknown = append(unknown, name)
+		case agent.ID == author.ID:
+		case !task.IsVisibleTo(agent):
+			hidden = append(hidden, name)
+		default:
+			ids = append(ids, agent.ID)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, domain.WithDetails(
+			fmt.Errorf("%w: no active agent named @%s in this workspace", domain.ErrValidation, strings.Join(unknown, ", @")),
+			map[string]any{"unknown_mentions": unknown},
+		)
+	}
+	if len(hidden) > 0 {
+		return nil, domain.WithDetails(
+			fmt.Errorf("%w: @%s cannot see this private task", domain.ErrValidation, strings.Join(hidden, ", @")),
+			map[string]any{"hidden_mentions": hidden},
+		)
+	}
+	return ids, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// MessageService manages direct messages between agents about tasks.
+type MessageService struct {
+	messageRepo *repository.MessageRepository
+	taskRepo    repository.TaskRepository
+	agentRepo   repository.AgentRepository
+}
+
+// NewMessageService creates a new MessageService.
+func NewMessageService(
+	messageRepo *repository.MessageRepository,
+	taskRepo repository.TaskRepository,
+	agentRepo repository.AgentRepository,
+) *MessageService {
+	return &MessageService{
+		messageRepo: messageRepo,
+		taskRepo:    taskRepo,
+		agentRepo:   agentRepo,
+	}
+}
+
+// SendMessageParams holds parameters for sending a direct message.
+type SendMessageParams struct {
+	TaskID      string
+	SenderID    string
+	RecipientID string
+	Body        string
+}
+
+// SendMessage sends a direct message about a task. Both agents must be active
+// and able to see the task; agents cannot message themselves.
+func (s *MessageService) SendMessage(ctx context.Context, params SendMessageParams) (*domain.TaskMessage, error) {
+	body := strings.TrimSpace(params.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: body is required", domain.ErrValidation)
+	}
+	if utf8.RuneCountInString(body) > domain.MaxMessageBodyLength {
+		return nil, fmt.Errorf("%w: body must be at most %d characters", domain.ErrValidation, domain.MaxMessageBodyLength)
+	}
+	if params.RecipientID == params.SenderID {
+		return nil, fmt.Errorf("%w: cannot message yourself", domain.ErrValidation)
+	}
+
+	sender, err := s.agentRepo.GetByID(ctx, params.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	if !sender.IsActive {
+		return nil, domain.ErrAgentInactive
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsVisibleTo(sender) {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	recipient, err := s.agentRepo.GetByID(ctx, params.RecipientID)
+	if errors.Is(err, domain.ErrAgentNotFound) || (err == nil && recipient.WorkspaceID != sender.WorkspaceID) {
+		return nil, fmt.Errorf("%w: recipient_id must be an agent of your workspace", domain.ErrValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !recipient.IsActive {
+		return nil, fmt.Errorf("%w: recipient is inactive", domain.ErrValidation)
+	}
+	if !task.IsVisibleTo(recipient) {
+		return nil, fmt.Errorf("%w: recipient cannot see this private task", domain.ErrValidation)
+	}
+
+	message := &domain.TaskMessage{
+		TaskID:      task.ID,
+		SenderID:    sender.ID,
+		RecipientID: recipient.ID,
+		Body:        body,
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	slog.Info("message sent",
+		"message_id", message.ID,
+		"task_id", task.ID,
+		"sender_id", sender.ID,
+		"recipient_id", recipient.ID,
+	)
+
+	return message, nil
+}
+
+// ListMessages returns the messages an agent sent or received, newest first,
+// with their total count.
+func (s *MessageService) ListMessages(ctx context.Context, filters repository.MessageFilters) ([]*domain.TaskMessage, int, error) {
+	return s.messageRepo.List(ctx, filters)
+}
+
+// CountUnread counts the messages to an agent it has not acknowledged yet.
+func (s *MessageService) CountUnread(ctx context.Context, agentID string) (int, error) {
+	return s.messageRepo.CountUnread(ctx, agentID)
+}
+
+// AcknowledgeMessage marks a message to the agent as read.
+func (s *MessageService) AcknowledgeMessage(ctx context.Context, messageID, agentID string) (*domain.TaskMessage, error) {
+	message, err := s.messageRepo.MarkRead(ctx, messageID, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("message acknowledged", "message_id", messageID, "agent_id", agentID)
+
+	return message, nil
+}
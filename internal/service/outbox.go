@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/pkg/client"
+)
+
+// HeaderEventType carries the type of the task event in event webhook deliveries.
+const HeaderEventType = "X-Sloptask-Event"
+
+// SetEventWebhook sets the webhook receiving a workspace's task events, or stops
+// publishing them when hook is nil. No event types means all of them.
+func (s *TaskService) SetEventWebhook(ctx context.Context, workspaceID string, hook *domain.EventWebhook) (*domain.EventWebhook, error) {
+	if hook != nil {
+		target, err := normalizeReportTarget(domain.ReportTargetWebhook, hook.URL)
+		if err != nil {
+			return nil, err
+		}
+		hook.URL = target
+
+		eventTypes := []domain.EventType{}
+		for _, eventType := range hook.EventTypes {
+			if !eventType.IsValid() {
+				return nil, fmt.Errorf("%w: unknown event type %q", domain.ErrValidation, eventType)
+			}
+			if !slices.Contains(eventTypes, eventType) {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+		slices.Sort(eventTypes)
+		hook.EventTypes = eventTypes
+	}
+
+	if err := s.workspaceRepo.SetEventWebhook(ctx, workspaceID, hook); err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace event webhook updated", "workspace_id", workspaceID, "enabled", hook != nil)
+
+	return hook, nil
+}
+
+// OutboxService publishes task events queued in the outbox to event webhooks and
+// broker topics and lets operators inspect and retry deliveries.
+type OutboxService struct {
+	pool       *pgxpool.Pool
+	outboxRepo *repository.OutboxRepository
+	secretRepo repository.WebhookSecretRepository
+	delivery   ReportDeliveryConfig
+	broker     EventBroker
+}
+
+// NewOutboxService creates a new OutboxService. The delivery configuration and
+// broker are only used by PublishPending; without a broker, messages to broker
+// topics stay pending.
+func NewOutboxService(
+	pool *pgxpool.Pool,
+	outboxRepo *repository.OutboxRepository,
+	secretRepo repository.WebhookSecretRepository,
+	delivery ReportDeliveryConfig,
+	broker EventBroker,
+) *OutboxService {
+	return &OutboxService{
+		pool:       pool,
+		outboxRepo: outboxRepo,
+		secretRepo: secretRepo,
+		delivery:   delivery,
+		broker:     broker,
+	}
+}
+
+// ListMessages returns a workspace's outbox messages, newest first, optionally
+// only those with the given status.
+func (s *OutboxService) ListMessages(ctx context.Context, workspaceID string, status *domain.OutboxStatus, limit, offset int) ([]*domain.OutboxMessage, int, error) {
+	return s.outboxRepo.List(ctx, workspaceID, status, limit, offset)
+}
+
+// RetryMessage makes a failed message of a workspace pending again, due at once
+// and with a fresh set of attempts.
+func (s *OutboxService) RetryMessage(ctx context.Context, workspaceID, messageID string) (*domain.OutboxMessage, error) {
+	message, err := s.outboxRepo.GetByID(ctx, workspaceID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Status != domain.OutboxFailed {
+		return nil, fmt.Errorf("%w: only failed messages can be retried, this one is %s", domain.ErrValidation, message.Status)
+	}
+
+	if err := s.outboxRepo.Requeue(ctx, messageID); err != nil {
+		return nil, err
+	}
+
+	slog.Info("outbox message requeued", "message_id", messageID, "workspace_id", workspaceID)
+
+	return s.outboxRepo.GetByID(ctx, workspaceID, messageID)
+}
+
+// PublishPending delivers every outbox message that is due. Failed deliveries
+// are retried with growing delays and marked failed after
+// MaxOutboxDeliveryAttempts. Returns the number of messages delivered.
+func (s *OutboxService) PublishPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+	for {
+		delivered, ok, err := s.publishNextPending(ctx, now)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+		if delivered {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// publishNextPending delivers one due message while holding its row lock, so
+// concurrent schedulers never deliver it twice. A crash after the webhook
+// accepted the message but before the outcome commits delivers it again with
+// the same delivery ID. ok is false when none is due.
+func (s *OutboxService) publishNextPending(ctx context.Context, now time.Time) (delivered, ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	targetTypes := []domain.OutboxTargetType{domain.OutboxTargetWebhook}
+	if s.broker != nil {
+		targetTypes = append(targetTypes, domain.OutboxTargetBroker)
+	}
+
+	message, err := s.outboxRepo.LockNextPending(ctx, tx, now, targetTypes)
+	if err != nil {
+		if errors.Is(err, domain.ErrOutboxMessageNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	var deliveryErr *string
+	var nextAttemptAt *time.Time
+	if err := s.publish(ctx, message); err != nil {
+		text := err.Error()
+		deliveryErr = &text
+		if attempts := message.Attempts + 1; attempts < domain.MaxOutboxDeliveryAttempts {
+			next := now.Add(domain.OutboxRetryDelay(attempts))
+			nextAttemptAt = &next
+		}
+	}
+
+	if err := s.outboxRepo.RecordDelivery(ctx, tx, message.ID, now, deliveryErr, nextAttemptAt); err != nil {
+		return false, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if deliveryErr != nil {
+		slog.Warn("event delivery failed",
+			"message_id", message.ID,
+			"target_type", message.TargetType,
+			"task_id", message.TaskID,
+			"event_id", message.EventID,
+			"attempts", message.Attempts+1,
+			"gave_up", nextAttemptAt == nil,
+			"error", *deliveryErr,
+		)
+		return false, true, nil
+	}
+
+	slog.Info("event delivered",
+		"message_id", message.ID,
+		"target_type", message.TargetType,
+		"task_id", message.TaskID,
+		"event_id", message.EventID,
+	)
+
+	return true, true, nil
+}
+
+// publish sends a message's event to its webhook or broker topic as a
+// client.WebhookPayload. The message ID is the delivery ID, so receivers can
+// drop redeliveries.
+func (s *OutboxService) publish(ctx context.Context, message *domain.OutboxMessage) error {
+	event := message.Event
+	payload := client.WebhookPayload{
+		DeliveryID:  message.ID,
+		WorkspaceID: message.WorkspaceID,
+		Event: client.Event{
+			ID:        event.ID,
+			TaskID:    event.TaskID,
+			Type:      client.EventType(event.Type),
+			ActorID:   event.ActorID,
+			Comment:   event.Comment,
+			Data:      event.Data,
+			CreatedAt: event.CreatedAt,
+		},
+	}
+	if event.OldStatus != nil {
+		status := client.TaskStatus(*event.OldStatus)
+		payload.Event.OldStatus = &status
+	}
+	if event.NewStatus != nil {
+		status := client.TaskStatus(*event.NewStatus)
+		payload.Event.NewStatus = &status
+	}
+	if event.Trace != nil {
+		payload.Event.TraceParent = event.Trace.TraceParent
+		payload.Event.TraceState = event.Trace.TraceState
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	if message.TargetType == domain.OutboxTargetBroker {
+		return s.broker.Publish(ctx, BrokerMessage{
+			Topic:   message.Target,
+			Key:     message.TaskID,
+			ID:      message.ID,
+			Headers: map[string]string{HeaderEventType: string(event.Type)},
+			Body:    body,
+		})
+	}
+
+	return postWebhook(ctx, s.delivery, s.secretRepo, webhookDelivery{
+		WorkspaceID: message.WorkspaceID,
+		URL:         message.Target,
+		UserAgent:   "sloptask-events",
+		ContentType: "application/json",
+		DeliveryID:  message.ID,
+		Headers:     map[string]string{HeaderEventType: string(event.Type)},
+		Body:        body,
+	})
+}
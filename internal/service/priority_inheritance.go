@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// maxPriorityInheritanceSteps bounds one propagation through blocking chains,
+// so a dependency cycle cannot keep it going.
+const maxPriorityInheritanceSteps = 1000
+
+// SetPriorityInheritance turns priority inheritance on or off for a workspace and
+// brings every affected task in line: blockers of open high and critical tasks
+// inherit their priority when it is turned on and drop it when it is turned off.
+// Returns the number of tasks whose inherited priority changed.
+func (s *TaskService) SetPriorityInheritance(ctx context.Context, workspaceID string, enabled bool) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	if err := s.workspaceRepo.SetPriorityInheritance(ctx, tx, workspaceID, enabled); err != nil {
+		return 0, err
+	}
+
+	candidates, err := s.taskRepo.FindPriorityInheritanceCandidates(ctx, tx, workspaceID)
+	if err != nil {
+		return 0, fmt.Errorf("find priority inheritance candidates: %w", err)
+	}
+
+	changed, err := s.propagatePriorityInheritance(ctx, tx, candidates)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("workspace priority inheritance updated",
+		"workspace_id", workspaceID,
+		"enabled", enabled,
+		"tasks_updated", changed,
+	)
+
+	return changed, nil
+}
+
+// syncPriorityInheritance re-evaluates inherited priorities after a task opened,
+// closed or was deleted: the task itself and, transitively, the tasks blocking it.
+// It does nothing unless the task's workspace has priority inheritance on.
+func (s *TaskService) syncPriorityInheritance(ctx context.Context, tx pgx.Tx, taskID string) error {
+	state, err := s.taskRepo.GetPriorityInheritanceState(ctx, tx, taskID)
+	if err != nil {
+		return fmt.Errorf("get priority inheritance state: %w", err)
+	}
+	if !state.Enabled {
+		return nil
+	}
+
+	// The task's blockers are re-evaluated even if its own inherited priority
+	// stays: it may have just stopped (or started) being open.
+	_, err = s.propagatePriorityInheritance(ctx, tx, append([]string{taskID}, state.BlockedBy...))
+	return err
+}
+
+// propagatePriorityInheritance updates the inherited priority of the given tasks
+// and, whenever one changes, of the tasks blocking it in turn. Returns the number
+// of tasks changed.
+func (s *TaskService) propagatePriorityInheritance(ctx context.Context, tx pgx.Tx, taskIDs []string) (int, error) {
+	queue := taskIDs
+	changed := make(map[string]bool)
+
+	for steps := 0; len(queue) > 0; steps++ {
+		if steps == maxPriorityInheritanceSteps {
+			slog.Warn("priority inheritance stopped early; blocking chains may contain a cycle",
+				"pending", len(queue),
+			)
+			break
+		}
+
+		taskID := queue[0]
+		queue = queue[1:]
+
+		state, err := s.taskRepo.GetPriorityInheritanceState(ctx, tx, taskID)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			continue // a stale blocker reference
+		}
+		if err != nil {
+			return 0, fmt.Errorf("get priority inheritance state: %w", err)
+		}
+
+		updated, err := s.updateInheritedPriority(ctx, tx, state)
+		if err != nil {
+			return 0, err
+		}
+		if updated {
+			changed[taskID] = true
+			queue = append(queue, state.BlockedBy...)
+		}
+	}
+
+	return len(changed), nil
+}
+
+// updateInheritedPriority sets the task's inherited priority to the effective
+// priority of the most urgent open task it blocks, if that is higher than its own,
+// and records the bump or its reversal as an event. Closed tasks and tasks of
+// workspaces without priority inheritance inherit nothing. Reports whether the
+// inherited priority changed.
+func (s *TaskService) updateInheritedPriority(ctx context.Context, tx pgx.Tx, state *repository.PriorityInheritanceState) (bool, error) {
+	var inherited *domain.TaskPriority
+	var source *domain.Task
+
+	if state.Enabled && state.Open {
+		var err error
+		source, err = s.taskRepo.FindPriorityInheritanceSource(ctx, tx, state.TaskID)
+		switch {
+		case errors.Is(err, domain.ErrTaskNotFound):
+			source = nil
+		case err != nil:
+			return false, fmt.Errorf("find priority inheritance source: %w", err)
+		default:
+			if priority := source.EffectivePriority(); priority.Rank() < state.Priority.Rank() {
+				inherited = &priority
+			}
+		}
+	}
+
+	if equalPriority(inherited, state.InheritedPriority) {
+		return false, nil
+	}
+
+	if err := s.taskRepo.SetInheritedPriority(ctx, tx, state.TaskID, inherited); err != nil {
+		return false, fmt.Errorf("set inherited priority: %w", err)
+	}
+
+	event := &domain.TaskEvent{TaskID: state.TaskID}
+	if inherited != nil {
+		event.Type = domain.EventTypePriorityInherited
+		event.Comment = fmt.Sprintf("Priority raised to %s: blocks %s task %s", *inherited, *inherited, source.ID)
+		event.Data = map[string]any{
+			"priority":           state.Priority,
+			"inherited_priority": *inherited,
+			"from_task_id":       source.ID,
+		}
+	} else {
+		event.Type = domain.EventTypePriorityRestored
+		event.Comment = fmt.Sprintf("Inherited priority %s dropped: back to %s", *state.InheritedPriority, state.Priority)
+		event.Data = map[string]any{
+			"priority":           state.Priority,
+			"inherited_priority": *state.InheritedPriority,
+		}
+	}
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return false, fmt.Errorf("create %s event: %w", event.Type, err)
+	}
+
+	return true, nil
+}
+
+// equalPriority reports whether two optional priorities are the same.
+func equalPriority(a, b *domain.TaskPriority) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// maxQueueDescriptionLength limits queue descriptions.
+const maxQueueDescriptionLength = 1000
+
+// claimableRecheckInterval is how often WaitForClaimable looks for work without
+// being told of a change, e.g. after the agent gained a capability.
+const claimableRecheckInterval = 5 * time.Second
+
+// QueueService manages the named task queues of a workspace.
+type QueueService struct {
+	queueRepo repository.QueueRepository
+}
+
+// NewQueueService creates a new QueueService.
+func NewQueueService(queueRepo repository.QueueRepository) *QueueService {
+	return &QueueService{queueRepo: queueRepo}
+}
+
+// UpdateQueueParams holds the changes for UpdateQueue. Nil fields are left unchanged.
+type UpdateQueueParams struct {
+	WorkspaceID string
+	Name        string
+	NewName     *string
+	Description *string
+}
+
+// CreateQueue creates a queue in the workspace.
+func (s *QueueService) CreateQueue(ctx context.Context, workspaceID, name, description string) (*domain.Queue, error) {
+	name, err := domain.NormalizeQueueName(name)
+	if err != nil {
+		return nil, err
+	}
+	description = strings.TrimSpace(description)
+	if len(description) > maxQueueDescriptionLength {
+		return nil, fmt.Errorf("%w: description must be at most %d characters", domain.ErrValidation, maxQueueDescriptionLength)
+	}
+
+	queue := &domain.Queue{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Description: description,
+	}
+	if err := s.queueRepo.Create(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	slog.Info("queue created",
+		"workspace_id", workspaceID,
+		"queue", name,
+	)
+
+	return queue, nil
+}
+
+// ListQueues returns all queues of a workspace.
+func (s *QueueService) ListQueues(ctx context.Context, workspaceID string) ([]*domain.Queue, error) {
+	return s.queueRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+// UpdateQueue renames a queue and/or changes its description. Tasks follow a rename.
+func (s *QueueService) UpdateQueue(ctx context.Context, params UpdateQueueParams) (*domain.Queue, error) {
+	name, err := domain.NormalizeQueueName(params.Name)
+	if err != nil {
+		return nil, domain.ErrQueueNotFound
+	}
+
+	var newName *string
+	if params.NewName != nil {
+		normalized, err := domain.NormalizeQueueName(*params.NewName)
+		if err != nil {
+			return nil, err
+		}
+		newName = &normalized
+	}
+
+	var description *string
+	if params.Description != nil {
+		trimmed := strings.TrimSpace(*params.Description)
+		if len(trimmed) > maxQueueDescriptionLength {
+			return nil, fmt.Errorf("%w: description must be at most %d characters", domain.ErrValidation, maxQueueDescriptionLength)
+		}
+		description = &trimmed
+	}
+
+	if newName == nil && description == nil {
+		return s.queueRepo.GetByName(ctx, params.WorkspaceID, name)
+	}
+
+	queue, err := s.queueRepo.Update(ctx, params.WorkspaceID, name, newName, description)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("queue updated",
+		"workspace_id", params.WorkspaceID,
+		"queue", name,
+		"new_name", queue.Name,
+	)
+
+	return queue, nil
+}
+
+// DeleteQueue removes a queue. Queues that still have tasks cannot be deleted.
+func (s *QueueService) DeleteQueue(ctx context.Context, workspaceID, name string) error {
+	name, err := domain.NormalizeQueueName(name)
+	if err != nil {
+		return domain.ErrQueueNotFound
+	}
+
+	if err := s.queueRepo.Delete(ctx, workspaceID, name); err != nil {
+		return err
+	}
+
+	slog.Info("queue deleted",
+		"workspace_id", workspaceID,
+		"queue", name,
+	)
+
+	return nil
+}
+
+// resolveQueue normalizes a queue name and checks that the queue exists in the workspace.
+func (s *TaskService) resolveQueue(ctx context.Context, workspaceID, name string) (*string, error) {
+	name, err := domain.NormalizeQueueName(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.queueRepo.GetByName(ctx, workspaceID, name); err != nil {
+		return nil, err
+	}
+	return &name, nil
+}
+
+// ClaimNextParams holds parameters for claiming the next available task.
+type ClaimNextParams struct {
+	AgentID string
+	Queue   *string // Optional: only claim from this queue
+	Comment string
+}
+
+// ClaimNext claims the most urgent task the agent is able to claim (priority, then age).
+// Returns ErrNoClaimableTask if nothing is available.
+func (s *TaskService) ClaimNext(ctx context.Context, params ClaimNextParams) (*domain.Task, *domain.TaskEvent, error) {
+	if params.Comment == "" {
+		return nil, nil, domain.ErrEmptyComment
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var queue *string
+	if params.Queue != nil {
+		queue, err = s.resolveQueue(ctx, agent.WorkspaceID, *params.Queue)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.FindNextClaimable(ctx, tx, agent, queue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data map[string]any
+	if queue != nil {
+		data = map[string]any{"queue": *queue}
+	}
+
+	event, err := s.claimLocked(ctx, tx, task, agent, params.Comment, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, err = s.taskRepo.GetByID(ctx, task.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reload claimed task: %w", err)
+	}
+
+	return task, event, nil
+}
+
+// WaitForClaimableParams holds parameters for waiting on claimable work.
+type WaitForClaimableParams struct {
+	AgentID string
+	Queue   *string // Optional: only wait for tasks of this queue
+	Timeout time.Duration
+}
+
+// WaitForClaimable returns the task ClaimNext would claim for the agent, waiting
+// up to params.Timeout for one to appear. It looks again on every change read from
+// changes, which should be subscribed to the agent's workspace before the call so
+// no change is missed, and every claimableRecheckInterval; with nil changes it
+// only polls. The task is not claimed, so another agent may claim it first.
+// Returns ErrNoClaimableTask if nothing appears in time or changes is closed.
+func (s *TaskService) WaitForClaimable(
+	ctx context.Context,
+	changes <-chan *domain.TaskChange,
+	params WaitForClaimableParams,
+) (*domain.Task, error) {
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue *string
+	if params.Queue != nil {
+		queue, err = s.resolveQueue(ctx, agent.WorkspaceID, *params.Queue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := time.NewTimer(params.Timeout)
+	defer timeout.Stop()
+	recheck := time.NewTicker(claimableRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		task, err := s.taskRepo.FindClaimable(ctx, agent, queue)
+		if !errors.Is(err, domain.ErrNoClaimableTask) {
+			return task, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, domain.ErrNoClaimableTask
+		case _, ok := <-changes:
+			if !ok {
+				return nil, domain.ErrNoClaimableTask
+			}
+		case <-recheck.C:
+			// Capabilities may have changed without a task event
+			if agent, err = s.getActiveAgent(ctx, params.AgentID); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
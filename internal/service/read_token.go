@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// ReadTokenService manages workspace-scoped read-only API tokens.
+type ReadTokenService struct {
+	readTokenRepo *repository.ReadTokenRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+// NewReadTokenService creates a new ReadTokenService.
+func NewReadTokenService(
+	readTokenRepo *repository.ReadTokenRepository,
+	workspaceRepo repository.WorkspaceRepository,
+) *ReadTokenService {
+	return &ReadTokenService{
+		readTokenRepo: readTokenRepo,
+		workspaceRepo: workspaceRepo,
+	}
+}
+
+// CreateReadToken issues a new read token for the workspace.
+// Returns the plaintext token, which is not stored and cannot be retrieved again.
+func (s *ReadTokenService) CreateReadToken(
+	ctx context.Context,
+	workspaceID string,
+	name string,
+	expiresAt *time.Time,
+) (string, *domain.ReadToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: name is required", domain.ErrValidation)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, fmt.Errorf("%w: expires_at must be in the future", domain.ErrValidation)
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return "", nil, err
+	}
+	if workspace.IsArchived() {
+		return "", nil, fmt.Errorf("%w: cannot issue read tokens for workspace %s", domain.ErrWorkspaceArchived, workspace.Slug)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("generate read token: %w", err)
+	}
+	plaintext := domain.ReadTokenPrefix + hex.EncodeToString(secret)
+
+	token := &domain.ReadToken{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		TokenHash:   domain.HashReadToken(plaintext),
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.readTokenRepo.Create(ctx, token); err != nil {
+		return "", nil, err
+	}
+
+	slog.Info("read token created",
+		"read_token_id", token.ID,
+		"workspace_id", workspaceID,
+		"expires_at", expiresAt,
+	)
+
+	return plaintext, token, nil
+}
+
+// ListReadTokens returns all read tokens of a workspace.
+func (s *ReadTokenService) ListReadTokens(ctx context.Context, workspaceID string) ([]*domain.ReadToken, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.readTokenRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+// RevokeReadToken revokes a read token immediately.
+func (s *ReadTokenService) RevokeReadToken(ctx context.Context, tokenID string) error {
+	if err := s.readTokenRepo.Revoke(ctx, tokenID); err != nil {
+		return err
+	}
+	slog.Info("read token revoked", "read_token_id", tokenID)
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ReleaseAbandonedTasks returns IN_PROGRESS tasks to NEW when their assignee was
+// deactivated or stopped sending heartbeats, so they don't sit untouched until
+// their deadline expires. It is meant to run periodically, like
+// ProcessExpiredDeadlines. Returns the number of tasks released, and an error
+// if any task failed.
+func (s *TaskService) ReleaseAbandonedTasks(ctx context.Context) (int, error) {
+	tasks, err := s.taskRepo.FindAbandoned(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("find abandoned tasks: %w", err)
+	}
+
+	if len(tasks) == 0 {
+		slog.Info("no abandoned tasks found")
+		return 0, nil
+	}
+
+	count := 0
+	var errs []error // Accumulate errors
+	for _, task := range tasks {
+		released, err := s.releaseTask(ctx, task.ID)
+		if err != nil {
+			slog.Error("failed to release abandoned task",
+				"task_id", task.ID,
+				"error", err,
+			)
+			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
+			continue
+		}
+		if released {
+			count++
+		}
+	}
+
+	slog.Info("released abandoned tasks",
+		"total", len(tasks),
+		"released", count,
+		"failed", len(errs),
+	)
+
+	if len(errs) > 0 {
+		return count, fmt.Errorf("released %d/%d tasks, %d failures: %v",
+			count, len(tasks), len(errs), errs)
+	}
+
+	return count, nil
+}
+
+// releaseTask moves one task back to NEW with a system released event. The task
+// and its assignee are re-checked under the row lock, so a task that was picked
+// up again or whose agent just sent a heartbeat is left alone (returns false).
+func (s *TaskService) releaseTask(ctx context.Context, taskID string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, taskID)
+	if err != nil {
+		return false, err
+	}
+	if task.Status != domain.TaskStatusInProgress || task.AssigneeID == nil {
+		return false, nil
+	}
+
+	agent, err := s.agentRepo.GetByID(ctx, *task.AssigneeID)
+	if err != nil {
+		return false, fmt.Errorf("get assignee: %w", err)
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return false, fmt.Errorf("get workspace: %w", err)
+	}
+
+	reason := agent.ReleaseReason(time.Now(), workspace.AgentStaleAfter())
+	if reason == "" {
+		return false, nil
+	}
+
+	oldStatus := task.Status
+	newStatus := domain.TaskStatusNew
+	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+		oldStatus, newStatus,
+		nil, CalculateDeadline(workspace, newStatus), nil,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update status: %w", err)
+	}
+
+	comment := fmt.Sprintf("Returned to the pool: assignee %s was deactivated.", agent.Name)
+	if reason == domain.ReleaseReasonAgentStale {
+		comment = fmt.Sprintf("Returned to the pool: assignee %s sent no heartbeat since %s.",
+			agent.Name, agent.LastSeenAt.UTC().Format(time.RFC3339))
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		ActorID:   nil, // system event
+		Type:      domain.EventTypeReleased,
+		OldStatus: &oldStatus,
+		NewStatus: &newStatus,
+		Comment:   comment,
+		Data: map[string]any{
+			"agent_id": agent.ID,
+			"reason":   reason,
+		},
+	}
+
+	if err := s.createHandOffAndCommit(ctx, tx, workspace, event); err != nil {
+		return false, err
+	}
+
+	slog.Info("abandoned task released",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"reason", reason,
+	)
+
+	return true, nil
+}
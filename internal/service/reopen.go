@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// ReopenTaskParams holds parameters for reopening a DONE task.
+type ReopenTaskParams struct {
+	TaskID    string
+	AgentID   string
+	NewStatus domain.TaskStatus // NEW or IN_PROGRESS
+	Comment   string
+}
+
+// ReopenTask moves a DONE task back to NEW (returned to the pool) or IN_PROGRESS (same assignee).
+// The stored result is cleared and an archived task is unarchived. Dependents that started
+// work on the strength of this task (IN_PROGRESS) are moved to BLOCKED, since their blocker
+// is unresolved again.
+func (s *TaskService) ReopenTask(ctx context.Context, params ReopenTaskParams) (*domain.TaskEvent, error) {
+	if params.Comment == "" {
+		return nil, domain.ErrEmptyComment
+	}
+
+	if !params.NewStatus.IsValid() {
+		return nil, domain.ErrInvalidStatus
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.CanReopen(task, agent, params.NewStatus); err != nil {
+		return nil, err
+	}
+
+	if params.NewStatus == domain.TaskStatusInProgress {
+		if err := s.validator.CheckBlockedByResolved(ctx, task.BlockedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get workspace: %w", err)
+	}
+
+	newAssignee := task.AssigneeID
+	if ShouldClearAssignee(params.NewStatus) {
+		newAssignee = nil
+	}
+
+	oldStatus := task.Status
+	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+		oldStatus, params.NewStatus,
+		newAssignee, CalculateDeadline(workspace, params.NewStatus), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.SetResult(ctx, tx, task.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if task.IsArchived() {
+		if err := s.taskRepo.SetArchived(ctx, tx, task.ID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	blockedCount, err := s.blockDependents(ctx, tx, workspace, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		ActorID:   &agent.ID,
+		Type:      domain.EventTypeReopened,
+		OldStatus: &oldStatus,
+		NewStatus: &params.NewStatus,
+		Comment:   params.Comment,
+	}
+
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task reopened",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"new_status", params.NewStatus,
+		"blocked_dependents", blockedCount,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
+
+// blockDependents moves IN_PROGRESS tasks that depend on blockerID to BLOCKED within tx,
+// recording a system event on each. Returns the number of tasks blocked.
+func (s *TaskService) blockDependents(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace, blockerID string) (int, error) {
+	dependents, err := s.taskRepo.GetDependentTasks(ctx, []string{blockerID})
+	if err != nil {
+		return 0, fmt.Errorf("get dependent tasks: %w", err)
+	}
+
+	count := 0
+	oldStatus := domain.TaskStatusInProgress
+	newStatus := domain.TaskStatusBlocked
+	for _, dependent := range dependents {
+		if dependent.Status != domain.TaskStatusInProgress {
+			continue
+		}
+
+		err := s.taskRepo.UpdateStatus(ctx, tx, dependent.ID,
+			oldStatus, newStatus,
+			dependent.AssigneeID, CalculateDeadline(workspace, newStatus), nil,
+		)
+		if errors.Is(err, domain.ErrTaskAlreadyClaimed) {
+			// Status changed concurrently; nothing to block
+			continue
+		}
+		if err != nil {
+			return count, fmt.Errorf("block dependent task %s: %w", dependent.ID, err)
+		}
+
+		event := &domain.TaskEvent{
+			TaskID:    dependent.ID,
+			ActorID:   nil, // system event
+			Type:      domain.EventTypeStatusChanged,
+			OldStatus: &oldStatus,
+			NewStatus: &newStatus,
+			Comment:   fmt.Sprintf("Blocker task %s was reopened.", blockerID),
+		}
+		if err := s.createEvent(ctx, tx, event); err != nil {
+			return count, fmt.Errorf("create event for dependent task %s: %w", dependent.ID, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
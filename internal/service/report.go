@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// ReportService manages scheduled reports and renders and delivers them when due.
+type ReportService struct {
+	pool          *pgxpool.Pool
+	reportRepo    *repository.ReportRepository
+	taskRepo      repository.TaskRepository
+	workspaceRepo repository.WorkspaceRepository
+	secretRepo    repository.WebhookSecretRepository
+	delivery      ReportDeliveryConfig
+}
+
+// NewReportService creates a new ReportService. The delivery configuration is only
+// used by RunDueReports; the HTTP API manages and previews reports without it.
+func NewReportService(
+	pool *pgxpool.Pool,
+	reportRepo *repository.ReportRepository,
+	taskRepo repository.TaskRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	secretRepo repository.WebhookSecretRepository,
+	delivery ReportDeliveryConfig,
+) *ReportService {
+	return &ReportService{
+		pool:          pool,
+		reportRepo:    reportRepo,
+		taskRepo:      taskRepo,
+		workspaceRepo: workspaceRepo,
+		secretRepo:    secretRepo,
+		delivery:      delivery,
+	}
+}
+
+// CreateReportParams holds parameters for creating a report.
+type CreateReportParams struct {
+	WorkspaceID string
+	CreatorID   string
+	Name        string
+	Kind        domain.ReportKind
+	Format      domain.ReportFormat // Optional: defaults to markdown
+	Period      domain.ReportPeriod // Optional: defaults to the kind's period
+	CronExpr    string
+	Timezone    string // Optional: IANA name, defaults to UTC
+	TargetType  domain.ReportTargetType
+	Target      string
+}
+
+// UpdateReportParams holds the changes for UpdateReport. Nil fields are left unchanged.
+type UpdateReportParams struct {
+	WorkspaceID string
+	ReportID    string
+	AgentID     string
+	Name        *string
+	Kind        *domain.ReportKind
+	Format      *domain.ReportFormat
+	Period      *domain.ReportPeriod
+	CronExpr    *string
+	Timezone    *string
+	TargetType  *domain.ReportTargetType
+	Target      *string
+	Enabled     *bool
+}
+
+// CreateReport validates and stores a report. The first delivery is the next time
+// the cron expression fires.
+func (s *ReportService) CreateReport(ctx context.Context, params CreateReportParams) (*domain.Report, error) {
+	report := &domain.Report{
+		WorkspaceID: params.WorkspaceID,
+		CreatorID:   params.CreatorID,
+		Name:        strings.TrimSpace(params.Name),
+		Kind:        params.Kind,
+		Format:      params.Format,
+		Period:      params.Period,
+		CronExpr:    strings.TrimSpace(params.CronExpr),
+		Timezone:    strings.TrimSpace(params.Timezone),
+		TargetType:  params.TargetType,
+		Target:      params.Target,
+		Enabled:     true,
+	}
+	if report.Format == "" {
+		report.Format = domain.ReportFormatMarkdown
+	}
+	if report.Period == "" {
+		report.Period = report.Kind.DefaultPeriod()
+	}
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+
+	if err := validateReport(report); err != nil {
+		return nil, err
+	}
+
+	var err error
+	report.NextRunAt, err = report.NextRun(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+
+	slog.Info("report created",
+		"workspace_id", report.WorkspaceID,
+		"report_id", report.ID,
+		"kind", report.Kind,
+		"target_type", report.TargetType,
+		"next_run_at", report.NextRunAt,
+	)
+
+	return report, nil
+}
+
+// GetReport returns a report of the workspace.
+func (s *ReportService) GetReport(ctx context.Context, workspaceID, reportID string) (*domain.Report, error) {
+	return s.reportRepo.GetByID(ctx, workspaceID, reportID)
+}
+
+// ListReports returns all reports of a workspace.
+func (s *ReportService) ListReports(ctx context.Context, workspaceID string) ([]*domain.Report, error) {
+	return s.reportRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+// UpdateReport changes a report. Only its creator may change it. Changing the cron
+// expression, timezone or re-enabling the report recomputes the next run from now.
+func (s *ReportService) UpdateReport(ctx context.Context, params UpdateReportParams) (*domain.Report, error) {
+	report, err := s.reportRepo.GetByID(ctx, params.WorkspaceID, params.ReportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.CreatorID != params.AgentID {
+		return nil, fmt.Errorf("%w: agent %s is not creator of report %s", domain.ErrPermissionDenied, params.AgentID, report.ID)
+	}
+
+	reschedule := false
+	if params.Name != nil {
+		report.Name = strings.TrimSpace(*params.Name)
+	}
+	if params.Kind != nil {
+		report.Kind = *params.Kind
+	}
+	if params.Format != nil {
+		report.Format = *params.Format
+	}
+	if params.Period != nil {
+		report.Period = *params.Period
+	}
+	if params.CronExpr != nil {
+		report.CronExpr = strings.TrimSpace(*params.CronExpr)
+		reschedule = true
+	}
+	if params.Timezone != nil {
+		report.Timezone = strings.TrimSpace(*params.Timezone)
+		reschedule = true
+	}
+	if params.TargetType != nil {
+		report.TargetType = *params.TargetType
+	}
+	if params.Target != nil {
+		report.Target = *params.Target
+	}
+	if params.Enabled != nil {
+		reschedule = reschedule || (*params.Enabled && !report.Enabled)
+		report.Enabled = *params.Enabled
+	}
+
+	if err := validateReport(report); err != nil {
+		return nil, err
+	}
+
+	if reschedule {
+		report.NextRunAt, err = report.NextRun(time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, err
+	}
+
+	slog.Info("report updated",
+		"workspace_id", report.WorkspaceID,
+		"report_id", report.ID,
+		"enabled", report.Enabled,
+		"next_run_at", report.NextRunAt,
+	)
+
+	return report, nil
+}
+
+// DeleteReport removes a report. Only its creator may delete it.
+func (s *ReportService) DeleteReport(ctx context.Context, workspaceID, reportID, agentID string) error {
+	report, err := s.reportRepo.GetByID(ctx, workspaceID, reportID)
+	if err != nil {
+		return err
+	}
+
+	if report.CreatorID != agentID {
+		return fmt.Errorf("%w: agent %s is not creator of report %s", domain.ErrPermissionDenied, agentID, report.ID)
+	}
+
+	if err := s.reportRepo.Delete(ctx, workspaceID, reportID); err != nil {
+		return err
+	}
+
+	slog.Info("report deleted",
+		"workspace_id", workspaceID,
+		"report_id", reportID,
+	)
+
+	return nil
+}
+
+// PreviewReport renders a report as it would be delivered now, without delivering it.
+func (s *ReportService) PreviewReport(ctx context.Context, workspaceID, reportID string) (*RenderedReport, error) {
+	report, err := s.reportRepo.GetByID(ctx, workspaceID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderReport(ctx, report, time.Now())
+}
+
+// RunDueReports renders and delivers every enabled report whose next run is due and
+// moves each report to its following run. A failed delivery is not retried; the
+// reason is stored in last_error. Returns the number of reports delivered.
+func (s *ReportService) RunDueReports(ctx context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+	for {
+		delivered, ok, err := s.runNextDue(ctx, now)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+		if delivered {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// runNextDue delivers one due report while holding its row lock, so concurrent
+// schedulers never deliver the same run twice. ok is false when none is due.
+func (s *ReportService) runNextDue(ctx context.Context, now time.Time) (delivered, ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	report, err := s.reportRepo.LockNextDue(ctx, tx, now)
+	if err != nil {
+		if errors.Is(err, domain.ErrReportNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	nextRunAt, err := report.NextRun(now)
+	if err != nil {
+		// Stored expressions are validated, but a timezone can disappear from tzdata
+		if err := s.reportRepo.Disable(ctx, tx, report.ID, err.Error()); err != nil {
+			return false, false, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return false, false, fmt.Errorf("commit transaction: %w", err)
+		}
+		slog.Error("report disabled", "report_id", report.ID, "error", err)
+		return false, true, nil
+	}
+
+	var runErr *string
+	deliverErr := s.renderAndDeliver(ctx, report, now)
+	if deliverErr != nil {
+		message := deliverErr.Error()
+		runErr = &message
+	}
+
+	if err := s.reportRepo.RecordRun(ctx, tx, report.ID, now, nextRunAt, runErr); err != nil {
+		return false, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if deliverErr != nil {
+		slog.Warn("report delivery failed",
+			"report_id", report.ID,
+			"target_type", report.TargetType,
+			"next_run_at", nextRunAt,
+			"error", deliverErr,
+		)
+		return false, true, nil
+	}
+
+	slog.Info("report delivered",
+		"report_id", report.ID,
+		"target_type", report.TargetType,
+		"next_run_at", nextRunAt,
+	)
+
+	return true, true, nil
+}
+
+// renderAndDeliver renders a report for the period ending at now and sends it to its target.
+func (s *ReportService) renderAndDeliver(ctx context.Context, report *domain.Report, now time.Time) error {
+	rendered, err := s.renderReport(ctx, report, now)
+	if err != nil {
+		return err
+	}
+
+	return s.deliver(ctx, report, rendered)
+}
+
+// validateReport checks a report's settings and normalizes its target.
+func validateReport(report *domain.Report) error {
+	if report.Name == "" || len(report.Name) > domain.MaxReportNameLength {
+		return fmt.Errorf("%w: name must be 1-%d characters", domain.ErrValidation, domain.MaxReportNameLength)
+	}
+	if !report.Kind.IsValid() {
+		return fmt.Errorf("%w: kind must be workspace_summary or agent_performance", domain.ErrValidation)
+	}
+	if !report.Format.IsValid() {
+		return fmt.Errorf("%w: format must be json or markdown", domain.ErrValidation)
+	}
+	if !report.Period.IsValid() {
+		return fmt.Errorf("%w: period must be day, week or month", domain.ErrValidation)
+	}
+
+	target, err := normalizeReportTarget(report.TargetType, report.Target)
+	if err != nil {
+		return err
+	}
+	report.Target = target
+
+	// Catches invalid cron expressions and timezones before they are stored
+	_, err = report.NextRun(time.Now())
+	return err
+}
+
+// normalizeReportTarget validates a webhook URL or email address list. Email
+// addresses are returned as a comma-separated list of bare addresses.
+func normalizeReportTarget(targetType domain.ReportTargetType, target string) (string, error) {
+	target = strings.TrimSpace(target)
+
+	switch targetType {
+	case domain.ReportTargetWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("%w: webhook target must be an absolute http:// or https:// URL", domain.ErrValidation)
+		}
+		return target, nil
+	case domain.ReportTargetEmail:
+		list, err := mail.ParseAddressList(target)
+		if err != nil || len(list) == 0 {
+			return "", fmt.Errorf("%w: email target must be a comma-separated list of addresses", domain.ErrValidation)
+		}
+		addresses := make([]string, len(list))
+		for i, address := range list {
+			addresses[i] = address.Address
+		}
+		return strings.Join(addresses, ","), nil
+	default:
+		return "", fmt.Errorf("%w: target_type must be webhook or email", domain.ErrValidation)
+	}
+}
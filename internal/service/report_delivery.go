@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/pkg/client"
+)
+
+// reportDeliveryTimeout bounds a single webhook delivery.
+const reportDeliveryTimeout = 10 * time.Second
+
+// HeaderReportID carries the ID of the report in webhook deliveries.
+const HeaderReportID = "X-Sloptask-Report"
+
+// ReportDeliveryConfig configures how reports and escalation notifications leave the server.
+type ReportDeliveryConfig struct {
+	// WebhookSecret signs webhook deliveries the same way task event webhooks
+	// are signed (see pkg/client) for workspaces without their own secret.
+	// Their deliveries are unsigned when empty.
+	WebhookSecret string
+
+	// SMTPAddr is the host:port of the mail relay. Email deliveries fail while empty.
+	SMTPAddr     string
+	SMTPFrom     string
+	SMTPUsername string // PLAIN auth is used when set
+	SMTPPassword string
+
+	// HTTPClient sends webhook deliveries; defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+}
+
+// errEmailNotConfigured is recorded on email deliveries made by a scheduler without SMTP settings.
+var errEmailNotConfigured = errors.New("email delivery is not configured (--smtp-addr, --smtp-from)")
+
+// deliver sends a rendered report to the report's target.
+func (s *ReportService) deliver(ctx context.Context, report *domain.Report, rendered *RenderedReport) error {
+	headers := map[string]string{HeaderReportID: report.ID}
+
+	switch report.TargetType {
+	case domain.ReportTargetWebhook:
+		return postWebhook(ctx, s.delivery, s.secretRepo, webhookDelivery{
+			WorkspaceID: report.WorkspaceID,
+			URL:         report.Target,
+			UserAgent:   "sloptask-reports",
+			ContentType: rendered.ContentType,
+			Headers:     headers,
+			Body:        rendered.Body,
+		})
+	case domain.ReportTargetEmail:
+		return sendEmail(s.delivery, emailDelivery{
+			Recipients:  strings.Split(report.Target, ","),
+			Subject:     rendered.Subject,
+			ContentType: rendered.ContentType,
+			Headers:     headers,
+			Body:        rendered.Body,
+		})
+	default:
+		return fmt.Errorf("unknown report target type %q", report.TargetType)
+	}
+}
+
+// webhookDelivery is one signed POST to a workspace's webhook target.
+type webhookDelivery struct {
+	WorkspaceID string
+	URL         string
+	UserAgent   string
+	ContentType string
+	DeliveryID  string            // reused on retries; a fresh ID per attempt when empty
+	Headers     map[string]string // identify what is delivered, e.g. X-Sloptask-Report
+	Body        []byte
+}
+
+// postWebhook POSTs a delivery, signed with the workspace's webhook secrets.
+// Any non-2xx response is a failure.
+func postWebhook(ctx context.Context, cfg ReportDeliveryConfig, secretRepo repository.WebhookSecretRepository, delivery webhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, reportDeliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", delivery.ContentType)
+	req.Header.Set("User-Agent", delivery.UserAgent)
+	for name, value := range delivery.Headers {
+		req.Header.Set(name, value)
+	}
+	deliveryID := delivery.DeliveryID
+	if deliveryID == "" {
+		deliveryID = uuid.NewString()
+	}
+	req.Header.Set(client.HeaderDelivery, deliveryID)
+	req.Header.Set(client.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	secrets, err := webhookSecrets(ctx, cfg, secretRepo, delivery.WorkspaceID, now)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		req.Header.Set(client.HeaderSignature, signWebhook(secrets, now, delivery.Body))
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: reportDeliveryTimeout}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("deliver webhook: %s responded %s", req.URL.Host, resp.Status)
+	}
+
+	return nil
+}
+
+// webhookSecrets returns the secrets signing webhook deliveries of a workspace:
+// its own secret (with the previous one during a rotation), else the
+// server-wide secret. Deliveries are unsigned when neither is set.
+func webhookSecrets(ctx context.Context, cfg ReportDeliveryConfig, secretRepo repository.WebhookSecretRepository, workspaceID string, now time.Time) ([]string, error) {
+	secret, err := secretRepo.GetByWorkspace(ctx, workspaceID)
+	if err == nil {
+		return secret.SigningSecrets(now), nil
+	}
+	if !errors.Is(err, domain.ErrWebhookSecretNotFound) {
+		return nil, fmt.Errorf("get webhook secret: %w", err)
+	}
+	if cfg.WebhookSecret != "" {
+		return []string{cfg.WebhookSecret}, nil
+	}
+	return nil, nil
+}
+
+// emailDelivery is one message to a list of addresses.
+type emailDelivery struct {
+	Recipients  []string
+	Subject     string
+	ContentType string
+	Headers     map[string]string // identify what is delivered, e.g. X-Sloptask-Report
+	Body        []byte
+}
+
+// sendEmail mails a delivery through the configured relay.
+func sendEmail(cfg ReportDeliveryConfig, delivery emailDelivery) error {
+	if cfg.SMTPAddr == "" || cfg.SMTPFrom == "" {
+		return errEmailNotConfigured
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.SMTPFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(delivery.Recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", delivery.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n", delivery.ContentType)
+	for _, name := range slices.Sorted(maps.Keys(delivery.Headers)) {
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, delivery.Headers[name])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(bytes.ReplaceAll(delivery.Body, []byte("\n"), []byte("\r\n")))
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTPAddr)
+		if err != nil {
+			return fmt.Errorf("invalid smtp address %q: %w", cfg.SMTPAddr, err)
+		}
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+	}
+
+	if err := smtp.SendMail(cfg.SMTPAddr, auth, cfg.SMTPFrom, delivery.Recipients, msg.Bytes()); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// Content types of rendered reports.
+const (
+	reportContentTypeJSON     = "application/json"
+	reportContentTypeMarkdown = "text/markdown; charset=utf-8"
+)
+
+// RenderedReport is a report document ready for delivery.
+type RenderedReport struct {
+	Subject     string // email subject line
+	ContentType string
+	Body        []byte
+}
+
+// reportDocument is the JSON rendering of a report. The markdown rendering
+// carries the same data.
+type reportDocument struct {
+	ReportID      string         `json:"report_id"`
+	ReportName    string         `json:"report_name"`
+	Kind          string         `json:"kind"`
+	WorkspaceID   string         `json:"workspace_id"`
+	WorkspaceName string         `json:"workspace_name"`
+	PeriodStart   time.Time      `json:"period_start"`
+	PeriodEnd     time.Time      `json:"period_end"`
+	Summary       *reportSummary `json:"summary,omitempty"`
+	Agents        []reportAgent  `json:"agents,omitempty"`
+}
+
+// reportSummary is the body of a workspace_summary report.
+type reportSummary struct {
+	TasksCreated          int            `json:"tasks_created"`
+	TasksCompleted        int            `json:"tasks_completed"`
+	TasksByStatus         map[string]int `json:"tasks_by_status"`
+	OverdueCount          int            `json:"overdue_count"`
+	StuckCount            int            `json:"stuck_count"`
+	AwaitingExternalCount int            `json:"awaiting_external_count"`
+	ClaimableCount        int            `json:"claimable_count"`
+	OldestClaimableAt     *time.Time     `json:"oldest_claimable_at"`
+}
+
+// reportAgent is one row of an agent_performance report.
+type reportAgent struct {
+	AgentID         string `json:"agent_id"`
+	AgentName       string `json:"agent_name"`
+	TasksCompleted  int    `json:"tasks_completed"`
+	TasksCancelled  int    `json:"tasks_cancelled"`
+	TasksInProgress int    `json:"tasks_in_progress"`
+	TasksStuck      int    `json:"tasks_stuck"`
+}
+
+// renderReport collects the report's data for the period ending at now and
+// renders it in the report's format.
+func (s *ReportService) renderReport(ctx context.Context, report *domain.Report, now time.Time) (*RenderedReport, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, report.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get workspace: %w", err)
+	}
+
+	doc := reportDocument{
+		ReportID:      report.ID,
+		ReportName:    report.Name,
+		Kind:          string(report.Kind),
+		WorkspaceID:   workspace.ID,
+		WorkspaceName: workspace.Name,
+		PeriodStart:   report.Period.Start(now),
+		PeriodEnd:     now,
+	}
+
+	filters := repository.StatsFilters{
+		WorkspaceID: report.WorkspaceID,
+		PeriodStart: doc.PeriodStart,
+		PeriodEnd:   doc.PeriodEnd,
+	}
+
+	agentStats, err := s.taskRepo.GetAgentStats(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	switch report.Kind {
+	case domain.ReportKindWorkspaceSummary:
+		workspaceStats, err := s.taskRepo.GetWorkspaceStats(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		depth, err := s.taskRepo.GetQueueDepth(ctx, report.WorkspaceID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		completed := 0
+		for _, agent := range agentStats {
+			completed += agent.TasksCompleted
+		}
+
+		doc.Summary = &reportSummary{
+			TasksCreated:          workspaceStats.TotalTasksCreated,
+			TasksCompleted:        completed,
+			TasksByStatus:         workspaceStats.TasksByStatus,
+			OverdueCount:          workspaceStats.OverdueCount,
+			StuckCount:            workspaceStats.StuckCount,
+			AwaitingExternalCount: workspaceStats.AwaitingExternalCount,
+			ClaimableCount:        depth.Total,
+			OldestClaimableAt:     depth.OldestAt,
+		}
+	case domain.ReportKindAgentPerformance:
+		doc.Agents = make([]reportAgent, len(agentStats))
+		for i, agent := range agentStats {
+			doc.Agents[i] = reportAgent{
+				AgentID:         agent.AgentID,
+				AgentName:       agent.AgentName,
+				TasksCompleted:  agent.TasksCompleted,
+				TasksCancelled:  agent.TasksCancelled,
+				TasksInProgress: agent.TasksInProgress,
+				TasksStuck:      agent.TasksStuckCount,
+			}
+		}
+	}
+
+	rendered := &RenderedReport{
+		Subject: fmt.Sprintf("[sloptask] %s: %s (%s)", workspace.Name, report.Name, now.In(reportLocation(report)).Format("2006-01-02")),
+	}
+
+	if report.Format == domain.ReportFormatJSON {
+		rendered.ContentType = reportContentTypeJSON
+		rendered.Body, err = json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("encode report: %w", err)
+		}
+		return rendered, nil
+	}
+
+	rendered.ContentType = reportContentTypeMarkdown
+	rendered.Body = []byte(renderReportMarkdown(doc, reportLocation(report)))
+	return rendered, nil
+}
+
+// reportLocation returns the timezone report times are shown in.
+func reportLocation(report *domain.Report) *time.Location {
+	loc, err := time.LoadLocation(report.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// renderReportMarkdown renders a report document as Markdown tables.
+func renderReportMarkdown(doc reportDocument, loc *time.Location) string {
+	const timeLayout = "2006-01-02 15:04 MST"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", escapeMarkdown(doc.WorkspaceName), escapeMarkdown(doc.ReportName))
+	fmt.Fprintf(&b, "Period: %s to %s\n", doc.PeriodStart.In(loc).Format(timeLayout), doc.PeriodEnd.In(loc).Format(timeLayout))
+
+	if summary := doc.Summary; summary != nil {
+		b.WriteString("\n| Metric | Value |\n|---|---:|\n")
+		fmt.Fprintf(&b, "| Tasks created | %d |\n", summary.TasksCreated)
+		fmt.Fprintf(&b, "| Tasks completed | %d |\n", summary.TasksCompleted)
+		fmt.Fprintf(&b, "| Overdue | %d |\n", summary.OverdueCount)
+		fmt.Fprintf(&b, "| Stuck | %d |\n", summary.StuckCount)
+		fmt.Fprintf(&b, "| Awaiting external | %d |\n", summary.AwaitingExternalCount)
+		fmt.Fprintf(&b, "| Claimable now | %d |\n", summary.ClaimableCount)
+		if summary.OldestClaimableAt != nil {
+			fmt.Fprintf(&b, "| Oldest claimable since | %s |\n", summary.OldestClaimableAt.In(loc).Format(timeLayout))
+		}
+
+		b.WriteString("\n## Tasks by status\n\n| Status | Tasks |\n|---|---:|\n")
+		statuses := make([]string, 0, len(summary.TasksByStatus))
+		for status := range summary.TasksByStatus {
+			statuses = append(statuses, status)
+		}
+		slices.Sort(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "| %s | %d |\n", status, summary.TasksByStatus[status])
+		}
+	}
+
+	if doc.Kind == string(domain.ReportKindAgentPerformance) {
+		if len(doc.Agents) == 0 {
+			b.WriteString("\nNo active agents.\n")
+			return b.String()
+		}
+		b.WriteString("\n| Agent | Completed | Cancelled | In progress | Stuck |\n|---|---:|---:|---:|---:|\n")
+		for _, agent := range doc.Agents {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n",
+				escapeMarkdown(agent.AgentName),
+				agent.TasksCompleted, agent.TasksCancelled, agent.TasksInProgress, agent.TasksStuck,
+			)
+		}
+	}
+
+	return b.String()
+}
+
+// escapeMarkdown keeps user-provided names from breaking tables and headings.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// EditTaskParams holds parameters for editing a task. Nil fields are left unchanged.
+type EditTaskParams struct {
+	TaskID      string
+	AgentID     string
+	Title       *string
+	Description *string
+	Comment     string // Optional: defaults to "Task edited"
+	// ExpectedVersion, when set, must be the task's current version
+	ExpectedVersion *int
+}
+
+// EditTask changes the title and/or description of an unfinished task. Only the creator
+// may edit. Every edit is stored as a new revision; the first edit also records the
+// original text as revision 1. Returns a nil revision and event if nothing changed.
+func (s *TaskService) EditTask(ctx context.Context, params EditTaskParams) (*domain.TaskRevision, *domain.TaskEvent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := task.CheckVersion(params.ExpectedVersion); err != nil {
+		return nil, nil, err
+	}
+
+	agent, err := s.getActiveAgent(ctx, params.AgentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !task.IsCreatedBy(agent.ID) {
+		return nil, nil, fmt.Errorf("%w: agent %s is not creator of task %s", domain.ErrNotTaskCreator, agent.ID, task.ID)
+	}
+
+	if task.Status.IsTerminal() {
+		return nil, nil, fmt.Errorf("%w: task %s is %s and can no longer be edited", domain.ErrInvalidTransition, task.ID, task.Status)
+	}
+
+	title, description := task.Title, task.Description
+	if params.Title != nil {
+		title = *params.Title
+		if len(title) < 5 || len(title) > 200 {
+			return nil, nil, fmt.Errorf("%w: title must be between 5 and 200 characters", domain.ErrValidation)
+		}
+	}
+	if params.Description != nil {
+		description = *params.Description
+		if strings.TrimSpace(description) == "" {
+			return nil, nil, fmt.Errorf("%w: description is required", domain.ErrValidation)
+		}
+	}
+
+	fields := []string{}
+	if title != task.Title {
+		fields = append(fields, "title")
+	}
+	if description != task.Description {
+		fields = append(fields, "description")
+	}
+	if len(fields) == 0 {
+		return nil, nil, nil
+	}
+
+	latest, err := s.taskRepo.LatestRevision(ctx, tx, task.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if latest == 0 {
+		original := &domain.TaskRevision{
+			TaskID:      task.ID,
+			Revision:    1,
+			Title:       task.Title,
+			Description: task.Description,
+			AuthorID:    task.CreatorID,
+			CreatedAt:   task.CreatedAt,
+		}
+		if err := s.taskRepo.CreateRevision(ctx, tx, original); err != nil {
+			return nil, nil, err
+		}
+		latest = 1
+	}
+
+	if err := s.taskRepo.UpdateText(ctx, tx, task.ID, title, description); err != nil {
+		return nil, nil, err
+	}
+
+	revision := &domain.TaskRevision{
+		TaskID:      task.ID,
+		Revision:    latest + 1,
+		Title:       title,
+		Description: description,
+		AuthorID:    agent.ID,
+	}
+	if err := s.taskRepo.CreateRevision(ctx, tx, revision); err != nil {
+		return nil, nil, err
+	}
+
+	comment := strings.TrimSpace(params.Comment)
+	if comment == "" {
+		comment = "Task edited"
+	}
+
+	event := &domain.TaskEvent{
+		TaskID:  task.ID,
+		ActorID: &agent.ID,
+		Type:    domain.EventTypeEdited,
+		Comment: comment,
+		Data:    map[string]any{"revision": revision.Revision, "fields": fields},
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("task edited",
+		"task_id", task.ID,
+		"agent_id", agent.ID,
+		"revision", revision.Revision,
+		"fields", fields,
+	)
+
+	return revision, event, nil
+}
+
+// ListTaskRevisions returns the version history of a task, oldest first. A task that
+// was never edited has a single revision: its current text.
+func (s *TaskService) ListTaskRevisions(ctx context.Context, task *domain.Task) ([]*domain.TaskRevision, error) {
+	revisions, err := s.taskRepo.ListRevisions(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		revisions = append(revisions, &domain.TaskRevision{
+			TaskID:      task.ID,
+			Revision:    1,
+			Title:       task.Title,
+			Description: task.Description,
+			AuthorID:    task.CreatorID,
+			CreatedAt:   task.CreatedAt,
+		})
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,333 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/testutil/factory"
+)
+
+// Failure injection: every state-changing operation updates the task and writes
+// its audit event in one transaction. A trigger that rejects task_events inserts
+// makes the last statement of each transaction fail, so nothing written before
+// it may survive.
+
+// taskState is the persisted state of a task that a failed operation must not change.
+type taskState struct {
+	WorkspaceID      string
+	Title            string
+	Status           string
+	AssigneeID       *string
+	StatusDeadlineAt *time.Time
+	Artefact         *string
+	Result           map[string]any
+	ArchivedAt       *time.Time
+	UpdatedAt        time.Time
+	EventCount       int
+}
+
+// TestRollback_EventFailureLeavesNoPartialState tests that each service method
+// rolls back its task update when the event insert fails.
+func (s *TaskServiceTestSuite) TestRollback_EventFailureLeavesNoPartialState() {
+	ctx := context.Background()
+
+	// Fixtures are created before the failpoint is armed
+	newTaskID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	inProgressID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	stuckID := s.createTask(ctx, domain.TaskStatusStuck, &s.agent1ID, nil)
+	doneID := s.createTask(ctx, domain.TaskStatusDone, &s.agent1ID, nil)
+	dependentID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, []string{doneID})
+	expiredID := s.createTaskWithExpiredDeadline(ctx)
+
+	waitingApprovalID := s.createTask(ctx, domain.TaskStatusWaitingApproval, &s.agent2ID, nil)
+
+	item, err := s.taskService.AddChecklistItem(ctx, inProgressID, s.agent1ID, "Write the tests")
+	s.Require().NoError(err)
+	comment, err := s.taskService.CommentTask(ctx, inProgressID, s.agent1ID, "Original comment", nil, nil)
+	s.Require().NoError(err)
+
+	target := factory.CreateWorkspace(s.T(), s.pool, factory.WithSlug("platform"))
+	targetAgent := factory.CreateAgent(s.T(), s.pool, target.ID, factory.WithAgentName("platform-agent"))
+
+	// A deactivated agent's task is abandoned and released
+	gone := factory.CreateAgent(s.T(), s.pool, s.workspaceID, factory.WithAgentName("gone-agent"), factory.Inactive())
+	abandonedID := s.createTask(ctx, domain.TaskStatusInProgress, &gone.ID, nil)
+
+	// agent1 and agent2 are busy; an idle agent lets auto-assign reach the event insert
+	factory.CreateAgent(s.T(), s.pool, s.workspaceID, factory.WithAgentName("agent-3"))
+	s.Require().NoError(s.workspaceRepo.SetAutoAssignStrategy(ctx, s.workspaceID, domain.AutoAssignRoundRobin))
+
+	s.injectEventFailure(ctx)
+
+	cases := []struct {
+		name    string
+		taskIDs []string // tasks whose state must be unchanged
+		run     func() error
+	}{
+		{
+			name:    "claim",
+			taskIDs: []string{newTaskID},
+			run: func() error {
+				_, err := s.taskService.ClaimTask(ctx, newTaskID, s.agent2ID, "Taking this task")
+				return err
+			},
+		},
+		{
+			name:    "escalate",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.EscalateTask(ctx, inProgressID, s.agent2ID, "No progress")
+				return err
+			},
+		},
+		{
+			name:    "takeover",
+			taskIDs: []string{stuckID},
+			run: func() error {
+				_, err := s.taskService.TakeoverTask(ctx, stuckID, s.agent2ID, "Taking over")
+				return err
+			},
+		},
+		{
+			name:    "transition with result",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+					TaskID:    inProgressID,
+					AgentID:   s.agent1ID,
+					NewStatus: domain.TaskStatusDone,
+					Comment:   "Finished",
+					Artefact:  "https://example.com/pr/1",
+					Result:    map[string]any{"tests": 42},
+				})
+				return err
+			},
+		},
+		{
+			name:    "reopen with dependents",
+			taskIDs: []string{doneID, dependentID},
+			run: func() error {
+				_, err := s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+					TaskID:    doneID,
+					AgentID:   s.agent1ID,
+					NewStatus: domain.TaskStatusNew,
+					Comment:   "Regression found",
+				})
+				return err
+			},
+		},
+		{
+			name:    "edit",
+			taskIDs: []string{newTaskID},
+			run: func() error {
+				title := "Edited title"
+				_, _, err := s.taskService.EditTask(ctx, service.EditTaskParams{
+					TaskID:  newTaskID,
+					AgentID: s.agent1ID,
+					Title:   &title,
+				})
+				return err
+			},
+		},
+		{
+			name:    "archive",
+			taskIDs: []string{doneID},
+			run: func() error {
+				_, err := s.taskService.ArchiveTask(ctx, doneID, s.agent1ID, "Out of the way")
+				return err
+			},
+		},
+		{
+			name:    "transfer",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.TransferTask(ctx, service.TransferTaskParams{
+					TaskID:            inProgressID,
+					WorkspaceID:       s.workspaceID,
+					TargetWorkspaceID: target.ID,
+					CreatorID:         targetAgent.ID,
+					Comment:           "Belongs to the platform team",
+				})
+				return err
+			},
+		},
+		{
+			name:    "comment edit",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.EditComment(ctx, inProgressID, comment.ID, s.agent1ID, "Edited comment")
+				return err
+			},
+		},
+		{
+			name:    "comment redact",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.RedactComment(ctx, inProgressID, comment.ID, s.agent1ID)
+				return err
+			},
+		},
+		{
+			name:    "approve",
+			taskIDs: []string{waitingApprovalID},
+			run: func() error {
+				_, err := s.taskService.ApproveTask(ctx, s.workspaceID, waitingApprovalID, domain.TaskStatusDone, "")
+				return err
+			},
+		},
+		{
+			name:    "reject",
+			taskIDs: []string{waitingApprovalID},
+			run: func() error {
+				_, err := s.taskService.RejectTask(ctx, s.workspaceID, waitingApprovalID, "Not what was asked")
+				return err
+			},
+		},
+		{
+			name:    "release",
+			taskIDs: []string{abandonedID},
+			run: func() error {
+				_, err := s.taskService.ReleaseAbandonedTasks(ctx)
+				return err
+			},
+		},
+		{
+			name:    "checklist claim",
+			taskIDs: []string{inProgressID},
+			run: func() error {
+				_, err := s.taskService.ClaimChecklistItem(ctx, service.ChecklistItemParams{
+					TaskID:  inProgressID,
+					ItemID:  item.ID,
+					AgentID: s.agent2ID,
+					Comment: "I'll take this",
+				})
+				return err
+			},
+		},
+		{
+			name:    "deadline expiry",
+			taskIDs: []string{expiredID},
+			run: func() error {
+				_, err := s.taskService.ProcessExpiredDeadlines(ctx)
+				return err
+			},
+		},
+		{
+			name:    "auto-assign",
+			taskIDs: []string{newTaskID},
+			run: func() error {
+				_, err := s.taskService.AutoAssign(ctx)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		s.Run(tc.name, func() {
+			before := make([]taskState, len(tc.taskIDs))
+			for i, taskID := range tc.taskIDs {
+				before[i] = s.loadTaskState(ctx, taskID)
+			}
+
+			s.Error(tc.run())
+
+			for i, taskID := range tc.taskIDs {
+				s.Equal(before[i], s.loadTaskState(ctx, taskID), "task %s changed", taskID)
+			}
+		})
+	}
+
+	// Side effects outside the tasks table are rolled back too
+	items, err := s.taskService.GetChecklist(ctx, inProgressID)
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.Nil(items[0].ClaimedBy)
+
+	var body string
+	var redactedAt *time.Time
+	err = s.pool.QueryRow(ctx, `SELECT comment, redacted_at FROM task_events WHERE id = $1`, comment.ID).Scan(&body, &redactedAt)
+	s.Require().NoError(err)
+	s.Equal("Original comment", body)
+	s.Nil(redactedAt)
+
+	var autoAssigned int
+	err = s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM agents WHERE last_auto_assigned_at IS NOT NULL`).Scan(&autoAssigned)
+	s.Require().NoError(err)
+	s.Zero(autoAssigned)
+}
+
+// TestRollback_CreateTaskEventFailure tests that a task is not persisted without its created event.
+func (s *TaskServiceTestSuite) TestRollback_CreateTaskEventFailure() {
+	ctx := context.Background()
+
+	s.injectEventFailure(ctx)
+
+	_, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Never persisted",
+		Description: "The created event insert fails",
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityNormal,
+		AssigneeID:  &s.agent2ID,
+	})
+	s.Require().Error(err)
+
+	var count int
+	err = s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM tasks`).Scan(&count)
+	s.Require().NoError(err)
+	s.Zero(count)
+}
+
+// Helper: injectEventFailure makes every task_events insert fail until the test ends.
+func (s *TaskServiceTestSuite) injectEventFailure(ctx context.Context) {
+	_, err := s.pool.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION test_fail_task_event_insert() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'injected failure';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE TRIGGER test_fail_task_event_insert
+			BEFORE INSERT ON task_events
+			FOR EACH ROW EXECUTE FUNCTION test_fail_task_event_insert();
+	`)
+	s.Require().NoError(err, "failed to install failure trigger")
+
+	t := s.T()
+	t.Cleanup(func() {
+		_, err := s.pool.Exec(context.Background(), `
+			DROP TRIGGER IF EXISTS test_fail_task_event_insert ON task_events;
+			DROP FUNCTION IF EXISTS test_fail_task_event_insert();
+		`)
+		if err != nil {
+			t.Errorf("failed to remove failure trigger: %v", err)
+		}
+	})
+}
+
+// Helper: loadTaskState reads the persisted state of a task and its event count.
+func (s *TaskServiceTestSuite) loadTaskState(ctx context.Context, taskID string) taskState {
+	var state taskState
+	err := s.pool.QueryRow(ctx, `
+		SELECT workspace_id, title, status, assignee_id, status_deadline_at, artefact, result, archived_at, updated_at,
+			(SELECT COUNT(*) FROM task_events WHERE task_id = t.id)
+		FROM tasks t
+		WHERE id = $1
+	`, taskID).Scan(
+		&state.WorkspaceID,
+		&state.Title,
+		&state.Status,
+		&state.AssigneeID,
+		&state.StatusDeadlineAt,
+		&state.Artefact,
+		&state.Result,
+		&state.ArchivedAt,
+		&state.UpdatedAt,
+		&state.EventCount,
+	)
+	s.Require().NoError(err, "failed to load task state")
+	return state
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// ScheduleService manages recurring task schedules and instantiates their tasks.
+type ScheduleService struct {
+	pool         *pgxpool.Pool
+	scheduleRepo *repository.ScheduleRepository
+	taskService  *TaskService
+}
+
+// NewScheduleService creates a new ScheduleService. Tasks are created through taskService.
+func NewScheduleService(pool *pgxpool.Pool, scheduleRepo *repository.ScheduleRepository, taskService *TaskService) *ScheduleService {
+	return &ScheduleService{
+		pool:         pool,
+		scheduleRepo: scheduleRepo,
+		taskService:  taskService,
+	}
+}
+
+// CreateScheduleParams holds parameters for creating a schedule.
+type CreateScheduleParams struct {
+	WorkspaceID string
+	CreatorID   string
+	Name        string
+	CronExpr    string
+	Timezone    string // Optional: IANA name, defaults to UTC
+	Template    domain.TaskTemplate
+}
+
+// UpdateScheduleParams holds the changes for UpdateSchedule. Nil fields are left unchanged;
+// a non-nil Template replaces the whole template.
+type UpdateScheduleParams struct {
+	WorkspaceID string
+	ScheduleID  string
+	AgentID     string
+	Name        *string
+	CronExpr    *string
+	Timezone    *string
+	Enabled     *bool
+	Template    *domain.TaskTemplate
+}
+
+// CreateSchedule validates and stores a schedule. The first run is the next time
+// the cron expression fires.
+func (s *ScheduleService) CreateSchedule(ctx context.Context, params CreateScheduleParams) (*domain.Schedule, error) {
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len(name) > domain.MaxScheduleNameLength {
+		return nil, fmt.Errorf("%w: name must be 1-%d characters", domain.ErrValidation, domain.MaxScheduleNameLength)
+	}
+
+	timezone := strings.TrimSpace(params.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	template, err := s.validateTemplate(ctx, params.WorkspaceID, params.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &domain.Schedule{
+		WorkspaceID: params.WorkspaceID,
+		CreatorID:   params.CreatorID,
+		Name:        name,
+		CronExpr:    strings.TrimSpace(params.CronExpr),
+		Timezone:    timezone,
+		Enabled:     true,
+		Template:    template,
+	}
+
+	schedule.NextRunAt, err = schedule.NextRun(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	slog.Info("schedule created",
+		"workspace_id", schedule.WorkspaceID,
+		"schedule_id", schedule.ID,
+		"cron", schedule.CronExpr,
+		"next_run_at", schedule.NextRunAt,
+	)
+
+	return schedule, nil
+}
+
+// GetSchedule returns a schedule of the workspace.
+func (s *ScheduleService) GetSchedule(ctx context.Context, workspaceID, scheduleID string) (*domain.Schedule, error) {
+	return s.scheduleRepo.GetByID(ctx, workspaceID, scheduleID)
+}
+
+// ListSchedules returns all schedules of a workspace.
+func (s *ScheduleService) ListSchedules(ctx context.Context, workspaceID string) ([]*domain.Schedule, error) {
+	return s.scheduleRepo.ListByWorkspace(ctx, workspaceID)
+}
+
+// UpdateSchedule changes a schedule. Only its creator may change it, since the
+// tasks it creates are created in the creator's name. Changing the cron expression,
+// timezone or re-enabling the schedule recomputes the next run from now.
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, params UpdateScheduleParams) (*domain.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, params.WorkspaceID, params.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.CreatorID != params.AgentID {
+		return nil, fmt.Errorf("%w: agent %s is not creator of schedule %s", domain.ErrPermissionDenied, params.AgentID, schedule.ID)
+	}
+
+	reschedule := false
+	if params.Name != nil {
+		name := strings.TrimSpace(*params.Name)
+		if name == "" || len(name) > domain.MaxScheduleNameLength {
+			return nil, fmt.Errorf("%w: name must be 1-%d characters", domain.ErrValidation, domain.MaxScheduleNameLength)
+		}
+		schedule.Name = name
+	}
+	if params.CronExpr != nil {
+		schedule.CronExpr = strings.TrimSpace(*params.CronExpr)
+		reschedule = true
+	}
+	if params.Timezone != nil {
+		schedule.Timezone = strings.TrimSpace(*params.Timezone)
+		reschedule = true
+	}
+	if params.Enabled != nil {
+		reschedule = reschedule || (*params.Enabled && !schedule.Enabled)
+		schedule.Enabled = *params.Enabled
+	}
+	if params.Template != nil {
+		template, err := s.validateTemplate(ctx, schedule.WorkspaceID, *params.Template)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Template = template
+	}
+
+	if reschedule {
+		schedule.NextRunAt, err = schedule.NextRun(time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	slog.Info("schedule updated",
+		"workspace_id", schedule.WorkspaceID,
+		"schedule_id", schedule.ID,
+		"enabled", schedule.Enabled,
+		"next_run_at", schedule.NextRunAt,
+	)
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule; tasks it created are kept. Only its creator may delete it.
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, workspaceID, scheduleID, agentID string) error {
+	schedule, err := s.scheduleRepo.GetByID(ctx, workspaceID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	if schedule.CreatorID != agentID {
+		return fmt.Errorf("%w: agent %s is not creator of schedule %s", domain.ErrPermissionDenied, agentID, schedule.ID)
+	}
+
+	if err := s.scheduleRepo.Delete(ctx, workspaceID, scheduleID); err != nil {
+		return err
+	}
+
+	slog.Info("schedule deleted",
+		"workspace_id", workspaceID,
+		"schedule_id", scheduleID,
+	)
+
+	return nil
+}
+
+// RunDueSchedules creates a task for every enabled schedule whose next run is due
+// and moves each schedule to its following run. A run that cannot create its task
+// (inactive creator, deleted queue, ...) is skipped and the reason stored in
+// last_error. Returns the number of tasks created.
+func (s *ScheduleService) RunDueSchedules(ctx context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+	for {
+		created, ok, err := s.runNextDue(ctx, now)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+		if created {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// runNextDue runs one due schedule in its own transaction. ok is false when none is due.
+func (s *ScheduleService) runNextDue(ctx context.Context, now time.Time) (created, ok bool, err error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	schedule, err := s.scheduleRepo.LockNextDue(ctx, tx, now)
+	if err != nil {
+		if errors.Is(err, domain.ErrScheduleNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	nextRunAt, err := schedule.NextRun(now)
+	if err != nil {
+		// Stored expressions are validated, but a timezone can disappear from tzdata
+		if err := s.scheduleRepo.Disable(ctx, tx, schedule.ID, err.Error()); err != nil {
+			return false, false, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return false, false, fmt.Errorf("commit transaction: %w", err)
+		}
+		slog.Error("schedule disabled", "schedule_id", schedule.ID, "error", err)
+		return false, true, nil
+	}
+
+	// The task is created under a savepoint so a failed run still records its outcome
+	var taskID, runErr *string
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return false, false, fmt.Errorf("begin savepoint: %w", err)
+	}
+	task, createErr := s.taskService.createTaskInTx(ctx, savepoint, scheduleTaskParams(schedule))
+	if createErr != nil {
+		if err := savepoint.Rollback(ctx); err != nil {
+			return false, false, fmt.Errorf("rollback savepoint: %w", err)
+		}
+		message := createErr.Error()
+		runErr = &message
+	} else {
+		if err := savepoint.Commit(ctx); err != nil {
+			return false, false, fmt.Errorf("release savepoint: %w", err)
+		}
+		taskID = &task.ID
+	}
+
+	if err := s.scheduleRepo.RecordRun(ctx, tx, schedule.ID, now, nextRunAt, taskID, runErr); err != nil {
+		return false, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	if createErr != nil {
+		slog.Warn("scheduled run created no task",
+			"schedule_id", schedule.ID,
+			"next_run_at", nextRunAt,
+			"error", createErr,
+		)
+		return false, true, nil
+	}
+
+	slog.Info("scheduled task created",
+		"schedule_id", schedule.ID,
+		"task_id", task.ID,
+		"next_run_at", nextRunAt,
+	)
+
+	return true, true, nil
+}
+
+// scheduleTaskParams builds the CreateTask parameters for a schedule's template.
+func scheduleTaskParams(schedule *domain.Schedule) CreateTaskParams {
+	template := schedule.Template
+	return CreateTaskParams{
+		WorkspaceID:          schedule.WorkspaceID,
+		CreatorID:            schedule.CreatorID,
+		Title:                template.Title,
+		Description:          template.Description,
+		AssigneeID:           template.AssigneeID,
+		Visibility:           template.Visibility,
+		Priority:             template.Priority,
+		RequiredCapabilities: template.RequiredCapabilities,
+		Queue:                template.Queue,
+		ScheduleID:           &schedule.ID,
+	}
+}
+
+// validateTemplate checks a task template the way CreateTask checks its parameters
+// and fills in the defaults (normal priority, public visibility).
+func (s *ScheduleService) validateTemplate(ctx context.Context, workspaceID string, template domain.TaskTemplate) (domain.TaskTemplate, error) {
+	template.Title = strings.TrimSpace(template.Title)
+	if len(template.Title) < 5 || len(template.Title) > 200 {
+		return template, fmt.Errorf("%w: title must be between 5 and 200 characters", domain.ErrValidation)
+	}
+	if strings.TrimSpace(template.Description) == "" {
+		return template, fmt.Errorf("%w: description is required", domain.ErrValidation)
+	}
+
+	if template.Priority == "" {
+		template.Priority = domain.TaskPriorityNormal
+	}
+	if !template.Priority.IsValid() {
+		return template, domain.ErrInvalidPriority
+	}
+	if template.Visibility == "" {
+		template.Visibility = domain.TaskVisibilityPublic
+	}
+	if !template.Visibility.IsValid() {
+		return template, domain.ErrInvalidVisibility
+	}
+
+	capabilities, err := domain.NormalizeCapabilities(template.RequiredCapabilities)
+	if err != nil {
+		return template, err
+	}
+	template.RequiredCapabilities = capabilities
+
+	if template.Queue != nil {
+		template.Queue, err = s.taskService.resolveQueue(ctx, workspaceID, *template.Queue)
+		if err != nil {
+			return template, err
+		}
+	}
+
+	if template.AssigneeID != nil {
+		assignee, err := s.taskService.getActiveAgent(ctx, *template.AssigneeID)
+		if err != nil {
+			return template, err
+		}
+		if assignee.WorkspaceID != workspaceID {
+			return template, fmt.Errorf("%w: assignee must be in same workspace", domain.ErrPermissionDenied)
+		}
+	}
+
+	return template, nil
+}
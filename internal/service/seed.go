@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// DefaultSeedSlug is the slug of the demo workspace when none is given.
+const DefaultSeedSlug = "demo"
+
+// seedAgent is a demo agent, by key.
+type seedAgent struct {
+	key          string
+	capabilities []string
+}
+
+// seedTask is a demo task, created by the planner. Blockers are task keys.
+type seedTask struct {
+	key                  string
+	title                string
+	description          string
+	priority             domain.TaskPriority
+	blockedBy            []string
+	requiredCapabilities []string
+}
+
+// seedStep moves a demo task on: a claim when status is empty, else a status
+// transition.
+type seedStep struct {
+	task     string
+	agent    string
+	status   domain.TaskStatus
+	comment  string
+	artefact string
+}
+
+var seedAgents = []seedAgent{
+	{key: "planner", capabilities: []string{"planning"}},
+	{key: "coder", capabilities: []string{"go", "sql"}},
+	{key: "reviewer", capabilities: []string{"go", "review"}},
+}
+
+// seedTasks ship an invoicing feature: a design, two branches of work on it,
+// and tests, docs and a release waiting on them. A CI fix runs alongside.
+var seedTasks = []seedTask{
+	{
+		key:         "design",
+		title:       "Design the invoice API",
+		description: "Write down the endpoints, payloads and error cases for creating, listing and voiding invoices.",
+		priority:    domain.TaskPriorityHigh,
+	},
+	{
+		key:                  "schema",
+		title:                "Add the invoices table",
+		description:          "Migration for invoices and invoice lines, with the indexes the list endpoint needs.",
+		priority:             domain.TaskPriorityHigh,
+		blockedBy:            []string{"design"},
+		requiredCapabilities: []string{"sql"},
+	},
+	{
+		key:                  "endpoints",
+		title:                "Implement the invoice endpoints",
+		description:          "Handlers and service methods following the design.",
+		priority:             domain.TaskPriorityNormal,
+		blockedBy:            []string{"design"},
+		requiredCapabilities: []string{"go"},
+	},
+	{
+		key:                  "tests",
+		title:                "Cover invoices with integration tests",
+		description:          "Create, list and void, including the error cases from the design.",
+		priority:             domain.TaskPriorityNormal,
+		blockedBy:            []string{"schema", "endpoints"},
+		requiredCapabilities: []string{"go"},
+	},
+	{
+		key:         "docs",
+		title:       "Document the invoice API",
+		description: "Add the endpoints to the API reference with request and response examples.",
+		priority:    domain.TaskPriorityLow,
+		blockedBy:   []string{"endpoints"},
+	},
+	{
+		key:         "release",
+		title:       "Release invoicing",
+		description: "Tag the release and announce it once tests and docs are done.",
+		priority:    domain.TaskPriorityNormal,
+		blockedBy:   []string{"tests", "docs"},
+	},
+	{
+		key:                  "ci",
+		title:                "Fix the flaky CI job",
+		description:          "The integration job times out about once in ten runs.",
+		priority:             domain.TaskPriorityCritical,
+		requiredCapabilities: []string{"go"},
+	},
+}
+
+// seedSteps leave the design reviewed and done, the schema done, the endpoints
+// and the CI fix in progress and the rest waiting on them.
+var seedSteps = []seedStep{
+	{task: "design", agent: "planner", comment: "Taking the design"},
+	{task: "design", agent: "planner", status: domain.TaskStatusNeedsReview, comment: "Draft ready for review", artefact: "https://example.com/docs/invoice-api"},
+	{task: "design", agent: "reviewer", status: domain.TaskStatusDone, comment: "Looks good, approved"},
+	{task: "schema", agent: "coder", comment: "Starting on the migration"},
+	{task: "schema", agent: "coder", status: domain.TaskStatusDone, comment: "Migration merged", artefact: "https://example.com/pulls/101"},
+	{task: "endpoints", agent: "coder", comment: "Picking up the endpoints"},
+	{task: "ci", agent: "reviewer", comment: "Looking into the timeouts"},
+}
+
+// SeedParams holds parameters for creating demo data.
+type SeedParams struct {
+	Slug string // workspace slug, DefaultSeedSlug when empty
+	Name string // workspace name, "Demo" when empty
+}
+
+// SeedResult is the demo data created by Seed.
+type SeedResult struct {
+	Workspace *domain.Workspace
+	Agents    []*domain.Agent // with their tokens
+	Tasks     []*domain.Task  // in their final state
+}
+
+// SeedService creates demo data for trying sloptask out.
+type SeedService struct {
+	pool          TxBeginner
+	workspaceRepo repository.WorkspaceRepository
+	agentRepo     repository.AgentRepository
+	taskRepo      repository.TaskRepository
+	taskService   *TaskService
+}
+
+// NewSeedService creates a new SeedService.
+func NewSeedService(
+	pool TxBeginner,
+	workspaceRepo repository.WorkspaceRepository,
+	agentRepo repository.AgentRepository,
+	taskRepo repository.TaskRepository,
+	taskService *TaskService,
+) *SeedService {
+	return &SeedService{
+		pool:          pool,
+		workspaceRepo: workspaceRepo,
+		agentRepo:     agentRepo,
+		taskRepo:      taskRepo,
+		taskService:   taskService,
+	}
+}
+
+// Seed creates a demo workspace with a planner, a coder and a reviewer, and a
+// dependency graph of tasks partly worked through, so every task goes through
+// the regular operations and gets real events. Returns ErrWorkspaceExists if
+// the slug is taken.
+func (s *SeedService) Seed(ctx context.Context, params SeedParams) (*SeedResult, error) {
+	slug := params.Slug
+	if slug == "" {
+		slug = DefaultSeedSlug
+	}
+	if len(slug) > maxWorkspaceSlugLength || !workspaceSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: slug must be lowercase letters, digits and dashes, at most %d characters", domain.ErrValidation, maxWorkspaceSlugLength)
+	}
+	name := params.Name
+	if name == "" {
+		name = "Demo"
+	}
+
+	workspace, agents, err := s.createWorkspace(ctx, name, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	agentIDs := make(map[string]string, len(agents))
+	for i, agent := range agents {
+		agentIDs[seedAgents[i].key] = agent.ID
+	}
+
+	taskIDs := make(map[string]string, len(seedTasks))
+	for _, spec := range seedTasks {
+		blockedBy := make([]string, len(spec.blockedBy))
+		for i, key := range spec.blockedBy {
+			blockedBy[i] = taskIDs[key]
+		}
+
+		task, err := s.taskService.CreateTask(ctx, CreateTaskParams{
+			WorkspaceID:          workspace.ID,
+			CreatorID:            agentIDs["planner"],
+			Title:                spec.title,
+			Description:          spec.description,
+			Visibility:           domain.TaskVisibilityPublic,
+			Priority:             spec.priority,
+			BlockedBy:            blockedBy,
+			RequiredCapabilities: spec.requiredCapabilities,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create demo task %s: %w", spec.key, err)
+		}
+		taskIDs[spec.key] = task.ID
+	}
+
+	for _, step := range seedSteps {
+		if step.status == "" {
+			_, err = s.taskService.ClaimTask(ctx, taskIDs[step.task], agentIDs[step.agent], step.comment)
+		} else {
+			_, err = s.taskService.TransitionStatus(ctx, TransitionStatusParams{
+				TaskID:    taskIDs[step.task],
+				AgentID:   agentIDs[step.agent],
+				NewStatus: step.status,
+				Comment:   step.comment,
+				Artefact:  step.artefact,
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("move demo task %s: %w", step.task, err)
+		}
+	}
+
+	tasks := make([]*domain.Task, 0, len(seedTasks))
+	for _, spec := range seedTasks {
+		task, err := s.taskRepo.GetByID(ctx, taskIDs[spec.key])
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	slog.Info("demo data seeded",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"agents", len(agents),
+		"tasks", len(tasks),
+	)
+
+	return &SeedResult{Workspace: workspace, Agents: agents, Tasks: tasks}, nil
+}
+
+// createWorkspace creates the demo workspace and its agents in one transaction.
+func (s *SeedService) createWorkspace(ctx context.Context, name, slug string) (*domain.Workspace, []*domain.Agent, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	workspace := &domain.Workspace{
+		Name:                   name,
+		Slug:                   slug,
+		StatusDeadlines:        map[string]int{"NEW": 120, "IN_PROGRESS": 1440, "BLOCKED": 2880},
+		AutoAssignStrategy:     domain.AutoAssignNone,
+		AgentStaleAfterSeconds: domain.DefaultAgentStaleAfterSeconds,
+		DeadlineExpiry:         map[string]string{},
+	}
+	if err := s.workspaceRepo.Create(ctx, tx, workspace); err != nil {
+		return nil, nil, err
+	}
+
+	agents := make([]*domain.Agent, 0, len(seedAgents))
+	for _, spec := range seedAgents {
+		token, err := generateAgentToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		agent := &domain.Agent{
+			WorkspaceID:  workspace.ID,
+			Name:         spec.key,
+			Token:        token,
+			Capabilities: spec.capabilities,
+		}
+		if err := s.agentRepo.Create(ctx, tx, agent); err != nil {
+			return nil, nil, err
+		}
+		agents = append(agents, agent)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return workspace, agents, nil
+}
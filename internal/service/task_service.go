@@ -2,42 +2,67 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/url"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/pkg/client"
 )
 
+// TxBeginner starts transactions. *pgxpool.Pool satisfies it; unit tests pass
+// a fake returning a fake pgx.Tx.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 // TaskService coordinates task operations and state transitions.
 type TaskService struct {
-	pool          *pgxpool.Pool
-	taskRepo      *repository.TaskRepository
-	eventRepo     *repository.TaskEventRepository
-	agentRepo     *repository.AgentRepository
-	workspaceRepo *repository.WorkspaceRepository
-	validator     *Validator
+	pool           TxBeginner
+	taskRepo       repository.TaskRepository
+	eventRepo      repository.TaskEventRepository
+	agentRepo      repository.AgentRepository
+	workspaceRepo  repository.WorkspaceRepository
+	checklistRepo  repository.ChecklistRepository
+	queueRepo      repository.QueueRepository
+	labelRepo      repository.LabelRepository
+	escalationRepo repository.EscalationRepository
+	// webhookSecretRepo signs DONE validation requests
+	webhookSecretRepo repository.WebhookSecretRepository
+	validator         *Validator
 }
 
 // NewTaskService creates a new TaskService.
 func NewTaskService(
-	pool *pgxpool.Pool,
-	taskRepo *repository.TaskRepository,
-	eventRepo *repository.TaskEventRepository,
-	agentRepo *repository.AgentRepository,
-	workspaceRepo *repository.WorkspaceRepository,
+	pool TxBeginner,
+	taskRepo repository.TaskRepository,
+	eventRepo repository.TaskEventRepository,
+	agentRepo repository.AgentRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	checklistRepo repository.ChecklistRepository,
+	queueRepo repository.QueueRepository,
+	labelRepo repository.LabelRepository,
+	escalationRepo repository.EscalationRepository,
+	webhookSecretRepo repository.WebhookSecretRepository,
 ) *TaskService {
 	return &TaskService{
-		pool:          pool,
-		taskRepo:      taskRepo,
-		eventRepo:     eventRepo,
-		agentRepo:     agentRepo,
-		workspaceRepo: workspaceRepo,
-		validator:     NewValidator(taskRepo),
+		pool:              pool,
+		taskRepo:          taskRepo,
+		eventRepo:         eventRepo,
+		agentRepo:         agentRepo,
+		workspaceRepo:     workspaceRepo,
+		checklistRepo:     checklistRepo,
+		queueRepo:         queueRepo,
+		labelRepo:         labelRepo,
+		escalationRepo:    escalationRepo,
+		webhookSecretRepo: webhookSecretRepo,
+		validator:         NewValidator(taskRepo),
 	}
 }
 
@@ -65,9 +90,53 @@ func validateArtefactURL(artefact string) error {
 	return nil
 }
 
+// validateEventData checks that an optional event payload fits within MaxEventDataBytes.
+func validateEventData(data map[string]any) error {
+	return validatePayloadSize(data, domain.MaxEventDataBytes, domain.ErrEventDataTooLarge)
+}
+
+// validatePayloadSize checks that an optional JSON payload encodes to at most limit bytes.
+func validatePayloadSize(payload map[string]any, limit int, errTooLarge error) error {
+	if len(payload) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	if len(encoded) > limit {
+		return fmt.Errorf("%w: %d bytes, limit is %d", errTooLarge, len(encoded), limit)
+	}
+	return nil
+}
+
+// createEvent persists a task event within the transaction. Events without a trace
+// context get the one of the request that caused them, if any. Status changes and
+// deletions re-evaluate priority inheritance along the task's blocking chain; a
+// move to DONE resolves the dependents it was the last blocker of.
+func (s *TaskService) createEvent(ctx context.Context, tx pgx.Tx, event *domain.TaskEvent) error {
+	if event.Trace == nil {
+		event.Trace = domain.TraceFromContext(ctx)
+	}
+	if err := s.eventRepo.Create(ctx, tx, event); err != nil {
+		return err
+	}
+	if event.NewStatus != nil || event.Type == domain.EventTypeDeleted {
+		if err := s.syncPriorityInheritance(ctx, tx, event.TaskID); err != nil {
+			return err
+		}
+	}
+	if event.NewStatus != nil && *event.NewStatus == domain.TaskStatusDone {
+		if _, err := s.resolveDependents(ctx, tx, event.TaskID); err != nil {
+			return fmt.Errorf("resolve dependents: %w", err)
+		}
+	}
+	return nil
+}
+
 // createEventAndCommit persists a task event within the transaction, then commits.
 func (s *TaskService) createEventAndCommit(ctx context.Context, tx pgx.Tx, event *domain.TaskEvent) error {
-	if err := s.eventRepo.Create(ctx, tx, event); err != nil {
+	if err := s.createEvent(ctx, tx, event); err != nil {
 		return fmt.Errorf("create event: %w", err)
 	}
 	if err := tx.Commit(ctx); err != nil {
@@ -107,6 +176,18 @@ func (s *TaskService) ClaimTask(
 		return nil, err
 	}
 
+	return s.claimLocked(ctx, tx, task, agent, comment, nil)
+}
+
+// claimLocked assigns a task locked in tx to the agent, records the claimed event and commits.
+func (s *TaskService) claimLocked(
+	ctx context.Context,
+	tx pgx.Tx,
+	task *domain.Task,
+	agent *domain.Agent,
+	comment string,
+	data map[string]any,
+) (*domain.TaskEvent, error) {
 	if err := s.validator.CanClaim(task, agent); err != nil {
 		return nil, err
 	}
@@ -120,11 +201,15 @@ func (s *TaskService) ClaimTask(
 		return nil, fmt.Errorf("get workspace: %w", err)
 	}
 
+	if err := s.checkClaimFairness(ctx, workspace, task, agent); err != nil {
+		return nil, err
+	}
+
 	newDeadline := CalculateDeadline(workspace, domain.TaskStatusInProgress)
 
-	err = s.taskRepo.UpdateStatus(ctx, tx, taskID,
+	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
 		domain.TaskStatusNew, domain.TaskStatusInProgress,
-		&agentID, newDeadline, nil,
+		&agent.ID, newDeadline, nil,
 	)
 	if err != nil {
 		return nil, err
@@ -133,12 +218,13 @@ func (s *TaskService) ClaimTask(
 	oldStatus := domain.TaskStatusNew
 	newStatus := domain.TaskStatusInProgress
 	event := &domain.TaskEvent{
-		TaskID:    taskID,
-		ActorID:   &agentID,
+		TaskID:    task.ID,
+		ActorID:   &agent.ID,
 		Type:      domain.EventTypeClaimed,
 		OldStatus: &oldStatus,
 		NewStatus: &newStatus,
 		Comment:   comment,
+		Data:      data,
 	}
 
 	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
@@ -146,8 +232,8 @@ func (s *TaskService) ClaimTask(
 	}
 
 	slog.Info("task claimed",
-		"task_id", taskID,
-		"agent_id", agentID,
+		"task_id", task.ID,
+		"agent_id", agent.ID,
 		"event_id", event.ID,
 	)
 
@@ -204,6 +290,11 @@ func (s *TaskService) EscalateTask(
 		return nil, err
 	}
 
+	route, err := s.matchEscalationRoute(ctx, task, agentID)
+	if err != nil {
+		return nil, err
+	}
+
 	oldStatus := domain.TaskStatusInProgress
 	newStatus := domain.TaskStatusBlocked
 	event := &domain.TaskEvent{
@@ -213,16 +304,42 @@ func (s *TaskService) EscalateTask(
 		OldStatus: &oldStatus,
 		NewStatus: &newStatus,
 		Comment:   comment,
+		Data:      map[string]any{},
+	}
+	// Record whose task was escalated for the escalation stats
+	if task.AssigneeID != nil {
+		event.Data["assignee_id"] = *task.AssigneeID
+	}
+	if route != nil {
+		event.Data["route"] = route.Name
+		if route.TargetType == domain.EscalationTargetAgent {
+			event.Data["routed_to"] = route.Target
+		}
 	}
 
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
-		return nil, err
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return nil, fmt.Errorf("create event: %w", err)
+	}
+
+	if route != nil {
+		if err := s.notifyEscalation(ctx, tx, route, task, event, agent); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
+	routeName := ""
+	if route != nil {
+		routeName = route.Name
+	}
 	slog.Info("task escalated",
 		"task_id", taskID,
 		"agent_id", agentID,
 		"event_id", event.ID,
+		"route", routeName,
 	)
 
 	return event, nil
@@ -292,8 +409,12 @@ func (s *TaskService) TakeoverTask(
 		NewStatus: &newStatus,
 		Comment:   comment,
 	}
+	// Record who lost the task for the takeover stats
+	if task.AssigneeID != nil {
+		event.Data = map[string]any{"previous_assignee_id": *task.AssigneeID}
+	}
 
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+	if err := s.createHandOffAndCommit(ctx, tx, workspace, event); err != nil {
 		return nil, err
 	}
 
@@ -306,28 +427,46 @@ func (s *TaskService) TakeoverTask(
 	return event, nil
 }
 
+// TransitionStatusParams holds parameters for a regular status transition.
+type TransitionStatusParams struct {
+	TaskID    string
+	AgentID   string
+	NewStatus domain.TaskStatus
+	Comment   string
+	Artefact  string         // Required when NewStatus is DONE or NEEDS_REVIEW (optional when approving a review)
+	Result    map[string]any // Optional structured result, stored on the task when NewStatus is DONE or NEEDS_REVIEW
+	Data      map[string]any // Optional structured payload stored on the event
+	// ExpectedVersion, when set, must be the task's current version
+	ExpectedVersion *int
+}
+
 // TransitionStatus implements regular status transitions.
-func (s *TaskService) TransitionStatus(
-	ctx context.Context,
-	taskID string,
-	agentID string,
-	newStatus domain.TaskStatus,
-	comment string,
-	artefact string,
-) (*domain.TaskEvent, error) {
+func (s *TaskService) TransitionStatus(ctx context.Context, params TransitionStatusParams) (*domain.TaskEvent, error) {
+	taskID := params.TaskID
+	agentID := params.AgentID
+	newStatus := params.NewStatus
+	comment := params.Comment
+	artefact := params.Artefact
+
 	if comment == "" {
 		return nil, domain.ErrEmptyComment
 	}
 
+	if err := validateEventData(params.Data); err != nil {
+		return nil, err
+	}
+
 	if !newStatus.IsValid() {
 		return nil, domain.ErrInvalidStatus
 	}
 
-	if newStatus == domain.TaskStatusDone {
-		if artefact == "" {
-			return nil, domain.ErrArtefactRequired
+	if newStatus.RequiresArtefact() {
+		if artefact != "" {
+			if err := validateArtefactURL(artefact); err != nil {
+				return nil, err
+			}
 		}
-		if err := validateArtefactURL(artefact); err != nil {
+		if err := validatePayloadSize(params.Result, domain.MaxTaskResultBytes, domain.ErrTaskResultTooLarge); err != nil {
 			return nil, err
 		}
 	}
@@ -346,6 +485,9 @@ func (s *TaskService) TransitionStatus(
 	if err != nil {
 		return nil, err
 	}
+	if err := task.CheckVersion(params.ExpectedVersion); err != nil {
+		return nil, err
+	}
 
 	oldStatus := task.Status
 
@@ -355,11 +497,22 @@ func (s *TaskService) TransitionStatus(
 	}
 
 	if err := s.validator.CanTransitionStatus(task, agent, newStatus); err != nil {
-		return nil, err
+		return nil, domain.WithDetails(err, map[string]any{
+			"status":           task.Status,
+			"allowed_statuses": s.validator.AllowedStatuses(task, agent),
+		})
+	}
+
+	// Approving a review reuses the artefact submitted with it
+	if newStatus.RequiresArtefact() && artefact == "" &&
+		(oldStatus != domain.TaskStatusNeedsReview || task.Artefact == nil) {
+		return nil, domain.ErrArtefactRequired
 	}
 
-	// When transitioning to IN_PROGRESS, verify blockers are resolved and no cycles exist
-	if newStatus == domain.TaskStatusInProgress {
+	// When transitioning to IN_PROGRESS, verify blockers are resolved and no cycles exist.
+	// A rejected review or an ended external wait returns to work that already passed these checks.
+	if newStatus == domain.TaskStatusInProgress &&
+		oldStatus != domain.TaskStatusNeedsReview && oldStatus != domain.TaskStatusAwaitingExternal {
 		if err := s.validator.CheckBlockedByResolved(ctx, task.BlockedBy); err != nil {
 			return nil, err
 		}
@@ -373,6 +526,38 @@ func (s *TaskService) TransitionStatus(
 		return nil, fmt.Errorf("get workspace: %w", err)
 	}
 
+	// A workspace may gate completions on an external check, e.g. CI
+	if newStatus == domain.TaskStatusDone && workspace.DoneValidation != nil {
+		request := &client.DoneValidationRequest{
+			WorkspaceID:    task.WorkspaceID,
+			TaskID:         task.ID,
+			Title:          task.Title,
+			Labels:         task.Labels,
+			AgentID:        agentID,
+			AssigneeID:     task.AssigneeID,
+			PreviousStatus: client.TaskStatus(oldStatus),
+			Artefact:       artefact,
+			Result:         params.Result,
+			Comment:        comment,
+		}
+		if artefact == "" && task.Artefact != nil {
+			request.Artefact = *task.Artefact
+		}
+		if len(request.Result) == 0 {
+			request.Result = task.Result
+		}
+
+		outcome, err := s.validateDone(ctx, workspace.DoneValidation, request)
+		if err != nil {
+			return nil, err
+		}
+		params.Data = maps.Clone(params.Data)
+		if params.Data == nil {
+			params.Data = make(map[string]any, 1)
+		}
+		params.Data["done_validation"] = outcome
+	}
+
 	newDeadline := CalculateDeadline(workspace, newStatus)
 
 	// Transitioning to NEW returns the task to the pool by clearing the assignee
@@ -382,7 +567,7 @@ func (s *TaskService) TransitionStatus(
 	}
 
 	var artefactPtr *string
-	if newStatus == domain.TaskStatusDone && artefact != "" {
+	if newStatus.RequiresArtefact() && artefact != "" {
 		artefactPtr = &artefact
 	}
 
@@ -394,16 +579,45 @@ func (s *TaskService) TransitionStatus(
 		return nil, err
 	}
 
+	if newStatus.RequiresArtefact() && len(params.Result) > 0 {
+		if err := s.taskRepo.SetResult(ctx, tx, taskID, params.Result); err != nil {
+			return nil, err
+		}
+	}
+
+	if oldStatus == domain.TaskStatusAwaitingExternal {
+		if err := s.taskRepo.SetExternalRef(ctx, tx, taskID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	eventType := domain.EventTypeStatusChanged
+	if oldStatus == domain.TaskStatusNeedsReview {
+		switch newStatus {
+		case domain.TaskStatusDone:
+			eventType = domain.EventTypeReviewApproved
+		case domain.TaskStatusInProgress:
+			eventType = domain.EventTypeReviewRejected
+		}
+	}
+
 	event := &domain.TaskEvent{
 		TaskID:    taskID,
 		ActorID:   &agentID,
-		Type:      domain.EventTypeStatusChanged,
+		Type:      eventType,
 		OldStatus: &oldStatus,
 		NewStatus: &newStatus,
 		Comment:   comment,
+		Data:      params.Data,
 	}
 
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+	// Giving a task back to the pool counts as an attempt
+	if newStatus == domain.TaskStatusNew {
+		err = s.createHandOffAndCommit(ctx, tx, workspace, event)
+	} else {
+		err = s.createEventAndCommit(ctx, tx, event)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -418,36 +632,52 @@ func (s *TaskService) TransitionStatus(
 	return event, nil
 }
 
-// ProcessExpiredDeadlines finds and processes all tasks with expired deadlines.
+// expiredDeadlineBatchSize bounds each scan of ProcessExpiredDeadlines.
+const expiredDeadlineBatchSize = 500
+
+// ProcessExpiredDeadlines finds and processes all tasks with expired deadlines,
+// in batches of expiredDeadlineBatchSize.
 // Returns the number of tasks successfully updated, and an error if any tasks failed.
 func (s *TaskService) ProcessExpiredDeadlines(ctx context.Context) (int, error) {
-	tasks, err := s.taskRepo.FindExpiredDeadlines(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("find expired tasks: %w", err)
+	total := 0
+	count := 0
+	var errs []error // Accumulate errors
+	var after *repository.DeadlineCursor
+	for {
+		tasks, err := s.taskRepo.FindExpiredDeadlines(ctx, after, expiredDeadlineBatchSize)
+		if err != nil {
+			return count, fmt.Errorf("find expired tasks: %w", err)
+		}
+
+		for _, task := range tasks {
+			if err := s.processExpiredTask(ctx, task); err != nil {
+				slog.Error("failed to process expired task",
+					"task_id", task.ID,
+					"error", err,
+				)
+				errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
+				continue
+			}
+			count++
+		}
+		total += len(tasks)
+
+		if len(tasks) < expiredDeadlineBatchSize {
+			break
+		}
+		// Failed tasks still match; the cursor moves past them
+		last := tasks[len(tasks)-1]
+		after = &repository.DeadlineCursor{DeadlineAt: *last.StatusDeadlineAt, ID: last.ID}
 	}
 
-	if len(tasks) == 0 {
+	if total == 0 {
 		slog.Info("no expired deadlines found")
 		return 0, nil
 	}
 
-	count := 0
-	var errs []error // Accumulate errors
-	for _, task := range tasks {
-		if err := s.processExpiredTask(ctx, task); err != nil {
-			slog.Error("failed to process expired task",
-				"task_id", task.ID,
-				"error", err,
-			)
-			errs = append(errs, fmt.Errorf("task %s: %w", task.ID, err))
-			continue
-		}
-		count++
-	}
-
-	failedCount := len(tasks) - count
+	failedCount := total - count
 	slog.Info("processed expired deadlines",
-		"total", len(tasks),
+		"total", total,
 		"successful", count,
 		"failed", failedCount,
 	)
@@ -455,13 +685,15 @@ func (s *TaskService) ProcessExpiredDeadlines(ctx context.Context) (int, error)
 	// Return error if there were failures
 	if len(errs) > 0 {
 		return count, fmt.Errorf("processed %d/%d tasks, %d failures: %v",
-			count, len(tasks), failedCount, errs)
+			count, total, failedCount, errs)
 	}
 
 	return count, nil
 }
 
-// processExpiredTask transitions a single task to STUCK status.
+// processExpiredTask moves a single task to the status its workspace configured
+// for an expired deadline in its current status, STUCK by default. A task moved
+// back to NEW returns to the pool with a fresh NEW deadline.
 func (s *TaskService) processExpiredTask(ctx context.Context, task *domain.Task) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -473,11 +705,22 @@ func (s *TaskService) processExpiredTask(ctx context.Context, task *domain.Task)
 		}
 	}()
 
+	workspace, err := s.workspaceRepo.GetByID(ctx, task.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("get workspace: %w", err)
+	}
+
 	oldStatus := task.Status
+	newStatus := workspace.ExpiryStatus(oldStatus)
+
+	newAssignee := task.AssigneeID
+	if ShouldClearAssignee(newStatus) {
+		newAssignee = nil
+	}
 
 	err = s.taskRepo.UpdateStatus(ctx, tx, task.ID,
-		oldStatus, domain.TaskStatusStuck,
-		task.AssigneeID, nil, nil,
+		oldStatus, newStatus,
+		newAssignee, CalculateDeadline(workspace, newStatus), nil,
 	)
 	if err != nil {
 		return fmt.Errorf("update status: %w", err)
@@ -488,23 +731,33 @@ func (s *TaskService) processExpiredTask(ctx context.Context, task *domain.Task)
 		durationMinutes = int(task.StatusDeadlineAt.Sub(task.UpdatedAt).Minutes())
 	}
 
-	newStatus := domain.TaskStatusStuck
+	comment := fmt.Sprintf("Status deadline expired. Was in %s for %d minutes.", oldStatus, durationMinutes)
+	if newStatus != domain.TaskStatusStuck {
+		comment += fmt.Sprintf(" Moved to %s as configured for the workspace.", newStatus)
+	}
+
 	event := &domain.TaskEvent{
 		TaskID:    task.ID,
 		ActorID:   nil, // system event
 		Type:      domain.EventTypeDeadlineExpired,
 		OldStatus: &oldStatus,
 		NewStatus: &newStatus,
-		Comment:   fmt.Sprintf("Status deadline expired. Was in %s for %d minutes.", oldStatus, durationMinutes),
+		Comment:   comment,
 	}
 
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+	if newStatus == domain.TaskStatusNew {
+		err = s.createHandOffAndCommit(ctx, tx, workspace, event)
+	} else {
+		err = s.createEventAndCommit(ctx, tx, event)
+	}
+	if err != nil {
 		return err
 	}
 
 	slog.Info("task deadline expired",
 		"task_id", task.ID,
 		"old_status", oldStatus,
+		"new_status", newStatus,
 		"duration_minutes", durationMinutes,
 	)
 
@@ -513,20 +766,58 @@ func (s *TaskService) processExpiredTask(ctx context.Context, task *domain.Task)
 
 // CreateTaskParams holds parameters for creating a new task.
 type CreateTaskParams struct {
-	WorkspaceID string
-	CreatorID   string
-	Title       string
-	Description string
-	AssigneeID  *string
-	Visibility  domain.TaskVisibility
-	Priority    domain.TaskPriority
-	BlockedBy   []string
+	WorkspaceID          string
+	CreatorID            string
+	Title                string
+	Description          string
+	AssigneeID           *string
+	Visibility           domain.TaskVisibility
+	Priority             domain.TaskPriority
+	BlockedBy            []string
+	RequiredCapabilities []string
+	Queue                *string  // Optional: queue name, must exist in the workspace
+	Labels               []string // Optional: names of labels registered in the workspace
+	RequiresApproval     bool     // Optional: only an operator may complete the task
+	ScheduleID           *string  // Set when a schedule creates the task; recorded on the created event
+	// Set when the task comes from an intake form; its source and contact are recorded on the created event
+	Intake *domain.IntakeSubmission
 }
 
 // CreateTask creates a new task with the given parameters.
 // If AssigneeID is provided, the task is created in IN_PROGRESS status automatically.
 // Otherwise, it's created in NEW status.
 func (s *TaskService) CreateTask(ctx context.Context, params CreateTaskParams) (*domain.Task, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.createTaskInTx(ctx, tx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("task created",
+		"task_id", task.ID,
+		"creator_id", params.CreatorID,
+		"status", task.Status,
+		"assignee_id", params.AssigneeID,
+	)
+
+	return task, nil
+}
+
+// createTaskInTx validates params and inserts the task with its created event within tx.
+func (s *TaskService) createTaskInTx(ctx context.Context, tx pgx.Tx, params CreateTaskParams) (*domain.Task, error) {
 	// Validate required fields
 	if params.Title == "" {
 		return nil, fmt.Errorf("title is required")
@@ -538,12 +829,30 @@ func (s *TaskService) CreateTask(ctx context.Context, params CreateTaskParams) (
 		return nil, fmt.Errorf("description is required")
 	}
 
+	requiredCapabilities, err := domain.NormalizeCapabilities(params.RequiredCapabilities)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate creator exists and is active
 	creator, err := s.getActiveAgent(ctx, params.CreatorID)
 	if err != nil {
 		return nil, fmt.Errorf("validate creator: %w", err)
 	}
 
+	var queue *string
+	if params.Queue != nil {
+		queue, err = s.resolveQueue(ctx, creator.WorkspaceID, *params.Queue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	labels, err := s.resolveLabels(ctx, tx, creator.WorkspaceID, params.Labels)
+	if err != nil {
+		return nil, err
+	}
+
 	// If assignee is provided, validate they exist, are active, and in same workspace
 	if params.AssigneeID != nil {
 		assignee, err := s.getActiveAgent(ctx, *params.AssigneeID)
@@ -553,40 +862,15 @@ func (s *TaskService) CreateTask(ctx context.Context, params CreateTaskParams) (
 		if assignee.WorkspaceID != creator.WorkspaceID {
 			return nil, fmt.Errorf("%w: assignee must be in same workspace", domain.ErrPermissionDenied)
 		}
-	}
-
-	// Validate all blocker tasks exist and are in the same workspace
-	if len(params.BlockedBy) > 0 {
-		blockers, err := s.taskRepo.GetBlockedByTasks(ctx, params.BlockedBy)
-		if err != nil {
-			return nil, fmt.Errorf("validate blockers: %w", err)
-		}
-
-		// Check that all blocker IDs were found
-		if len(blockers) != len(params.BlockedBy) {
-			foundIDs := make(map[string]bool)
-			for _, b := range blockers {
-				foundIDs[b.ID] = true
-			}
-			var missingIDs []string
-			for _, id := range params.BlockedBy {
-				if !foundIDs[id] {
-					missingIDs = append(missingIDs, id)
-				}
-			}
-			return nil, fmt.Errorf("%w: blocker tasks not found: %v", domain.ErrTaskNotFound, missingIDs)
-		}
-
-		// Check that all blockers are in the same workspace
-		for _, blocker := range blockers {
-			if blocker.WorkspaceID != creator.WorkspaceID {
-				return nil, fmt.Errorf("%w: blocker task %s is not in the same workspace", domain.ErrPermissionDenied, blocker.ID)
-			}
+		if !assignee.HasCapabilities(requiredCapabilities) {
+			return nil, fmt.Errorf("%w: assignee %s lacks %v", domain.ErrMissingCapabilities, assignee.ID, requiredCapabilities)
 		}
 	}
 
-	// Note: Cyclic dependency check is performed when task transitions to IN_PROGRESS,
-	// not at creation time. This allows flexible dependency management.
+	blockedBy, err := s.validateNewBlockers(ctx, creator.WorkspaceID, params.BlockedBy)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine initial status: IN_PROGRESS if assignee provided, otherwise NEW
 	initialStatus := domain.TaskStatusNew
@@ -603,29 +887,22 @@ func (s *TaskService) CreateTask(ctx context.Context, params CreateTaskParams) (
 	// Calculate deadline
 	deadline := CalculateDeadline(workspace, initialStatus)
 
-	// Begin transaction
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("begin transaction: %w", err)
-	}
-	defer func() {
-		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
-			slog.Error("failed to rollback transaction", "error", err)
-		}
-	}()
-
 	// Create task in repository
 	task, err := s.taskRepo.Create(ctx, tx, &domain.Task{
-		WorkspaceID:      params.WorkspaceID,
-		Title:            params.Title,
-		Description:      params.Description,
-		CreatorID:        params.CreatorID,
-		AssigneeID:       params.AssigneeID,
-		Status:           initialStatus,
-		Visibility:       params.Visibility,
-		Priority:         params.Priority,
-		BlockedBy:        params.BlockedBy,
-		StatusDeadlineAt: deadline,
+		WorkspaceID:          params.WorkspaceID,
+		Title:                params.Title,
+		Description:          params.Description,
+		CreatorID:            params.CreatorID,
+		AssigneeID:           params.AssigneeID,
+		Status:               initialStatus,
+		Visibility:           params.Visibility,
+		Priority:             params.Priority,
+		BlockedBy:            blockedBy,
+		StatusDeadlineAt:     deadline,
+		RequiredCapabilities: requiredCapabilities,
+		Queue:                queue,
+		Labels:               labels,
+		RequiresApproval:     params.RequiresApproval,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create task: %w", err)
@@ -640,27 +917,106 @@ func (s *TaskService) CreateTask(ctx context.Context, params CreateTaskParams) (
 		NewStatus: &initialStatus,
 		Comment:   "Task created",
 	}
-
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
-		return nil, err
+	data := map[string]any{}
+	if params.ScheduleID != nil {
+		data["schedule_id"] = *params.ScheduleID
+	}
+	if params.Intake != nil {
+		data["source"] = intakeSource
+		if params.Intake.Contact != "" {
+			data["contact"] = params.Intake.Contact
+		}
+	}
+	if params.AssigneeID != nil {
+		data["assignee_id"] = *params.AssigneeID
+	}
+	if params.RequiresApproval {
+		data["requires_approval"] = true
+	}
+	if len(data) > 0 {
+		event.Data = data
 	}
 
-	slog.Info("task created",
-		"task_id", task.ID,
-		"creator_id", params.CreatorID,
-		"status", initialStatus,
-		"assignee_id", params.AssigneeID,
-	)
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return nil, fmt.Errorf("create event: %w", err)
+	}
 
 	return task, nil
 }
 
+// validateNewBlockers checks the blocked_by list of a new task and returns it
+// without duplicates. Every blocker must exist in the workspace; one in
+// another workspace is reported as missing, so its existence isn't revealed.
+// A new task can't close a cycle itself (nothing depends on it yet and
+// blocked_by is immutable), but its blockers' chains are walked so a cycle or
+// an over-deep chain already in the graph is rejected now rather than when
+// the task is claimed.
+func (s *TaskService) validateNewBlockers(ctx context.Context, workspaceID string, blockedBy []string) ([]string, error) {
+	if len(blockedBy) == 0 {
+		return blockedBy, nil
+	}
+
+	unique := make([]string, 0, len(blockedBy))
+	seen := make(map[string]bool, len(blockedBy))
+	for _, id := range blockedBy {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	blockers, err := s.taskRepo.GetBlockedByTasks(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("validate blockers: %w", err)
+	}
+	found := make(map[string]bool, len(blockers))
+	for _, blocker := range blockers {
+		if blocker.WorkspaceID == workspaceID {
+			found[blocker.ID] = true
+		}
+	}
+	var missing []string
+	for _, id := range unique {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, domain.WithDetails(
+			fmt.Errorf("%w: blocker tasks not found: %v", domain.ErrValidation, missing),
+			map[string]any{"missing_blockers": missing},
+		)
+	}
+
+	visited := make(map[string]bool)
+	recStack := make(map[string]bool)
+	for _, id := range unique {
+		if visited[id] {
+			continue
+		}
+		// Depth 1: the new task itself is the root of the chain
+		if err := s.validator.checkCyclicDependencyWithDepth(ctx, id, visited, recStack, 1); err != nil {
+			return nil, fmt.Errorf("validate blockers: %w", err)
+		}
+	}
+	return unique, nil
+}
+
 // CommentTask adds a comment to a task without changing status.
-func (s *TaskService) CommentTask(ctx context.Context, taskID, agentID, comment string) (*domain.TaskEvent, error) {
+// The optional data payload is stored on the event as structured context.
+// A non-nil replyTo makes the comment a reply to another comment of the task.
+func (s *TaskService) CommentTask(ctx context.Context, taskID, agentID, comment string, data map[string]any, replyTo *string) (*domain.TaskEvent, error) {
 	if comment == "" {
 		return nil, domain.ErrEmptyComment
 	}
 
+	if err := validateEventData(data); err != nil {
+		return nil, err
+	}
+	if _, ok := data[MentionsDataKey]; ok {
+		return nil, fmt.Errorf("%w: data.%s is reserved for the agents mentioned in the comment", domain.ErrValidation, MentionsDataKey)
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
@@ -694,22 +1050,61 @@ func (s *TaskService) CommentTask(ctx context.Context, taskID, agentID, comment
 		}
 	}
 
+	var threadID *string
+	if replyTo != nil {
+		parent, err := s.eventRepo.GetComment(ctx, tx, task.ID, *replyTo)
+		if err != nil {
+			if errors.Is(err, domain.ErrCommentNotFound) {
+				return nil, fmt.Errorf("%w: reply_to_event_id must be a comment of this task", domain.ErrValidation)
+			}
+			return nil, fmt.Errorf("get replied comment: %w", err)
+		}
+		// Replies to replies stay in the thread of the comment that started it
+		threadID = &parent.ID
+		if parent.ThreadID != nil {
+			threadID = parent.ThreadID
+		}
+	}
+
+	mentions, err := s.resolveMentions(ctx, task, agent, comment)
+	if err != nil {
+		return nil, err
+	}
+	if len(mentions) > 0 {
+		data = maps.Clone(data)
+		if data == nil {
+			data = map[string]any{}
+		}
+		data[MentionsDataKey] = mentions
+	}
+
 	// Create comment event
 	event := &domain.TaskEvent{
 		TaskID:  taskID,
 		ActorID: &agentID,
 		Type:    domain.EventTypeCommented,
 		Comment: comment,
+		Data:    data,
+
+		ReplyToEventID: replyTo,
+		ThreadID:       threadID,
 	}
 
-	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+	if err := s.createEvent(ctx, tx, event); err != nil {
+		return nil, fmt.Errorf("create event: %w", err)
+	}
+	if err := s.eventRepo.CreateMentions(ctx, tx, event.ID, mentions); err != nil {
 		return nil, err
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
 
 	slog.Info("comment added",
 		"task_id", taskID,
 		"agent_id", agentID,
 		"event_id", event.ID,
+		"mentions", len(mentions),
 	)
 
 	return event, nil
@@ -2,7 +2,12 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,6 +17,8 @@ import (
 	"github.com/mtlprog/sloptask/internal/domain"
 	"github.com/mtlprog/sloptask/internal/repository"
 	"github.com/mtlprog/sloptask/internal/service"
+	"github.com/mtlprog/sloptask/internal/testutil/factory"
+	"github.com/mtlprog/sloptask/pkg/client"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -20,10 +27,10 @@ type TaskServiceTestSuite struct {
 	suite.Suite
 	pool          *pgxpool.Pool
 	taskService   *service.TaskService
-	taskRepo      *repository.TaskRepository
-	eventRepo     *repository.TaskEventRepository
-	agentRepo     *repository.AgentRepository
-	workspaceRepo *repository.WorkspaceRepository
+	taskRepo      repository.TaskRepository
+	eventRepo     repository.TaskEventRepository
+	agentRepo     repository.AgentRepository
+	workspaceRepo repository.WorkspaceRepository
 
 	// Test fixtures
 	workspaceID string
@@ -42,7 +49,7 @@ func (s *TaskServiceTestSuite) SetupSuite() {
 	ctx := context.Background()
 
 	// Connect to database
-	db, err := database.New(ctx, databaseURL)
+	db, err := database.New(ctx, databaseURL, database.DefaultPoolConfig)
 	s.Require().NoError(err, "failed to connect to database")
 
 	s.pool = db.Pool()
@@ -64,6 +71,11 @@ func (s *TaskServiceTestSuite) SetupSuite() {
 		s.eventRepo,
 		s.agentRepo,
 		s.workspaceRepo,
+		repository.NewChecklistRepository(s.pool),
+		repository.NewQueueRepository(s.pool),
+		repository.NewLabelRepository(s.pool),
+		repository.NewEscalationRepository(s.pool),
+		repository.NewWebhookSecretRepository(s.pool),
 	)
 }
 
@@ -76,24 +88,23 @@ func (s *TaskServiceTestSuite) SetupTest() {
 	s.Require().NoError(err, "failed to truncate tables")
 
 	// Create test workspace (same as seed data)
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO workspaces (id, name, slug, status_deadlines)
-		VALUES ('00000000-0000-0000-0000-000000000001', 'Test Workspace', 'test',
-				'{"NEW": 120, "IN_PROGRESS": 1440, "BLOCKED": 2880}'::jsonb)
-	`)
-	s.Require().NoError(err, "failed to create workspace")
-	s.workspaceID = "00000000-0000-0000-0000-000000000001"
+	workspace := factory.CreateWorkspace(s.T(), s.pool,
+		factory.WithWorkspaceID("00000000-0000-0000-0000-000000000001"),
+		factory.WithSlug("test"),
+	)
+	s.workspaceID = workspace.ID
 
 	// Create test agents
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO agents (id, workspace_id, name, token, is_active)
-		VALUES
-			('00000000-0000-0000-0000-000000000011', $1, 'agent-1', 'token-1', true),
-			('00000000-0000-0000-0000-000000000012', $1, 'agent-2', 'token-2', true)
-	`, s.workspaceID)
-	s.Require().NoError(err, "failed to create agents")
-	s.agent1ID = "00000000-0000-0000-0000-000000000011"
-	s.agent2ID = "00000000-0000-0000-0000-000000000012"
+	s.agent1ID = factory.CreateAgent(s.T(), s.pool, s.workspaceID,
+		factory.WithAgentID("00000000-0000-0000-0000-000000000011"),
+		factory.WithAgentName("agent-1"),
+		factory.WithToken("token-1"),
+	).ID
+	s.agent2ID = factory.CreateAgent(s.T(), s.pool, s.workspaceID,
+		factory.WithAgentID("00000000-0000-0000-0000-000000000012"),
+		factory.WithAgentName("agent-2"),
+		factory.WithToken("token-2"),
+	).ID
 }
 
 // TearDownSuite runs once after all tests.
@@ -261,7 +272,13 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_NewToDone_ShouldFail() {
 	taskID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
 
 	// Artefact validation passes (valid URL provided), but state machine rejects NEW → DONE.
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusDone, "Invalid transition", "https://github.com/example")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Invalid transition",
+		Artefact:  "https://github.com/example",
+	})
 	s.Error(err)
 	s.ErrorIs(err, domain.ErrInvalidTransition)
 }
@@ -275,7 +292,13 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_InProgressToDone_Success() {
 
 	// Agent1 completes the task with artefact
 	artefactURL := "https://github.com/example/result"
-	event, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusDone, "Task completed", artefactURL)
+	event, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Task completed",
+		Artefact:  artefactURL,
+	})
 	s.Require().NoError(err)
 	s.NotNil(event)
 	s.Equal(domain.EventTypeStatusChanged, event.Type)
@@ -295,7 +318,12 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_InProgressToDone_MissingArte
 
 	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
 
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusDone, "Done", "")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Done",
+	})
 	s.Error(err)
 	s.ErrorIs(err, domain.ErrArtefactRequired)
 }
@@ -306,7 +334,13 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_InProgressToDone_InvalidArte
 
 	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
 
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusDone, "Done", "not-a-url")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Done",
+		Artefact:  "not-a-url",
+	})
 	s.Error(err)
 	s.ErrorIs(err, domain.ErrInvalidArtefactURL)
 }
@@ -317,7 +351,13 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_InProgressToDone_FtpArtefact
 
 	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
 
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusDone, "Done", "ftp://example.com/result")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Done",
+		Artefact:  "ftp://example.com/result",
+	})
 	s.Error(err)
 	s.ErrorIs(err, domain.ErrInvalidArtefactURL)
 }
@@ -329,7 +369,13 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_NonDone_ArtefactIgnored() {
 	// STUCK → IN_PROGRESS by the owner is a valid non-DONE transition
 	taskID := s.createTask(ctx, domain.TaskStatusStuck, &s.agent1ID, nil)
 
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID, domain.TaskStatusInProgress, "Resuming work", "https://example.com/result")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Resuming work",
+		Artefact:  "https://example.com/result",
+	})
 	s.Require().NoError(err)
 
 	// Artefact must not be written for non-DONE transitions
@@ -364,14 +410,43 @@ func (s *TaskServiceTestSuite) TestProcessExpiredDeadlines() {
 	s.Nil(events[1].ActorID) // System event
 }
 
+// TestFindExpiredDeadlines_Batches tests that the deadline scan is bounded and
+// resumes after its cursor.
+func (s *TaskServiceTestSuite) TestFindExpiredDeadlines_Batches() {
+	ctx := context.Background()
+	now := time.Now()
+	oldestID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatusDeadline(now.Add(-3*time.Hour))).ID
+	olderID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatusDeadline(now.Add(-2*time.Hour))).ID
+	oldID := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatusDeadline(now.Add(-time.Hour))).ID
+	factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithStatusDeadline(now.Add(time.Hour)))
+
+	first, err := s.taskRepo.FindExpiredDeadlines(ctx, nil, 2)
+	s.Require().NoError(err)
+	s.Require().Len(first, 2)
+	s.Equal([]string{oldestID, olderID}, []string{first[0].ID, first[1].ID})
+
+	last := first[1]
+	rest, err := s.taskRepo.FindExpiredDeadlines(ctx, &repository.DeadlineCursor{DeadlineAt: *last.StatusDeadlineAt, ID: last.ID}, 2)
+	s.Require().NoError(err)
+	s.Require().Len(rest, 1)
+	s.Equal(oldID, rest[0].ID)
+}
+
 // TestTransitionStatus_StuckToInProgress_ByNonOwner_ShouldFail tests STUCK bypass protection.
 func (s *TaskServiceTestSuite) TestTransitionStatus_StuckToInProgress_ByNonOwner_ShouldFail() {
 	ctx := context.Background()
 	taskID := s.createTask(ctx, domain.TaskStatusStuck, &s.agent1ID, nil)
 
 	// Agent2 tries to resume agent1's task (should fail)
-	_, err := s.taskService.TransitionStatus(ctx, taskID, s.agent2ID,
-		domain.TaskStatusInProgress, "Trying to resume", "")
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Trying to resume",
+	})
 	s.Error(err)
 	s.ErrorIs(err, domain.ErrPermissionDenied)
 }
@@ -382,8 +457,12 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_StuckToInProgress_ByOwner_Su
 	taskID := s.createTask(ctx, domain.TaskStatusStuck, &s.agent1ID, nil)
 
 	// Agent1 resumes their own task (should succeed)
-	event, err := s.taskService.TransitionStatus(ctx, taskID, s.agent1ID,
-		domain.TaskStatusInProgress, "Resuming work", "")
+	event, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Resuming work",
+	})
 	s.Require().NoError(err)
 	s.NotNil(event)
 }
@@ -398,58 +477,2324 @@ func (s *TaskServiceTestSuite) TestTransitionStatus_StuckToInProgress_ByOwner_Su
 // Error accumulation logic is still present in ProcessExpiredDeadlines and can
 // be verified through manual testing or integration tests with mocked dependencies.
 
-// Helper: createTask creates a test task.
-func (s *TaskServiceTestSuite) createTask(
-	ctx context.Context,
-	status domain.TaskStatus,
-	assigneeID *string,
-	blockedBy []string,
-) string {
-	if blockedBy == nil {
-		blockedBy = []string{}
+// TestCommentTask_WithData tests that structured data is stored on the event.
+func (s *TaskServiceTestSuite) TestCommentTask_WithData() {
+	ctx := context.Background()
+	taskID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+
+	data := map[string]any{"tool": "pytest", "passed": float64(42)}
+	event, err := s.taskService.CommentTask(ctx, taskID, s.agent2ID, "Test run finished", data, nil)
+	s.Require().NoError(err)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+	s.Equal(event.ID, events[1].ID)
+	s.Equal(data, events[1].Data)
+	s.Nil(events[0].Data)
+}
+
+// TestGetLineage tests ancestry and descendant trees over blocked_by.
+func (s *TaskServiceTestSuite) TestGetLineage() {
+	ctx := context.Background()
+
+	// root <- middle <- leaf (leaf is blocked by middle, middle by root)
+	rootID := s.createTask(ctx, domain.TaskStatusDone, &s.agent1ID, nil)
+	middleID := s.createTask(ctx, domain.TaskStatusNew, nil, []string{rootID})
+	leafID := s.createTask(ctx, domain.TaskStatusNew, nil, []string{middleID})
+
+	lineage, err := s.taskService.GetLineage(ctx, middleID, s.agent2ID)
+	s.Require().NoError(err)
+	s.Equal(middleID, lineage.Task.ID)
+
+	s.Require().Len(lineage.Ancestors, 1)
+	s.Equal(rootID, lineage.Ancestors[0].Task.ID)
+	s.Equal(domain.LineageRelationBlocks, lineage.Ancestors[0].Relation)
+
+	s.Require().Len(lineage.Descendants, 1)
+	s.Equal(leafID, lineage.Descendants[0].Task.ID)
+	s.Equal(domain.LineageRelationBlockedBy, lineage.Descendants[0].Relation)
+
+	// From the root, the leaf is a grandchild
+	lineage, err = s.taskService.GetLineage(ctx, rootID, s.agent2ID)
+	s.Require().NoError(err)
+	s.Require().Len(lineage.Descendants, 1)
+	s.Require().Len(lineage.Descendants[0].Children, 1)
+	s.Equal(leafID, lineage.Descendants[0].Children[0].Task.ID)
+}
+
+// TestTransitionStatus_DoneWithResult tests that the structured result is stored on the task.
+func (s *TaskServiceTestSuite) TestTransitionStatus_DoneWithResult() {
+	ctx := context.Background()
+	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+
+	result := map[string]any{"pr": "https://github.com/example/pr/42", "files_changed": float64(3)}
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Completed",
+		Artefact:  "https://github.com/example/pr/42",
+		Result:    result,
+	})
+	s.Require().NoError(err)
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(result, task.Result)
+}
+
+// TestTransitionStatus_ReviewWorkflow tests submit, reject and approve through NEEDS_REVIEW.
+func (s *TaskServiceTestSuite) TestTransitionStatus_ReviewWorkflow() {
+	ctx := context.Background()
+
+	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	artefactURL := "https://github.com/example/pr/7"
+
+	// Submitting for review requires an artefact
+	_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusNeedsReview,
+		Comment:   "Ready for review",
+	})
+	s.ErrorIs(err, domain.ErrArtefactRequired)
+
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusNeedsReview,
+		Comment:   "Ready for review",
+		Artefact:  artefactURL,
+	})
+	s.Require().NoError(err)
+
+	// Assignee cannot approve own work
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "LGTM",
+	})
+	s.ErrorIs(err, domain.ErrPermissionDenied)
+
+	// Reviewer rejects: back to IN_PROGRESS, assignee kept
+	event, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Missing tests",
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeReviewRejected, event.Type)
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, task.Status)
+	s.True(task.IsOwnedBy(s.agent1ID))
+
+	// Resubmit and approve without repeating the artefact
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusNeedsReview,
+		Comment:   "Tests added",
+		Artefact:  artefactURL,
+	})
+	s.Require().NoError(err)
+
+	event, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "LGTM",
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeReviewApproved, event.Type)
+
+	task, err = s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusDone, task.Status)
+	s.Require().NotNil(task.Artefact)
+	s.Equal(artefactURL, *task.Artefact)
+}
+
+// TestChecklist_HelperClaimsAndCompletesItem tests sub-claims on checklist items.
+func (s *TaskServiceTestSuite) TestChecklist_HelperClaimsAndCompletesItem() {
+	ctx := context.Background()
+
+	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+
+	// Helpers cannot add items to someone else's task
+	_, err := s.taskService.AddChecklistItem(ctx, taskID, s.agent2ID, "Write docs")
+	s.ErrorIs(err, domain.ErrPermissionDenied)
+
+	docs, err := s.taskService.AddChecklistItem(ctx, taskID, s.agent1ID, "Write docs")
+	s.Require().NoError(err)
+	s.Equal(1, docs.Position)
+
+	tests, err := s.taskService.AddChecklistItem(ctx, taskID, s.agent1ID, "Write tests")
+	s.Require().NoError(err)
+	s.Equal(2, tests.Position)
+
+	event, err := s.taskService.ClaimChecklistItem(ctx, service.ChecklistItemParams{
+		TaskID:  taskID,
+		ItemID:  docs.ID,
+		AgentID: s.agent2ID,
+		Comment: "I'll take the docs",
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeChecklistClaimed, event.Type)
+	s.Equal(docs.ID, event.Data["checklist_item_id"])
+
+	_, err = s.taskService.ClaimChecklistItem(ctx, service.ChecklistItemParams{
+		TaskID:  taskID,
+		ItemID:  docs.ID,
+		AgentID: s.agent1ID,
+		Comment: "Mine",
+	})
+	s.ErrorIs(err, domain.ErrChecklistItemClaimed)
+
+	_, err = s.taskService.CompleteChecklistItem(ctx, service.ChecklistItemParams{
+		TaskID:  taskID,
+		ItemID:  docs.ID,
+		AgentID: s.agent2ID,
+		Comment: "Docs written",
+	})
+	s.Require().NoError(err)
+
+	// Task stays with its assignee
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, task.Status)
+	s.True(task.IsOwnedBy(s.agent1ID))
+
+	items, err := s.taskService.GetChecklist(ctx, taskID)
+	s.Require().NoError(err)
+	progress := domain.NewChecklistProgress(items)
+	s.Equal(2, progress.Total)
+	s.Equal(1, progress.Completed)
+}
+
+// TestReopenTask_BlocksInProgressDependents tests reopening DONE work and re-blocking dependents.
+func (s *TaskServiceTestSuite) TestReopenTask_BlocksInProgressDependents() {
+	ctx := context.Background()
+
+	blockerID := s.createTask(ctx, domain.TaskStatusDone, &s.agent2ID, nil)
+	dependentID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, []string{blockerID})
+
+	// Only the creator can reopen
+	_, err := s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    blockerID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Broken",
+	})
+	s.ErrorIs(err, domain.ErrNotTaskCreator)
+
+	event, err := s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    blockerID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Output is wrong, please fix",
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeReopened, event.Type)
+
+	blocker, err := s.taskRepo.GetByID(ctx, blockerID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, blocker.Status)
+	s.True(blocker.IsOwnedBy(s.agent2ID))
+
+	dependent, err := s.taskRepo.GetByID(ctx, dependentID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusBlocked, dependent.Status)
+
+	// No longer DONE, so it cannot be reopened again
+	_, err = s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    blockerID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusNew,
+		Comment:   "Again",
+	})
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+}
+
+// TestTransitionStatus_DoneResolvesDependents tests blockers_resolved events and auto-unblock.
+func (s *TaskServiceTestSuite) TestTransitionStatus_DoneResolvesDependents() {
+	ctx := context.Background()
+	s.Require().NoError(s.workspaceRepo.SetAutoUnblock(ctx, s.workspaceID, true))
+
+	blockerID := s.createTask(ctx, domain.TaskStatusDone, &s.agent2ID, nil)
+	otherBlockerID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+	dependentID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, []string{blockerID})
+	waitingID := s.createTask(ctx, domain.TaskStatusNew, nil, []string{blockerID, otherBlockerID})
+
+	// Reopening the blocker blocks the IN_PROGRESS dependent
+	_, err := s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    blockerID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusInProgress,
+		Comment:   "Output is wrong, please fix",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    blockerID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusDone,
+		Comment:   "Fixed",
+		Artefact:  "https://github.com/example/fix",
+	})
+	s.Require().NoError(err)
+
+	dependent, err := s.taskRepo.GetByID(ctx, dependentID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, dependent.Status, "back to the status it was blocked from")
+	s.True(dependent.IsOwnedBy(s.agent2ID))
+
+	events, err := s.eventRepo.GetByTaskID(ctx, dependentID)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeBlockersResolved, last.Type)
+	s.Nil(last.ActorID)
+	s.Require().NotNil(last.NewStatus)
+	s.Equal(domain.TaskStatusInProgress, *last.NewStatus)
+	s.Equal(blockerID, last.Data["blocker_id"])
+
+	// Still waiting on another blocker: no event
+	events, err = s.eventRepo.GetByTaskID(ctx, waitingID)
+	s.Require().NoError(err)
+	for _, event := range events {
+		s.NotEqual(domain.EventTypeBlockersResolved, event.Type)
+	}
+}
+
+// TestTransitionStatus_ConcurrentBlockersResolveDependent tests that a dependent
+// is resolved when its last two blockers are completed at the same time.
+func (s *TaskServiceTestSuite) TestTransitionStatus_ConcurrentBlockersResolveDependent() {
+	ctx := context.Background()
+
+	for range 5 {
+		firstID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+		secondID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+		dependentID := s.createTask(ctx, domain.TaskStatusNew, nil, []string{firstID, secondID})
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i, blockerID := range []string{firstID, secondID} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+					TaskID:    blockerID,
+					AgentID:   s.agent2ID,
+					NewStatus: domain.TaskStatusDone,
+					Comment:   "Done",
+					Artefact:  "https://github.com/example/done",
+				})
+			}()
+		}
+		wg.Wait()
+		s.Require().NoError(errs[0])
+		s.Require().NoError(errs[1])
+
+		events, err := s.eventRepo.GetByTaskID(ctx, dependentID)
+		s.Require().NoError(err)
+		resolved := 0
+		for _, event := range events {
+			if event.Type == domain.EventTypeBlockersResolved {
+				resolved++
+			}
+		}
+		s.Equal(1, resolved, "exactly one of the blockers resolves the dependent")
 	}
+}
+
+// TestClaimTask_RequiresCapabilities tests capability-based claim routing.
+func (s *TaskServiceTestSuite) TestClaimTask_RequiresCapabilities() {
+	ctx := context.Background()
 
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, assignee_id, status, blocked_by)
-		VALUES ($1, 'Test Task', 'Test Description', $2, $3, $4, $5)
-		RETURNING id
-	`, s.workspaceID, s.agent1ID, assigneeID, status, blockedBy).Scan(&taskID)
-	s.Require().NoError(err, "failed to create task")
+	task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID:          s.workspaceID,
+		CreatorID:            s.agent1ID,
+		Title:                "Review the design",
+		Description:          "Needs a reviewer",
+		Visibility:           domain.TaskVisibilityPublic,
+		Priority:             domain.TaskPriorityNormal,
+		RequiredCapabilities: []string{" Reviewer ", "reviewer"},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"reviewer"}, task.RequiredCapabilities)
+
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Taking it")
+	s.ErrorIs(err, domain.ErrMissingCapabilities)
 
-	// Create "created" event
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO task_events (task_id, actor_id, type, new_status, comment)
-		VALUES ($1, $2, 'created', $3, 'Task created')
-	`, taskID, s.agent1ID, status)
-	s.Require().NoError(err, "failed to create event")
+	s.Require().NoError(s.agentRepo.SetCapabilities(ctx, s.agent2ID, []string{"coder", "reviewer"}))
 
-	return taskID
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Taking it")
+	s.NoError(err)
 }
 
-// Helper: createTaskWithExpiredDeadline creates task with past deadline.
-func (s *TaskServiceTestSuite) createTaskWithExpiredDeadline(ctx context.Context) string {
-	expiredTime := time.Now().Add(-1 * time.Hour)
+// TestAutoAssign_LeastLoadedSkipsBusyAgents tests that only idle agents receive tasks.
+func (s *TaskServiceTestSuite) TestAutoAssign_LeastLoadedSkipsBusyAgents() {
+	ctx := context.Background()
+
+	// Disabled by default
+	firstID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	count, err := s.taskService.AutoAssign(ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+
+	s.Require().NoError(s.workspaceRepo.SetAutoAssignStrategy(ctx, s.workspaceID, domain.AutoAssignLeastLoaded))
+
+	// agent1 is busy, so only agent2 can take work
+	s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	secondID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
 
-	var taskID string
-	err := s.pool.QueryRow(ctx, `
-		INSERT INTO tasks (workspace_id, title, description, creator_id, status, status_deadline_at)
-		VALUES ($1, 'Expired Task', 'Test Description', $2, 'IN_PROGRESS', $3)
-		RETURNING id
-	`, s.workspaceID, s.agent1ID, expiredTime).Scan(&taskID)
-	s.Require().NoError(err, "failed to create task with expired deadline")
+	count, err = s.taskService.AutoAssign(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	first, err := s.taskRepo.GetByID(ctx, firstID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, first.Status)
+	s.Require().NotNil(first.AssigneeID)
+	s.Equal(s.agent2ID, *first.AssigneeID)
+	s.NotNil(first.StatusDeadlineAt)
 
-	// Create "created" event
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO task_events (task_id, actor_id, type, new_status, comment)
-		VALUES ($1, $2, 'created', 'IN_PROGRESS', 'Task created')
-	`, taskID, s.agent1ID)
-	s.Require().NoError(err, "failed to create event")
+	second, err := s.taskRepo.GetByID(ctx, secondID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, second.Status)
+	s.Nil(second.AssigneeID)
 
-	return taskID
+	events, err := s.eventRepo.GetByTaskID(ctx, firstID)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeStatusChanged, last.Type)
+	s.True(last.IsSystemEvent())
+	s.Equal(true, last.Data["auto_assigned"])
+	s.Equal(s.agent2ID, last.Data["agent_id"])
 }
 
-// TestTaskServiceTestSuite runs the test suite.
-func TestTaskServiceTestSuite(t *testing.T) {
-	suite.Run(t, new(TaskServiceTestSuite))
+// TestClaimNext_ScopedToQueue tests queue CRUD and claim-next within a queue.
+func (s *TaskServiceTestSuite) TestClaimNext_ScopedToQueue() {
+	ctx := context.Background()
+	queueService := service.NewQueueService(repository.NewQueueRepository(s.pool))
+
+	_, err := queueService.CreateQueue(ctx, s.workspaceID, " Review ", "Code review pipeline")
+	s.Require().NoError(err)
+	_, err = queueService.CreateQueue(ctx, s.workspaceID, "review", "")
+	s.ErrorIs(err, domain.ErrQueueExists)
+
+	review := "review"
+	reviewTask, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Review the parser",
+		Description: "Queued for review",
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityLow,
+		Queue:       &review,
+	})
+	s.Require().NoError(err)
+	s.Require().NotNil(reviewTask.Queue)
+
+	urgent, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Fix the outage",
+		Description: "Default pool",
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityCritical,
+	})
+	s.Require().NoError(err)
+
+	unknown := "deploy"
+	_, err = s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Deploy the parser",
+		Description: "Unknown queue",
+		Queue:       &unknown,
+	})
+	s.ErrorIs(err, domain.ErrQueueNotFound)
+
+	// Scoped claim ignores the more urgent task outside the queue
+	task, event, err := s.taskService.ClaimNext(ctx, service.ClaimNextParams{
+		AgentID: s.agent2ID,
+		Queue:   &review,
+		Comment: "Next review",
+	})
+	s.Require().NoError(err)
+	s.Equal(reviewTask.ID, task.ID)
+	s.Equal(domain.TaskStatusInProgress, task.Status)
+	s.Equal(domain.EventTypeClaimed, event.Type)
+
+	_, _, err = s.taskService.ClaimNext(ctx, service.ClaimNextParams{
+		AgentID: s.agent2ID,
+		Queue:   &review,
+		Comment: "Next review",
+	})
+	s.ErrorIs(err, domain.ErrNoClaimableTask)
+
+	task, _, err = s.taskService.ClaimNext(ctx, service.ClaimNextParams{
+		AgentID: s.agent2ID,
+		Comment: "Anything",
+	})
+	s.Require().NoError(err)
+	s.Equal(urgent.ID, task.ID)
+
+	// Renames carry tasks along; non-empty queues cannot be deleted
+	renamed := "code-review"
+	_, err = queueService.UpdateQueue(ctx, service.UpdateQueueParams{
+		WorkspaceID: s.workspaceID,
+		Name:        review,
+		NewName:     &renamed,
+	})
+	s.Require().NoError(err)
+
+	moved, err := s.taskRepo.GetByID(ctx, reviewTask.ID)
+	s.Require().NoError(err)
+	s.Equal(renamed, *moved.Queue)
+
+	s.ErrorIs(queueService.DeleteQueue(ctx, s.workspaceID, renamed), domain.ErrQueueNotEmpty)
+}
+
+// TestAwaitExternal_ResolvedByReference tests parking tasks on an external item
+// and resuming all of them by resolving the reference.
+func (s *TaskServiceTestSuite) TestAwaitExternal_ResolvedByReference() {
+	ctx := context.Background()
+
+	first := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	second := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+
+	ref := domain.ExternalRef{System: " GitHub ", ID: "acme/api#412"}
+
+	// Only the assignee can park its task
+	_, err := s.taskService.AwaitExternal(ctx, service.AwaitExternalParams{
+		TaskID: first, AgentID: s.agent2ID, Ref: ref, Comment: "Waiting for merge",
+	})
+	s.ErrorIs(err, domain.ErrNotTaskOwner)
+
+	_, err = s.taskService.AwaitExternal(ctx, service.AwaitExternalParams{
+		TaskID: first, AgentID: s.agent1ID, Ref: domain.ExternalRef{System: "github"}, Comment: "No id",
+	})
+	s.ErrorIs(err, domain.ErrInvalidExternalRef)
+
+	// A plain transition cannot enter the status without a reference
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID: first, AgentID: s.agent1ID, NewStatus: domain.TaskStatusAwaitingExternal, Comment: "Waiting",
+	})
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+
+	for taskID, assignee := range map[string]string{first: s.agent1ID, second: s.agent2ID} {
+		event, err := s.taskService.AwaitExternal(ctx, service.AwaitExternalParams{
+			TaskID: taskID, AgentID: assignee, Ref: ref, Comment: "Waiting for merge",
+		})
+		s.Require().NoError(err)
+		s.Equal(domain.EventTypeAwaitingExternal, event.Type)
+		s.Equal("github", event.Data["external_system"])
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, first)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusAwaitingExternal, task.Status)
+	s.Require().NotNil(task.ExternalRef)
+	s.Equal("github", task.ExternalRef.System)
+	s.Nil(task.StatusDeadlineAt)
+
+	stats, err := s.taskRepo.GetWorkspaceStats(ctx, repository.StatsFilters{
+		WorkspaceID: s.workspaceID,
+		PeriodEnd:   time.Now(),
+	})
+	s.Require().NoError(err)
+	s.Equal(2, stats.AwaitingExternalCount)
+	s.Equal(map[string]int{"github": 2}, stats.AwaitingExternalBySystem)
+	s.Zero(stats.TasksByStatus[string(domain.TaskStatusBlocked)])
+
+	_, err = s.taskService.ResolveExternal(ctx, service.ResolveExternalParams{
+		WorkspaceID: s.workspaceID, System: "github", ExternalID: "acme/api#999", Comment: "Merged",
+	})
+	s.ErrorIs(err, domain.ErrExternalRefNotFound)
+
+	events, err := s.taskService.ResolveExternal(ctx, service.ResolveExternalParams{
+		WorkspaceID: s.workspaceID,
+		System:      "GitHub",
+		ExternalID:  "acme/api#412",
+		Comment:     "PR merged",
+		Data:        map[string]any{"merge_sha": "abc123"},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+	for _, event := range events {
+		s.True(event.IsSystemEvent())
+		s.Equal(domain.EventTypeExternalResolved, event.Type)
+		s.Equal("abc123", event.Data["merge_sha"])
+	}
+
+	for _, taskID := range []string{first, second} {
+		task, err := s.taskRepo.GetByID(ctx, taskID)
+		s.Require().NoError(err)
+		s.Equal(domain.TaskStatusInProgress, task.Status)
+		s.Nil(task.ExternalRef)
+		s.NotNil(task.AssigneeID)
+	}
+}
+
+// TestRunDueSchedules_CreatesTaskAndAdvances tests that a due schedule creates one task
+// and moves to its next run, and that a run that cannot create its task is recorded.
+func (s *TaskServiceTestSuite) TestRunDueSchedules_CreatesTaskAndAdvances() {
+	ctx := context.Background()
+
+	scheduleService := service.NewScheduleService(s.pool, repository.NewScheduleRepository(s.pool), s.taskService)
+
+	schedule, err := scheduleService.CreateSchedule(ctx, service.CreateScheduleParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Name:        "daily-report",
+		CronExpr:    "0 6 * * *",
+		Template: domain.TaskTemplate{
+			Title:       "Generate daily report",
+			Description: "Summarize yesterday's activity",
+		},
+	})
+	s.Require().NoError(err)
+	s.True(schedule.NextRunAt.After(time.Now()))
+	s.Equal(domain.TaskPriorityNormal, schedule.Template.Priority)
+
+	_, err = scheduleService.CreateSchedule(ctx, service.CreateScheduleParams{
+		WorkspaceID: s.workspaceID, CreatorID: s.agent1ID, Name: "daily-report", CronExpr: "@daily",
+		Template: domain.TaskTemplate{Title: "Another report", Description: "Duplicate name"},
+	})
+	s.ErrorIs(err, domain.ErrScheduleExists)
+
+	// Nothing is due yet
+	count, err := scheduleService.RunDueSchedules(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	_, err = s.pool.Exec(ctx, "UPDATE schedules SET next_run_at = NOW() - INTERVAL '1 hour' WHERE id = $1", schedule.ID)
+	s.Require().NoError(err)
+
+	count, err = scheduleService.RunDueSchedules(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	schedule, err = scheduleService.GetSchedule(ctx, s.workspaceID, schedule.ID)
+	s.Require().NoError(err)
+	s.True(schedule.NextRunAt.After(time.Now()))
+	s.Require().NotNil(schedule.LastTaskID)
+	s.Nil(schedule.LastError)
+
+	task, err := s.taskRepo.GetByID(ctx, *schedule.LastTaskID)
+	s.Require().NoError(err)
+	s.Equal("Generate daily report", task.Title)
+	s.Equal(s.agent1ID, task.CreatorID)
+	s.Equal(domain.TaskStatusNew, task.Status)
+
+	// A run whose creator was deactivated creates no task and keeps its schedule going
+	_, err = s.pool.Exec(ctx, "UPDATE agents SET is_active = false WHERE id = $1", s.agent1ID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(ctx, "UPDATE schedules SET next_run_at = NOW() - INTERVAL '1 hour' WHERE id = $1", schedule.ID)
+	s.Require().NoError(err)
+
+	count, err = scheduleService.RunDueSchedules(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	schedule, err = scheduleService.GetSchedule(ctx, s.workspaceID, schedule.ID)
+	s.Require().NoError(err)
+	s.True(schedule.Enabled)
+	s.True(schedule.NextRunAt.After(time.Now()))
+	s.NotNil(schedule.LastError)
+	s.Equal(task.ID, *schedule.LastTaskID)
+}
+
+// TestLabels_RegistryRewritesTasks tests that tasks only take registered labels and that
+// renames, merges and deletes in the registry rewrite task labels.
+func (s *TaskServiceTestSuite) TestLabels_RegistryRewritesTasks() {
+	ctx := context.Background()
+
+	labelService := service.NewLabelService(s.pool, repository.NewLabelRepository(s.pool))
+
+	color := "#D73A4A"
+	bug, created, err := labelService.PutLabel(ctx, s.workspaceID, " Bug ", &color, nil)
+	s.Require().NoError(err)
+	s.True(created)
+	s.Equal("bug", bug.Name)
+	s.Equal("#d73a4a", bug.Color)
+
+	// Registering again is a no-op that keeps the color
+	bug, created, err = labelService.PutLabel(ctx, s.workspaceID, "bug", nil, nil)
+	s.Require().NoError(err)
+	s.False(created)
+	s.Equal("#d73a4a", bug.Color)
+
+	for _, name := range []string{"defect", "infra"} {
+		_, _, err = labelService.PutLabel(ctx, s.workspaceID, name, nil, nil)
+		s.Require().NoError(err)
+	}
+
+	_, err = s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID, CreatorID: s.agent1ID,
+		Title: "Fix login crash", Description: "Crash on empty password", Labels: []string{"bug", "crash"},
+	})
+	s.ErrorIs(err, domain.ErrUnknownLabel)
+
+	first, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID, CreatorID: s.agent1ID,
+		Title: "Fix login crash", Description: "Crash on empty password", Labels: []string{"BUG", "infra"},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"bug", "infra"}, first.Labels)
+
+	second := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	labels, event, err := s.taskService.SetTaskLabels(ctx, service.SetTaskLabelsParams{
+		TaskID: second, AgentID: s.agent1ID, Labels: []string{"defect", "bug"},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"bug", "defect"}, labels)
+	s.Require().NotNil(event)
+	s.Equal(domain.EventTypeLabelsChanged, event.Type)
+
+	// Same labels again: nothing changes
+	_, event, err = s.taskService.SetTaskLabels(ctx, service.SetTaskLabelsParams{
+		TaskID: second, AgentID: s.agent1ID, Labels: []string{"bug", "defect"},
+	})
+	s.Require().NoError(err)
+	s.Nil(event)
+
+	_, _, err = s.taskService.SetTaskLabels(ctx, service.SetTaskLabelsParams{
+		TaskID: second, AgentID: s.agent2ID, Labels: []string{"bug"},
+	})
+	s.ErrorIs(err, domain.ErrPermissionDenied)
+
+	// Merging defect into bug dedupes the second task's labels
+	bug, err = labelService.MergeLabel(ctx, s.workspaceID, "defect", "bug")
+	s.Require().NoError(err)
+	s.Equal(2, bug.UsageCount)
+	_, err = labelService.GetLabel(ctx, s.workspaceID, "defect")
+	s.ErrorIs(err, domain.ErrLabelNotFound)
+
+	task, err := s.taskRepo.GetByID(ctx, second)
+	s.Require().NoError(err)
+	s.Equal([]string{"bug"}, task.Labels)
+
+	// Renaming onto an existing label is refused, a free name rewrites the tasks
+	newName := "infra"
+	_, err = labelService.UpdateLabel(ctx, service.UpdateLabelParams{WorkspaceID: s.workspaceID, Name: "bug", NewName: &newName})
+	s.ErrorIs(err, domain.ErrLabelExists)
+
+	newName = "type:bug"
+	renamed, err := labelService.UpdateLabel(ctx, service.UpdateLabelParams{WorkspaceID: s.workspaceID, Name: "bug", NewName: &newName})
+	s.Require().NoError(err)
+	s.Equal("type:bug", renamed.Name)
+	s.Equal(2, renamed.UsageCount)
+
+	task, err = s.taskRepo.GetByID(ctx, first.ID)
+	s.Require().NoError(err)
+	s.Equal([]string{"infra", "type:bug"}, task.Labels)
+
+	s.Require().NoError(labelService.DeleteLabel(ctx, s.workspaceID, "infra"))
+	task, err = s.taskRepo.GetByID(ctx, first.ID)
+	s.Require().NoError(err)
+	s.Equal([]string{"type:bug"}, task.Labels)
+}
+
+// TestArchiveTask_HiddenFromDefaultList tests archiving finished tasks and the list filter.
+func (s *TaskServiceTestSuite) TestArchiveTask_HiddenFromDefaultList() {
+	ctx := context.Background()
+
+	liveID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+	doneID := s.createTask(ctx, domain.TaskStatusDone, &s.agent2ID, nil)
+
+	// Only finished tasks can be archived
+	_, err := s.taskService.ArchiveTask(ctx, liveID, s.agent1ID, "")
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+
+	// The assignee may archive
+	event, err := s.taskService.ArchiveTask(ctx, doneID, s.agent2ID, "")
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeArchived, event.Type)
+	s.Equal("Task archived", event.Comment)
+
+	_, err = s.taskService.ArchiveTask(ctx, doneID, s.agent1ID, "Again")
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+
+	listIDs := func(archived string) []string {
+		results, total, err := s.taskRepo.List(ctx, repository.TaskListFilters{
+			WorkspaceID: s.workspaceID,
+			AgentID:     s.agent1ID,
+			Archived:    archived,
+			Limit:       200,
+		})
+		s.Require().NoError(err)
+		s.Len(results, total)
+		ids := make([]string, len(results))
+		for i, result := range results {
+			ids[i] = result.Task.ID
+		}
+		return ids
+	}
+
+	s.Contains(listIDs(""), liveID)
+	s.NotContains(listIDs(""), doneID)
+	s.Contains(listIDs(repository.ArchivedInclude), doneID)
+	s.Equal([]string{doneID}, listIDs(repository.ArchivedOnly))
+
+	// Reopening brings the task back into default lists
+	_, err = s.taskService.ReopenTask(ctx, service.ReopenTaskParams{
+		TaskID:    doneID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusNew,
+		Comment:   "Needs another pass",
+	})
+	s.Require().NoError(err)
+
+	task, err := s.taskRepo.GetByID(ctx, doneID)
+	s.Require().NoError(err)
+	s.False(task.IsArchived())
+	s.Contains(listIDs(""), doneID)
+}
+
+// TestCommentTask_RecordsTraceContext tests that events carry the trace context of the request.
+func (s *TaskServiceTestSuite) TestCommentTask_RecordsTraceContext() {
+	taskID := s.createTask(context.Background(), domain.TaskStatusNew, nil, nil)
+
+	trace := domain.ParseTraceContext("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=abc")
+	s.Require().NotNil(trace)
+	ctx := domain.ContextWithTrace(context.Background(), trace)
+
+	_, err := s.taskService.CommentTask(ctx, taskID, s.agent1ID, "Traced", nil, nil)
+	s.Require().NoError(err)
+	_, err = s.taskService.CommentTask(context.Background(), taskID, s.agent1ID, "Untraced", nil, nil)
+	s.Require().NoError(err)
+
+	events, err := s.eventRepo.GetByTaskID(context.Background(), taskID)
+	s.Require().NoError(err)
+	s.Require().Len(events, 3) // created, traced and untraced comment
+	s.Require().NotNil(events[1].Trace)
+	s.Equal(trace.TraceParent, events[1].Trace.TraceParent)
+	s.Equal("vendor=abc", events[1].Trace.TraceState)
+	s.Nil(events[2].Trace)
+}
+
+func (s *TaskServiceTestSuite) TestDeleteTask_HiddenAndPurged() {
+	ctx := context.Background()
+
+	blockerID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	dependentID := s.createTask(ctx, domain.TaskStatusBlocked, nil, []string{blockerID})
+
+	// Only the creator may delete
+	_, err := s.taskService.DeleteTask(ctx, service.DeleteTaskParams{TaskID: blockerID, AgentID: &s.agent2ID})
+	s.ErrorIs(err, domain.ErrNotTaskCreator)
+
+	event, err := s.taskService.DeleteTask(ctx, service.DeleteTaskParams{TaskID: blockerID, AgentID: &s.agent1ID})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeDeleted, event.Type)
+	s.Equal("Task deleted", event.Comment)
+
+	// The deleted task is gone from reads and no longer blocks its dependent
+	_, err = s.taskRepo.GetByID(ctx, blockerID)
+	s.ErrorIs(err, domain.ErrTaskNotFound)
+	dependent, err := s.taskRepo.GetByID(ctx, dependentID)
+	s.Require().NoError(err)
+	s.Empty(dependent.BlockedBy)
+
+	// Deleting twice reports the task as missing
+	_, err = s.taskService.DeleteTask(ctx, service.DeleteTaskParams{TaskID: blockerID, WorkspaceID: s.workspaceID})
+	s.ErrorIs(err, domain.ErrTaskNotFound)
+
+	// Operators may delete any task of the workspace
+	_, err = s.taskService.DeleteTask(ctx, service.DeleteTaskParams{TaskID: dependentID, WorkspaceID: s.workspaceID})
+	s.Require().NoError(err)
+
+	// Recent deletions are kept until they are old enough
+	purged, err := s.taskService.PurgeDeletedTasks(ctx, time.Hour)
+	s.Require().NoError(err)
+	s.Zero(purged)
+
+	purged, err = s.taskService.PurgeDeletedTasks(ctx, 0)
+	s.Require().NoError(err)
+	s.EqualValues(2, purged)
+
+	var events int
+	err = s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM task_events WHERE task_id = ANY($1)`,
+		[]string{blockerID, dependentID}).Scan(&events)
+	s.Require().NoError(err)
+	s.Zero(events)
+}
+
+func (s *TaskServiceTestSuite) TestRunDueReports_DeliversSignedWebhook() {
+	ctx := context.Background()
+
+	s.createTask(ctx, domain.TaskStatusDone, &s.agent2ID, nil)
+	s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header.Clone(), body: body}
+	}))
+	defer server.Close()
+
+	reportService := service.NewReportService(
+		s.pool,
+		repository.NewReportRepository(s.pool),
+		s.taskRepo,
+		s.workspaceRepo,
+		repository.NewWebhookSecretRepository(s.pool),
+		service.ReportDeliveryConfig{WebhookSecret: "report-secret"},
+	)
+
+	report, err := reportService.CreateReport(ctx, service.CreateReportParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Name:        "Daily summary",
+		Kind:        domain.ReportKindWorkspaceSummary,
+		Format:      domain.ReportFormatJSON,
+		CronExpr:    "0 9 * * *",
+		TargetType:  domain.ReportTargetWebhook,
+		Target:      server.URL,
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.ReportPeriodDay, report.Period)
+
+	// Nothing is due yet
+	count, err := reportService.RunDueReports(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	_, err = s.pool.Exec(ctx, `UPDATE reports SET next_run_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, report.ID)
+	s.Require().NoError(err)
+
+	count, err = reportService.RunDueReports(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	got := <-deliveries
+	s.Equal("application/json", got.header.Get("Content-Type"))
+	s.Equal(report.ID, got.header.Get(service.HeaderReportID))
+	s.NoError(client.NewWebhookVerifier("report-secret").Verify(got.header, got.body))
+
+	var doc struct {
+		ReportID string `json:"report_id"`
+		Summary  struct {
+			TasksCreated   int `json:"tasks_created"`
+			TasksCompleted int `json:"tasks_completed"`
+			ClaimableCount int `json:"claimable_count"`
+		} `json:"summary"`
+	}
+	s.Require().NoError(json.Unmarshal(got.body, &doc))
+	s.Equal(report.ID, doc.ReportID)
+	s.Equal(2, doc.Summary.TasksCreated)
+	s.Equal(1, doc.Summary.TasksCompleted)
+	s.Equal(1, doc.Summary.ClaimableCount)
+
+	// The run moved the report to its next occurrence
+	stored, err := reportService.GetReport(ctx, s.workspaceID, report.ID)
+	s.Require().NoError(err)
+	s.NotNil(stored.LastRunAt)
+	s.Nil(stored.LastError)
+	s.True(stored.NextRunAt.After(time.Now()))
+}
+
+func (s *TaskServiceTestSuite) TestCreateReport_ValidatesTarget() {
+	ctx := context.Background()
+
+	reportService := service.NewReportService(
+		s.pool,
+		repository.NewReportRepository(s.pool),
+		s.taskRepo,
+		s.workspaceRepo,
+		repository.NewWebhookSecretRepository(s.pool),
+		service.ReportDeliveryConfig{},
+	)
+
+	params := service.CreateReportParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Name:        "Weekly agents",
+		Kind:        domain.ReportKindAgentPerformance,
+		CronExpr:    "0 9 * * 1",
+		TargetType:  domain.ReportTargetWebhook,
+		Target:      "ftp://example.com/report",
+	}
+	_, err := reportService.CreateReport(ctx, params)
+	s.ErrorIs(err, domain.ErrValidation)
+
+	params.TargetType = domain.ReportTargetEmail
+	params.Target = "Lead <lead@example.com>, ops@example.com"
+	report, err := reportService.CreateReport(ctx, params)
+	s.Require().NoError(err)
+	s.Equal("lead@example.com,ops@example.com", report.Target)
+	s.Equal(domain.ReportFormatMarkdown, report.Format)
+	s.Equal(domain.ReportPeriodWeek, report.Period)
+
+	// Email reports fail without SMTP settings and record why
+	_, err = s.pool.Exec(ctx, `UPDATE reports SET next_run_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, report.ID)
+	s.Require().NoError(err)
+
+	count, err := reportService.RunDueReports(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	stored, err := reportService.GetReport(ctx, s.workspaceID, report.ID)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored.LastError)
+	s.Contains(*stored.LastError, "not configured")
+}
+
+func (s *TaskServiceTestSuite) TestImportTasks_AllOrNothing() {
+	ctx := context.Background()
+
+	labelService := service.NewLabelService(s.pool, repository.NewLabelRepository(s.pool))
+	_, _, err := labelService.PutLabel(ctx, s.workspaceID, "bug", nil, nil)
+	s.Require().NoError(err)
+
+	countTasks := func() int {
+		var count int
+		s.Require().NoError(s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE workspace_id = $1", s.workspaceID).Scan(&count))
+		return count
+	}
+
+	rows, err := service.ParseImport(strings.NewReader(
+		"title,description,priority,labels\n"+
+			"Import first task,Imported,high,bug\n"+
+			"Bad,Title too short,normal,\n"+
+			"Import third task,Imported,low,bug;unknown\n",
+	), service.ImportFormatCSV)
+	s.Require().NoError(err)
+	s.Require().Len(rows, 3)
+	s.Equal([]string{"bug", "unknown"}, rows[2].Labels)
+
+	result, err := s.taskService.ImportTasks(ctx, service.ImportTasksParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Rows:        rows,
+	})
+	s.Require().NoError(err)
+	s.Equal(1, result.Valid)
+	s.Empty(result.Tasks)
+	s.Require().Len(result.Errors, 2)
+	s.Equal(3, result.Errors[0].Row)
+	s.Equal(4, result.Errors[1].Row)
+	s.Contains(result.Errors[1].Message, "unknown")
+	s.Equal(0, countTasks(), "nothing is created while any row is invalid")
+
+	rows, err = service.ParseImport(strings.NewReader(
+		`{"tasks": [{"title": "Import first task", "description": "Imported", "priority": "high", "labels": ["bug"], "status": "DONE"}]}`,
+	), service.ImportFormatJSON)
+	s.Require().NoError(err)
+
+	result, err = s.taskService.ImportTasks(ctx, service.ImportTasksParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Rows:        rows,
+	})
+	s.Require().NoError(err)
+	s.Empty(result.Errors)
+	s.Require().Len(result.Tasks, 1)
+
+	task := result.Tasks[0]
+	s.Equal(domain.TaskStatusNew, task.Status)
+	s.Equal(domain.TaskPriorityHigh, task.Priority)
+	s.Equal([]string{"bug"}, task.Labels)
+	s.Equal(s.agent1ID, task.CreatorID)
+	s.Equal(1, countTasks())
+}
+
+// Helper: createTask creates a test task created by agent1.
+func (s *TaskServiceTestSuite) createTask(
+	_ context.Context,
+	status domain.TaskStatus,
+	assigneeID *string,
+	blockedBy []string,
+) string {
+	opts := []factory.TaskOption{factory.WithStatus(status), factory.WithBlockedBy(blockedBy...)}
+	if assigneeID != nil {
+		opts = append(opts, factory.WithAssignee(*assigneeID))
+	}
+	return factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, opts...).ID
+}
+
+// Helper: createTaskWithExpiredDeadline creates task with past deadline.
+func (s *TaskServiceTestSuite) createTaskWithExpiredDeadline(_ context.Context) string {
+	return factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithTitle("Expired Task"),
+		factory.WithStatus(domain.TaskStatusInProgress),
+		factory.WithStatusDeadline(time.Now().Add(-1*time.Hour)),
+	).ID
+}
+
+// TestTaskServiceTestSuite runs the test suite.
+func TestTaskServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskServiceTestSuite))
+}
+
+func (s *TaskServiceTestSuite) TestRunDueReports_DualSignsDuringSecretRotation() {
+	ctx := context.Background()
+
+	deliveries := make(chan http.Header, 2)
+	bodies := make(chan []byte, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- r.Header.Clone()
+		bodies <- body
+	}))
+	defer server.Close()
+
+	secretRepo := repository.NewWebhookSecretRepository(s.pool)
+	webhookService := service.NewWebhookSecretService(s.pool, secretRepo, s.workspaceRepo)
+	reportService := service.NewReportService(
+		s.pool,
+		repository.NewReportRepository(s.pool),
+		s.taskRepo,
+		s.workspaceRepo,
+		secretRepo,
+		service.ReportDeliveryConfig{WebhookSecret: "server-secret"},
+	)
+
+	// The first secret has nothing to overlap with
+	first, err := webhookService.RotateWebhookSecret(ctx, s.workspaceID, time.Hour)
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(first.Secret, domain.WebhookSecretPrefix))
+	s.Nil(first.PreviousSecret)
+
+	second, err := webhookService.RotateWebhookSecret(ctx, s.workspaceID, time.Hour)
+	s.Require().NoError(err)
+	s.Require().NotNil(second.PreviousSecret)
+	s.Equal(first.Secret, *second.PreviousSecret)
+	s.True(second.IsRotating(time.Now()))
+
+	_, err = webhookService.RotateWebhookSecret(ctx, s.workspaceID, time.Hour)
+	s.ErrorIs(err, domain.ErrRotationInProgress)
+
+	report, err := reportService.CreateReport(ctx, service.CreateReportParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Name:        "Daily summary",
+		Kind:        domain.ReportKindWorkspaceSummary,
+		CronExpr:    "0 9 * * *",
+		TargetType:  domain.ReportTargetWebhook,
+		Target:      server.URL,
+	})
+	s.Require().NoError(err)
+
+	runNow := func() (http.Header, []byte) {
+		_, err := s.pool.Exec(ctx, `UPDATE reports SET next_run_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, report.ID)
+		s.Require().NoError(err)
+		count, err := reportService.RunDueReports(ctx)
+		s.Require().NoError(err)
+		s.Require().Equal(1, count)
+		return <-deliveries, <-bodies
+	}
+
+	// During the overlap receivers holding either secret accept the delivery
+	header, body := runNow()
+	s.NoError(client.NewWebhookVerifier(first.Secret).Verify(header, body))
+	s.NoError(client.NewWebhookVerifier(second.Secret).Verify(header, body))
+	s.ErrorIs(client.NewWebhookVerifier("server-secret").Verify(header, body), client.ErrInvalidSignature)
+
+	completed, err := webhookService.CompleteWebhookSecretRotation(ctx, s.workspaceID)
+	s.Require().NoError(err)
+	s.False(completed.IsRotating(time.Now()))
+
+	_, err = webhookService.CompleteWebhookSecretRotation(ctx, s.workspaceID)
+	s.ErrorIs(err, domain.ErrNoRotationInProgress)
+
+	header, body = runNow()
+	s.NoError(client.NewWebhookVerifier(second.Secret).Verify(header, body))
+	s.ErrorIs(client.NewWebhookVerifier(first.Secret).Verify(header, body), client.ErrInvalidSignature)
+}
+
+// TestPriorityInheritance tests that blockers of critical tasks are ranked as
+// critical along the chain and drop the bump when the dependent closes.
+func (s *TaskServiceTestSuite) TestPriorityInheritance() {
+	ctx := context.Background()
+
+	updated, err := s.taskService.SetPriorityInheritance(ctx, s.workspaceID, true)
+	s.Require().NoError(err)
+	s.Equal(0, updated)
+
+	create := func(title string, priority domain.TaskPriority, blockedBy ...string) *domain.Task {
+		task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+			WorkspaceID: s.workspaceID,
+			CreatorID:   s.agent1ID,
+			Title:       title,
+			Description: "Priority inheritance",
+			Visibility:  domain.TaskVisibilityPublic,
+			Priority:    priority,
+			BlockedBy:   blockedBy,
+		})
+		s.Require().NoError(err)
+		return task
+	}
+	inherited := func(taskID string) *domain.TaskPriority {
+		task, err := s.taskRepo.GetByID(ctx, taskID)
+		s.Require().NoError(err)
+		return task.InheritedPriority
+	}
+	lastEvent := func(taskID string) *domain.TaskEvent {
+		events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+		s.Require().NoError(err)
+		return events[len(events)-1]
+	}
+
+	other := create("Older normal task", domain.TaskPriorityNormal)
+	root := create("Low priority root blocker", domain.TaskPriorityLow)
+	mid := create("Normal priority blocker", domain.TaskPriorityNormal, root.ID)
+	dependent := create("Critical dependent task", domain.TaskPriorityCritical, mid.ID)
+
+	// The bump propagates along the chain
+	for _, taskID := range []string{root.ID, mid.ID} {
+		s.Require().NotNil(inherited(taskID))
+		s.Equal(domain.TaskPriorityCritical, *inherited(taskID))
+	}
+	s.Nil(inherited(other.ID))
+	s.Nil(inherited(dependent.ID))
+
+	event := lastEvent(mid.ID)
+	s.Equal(domain.EventTypePriorityInherited, event.Type)
+	s.Nil(event.ActorID)
+	s.Equal(dependent.ID, event.Data["from_task_id"])
+	s.Equal(mid.ID, lastEvent(root.ID).Data["from_task_id"])
+
+	// Next-task selection ranks the root blocker above the older normal task
+	claimed, _, err := s.taskService.ClaimNext(ctx, service.ClaimNextParams{AgentID: s.agent2ID, Comment: "Most urgent"})
+	s.Require().NoError(err)
+	s.Equal(root.ID, claimed.ID)
+	s.Equal(domain.TaskPriorityCritical, claimed.EffectivePriority())
+
+	// Closing the dependent restores both blockers
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    dependent.ID,
+		AgentID:   s.agent1ID,
+		NewStatus: domain.TaskStatusCancelled,
+		Comment:   "No longer needed",
+	})
+	s.Require().NoError(err)
+
+	for _, taskID := range []string{root.ID, mid.ID} {
+		s.Nil(inherited(taskID))
+		event := lastEvent(taskID)
+		s.Equal(domain.EventTypePriorityRestored, event.Type)
+		s.Equal(string(domain.TaskPriorityCritical), event.Data["inherited_priority"])
+	}
+
+	// Toggling re-evaluates existing tasks
+	_, err = s.taskService.SetPriorityInheritance(ctx, s.workspaceID, false)
+	s.Require().NoError(err)
+	create("High dependent task", domain.TaskPriorityHigh, root.ID)
+	s.Nil(inherited(root.ID))
+
+	updated, err = s.taskService.SetPriorityInheritance(ctx, s.workspaceID, true)
+	s.Require().NoError(err)
+	s.Equal(1, updated)
+	s.Require().NotNil(inherited(root.ID))
+	s.Equal(domain.TaskPriorityHigh, *inherited(root.ID))
+
+	updated, err = s.taskService.SetPriorityInheritance(ctx, s.workspaceID, false)
+	s.Require().NoError(err)
+	s.Equal(1, updated)
+	s.Nil(inherited(root.ID))
+}
+
+// TestEscalateTask_RoutesNotifications tests that escalations reach the target
+// of the first matching route and fall back to the task's creator.
+func (s *TaskServiceTestSuite) TestEscalateTask_RoutesNotifications() {
+	ctx := context.Background()
+
+	deliveries := make(chan http.Header, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- r.Header.Clone()
+		bodies <- body
+	}))
+	defer server.Close()
+
+	escalationService := service.NewEscalationService(
+		s.pool,
+		repository.NewEscalationRepository(s.pool),
+		s.agentRepo,
+		s.workspaceRepo,
+		repository.NewWebhookSecretRepository(s.pool),
+		service.ReportDeliveryConfig{WebhookSecret: "server-secret"},
+	)
+
+	operatorID := factory.CreateAgent(s.T(), s.pool, s.workspaceID,
+		factory.WithAgentName("security-lead"),
+		factory.WithToken("token-security"),
+	).ID
+
+	label := "Security"
+	critical := domain.TaskPriorityCritical
+	_, err := escalationService.SetRoutes(ctx, s.workspaceID, []*domain.EscalationRoute{
+		{Name: "default", TargetType: domain.EscalationTargetAgent, Target: operatorID},
+	})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	routes, err := escalationService.SetRoutes(ctx, s.workspaceID, []*domain.EscalationRoute{
+		{Name: "security", Label: &label, TargetType: domain.EscalationTargetAgent, Target: operatorID},
+		{Name: "incidents", Priority: &critical, TargetType: domain.EscalationTargetWebhook, Target: server.URL},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(routes, 2)
+	s.Equal("security", *routes[0].Label)
+
+	escalate := func(opts ...factory.TaskOption) *domain.TaskEvent {
+		opts = append(opts, factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent1ID))
+		task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, opts...)
+		event, err := s.taskService.EscalateTask(ctx, task.ID, s.agent2ID, "Task is stuck")
+		s.Require().NoError(err)
+		return event
+	}
+
+	// A labeled critical task takes the first route
+	event := escalate(factory.WithLabels("security"), factory.WithPriority(domain.TaskPriorityCritical))
+	s.Equal("security", event.Data["route"])
+	s.Equal(operatorID, event.Data["routed_to"])
+
+	inbox, total, err := escalationService.ListAgentEscalations(ctx, operatorID, 50, 0)
+	s.Require().NoError(err)
+	s.Equal(1, total)
+	s.Require().Len(inbox, 1)
+	s.Equal(event.ID, inbox[0].EventID)
+	s.Equal(domain.EscalationDelivered, inbox[0].Status)
+
+	// Without a matching route the creator is told
+	event = escalate()
+	s.Equal(domain.DefaultEscalationRoute, event.Data["route"])
+	inbox, total, err = escalationService.ListAgentEscalations(ctx, s.agent1ID, 50, 0)
+	s.Require().NoError(err)
+	s.Equal(1, total)
+	s.Equal(event.ID, inbox[0].EventID)
+
+	// Webhook notifications wait for the scheduler
+	event = escalate(factory.WithPriority(domain.TaskPriorityCritical))
+	s.Equal("incidents", event.Data["route"])
+	s.NotContains(event.Data, "routed_to")
+
+	count, err := escalationService.DeliverPendingEscalations(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	header, body := <-deliveries, <-bodies
+	s.NotEmpty(header.Get(service.HeaderEscalationID))
+	s.NoError(client.NewWebhookVerifier("server-secret").Verify(header, body))
+
+	var payload map[string]any
+	s.Require().NoError(json.Unmarshal(body, &payload))
+	s.Equal("task.escalated", payload["type"])
+	s.Equal(event.TaskID, payload["task_id"])
+	s.Equal("incidents", payload["route"])
+
+	count, err = escalationService.DeliverPendingEscalations(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+}
+
+// TestChangeFeed tests that committed task events reach the subscribers of
+// their workspace only.
+func (s *TaskServiceTestSuite) TestChangeFeed() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feed := service.NewChangeFeed(s.eventRepo)
+	go feed.Run(ctx)
+	select {
+	case <-feed.Ready():
+	case <-time.After(5 * time.Second):
+		s.FailNow("change feed did not start listening")
+	}
+
+	changes, unsubscribe := feed.Subscribe(s.workspaceID)
+	defer unsubscribe()
+	other, unsubscribeOther := feed.Subscribe("00000000-0000-0000-0000-0000000000ff")
+	defer unsubscribeOther()
+
+	taskID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+
+	event, err := s.taskService.ClaimTask(ctx, taskID, s.agent2ID, "Taking it")
+	s.Require().NoError(err)
+
+	select {
+	case change := <-changes:
+		s.Equal(event.ID, change.EventID)
+		s.Equal(taskID, change.TaskID)
+		s.Equal(s.workspaceID, change.WorkspaceID)
+		s.Equal(domain.EventTypeClaimed, change.Type)
+		s.Require().NotNil(change.NewStatus)
+		s.Equal(domain.TaskStatusInProgress, *change.NewStatus)
+	case <-time.After(5 * time.Second):
+		s.FailNow("no change received")
+	}
+
+	select {
+	case change := <-other:
+		s.Failf("change delivered to another workspace", "%+v", change)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestWaitForClaimable tests that a waiting agent gets a task as soon as one
+// becomes claimable, and nothing once the wait times out.
+func (s *TaskServiceTestSuite) TestWaitForClaimable() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feed := service.NewChangeFeed(s.eventRepo)
+	go feed.Run(ctx)
+	select {
+	case <-feed.Ready():
+	case <-time.After(5 * time.Second):
+		s.FailNow("change feed did not start listening")
+	}
+
+	params := service.WaitForClaimableParams{AgentID: s.agent2ID, Timeout: 10 * time.Millisecond}
+
+	_, err := s.taskService.WaitForClaimable(ctx, nil, params)
+	s.ErrorIs(err, domain.ErrNoClaimableTask)
+
+	changes, unsubscribe := feed.Subscribe(s.workspaceID)
+	defer unsubscribe()
+
+	created := make(chan string, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+			WorkspaceID: s.workspaceID,
+			CreatorID:   s.agent1ID,
+			Title:       "Fresh work",
+			Description: "Appears while agent-2 waits",
+			Visibility:  domain.TaskVisibilityPublic,
+			Priority:    domain.TaskPriorityNormal,
+		})
+		if err == nil {
+			created <- task.ID
+		}
+		close(created)
+	}()
+
+	// Woken by the change well before the recheck interval
+	params.Timeout = 3 * time.Second
+	started := time.Now()
+	task, err := s.taskService.WaitForClaimable(ctx, changes, params)
+	s.Require().NoError(err)
+	s.Equal(<-created, task.ID)
+	s.Less(time.Since(started), 2*time.Second)
+
+	// Waiting does not claim
+	stored, err := s.taskRepo.GetByID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, stored.Status)
+
+	feed.Close()
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Mine")
+	s.Require().NoError(err)
+	_, err = s.taskService.WaitForClaimable(ctx, changes, params)
+	s.ErrorIs(err, domain.ErrNoClaimableTask)
+}
+
+func (s *TaskServiceTestSuite) TestTransitionStatus_DoneValidation() {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var requests []client.DoneValidationRequest
+	respond := func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) }
+	validator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request client.DoneValidationRequest
+		s.NoError(json.NewDecoder(r.Body).Decode(&request))
+		mu.Lock()
+		requests = append(requests, request)
+		reply := respond
+		mu.Unlock()
+		reply(w)
+	}))
+	defer validator.Close()
+	setResponse := func(reply func(http.ResponseWriter)) {
+		mu.Lock()
+		respond = reply
+		mu.Unlock()
+	}
+
+	hook, err := s.taskService.SetDoneValidation(ctx, s.workspaceID, &domain.DoneValidationHook{URL: validator.URL})
+	s.Require().NoError(err)
+	s.Equal(domain.DefaultDoneValidationTimeout, hook.Timeout)
+
+	_, err = s.taskService.SetDoneValidation(ctx, s.workspaceID, &domain.DoneValidationHook{URL: "ftp://ci"})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	complete := func(taskID string) (*domain.TaskEvent, error) {
+		return s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+			TaskID:    taskID,
+			AgentID:   s.agent1ID,
+			NewStatus: domain.TaskStatusDone,
+			Comment:   "Finished",
+			Artefact:  "https://github.com/example/pull/1",
+			Result:    map[string]any{"tests": "passed"},
+		})
+	}
+
+	// Rejected with the validator's reason; the task stays put
+	setResponse(func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"reason": "CI is red on main"}`))
+	})
+	taskID := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	_, err = complete(taskID)
+	s.Require().ErrorIs(err, domain.ErrDoneRejected)
+	s.Contains(err.Error(), "CI is red on main")
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, task.Status)
+
+	mu.Lock()
+	s.Require().Len(requests, 1)
+	s.Equal(taskID, requests[0].TaskID)
+	s.Equal(s.agent1ID, requests[0].AgentID)
+	s.Equal(client.TaskStatus(domain.TaskStatusInProgress), requests[0].PreviousStatus)
+	s.Equal("https://github.com/example/pull/1", requests[0].Artefact)
+	s.Equal("passed", requests[0].Result["tests"])
+	mu.Unlock()
+
+	// Accepted
+	setResponse(func(w http.ResponseWriter) { w.WriteHeader(http.StatusNoContent) })
+	event, err := complete(taskID)
+	s.Require().NoError(err)
+	s.Equal("accepted", event.Data["done_validation"])
+
+	// Unavailable: blocks unless the hook fails open
+	setResponse(func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) })
+	taskID = s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	_, err = complete(taskID)
+	s.ErrorIs(err, domain.ErrDoneValidationUnavailable)
+
+	_, err = s.taskService.SetDoneValidation(ctx, s.workspaceID, &domain.DoneValidationHook{URL: validator.URL, FailOpen: true})
+	s.Require().NoError(err)
+	event, err = complete(taskID)
+	s.Require().NoError(err)
+	s.Equal("unavailable", event.Data["done_validation"])
+
+	// Without a hook nothing is called
+	_, err = s.taskService.SetDoneValidation(ctx, s.workspaceID, nil)
+	s.Require().NoError(err)
+	mu.Lock()
+	calls := len(requests)
+	mu.Unlock()
+	event, err = complete(s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil))
+	s.Require().NoError(err)
+	s.NotContains(event.Data, "done_validation")
+	mu.Lock()
+	s.Len(requests, calls)
+	mu.Unlock()
+}
+
+// TestReleaseAbandonedTasks tests that tasks of stale and deactivated agents return to the pool.
+func (s *TaskServiceTestSuite) TestReleaseAbandonedTasks() {
+	ctx := context.Background()
+
+	// agent1 never sent a heartbeat, agent2 went silent ten minutes ago
+	_, err := s.pool.Exec(ctx, "UPDATE agents SET last_seen_at = NOW() - INTERVAL '10 minutes' WHERE id = $1", s.agent2ID)
+	s.Require().NoError(err)
+
+	heldByAgent1 := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent1ID, nil)
+	heldByAgent2 := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+	blockedByAgent2 := s.createTask(ctx, domain.TaskStatusBlocked, &s.agent2ID, nil)
+
+	count, err := s.taskService.ReleaseAbandonedTasks(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	task, err := s.taskRepo.GetByID(ctx, heldByAgent2)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, task.Status)
+	s.Nil(task.AssigneeID)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, heldByAgent2)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeReleased, last.Type)
+	s.True(last.IsSystemEvent())
+	s.Equal(s.agent2ID, last.Data["agent_id"])
+	s.Equal(domain.ReleaseReasonAgentStale, last.Data["reason"])
+
+	for _, taskID := range []string{heldByAgent1, blockedByAgent2} {
+		task, err := s.taskRepo.GetByID(ctx, taskID)
+		s.Require().NoError(err)
+		s.NotEqual(domain.TaskStatusNew, task.Status)
+	}
+
+	// A fresh heartbeat keeps the agent's work; deactivation releases it
+	_, err = s.agentRepo.Heartbeat(ctx, s.agent2ID)
+	s.Require().NoError(err)
+	reclaimed := s.createTask(ctx, domain.TaskStatusInProgress, &s.agent2ID, nil)
+	_, err = s.pool.Exec(ctx, "UPDATE agents SET is_active = false WHERE id = $1", s.agent1ID)
+	s.Require().NoError(err)
+
+	count, err = s.taskService.ReleaseAbandonedTasks(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	task, err = s.taskRepo.GetByID(ctx, heldByAgent1)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, task.Status)
+
+	events, err = s.eventRepo.GetByTaskID(ctx, heldByAgent1)
+	s.Require().NoError(err)
+	s.Equal(domain.ReleaseReasonAgentInactive, events[len(events)-1].Data["reason"])
+
+	task, err = s.taskRepo.GetByID(ctx, reclaimed)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusInProgress, task.Status)
+}
+
+func (s *TaskServiceTestSuite) TestTransferTask() {
+	ctx := context.Background()
+
+	target := factory.CreateWorkspace(s.T(), s.pool, factory.WithSlug("platform"))
+	targetAgent := factory.CreateAgent(s.T(), s.pool, target.ID, factory.WithAgentName("platform-agent"))
+	_, err := s.pool.Exec(ctx, "INSERT INTO labels (workspace_id, name) VALUES ($1, 'backend')", target.ID)
+	s.Require().NoError(err)
+
+	task := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress),
+		factory.WithAssignee(s.agent2ID),
+		factory.WithLabels("backend", "bug"),
+	)
+	dependent := s.createTask(ctx, domain.TaskStatusBlocked, &s.agent1ID, []string{task.ID})
+
+	// The creator must belong to the target workspace
+	_, err = s.taskService.TransferTask(ctx, service.TransferTaskParams{
+		TaskID: task.ID, WorkspaceID: s.workspaceID, TargetWorkspaceID: target.ID, CreatorID: s.agent1ID,
+	})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	event, err := s.taskService.TransferTask(ctx, service.TransferTaskParams{
+		TaskID: task.ID, WorkspaceID: s.workspaceID, TargetWorkspaceID: target.ID, CreatorID: targetAgent.ID,
+		Comment: "Belongs to the platform team",
+	})
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeTransferred, event.Type)
+	s.True(event.IsSystemEvent())
+	s.Equal(s.workspaceID, event.Data["from_workspace_id"])
+	s.Equal(s.agent2ID, event.Data["previous_assignee_id"])
+
+	moved, err := s.taskRepo.GetByID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(target.ID, moved.WorkspaceID)
+	s.Equal(targetAgent.ID, moved.CreatorID)
+	s.Equal(domain.TaskStatusNew, moved.Status)
+	s.Nil(moved.AssigneeID)
+	s.Equal([]string{"backend"}, moved.Labels)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeCreated, events[0].Type)
+
+	unblocked, err := s.taskRepo.GetByID(ctx, dependent)
+	s.Require().NoError(err)
+	s.Empty(unblocked.BlockedBy)
+
+	// Closed tasks stay where they are
+	done := s.createTask(ctx, domain.TaskStatusDone, &s.agent1ID, nil)
+	_, err = s.taskService.TransferTask(ctx, service.TransferTaskParams{
+		TaskID: done, WorkspaceID: s.workspaceID, TargetWorkspaceID: target.ID, CreatorID: targetAgent.ID,
+	})
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+}
+
+func (s *TaskServiceTestSuite) TestAgeWaitingTasks() {
+	ctx := context.Background()
+
+	twoHoursAgo := time.Now().Add(-2 * time.Hour)
+	stale := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID, factory.WithCreatedAt(twoHoursAgo)).ID
+	critical := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithCreatedAt(twoHoursAgo), factory.WithPriority(domain.TaskPriorityCritical)).ID
+	fresh := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID).ID
+	claimed := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithCreatedAt(twoHoursAgo), factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID)).ID
+
+	// Aging is off by default
+	count, err := s.taskService.AgeWaitingTasks(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	_, err = s.taskService.SetPriorityAging(ctx, s.workspaceID, &domain.PriorityAging{After: 30 * time.Second})
+	s.ErrorIs(err, domain.ErrValidation)
+	_, err = s.taskService.SetPriorityAging(ctx, s.workspaceID, &domain.PriorityAging{After: time.Hour, Action: "escalate"})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	aging, err := s.taskService.SetPriorityAging(ctx, s.workspaceID, &domain.PriorityAging{After: time.Hour})
+	s.Require().NoError(err)
+	s.Equal(domain.PriorityAgingBump, aging.Action)
+
+	count, err = s.taskService.AgeWaitingTasks(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	task, err := s.taskRepo.GetByID(ctx, stale)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskPriorityHigh, task.Priority)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, stale)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypePriorityAged, last.Type)
+	s.True(last.IsSystemEvent())
+	s.Equal("normal", last.Data["old_priority"])
+	s.Equal("high", last.Data["new_priority"])
+
+	for _, taskID := range []string{critical, fresh, claimed} {
+		events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+		s.Require().NoError(err)
+		s.NotEqual(domain.EventTypePriorityAged, events[len(events)-1].Type)
+	}
+
+	// The wait starts over after aging
+	count, err = s.taskService.AgeWaitingTasks(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	// Warn mode records an event without touching the priority, critical tasks included
+	_, err = s.taskService.SetPriorityAging(ctx, s.workspaceID, &domain.PriorityAging{After: time.Hour, Action: domain.PriorityAgingWarn})
+	s.Require().NoError(err)
+
+	count, err = s.taskService.AgeWaitingTasks(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	task, err = s.taskRepo.GetByID(ctx, critical)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskPriorityCritical, task.Priority)
+
+	events, err = s.eventRepo.GetByTaskID(ctx, critical)
+	s.Require().NoError(err)
+	last = events[len(events)-1]
+	s.Equal(domain.EventTypePriorityAged, last.Type)
+	s.Equal("warn", last.Data["action"])
+	s.Equal("critical", last.Data["priority"])
+}
+
+func (s *TaskServiceTestSuite) TestClaimFairness() {
+	ctx := context.Background()
+
+	tasks := make([]string, 5)
+	for i := range tasks {
+		tasks[i] = s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	}
+
+	_, err := s.taskService.SetClaimFairness(ctx, s.workspaceID, domain.ClaimFairness{Quota: -1})
+	s.ErrorIs(err, domain.ErrValidation)
+	_, err = s.taskService.SetClaimFairness(ctx, s.workspaceID, domain.ClaimFairness{Quota: 2, Window: 10 * time.Second})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	fairness, err := s.taskService.SetClaimFairness(ctx, s.workspaceID, domain.ClaimFairness{Quota: 2})
+	s.Require().NoError(err)
+	s.Equal(time.Hour, fairness.Window)
+
+	for _, taskID := range tasks[:2] {
+		_, err := s.taskService.ClaimTask(ctx, taskID, s.agent1ID, "Taking it")
+		s.Require().NoError(err)
+	}
+	_, err = s.taskService.ClaimTask(ctx, tasks[2], s.agent1ID, "One more")
+	s.ErrorIs(err, domain.ErrClaimQuotaExceeded)
+
+	// Taking turns: agent2 never sent a heartbeat, so agent1 may claim again
+	_, err = s.taskService.SetClaimFairness(ctx, s.workspaceID, domain.ClaimFairness{TakeTurns: true})
+	s.Require().NoError(err)
+
+	_, err = s.taskService.ClaimTask(ctx, tasks[2], s.agent1ID, "One more")
+	s.Require().NoError(err)
+
+	// Once agent2 is live and idle, agent1 has to wait for it
+	_, err = s.agentRepo.Heartbeat(ctx, s.agent2ID)
+	s.Require().NoError(err)
+
+	_, err = s.taskService.ClaimTask(ctx, tasks[3], s.agent1ID, "And another")
+	s.ErrorIs(err, domain.ErrClaimTurn)
+
+	_, err = s.taskService.ClaimTask(ctx, tasks[3], s.agent2ID, "My turn")
+	s.Require().NoError(err)
+
+	// agent1 is busy, so agent2 may claim twice in a row
+	_, err = s.taskService.ClaimTask(ctx, tasks[4], s.agent2ID, "Next one")
+	s.Require().NoError(err)
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, s.workspaceID)
+	s.Require().NoError(err)
+	s.Equal(domain.ClaimFairness{Window: time.Hour, TakeTurns: true}, workspace.ClaimFairness)
+}
+
+func (s *TaskServiceTestSuite) TestWorkspaceConfigExportImport() {
+	ctx := context.Background()
+
+	newConfigService := func() (*service.WorkspaceConfigService, *service.LabelService, *service.QueueService, *service.EscalationService, *service.ScheduleService) {
+		labelService := service.NewLabelService(s.pool, repository.NewLabelRepository(s.pool))
+		queueService := service.NewQueueService(repository.NewQueueRepository(s.pool))
+		escalationService := service.NewEscalationService(s.pool, repository.NewEscalationRepository(s.pool), s.agentRepo,
+			s.workspaceRepo, repository.NewWebhookSecretRepository(s.pool), service.ReportDeliveryConfig{})
+		scheduleService := service.NewScheduleService(s.pool, repository.NewScheduleRepository(s.pool), s.taskService)
+		reportService := service.NewReportService(s.pool, repository.NewReportRepository(s.pool), s.taskRepo,
+			s.workspaceRepo, repository.NewWebhookSecretRepository(s.pool), service.ReportDeliveryConfig{})
+		return service.NewWorkspaceConfigService(s.workspaceRepo, s.agentRepo, s.taskService, labelService, queueService,
+			escalationService, scheduleService, reportService), labelService, queueService, escalationService, scheduleService
+	}
+	configService, labelService, queueService, escalationService, scheduleService := newConfigService()
+
+	color := "#ff0000"
+	description := "Something is broken"
+	_, _, err := labelService.PutLabel(ctx, s.workspaceID, "bug", &color, &description)
+	s.Require().NoError(err)
+	_, err = queueService.CreateQueue(ctx, s.workspaceID, "review", "Waiting for review")
+	s.Require().NoError(err)
+	_, err = escalationService.SetRoutes(ctx, s.workspaceID, []*domain.EscalationRoute{
+		{Name: "default", TargetType: domain.EscalationTargetAgent, Target: s.agent2ID},
+	})
+	s.Require().NoError(err)
+	_, err = scheduleService.CreateSchedule(ctx, service.CreateScheduleParams{
+		WorkspaceID: s.workspaceID, CreatorID: s.agent1ID, Name: "daily-report", CronExpr: "@daily",
+		Template: domain.TaskTemplate{Title: "Write the daily report", AssigneeID: &s.agent2ID},
+	})
+	s.Require().NoError(err)
+	_, err = s.taskService.SetClaimFairness(ctx, s.workspaceID, domain.ClaimFairness{Quota: 3})
+	s.Require().NoError(err)
+
+	_, cfg, err := configService.ExportConfig(ctx, s.workspaceID)
+	s.Require().NoError(err)
+	s.Equal("agent-2", cfg.EscalationRoutes[0].Target)
+	s.Equal("agent-1", cfg.Schedules[0].CreatorID)
+	s.Equal("agent-2", *cfg.Schedules[0].Template.AssigneeID)
+
+	// Importing the export back changes nothing
+	result, err := configService.ImportConfig(ctx, s.workspaceID, cfg)
+	s.Require().NoError(err)
+	s.False(result.SettingsChanged)
+	s.Equal(domain.ConfigImportCounts{Unchanged: 1}, result.Labels)
+	s.Equal(domain.ConfigImportCounts{Unchanged: 1}, result.Queues)
+	s.Equal(domain.ConfigImportCounts{Unchanged: 1}, result.EscalationRoutes)
+	s.Equal(domain.ConfigImportCounts{Unchanged: 1}, result.Schedules)
+
+	// Promote it to another workspace whose agents have the same names
+	staging := factory.CreateWorkspace(s.T(), s.pool, factory.WithSlug("staging"))
+	factory.CreateAgent(s.T(), s.pool, staging.ID, factory.WithAgentName("agent-1"), factory.WithToken("token-staging-1"))
+
+	_, err = configService.ImportConfig(ctx, staging.ID, cfg)
+	s.ErrorIs(err, domain.ErrValidation)
+
+	stagingAgent2 := factory.CreateAgent(s.T(), s.pool, staging.ID,
+		factory.WithAgentName("agent-2"), factory.WithToken("token-staging-2")).ID
+
+	result, err = configService.ImportConfig(ctx, staging.ID, cfg)
+	s.Require().NoError(err)
+	s.True(result.SettingsChanged)
+	s.Equal(domain.ConfigImportCounts{Created: 1}, result.Labels)
+	s.Equal(domain.ConfigImportCounts{Created: 1}, result.EscalationRoutes)
+	s.Equal(domain.ConfigImportCounts{Created: 1}, result.Schedules)
+
+	routes, err := escalationService.ListRoutes(ctx, staging.ID)
+	s.Require().NoError(err)
+	s.Require().Len(routes, 1)
+	s.Equal(stagingAgent2, routes[0].Target)
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, staging.ID)
+	s.Require().NoError(err)
+	s.Equal(3, workspace.ClaimFairness.Quota)
+
+	// Only the changed label is updated
+	cfg.Labels[0].Description = "Broken"
+	result, err = configService.ImportConfig(ctx, staging.ID, &domain.WorkspaceConfig{Labels: cfg.Labels})
+	s.Require().NoError(err)
+	s.False(result.SettingsChanged)
+	s.Equal(domain.ConfigImportCounts{Updated: 1}, result.Labels)
+}
+
+func (s *TaskServiceTestSuite) TestWarnApproachingDeadlines() {
+	ctx := context.Background()
+
+	// IN_PROGRESS deadlines are 1440 minutes in the test workspace
+	dueSoon := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+		factory.WithStatusDeadline(time.Now().Add(time.Hour))).ID
+	dueLater := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+		factory.WithStatusDeadline(time.Now().Add(10*time.Hour))).ID
+	expired := s.createTaskWithExpiredDeadline(ctx)
+
+	// Warnings are off by default
+	count, err := s.taskService.WarnApproachingDeadlines(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	s.ErrorIs(s.taskService.SetDeadlineWarning(ctx, s.workspaceID, 100), domain.ErrValidation)
+	s.Require().NoError(s.taskService.SetDeadlineWarning(ctx, s.workspaceID, 10))
+
+	count, err = s.taskService.WarnApproachingDeadlines(ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, dueSoon)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeDeadlineWarning, last.Type)
+	s.True(last.IsSystemEvent())
+	s.Equal("IN_PROGRESS", last.Data["status"])
+	s.EqualValues(10, last.Data["percent"])
+
+	for _, taskID := range []string{dueLater, expired} {
+		events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+		s.Require().NoError(err)
+		s.NotEqual(domain.EventTypeDeadlineWarning, events[len(events)-1].Type)
+	}
+
+	// A task is warned once per deadline
+	count, err = s.taskService.WarnApproachingDeadlines(ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+}
+
+func (s *TaskServiceTestSuite) TestProcessExpiredDeadlines_ConfiguredExpiry() {
+	ctx := context.Background()
+
+	s.ErrorIs(s.taskService.SetDeadlineExpiry(ctx, s.workspaceID, map[string]string{"NEW": "NEW"}), domain.ErrValidation)
+	s.ErrorIs(s.taskService.SetDeadlineExpiry(ctx, s.workspaceID, map[string]string{"STUCK": "NEW"}), domain.ErrValidation)
+	s.Require().NoError(s.taskService.SetDeadlineExpiry(ctx, s.workspaceID, map[string]string{
+		"IN_PROGRESS": "NEW",
+		"NEW":         "CANCELLED",
+	}))
+
+	inProgress := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(s.agent2ID),
+		factory.WithStatusDeadline(time.Now().Add(-time.Hour))).ID
+	unclaimed := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusNew),
+		factory.WithStatusDeadline(time.Now().Add(-time.Hour))).ID
+	blocked := factory.CreateTask(s.T(), s.pool, s.workspaceID, s.agent1ID,
+		factory.WithStatus(domain.TaskStatusBlocked), factory.WithAssignee(s.agent2ID),
+		factory.WithStatusDeadline(time.Now().Add(-time.Hour))).ID
+
+	count, err := s.taskService.ProcessExpiredDeadlines(ctx)
+	s.Require().NoError(err)
+	s.Equal(3, count)
+
+	// Back to the pool with a fresh NEW deadline
+	task, err := s.taskRepo.GetByID(ctx, inProgress)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, task.Status)
+	s.Nil(task.AssigneeID)
+	s.Require().NotNil(task.StatusDeadlineAt)
+	s.True(task.StatusDeadlineAt.After(time.Now()))
+
+	events, err := s.eventRepo.GetByTaskID(ctx, inProgress)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeDeadlineExpired, last.Type)
+	s.Equal(domain.TaskStatusNew, *last.NewStatus)
+
+	task, err = s.taskRepo.GetByID(ctx, unclaimed)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusCancelled, task.Status)
+	s.Nil(task.StatusDeadlineAt)
+
+	// Statuses left out keep the default
+	task, err = s.taskRepo.GetByID(ctx, blocked)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusStuck, task.Status)
+	s.Equal(&s.agent2ID, task.AssigneeID)
+}
+
+func (s *TaskServiceTestSuite) TestMaxAttempts_HoldsTaskForHumanReview() {
+	ctx := context.Background()
+
+	s.ErrorIs(s.taskService.SetMaxAttempts(ctx, s.workspaceID, domain.MaxAttemptsLimit+1), domain.ErrValidation)
+	s.Require().NoError(s.taskService.SetMaxAttempts(ctx, s.workspaceID, 2))
+
+	taskID := s.createTask(ctx, domain.TaskStatusStuck, &s.agent1ID, nil)
+
+	// First hand-off: taken over
+	_, err := s.taskService.TakeoverTask(ctx, taskID, s.agent2ID, "Taking over")
+	s.Require().NoError(err)
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(1, task.Attempts)
+	s.False(task.IsHeldForReview())
+
+	// Second hand-off: given back to the pool, reaching the limit
+	_, err = s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+		TaskID:    taskID,
+		AgentID:   s.agent2ID,
+		NewStatus: domain.TaskStatusNew,
+		Comment:   "Giving up",
+	})
+	s.Require().NoError(err)
+	task, err = s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Equal(2, task.Attempts)
+	s.True(task.IsHeldForReview())
+
+	events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+	s.Require().NoError(err)
+	last := events[len(events)-1]
+	s.Equal(domain.EventTypeAttemptsExceeded, last.Type)
+	s.True(last.IsSystemEvent())
+	s.EqualValues(2, last.Data["max_attempts"])
+
+	_, err = s.taskService.ClaimTask(ctx, taskID, s.agent1ID, "Claiming")
+	s.ErrorIs(err, domain.ErrHumanReviewHold)
+
+	// An operator clears the hold
+	_, err = s.taskService.ClearHumanReview(ctx, s.workspaceID, taskID, "")
+	s.Require().NoError(err)
+	_, err = s.taskService.ClearHumanReview(ctx, s.workspaceID, taskID, "")
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+
+	_, err = s.taskService.ClaimTask(ctx, taskID, s.agent1ID, "Claiming")
+	s.Require().NoError(err)
+	task, err = s.taskRepo.GetByID(ctx, taskID)
+	s.Require().NoError(err)
+	s.Zero(task.Attempts)
+}
+
+func (s *TaskServiceTestSuite) TestApprovalGate() {
+	ctx := context.Background()
+
+	task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID:      s.workspaceID,
+		CreatorID:        s.agent1ID,
+		Title:            "Deploy to production",
+		Description:      "Needs an operator's sign-off",
+		AssigneeID:       &s.agent2ID,
+		Visibility:       domain.TaskVisibilityPublic,
+		Priority:         domain.TaskPriorityNormal,
+		RequiresApproval: true,
+	})
+	s.Require().NoError(err)
+	s.True(task.RequiresApproval)
+
+	transition := func(agentID string, status domain.TaskStatus) error {
+		_, err := s.taskService.TransitionStatus(ctx, service.TransitionStatusParams{
+			TaskID:    task.ID,
+			AgentID:   agentID,
+			NewStatus: status,
+			Comment:   "Deployed",
+			Artefact:  "https://ci.example.com/deploys/1",
+		})
+		return err
+	}
+
+	// Agents cannot complete the task on their own authority
+	s.ErrorIs(transition(s.agent2ID, domain.TaskStatusDone), domain.ErrApprovalRequired)
+	s.Require().NoError(transition(s.agent2ID, domain.TaskStatusWaitingApproval))
+
+	updated, err := s.taskRepo.GetByID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusWaitingApproval, updated.Status)
+	s.Nil(updated.StatusDeadlineAt, "WAITING_APPROVAL has no deadline")
+	s.ErrorIs(transition(s.agent2ID, domain.TaskStatusDone), domain.ErrApprovalRequired)
+	s.ErrorIs(transition(s.agent1ID, domain.TaskStatusCancelled), domain.ErrApprovalRequired)
+
+	// Rejection needs a reason and returns the task to the pool
+	_, err = s.taskService.RejectTask(ctx, s.workspaceID, task.ID, " ")
+	s.ErrorIs(err, domain.ErrEmptyComment)
+	event, err := s.taskService.RejectTask(ctx, s.workspaceID, task.ID, "Wrong release tag")
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeApprovalRejected, event.Type)
+	s.Nil(event.ActorID)
+
+	updated, err = s.taskRepo.GetByID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusNew, updated.Status)
+	s.Nil(updated.AssigneeID)
+
+	_, err = s.taskService.ApproveTask(ctx, s.workspaceID, task.ID, domain.TaskStatusDone, "")
+	s.ErrorIs(err, domain.ErrInvalidTransition)
+
+	// Second round: approved
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Retrying with the right tag")
+	s.Require().NoError(err)
+	s.Require().NoError(transition(s.agent2ID, domain.TaskStatusWaitingApproval))
+
+	event, err = s.taskService.ApproveTask(ctx, s.workspaceID, task.ID, domain.TaskStatusDone, "")
+	s.Require().NoError(err)
+	s.Equal(domain.EventTypeApprovalGranted, event.Type)
+
+	updated, err = s.taskRepo.GetByID(ctx, task.ID)
+	s.Require().NoError(err)
+	s.Equal(domain.TaskStatusDone, updated.Status)
+	s.Require().NotNil(updated.Artefact)
+	s.Equal("https://ci.example.com/deploys/1", *updated.Artefact)
+}
+
+// TestEventOutbox_PublishesTaskEventsInOrder tests that task events reach the
+// event webhook once each, in order per task, and that a failed delivery holds
+// back later events of its task until a retry with the same delivery ID succeeds.
+func (s *TaskServiceTestSuite) TestEventOutbox_PublishesTaskEventsInOrder() {
+	ctx := context.Background()
+
+	type delivery struct {
+		header  http.Header
+		payload client.WebhookPayload
+	}
+	var mu sync.Mutex
+	var deliveries []delivery
+	failNext := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload client.WebhookPayload
+		s.NoError(json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries = append(deliveries, delivery{header: r.Header.Clone(), payload: payload})
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	outboxService := service.NewOutboxService(
+		s.pool,
+		repository.NewOutboxRepository(s.pool),
+		repository.NewWebhookSecretRepository(s.pool),
+		service.ReportDeliveryConfig{WebhookSecret: "server-secret"},
+		nil,
+	)
+
+	_, err := s.taskService.SetEventWebhook(ctx, s.workspaceID, &domain.EventWebhook{
+		URL:        server.URL,
+		EventTypes: []domain.EventType{"bogus"},
+	})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	hook, err := s.taskService.SetEventWebhook(ctx, s.workspaceID, &domain.EventWebhook{
+		URL:        server.URL,
+		EventTypes: []domain.EventType{domain.EventTypeCreated, domain.EventTypeClaimed, domain.EventTypeCreated},
+	})
+	s.Require().NoError(err)
+	s.Equal([]domain.EventType{domain.EventTypeClaimed, domain.EventTypeCreated}, hook.EventTypes)
+
+	task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Published task",
+		Description: "Event outbox",
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityNormal,
+	})
+	s.Require().NoError(err)
+	_, err = s.taskService.CommentTask(ctx, task.ID, s.agent1ID, "Not published", nil, nil)
+	s.Require().NoError(err)
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Taking this task")
+	s.Require().NoError(err)
+
+	// The created event fails, and the claimed event waits behind it
+	count, err := outboxService.PublishPending(ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+	s.Require().Len(deliveries, 1)
+
+	messages, total, err := outboxService.ListMessages(ctx, s.workspaceID, nil, 50, 0)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+	s.Equal(domain.OutboxPending, messages[1].Status)
+	s.Equal(1, messages[1].Attempts)
+	s.Require().NotNil(messages[1].LastError)
+
+	_, err = s.pool.Exec(ctx, `UPDATE event_outbox SET next_attempt_at = NOW() - INTERVAL '1 minute' WHERE task_id = $1`, task.ID)
+	s.Require().NoError(err)
+	count, err = outboxService.PublishPending(ctx)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+
+	s.Require().Len(deliveries, 3)
+	s.Equal(client.EventType(domain.EventTypeCreated), deliveries[1].payload.Event.Type)
+	s.Equal(client.EventType(domain.EventTypeClaimed), deliveries[2].payload.Event.Type)
+	s.Equal(s.workspaceID, deliveries[2].payload.WorkspaceID)
+	s.Equal(task.ID, deliveries[2].payload.Event.TaskID)
+	s.Equal("claimed", deliveries[2].header.Get(service.HeaderEventType))
+
+	// The retry reuses the delivery ID so receivers can drop duplicates
+	s.Equal(deliveries[0].payload.DeliveryID, deliveries[1].payload.DeliveryID)
+	s.Equal(deliveries[1].payload.DeliveryID, deliveries[1].header.Get(client.HeaderDelivery))
+	s.NotEqual(deliveries[1].payload.DeliveryID, deliveries[2].payload.DeliveryID)
+
+	delivered := domain.OutboxDelivered
+	messages, total, err = outboxService.ListMessages(ctx, s.workspaceID, &delivered, 50, 0)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+	s.NotNil(messages[0].DeliveredAt)
+
+	_, err = outboxService.RetryMessage(ctx, s.workspaceID, messages[0].ID)
+	s.ErrorIs(err, domain.ErrValidation)
+
+	count, err = outboxService.PublishPending(ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+}
+
+// fakeEventBroker records the messages published to it.
+type fakeEventBroker struct {
+	messages []service.BrokerMessage
+}
+
+func (b *fakeEventBroker) Publish(_ context.Context, message service.BrokerMessage) error {
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func (b *fakeEventBroker) Close() error {
+	return nil
+}
+
+// TestEventOutbox_PublishesToBrokerTopic tests that task events queued for the
+// workspace's broker topic wait for a scheduler with a broker and are not held
+// back by a failing event webhook.
+func (s *TaskServiceTestSuite) TestEventOutbox_PublishesToBrokerTopic() {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := s.taskService.SetEventBroker(ctx, s.workspaceID, &domain.EventBrokerTopic{Topic: "sloptask.*"})
+	s.ErrorIs(err, domain.ErrValidation)
+
+	topic, err := s.taskService.SetEventBroker(ctx, s.workspaceID, &domain.EventBrokerTopic{Topic: " sloptask.acme.events "})
+	s.Require().NoError(err)
+	s.Equal("sloptask.acme.events", topic.Topic)
+	s.Empty(topic.EventTypes)
+	_, err = s.taskService.SetEventWebhook(ctx, s.workspaceID, &domain.EventWebhook{URL: server.URL})
+	s.Require().NoError(err)
+
+	task, err := s.taskService.CreateTask(ctx, service.CreateTaskParams{
+		WorkspaceID: s.workspaceID,
+		CreatorID:   s.agent1ID,
+		Title:       "Brokered task",
+		Description: "Event broker",
+		Visibility:  domain.TaskVisibilityPublic,
+		Priority:    domain.TaskPriorityNormal,
+	})
+	s.Require().NoError(err)
+	_, err = s.taskService.ClaimTask(ctx, task.ID, s.agent2ID, "Taking this task")
+	s.Require().NoError(err)
+
+	outboxRepo := repository.NewOutboxRepository(s.pool)
+	secretRepo := repository.NewWebhookSecretRepository(s.pool)
+
+	// Without a broker only the webhook is tried
+	count, err := service.NewOutboxService(s.pool, outboxRepo, secretRepo, service.ReportDeliveryConfig{}, nil).PublishPending(ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+
+	broker := &fakeEventBroker{}
+	count, err = service.NewOutboxService(s.pool, outboxRepo, secretRepo, service.ReportDeliveryConfig{}, broker).PublishPending(ctx)
+	s.Require().NoError(err)
+	s.Equal(2, count)
+
+	s.Require().Len(broker.messages, 2)
+	for i, eventType := range []domain.EventType{domain.EventTypeCreated, domain.EventTypeClaimed} {
+		message := broker.messages[i]
+		s.Equal("sloptask.acme.events", message.Topic)
+		s.Equal(task.ID, message.Key)
+		s.Equal(string(eventType), message.Headers[service.HeaderEventType])
+
+		var payload client.WebhookPayload
+		s.Require().NoError(json.Unmarshal(message.Body, &payload))
+		s.Equal(message.ID, payload.DeliveryID)
+		s.Equal(client.EventType(eventType), payload.Event.Type)
+	}
+
+	pending := domain.OutboxPending
+	messages, total, err := outboxRepo.List(ctx, s.workspaceID, &pending, 50, 0)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+	for _, message := range messages {
+		s.Equal(domain.OutboxTargetWebhook, message.TargetType)
+	}
+}
+
+// TestSeed_CreatesDemoWorkspace tests that seeding creates working agents and a
+// partly worked task graph, and refuses to seed a taken slug twice.
+func (s *TaskServiceTestSuite) TestSeed_CreatesDemoWorkspace() {
+	ctx := context.Background()
+	seedService := service.NewSeedService(s.pool, s.workspaceRepo, s.agentRepo, s.taskRepo, s.taskService)
+
+	result, err := seedService.Seed(ctx, service.SeedParams{})
+	s.Require().NoError(err)
+	s.Equal(service.DefaultSeedSlug, result.Workspace.Slug)
+
+	s.Require().Len(result.Agents, 3)
+	for _, agent := range result.Agents {
+		found, err := s.agentRepo.GetByToken(ctx, agent.Token)
+		s.Require().NoError(err)
+		s.Equal(result.Workspace.ID, found.WorkspaceID)
+	}
+
+	statuses := map[string]domain.TaskStatus{}
+	for _, task := range result.Tasks {
+		statuses[task.Title] = task.Status
+	}
+	s.Equal(domain.TaskStatusDone, statuses["Design the invoice API"])
+	s.Equal(domain.TaskStatusInProgress, statuses["Implement the invoice endpoints"])
+	s.Equal(domain.TaskStatusNew, statuses["Release invoicing"])
+
+	events, err := s.eventRepo.GetByTaskID(ctx, result.Tasks[0].ID)
+	s.Require().NoError(err)
+	s.Len(events, 4, "created, claimed, submitted for review, approved")
+
+	_, err = seedService.Seed(ctx, service.SeedParams{})
+	s.ErrorIs(err, domain.ErrWorkspaceExists)
+}
+
+func (s *TaskServiceTestSuite) TestPruneEvents() {
+	ctx := context.Background()
+
+	taskID := s.createTask(ctx, domain.TaskStatusNew, nil, nil)
+	old, err := s.taskService.CommentTask(ctx, taskID, s.agent1ID, "old note", nil, nil)
+	s.Require().NoError(err)
+	mentioned, err := s.taskService.CommentTask(ctx, taskID, s.agent1ID, "old mention", nil, nil)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(ctx, "INSERT INTO task_mentions (event_id, agent_id) VALUES ($1, $2)", mentioned.ID, s.agent2ID)
+	s.Require().NoError(err)
+	question, err := s.taskService.CommentTask(ctx, taskID, s.agent1ID, "old question", nil, nil)
+	s.Require().NoError(err)
+	oldAnswer, err := s.taskService.CommentTask(ctx, taskID, s.agent2ID, "old answer", nil, &question.ID)
+	s.Require().NoError(err)
+	_, err = s.pool.Exec(ctx, "UPDATE task_events SET created_at = NOW() - INTERVAL '100 days' WHERE task_id = $1", taskID)
+	s.Require().NoError(err)
+	reply, err := s.taskService.CommentTask(ctx, taskID, s.agent1ID, "recent follow-up", nil, &oldAnswer.ID)
+	s.Require().NoError(err)
+
+	_, err = s.taskService.PruneEvents(ctx, s.workspaceID, time.Now())
+	s.Error(err, "unsettled events are never pruned")
+
+	// Only the old comment goes: the task keeps its created event, the
+	// mentioned comment and the whole thread still being replied to
+	pruned, err := s.taskService.PruneEvents(ctx, s.workspaceID, time.Now().Add(-90*24*time.Hour))
+	s.Require().NoError(err)
+	s.EqualValues(1, pruned)
+
+	events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+	s.Require().NoError(err)
+	ids := make([]string, len(events))
+	types := make([]domain.EventType, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+		types[i] = event.Type
+	}
+	s.NotContains(ids, old.ID)
+	s.Contains(ids, mentioned.ID)
+	s.Contains(ids, question.ID)
+	s.Contains(ids, oldAnswer.ID)
+	s.Contains(ids, reply.ID)
+	s.Contains(types, domain.EventTypeCreated)
 }
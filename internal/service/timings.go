@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// GetTimings breaks down how long a task visible to the agent spent in each
+// status and with each assignee, reconstructed from its events.
+func (s *TaskService) GetTimings(ctx context.Context, taskID, agentID string) (*domain.TaskTimings, error) {
+	agent, err := s.getActiveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !task.IsVisibleTo(agent) {
+		return nil, fmt.Errorf("%w: task %s is not visible to agent %s", domain.ErrPermissionDenied, taskID, agentID)
+	}
+
+	events, err := s.eventRepo.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("get events: %w", err)
+	}
+
+	timings := domain.ComputeTaskTimings(task, events, time.Now())
+
+	for i := range timings.Assignees {
+		assignee, err := s.agentRepo.GetByID(ctx, timings.Assignees[i].AgentID)
+		if errors.Is(err, domain.ErrAgentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get assignee: %w", err)
+		}
+		timings.Assignees[i].AgentName = assignee.Name
+	}
+
+	return timings, nil
+}
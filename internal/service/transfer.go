@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// TransferTaskParams holds parameters for moving a task to another workspace.
+type TransferTaskParams struct {
+	TaskID            string
+	WorkspaceID       string                 // the task must belong to this workspace
+	TargetWorkspaceID string                 // where the task goes
+	CreatorID         string                 // agent of the target workspace recorded as the new creator
+	Visibility        *domain.TaskVisibility // Optional: defaults to the task's visibility
+	Comment           string
+}
+
+// TransferTask moves an open task with its events, checklist and revisions to
+// another workspace, for work triaged into the wrong team's workspace. The task
+// arrives NEW and unassigned with a fresh deadline. Agents of the old workspace
+// lose every hold on it: its creator is replaced, checklist claims are released,
+// and its blockers, queue and external wait are dropped. Labels the target
+// workspace has not registered are dropped, and dependents in the old workspace
+// no longer wait on it, as when it is deleted.
+func (s *TaskService) TransferTask(ctx context.Context, params TransferTaskParams) (*domain.TaskEvent, error) {
+	if params.TargetWorkspaceID == params.WorkspaceID {
+		return nil, fmt.Errorf("%w: target workspace must differ from the task's workspace", domain.ErrValidation)
+	}
+	if params.Visibility != nil && !params.Visibility.IsValid() {
+		return nil, domain.ErrInvalidVisibility
+	}
+
+	source, err := s.workspaceRepo.GetByID(ctx, params.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.workspaceRepo.GetByID(ctx, params.TargetWorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, workspace := range []*domain.Workspace{source, target} {
+		if workspace.IsArchived() {
+			return nil, fmt.Errorf("%w: workspace %s is frozen", domain.ErrWorkspaceArchived, workspace.Slug)
+		}
+	}
+
+	creator, err := s.agentRepo.GetByID(ctx, params.CreatorID)
+	if err != nil || creator.WorkspaceID != target.ID || !creator.IsActive {
+		return nil, fmt.Errorf("%w: creator_id must be an active agent of the target workspace", domain.ErrValidation)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	task, err := s.taskRepo.GetByIDForUpdate(ctx, tx, params.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkspaceID != source.ID {
+		return nil, fmt.Errorf("%w: task %s", domain.ErrTaskNotFound, task.ID)
+	}
+	if task.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: only open tasks can be transferred, task is %s", domain.ErrInvalidTransition, task.Status)
+	}
+
+	missing, err := s.labelRepo.LockRegistered(ctx, tx, target.ID, task.Labels)
+	if err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for _, label := range task.Labels {
+		if !slices.Contains(missing, label) {
+			labels = append(labels, label)
+		}
+	}
+
+	visibility := task.Visibility
+	if params.Visibility != nil {
+		visibility = *params.Visibility
+	}
+
+	unblocked, err := s.taskRepo.RemoveBlocker(ctx, tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	releasedClaims, err := s.checklistRepo.ReleaseClaims(ctx, tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	newStatus := domain.TaskStatusNew
+	err = s.taskRepo.Transfer(ctx, tx, task.ID, repository.TaskTransfer{
+		WorkspaceID:      target.ID,
+		CreatorID:        creator.ID,
+		Visibility:       visibility,
+		Labels:           labels,
+		StatusDeadlineAt: CalculateDeadline(target, newStatus),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The blockers left behind may have inherited their priority from this task
+	if _, err := s.propagatePriorityInheritance(ctx, tx, task.BlockedBy); err != nil {
+		return nil, err
+	}
+
+	comment := strings.TrimSpace(params.Comment)
+	if comment == "" {
+		comment = fmt.Sprintf("Transferred from workspace %s to %s", source.Slug, target.Slug)
+	}
+
+	data := map[string]any{
+		"from_workspace_id":         source.ID,
+		"to_workspace_id":           target.ID,
+		"previous_creator_id":       task.CreatorID,
+		"removed_from_blocked_by":   unblocked,
+		"checklist_claims_released": releasedClaims,
+	}
+	if task.AssigneeID != nil {
+		data["previous_assignee_id"] = *task.AssigneeID
+	}
+	if len(task.BlockedBy) > 0 {
+		data["dropped_blocked_by"] = task.BlockedBy
+	}
+	if task.Queue != nil {
+		data["dropped_queue"] = *task.Queue
+	}
+	if len(missing) > 0 {
+		data["dropped_labels"] = missing
+	}
+	if visibility != task.Visibility {
+		data["previous_visibility"] = task.Visibility
+	}
+
+	oldStatus := task.Status
+	event := &domain.TaskEvent{
+		TaskID:    task.ID,
+		ActorID:   nil, // operator action
+		Type:      domain.EventTypeTransferred,
+		OldStatus: &oldStatus,
+		NewStatus: &newStatus,
+		Comment:   comment,
+		Data:      data,
+	}
+	if err := s.createEventAndCommit(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	slog.Info("task transferred",
+		"task_id", task.ID,
+		"from_workspace_id", source.ID,
+		"to_workspace_id", target.ID,
+		"old_status", oldStatus,
+		"removed_from_blocked_by", unblocked,
+		"event_id", event.ID,
+	)
+
+	return event, nil
+}
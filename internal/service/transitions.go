@@ -0,0 +1,187 @@
+package service
+
+import (
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// TransitionActor is an agent's relation to a task when changing its status.
+type TransitionActor string
+
+const (
+	ActorAssignee   TransitionActor = "assignee"
+	ActorCreator    TransitionActor = "creator"
+	ActorOtherAgent TransitionActor = "other agent"
+	ActorOperator   TransitionActor = "operator" // holder of the admin token, not an agent
+)
+
+// TransitionRule is a status change, the API operation making it and who may use it.
+type TransitionRule struct {
+	From      domain.TaskStatus
+	To        domain.TaskStatus
+	Operation string
+	Actors    []TransitionActor
+}
+
+// HasActor reports whether the actor may make the transition.
+func (r TransitionRule) HasActor(actor TransitionActor) bool {
+	for _, a := range r.Actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// taskStatuses lists every status in state machine order.
+var taskStatuses = []domain.TaskStatus{
+	domain.TaskStatusNew,
+	domain.TaskStatusInProgress,
+	domain.TaskStatusNeedsReview,
+	domain.TaskStatusBlocked,
+	domain.TaskStatusAwaitingExternal,
+	domain.TaskStatusWaitingApproval,
+	domain.TaskStatusStuck,
+	domain.TaskStatusDone,
+	domain.TaskStatusCancelled,
+}
+
+// transitionOperation is an API operation that changes a task's status.
+type transitionOperation struct {
+	name    string
+	targets []domain.TaskStatus // nil for any status
+	check   func(v *Validator, task *domain.Task, agent *domain.Agent, to domain.TaskStatus) error
+
+	// operator operations are made with the admin token, so probed as ActorOperator only
+	operator bool
+}
+
+var transitionOperations = []transitionOperation{
+	{
+		name: "POST /claim",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, _ domain.TaskStatus) error {
+			return v.CanClaim(task, agent)
+		},
+		targets: []domain.TaskStatus{
+			domain.TaskStatusInProgress,
+		},
+	},
+	{
+		name: "PATCH /status",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, to domain.TaskStatus) error {
+			return v.CanTransitionStatus(task, agent, to)
+		},
+	},
+	{
+		name: "POST /escalate",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, _ domain.TaskStatus) error {
+			return v.CanEscalate(task, agent)
+		},
+		targets: []domain.TaskStatus{domain.TaskStatusBlocked},
+	},
+	{
+		name: "POST /await-external",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, _ domain.TaskStatus) error {
+			return v.CanAwaitExternal(task, agent)
+		},
+		targets: []domain.TaskStatus{domain.TaskStatusAwaitingExternal},
+	},
+	{
+		name: "POST /takeover",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, _ domain.TaskStatus) error {
+			return v.CanTakeover(task, agent)
+		},
+		targets: []domain.TaskStatus{domain.TaskStatusInProgress},
+	},
+	{
+		name: "POST /reopen",
+		check: func(v *Validator, task *domain.Task, agent *domain.Agent, to domain.TaskStatus) error {
+			return v.CanReopen(task, agent, to)
+		},
+		targets: []domain.TaskStatus{domain.TaskStatusNew, domain.TaskStatusInProgress},
+	},
+	{
+		name: "POST /approve (admin)",
+		check: func(v *Validator, task *domain.Task, _ *domain.Agent, to domain.TaskStatus) error {
+			return v.CanResolveApproval(task, to)
+		},
+		targets:  []domain.TaskStatus{domain.TaskStatusDone, domain.TaskStatusInProgress},
+		operator: true,
+	},
+	{
+		name: "POST /reject (admin)",
+		check: func(v *Validator, task *domain.Task, _ *domain.Agent, to domain.TaskStatus) error {
+			return v.CanResolveApproval(task, to)
+		},
+		targets:  []domain.TaskStatus{domain.TaskStatusNew},
+		operator: true,
+	},
+}
+
+// StatusTransitions derives the state machine from the Validator: every status
+// change is probed through every operation as the task's assignee, its creator
+// and another agent of the workspace, or as an operator for admin operations.
+// Documentation generated from it cannot drift from the rules the API enforces.
+func StatusTransitions() []TransitionRule {
+	v := &Validator{}
+	var rules []TransitionRule
+
+	for _, from := range taskStatuses {
+		for _, op := range transitionOperations {
+			targets := op.targets
+			if targets == nil {
+				targets = taskStatuses
+			}
+			for _, to := range targets {
+				if to == from {
+					continue
+				}
+
+				probed := []TransitionActor{ActorAssignee, ActorCreator, ActorOtherAgent}
+				if op.operator {
+					probed = []TransitionActor{ActorOperator}
+				}
+
+				var actors []TransitionActor
+				for _, actor := range probed {
+					task, agent := transitionProbe(from, actor)
+					if op.check(v, task, agent, to) == nil {
+						actors = append(actors, actor)
+					}
+				}
+				if len(actors) > 0 {
+					rules = append(rules, TransitionRule{From: from, To: to, Operation: op.name, Actors: actors})
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+// transitionProbe builds a public task in the given status and an agent standing
+// in the given relation to it. NEW tasks probed by non-assignees are unassigned.
+func transitionProbe(status domain.TaskStatus, actor TransitionActor) (*domain.Task, *domain.Agent) {
+	const workspaceID, agentID, otherID, thirdID = "workspace", "agent", "other", "third"
+
+	agent := &domain.Agent{ID: agentID, WorkspaceID: workspaceID, IsActive: true}
+	task := &domain.Task{
+		ID:          "task",
+		WorkspaceID: workspaceID,
+		Status:      status,
+		Visibility:  domain.TaskVisibilityPublic,
+		CreatorID:   otherID,
+	}
+
+	assignee := thirdID
+	switch actor {
+	case ActorAssignee:
+		assignee = agentID
+	case ActorCreator:
+		task.CreatorID = agentID
+	}
+	if actor == ActorAssignee || status != domain.TaskStatusNew {
+		task.AssigneeID = &assignee
+	}
+
+	return task, agent
+}
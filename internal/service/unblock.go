@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// resolveDependents runs within tx once blockerID is DONE: every open task it
+// blocks whose other blockers are done as well gets a blockers_resolved system
+// event. In workspaces with auto-unblock, BLOCKED dependents also return to the
+// status they were blocked from, unless they were escalated: an escalation
+// asks for help rather than waiting on a blocker. Returns the number of events.
+//
+// Each dependent is locked before its blockers are checked, so of two blockers
+// completing at once the second waits for the first to commit and sees it DONE.
+func (s *TaskService) resolveDependents(ctx context.Context, tx pgx.Tx, blockerID string) (int, error) {
+	dependentIDs, err := s.taskRepo.FindDependentIDs(ctx, tx, blockerID)
+	if err != nil {
+		return 0, fmt.Errorf("get dependent tasks: %w", err)
+	}
+
+	var workspace *domain.Workspace
+	count := 0
+	for _, dependentID := range dependentIDs {
+		dependent, err := s.taskRepo.GetByIDForUpdate(ctx, tx, dependentID)
+		if errors.Is(err, domain.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return count, fmt.Errorf("lock dependent task %s: %w", dependentID, err)
+		}
+		if dependent.Status == domain.TaskStatusDone || dependent.Status == domain.TaskStatusCancelled {
+			continue
+		}
+
+		unresolved, err := s.taskRepo.CountUnresolvedBlockers(ctx, tx, dependent.ID, blockerID)
+		if err != nil {
+			return count, fmt.Errorf("check blockers of task %s: %w", dependent.ID, err)
+		}
+		if unresolved > 0 {
+			continue
+		}
+
+		if workspace == nil {
+			workspace, err = s.workspaceRepo.GetByID(ctx, dependent.WorkspaceID)
+			if err != nil {
+				return count, fmt.Errorf("get workspace: %w", err)
+			}
+		}
+
+		event := &domain.TaskEvent{
+			TaskID:  dependent.ID,
+			ActorID: nil, // system event
+			Type:    domain.EventTypeBlockersResolved,
+			Comment: fmt.Sprintf("All blockers are done, the last being task %s.", blockerID),
+			Data:    map[string]any{"blocker_id": blockerID},
+		}
+
+		if workspace.AutoUnblock && dependent.Status == domain.TaskStatusBlocked {
+			newStatus, err := s.unblockedStatus(ctx, tx, dependent.ID)
+			if err != nil {
+				return count, err
+			}
+			if newStatus != nil {
+				unblocked, err := s.unblockDependent(ctx, tx, workspace, dependent, *newStatus)
+				if err != nil {
+					return count, err
+				}
+				if unblocked {
+					oldStatus := dependent.Status
+					event.OldStatus = &oldStatus
+					event.NewStatus = newStatus
+					event.Comment = fmt.Sprintf("All blockers are done, the last being task %s. Back to %s.", blockerID, *newStatus)
+				}
+			}
+		}
+
+		if err := s.createEvent(ctx, tx, event); err != nil {
+			return count, fmt.Errorf("create event for dependent task %s: %w", dependent.ID, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// unblockedStatus returns the status a BLOCKED task was blocked from, or nil
+// if it should stay BLOCKED: it was escalated, or its history doesn't tell.
+func (s *TaskService) unblockedStatus(ctx context.Context, tx pgx.Tx, taskID string) (*domain.TaskStatus, error) {
+	event, err := s.eventRepo.GetLastStatusChange(ctx, tx, taskID, domain.TaskStatusBlocked)
+	if err != nil {
+		return nil, fmt.Errorf("get events of task %s: %w", taskID, err)
+	}
+	if event == nil || event.Type == domain.EventTypeEscalated || event.OldStatus == nil {
+		return nil, nil
+	}
+
+	switch *event.OldStatus {
+	case domain.TaskStatusNew, domain.TaskStatusInProgress:
+		return event.OldStatus, nil
+	default:
+		return nil, nil
+	}
+}
+
+// unblockDependent moves a BLOCKED task to newStatus with a fresh deadline.
+// Returning to NEW gives the task back to the pool. Reports false if the task
+// changed concurrently and was left alone.
+func (s *TaskService) unblockDependent(
+	ctx context.Context,
+	tx pgx.Tx,
+	workspace *domain.Workspace,
+	task *domain.Task,
+	newStatus domain.TaskStatus,
+) (bool, error) {
+	assignee := task.AssigneeID
+	if ShouldClearAssignee(newStatus) {
+		assignee = nil
+	}
+
+	err := s.taskRepo.UpdateStatus(ctx, tx, task.ID,
+		domain.TaskStatusBlocked, newStatus,
+		assignee, CalculateDeadline(workspace, newStatus), nil,
+	)
+	if errors.Is(err, domain.ErrTaskAlreadyClaimed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unblock dependent task %s: %w", task.ID, err)
+	}
+
+	slog.Info("dependent task unblocked",
+		"task_id", task.ID,
+		"new_status", newStatus,
+	)
+	return true, nil
+}
@@ -12,11 +12,11 @@ const maxDependencyDepth = 100
 
 // Validator handles permission and state validation for task operations.
 type Validator struct {
-	taskRepo *repository.TaskRepository
+	taskRepo repository.TaskRepository
 }
 
 // NewValidator creates a new Validator.
-func NewValidator(taskRepo *repository.TaskRepository) *Validator {
+func NewValidator(taskRepo repository.TaskRepository) *Validator {
 	return &Validator{
 		taskRepo: taskRepo,
 	}
@@ -44,6 +44,19 @@ func (v *Validator) CanClaim(task *domain.Task, agent *domain.Agent) error {
 		return fmt.Errorf("%w: task %s in workspace %s, agent %s in workspace %s", domain.ErrPermissionDenied, task.ID, task.WorkspaceID, agent.ID, agent.WorkspaceID)
 	}
 
+	// Must have every required capability
+	if !agent.HasCapabilities(task.RequiredCapabilities) {
+		return domain.WithDetails(
+			fmt.Errorf("%w: task %s requires %v, agent %s has %v", domain.ErrMissingCapabilities, task.ID, task.RequiredCapabilities, agent.ID, agent.Capabilities),
+			map[string]any{"missing_capabilities": agent.MissingCapabilities(task.RequiredCapabilities)},
+		)
+	}
+
+	// Must not wait for an operator after changing hands too often
+	if task.IsHeldForReview() {
+		return fmt.Errorf("%w: task %s changed hands %d times", domain.ErrHumanReviewHold, task.ID, task.Attempts)
+	}
+
 	return nil
 }
 
@@ -84,9 +97,65 @@ func (v *Validator) CanTakeover(task *domain.Task, agent *domain.Agent) error {
 		return fmt.Errorf("%w: task %s in workspace %s, agent %s in workspace %s", domain.ErrPermissionDenied, task.ID, task.WorkspaceID, agent.ID, agent.WorkspaceID)
 	}
 
+	// Must not wait for an operator after changing hands too often
+	if task.IsHeldForReview() {
+		return fmt.Errorf("%w: task %s changed hands %d times", domain.ErrHumanReviewHold, task.ID, task.Attempts)
+	}
+
 	return nil
 }
 
+// CanAwaitExternal validates if an agent can park its IN_PROGRESS task on an external system.
+func (v *Validator) CanAwaitExternal(task *domain.Task, agent *domain.Agent) error {
+	// Must be in IN_PROGRESS status
+	if task.Status != domain.TaskStatusInProgress {
+		return fmt.Errorf("%w: task %s is in %s status, expected IN_PROGRESS", domain.ErrInvalidTransition, task.ID, task.Status)
+	}
+
+	// Must be in same workspace
+	if task.WorkspaceID != agent.WorkspaceID {
+		return fmt.Errorf("%w: task %s in workspace %s, agent %s in workspace %s", domain.ErrPermissionDenied, task.ID, task.WorkspaceID, agent.ID, agent.WorkspaceID)
+	}
+
+	// Only assignee knows what the work waits on
+	if !task.IsOwnedBy(agent.ID) {
+		return fmt.Errorf("%w: agent %s is not owner of task %s", domain.ErrNotTaskOwner, agent.ID, task.ID)
+	}
+
+	return nil
+}
+
+// CanReopen validates if an agent can reopen a DONE task into newStatus (NEW or IN_PROGRESS).
+func (v *Validator) CanReopen(task *domain.Task, agent *domain.Agent, newStatus domain.TaskStatus) error {
+	// Must be in DONE status
+	if task.Status != domain.TaskStatusDone {
+		return fmt.Errorf("%w: task %s is in %s status, expected DONE", domain.ErrInvalidTransition, task.ID, task.Status)
+	}
+
+	// Must be in same workspace
+	if task.WorkspaceID != agent.WorkspaceID {
+		return fmt.Errorf("%w: task %s in workspace %s, agent %s in workspace %s", domain.ErrPermissionDenied, task.ID, task.WorkspaceID, agent.ID, agent.WorkspaceID)
+	}
+
+	// Only creator can reopen
+	if !task.IsCreatedBy(agent.ID) {
+		return fmt.Errorf("%w: agent %s is not creator of task %s", domain.ErrNotTaskCreator, agent.ID, task.ID)
+	}
+
+	switch newStatus {
+	case domain.TaskStatusNew:
+		return nil
+	case domain.TaskStatusInProgress:
+		// Resuming work needs someone to do it
+		if task.AssigneeID == nil {
+			return fmt.Errorf("%w: task %s has no assignee to resume work, reopen to NEW instead", domain.ErrInvalidTransition, task.ID)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: task %s cannot be reopened to %s", domain.ErrInvalidTransition, task.ID, newStatus)
+	}
+}
+
 // CanTransitionStatus validates if an agent can transition task to a new status.
 func (v *Validator) CanTransitionStatus(
 	task *domain.Task,
@@ -120,11 +189,14 @@ func (v *Validator) CanTransitionStatus(
 
 	case domain.TaskStatusInProgress:
 		switch newStatus {
-		case domain.TaskStatusDone:
-			// Only assignee can complete
+		case domain.TaskStatusDone, domain.TaskStatusNeedsReview, domain.TaskStatusWaitingApproval:
+			// Only assignee can complete or submit for review or approval
 			if !task.IsOwnedBy(agent.ID) {
 				return fmt.Errorf("%w: agent %s is not owner of task %s", domain.ErrNotTaskOwner, agent.ID, task.ID)
 			}
+			if newStatus == domain.TaskStatusDone && task.RequiresApproval {
+				return fmt.Errorf("%w: move task %s to WAITING_APPROVAL instead of DONE", domain.ErrApprovalRequired, task.ID)
+			}
 		case domain.TaskStatusBlocked:
 			// Assignee can block own task
 			if task.IsOwnedBy(agent.ID) {
@@ -132,6 +204,9 @@ func (v *Validator) CanTransitionStatus(
 			}
 			// Others can escalate (handled by CanEscalate)
 			return fmt.Errorf("%w: agent %s must use escalate operation for task %s", domain.ErrPermissionDenied, agent.ID, task.ID)
+		case domain.TaskStatusAwaitingExternal:
+			// Needs an external reference (handled by CanAwaitExternal)
+			return fmt.Errorf("%w: use await-external operation for task %s", domain.ErrInvalidTransition, task.ID)
 		case domain.TaskStatusNew:
 			// Only assignee can release task
 			if !task.IsOwnedBy(agent.ID) {
@@ -146,6 +221,26 @@ func (v *Validator) CanTransitionStatus(
 			return fmt.Errorf("%w: task %s cannot transition IN_PROGRESS -> %s", domain.ErrInvalidTransition, task.ID, newStatus)
 		}
 
+	case domain.TaskStatusNeedsReview:
+		switch newStatus {
+		case domain.TaskStatusDone, domain.TaskStatusInProgress, domain.TaskStatusWaitingApproval:
+			// Another agent approves (DONE, or WAITING_APPROVAL to pass it on to an operator)
+			// or rejects (IN_PROGRESS); assignee cannot review own work
+			if task.IsOwnedBy(agent.ID) {
+				return fmt.Errorf("%w: agent %s cannot review own task %s", domain.ErrPermissionDenied, agent.ID, task.ID)
+			}
+			if newStatus == domain.TaskStatusDone && task.RequiresApproval {
+				return fmt.Errorf("%w: move task %s to WAITING_APPROVAL instead of DONE", domain.ErrApprovalRequired, task.ID)
+			}
+		case domain.TaskStatusCancelled:
+			// Only creator can cancel
+			if !task.IsCreatedBy(agent.ID) {
+				return fmt.Errorf("%w: agent %s is not creator of task %s", domain.ErrNotTaskCreator, agent.ID, task.ID)
+			}
+		default:
+			return fmt.Errorf("%w: task %s cannot transition NEEDS_REVIEW -> %s", domain.ErrInvalidTransition, task.ID, newStatus)
+		}
+
 	case domain.TaskStatusBlocked:
 		switch newStatus {
 		case domain.TaskStatusInProgress:
@@ -167,6 +262,31 @@ func (v *Validator) CanTransitionStatus(
 			return fmt.Errorf("%w: task %s cannot transition BLOCKED -> %s", domain.ErrInvalidTransition, task.ID, newStatus)
 		}
 
+	case domain.TaskStatusAwaitingExternal:
+		switch newStatus {
+		case domain.TaskStatusInProgress:
+			// Assignee resumes early; integrations resolve through ResolveExternal
+			if !task.IsOwnedBy(agent.ID) {
+				return fmt.Errorf("%w: agent %s is not owner of task %s", domain.ErrNotTaskOwner, agent.ID, task.ID)
+			}
+		case domain.TaskStatusNew:
+			// Creator or assignee can release
+			if !task.IsCreatedBy(agent.ID) && !task.IsOwnedBy(agent.ID) {
+				return fmt.Errorf("%w: agent %s is neither creator nor assignee of task %s", domain.ErrPermissionDenied, agent.ID, task.ID)
+			}
+		case domain.TaskStatusCancelled:
+			// Only creator can cancel
+			if !task.IsCreatedBy(agent.ID) {
+				return fmt.Errorf("%w: agent %s is not creator of task %s", domain.ErrNotTaskCreator, agent.ID, task.ID)
+			}
+		default:
+			return fmt.Errorf("%w: task %s cannot transition AWAITING_EXTERNAL -> %s", domain.ErrInvalidTransition, task.ID, newStatus)
+		}
+
+	case domain.TaskStatusWaitingApproval:
+		// Only an operator resolves an approval (see CanResolveApproval)
+		return fmt.Errorf("%w: task %s waits for an operator", domain.ErrApprovalRequired, task.ID)
+
 	case domain.TaskStatusStuck:
 		switch newStatus {
 		case domain.TaskStatusInProgress:
@@ -201,6 +321,21 @@ func (v *Validator) CanTransitionStatus(
 	return nil
 }
 
+// CanResolveApproval validates an operator's decision on a WAITING_APPROVAL task:
+// approval moves it to DONE or back to IN_PROGRESS, rejection returns it to NEW.
+func (v *Validator) CanResolveApproval(task *domain.Task, newStatus domain.TaskStatus) error {
+	if task.Status != domain.TaskStatusWaitingApproval {
+		return fmt.Errorf("%w: task %s is %s, not WAITING_APPROVAL", domain.ErrInvalidTransition, task.ID, task.Status)
+	}
+
+	switch newStatus {
+	case domain.TaskStatusDone, domain.TaskStatusInProgress, domain.TaskStatusNew:
+		return nil
+	default:
+		return fmt.Errorf("%w: task %s cannot transition WAITING_APPROVAL -> %s", domain.ErrInvalidTransition, task.ID, newStatus)
+	}
+}
+
 // CheckBlockedByResolved checks if all blocker tasks are in DONE status.
 func (v *Validator) CheckBlockedByResolved(ctx context.Context, blockedBy []string) error {
 	if len(blockedBy) == 0 {
@@ -212,21 +347,47 @@ func (v *Validator) CheckBlockedByResolved(ctx context.Context, blockedBy []stri
 		return fmt.Errorf("get blocker tasks: %w", err)
 	}
 
-	// Check if we found all blocker tasks
-	if len(tasks) != len(blockedBy) {
-		return fmt.Errorf("%w: some blocker tasks not found", domain.ErrUnresolvedBlockers)
-	}
-
-	// Check if all are in DONE status
+	// Every blocker must exist and be DONE; the details list all that aren't
+	found := make(map[string]bool, len(tasks))
+	unresolved := []map[string]any{}
 	for _, task := range tasks {
+		found[task.ID] = true
 		if task.Status != domain.TaskStatusDone {
-			return fmt.Errorf("%w: task %s is in %s status", domain.ErrUnresolvedBlockers, task.ID, task.Status)
+			unresolved = append(unresolved, map[string]any{"id": task.ID, "status": task.Status})
+		}
+	}
+	missing := []string{}
+	for _, id := range blockedBy {
+		if !found[id] {
+			missing = append(missing, id)
 		}
 	}
+	details := map[string]any{"unresolved_blockers": unresolved, "missing_blockers": missing}
+
+	if len(missing) > 0 {
+		return domain.WithDetails(fmt.Errorf("%w: some blocker tasks not found", domain.ErrUnresolvedBlockers), details)
+	}
+	if len(unresolved) > 0 {
+		return domain.WithDetails(
+			fmt.Errorf("%w: task %s is in %s status", domain.ErrUnresolvedBlockers, unresolved[0]["id"], unresolved[0]["status"]),
+			details,
+		)
+	}
 
 	return nil
 }
 
+// AllowedStatuses lists the statuses agent may move task to with PATCH /status.
+func (v *Validator) AllowedStatuses(task *domain.Task, agent *domain.Agent) []domain.TaskStatus {
+	allowed := []domain.TaskStatus{}
+	for _, status := range taskStatuses {
+		if status != task.Status && v.CanTransitionStatus(task, agent, status) == nil {
+			allowed = append(allowed, status)
+		}
+	}
+	return allowed
+}
+
 // CheckCyclicDependency performs DFS to detect cycles in task dependencies.
 // This is called when transitioning to IN_PROGRESS status.
 func (v *Validator) CheckCyclicDependency(
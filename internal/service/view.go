@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// ViewService manages the saved task list views of agents.
+type ViewService struct {
+	viewRepo *repository.ViewRepository
+}
+
+// NewViewService creates a new ViewService.
+func NewViewService(viewRepo *repository.ViewRepository) *ViewService {
+	return &ViewService{viewRepo: viewRepo}
+}
+
+// CreateView saves a named GET /tasks query for the agent.
+func (s *ViewService) CreateView(ctx context.Context, agentID, name string, params map[string]string) (*domain.SavedView, error) {
+	name, err := domain.NormalizeViewName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateViewParams(params); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	view := &domain.SavedView{
+		AgentID: agentID,
+		Name:    name,
+		Params:  params,
+	}
+	if err := s.viewRepo.Create(ctx, view); err != nil {
+		return nil, err
+	}
+
+	slog.Info("view created",
+		"agent_id", agentID,
+		"view", name,
+	)
+
+	return view, nil
+}
+
+// GetView returns a view of the agent by name.
+func (s *ViewService) GetView(ctx context.Context, agentID, name string) (*domain.SavedView, error) {
+	name, err := domain.NormalizeViewName(name)
+	if err != nil {
+		return nil, domain.ErrViewNotFound
+	}
+	return s.viewRepo.GetByName(ctx, agentID, name)
+}
+
+// ListViews returns all views of the agent.
+func (s *ViewService) ListViews(ctx context.Context, agentID string) ([]*domain.SavedView, error) {
+	return s.viewRepo.ListByAgent(ctx, agentID)
+}
+
+// UpdateView replaces the query of a view of the agent.
+func (s *ViewService) UpdateView(ctx context.Context, agentID, name string, params map[string]string) (*domain.SavedView, error) {
+	name, err := domain.NormalizeViewName(name)
+	if err != nil {
+		return nil, domain.ErrViewNotFound
+	}
+	if err := domain.ValidateViewParams(params); err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	view, err := s.viewRepo.UpdateParams(ctx, agentID, name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("view updated",
+		"agent_id", agentID,
+		"view", name,
+	)
+
+	return view, nil
+}
+
+// DeleteView removes a view of the agent.
+func (s *ViewService) DeleteView(ctx context.Context, agentID, name string) error {
+	name, err := domain.NormalizeViewName(name)
+	if err != nil {
+		return domain.ErrViewNotFound
+	}
+
+	if err := s.viewRepo.Delete(ctx, agentID, name); err != nil {
+		return err
+	}
+
+	slog.Info("view deleted",
+		"agent_id", agentID,
+		"view", name,
+	)
+
+	return nil
+}
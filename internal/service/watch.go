@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// WatchService manages agents watching tasks and their notifications.
+type WatchService struct {
+	watchRepo *repository.WatchRepository
+	taskRepo  repository.TaskRepository
+}
+
+// NewWatchService creates a new WatchService.
+func NewWatchService(watchRepo *repository.WatchRepository, taskRepo repository.TaskRepository) *WatchService {
+	return &WatchService{
+		watchRepo: watchRepo,
+		taskRepo:  taskRepo,
+	}
+}
+
+// Watch subscribes the agent to a task it can see. Watching a task again is a
+// no-op that returns the existing watch.
+func (s *WatchService) Watch(ctx context.Context, taskID string, agent *domain.Agent) (*domain.TaskWatch, error) {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsVisibleTo(agent) {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	watch, err := s.watchRepo.Watch(ctx, task.ID, agent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("task watched", "task_id", task.ID, "agent_id", agent.ID)
+
+	return watch, nil
+}
+
+// Unwatch unsubscribes the agent from a task. Unwatching a task the agent
+// doesn't watch succeeds too.
+func (s *WatchService) Unwatch(ctx context.Context, taskID string, agent *domain.Agent) error {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if task.WorkspaceID != agent.WorkspaceID {
+		return domain.ErrTaskNotFound
+	}
+
+	if err := s.watchRepo.Unwatch(ctx, task.ID, agent.ID); err != nil {
+		return err
+	}
+
+	slog.Info("task unwatched", "task_id", task.ID, "agent_id", agent.ID)
+
+	return nil
+}
+
+// ListNotifications returns the events on the tasks an agent watches and the
+// comments mentioning it, newest first, with their total count.
+func (s *WatchService) ListNotifications(ctx context.Context, filters repository.NotificationFilters) ([]repository.Notification, int, error) {
+	return s.watchRepo.ListNotifications(ctx, filters)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+	"github.com/mtlprog/sloptask/pkg/client"
+)
+
+// WebhookSecretService issues and rotates the secrets signing workspace webhooks.
+type WebhookSecretService struct {
+	pool              *pgxpool.Pool
+	webhookSecretRepo repository.WebhookSecretRepository
+	workspaceRepo     repository.WorkspaceRepository
+}
+
+// NewWebhookSecretService creates a new WebhookSecretService.
+func NewWebhookSecretService(
+	pool *pgxpool.Pool,
+	webhookSecretRepo repository.WebhookSecretRepository,
+	workspaceRepo repository.WorkspaceRepository,
+) *WebhookSecretService {
+	return &WebhookSecretService{
+		pool:              pool,
+		webhookSecretRepo: webhookSecretRepo,
+		workspaceRepo:     workspaceRepo,
+	}
+}
+
+// GetWebhookSecret returns the webhook secret of a workspace.
+func (s *WebhookSecretService) GetWebhookSecret(ctx context.Context, workspaceID string) (*domain.WebhookSecret, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+	return s.webhookSecretRepo.GetByWorkspace(ctx, workspaceID)
+}
+
+// RotateWebhookSecret issues a new webhook secret for the workspace. The current
+// secret, if any, keeps signing deliveries next to the new one for overlap, so
+// receivers can switch to the new secret without rejecting deliveries. With a
+// zero overlap the current secret is replaced at once. A workspace can only run
+// one rotation at a time; complete it first to rotate again.
+func (s *WebhookSecretService) RotateWebhookSecret(ctx context.Context, workspaceID string, overlap time.Duration) (*domain.WebhookSecret, error) {
+	if overlap < 0 || overlap > domain.MaxWebhookRotationOverlap {
+		return nil, fmt.Errorf("%w: overlap must be between 0 and %d minutes", domain.ErrValidation, int(domain.MaxWebhookRotationOverlap.Minutes()))
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.IsArchived() {
+		return nil, fmt.Errorf("%w: cannot rotate the webhook secret of workspace %s", domain.ErrWorkspaceArchived, workspace.Slug)
+	}
+
+	plaintext, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	now := time.Now()
+	secret := &domain.WebhookSecret{WorkspaceID: workspaceID, Secret: plaintext}
+
+	current, err := s.webhookSecretRepo.GetByWorkspaceForUpdate(ctx, tx, workspaceID)
+	switch {
+	case errors.Is(err, domain.ErrWebhookSecretNotFound):
+		// First secret of the workspace: nothing to overlap with
+	case err != nil:
+		return nil, fmt.Errorf("get webhook secret: %w", err)
+	case current.IsRotating(now):
+		return nil, fmt.Errorf("%w: the previous secret signs until %s", domain.ErrRotationInProgress, current.PreviousExpiresAt.Format(time.RFC3339))
+	case overlap > 0:
+		expiresAt := now.Add(overlap)
+		secret.PreviousSecret = &current.Secret
+		secret.PreviousExpiresAt = &expiresAt
+	}
+
+	if err := s.webhookSecretRepo.Save(ctx, tx, secret); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("webhook secret rotated",
+		"workspace_id", workspaceID,
+		"previous_expires_at", secret.PreviousExpiresAt,
+	)
+
+	return secret, nil
+}
+
+// CompleteWebhookSecretRotation stops signing with the previous secret before its
+// overlap ends, once every receiver has switched to the new one.
+func (s *WebhookSecretService) CompleteWebhookSecretRotation(ctx context.Context, workspaceID string) (*domain.WebhookSecret, error) {
+	if _, err := s.workspaceRepo.GetByID(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	secret, err := s.webhookSecretRepo.GetByWorkspaceForUpdate(ctx, tx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !secret.IsRotating(time.Now()) {
+		return nil, domain.ErrNoRotationInProgress
+	}
+
+	if err := s.webhookSecretRepo.ClearPrevious(ctx, tx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("webhook secret rotation completed", "workspace_id", workspaceID)
+
+	secret.PreviousSecret = nil
+	secret.PreviousExpiresAt = nil
+	return secret, nil
+}
+
+// generateWebhookSecret returns a new random webhook secret.
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return domain.WebhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+// signWebhook returns the signature header value for body signed at timestamp
+// with every secret, comma-separated. Receivers accept the delivery if any
+// signature matches a secret they know.
+func signWebhook(secrets []string, timestamp time.Time, body []byte) string {
+	signatures := make([]string, len(secrets))
+	for i, secret := range secrets {
+		signatures[i] = client.Sign([]byte(secret), timestamp, body)
+	}
+	return strings.Join(signatures, ",")
+}
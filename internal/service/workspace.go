@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// DefaultDeleteBatchSize is how many tasks DeleteWorkspace removes per transaction.
+const DefaultDeleteBatchSize = 1000
+
+// WorkspaceService archives, deletes and clones workspaces on behalf of operators.
+// Every change is recorded in the admin audit log.
+type WorkspaceService struct {
+	pool          *pgxpool.Pool
+	workspaceRepo repository.WorkspaceRepository
+	agentRepo     repository.AgentRepository
+	readTokenRepo *repository.ReadTokenRepository
+	scheduleRepo  *repository.ScheduleRepository
+	reportRepo    *repository.ReportRepository
+	auditRepo     *repository.AuditRepository
+}
+
+// NewWorkspaceService creates a new WorkspaceService.
+func NewWorkspaceService(
+	pool *pgxpool.Pool,
+	workspaceRepo repository.WorkspaceRepository,
+	agentRepo repository.AgentRepository,
+	readTokenRepo *repository.ReadTokenRepository,
+	scheduleRepo *repository.ScheduleRepository,
+	reportRepo *repository.ReportRepository,
+	auditRepo *repository.AuditRepository,
+) *WorkspaceService {
+	return &WorkspaceService{
+		pool:          pool,
+		workspaceRepo: workspaceRepo,
+		agentRepo:     agentRepo,
+		readTokenRepo: readTokenRepo,
+		scheduleRepo:  scheduleRepo,
+		reportRepo:    reportRepo,
+		auditRepo:     auditRepo,
+	}
+}
+
+// ArchiveResult reports what archiving a workspace revoked and disabled.
+type ArchiveResult struct {
+	Workspace         *domain.Workspace
+	AgentsRevoked     int64
+	ReadTokensRevoked int64
+	SchedulesDisabled int64
+	ReportsDisabled   int64
+}
+
+// DeleteWorkspaceParams holds parameters for deleting a workspace.
+type DeleteWorkspaceParams struct {
+	WorkspaceID string
+	Confirm     string // must equal the workspace slug
+	SkipExport  bool   // delete without an export taken after archiving
+	BatchSize   int    // tasks per transaction; DefaultDeleteBatchSize if zero
+}
+
+// DeleteWorkspaceResult reports a completed deletion.
+type DeleteWorkspaceResult struct {
+	Workspace    *domain.Workspace
+	TasksDeleted int64
+	Batches      int
+	ExportedAt   *time.Time // the export the deletion relied on; nil with SkipExport
+}
+
+// ArchiveWorkspace freezes a workspace: its agents are deactivated and their
+// tokens replaced, read tokens are revoked, schedules and reports are disabled,
+// and background jobs skip its tasks. Data stays readable through the admin export.
+func (s *WorkspaceService) ArchiveWorkspace(ctx context.Context, workspaceID, reason string) (*ArchiveResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	workspace, err := s.workspaceRepo.GetByIDForUpdate(ctx, tx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.IsArchived() {
+		return nil, fmt.Errorf("%w: workspace %s was archived at %s", domain.ErrWorkspaceArchived, workspace.Slug, workspace.ArchivedAt.Format(time.RFC3339))
+	}
+
+	result, err := s.archiveInTx(ctx, tx, workspace, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	slog.Info("workspace archived",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"agents_revoked", result.AgentsRevoked,
+		"read_tokens_revoked", result.ReadTokensRevoked,
+	)
+
+	return result, nil
+}
+
+// archiveInTx archives a locked, live workspace and records it in the audit log.
+func (s *WorkspaceService) archiveInTx(ctx context.Context, tx pgx.Tx, workspace *domain.Workspace, reason string) (*ArchiveResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "workspace archived"
+	}
+
+	archivedAt, err := s.workspaceRepo.Archive(ctx, tx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	workspace.ArchivedAt = &archivedAt
+
+	result := &ArchiveResult{Workspace: workspace}
+	if result.AgentsRevoked, err = s.agentRepo.RevokeByWorkspace(ctx, tx, workspace.ID); err != nil {
+		return nil, err
+	}
+	if result.ReadTokensRevoked, err = s.readTokenRepo.RevokeByWorkspace(ctx, tx, workspace.ID); err != nil {
+		return nil, err
+	}
+	if result.SchedulesDisabled, err = s.scheduleRepo.DisableByWorkspace(ctx, tx, workspace.ID, reason); err != nil {
+		return nil, err
+	}
+	if result.ReportsDisabled, err = s.reportRepo.DisableByWorkspace(ctx, tx, workspace.ID, reason); err != nil {
+		return nil, err
+	}
+
+	err = s.auditRepo.Create(ctx, tx, &domain.AuditEntry{
+		Action:      domain.AuditWorkspaceArchived,
+		WorkspaceID: &workspace.ID,
+		Details: map[string]any{
+			"slug":                workspace.Slug,
+			"reason":              reason,
+			"agents_revoked":      result.AgentsRevoked,
+			"read_tokens_revoked": result.ReadTokensRevoked,
+			"schedules_disabled":  result.SchedulesDisabled,
+			"reports_disabled":    result.ReportsDisabled,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWorkspace permanently removes a workspace and everything in it. A live
+// workspace is archived first, which revokes its tokens. Unless SkipExport is
+// set, deletion then requires an export recorded after archiving, so the export
+// is complete: the first call on a live workspace archives it and fails with
+// ErrExportRequired. Tasks are deleted in batches, each in its own transaction;
+// an interrupted deletion can be resumed by calling DeleteWorkspace again.
+func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, params DeleteWorkspaceParams) (*DeleteWorkspaceResult, error) {
+	batchSize := params.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultDeleteBatchSize
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, params.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Confirm != workspace.Slug {
+		return nil, fmt.Errorf("%w: confirm must equal the workspace slug", domain.ErrValidation)
+	}
+
+	if !workspace.IsArchived() {
+		if workspace, err = s.archiveForDeletion(ctx, params.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DeleteWorkspaceResult{Workspace: workspace}
+	if !params.SkipExport {
+		exportedAt, err := s.auditRepo.LastAt(ctx, workspace.ID, domain.AuditWorkspaceExported)
+		if err != nil {
+			return nil, err
+		}
+		if exportedAt == nil || exportedAt.Before(*workspace.ArchivedAt) {
+			return nil, fmt.Errorf("%w: workspace %s is archived; export it, then delete again", domain.ErrExportRequired, workspace.Slug)
+		}
+		result.ExportedAt = exportedAt
+	}
+
+	for {
+		deleted, err := s.workspaceRepo.DeleteTaskBatch(ctx, workspace.ID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if deleted == 0 {
+			break
+		}
+		result.TasksDeleted += deleted
+		result.Batches++
+		slog.Debug("workspace task batch deleted", "workspace_id", workspace.ID, "tasks", deleted)
+	}
+
+	if err := s.deleteInTx(ctx, result, params.SkipExport); err != nil {
+		return nil, err
+	}
+
+	slog.Info("workspace deleted",
+		"workspace_id", workspace.ID,
+		"slug", workspace.Slug,
+		"tasks_deleted", result.TasksDeleted,
+		"batches", result.Batches,
+		"skip_export", params.SkipExport,
+	)
+
+	return result, nil
+}
+
+// archiveForDeletion archives a workspace unless a concurrent call already did.
+func (s *WorkspaceService) archiveForDeletion(ctx context.Context, workspaceID string) (*domain.Workspace, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	workspace, err := s.workspaceRepo.GetByIDForUpdate(ctx, tx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace.IsArchived() {
+		if _, err := s.archiveInTx(ctx, tx, workspace, "archived for deletion"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// deleteInTx removes the workspace row, which cascades to what the task batches
+// left, and records the deletion in the audit log.
+func (s *WorkspaceService) deleteInTx(ctx context.Context, result *DeleteWorkspaceResult, skipExport bool) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	workspace := result.Workspace
+	if err := s.workspaceRepo.Delete(ctx, tx, workspace.ID); err != nil {
+		return err
+	}
+
+	details := map[string]any{
+		"slug":          workspace.Slug,
+		"name":          workspace.Name,
+		"tasks_deleted": result.TasksDeleted,
+		"skip_export":   skipExport,
+	}
+	if result.ExportedAt != nil {
+		details["exported_at"] = result.ExportedAt.UTC().Format(time.RFC3339)
+	}
+	err = s.auditRepo.Create(ctx, tx, &domain.AuditEntry{
+		Action:      domain.AuditWorkspaceDeleted,
+		WorkspaceID: &workspace.ID,
+		Details:     details,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// maxWorkspaceSlugLength limits workspace slugs.
+const maxWorkspaceSlugLength = 100
+
+// workspaceSlugPattern is the shape of a workspace slug.
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// CreateSandboxParams holds parameters for cloning a workspace into a sandbox.
+type CreateSandboxParams struct {
+	SourceID string
+	Name     string        // default: the source's name with a "(sandbox)" suffix
+	Slug     string        // default: the source's slug with a random suffix
+	TTL      time.Duration // default: domain.DefaultSandboxTTL
+}
+
+// SandboxResult reports a created sandbox. Agents carry their new tokens.
+type SandboxResult struct {
+	Workspace *domain.Workspace
+	Agents    []*domain.Agent
+	Clone     *repository.SandboxClone
+}
+
+// CreateSandbox clones a live workspace's settings, queues, labels, agents and open
+// tasks into a new sandbox workspace, for trying agents against realistic data
+// without touching the original. Agents get fresh tokens. The sandbox expires after
+// the TTL and is then deleted by PurgeExpiredSandboxes.
+func (s *WorkspaceService) CreateSandbox(ctx context.Context, params CreateSandboxParams) (*SandboxResult, error) {
+	ttl := params.TTL
+	if ttl == 0 {
+		ttl = domain.DefaultSandboxTTL
+	}
+	if ttl < time.Minute || ttl > domain.MaxSandboxTTL {
+		return nil, fmt.Errorf("%w: ttl must be between 1m and %s", domain.ErrValidation, domain.MaxSandboxTTL)
+	}
+
+	// Tokens are generated up front; agents added meanwhile are not cloned
+	agents, err := s.agentRepo.ListByWorkspace(ctx, params.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make(map[string]string, len(agents))
+	for _, agent := range agents {
+		if tokens[agent.ID], err = generateAgentToken(); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err.Error() != "tx is closed" {
+			slog.Error("failed to rollback transaction", "error", err)
+		}
+	}()
+
+	source, err := s.workspaceRepo.GetByIDForUpdate(ctx, tx, params.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsArchived() {
+		return nil, fmt.Errorf("%w: cannot clone workspace %s", domain.ErrWorkspaceArchived, source.Slug)
+	}
+	if source.IsSandbox() {
+		return nil, fmt.Errorf("%w: workspace %s is a sandbox itself", domain.ErrValidation, source.Slug)
+	}
+
+	sandbox, err := newSandbox(source, params, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workspaceRepo.Create(ctx, tx, sandbox); err != nil {
+		return nil, err
+	}
+
+	clone, err := s.workspaceRepo.CloneIntoSandbox(ctx, tx, source.ID, sandbox.ID, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.auditRepo.Create(ctx, tx, &domain.AuditEntry{
+		Action:      domain.AuditSandboxCreated,
+		WorkspaceID: &source.ID,
+		Details: map[string]any{
+			"sandbox_id":    sandbox.ID,
+			"slug":          sandbox.Slug,
+			"expires_at":    sandbox.ExpiresAt.UTC().Format(time.RFC3339),
+			"agents_cloned": clone.Agents,
+			"tasks_cloned":  clone.Tasks,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	sandboxAgents, err := s.agentRepo.ListByWorkspace(ctx, sandbox.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("sandbox created",
+		"workspace_id", sandbox.ID,
+		"slug", sandbox.Slug,
+		"sandbox_of", source.ID,
+		"expires_at", sandbox.ExpiresAt,
+		"agents", clone.Agents,
+		"tasks", clone.Tasks,
+	)
+
+	return &SandboxResult{Workspace: sandbox, Agents: sandboxAgents, Clone: clone}, nil
+}
+
+// newSandbox builds the sandbox workspace for a source, validating the name and slug.
+func newSandbox(source *domain.Workspace, params CreateSandboxParams, ttl time.Duration) (*domain.Workspace, error) {
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		name = source.Name + " (sandbox)"
+	}
+	if len(name) > 255 {
+		return nil, fmt.Errorf("%w: name must be at most 255 characters", domain.ErrValidation)
+	}
+
+	slug := strings.TrimSpace(params.Slug)
+	if slug == "" {
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, fmt.Errorf("generate sandbox slug: %w", err)
+		}
+		slug = fmt.Sprintf("%.*s-sandbox-%x", maxWorkspaceSlugLength-len("-sandbox-")-6, source.Slug, suffix)
+	}
+	if len(slug) > maxWorkspaceSlugLength || !workspaceSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: slug must be lowercase letters, digits and dashes, at most %d characters", domain.ErrValidation, maxWorkspaceSlugLength)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	return &domain.Workspace{
+		Name:                   name,
+		Slug:                   slug,
+		StatusDeadlines:        source.StatusDeadlines,
+		AutoAssignStrategy:     source.AutoAssignStrategy,
+		PriorityInheritance:    source.PriorityInheritance,
+		AutoUnblock:            source.AutoUnblock,
+		AgentStaleAfterSeconds: source.AgentStaleAfterSeconds,
+		PriorityAging:          source.PriorityAging,
+		ClaimFairness:          source.ClaimFairness,
+		DeadlineWarningPercent: source.DeadlineWarningPercent,
+		DeadlineExpiry:         source.DeadlineExpiry,
+		MaxAttempts:            source.MaxAttempts,
+		SandboxOf:              &source.ID,
+		ExpiresAt:              &expiresAt,
+	}, nil
+}
+
+// generateAgentToken returns a new random agent token.
+func generateAgentToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate agent token: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// PurgeExpiredSandboxes deletes every sandbox that expired by now, without an
+// export. A sandbox that fails to delete is logged and retried on the next run.
+// Returns the number of sandboxes deleted.
+func (s *WorkspaceService) PurgeExpiredSandboxes(ctx context.Context, now time.Time) (int, error) {
+	sandboxes, err := s.workspaceRepo.ListExpiredSandboxes(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, sandbox := range sandboxes {
+		_, err := s.DeleteWorkspace(ctx, DeleteWorkspaceParams{
+			WorkspaceID: sandbox.ID,
+			Confirm:     sandbox.Slug,
+			SkipExport:  true,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return count, ctx.Err()
+			}
+			slog.Error("failed to purge expired sandbox", "workspace_id", sandbox.ID, "slug", sandbox.Slug, "error", err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
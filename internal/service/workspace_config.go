@@ -0,0 +1,805 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// WorkspaceConfigService exports and imports the configuration of workspaces,
+// separate from their tasks, so it can be reviewed and promoted between deployments.
+type WorkspaceConfigService struct {
+	workspaceRepo     repository.WorkspaceRepository
+	agentRepo         repository.AgentRepository
+	taskService       *TaskService
+	labelService      *LabelService
+	queueService      *QueueService
+	escalationService *EscalationService
+	scheduleService   *ScheduleService
+	reportService     *ReportService
+}
+
+// NewWorkspaceConfigService creates a new WorkspaceConfigService. Every section
+// is changed through the service that owns it, so imports are validated like
+// the matching API calls.
+func NewWorkspaceConfigService(
+	workspaceRepo repository.WorkspaceRepository,
+	agentRepo repository.AgentRepository,
+	taskService *TaskService,
+	labelService *LabelService,
+	queueService *QueueService,
+	escalationService *EscalationService,
+	scheduleService *ScheduleService,
+	reportService *ReportService,
+) *WorkspaceConfigService {
+	return &WorkspaceConfigService{
+		workspaceRepo:     workspaceRepo,
+		agentRepo:         agentRepo,
+		taskService:       taskService,
+		labelService:      labelService,
+		queueService:      queueService,
+		escalationService: escalationService,
+		scheduleService:   scheduleService,
+		reportService:     reportService,
+	}
+}
+
+// ExportConfig returns the workspace and its configuration with every section
+// present. Escalation routes keep their evaluation order; the other lists are
+// sorted by name so exports of the same configuration are identical.
+func (s *WorkspaceConfigService) ExportConfig(ctx context.Context, workspaceID string) (*domain.Workspace, *domain.WorkspaceConfig, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	agents, err := s.agentRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make(map[string]string, len(agents))
+	for _, agent := range agents {
+		names[agent.ID] = agent.Name
+	}
+	nameOf := func(agentID string) string {
+		if name, ok := names[agentID]; ok {
+			return name
+		}
+		return agentID
+	}
+
+	cfg := &domain.WorkspaceConfig{
+		Settings: &domain.WorkspaceSettings{
+			StatusDeadlines:        workspace.StatusDeadlines,
+			AutoAssignStrategy:     workspace.AutoAssignStrategy,
+			PriorityInheritance:    workspace.PriorityInheritance,
+			AutoUnblock:            workspace.AutoUnblock,
+			AgentStaleAfterSeconds: workspace.AgentStaleAfterSeconds,
+			DoneValidation:         workspace.DoneValidation,
+			PriorityAging:          workspace.PriorityAging,
+			ClaimFairness:          workspace.ClaimFairness,
+			DeadlineWarningPercent: workspace.DeadlineWarningPercent,
+			DeadlineExpiry:         workspace.DeadlineExpiry,
+			MaxAttempts:            workspace.MaxAttempts,
+			EventWebhook:           workspace.EventWebhook,
+			EventBroker:            workspace.EventBroker,
+		},
+		Labels:           []*domain.Label{},
+		Queues:           []*domain.Queue{},
+		EscalationRoutes: []*domain.EscalationRoute{},
+		Schedules:        []*domain.Schedule{},
+		Reports:          []*domain.Report{},
+	}
+
+	labels, err := s.labelService.ListLabels(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, label := range labels {
+		cfg.Labels = append(cfg.Labels, &domain.Label{Name: label.Name, Color: label.Color, Description: label.Description})
+	}
+
+	queues, err := s.queueService.ListQueues(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, queue := range queues {
+		cfg.Queues = append(cfg.Queues, &domain.Queue{Name: queue.Name, Description: queue.Description})
+	}
+
+	routes, err := s.escalationService.ListRoutes(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, route := range routes {
+		exported := &domain.EscalationRoute{
+			Name:       route.Name,
+			Label:      route.Label,
+			Priority:   route.Priority,
+			TargetType: route.TargetType,
+			Target:     route.Target,
+		}
+		if route.CreatorID != nil {
+			creator := nameOf(*route.CreatorID)
+			exported.CreatorID = &creator
+		}
+		if route.TargetType == domain.EscalationTargetAgent {
+			exported.Target = nameOf(route.Target)
+		}
+		cfg.EscalationRoutes = append(cfg.EscalationRoutes, exported)
+	}
+
+	schedules, err := s.scheduleService.ListSchedules(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, schedule := range schedules {
+		template := schedule.Template
+		if template.AssigneeID != nil {
+			assignee := nameOf(*template.AssigneeID)
+			template.AssigneeID = &assignee
+		}
+		cfg.Schedules = append(cfg.Schedules, &domain.Schedule{
+			CreatorID: nameOf(schedule.CreatorID),
+			Name:      schedule.Name,
+			CronExpr:  schedule.CronExpr,
+			Timezone:  schedule.Timezone,
+			Enabled:   schedule.Enabled,
+			Template:  template,
+		})
+	}
+
+	reports, err := s.reportService.ListReports(ctx, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, report := range reports {
+		cfg.Reports = append(cfg.Reports, &domain.Report{
+			CreatorID:  nameOf(report.CreatorID),
+			Name:       report.Name,
+			Kind:       report.Kind,
+			Format:     report.Format,
+			Period:     report.Period,
+			CronExpr:   report.CronExpr,
+			Timezone:   report.Timezone,
+			TargetType: report.TargetType,
+			Target:     report.Target,
+			Enabled:    report.Enabled,
+		})
+	}
+
+	slices.SortFunc(cfg.Labels, func(a, b *domain.Label) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(cfg.Queues, func(a, b *domain.Queue) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(cfg.Schedules, func(a, b *domain.Schedule) int { return cmp.Compare(a.Name, b.Name) })
+	slices.SortFunc(cfg.Reports, func(a, b *domain.Report) int { return cmp.Compare(a.Name, b.Name) })
+
+	return workspace, cfg, nil
+}
+
+// ImportConfig applies a configuration to a workspace: settings, then labels,
+// queues, escalation routes, schedules and reports. Sections left nil are not
+// touched. Labels, queues, schedules and reports are matched by name and created
+// or updated; ones missing from the configuration are kept. Escalation routes are
+// replaced as a whole, since their order matters.
+//
+// Agent names are resolved before anything changes. Other validation errors stop
+// the import at the failing item with the sections before it applied; importing
+// the same configuration again is safe, as unchanged items are left alone.
+func (s *WorkspaceConfigService) ImportConfig(ctx context.Context, workspaceID string, cfg *domain.WorkspaceConfig) (*domain.ConfigImportResult, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.IsArchived() {
+		return nil, domain.ErrWorkspaceArchived
+	}
+
+	cfg, err = s.resolveAgents(ctx, workspaceID, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ConfigImportResult{}
+	if cfg.Settings != nil {
+		if result.SettingsChanged, err = s.importSettings(ctx, workspace, cfg.Settings); err != nil {
+			return nil, fmt.Errorf("settings: %w", err)
+		}
+	}
+	if cfg.Labels != nil {
+		if result.Labels, err = s.importLabels(ctx, workspaceID, cfg.Labels); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Queues != nil {
+		if result.Queues, err = s.importQueues(ctx, workspaceID, cfg.Queues); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.EscalationRoutes != nil {
+		if result.EscalationRoutes, err = s.importEscalationRoutes(ctx, workspaceID, cfg.EscalationRoutes); err != nil {
+			return nil, fmt.Errorf("escalation routes: %w", err)
+		}
+	}
+	if cfg.Schedules != nil {
+		if result.Schedules, err = s.importSchedules(ctx, workspaceID, cfg.Schedules); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Reports != nil {
+		if result.Reports, err = s.importReports(ctx, workspaceID, cfg.Reports); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Info("workspace configuration imported",
+		"workspace_id", workspaceID,
+		"settings_changed", result.SettingsChanged,
+		"labels", result.Labels,
+		"queues", result.Queues,
+		"escalation_routes", result.EscalationRoutes,
+		"schedules", result.Schedules,
+		"reports", result.Reports,
+	)
+
+	return result, nil
+}
+
+// resolveAgents returns a copy of the configuration with agent names replaced by
+// the IDs of the workspace's agents. Unknown names are a validation error.
+func (s *WorkspaceConfigService) resolveAgents(ctx context.Context, workspaceID string, cfg *domain.WorkspaceConfig) (*domain.WorkspaceConfig, error) {
+	agents, err := s.agentRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(agents))
+	for _, agent := range agents {
+		ids[agent.Name] = agent.ID
+	}
+	resolve := func(name, item, field string) (string, error) {
+		if id, ok := ids[strings.TrimSpace(name)]; ok {
+			return id, nil
+		}
+		return "", fmt.Errorf("%w: %s: %s %q is not an agent of the workspace", domain.ErrValidation, item, field, name)
+	}
+
+	resolved := *cfg
+	if cfg.EscalationRoutes != nil {
+		resolved.EscalationRoutes = make([]*domain.EscalationRoute, len(cfg.EscalationRoutes))
+		for i, route := range cfg.EscalationRoutes {
+			copied := *route
+			item := fmt.Sprintf("escalation route %q", route.Name)
+			if route.CreatorID != nil {
+				creatorID, err := resolve(*route.CreatorID, item, "creator")
+				if err != nil {
+					return nil, err
+				}
+				copied.CreatorID = &creatorID
+			}
+			if route.TargetType == domain.EscalationTargetAgent {
+				if copied.Target, err = resolve(route.Target, item, "target"); err != nil {
+					return nil, err
+				}
+			}
+			resolved.EscalationRoutes[i] = &copied
+		}
+	}
+
+	if cfg.Schedules != nil {
+		resolved.Schedules = make([]*domain.Schedule, len(cfg.Schedules))
+		for i, schedule := range cfg.Schedules {
+			copied := *schedule
+			item := fmt.Sprintf("schedule %q", schedule.Name)
+			if copied.CreatorID, err = resolve(schedule.CreatorID, item, "creator"); err != nil {
+				return nil, err
+			}
+			if schedule.Template.AssigneeID != nil {
+				assigneeID, err := resolve(*schedule.Template.AssigneeID, item, "assignee")
+				if err != nil {
+					return nil, err
+				}
+				copied.Template.AssigneeID = &assigneeID
+			}
+			resolved.Schedules[i] = &copied
+		}
+	}
+
+	if cfg.Reports != nil {
+		resolved.Reports = make([]*domain.Report, len(cfg.Reports))
+		for i, report := range cfg.Reports {
+			copied := *report
+			if copied.CreatorID, err = resolve(report.CreatorID, fmt.Sprintf("report %q", report.Name), "creator"); err != nil {
+				return nil, err
+			}
+			resolved.Reports[i] = &copied
+		}
+	}
+
+	return &resolved, nil
+}
+
+// importSettings brings the workspace's settings in line with the configuration,
+// changing only what differs. Settings left empty take their defaults. Reports
+// whether anything changed.
+func (s *WorkspaceConfigService) importSettings(ctx context.Context, workspace *domain.Workspace, settings *domain.WorkspaceSettings) (bool, error) {
+	for status, minutes := range settings.StatusDeadlines {
+		if !domain.TaskStatus(status).IsValid() {
+			return false, fmt.Errorf("%w: status_deadlines: unknown status %q", domain.ErrValidation, status)
+		}
+		if minutes < 1 {
+			return false, fmt.Errorf("%w: status_deadlines: %s must be at least 1 minute", domain.ErrValidation, status)
+		}
+	}
+
+	strategy := settings.AutoAssignStrategy
+	if strategy == "" {
+		strategy = domain.AutoAssignNone
+	}
+	if !strategy.IsValid() {
+		return false, fmt.Errorf("%w: auto_assign_strategy must be none, round_robin, least_loaded or capability_match", domain.ErrValidation)
+	}
+
+	staleAfter := settings.AgentStaleAfterSeconds
+	if staleAfter == 0 {
+		staleAfter = domain.DefaultAgentStaleAfterSeconds
+	}
+	if staleAfter < domain.MinAgentStaleAfterSeconds || staleAfter > domain.MaxAgentStaleAfterSeconds {
+		return false, fmt.Errorf("%w: agent_stale_after_seconds must be between %d and %d",
+			domain.ErrValidation, domain.MinAgentStaleAfterSeconds, domain.MaxAgentStaleAfterSeconds)
+	}
+
+	hook := settings.DoneValidation
+	if hook != nil && hook.Timeout == 0 {
+		hook = &domain.DoneValidationHook{URL: hook.URL, Timeout: domain.DefaultDoneValidationTimeout, FailOpen: hook.FailOpen}
+	}
+	aging := settings.PriorityAging
+	if aging != nil && aging.Action == "" {
+		aging = &domain.PriorityAging{After: aging.After, Action: domain.PriorityAgingBump}
+	}
+	fairness := settings.ClaimFairness
+	if fairness.Window == 0 {
+		fairness.Window = domain.DefaultClaimQuotaWindowSeconds * time.Second
+	}
+
+	changed := false
+	if !maps.Equal(workspace.StatusDeadlines, settings.StatusDeadlines) {
+		if err := s.workspaceRepo.SetStatusDeadlines(ctx, workspace.ID, settings.StatusDeadlines); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if strategy != workspace.AutoAssignStrategy {
+		if err := s.workspaceRepo.SetAutoAssignStrategy(ctx, workspace.ID, strategy); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if settings.AutoUnblock != workspace.AutoUnblock {
+		if err := s.workspaceRepo.SetAutoUnblock(ctx, workspace.ID, settings.AutoUnblock); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if staleAfter != workspace.AgentStaleAfterSeconds {
+		if err := s.workspaceRepo.SetAgentStaleAfter(ctx, workspace.ID, staleAfter); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !equalPointers(hook, workspace.DoneValidation) {
+		if _, err := s.taskService.SetDoneValidation(ctx, workspace.ID, hook); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !equalPointers(aging, workspace.PriorityAging) {
+		if _, err := s.taskService.SetPriorityAging(ctx, workspace.ID, aging); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if settings.DeadlineWarningPercent != workspace.DeadlineWarningPercent {
+		if err := s.taskService.SetDeadlineWarning(ctx, workspace.ID, settings.DeadlineWarningPercent); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !maps.Equal(workspace.DeadlineExpiry, settings.DeadlineExpiry) {
+		if err := s.taskService.SetDeadlineExpiry(ctx, workspace.ID, settings.DeadlineExpiry); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if settings.MaxAttempts != workspace.MaxAttempts {
+		if err := s.taskService.SetMaxAttempts(ctx, workspace.ID, settings.MaxAttempts); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !equalEventWebhooks(settings.EventWebhook, workspace.EventWebhook) {
+		if _, err := s.taskService.SetEventWebhook(ctx, workspace.ID, settings.EventWebhook); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !equalEventBrokers(settings.EventBroker, workspace.EventBroker) {
+		if _, err := s.taskService.SetEventBroker(ctx, workspace.ID, settings.EventBroker); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if fairness != workspace.ClaimFairness {
+		if _, err := s.taskService.SetClaimFairness(ctx, workspace.ID, fairness); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	// Last, as it reworks inherited priorities across the workspace
+	if settings.PriorityInheritance != workspace.PriorityInheritance {
+		if _, err := s.taskService.SetPriorityInheritance(ctx, workspace.ID, settings.PriorityInheritance); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// importLabels registers missing labels and updates the color and description of
+// existing ones. An empty color keeps the current one.
+func (s *WorkspaceConfigService) importLabels(ctx context.Context, workspaceID string, labels []*domain.Label) (domain.ConfigImportCounts, error) {
+	var counts domain.ConfigImportCounts
+
+	current, err := s.labelService.ListLabels(ctx, workspaceID)
+	if err != nil {
+		return counts, err
+	}
+	byName := make(map[string]*domain.Label, len(current))
+	for _, label := range current {
+		byName[label.Name] = label
+	}
+
+	for _, label := range labels {
+		name, err := domain.NormalizeLabelName(label.Name)
+		if err != nil {
+			return counts, fmt.Errorf("label %q: %w", label.Name, err)
+		}
+
+		description := strings.TrimSpace(label.Description)
+
+		existing := byName[name]
+		if existing != nil && (label.Color == "" || strings.EqualFold(label.Color, existing.Color)) && description == existing.Description {
+			counts.Unchanged++
+			continue
+		}
+
+		var color *string
+		if label.Color != "" {
+			color = &label.Color
+		}
+		if _, _, err := s.labelService.PutLabel(ctx, workspaceID, name, color, &description); err != nil {
+			return counts, fmt.Errorf("label %q: %w", name, err)
+		}
+		if existing == nil {
+			counts.Created++
+		} else {
+			counts.Updated++
+		}
+	}
+
+	return counts, nil
+}
+
+// importQueues creates missing queues and updates the description of existing ones.
+func (s *WorkspaceConfigService) importQueues(ctx context.Context, workspaceID string, queues []*domain.Queue) (domain.ConfigImportCounts, error) {
+	var counts domain.ConfigImportCounts
+
+	current, err := s.queueService.ListQueues(ctx, workspaceID)
+	if err != nil {
+		return counts, err
+	}
+	byName := make(map[string]*domain.Queue, len(current))
+	for _, queue := range current {
+		byName[queue.Name] = queue
+	}
+
+	for _, queue := range queues {
+		name, err := domain.NormalizeQueueName(queue.Name)
+		if err != nil {
+			return counts, fmt.Errorf("queue %q: %w", queue.Name, err)
+		}
+		description := strings.TrimSpace(queue.Description)
+
+		existing := byName[name]
+		switch {
+		case existing == nil:
+			if _, err := s.queueService.CreateQueue(ctx, workspaceID, name, description); err != nil {
+				return counts, fmt.Errorf("queue %q: %w", name, err)
+			}
+			counts.Created++
+		case existing.Description != description:
+			if _, err := s.queueService.UpdateQueue(ctx, UpdateQueueParams{WorkspaceID: workspaceID, Name: name, Description: &description}); err != nil {
+				return counts, fmt.Errorf("queue %q: %w", name, err)
+			}
+			counts.Updated++
+		default:
+			counts.Unchanged++
+		}
+	}
+
+	return counts, nil
+}
+
+// importEscalationRoutes replaces the workspace's escalation routes unless they
+// already match the configuration. Routes are compared by name for the counts.
+func (s *WorkspaceConfigService) importEscalationRoutes(ctx context.Context, workspaceID string, routes []*domain.EscalationRoute) (domain.ConfigImportCounts, error) {
+	var counts domain.ConfigImportCounts
+
+	current, err := s.escalationService.ListRoutes(ctx, workspaceID)
+	if err != nil {
+		return counts, err
+	}
+	if slices.EqualFunc(current, routes, equalEscalationRoutes) {
+		counts.Unchanged = len(routes)
+		return counts, nil
+	}
+
+	byName := make(map[string]*domain.EscalationRoute, len(current))
+	for _, route := range current {
+		byName[route.Name] = route
+	}
+
+	routes, err = s.escalationService.SetRoutes(ctx, workspaceID, routes)
+	if err != nil {
+		return counts, err
+	}
+
+	for _, route := range routes {
+		existing, ok := byName[route.Name]
+		switch {
+		case !ok:
+			counts.Created++
+		case equalEscalationRoutes(existing, route) && existing.Position == route.Position:
+			counts.Unchanged++
+		default:
+			counts.Updated++
+		}
+		delete(byName, route.Name)
+	}
+	counts.Deleted = len(byName)
+
+	return counts, nil
+}
+
+// importSchedules creates missing schedules and updates existing ones. A schedule
+// keeps its creator, since its tasks are created in the creator's name.
+func (s *WorkspaceConfigService) importSchedules(ctx context.Context, workspaceID string, schedules []*domain.Schedule) (domain.ConfigImportCounts, error) {
+	var counts domain.ConfigImportCounts
+
+	current, err := s.scheduleService.ListSchedules(ctx, workspaceID)
+	if err != nil {
+		return counts, err
+	}
+	byName := make(map[string]*domain.Schedule, len(current))
+	for _, schedule := range current {
+		byName[schedule.Name] = schedule
+	}
+
+	for _, schedule := range schedules {
+		name := strings.TrimSpace(schedule.Name)
+		timezone := cmp.Or(strings.TrimSpace(schedule.Timezone), "UTC")
+
+		existing := byName[name]
+		if existing == nil {
+			created, err := s.scheduleService.CreateSchedule(ctx, CreateScheduleParams{
+				WorkspaceID: workspaceID,
+				CreatorID:   schedule.CreatorID,
+				Name:        name,
+				CronExpr:    schedule.CronExpr,
+				Timezone:    timezone,
+				Template:    schedule.Template,
+			})
+			if err != nil {
+				return counts, fmt.Errorf("schedule %q: %w", name, err)
+			}
+			if !schedule.Enabled {
+				if _, err := s.scheduleService.UpdateSchedule(ctx, UpdateScheduleParams{
+					WorkspaceID: workspaceID,
+					ScheduleID:  created.ID,
+					AgentID:     created.CreatorID,
+					Enabled:     &schedule.Enabled,
+				}); err != nil {
+					return counts, fmt.Errorf("schedule %q: %w", name, err)
+				}
+			}
+			counts.Created++
+			continue
+		}
+
+		if existing.CreatorID != schedule.CreatorID {
+			return counts, fmt.Errorf("%w: schedule %q: the creator of a schedule cannot change, delete it first", domain.ErrValidation, name)
+		}
+
+		params := UpdateScheduleParams{WorkspaceID: workspaceID, ScheduleID: existing.ID, AgentID: existing.CreatorID}
+		changed := false
+		if cronExpr := strings.TrimSpace(schedule.CronExpr); cronExpr != existing.CronExpr {
+			params.CronExpr, changed = &cronExpr, true
+		}
+		if timezone != existing.Timezone {
+			params.Timezone, changed = &timezone, true
+		}
+		if schedule.Enabled != existing.Enabled {
+			params.Enabled, changed = &schedule.Enabled, true
+		}
+		if !equalTaskTemplates(schedule.Template, existing.Template) {
+			params.Template, changed = &schedule.Template, true
+		}
+		if !changed {
+			counts.Unchanged++
+			continue
+		}
+
+		if _, err := s.scheduleService.UpdateSchedule(ctx, params); err != nil {
+			return counts, fmt.Errorf("schedule %q: %w", name, err)
+		}
+		counts.Updated++
+	}
+
+	return counts, nil
+}
+
+// importReports creates missing reports and updates existing ones. A report
+// keeps its creator.
+func (s *WorkspaceConfigService) importReports(ctx context.Context, workspaceID string, reports []*domain.Report) (domain.ConfigImportCounts, error) {
+	var counts domain.ConfigImportCounts
+
+	current, err := s.reportService.ListReports(ctx, workspaceID)
+	if err != nil {
+		return counts, err
+	}
+	byName := make(map[string]*domain.Report, len(current))
+	for _, report := range current {
+		byName[report.Name] = report
+	}
+
+	for _, report := range reports {
+		wanted := *report
+		wanted.Name = strings.TrimSpace(report.Name)
+		wanted.CronExpr = strings.TrimSpace(report.CronExpr)
+		wanted.Timezone = cmp.Or(strings.TrimSpace(report.Timezone), "UTC")
+		wanted.Format = cmp.Or(report.Format, domain.ReportFormatMarkdown)
+		wanted.Period = cmp.Or(report.Period, report.Kind.DefaultPeriod())
+
+		existing := byName[wanted.Name]
+		if existing == nil {
+			created, err := s.reportService.CreateReport(ctx, CreateReportParams{
+				WorkspaceID: workspaceID,
+				CreatorID:   wanted.CreatorID,
+				Name:        wanted.Name,
+				Kind:        wanted.Kind,
+				Format:      wanted.Format,
+				Period:      wanted.Period,
+				CronExpr:    wanted.CronExpr,
+				Timezone:    wanted.Timezone,
+				TargetType:  wanted.TargetType,
+				Target:      wanted.Target,
+			})
+			if err != nil {
+				return counts, fmt.Errorf("report %q: %w", wanted.Name, err)
+			}
+			if !wanted.Enabled {
+				if _, err := s.reportService.UpdateReport(ctx, UpdateReportParams{
+					WorkspaceID: workspaceID,
+					ReportID:    created.ID,
+					AgentID:     created.CreatorID,
+					Enabled:     &wanted.Enabled,
+				}); err != nil {
+					return counts, fmt.Errorf("report %q: %w", wanted.Name, err)
+				}
+			}
+			counts.Created++
+			continue
+		}
+
+		if existing.CreatorID != wanted.CreatorID {
+			return counts, fmt.Errorf("%w: report %q: the creator of a report cannot change, delete it first", domain.ErrValidation, wanted.Name)
+		}
+
+		params := UpdateReportParams{WorkspaceID: workspaceID, ReportID: existing.ID, AgentID: existing.CreatorID}
+		changed := false
+		if wanted.Kind != existing.Kind {
+			params.Kind, changed = &wanted.Kind, true
+		}
+		if wanted.Format != existing.Format {
+			params.Format, changed = &wanted.Format, true
+		}
+		if wanted.Period != existing.Period {
+			params.Period, changed = &wanted.Period, true
+		}
+		if wanted.CronExpr != existing.CronExpr {
+			params.CronExpr, changed = &wanted.CronExpr, true
+		}
+		if wanted.Timezone != existing.Timezone {
+			params.Timezone, changed = &wanted.Timezone, true
+		}
+		if wanted.TargetType != existing.TargetType {
+			params.TargetType, changed = &wanted.TargetType, true
+		}
+		if wanted.Target != existing.Target {
+			params.Target, changed = &wanted.Target, true
+		}
+		if wanted.Enabled != existing.Enabled {
+			params.Enabled, changed = &wanted.Enabled, true
+		}
+		if !changed {
+			counts.Unchanged++
+			continue
+		}
+
+		if _, err := s.reportService.UpdateReport(ctx, params); err != nil {
+			return counts, fmt.Errorf("report %q: %w", wanted.Name, err)
+		}
+		counts.Updated++
+	}
+
+	return counts, nil
+}
+
+// equalPointers reports whether a and b are both nil or point to equal values.
+func equalPointers[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// equalEventWebhooks reports whether two event webhooks have the same URL and
+// publish the same event types, in any order.
+func equalEventWebhooks(a, b *domain.EventWebhook) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.URL == b.URL && slices.Equal(slices.Sorted(slices.Values(a.EventTypes)), slices.Sorted(slices.Values(b.EventTypes)))
+}
+
+// equalEventBrokers reports whether two broker topics have the same name and
+// publish the same event types, in any order.
+func equalEventBrokers(a, b *domain.EventBrokerTopic) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Topic == b.Topic && slices.Equal(slices.Sorted(slices.Values(a.EventTypes)), slices.Sorted(slices.Values(b.EventTypes)))
+}
+
+// equalEscalationRoutes reports whether two routes have the same name, criteria and target.
+func equalEscalationRoutes(a, b *domain.EscalationRoute) bool {
+	return a.Name == b.Name &&
+		equalPointers(a.Label, b.Label) &&
+		equalPointers(a.Priority, b.Priority) &&
+		equalPointers(a.CreatorID, b.CreatorID) &&
+		a.TargetType == b.TargetType &&
+		a.Target == b.Target
+}
+
+// equalTaskTemplates reports whether a template matches a stored one, taking the
+// defaults a stored template was given into account.
+func equalTaskTemplates(template, stored domain.TaskTemplate) bool {
+	capabilities, err := domain.NormalizeCapabilities(template.RequiredCapabilities)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(template.Title) == stored.Title &&
+		template.Description == stored.Description &&
+		cmp.Or(template.Priority, domain.TaskPriorityNormal) == stored.Priority &&
+		cmp.Or(template.Visibility, domain.TaskVisibilityPublic) == stored.Visibility &&
+		equalPointers(template.AssigneeID, stored.AssigneeID) &&
+		slices.Equal(capabilities, stored.RequiredCapabilities) &&
+		equalPointers(template.Queue, stored.Queue)
+}
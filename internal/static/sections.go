@@ -0,0 +1,52 @@
+package static
+
+import "strings"
+
+// Section is a "## " or "### " section of a markdown document. Body holds the
+// heading line and everything up to the next heading of any level.
+type Section struct {
+	Level int
+	Title string
+	Body  string
+}
+
+// SplitSections splits a markdown document into the text before its first
+// section and its "## " and "### " sections. Headings inside fenced code
+// blocks are not section boundaries.
+func SplitSections(doc string) (string, []Section) {
+	var preamble strings.Builder
+	var sections []Section
+	inFence := false
+
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if level, title, ok := sectionHeading(trimmed); ok {
+				sections = append(sections, Section{Level: level, Title: title})
+			}
+		}
+
+		if len(sections) == 0 {
+			preamble.WriteString(line)
+		} else {
+			sections[len(sections)-1].Body += line
+		}
+	}
+
+	return preamble.String(), sections
+}
+
+// sectionHeading parses a "## " or "### " heading line.
+func sectionHeading(line string) (int, string, bool) {
+	for _, level := range []int{3, 2} {
+		prefix := strings.Repeat("#", level) + " "
+		if title, ok := strings.CutPrefix(line, prefix); ok {
+			return level, strings.TrimSpace(title), true
+		}
+	}
+	return 0, "", false
+}
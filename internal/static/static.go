@@ -11,3 +11,8 @@ var SkillMd string
 //
 //go:embed index.html
 var IndexHTML string
+
+// OperatorMd contains the embedded guide for operators (admin API and read tokens).
+//
+//go:embed operator.md
+var OperatorMd string
@@ -0,0 +1,166 @@
+// Package factory creates database fixtures for integration tests.
+//
+// Every function inserts one row (plus, for tasks, its blockers and "created"
+// event) with sensible defaults, applies functional options on top, and fails
+// the test on error. Defaults that must be unique (slugs, agent names, tokens) get a
+// per-process sequence number, so fixtures never collide within a test.
+//
+//	ws := factory.CreateWorkspace(t, pool)
+//	agent := factory.CreateAgent(t, pool, ws.ID, factory.WithCapabilities("coder"))
+//	task := factory.CreateTask(t, pool, ws.ID, agent.ID, factory.WithStatus(domain.TaskStatusInProgress), factory.WithAssignee(agent.ID))
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// DB is satisfied by *pgxpool.Pool and pgx.Tx.
+type DB interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// seq numbers unique defaults.
+var seq atomic.Int64
+
+// next returns the next sequence number.
+func next() int64 {
+	return seq.Add(1)
+}
+
+// WorkspaceOption customizes CreateWorkspace.
+type WorkspaceOption func(*domain.Workspace)
+
+// WithWorkspaceID sets the workspace ID instead of a random UUID.
+func WithWorkspaceID(id string) WorkspaceOption {
+	return func(w *domain.Workspace) { w.ID = id }
+}
+
+// WithWorkspaceName sets the workspace name.
+func WithWorkspaceName(name string) WorkspaceOption {
+	return func(w *domain.Workspace) { w.Name = name }
+}
+
+// WithSlug sets the workspace slug.
+func WithSlug(slug string) WorkspaceOption {
+	return func(w *domain.Workspace) { w.Slug = slug }
+}
+
+// WithStatusDeadlines sets the per-status deadlines in minutes.
+func WithStatusDeadlines(deadlines map[string]int) WorkspaceOption {
+	return func(w *domain.Workspace) { w.StatusDeadlines = deadlines }
+}
+
+// WithAutoAssignStrategy sets the workspace's auto-assignment strategy.
+func WithAutoAssignStrategy(strategy domain.AutoAssignStrategy) WorkspaceOption {
+	return func(w *domain.Workspace) { w.AutoAssignStrategy = strategy }
+}
+
+// CreateWorkspace inserts a workspace. Deadlines default to the seed data's
+// (NEW 120, IN_PROGRESS 1440, BLOCKED 2880 minutes).
+func CreateWorkspace(t testing.TB, db DB, opts ...WorkspaceOption) *domain.Workspace {
+	t.Helper()
+
+	n := next()
+	workspace := &domain.Workspace{
+		ID:                 uuid.NewString(),
+		Name:               "Test Workspace",
+		Slug:               fmt.Sprintf("test-%d", n),
+		StatusDeadlines:    map[string]int{"NEW": 120, "IN_PROGRESS": 1440, "BLOCKED": 2880},
+		AutoAssignStrategy: domain.AutoAssignNone,
+	}
+	for _, opt := range opts {
+		opt(workspace)
+	}
+
+	deadlines, err := json.Marshal(workspace.StatusDeadlines)
+	if err != nil {
+		t.Fatalf("factory: encode status deadlines: %v", err)
+	}
+
+	err = db.QueryRow(context.Background(), `
+		INSERT INTO workspaces (id, name, slug, status_deadlines, auto_assign_strategy)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, workspace.ID, workspace.Name, workspace.Slug, deadlines, workspace.AutoAssignStrategy).Scan(&workspace.CreatedAt)
+	if err != nil {
+		t.Fatalf("factory: create workspace %q: %v", workspace.Slug, err)
+	}
+
+	return workspace
+}
+
+// AgentOption customizes CreateAgent.
+type AgentOption func(*domain.Agent)
+
+// WithAgentID sets the agent ID instead of a random UUID.
+func WithAgentID(id string) AgentOption {
+	return func(a *domain.Agent) { a.ID = id }
+}
+
+// WithAgentName sets the agent name, unique within the workspace.
+func WithAgentName(name string) AgentOption {
+	return func(a *domain.Agent) { a.Name = name }
+}
+
+// WithToken sets the agent's bearer token.
+func WithToken(token string) AgentOption {
+	return func(a *domain.Agent) { a.Token = token }
+}
+
+// WithCapabilities sets the capabilities the agent offers.
+func WithCapabilities(capabilities ...string) AgentOption {
+	return func(a *domain.Agent) { a.Capabilities = capabilities }
+}
+
+// Inactive creates a deactivated agent.
+func Inactive() AgentOption {
+	return func(a *domain.Agent) { a.IsActive = false }
+}
+
+// CreateAgent inserts an active agent into a workspace.
+func CreateAgent(t testing.TB, db DB, workspaceID string, opts ...AgentOption) *domain.Agent {
+	t.Helper()
+
+	n := next()
+	agent := &domain.Agent{
+		ID:           uuid.NewString(),
+		WorkspaceID:  workspaceID,
+		Name:         fmt.Sprintf("agent-%d", n),
+		Token:        fmt.Sprintf("factory-token-%d", n),
+		IsActive:     true,
+		Capabilities: []string{},
+	}
+	for _, opt := range opts {
+		opt(agent)
+	}
+
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO agents (id, workspace_id, name, token, is_active, capabilities)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, agent.ID, agent.WorkspaceID, agent.Name, agent.Token, agent.IsActive, agent.Capabilities).Scan(&agent.CreatedAt)
+	if err != nil {
+		t.Fatalf("factory: create agent %q: %v", agent.Name, err)
+	}
+
+	return agent
+}
+
+// timeOrNow returns t, or the current time if t is zero.
+func timeOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
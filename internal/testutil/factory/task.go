@@ -0,0 +1,209 @@
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+)
+
+// taskFixture is a task to insert with its fixture-only settings.
+type taskFixture struct {
+	task         domain.Task
+	createdEvent bool
+}
+
+// TaskOption customizes CreateTask.
+type TaskOption func(*taskFixture)
+
+// WithTitle sets the task title (5-200 characters).
+func WithTitle(title string) TaskOption {
+	return func(f *taskFixture) { f.task.Title = title }
+}
+
+// WithDescription sets the task description.
+func WithDescription(description string) TaskOption {
+	return func(f *taskFixture) { f.task.Description = description }
+}
+
+// WithStatus sets the task status. It does not set an assignee or a deadline.
+func WithStatus(status domain.TaskStatus) TaskOption {
+	return func(f *taskFixture) { f.task.Status = status }
+}
+
+// WithAssignee assigns the task to an agent.
+func WithAssignee(agentID string) TaskOption {
+	return func(f *taskFixture) { f.task.AssigneeID = &agentID }
+}
+
+// WithPriority sets the task priority.
+func WithPriority(priority domain.TaskPriority) TaskOption {
+	return func(f *taskFixture) { f.task.Priority = priority }
+}
+
+// WithVisibility sets the task visibility.
+func WithVisibility(visibility domain.TaskVisibility) TaskOption {
+	return func(f *taskFixture) { f.task.Visibility = visibility }
+}
+
+// Private creates a private task.
+func Private() TaskOption {
+	return WithVisibility(domain.TaskVisibilityPrivate)
+}
+
+// WithBlockedBy sets the tasks blocking this one.
+func WithBlockedBy(taskIDs ...string) TaskOption {
+	return func(f *taskFixture) { f.task.BlockedBy = taskIDs }
+}
+
+// WithRequiredCapabilities sets the capabilities an agent needs to claim the task.
+func WithRequiredCapabilities(capabilities ...string) TaskOption {
+	return func(f *taskFixture) { f.task.RequiredCapabilities = capabilities }
+}
+
+// WithQueue puts the task in a queue, which must exist in the workspace.
+func WithQueue(queue string) TaskOption {
+	return func(f *taskFixture) { f.task.Queue = &queue }
+}
+
+// WithLabels sets the task labels, which must be registered in the workspace.
+func WithLabels(labels ...string) TaskOption {
+	return func(f *taskFixture) { f.task.Labels = labels }
+}
+
+// WithStatusDeadline sets when the current status expires. Only NEW,
+// IN_PROGRESS and BLOCKED tasks may have a deadline.
+func WithStatusDeadline(deadline time.Time) TaskOption {
+	return func(f *taskFixture) { f.task.StatusDeadlineAt = &deadline }
+}
+
+// WithCreatedAt backdates the task and its created event.
+func WithCreatedAt(createdAt time.Time) TaskOption {
+	return func(f *taskFixture) { f.task.CreatedAt = createdAt }
+}
+
+// WithoutCreatedEvent skips the "created" event, e.g. to test tasks with no history.
+func WithoutCreatedEvent() TaskOption {
+	return func(f *taskFixture) { f.createdEvent = false }
+}
+
+// CreateTask inserts a public, normal-priority NEW task titled "Test Task"
+// together with its "created" event by the creator.
+func CreateTask(t testing.TB, db DB, workspaceID, creatorID string, opts ...TaskOption) *domain.Task {
+	t.Helper()
+
+	fixture := &taskFixture{
+		task: domain.Task{
+			WorkspaceID:          workspaceID,
+			Title:                "Test Task",
+			Description:          "Test Description",
+			CreatorID:            creatorID,
+			Status:               domain.TaskStatusNew,
+			Visibility:           domain.TaskVisibilityPublic,
+			Priority:             domain.TaskPriorityNormal,
+			BlockedBy:            []string{},
+			RequiredCapabilities: []string{},
+			Labels:               []string{},
+		},
+		createdEvent: true,
+	}
+	for _, opt := range opts {
+		opt(fixture)
+	}
+
+	task := &fixture.task
+	task.CreatedAt = timeOrNow(task.CreatedAt)
+
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO tasks (
+			workspace_id, title, description, creator_id, assignee_id, status, visibility, priority,
+			required_capabilities, queue, labels, status_deadline_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+		RETURNING id, number, (SELECT UPPER(slug) FROM workspaces WHERE id = workspace_id) || '-' || number, version, updated_at
+	`,
+		task.WorkspaceID, task.Title, task.Description, task.CreatorID, task.AssigneeID, task.Status, task.Visibility, task.Priority,
+		task.RequiredCapabilities, task.Queue, task.Labels, task.StatusDeadlineAt, task.CreatedAt,
+	).Scan(&task.ID, &task.Number, &task.Key, &task.Version, &task.UpdatedAt)
+	if err != nil {
+		t.Fatalf("factory: create task %q: %v", task.Title, err)
+	}
+
+	if len(task.BlockedBy) > 0 {
+		_, err = db.Exec(context.Background(), `
+			INSERT INTO task_dependencies (workspace_id, task_id, blocker_id, position)
+			SELECT $1, $2, b.id, b.position FROM unnest($3::uuid[]) WITH ORDINALITY AS b(id, position)
+		`, task.WorkspaceID, task.ID, task.BlockedBy)
+		if err != nil {
+			t.Fatalf("factory: create blockers of task %q: %v", task.Title, err)
+		}
+	}
+
+	if fixture.createdEvent {
+		CreateEventChain(t, db, task.ID, domain.TaskEvent{
+			ActorID:   &task.CreatorID,
+			Type:      domain.EventTypeCreated,
+			NewStatus: &task.Status,
+			Comment:   "Task created",
+			CreatedAt: task.CreatedAt,
+		})
+	}
+
+	return task
+}
+
+// CreateEventChain inserts events for a task in the given order and returns
+// them with their IDs. An event without a type is a status change; without a
+// comment it gets a generic one. OldStatus defaults to the previous event's
+// NewStatus. Events without CreatedAt are one second apart and end now, so they
+// sort in the given order. The task row itself is not changed.
+func CreateEventChain(t testing.TB, db DB, taskID string, events ...domain.TaskEvent) []*domain.TaskEvent {
+	t.Helper()
+
+	created := make([]*domain.TaskEvent, len(events))
+	start := time.Now().Add(-time.Duration(len(events)-1) * time.Second)
+	var previous *domain.TaskStatus
+
+	for i, template := range events {
+		event := template
+		event.TaskID = taskID
+		if event.Type == "" {
+			event.Type = domain.EventTypeStatusChanged
+		}
+		if event.Comment == "" {
+			event.Comment = "Status changed"
+		}
+		if event.OldStatus == nil && event.Type == domain.EventTypeStatusChanged {
+			event.OldStatus = previous
+		}
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		}
+
+		var data []byte
+		if event.Data != nil {
+			var err error
+			if data, err = json.Marshal(event.Data); err != nil {
+				t.Fatalf("factory: encode event data: %v", err)
+			}
+		}
+
+		err := db.QueryRow(context.Background(), `
+			INSERT INTO task_events (task_id, actor_id, type, old_status, new_status, comment, data, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, event.TaskID, event.ActorID, event.Type, event.OldStatus, event.NewStatus, event.Comment, data, event.CreatedAt).Scan(&event.ID)
+		if err != nil {
+			t.Fatalf("factory: create %s event for task %s: %v", event.Type, taskID, err)
+		}
+
+		if event.NewStatus != nil {
+			previous = event.NewStatus
+		}
+		created[i] = &event
+	}
+
+	return created
+}
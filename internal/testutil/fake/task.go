@@ -0,0 +1,56 @@
+// Package fake provides in-memory repositories for unit tests that run
+// without a database.
+//
+// Each fake embeds the repository interface it stands in for and overrides
+// only the methods tests have needed so far. The others panic on the nil
+// embedded interface, so a test fails loudly when the code under test needs
+// more; add the method here rather than in a test file.
+//
+//	tasks := &fake.TaskRepository{Tasks: map[string]*domain.Task{"build": build}}
+//	schema := graphapi.NewSchema(tasks, events)
+package fake
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/repository"
+)
+
+// TaskRepository serves tasks from memory, keyed by ID.
+type TaskRepository struct {
+	repository.TaskRepository
+	Tasks map[string]*domain.Task
+}
+
+// GetByID returns the task with the given ID, or ErrTaskNotFound.
+func (r *TaskRepository) GetByID(_ context.Context, taskID string) (*domain.Task, error) {
+	task, ok := r.Tasks[taskID]
+	if !ok {
+		return nil, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// GetBlockedByTasks returns the tasks with the given IDs that exist, in order.
+func (r *TaskRepository) GetBlockedByTasks(_ context.Context, blockedBy []string) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	for _, id := range blockedBy {
+		if task, ok := r.Tasks[id]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// GetDependentTasks returns the tasks blocked by any of the given ones.
+func (r *TaskRepository) GetDependentTasks(_ context.Context, taskIDs []string) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	for _, task := range r.Tasks {
+		if slices.ContainsFunc(task.BlockedBy, func(id string) bool { return slices.Contains(taskIDs, id) }) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+// maxBoardTasks is the page size used to fill the board; the API allows at most 200.
+const maxBoardTasks = 200
+
+// boardStatuses are the statuses shown as board columns, in order.
+var boardStatuses = []domain.TaskStatus{
+	domain.TaskStatusNew,
+	domain.TaskStatusInProgress,
+	domain.TaskStatusNeedsReview,
+	domain.TaskStatusBlocked,
+	domain.TaskStatusAwaitingExternal,
+	domain.TaskStatusWaitingApproval,
+	domain.TaskStatusStuck,
+}
+
+// APIError is an error response of the sloptask API.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%d %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Client calls the sloptask HTTP API for the TUI. Its token may be an agent
+// token, a read token or the admin token; the workspace selects what the admin
+// token reads and is ignored for the others.
+type Client struct {
+	baseURL   string
+	token     string
+	workspace string
+	http      *http.Client
+}
+
+// NewClient creates a Client for the server at baseURL.
+func NewClient(baseURL, token, workspace string) *Client {
+	return &Client{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		token:     token,
+		workspace: workspace,
+		http:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// ListOpenTasks returns the tasks in the board's statuses, most urgent first,
+// and the total number of such tasks, which may exceed the returned page.
+func (c *Client) ListOpenTasks(ctx context.Context) ([]dto.TaskListResponse, int, error) {
+	statuses := make([]string, len(boardStatuses))
+	for i, status := range boardStatuses {
+		statuses[i] = string(status)
+	}
+	query := url.Values{
+		"status": {strings.Join(statuses, ",")},
+		"sort":   {"-priority,created_at"},
+		"limit":  {fmt.Sprint(maxBoardTasks)},
+	}
+
+	var response dto.TasksListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks", query, nil, &response); err != nil {
+		return nil, 0, err
+	}
+	return response.Tasks, response.Total, nil
+}
+
+// GetTask returns a task with its events.
+func (c *Client) GetTask(ctx context.Context, taskID string) (*dto.TaskDetailResponse, error) {
+	var response dto.TaskDetailResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks/"+url.PathEscape(taskID), nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// AgentNames maps the IDs of the workspace's agents to their names.
+func (c *Client) AgentNames(ctx context.Context) (map[string]string, error) {
+	var response dto.AgentsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agents", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(response.Agents))
+	for _, agent := range response.Agents {
+		names[agent.ID] = agent.Name
+	}
+	return names, nil
+}
+
+// WorkspaceID resolves the selected workspace to its ID, as admin endpoints
+// take it in the path. Slugs are looked up in the admin workspace list.
+func (c *Client) WorkspaceID(ctx context.Context) (string, error) {
+	if _, err := uuid.Parse(c.workspace); err == nil {
+		return c.workspace, nil
+	}
+
+	var response dto.WorkspacesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/workspaces", nil, nil, &response); err != nil {
+		return "", err
+	}
+	for _, workspace := range response.Workspaces {
+		if workspace.Slug == c.workspace {
+			return workspace.ID, nil
+		}
+	}
+	return "", fmt.Errorf("workspace %q not found", c.workspace)
+}
+
+// DeleteTask soft-deletes a task as operator.
+func (c *Client) DeleteTask(ctx context.Context, workspaceID, taskID, comment string) error {
+	path := fmt.Sprintf("/api/v1/admin/workspaces/%s/tasks/%s", url.PathEscape(workspaceID), url.PathEscape(taskID))
+	return c.do(ctx, http.MethodDelete, path, nil, dto.DeleteTaskRequest{Comment: comment}, nil)
+}
+
+// ResolveExternal moves every task waiting on the external reference back to
+// IN_PROGRESS and returns how many tasks it resumed.
+func (c *Client) ResolveExternal(ctx context.Context, workspaceID string, ref dto.ExternalRefInfo, comment string) (int, error) {
+	path := fmt.Sprintf("/api/v1/admin/workspaces/%s/external/resolve", url.PathEscape(workspaceID))
+	request := dto.ResolveExternalRequest{System: ref.System, ExternalID: ref.ID, Comment: comment}
+
+	var response dto.ResolveExternalResponse
+	if err := c.do(ctx, http.MethodPost, path, nil, request, &response); err != nil {
+		return 0, err
+	}
+	return len(response.Events), nil
+}
+
+// do sends a request and decodes the JSON response into out, or the error
+// response into an *APIError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.workspace != "" {
+		req.Header.Set(middleware.HeaderWorkspace, c.workspace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Status: resp.StatusCode}
+		var errorResponse dto.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err == nil {
+			apiErr.Code, apiErr.Message = errorResponse.Error.Code, errorResponse.Error.Message
+		} else {
+			// Middleware answers in plain text
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
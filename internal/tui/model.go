@@ -0,0 +1,478 @@
+// Package tui implements `sloptask tui`, a terminal console for a running
+// sloptask server: a live board of open tasks, task details with their event
+// history, and operator actions taken with the admin token.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtlprog/sloptask/internal/domain"
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+)
+
+// actionComment is recorded with operator actions taken from the console.
+const actionComment = "Operator action from the sloptask console"
+
+type view int
+
+const (
+	viewBoard view = iota
+	viewDetail
+)
+
+// Messages delivering the results of API calls.
+type (
+	tasksMsg struct {
+		tasks []dto.TaskListResponse
+		total int
+	}
+	agentsMsg  map[string]string
+	detailMsg  *dto.TaskDetailResponse
+	actionMsg  string
+	errMsg     struct{ err error }
+	tickMsg    time.Time
+	deletedMsg struct{}
+)
+
+// Model is the bubbletea model of the console.
+type Model struct {
+	client   *Client
+	interval time.Duration
+
+	columns   [][]dto.TaskListResponse // one per board status
+	total     int
+	agents    map[string]string
+	updatedAt time.Time
+
+	view          view
+	col, row      int
+	openTaskID    string // task shown in the detail view
+	detail        *dto.TaskDetailResponse
+	scroll        int
+	confirmDelete bool
+	status        string
+
+	width, height int
+}
+
+// New creates the console model. The board and the open task refresh every interval.
+func New(client *Client, interval time.Duration) Model {
+	return Model{
+		client:   client,
+		interval: interval,
+		columns:  make([][]dto.TaskListResponse, len(boardStatuses)),
+		agents:   map[string]string{},
+		width:    120,
+		height:   30,
+	}
+}
+
+// Run starts the console and blocks until the user quits.
+func Run(client *Client, interval time.Duration) error {
+	_, err := tea.NewProgram(New(client, interval), tea.WithAltScreen()).Run()
+	return err
+}
+
+// Init loads the board and starts the refresh ticker.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.loadTasks(), m.loadAgents(), m.tick())
+}
+
+// Update handles keys, window resizes and API results.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tickMsg:
+		cmds := []tea.Cmd{m.loadTasks(), m.tick()}
+		if m.view == viewDetail && m.detail != nil {
+			cmds = append(cmds, m.loadDetail(m.detail.Task.ID))
+		}
+		return m, tea.Batch(cmds...)
+
+	case tasksMsg:
+		m.setTasks(msg.tasks, msg.total)
+		return m, nil
+
+	case agentsMsg:
+		m.agents = msg
+		return m, nil
+
+	case detailMsg:
+		// Drop late responses for a task no longer shown
+		if m.view == viewDetail && msg.Task.ID == m.openTaskID {
+			m.detail = msg
+		}
+		return m, nil
+
+	case actionMsg:
+		m.status = string(msg)
+		cmds := []tea.Cmd{m.loadTasks()}
+		if m.view == viewDetail && m.detail != nil {
+			cmds = append(cmds, m.loadDetail(m.detail.Task.ID))
+		}
+		return m, tea.Batch(cmds...)
+
+	case deletedMsg:
+		m.status = "Task deleted"
+		m.view, m.detail = viewBoard, nil
+		return m, m.loadTasks()
+
+	case errMsg:
+		m.status = "Error: " + msg.err.Error()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey applies a key press to the current view.
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if m.confirmDelete {
+		m.confirmDelete = false
+		if key == "y" && m.detail != nil {
+			m.status = "Deleting..."
+			return m, m.deleteTask(m.detail.Task.ID)
+		}
+		m.status = "Delete cancelled"
+		return m, nil
+	}
+
+	switch m.view {
+	case viewBoard:
+		switch key {
+		case "q":
+			return m, tea.Quit
+		case "left", "h":
+			m.col = (m.col + len(m.columns) - 1) % len(m.columns)
+			m.row = min(m.row, max(len(m.columns[m.col])-1, 0))
+		case "right", "l", "tab":
+			m.col = (m.col + 1) % len(m.columns)
+			m.row = min(m.row, max(len(m.columns[m.col])-1, 0))
+		case "up", "k":
+			m.row = max(m.row-1, 0)
+		case "down", "j":
+			m.row = min(m.row+1, max(len(m.columns[m.col])-1, 0))
+		case "r":
+			return m, m.loadTasks()
+		case "enter":
+			if task, ok := m.selected(); ok {
+				m.view, m.scroll, m.openTaskID, m.detail = viewDetail, 0, task.ID, nil
+				return m, m.loadDetail(task.ID)
+			}
+		}
+
+	case viewDetail:
+		switch key {
+		case "q":
+			return m, tea.Quit
+		case "esc", "backspace":
+			m.view, m.detail = viewBoard, nil
+		case "up", "k":
+			m.scroll = max(m.scroll-1, 0)
+		case "down", "j":
+			m.scroll = min(m.scroll+1, max(len(m.detailLines())-m.detailRows(), 0))
+		case "r":
+			if m.detail != nil {
+				return m, m.loadDetail(m.detail.Task.ID)
+			}
+		case "x":
+			if m.detail != nil {
+				m.confirmDelete = true
+				m.status = "Delete this task? y to confirm, any other key to cancel"
+			}
+		case "e":
+			if m.detail != nil {
+				if ref := m.detail.Task.ExternalRef; ref != nil && m.detail.Task.Status == string(domain.TaskStatusAwaitingExternal) {
+					m.status = "Resolving external wait..."
+					return m, m.resolveExternal(*ref)
+				}
+				m.status = "Task is not awaiting an external reference"
+			}
+		}
+	}
+	return m, nil
+}
+
+// setTasks groups tasks into board columns and keeps the cursor in range.
+func (m *Model) setTasks(tasks []dto.TaskListResponse, total int) {
+	columns := make([][]dto.TaskListResponse, len(boardStatuses))
+	for _, task := range tasks {
+		for i, status := range boardStatuses {
+			if task.Status == string(status) {
+				columns[i] = append(columns[i], task)
+				break
+			}
+		}
+	}
+	m.columns, m.total, m.updatedAt = columns, total, time.Now()
+	m.row = min(m.row, max(len(m.columns[m.col])-1, 0))
+}
+
+// selected returns the task under the board cursor.
+func (m Model) selected() (dto.TaskListResponse, bool) {
+	column := m.columns[m.col]
+	if m.row >= len(column) {
+		return dto.TaskListResponse{}, false
+	}
+	return column[m.row], true
+}
+
+// agentName returns the name of an agent, or its ID when unknown.
+func (m Model) agentName(id *string) string {
+	if id == nil {
+		return "-"
+	}
+	if name, ok := m.agents[*id]; ok {
+		return name
+	}
+	return *id
+}
+
+func (m Model) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m Model) loadTasks() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		tasks, total, err := client.ListOpenTasks(context.Background())
+		if err != nil {
+			return errMsg{err}
+		}
+		return tasksMsg{tasks: tasks, total: total}
+	}
+}
+
+func (m Model) loadAgents() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		names, err := client.AgentNames(context.Background())
+		if err != nil {
+			return errMsg{err}
+		}
+		return agentsMsg(names)
+	}
+}
+
+func (m Model) loadDetail(taskID string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		detail, err := client.GetTask(context.Background(), taskID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return detailMsg(detail)
+	}
+}
+
+func (m Model) deleteTask(taskID string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx := context.Background()
+		workspaceID, err := client.WorkspaceID(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := client.DeleteTask(ctx, workspaceID, taskID, actionComment); err != nil {
+			return errMsg{err}
+		}
+		return deletedMsg{}
+	}
+}
+
+func (m Model) resolveExternal(ref dto.ExternalRefInfo) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx := context.Background()
+		workspaceID, err := client.WorkspaceID(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		resumed, err := client.ResolveExternal(ctx, workspaceID, ref, actionComment)
+		if err != nil {
+			return errMsg{err}
+		}
+		return actionMsg(fmt.Sprintf("Resolved %s %s: %d task(s) resumed", ref.System, ref.ID, resumed))
+	}
+}
+
+// View renders the current view.
+func (m Model) View() string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("sloptask · %d open tasks", m.total)
+	if m.client.workspace != "" {
+		header = fmt.Sprintf("sloptask · %s · %d open tasks", m.client.workspace, m.total)
+	}
+	if !m.updatedAt.IsZero() {
+		header += " · updated " + m.updatedAt.Format("15:04:05")
+	}
+	b.WriteString(header + "\n\n")
+
+	var help string
+	if m.view == viewDetail {
+		m.renderDetail(&b)
+		help = "↑/↓ scroll · esc back · r refresh · x delete · e resolve external · q quit"
+	} else {
+		m.renderBoard(&b)
+		help = "←/→ column · ↑/↓ task · enter details · r refresh · q quit"
+	}
+
+	b.WriteString("\n" + help + "\n")
+	if m.status != "" {
+		b.WriteString(m.status + "\n")
+	}
+	return b.String()
+}
+
+// renderBoard renders one column per board status.
+func (m Model) renderBoard(b *strings.Builder) {
+	width := max(m.width/len(boardStatuses)-1, 12)
+	rows := max(m.height-8, 1)
+
+	headers := make([]string, len(boardStatuses))
+	for i, status := range boardStatuses {
+		headers[i] = fit(fmt.Sprintf("%s (%d)", status, len(m.columns[i])), width)
+	}
+	b.WriteString(strings.Join(headers, " ") + "\n")
+
+	// Scroll the selected column so the cursor stays visible
+	offset := max(m.row-rows+1, 0)
+	for r := range rows {
+		cells := make([]string, len(boardStatuses))
+		empty := true
+		for i, column := range m.columns {
+			index := r
+			if i == m.col {
+				index += offset
+			}
+			if index >= len(column) {
+				cells[i] = fit("", width)
+				continue
+			}
+			empty = false
+			marker := "  "
+			if i == m.col && index == m.row {
+				marker = "> "
+			}
+			cells[i] = fit(marker+priorityMark(column[index].EffectivePriority)+column[index].Title, width)
+		}
+		if empty {
+			break
+		}
+		b.WriteString(strings.Join(cells, " ") + "\n")
+	}
+}
+
+// renderDetail renders the open task and its events, newest last.
+func (m Model) renderDetail(b *strings.Builder) {
+	if m.detail == nil {
+		b.WriteString("Loading...\n")
+		return
+	}
+
+	lines := m.detailLines()
+	rows := m.detailRows()
+	scroll := min(m.scroll, max(len(lines)-rows, 0))
+	for _, line := range lines[scroll:min(scroll+rows, len(lines))] {
+		b.WriteString(fit(line, m.width) + "\n")
+	}
+}
+
+// detailRows is the number of detail lines that fit on screen.
+func (m Model) detailRows() int {
+	return max(m.height-6, 1)
+}
+
+// detailLines renders the open task and its events as lines.
+func (m Model) detailLines() []string {
+	if m.detail == nil {
+		return nil
+	}
+	task := m.detail.Task
+
+	title := task.Title
+	if task.Key != "" {
+		title = task.Key + "  " + title
+	}
+	lines := []string{
+		title,
+		fmt.Sprintf("%s · %s priority · %s · assignee %s · creator %s",
+			task.Status, task.EffectivePriority, task.Visibility, m.agentName(task.AssigneeID), m.agentName(&task.CreatorID)),
+	}
+	if task.StatusDeadlineAt != nil {
+		deadline := "deadline " + task.StatusDeadlineAt.Local().Format("2006-01-02 15:04")
+		if task.IsOverdue {
+			deadline += " (overdue)"
+		}
+		lines = append(lines, deadline)
+	}
+	if len(task.Labels) > 0 {
+		lines = append(lines, "labels: "+strings.Join(task.Labels, ", "))
+	}
+	if task.ExternalRef != nil {
+		lines = append(lines, fmt.Sprintf("waiting on %s %s", task.ExternalRef.System, task.ExternalRef.ID))
+	}
+	if len(task.BlockedBy) > 0 {
+		lines = append(lines, "blocked by: "+strings.Join(task.BlockedBy, ", "))
+	}
+	if task.Description != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(task.Description, "\n")...)
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Events (%d)", len(m.detail.Events)))
+	for _, event := range m.detail.Events {
+		actor := "system"
+		if event.ActorName != nil {
+			actor = *event.ActorName
+		}
+		line := fmt.Sprintf("%s  %-18s %-14s", event.CreatedAt.Local().Format("01-02 15:04:05"), event.Type, actor)
+		if event.OldStatus != nil && event.NewStatus != nil && *event.OldStatus != *event.NewStatus {
+			line += fmt.Sprintf(" %s → %s", *event.OldStatus, *event.NewStatus)
+		}
+		if event.Comment != "" {
+			line += "  " + strings.ReplaceAll(event.Comment, "\n", " ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// priorityMark prefixes urgent tasks so they stand out in the columns.
+func priorityMark(priority string) string {
+	switch domain.TaskPriority(priority) {
+	case domain.TaskPriorityCritical:
+		return "!! "
+	case domain.TaskPriorityHigh:
+		return "! "
+	default:
+		return ""
+	}
+}
+
+// fit truncates or pads s to exactly width runes.
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:width])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
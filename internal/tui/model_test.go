@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mtlprog/sloptask/internal/handler/dto"
+	"github.com/mtlprog/sloptask/internal/middleware"
+)
+
+func TestModel_BoardAndDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer admin", r.Header.Get("Authorization"))
+		assert.Equal(t, "mtl-agents", r.Header.Get(middleware.HeaderWorkspace))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/tasks":
+			assert.Equal(t, "NEW,IN_PROGRESS,NEEDS_REVIEW,BLOCKED,AWAITING_EXTERNAL,WAITING_APPROVAL,STUCK", r.URL.Query().Get("status"))
+			_ = json.NewEncoder(w).Encode(dto.TasksListResponse{Total: 3, Tasks: []dto.TaskListResponse{
+				{ID: "t1", Title: "Fix login", Status: "NEW", EffectivePriority: "critical"},
+				{ID: "t2", Title: "Write docs", Status: "NEW", EffectivePriority: "normal"},
+				{ID: "t3", Title: "Deploy", Status: "STUCK", EffectivePriority: "high"},
+			}})
+		case "/api/v1/tasks/t3":
+			actor := "agent-1"
+			_ = json.NewEncoder(w).Encode(dto.TaskDetailResponse{
+				Task:   dto.TaskDetail{ID: "t3", Title: "Deploy", Status: "STUCK", EffectivePriority: "high"},
+				Events: []dto.TaskEventInfo{{Type: "escalated", ActorName: &actor, Comment: "Need credentials"}},
+			})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(dto.NewErrorResponse("INSUFFICIENT_ACCESS", "Task not found"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "admin", "mtl-agents")
+	m := New(client, time.Minute)
+
+	var model tea.Model = m
+	model, _ = model.Update(m.loadTasks()())
+	board := model.(Model)
+	assert.Len(t, board.columns[0], 2)
+	assert.Len(t, board.columns[6], 1)
+	assert.Contains(t, board.View(), "!! Fix login")
+	assert.Contains(t, board.View(), "3 open tasks")
+
+	// Moving left from the first column wraps to STUCK
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	model, _ = model.Update(cmd())
+	detail := model.(Model)
+	assert.Equal(t, viewDetail, detail.view)
+	assert.Contains(t, detail.View(), "Need credentials")
+	assert.Contains(t, detail.View(), "agent-1")
+
+	// Responses for a task no longer shown are dropped
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model, _ = model.Update(detailMsg(&dto.TaskDetailResponse{Task: dto.TaskDetail{ID: "t3"}}))
+	assert.Nil(t, model.(Model).detail)
+
+	model, _ = model.Update(m.loadDetail("missing")())
+	assert.True(t, strings.HasPrefix(model.(Model).status, "Error: INSUFFICIENT_ACCESS"))
+}
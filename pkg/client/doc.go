@@ -0,0 +1,5 @@
+// Package client contains helpers for programs integrating with a sloptask server.
+//
+// It is importable outside this module and deliberately depends only on the
+// standard library.
+package client
@@ -0,0 +1,28 @@
+package client
+
+// DoneValidationRequest is the JSON body POSTed to a workspace's DONE validation
+// webhook before a task moves to DONE. It is signed like webhook deliveries;
+// verify it with WebhookVerifier.Verify.
+//
+// Respond 2xx to accept the completion. Respond 4xx to reject it: the reason of a
+// DoneValidationResponse body, or a plain-text body, is returned to the agent.
+// Any other response, or none within the workspace's timeout, makes the
+// transition fail unless the workspace lets completions through when the webhook
+// is unavailable.
+type DoneValidationRequest struct {
+	WorkspaceID    string         `json:"workspace_id"`
+	TaskID         string         `json:"task_id"`
+	Title          string         `json:"title"`
+	Labels         []string       `json:"labels"`
+	AgentID        string         `json:"agent_id"` // the agent moving the task to DONE
+	AssigneeID     *string        `json:"assignee_id"`
+	PreviousStatus TaskStatus     `json:"previous_status"`
+	Artefact       string         `json:"artefact"`
+	Result         map[string]any `json:"result,omitempty"`
+	Comment        string         `json:"comment"`
+}
+
+// DoneValidationResponse is the optional JSON body of a rejection.
+type DoneValidationResponse struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook request headers.
+const (
+	// HeaderSignature carries one or more comma-separated "v1=<hex>" signatures.
+	// Several signatures are sent while a secret is being rotated.
+	HeaderSignature = "X-Sloptask-Signature"
+	// HeaderTimestamp carries the Unix time (seconds) the delivery was signed.
+	HeaderTimestamp = "X-Sloptask-Timestamp"
+	// HeaderDelivery carries the unique delivery ID. Retries reuse the same ID.
+	HeaderDelivery = "X-Sloptask-Delivery"
+)
+
+const (
+	signatureVersion = "v1"
+
+	// DefaultTolerance is the maximum accepted age (and clock skew) of a delivery.
+	DefaultTolerance = 5 * time.Minute
+
+	// MaxWebhookBodyBytes bounds how much of a webhook request body is read.
+	MaxWebhookBodyBytes = 1 << 20
+)
+
+// Webhook verification errors.
+var (
+	ErrMissingHeaders    = errors.New("webhook signature headers missing")
+	ErrInvalidSignature  = errors.New("webhook signature invalid")
+	ErrTimestampExpired  = errors.New("webhook timestamp outside tolerance")
+	ErrDuplicateDelivery = errors.New("webhook delivery already processed")
+)
+
+// EventType is the type of a task event.
+type EventType string
+
+// TaskStatus is the status of a task.
+type TaskStatus string
+
+// Event is a task event as delivered in webhook payloads.
+type Event struct {
+	ID        string         `json:"id"`
+	TaskID    string         `json:"task_id"`
+	Type      EventType      `json:"type"`
+	ActorID   *string        `json:"actor_id"`
+	OldStatus *TaskStatus    `json:"old_status"`
+	NewStatus *TaskStatus    `json:"new_status"`
+	Comment   string         `json:"comment"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+
+	// W3C trace context of the request that caused the event. Start the
+	// consumer span as a child of TraceParent to continue the agent's trace.
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+}
+
+// WebhookPayload is the JSON body of a webhook delivery.
+type WebhookPayload struct {
+	DeliveryID  string `json:"delivery_id"`
+	WorkspaceID string `json:"workspace_id"`
+	Event       Event  `json:"event"`
+}
+
+// Sign computes the signature header value for body signed at timestamp with secret.
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	return signatureVersion + "=" + hex.EncodeToString(computeMAC(secret, timestamp.Unix(), body))
+}
+
+// computeMAC returns HMAC-SHA256 over "<unix timestamp>.<body>".
+func computeMAC(secret []byte, unix int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(unix, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// WebhookVerifier checks signatures of incoming webhook deliveries and
+// rejects stale or already processed ones.
+type WebhookVerifier struct {
+	secrets   [][]byte
+	tolerance time.Duration
+	dedup     *DeliveryCache
+	now       func() time.Time
+}
+
+// NewWebhookVerifier creates a verifier accepting signatures made with any of the
+// given secrets (pass old and new secrets during rotation). Deliveries are
+// deduplicated for twice the tolerance window.
+func NewWebhookVerifier(secrets ...string) *WebhookVerifier {
+	keys := make([][]byte, len(secrets))
+	for i, secret := range secrets {
+		keys[i] = []byte(secret)
+	}
+	return &WebhookVerifier{
+		secrets:   keys,
+		tolerance: DefaultTolerance,
+		dedup:     NewDeliveryCache(2 * DefaultTolerance),
+		now:       time.Now,
+	}
+}
+
+// Verify checks the timestamp and signature headers against body.
+// It does not consult the delivery cache, so it can be used to re-verify a
+// stored delivery.
+func (v *WebhookVerifier) Verify(header http.Header, body []byte) error {
+	signatures := header.Get(HeaderSignature)
+	timestampHeader := header.Get(HeaderTimestamp)
+	if signatures == "" || timestampHeader == "" {
+		return ErrMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrMissingHeaders, timestampHeader)
+	}
+
+	age := v.now().Sub(time.Unix(unix, 0))
+	if age > v.tolerance || age < -v.tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrTimestampExpired, age.Truncate(time.Second))
+	}
+
+	for _, signature := range strings.Split(signatures, ",") {
+		version, value, ok := strings.Cut(strings.TrimSpace(signature), "=")
+		if !ok || version != signatureVersion {
+			continue
+		}
+		received, err := hex.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		for _, secret := range v.secrets {
+			if hmac.Equal(received, computeMAC(secret, unix, body)) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// ParseRequest reads, verifies and decodes a webhook request. A delivery ID seen
+// before within the dedup window yields ErrDuplicateDelivery along with the
+// decoded payload, so handlers can acknowledge retries without reprocessing.
+func (v *WebhookVerifier) ParseRequest(r *http.Request) (*WebhookPayload, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxWebhookBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read webhook body: %w", err)
+	}
+	if len(body) > MaxWebhookBodyBytes {
+		return nil, fmt.Errorf("webhook body exceeds %d bytes", MaxWebhookBodyBytes)
+	}
+
+	return v.Parse(r.Header, body)
+}
+
+// Parse verifies and decodes a webhook delivery from its headers and raw body.
+func (v *WebhookVerifier) Parse(header http.Header, body []byte) (*WebhookPayload, error) {
+	if err := v.Verify(header, body); err != nil {
+		return nil, err
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode webhook payload: %w", err)
+	}
+
+	deliveryID := header.Get(HeaderDelivery)
+	if deliveryID == "" {
+		deliveryID = payload.DeliveryID
+	}
+	if deliveryID != "" && v.dedup.Seen(deliveryID) {
+		return &payload, ErrDuplicateDelivery
+	}
+
+	return &payload, nil
+}
+
+// DeliveryCache remembers delivery IDs for a limited time.
+// It is safe for concurrent use.
+type DeliveryCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	seen  map[string]time.Time
+	now   func() time.Time
+	sweep time.Time
+}
+
+// NewDeliveryCache creates a cache keeping delivery IDs for ttl.
+func NewDeliveryCache(ttl time.Duration) *DeliveryCache {
+	return &DeliveryCache{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Seen records id and reports whether it was already recorded within the TTL.
+func (c *DeliveryCache) Seen(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.sweep) > c.ttl {
+		for key, at := range c.seen {
+			if now.Sub(at) > c.ttl {
+				delete(c.seen, key)
+			}
+		}
+		c.sweep = now
+	}
+
+	if at, ok := c.seen[id]; ok && now.Sub(at) <= c.ttl {
+		return true
+	}
+	c.seen[id] = now
+	return false
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(t *testing.T, secret string, signedAt time.Time, body []byte, deliveryID string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/hooks/sloptask", bytes.NewReader(body))
+	req.Header.Set(HeaderSignature, Sign([]byte(secret), signedAt, body))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(signedAt.Unix(), 10))
+	req.Header.Set(HeaderDelivery, deliveryID)
+	return req
+}
+
+func TestWebhookVerifier_ParseRequest(t *testing.T) {
+	body := []byte(`{"delivery_id":"d-1","workspace_id":"w-1","event":{"id":"e-1","task_id":"t-1","type":"status_changed","new_status":"DONE","comment":"ok","created_at":"2026-01-02T03:04:05Z"}}`)
+	now := time.Now()
+
+	verifier := NewWebhookVerifier("old-secret", "new-secret")
+
+	payload, err := verifier.ParseRequest(signedRequest(t, "new-secret", now, body, "d-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "w-1", payload.WorkspaceID)
+	assert.Equal(t, EventType("status_changed"), payload.Event.Type)
+	require.NotNil(t, payload.Event.NewStatus)
+	assert.Equal(t, TaskStatus("DONE"), *payload.Event.NewStatus)
+
+	// Retries of the same delivery are flagged, with the payload still decoded
+	payload, err = verifier.ParseRequest(signedRequest(t, "old-secret", now, body, "d-1"))
+	assert.ErrorIs(t, err, ErrDuplicateDelivery)
+	assert.NotNil(t, payload)
+}
+
+func TestWebhookVerifier_Rejects(t *testing.T) {
+	body := []byte(`{"delivery_id":"d-2","event":{}}`)
+	now := time.Now()
+	verifier := NewWebhookVerifier("secret")
+
+	_, err := verifier.ParseRequest(signedRequest(t, "wrong", now, body, "d-2"))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = verifier.ParseRequest(signedRequest(t, "secret", now.Add(-time.Hour), body, "d-2"))
+	assert.ErrorIs(t, err, ErrTimestampExpired)
+
+	req := signedRequest(t, "secret", now, body, "d-2")
+	req.Header.Del(HeaderSignature)
+	_, err = verifier.ParseRequest(req)
+	assert.ErrorIs(t, err, ErrMissingHeaders)
+
+	// Tampered body
+	req = signedRequest(t, "secret", now, body, "d-2")
+	req.Body = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"event":{"type":"x"}}`))).Body
+	_, err = verifier.ParseRequest(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestDeliveryCache_Expires(t *testing.T) {
+	now := time.Now()
+	cache := NewDeliveryCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	assert.False(t, cache.Seen("a"))
+	assert.True(t, cache.Seen("a"))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, cache.Seen("a"))
+}